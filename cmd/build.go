@@ -2,19 +2,34 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
+	"knative.dev/client/pkg/util"
+
 	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/builders/buildkit"
 	pack "knative.dev/func/pkg/builders/buildpacks"
+	"knative.dev/func/pkg/builders/dockerfile"
+	"knative.dev/func/pkg/builders/kaniko"
 	"knative.dev/func/pkg/builders/s2i"
 	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/docker"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/oci"
+	"knative.dev/func/pkg/utils"
 )
 
 func NewBuildCmd(newClient ClientFactory) *cobra.Command {
@@ -28,8 +43,14 @@ NAME
 SYNOPSIS
 	{{rootCmdUse}} build [-r|--registry] [--builder] [--builder-image]
 		         [--push] [--username] [--password] [--token]
-	             [--platform] [-p|--path] [-c|--confirm] [-v|--verbose]
-		         [--build-timestamp] [--registry-insecure]
+	             [--platform] [--platforms] [-p|--path] [-c|--confirm] [-v|--verbose]
+		         [--build-timestamp] [--incremental] [--buildkit-host]
+		         [--registry-insecure] [--show-context] [--sbom] [--sbom-output]
+		         [--sign] [--sign-key] [--build-env] [--trust-builder] [--dockerfile]
+		         [--output] [--scan] [--scanner] [--scan-severity] [--scan-output]
+		         [--tag-strategy] [--progress] [--all] [--parallel]
+
+	{{rootCmdUse}} build logs --remote [--last] [-o|--output] [-p|--path]
 
 DESCRIPTION
 
@@ -45,6 +66,91 @@ DESCRIPTION
 	When building a function for the first time, either a registry or explicit
 	image name is required.  Subsequent builds will reuse these option values.
 
+	The image derived from the registry is tagged by --tag-strategy: latest
+	(the default), git-sha (the short hash of the commit checked out), semver
+	(the semantic version of its git tag) or timestamp (the time of the
+	build, in UTC).  The tag of an explicit image may instead include
+	expressions expanded when built, such as git.sha (see the func.yaml
+	reference).  The tag derived is recorded in func.yaml as deploy.tag.
+
+	The files sent to the builder are those of the function's directory less
+	those excluded by its .gitignore and .funcignore, which follow .gitignore
+	syntax; prefix a pattern with ! to include files otherwise excluded.  Use
+	--show-context to list these files without building.
+
+	With --sbom, a software bill of materials (SBOM) of the dependencies
+	declared by the function's manifests (such as go.mod or package-lock.json)
+	is generated when it is built, as a CycloneDX or SPDX document.  The SBOM
+	is attached to the function's image as an OCI artifact when pushed, and
+	written to the path given by --sbom-output if any.
+
+	With --sign, the function's image is signed with cosign once pushed: with
+	the private key given by --sign-key, whose password if any is read from
+	COSIGN_PASSWORD, or else keyless, with a certificate of the OIDC identity
+	of the environment (SIGSTORE_ID_TOKEN, or that of a GitHub Actions
+	workflow).  The reference of the signature is recorded in func.yaml as
+	build.signature.
+
+	With --output, the image built is also written to a file, such that it
+	can be carried to an environment without access to the registry, or
+	loaded by other tools: oci:<path> writes an OCI image layout as a tar,
+	of the image or of the images of each platform, and docker-archive:<path>
+	writes the image as would "docker save", to be loaded by "docker load".
+	Images built by the kaniko builder are only of the registry to which the
+	build pushes them, so cannot be written.
+
+	With --scan, the image built is scanned for known vulnerabilities (CVEs)
+	before it is pushed, by the trivy or grype command (--scanner), and the
+	build fails if any are of the severity given by --scan-severity (low,
+	medium, high or critical, by default high) or above.  The report of every
+	vulnerability found is written as JSON to the path given by --scan-output
+	if any, whether or not the scan fails, for use in CI.  The scan is
+	persisted in func.yaml as build.scan.  Images built by the kaniko builder
+	cannot be scanned.  The scanner is not built into func: its command must
+	be installed on the PATH, such as of the machine or CI image building the
+	function, or the scan fails.
+
+	With --progress json, the progress of the build is written to stdout as
+	JSON objects, one per line, each with the time at which it was reached,
+	other messages being written to stderr: build-started, a phase as each
+	phase starts (detect, analyze, restore, build and export of the pack
+	builder, build and export of the host builder, build of s2i), built, and
+	on --push, the push phase, push-progress as each quarter of each layer is
+	pushed, and image-pushed with the digest of the image.
+
+	With --all, each function of the workspace at --path is built, as last
+	configured, such as those of a repository of several functions.  The
+	functions are those listed by its func.workspace.yaml, as paths relative
+	to it beneath "functions", or else those found beneath it.  At most
+	--parallel are built at once, by default one, and a summary of each
+	build, its image or the error by which it failed, is written once all
+	are built.  Only flags which apply to every function, such as --builder,
+	--registry, --platforms and --push, may be given with --all.
+
+	Build environment variables, set with --build-env, are available to the
+	builder only, such as those configuring buildpacks (BP_GO_VERSION), and
+	are persisted in func.yaml as build.buildEnvs.  The pack builder's builder
+	and run images are set with --builder-image and --run-image, and
+	--trust-builder trusts a builder image other than those of known
+	publishers, whose lifecycle pack otherwise runs phase by phase,
+	withholding the registry credentials from the buildpacks.
+
+	The dockerfile builder builds the function from a Dockerfile of its own,
+	the Dockerfile of its root or that given by --dockerfile, using the local
+	container engine.  The image is labeled with the function's name and
+	runtime, and listens on port 8080 (LISTEN_ADDRESS) unless the Dockerfile
+	sets another.  Build environment variables are passed as build arguments.
+
+	Functions may be built without a local container engine by the buildkit
+	builder, with a BuildKit daemon given by --buildkit-host, or by the kaniko
+	builder, which builds the function's Dockerfile as a pod on the current
+	cluster, streaming its output.  The kaniko builder pushes the image as it
+	is built, with the credentials of the function's registry.
+
+	'logs' shows the logs of the function's most recent remote builds, such as
+	those of 'deploy --remote', which are retained on the cluster once the
+	pods which ran them are removed.
+
 EXAMPLES
 
 	o Build a function container using the given registry.
@@ -61,15 +167,72 @@ EXAMPLES
 	o Build a function specifying the Source-to-Image (S2I) builder
 	  $ {{rootCmdUse}} build --builder=s2i
 
+	o Rebuild a function with the S2I builder, restoring dependencies (such as
+	  the Maven repository or node_modules) saved from its previous image.
+	  Subsequent builds remain incremental until --incremental=false.
+	  $ {{rootCmdUse}} build --builder=s2i --incremental
+
+	o Build a function from its own Dockerfile, docker/Dockerfile.
+	  $ {{rootCmdUse}} build --builder=dockerfile --dockerfile=docker/Dockerfile
+
+	o Build and push a function from its Dockerfile on the current cluster,
+	  without a local container engine.
+	  $ {{rootCmdUse}} build --builder=kaniko --registry registry.example.com/alice
+
+	o List the files of the function which would be sent to the builder.
+	  $ {{rootCmdUse}} build --show-context
+
 	o Build a function specifying the Pack builder with a custom Buildpack
 	  builder image.
 	  $ {{rootCmdUse}} build --builder=pack --builder-image=cnbs/sample-builder:bionic
 
+	o Build a function with the Pack builder using a trusted, private builder
+	  image, run image and Go version.
+	  $ {{rootCmdUse}} build --builder=pack --builder-image=registry.example.com/builder:1 \
+	      --run-image=registry.example.com/run:1 --trust-builder --build-env BP_GO_VERSION=1.23
+
+	o Build a function for arm64 using a remote BuildKit daemon, which requires
+	  no local container engine.
+	  $ {{rootCmdUse}} build --builder=buildkit --buildkit-host=tcp://buildkitd.example.com:1234 --platform=linux/arm64
+
+	o Build and push an image of a function for arm64 alone, such as from an
+	  amd64 host, which is pushed as an image rather than a manifest list.
+	  $ {{rootCmdUse}} build --platform linux/arm64 --push
+
+	o Build and push a multi-architecture image of a function, for both amd64
+	  and arm64.  The pack and s2i builders build the image of each platform
+	  in turn, which are pushed as a single manifest list.
+	  $ {{rootCmdUse}} build --platforms linux/amd64,linux/arm64 --push
+
+	o Build a function with a CycloneDX SBOM of its dependencies, written to
+	  sbom.json.  The SBOM is attached to the image when pushed.
+	  $ {{rootCmdUse}} build --sbom cyclonedx --sbom-output sbom.json
+
+	o Build a function and write its image to function.tar as an OCI image
+	  layout, without pushing it.
+	  $ {{rootCmdUse}} build --output oci:./function.tar
+
+	o Build a function and fail if its image has critical vulnerabilities,
+	  writing the report of its scan by grype to scan.json.
+	  $ {{rootCmdUse}} build --scan --scanner grype --scan-severity critical --scan-output scan.json
+
+	o Build and push a function from CI, reading the phases of its build.
+	  $ {{rootCmdUse}} build --push --progress json | jq -r 'select(.stage=="phase").phase'
+
+	o Build and push every function of the repository, four at once.
+	  $ {{rootCmdUse}} build --all --parallel 4 --push
+
+	o Build, push and sign a function's image with a cosign key.
+	  $ {{rootCmdUse}} build --push --sign --sign-key cosign.key
+
+	o Show the logs of the last three remote builds of a function.
+	  $ {{rootCmdUse}} build logs --remote --last 3
+
 `,
 		SuggestFor: []string{"biuld", "buidl", "built"},
 		PreRunE: bindEnv("image", "path", "builder", "registry", "confirm",
-			"push", "builder-image", "base-image", "platform", "verbose",
-			"build-timestamp", "registry-insecure", "username", "password", "token"),
+			"push", "builder-image", "base-image", "run-image", "platform", "platforms", "verbose",
+			"build-timestamp", "incremental", "buildkit-host", "registry-insecure", "show-context", "sbom", "sbom-output", "sign", "sign-key", "scan", "scanner", "scan-severity", "scan-output", "tag-strategy", "progress", "all", "parallel", "trust-builder", "dockerfile", "username", "password", "token"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBuild(cmd, args, newClient)
 		},
@@ -110,9 +273,34 @@ EXAMPLES
 	cmd.Flags().StringP("builder-image", "", builderImage,
 		"Specify a custom builder image for use by the builder other than its default. ($FUNC_BUILDER_IMAGE)")
 	cmd.Flags().StringP("base-image", "", f.Build.BaseImage,
-		"Override the base image for your function (host builder only)")
+		"Override the image your function is built upon: the builder image of the pack and s2i builders, or the base of the host builder. ($FUNC_BASE_IMAGE)")
+	cmd.Flags().StringP("run-image", "", f.Build.RunImage,
+		"Override the image your function runs upon: the run image of the pack builder, the runtime image of the s2i builder, or the base of the host builder. ($FUNC_RUN_IMAGE)")
 	cmd.Flags().StringP("image", "i", f.Image,
 		"Full image name in the form [registry]/[namespace]/[name]:[tag] (optional). This option takes precedence over --registry ($FUNC_IMAGE)")
+	cmd.Flags().String("tag-strategy", f.Build.TagStrategy,
+		fmt.Sprintf("Tag of the image derived from the registry when building: %v. Defaults to latest. ($FUNC_TAG_STRATEGY)", strings.Join(fn.TagStrategies, ", ")))
+	cmd.Flags().String("sbom", f.Build.SBOM,
+		fmt.Sprintf("Generate an SBOM of the function's dependencies when building, in the format %q or %q. ($FUNC_SBOM)", fn.SBOMFormatCycloneDX, fn.SBOMFormatSPDX))
+	cmd.Flags().Bool("sign", f.Build.Sign != nil,
+		"Sign the function's image with cosign once pushed, keyless unless --sign-key is given. ($FUNC_SIGN)")
+	cmd.Flags().String("sign-key", signKey(f),
+		"Path to the cosign private key with which to sign the image. Its password, if any, is read from COSIGN_PASSWORD. ($FUNC_SIGN_KEY)")
+	cmd.Flags().Bool("scan", f.Build.Scan != nil,
+		"Scan the function's image for known vulnerabilities once built, failing if any are of --scan-severity or above. Requires the trivy or grype command (--scanner) to be installed on the PATH. ($FUNC_SCAN)")
+	cmd.Flags().String("scanner", scanSpec(f).Scanner,
+		fmt.Sprintf("Scanner of the image, %q or %q, whose command is run from the PATH. Defaults to %v. Requires --scan. ($FUNC_SCANNER)", fn.ScannerTrivy, fn.ScannerGrype, fn.DefaultScanner))
+	cmd.Flags().String("scan-severity", scanSpec(f).Severity,
+		fmt.Sprintf("Severity of vulnerabilities at or above which the scan fails (%v). Defaults to %v. Requires --scan. ($FUNC_SCAN_SEVERITY)", strings.Join(fn.Severities, "|"), fn.DefaultScanSeverity))
+	cmd.Flags().Bool("incremental", f.Build.Incremental, "Reuse artifacts of the function's previous image, such as downloaded dependencies, when building. This is only useful for the s2i builder. ($FUNC_INCREMENTAL)")
+	cmd.Flags().Bool("trust-builder", trustBuilder(f),
+		"Trust the builder image of the pack builder, giving its lifecycle the registry credentials. By default only builder images of known publishers are trusted. ($FUNC_TRUST_BUILDER)")
+	cmd.Flags().String("dockerfile", f.Build.Dockerfile,
+		"Path, relative to the function's root, of the Dockerfile from which the dockerfile and kaniko builders build the function. Defaults to Dockerfile. ($FUNC_DOCKERFILE)")
+	cmd.Flags().StringArray("build-env", []string{},
+		"Build environment variable to set in the form NAME=VALUE. "+
+			"You may provide this flag multiple times for setting multiple build environment variables. "+
+			"To unset, specify the variable name followed by a \"-\" (e.g., NAME-).")
 
 	// Static Flags:
 	// Options which are either empty or have static defaults only (not
@@ -120,14 +308,30 @@ EXAMPLES
 	cmd.Flags().BoolP("push", "u", false,
 		"Attempt to push the function image to the configured registry after being successfully built")
 	cmd.Flags().StringP("platform", "", "",
-		"Optionally specify a target platform, for example \"linux/amd64\" when using the s2i build strategy")
+		"Target platform of the image, which may be other than that of the host, for example \"linux/arm64\". The image is of that platform alone, rather than a manifest list. ($FUNC_PLATFORM)")
+	cmd.Flags().String("platforms", "",
+		"Comma-separated target platforms of a multi-architecture image, for example \"linux/amd64,linux/arm64\" ($FUNC_PLATFORMS)")
 	cmd.Flags().StringP("username", "", "",
 		"Username to use when pushing to the registry.")
 	cmd.Flags().StringP("password", "", "",
 		"Password to use when pushing to the registry.")
 	cmd.Flags().StringP("token", "", "",
 		"Token to use when pushing to the registry.")
+	cmd.Flags().String("sbom-output", "",
+		"Path to which to write the SBOM generated when building. Requires --sbom. ($FUNC_SBOM_OUTPUT)")
+	cmd.Flags().String("scan-output", "",
+		"Path to which to write the JSON report of the scan of the image. Requires --scan. ($FUNC_SCAN_OUTPUT)")
+	cmd.Flags().String("progress", progressText,
+		fmt.Sprintf("Format in which the progress of the build is reported. [%v|%v]. ($FUNC_PROGRESS)", progressText, progressJSON))
+	cmd.Flags().Bool("all", false,
+		"Build every function of the workspace at --path, those listed by its func.workspace.yaml or else those found beneath it. ($FUNC_ALL)")
+	cmd.Flags().Int("parallel", 1,
+		"Number of functions built at once with --all. ($FUNC_PARALLEL)")
+	cmd.Flags().String("output", "",
+		"File to which to write the image built, as oci:<path> (an OCI image layout as a tar) or docker-archive:<path> (as written by docker save)")
 	cmd.Flags().BoolP("build-timestamp", "", false, "Use the actual time as the created time for the docker image. This is only useful for buildpacks builder.")
+	cmd.Flags().Bool("show-context", false, "List the files of the function which are sent to the builder, as determined by its .gitignore, its .funcignore and the builder, without building. ($FUNC_SHOW_CONTEXT)")
+	cmd.Flags().String("buildkit-host", os.Getenv("BUILDKIT_HOST"), "Address of the BuildKit daemon used by the buildkit builder, such as tcp://host:1234, ssh://user@host or kube-pod://buildkitd. Defaults to BUILDKIT_HOST. ($FUNC_BUILDKIT_HOST)")
 
 	// Temporarily Hidden Basic Auth Flags
 	// Username, Password and Token flags, which plumb through basic auth, are
@@ -141,6 +345,8 @@ EXAMPLES
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
+	cmd.AddCommand(NewBuildLogsCmd())
+
 	// Tab Completion
 	if err := cmd.RegisterFlagCompletionFunc("builder", CompleteBuilderList); err != nil {
 		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
@@ -157,7 +363,20 @@ func runBuild(cmd *cobra.Command, _ []string, newClient ClientFactory) (err erro
 		cfg buildConfig
 		f   fn.Function
 	)
-	if cfg, err = newBuildConfig().Prompt(); err != nil { // gather values into a single instruction set
+	if viper.GetBool("show-context") {
+		return showContext(cmd, viper.GetString("path"), viper.GetString("builder"))
+	}
+	if viper.GetBool("all") {
+		return runBuildAll(cmd, newClient)
+	}
+	cfg = newBuildConfig()
+	if cfg.BuildEnvs, err = cmd.Flags().GetStringArray("build-env"); err != nil {
+		return
+	}
+	if cfg.Output, err = cmd.Flags().GetString("output"); err != nil {
+		return
+	}
+	if cfg, err = cfg.Prompt(); err != nil { // gather values into a single instruction set
 		// Layer 2: Catch technical errors and provide CLI-specific user-friendly messages
 
 		// Check if it's a "not initialized" error (no function found)
@@ -187,19 +406,6 @@ Cannot use both --image and --registry together. Choose one:
 
 Note: FUNC_REGISTRY environment variable doesn't conflict with --image flag
 
-For more options, run 'func build --help'`, err)
-		}
-		if errors.Is(err, fn.ErrPlatformNotSupported) {
-			return fmt.Errorf(`%w
-
-The --platform flag is only supported with the S2I builder.
-
-Try this:
-  func build --registry <registry> --builder=s2i --platform linux/amd64
-
-Or remove the --platform flag:
-  func build --registry <registry>
-
 For more options, run 'func build --help'`, err)
 		}
 		return
@@ -214,6 +420,13 @@ For more options, run 'func build --help'`, err)
 
 	cmd.SetContext(cfg.WithValues(cmd.Context())) // Some optional settings are passed via context
 
+	// Of JSON progress, stdout is of its events alone, the messages otherwise
+	// written to it being written to stderr.
+	if cfg.Progress == progressJSON {
+		cmd.SetContext(context.WithValue(cmd.Context(), fn.DeployEventsKey{}, jsonProgress(cmd.OutOrStdout())))
+		cmd.SetOut(cmd.ErrOrStderr())
+	}
+
 	// Client
 	clientOptions, err := cfg.clientOptions()
 	if err != nil {
@@ -230,6 +443,15 @@ For more options, run 'func build --help'`, err)
 	if f, err = client.Build(cmd.Context(), f, buildOptions...); err != nil {
 		return
 	}
+	if err = writeSBOMOutput(f, cfg.SBOMOutput); err != nil {
+		return
+	}
+	if err = scanImage(cmd.Context(), cmd.ErrOrStderr(), client, f, cfg.Builder, cfg.ScanOutput); err != nil {
+		return
+	}
+	if err = writeImageOutput(cmd.Context(), f, cfg.Builder, cfg.Output); err != nil {
+		return
+	}
 	if cfg.Push {
 		if f, _, err = client.Push(cmd.Context(), f); err != nil {
 			return
@@ -243,6 +465,115 @@ For more options, run 'func build --help'`, err)
 	return f.Stamp()
 }
 
+// trustBuilder returns whether the function's pack builder image is
+// explicitly trusted.
+func trustBuilder(f fn.Function) bool {
+	return f.Build.TrustBuilder != nil && *f.Build.TrustBuilder
+}
+
+// signKey of the function, if signed with a key.
+func signKey(f fn.Function) string {
+	if f.Build.Sign == nil {
+		return ""
+	}
+	return f.Build.Sign.Key
+}
+
+// scanSpec of the function, empty if it is not scanned.
+func scanSpec(f fn.Function) fn.ScanSpec {
+	if f.Build.Scan == nil {
+		return fn.ScanSpec{}
+	}
+	return *f.Build.Scan
+}
+
+// scanImage scans the image built for known vulnerabilities, if the function
+// defines a scan (build.scan), writing a summary to w and the report as JSON
+// to output if given.  The report is written whether or not the scan fails.
+func scanImage(ctx context.Context, w io.Writer, client *fn.Client, f fn.Function, builder, output string) error {
+	if f.Build.Scan == nil {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "func-scan")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "image.tar")
+	if err = writeImageOutput(ctx, f, builder, string(oci.ArchiveOCI)+":"+path); err != nil {
+		return err
+	}
+
+	r, err := client.Scan(ctx, f, path)
+	if err != nil && !errors.Is(err, fn.ErrVulnerable) {
+		return err
+	}
+	if output != "" {
+		data, jerr := json.MarshalIndent(r, "", "  ")
+		if jerr != nil {
+			return jerr
+		}
+		if jerr = os.WriteFile(output, append(data, '\n'), 0644); jerr != nil {
+			return fmt.Errorf("cannot write the scan report: %w", jerr)
+		}
+	}
+	failing := r.Failing()
+	fmt.Fprintf(w, "Scanned %v with %v: %d vulnerabilities, %d of severity %v or above\n",
+		r.Image, r.Scanner, len(r.Vulnerabilities), len(failing), r.Threshold)
+	if len(failing) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", "ID", "SEVERITY", "PACKAGE", "VERSION", "FIXED")
+		for _, v := range failing {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.ID, v.Severity, v.Package, v.Version, v.FixedVersion)
+		}
+		tw.Flush()
+	}
+	return err
+}
+
+// writeSBOMOutput copies the SBOM generated by the function's build to path,
+// if given.
+func writeSBOMOutput(f fn.Function, path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(f.SBOMPath())
+	if err != nil {
+		return fmt.Errorf("cannot read the SBOM of the build: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// writeImageOutput writes the image built to the archive given by output,
+// if any: from the OCI layout of the last build of the host and buildkit
+// builders, or else from the daemon of the container engine.
+func writeImageOutput(ctx context.Context, f fn.Function, builder, output string) error {
+	if output == "" {
+		return nil
+	}
+	format, path, err := oci.ParseArchive(output)
+	if err != nil {
+		return err
+	}
+	ref, err := name.ParseReference(f.Build.Image)
+	if err != nil {
+		return err
+	}
+	var ii v1.ImageIndex
+	if builder == builders.Host || builder == builders.BuildKit {
+		ii, err = oci.LastBuildIndex(f)
+	} else {
+		ii, err = docker.DaemonIndex(ctx, f)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read the image built: %w", err)
+	}
+	if err = oci.WriteArchive(path, format, ref, ii); err != nil {
+		return fmt.Errorf("cannot write the image to %v: %w", path, err)
+	}
+	return nil
+}
+
 // WithValues returns a context populated with values from the build config
 // which are provided to the system via the context.
 func (c buildConfig) WithValues(ctx context.Context) context.Context {
@@ -253,6 +584,47 @@ func (c buildConfig) WithValues(ctx context.Context) context.Context {
 	return ctx
 }
 
+// showContext lists the files of the function at path which comprise its
+// build context, as sent by the given builder.
+func showContext(cmd *cobra.Command, path, builder string) error {
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return err
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	files, err := buildContext(f, builder)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Fprintln(cmd.OutOrStdout(), file)
+	}
+	return nil
+}
+
+// buildContext returns the files of the function's build context as sent by
+// the given builder, each of which may exclude further files.
+func buildContext(f fn.Function, builder string) ([]string, error) {
+	switch builder {
+	case builders.Host:
+		return oci.Context(f)
+	case builders.BuildKit:
+		return buildkit.Context(f)
+	case builders.S2I:
+		return s2i.Context(f)
+	case builders.Dockerfile, builders.Kaniko:
+		return dockerfile.Context(f.Root)
+	default:
+		ignorer, err := fn.NewIgnorer(f.Root)
+		if err != nil {
+			return nil, err
+		}
+		return ignorer.Context()
+	}
+}
+
 type buildConfig struct {
 	// Globals (builder, confirm, registry, verbose)
 	config.Global
@@ -265,17 +637,27 @@ type buildConfig struct {
 	// image name derivation based on registry and function name)
 	Image string
 
-	// BaseImage is an image to build a function upon (host builder only)
-	// TODO: gauron99 -- make option to add a path to dockerfile ?
+	// BaseImage is an image to build a function upon: the builder image of
+	// the pack and s2i builders, or the base of the host builder.
 	BaseImage string
 
+	// Dockerfile is the path, relative to the function's root, of the
+	// Dockerfile built by the dockerfile and kaniko builders.
+	Dockerfile string
+
+	// RunImage is an image for the built function to run upon.
+	RunImage string
+
 	// Path of the function implementation on local disk. Defaults to current
 	// working directory of the process.
 	Path string
 
-	// Platform ofr resultant image (s2i builder only)
+	// Platform of the resultant image.
 	Platform string
 
+	// Platforms of the resultant multi-architecture image, comma-separated.
+	Platforms string
+
 	// Push the resulting image to the registry after building.
 	Push bool
 
@@ -293,29 +675,119 @@ type buildConfig struct {
 	// Build with the current timestamp as the created time for docker image.
 	// This is only useful for buildpacks builder.
 	WithTimestamp bool
+
+	// Incremental builds restore the artifacts saved from the function's
+	// previous image.  This is only useful for the s2i builder, and is
+	// persisted.
+	Incremental bool
+
+	// BuildKitHost is the address of the BuildKit daemon used by the buildkit
+	// builder.
+	BuildKitHost string
+
+	// SBOM format of the software bill of materials to generate when
+	// building, if any.
+	SBOM string
+
+	// TagStrategy by which the tag of the image derived from the registry
+	// is chosen.
+	TagStrategy string
+
+	// SBOMOutput is a path to which to write the generated SBOM.
+	SBOMOutput string
+
+	// Output is the archive, <format>:<path>, to which to write the image
+	// built, if any.  Of the build command alone.
+	Output string
+
+	// Scan the image built for known vulnerabilities.
+	Scan bool
+
+	// Scanner of the image, and the ScanSeverity at or above which
+	// vulnerabilities fail the scan.  Defaults of fn.ScanSpec if empty.
+	Scanner, ScanSeverity string
+
+	// ScanOutput is a path to which to write the report of the scan.
+	ScanOutput string
+
+	// Progress is the format in which the progress of the build, and of the
+	// deployment, is reported: "text", or "json" events written to stdout.
+	Progress string
+
+	// Sign the image with cosign once pushed.
+	Sign bool
+
+	// SignKey is the path of the cosign private key with which to sign the
+	// image.  Keyless signing is used if empty.
+	SignKey string
+
+	// TrustBuilder replaces whether the pack builder trusts its builder
+	// image, if set.
+	TrustBuilder *bool
+
+	// BuildEnvs to set on the function, which may include removals using a
+	// "-".
+	BuildEnvs []string
 }
 
 // newBuildConfig gathers options into a single build request.
 func newBuildConfig() buildConfig {
-	return buildConfig{
+	cfg := buildConfig{
 		Global: config.Global{
 			Builder:          viper.GetString("builder"),
 			Confirm:          viper.GetBool("confirm"),
 			Registry:         registry(), // deferred defaulting
 			Verbose:          viper.GetBool("verbose"),
 			RegistryInsecure: viper.GetBool("registry-insecure"),
+			Builders:         builderPolicies(),
+			Dependencies:     dependencies(),
 		},
 		BuilderImage:  viper.GetString("builder-image"),
 		BaseImage:     viper.GetString("base-image"),
+		Dockerfile:    viper.GetString("dockerfile"),
+		RunImage:      viper.GetString("run-image"),
 		Image:         viper.GetString("image"),
 		Path:          viper.GetString("path"),
 		Platform:      viper.GetString("platform"),
+		Platforms:     viper.GetString("platforms"),
 		Push:          viper.GetBool("push"),
 		Username:      viper.GetString("username"),
 		Password:      viper.GetString("password"),
 		Token:         viper.GetString("token"),
 		WithTimestamp: viper.GetBool("build-timestamp"),
+		Incremental:   viper.GetBool("incremental"),
+		BuildKitHost:  viper.GetString("buildkit-host"),
+		SBOM:          viper.GetString("sbom"),
+		SBOMOutput:    viper.GetString("sbom-output"),
+		TagStrategy:   viper.GetString("tag-strategy"),
+		Scan:          viper.GetBool("scan"),
+		Scanner:       viper.GetString("scanner"),
+		ScanSeverity:  viper.GetString("scan-severity"),
+		ScanOutput:    viper.GetString("scan-output"),
+		Progress:      viper.GetString("progress"),
+		Sign:          viper.GetBool("sign"),
+		SignKey:       viper.GetString("sign-key"),
+	}
+	if viper.IsSet("trust-builder") {
+		v := viper.GetBool("trust-builder")
+		cfg.TrustBuilder = &v
 	}
+	return cfg
+}
+
+// builderPolicies returns the builder policies of the global config file,
+// there being no corresponding flags or environment variables.
+func builderPolicies() map[string]builders.Policy {
+	cfg, _ := config.NewDefault() // load errors are reported with the command's flags
+	return cfg.Builders
+}
+
+// dependencies returns the private registries of dependencies of the global
+// config file, with those of the environment (FUNC_NPM_REGISTRY etc.) taking
+// precedence.
+func dependencies() builders.Dependencies {
+	cfg, _ := config.NewDefault()
+	return cfg.Dependencies.WithEnv(os.Getenv)
 }
 
 // Configure the given function.  Updates a function struct with all
@@ -329,7 +801,28 @@ func (c buildConfig) Configure(f fn.Function) fn.Function {
 	}
 	f.Image = c.Image
 	f.Build.BaseImage = c.BaseImage
-	// Path, Platform and Push are not part of a function's state.
+	f.Build.RunImage = c.RunImage
+	f.Build.Dockerfile = c.Dockerfile
+	f.Build.SBOM = c.SBOM
+	f.Build.TagStrategy = c.TagStrategy
+	f.Build.Incremental = c.Incremental
+	f.Build.Scan = nil
+	if c.Scan {
+		f.Build.Scan = &fn.ScanSpec{Scanner: c.Scanner, Severity: c.ScanSeverity}
+	}
+	f.Build.Sign = nil
+	if c.Sign {
+		f.Build.Sign = &fn.SignSpec{Key: c.SignKey}
+	}
+	if c.TrustBuilder != nil {
+		f.Build.TrustBuilder = c.TrustBuilder
+	}
+	// Malformed build envs are reported by Validate.
+	if envs, err := applyEnvs(f.Build.BuildEnvs, nil, c.BuildEnvs); err == nil {
+		f.Build.BuildEnvs = envs
+	}
+	// Path, Platform(s), Push, SBOMOutput, ScanOutput and Output are not
+	// part of a function's state.
 	return f
 }
 
@@ -399,7 +892,7 @@ func (c buildConfig) Prompt() (buildConfig, error) {
 			Name: "builder",
 			Prompt: &survey.Select{
 				Message: "Select builder:",
-				Options: []string{"pack", "s2i", "host"},
+				Options: []string{"pack", "s2i", "host", "buildkit", "dockerfile", "kaniko"},
 				Default: c.Builder,
 			},
 		},
@@ -449,15 +942,76 @@ func (c buildConfig) Validate(cmd *cobra.Command) (err error) {
 		return fn.ErrConflictingImageAndRegistry
 	}
 
-	// Platform is only supported with the S2I builder at this time
-	if c.Platform != "" && c.Builder != builders.S2I {
-		err = fn.ErrPlatformNotSupported
+	// Image and registry are validated up front, such that a malformed value
+	// is reported specifically rather than by the registry after a full build.
+	// The expressions of an image's tag are validated with the function.
+	if c.Image != "" && !fn.HasTagExpressions(c.Image) {
+		if err = utils.ValidateImage(c.Image); err != nil {
+			return
+		}
+	}
+	if c.Registry != "" {
+		if err = utils.ValidateRegistry(c.Registry); err != nil {
+			return
+		}
+	}
+
+	if c.Platform != "" && c.Platforms != "" {
+		return errors.New("only one of --platform and --platforms may be specified")
+	}
+	if _, err = c.platforms(); err != nil {
 		return
 	}
 
-	// BaseImage is only supported with the host builder
-	if c.BaseImage != "" && c.Builder != "host" {
-		err = errors.New("only host builds support specifying the base image")
+	switch c.SBOM {
+	case "", fn.SBOMFormatCycloneDX, fn.SBOMFormatSPDX:
+	default:
+		return fmt.Errorf("unrecognized value for --sbom '%v'.  Accepts '%v' or '%v'", c.SBOM, fn.SBOMFormatCycloneDX, fn.SBOMFormatSPDX)
+	}
+	if c.SBOMOutput != "" && c.SBOM == "" {
+		return errors.New("--sbom-output requires an SBOM format (--sbom)")
+	}
+	if c.TagStrategy != "" && !slices.Contains(fn.TagStrategies, c.TagStrategy) {
+		return fmt.Errorf("unrecognized value for --tag-strategy '%v'.  Accepts %v", c.TagStrategy, strings.Join(fn.TagStrategies, ", "))
+	}
+	if c.Output != "" {
+		if _, _, err = oci.ParseArchive(c.Output); err != nil {
+			return fmt.Errorf("invalid --output: %w", err)
+		}
+		if c.Builder == builders.Kaniko {
+			return fmt.Errorf("--output is not supported by the %v builder, whose image is pushed by the build", builders.Kaniko)
+		}
+	}
+	switch c.Scanner {
+	case "", fn.ScannerTrivy, fn.ScannerGrype:
+	default:
+		return fmt.Errorf("unrecognized value for --scanner '%v'.  Accepts '%v' or '%v'", c.Scanner, fn.ScannerTrivy, fn.ScannerGrype)
+	}
+	if c.ScanSeverity != "" && !slices.Contains(fn.Severities, c.ScanSeverity) {
+		return fmt.Errorf("unrecognized value for --scan-severity '%v'.  Accepts %v", c.ScanSeverity, strings.Join(fn.Severities, ", "))
+	}
+	if (c.Scanner != "" || c.ScanSeverity != "" || c.ScanOutput != "") && !c.Scan {
+		return errors.New("--scanner, --scan-severity and --scan-output require scanning the image (--scan)")
+	}
+	if c.Scan && c.Builder == builders.Kaniko {
+		return fmt.Errorf("--scan is not supported by the %v builder, whose image is pushed by the build", builders.Kaniko)
+	}
+	switch c.Progress {
+	case "", progressText, progressJSON:
+	default:
+		return fmt.Errorf("unrecognized value for --progress '%v'.  Accepts '%v' or '%v'", c.Progress, progressText, progressJSON)
+	}
+	if c.SignKey != "" && !c.Sign {
+		return errors.New("--sign-key requires signing the image (--sign)")
+	}
+	if cmd.Flags().Changed("dockerfile") && c.Builder != builders.Dockerfile && c.Builder != builders.Kaniko {
+		return fmt.Errorf("--dockerfile requires a builder of Dockerfiles (--builder=%v or --builder=%v)", builders.Dockerfile, builders.Kaniko)
+	}
+	if c.Dockerfile != "" && !filepath.IsLocal(c.Dockerfile) {
+		return fmt.Errorf("invalid --dockerfile '%v'.  Must be a path relative to, and within, the function root", c.Dockerfile)
+	}
+	if _, _, err = util.OrderedMapAndRemovalListFromArray(c.BuildEnvs, "="); err != nil {
+		return fmt.Errorf("invalid --build-env: %w", err)
 	}
 	return
 }
@@ -468,12 +1022,6 @@ func (c buildConfig) Validate(cmd *cobra.Command) (err error) {
 // builder and pusher are the default implementations and the Pack and S2I
 // constructors simplified.
 //
-// TODO: Platform is currently only used by the S2I builder.  This should be
-// a multi-valued argument which passes through to the "host" builder (which
-// supports multi-arch/platform images), and throw an error if either trying
-// to specify a platform for buildpacks, or trying to specify more than one
-// for S2I.
-//
 // TODO: As a further optimization, it might be ideal to only build the
 // image necessary for the target cluster, since the end product of  a function
 // deployment is not the contiainer, but rather the running service.
@@ -488,18 +1036,58 @@ func (c buildConfig) clientOptions() ([]fn.Option, error) {
 			fn.WithPusher(oci.NewPusher(c.RegistryInsecure, false, c.Verbose,
 				oci.WithTransport(newTransport(c.RegistryInsecure)),
 				oci.WithCredentialsProvider(creds),
+				oci.WithPlainProgress(ciMode()),
+				oci.WithVerbose(c.Verbose))),
+		)
+	case builders.BuildKit:
+		// Images built by the daemon are written to the function as an OCI
+		// layout, and are therefore pushed as are those of the host builder.
+		t := newTransport(c.RegistryInsecure)
+		creds := newCredentialsProvider(config.Dir(), t)
+		o = append(o,
+			fn.WithBuilder(buildkit.NewBuilder(
+				buildkit.WithName(builders.BuildKit),
+				buildkit.WithAddress(c.BuildKitHost),
+				buildkit.WithPlainProgress(ciMode()),
+				buildkit.WithVerbose(c.Verbose))),
+			fn.WithPusher(oci.NewPusher(c.RegistryInsecure, false, c.Verbose,
+				oci.WithTransport(newTransport(c.RegistryInsecure)),
+				oci.WithCredentialsProvider(creds),
+				oci.WithPlainProgress(ciMode()),
 				oci.WithVerbose(c.Verbose))),
 		)
+	case builders.Dockerfile:
+		// Images built by the daemon are pushed from it, as are those of pack
+		// and s2i, by the default (docker) pusher.
+		o = append(o,
+			fn.WithBuilder(dockerfile.NewBuilder(
+				dockerfile.WithName(builders.Dockerfile),
+				dockerfile.WithVerbose(c.Verbose))))
+	case builders.Kaniko:
+		// Images are pushed as they are built, on the cluster, so the
+		// push only records the digest of that built.
+		t := newTransport(c.RegistryInsecure)
+		o = append(o,
+			fn.WithBuilder(kaniko.NewBuilder(
+				kaniko.WithName(builders.Kaniko),
+				kaniko.WithCredentialsProvider(newCredentialsProvider(config.Dir(), t)),
+				kaniko.WithVerbose(c.Verbose))),
+			fn.WithPusher(kaniko.NewPusher(builders.Kaniko)))
 	case builders.Pack:
 		o = append(o,
 			fn.WithBuilder(pack.NewBuilder(
 				pack.WithName(builders.Pack),
 				pack.WithTimestamp(c.WithTimestamp),
+				pack.WithPolicy(c.Builders[builders.Pack]),
+				pack.WithDependencies(c.Dependencies),
 				pack.WithVerbose(c.Verbose))))
 	case builders.S2I:
 		o = append(o,
 			fn.WithBuilder(s2i.NewBuilder(
 				s2i.WithName(builders.S2I),
+				s2i.WithPolicy(c.Builders[builders.S2I]),
+				s2i.WithDependencies(c.Dependencies),
+				s2i.WithIncremental(c.Incremental),
 				s2i.WithVerbose(c.Verbose))))
 	default:
 		return o, builders.ErrUnknownBuilder{Name: c.Builder, Known: KnownBuilders()}
@@ -513,17 +1101,34 @@ func (c buildConfig) buildOptions() (oo []fn.BuildOption, err error) {
 
 	// Platforms
 	//
-	// TODO: upgrade --platform to a multi-value field.  The individual builder
-	// implementations are responsible for bubbling an error if they do
-	// not support this.  Pack  supports none, S2I supports one, host builder
-	// supports multi.
-	if c.Platform != "" {
-		parts := strings.Split(c.Platform, "/")
-		if len(parts) != 2 {
-			return oo, fmt.Errorf("the value for --patform must be in the form [OS]/[Architecture].  eg \"linux/amd64\"")
-		}
-		oo = append(oo, fn.BuildWithPlatforms([]fn.Platform{{OS: parts[0], Architecture: parts[1]}}))
+	// The builders of a single platform at a time (pack and s2i) build the
+	// image of each in turn, those of several (host and buildkit) build them
+	// at once.
+	pp, err := c.platforms()
+	if err != nil {
+		return
+	}
+	if len(pp) > 0 {
+		oo = append(oo, fn.BuildWithPlatforms(pp))
 	}
 
 	return
 }
+
+// platforms of either --platform or --platforms, if any.
+func (c buildConfig) platforms() (pp []fn.Platform, err error) {
+	flag, value := "--platforms", c.Platforms
+	if c.Platform != "" {
+		flag, value = "--platform", c.Platform
+	}
+	if value == "" {
+		return
+	}
+	if pp, err = fn.ParsePlatforms(value); err != nil {
+		return nil, fmt.Errorf("invalid %v '%v'.  Must be in the form OS/ARCHITECTURE[/VARIANT], for example \"linux/amd64\"", flag, value)
+	}
+	if c.Platform != "" && len(pp) > 1 {
+		return nil, fmt.Errorf("invalid --platform '%v'.  Specify several platforms with --platforms", value)
+	}
+	return
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// buildAllFlags are those which may be given with --all, applying to every
+// function of the workspace.  Others define the state of a single function,
+// such as its image, and so are not.
+var buildAllFlags = []string{"all", "parallel", "builder", "registry", "registry-insecure",
+	"push", "platform", "platforms", "buildkit-host", "build-timestamp", "confirm", "path", "verbose"}
+
+// workspaceBuild is the result of the build of a function of a workspace.
+type workspaceBuild struct {
+	Path    string
+	Image   string
+	Elapsed time.Duration
+	Err     error
+}
+
+// runBuildAll builds each function of the workspace at --path, those of its
+// manifest or else those found beneath it, at most --parallel at once.  Each
+// is built as last configured, other than by the flags of buildAllFlags.
+// The output of builds run concurrently is retained, and written once each
+// completes with each line prefixed by the function's path, such that their
+// output is not interleaved.  Their results are written as a summary once all
+// are built, failing if any build failed.
+func runBuildAll(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	var invalid []string
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if !slices.Contains(buildAllFlags, flag.Name) {
+			invalid = append(invalid, "--"+flag.Name)
+		}
+	})
+	if len(invalid) > 0 {
+		return fmt.Errorf("%v may not be given with --all, being of a single function", strings.Join(invalid, ", "))
+	}
+	parallel := viper.GetInt("parallel")
+	if parallel < 1 {
+		return fmt.Errorf("invalid --parallel '%v'.  Must be at least 1", parallel)
+	}
+
+	root, err := filepath.Abs(viper.GetString("path"))
+	if err != nil {
+		return
+	}
+	ff, err := fn.WorkspaceFunctions(root)
+	if err != nil {
+		return
+	}
+	if len(ff) == 0 {
+		return fmt.Errorf("no functions were found in the workspace %v", root)
+	}
+
+	var (
+		results    = make([]workspaceBuild, len(ff))
+		slots      = make(chan struct{}, parallel)
+		concurrent = parallel > 1 && len(ff) > 1
+		mu         sync.Mutex // of messages, written as each build starts and ends
+		wg         sync.WaitGroup
+	)
+	for i, f := range ff {
+		results[i].Path, _ = filepath.Rel(root, f.Root)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			mu.Lock()
+			fmt.Fprintf(cmd.ErrOrStderr(), "Building %v\n", results[i].Path)
+			mu.Unlock()
+
+			ctx, out := cmd.Context(), &buildOutput{}
+			if concurrent {
+				ctx = context.WithValue(ctx, fn.BuildOutputKey{}, out)
+			}
+			start := time.Now()
+			f, err := buildWorkspaceFunction(ctx, cmd, newClient, f)
+			results[i].Image, results[i].Elapsed, results[i].Err = f.Build.Image, time.Since(start), err
+
+			mu.Lock()
+			out.writePrefixed(cmd.ErrOrStderr(), "["+results[i].Path+"] ")
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Failed to build %v: %v\n", results[i].Path, err)
+			} else {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Built %v in %v\n", results[i].Path, results[i].Elapsed.Round(time.Second))
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	writeWorkspaceBuilds(cmd.OutOrStdout(), results)
+	var failed int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d functions failed to build", failed, len(results))
+	}
+	return
+}
+
+// buildWorkspaceFunction builds, and pushes if requested, the function of a
+// workspace with its own client, as the builder of each may differ.
+func buildWorkspaceFunction(ctx context.Context, cmd *cobra.Command, newClient ClientFactory, f fn.Function) (fn.Function, error) {
+	cfg := buildConfig{
+		Global: config.Global{
+			Builder:          f.Build.Builder,
+			Registry:         f.Registry,
+			RegistryInsecure: viper.GetBool("registry-insecure"),
+			Verbose:          viper.GetBool("verbose"),
+			Builders:         builderPolicies(),
+			Dependencies:     dependencies(),
+		},
+		Path:          f.Root,
+		Platform:      viper.GetString("platform"),
+		Platforms:     viper.GetString("platforms"),
+		Push:          viper.GetBool("push"),
+		WithTimestamp: viper.GetBool("build-timestamp"),
+		Incremental:   f.Build.Incremental,
+		BuildKitHost:  viper.GetString("buildkit-host"),
+	}
+	if cfg.Builder == "" || cmd.Flags().Changed("builder") {
+		cfg.Builder = viper.GetString("builder")
+	}
+	if cfg.Builder == "" {
+		cfg.Builder = builders.Default
+	}
+	if cfg.Registry == "" || cmd.Flags().Changed("registry") {
+		cfg.Registry = registry()
+	}
+	if err := cfg.Validate(cmd); err != nil {
+		return f, err
+	}
+	f = cfg.Global.Configure(f)
+
+	clientOptions, err := cfg.clientOptions()
+	if err != nil {
+		return f, err
+	}
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure}, clientOptions...)
+	defer done()
+
+	buildOptions, err := cfg.buildOptions()
+	if err != nil {
+		return f, err
+	}
+	ctx = cfg.WithValues(ctx)
+	if f, err = client.Build(ctx, f, buildOptions...); err != nil {
+		return f, err
+	}
+	if cfg.Push {
+		if f, _, err = client.Push(ctx, f); err != nil {
+			return f, err
+		}
+	}
+	if err = f.Write(); err != nil {
+		return f, err
+	}
+	return f, f.Stamp()
+}
+
+// writeWorkspaceBuilds as a table of the result of each: its image if built,
+// or the error by which it failed.
+func writeWorkspaceBuilds(w io.Writer, results []workspaceBuild) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", "FUNCTION", "STATUS", "TIME", "DETAIL")
+	for _, r := range results {
+		status, detail := "built", r.Image
+		if r.Err != nil {
+			status, detail = "failed", r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Path, status, r.Elapsed.Round(time.Second), detail)
+	}
+}
+
+// buildOutput retains the output of a build, written concurrently by the
+// client and builder, for writing once the build completes.
+type buildOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *buildOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+// writePrefixed writes each line of the output retained to w, prefixed.
+func (o *buildOutput) writePrefixed(w io.Writer, prefix string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.buf.Len() == 0 {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(o.buf.String(), "\n"), "\n") {
+		fmt.Fprintf(w, "%v%v\n", prefix, line)
+	}
+}
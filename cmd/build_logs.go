@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/pipelines/tekton"
+)
+
+// ErrLocalBuildLogs indicates that the logs of local builds were requested,
+// which are printed as they run rather than retained.
+var ErrLocalBuildLogs = errors.New("only the logs of remote builds are retained. Use --remote")
+
+func NewBuildLogsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the logs of the most recent remote builds of a function",
+		Long: fmt.Sprintf(`Show the logs of the most recent remote builds of a function
+
+Shows the logs of each step of the function's most recent builds on the
+cluster, newest first, such as those of 'deploy --remote'.  The logs of the
+last %v remote builds of a function are retained on the cluster in config
+maps, such that they remain available once the pods which ran the builds are
+removed.  Long logs are retained as their last lines.
+
+Local builds print their logs as they run, and are not retained.
+`, tekton.BuildLogsRetained),
+		Example: `
+# Show the logs of the last remote build of the function in the current directory
+{{rootCmdUse}} build logs --remote
+
+# Show the logs of the last three remote builds
+{{rootCmdUse}} build logs --remote --last 3
+`,
+		PreRunE: bindEnv("remote", "last", "output", "path"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runBuildLogs(cmd)
+		},
+	}
+
+	cmd.Flags().Bool("remote", false, "Show the logs of builds on the cluster. ($FUNC_REMOTE)")
+	cmd.Flags().Int("last", 1, "Number of the most recent builds whose logs are shown, 0 being all retained. ($FUNC_LAST)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+
+	return cmd
+}
+
+func runBuildLogs(cmd *cobra.Command) error {
+	if !viper.GetBool("remote") {
+		return ErrLocalBuildLogs
+	}
+	last := viper.GetInt("last")
+	if last < 0 {
+		return fmt.Errorf("--last may not be negative, got %v", last)
+	}
+	output := viper.GetString("output")
+	if output != "human" && output != "json" {
+		return fmt.Errorf("unsupported output format %q. Accepts 'human' or 'json'", output)
+	}
+
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return err
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	if f.Deploy.Namespace == "" {
+		return ErrNotDeployed
+	}
+
+	ll, err := tekton.BuildLogs(cmd.Context(), f.Name, f.Deploy.Namespace, last)
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(ll)
+	}
+	if len(ll) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "No remote builds of %v have been retained\n", f.Name)
+		return nil
+	}
+	writeBuildLogs(cmd.OutOrStdout(), ll, time.Now())
+	return nil
+}
+
+// writeBuildLogs of each build, with a heading per build and step.
+func writeBuildLogs(w io.Writer, ll []tekton.BuildLog, now time.Time) {
+	for i, l := range ll {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		status := "succeeded"
+		if !l.Succeeded {
+			status = "failed"
+		}
+		fmt.Fprintf(w, "Build %v %v", l.Run, status)
+		if !l.Started.IsZero() {
+			fmt.Fprintf(w, " %v ago", now.Sub(l.Started).Round(time.Second))
+			if !l.Completed.IsZero() {
+				fmt.Fprintf(w, " after %v", l.Completed.Sub(l.Started).Round(time.Second))
+			}
+		}
+		fmt.Fprintln(w)
+		if !l.Succeeded && l.Message != "" {
+			fmt.Fprintf(w, "  %v\n", l.Message)
+		}
+		for _, s := range l.Steps {
+			fmt.Fprintf(w, "\n--- %v/%v\n", s.Task, s.Step)
+			fmt.Fprint(w, s.Log)
+			if s.Log != "" && !strings.HasSuffix(s.Log, "\n") {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/pipelines/tekton"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestBuildLogs_Remote ensures that only the logs of remote builds of a
+// deployed function may be shown.
+func TestBuildLogs_Remote(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBuildCmd(NewTestClient())
+	cmd.SetArgs([]string{"logs"})
+	if err := cmd.Execute(); !errors.Is(err, ErrLocalBuildLogs) {
+		t.Fatalf("expected ErrLocalBuildLogs, got %v", err)
+	}
+
+	cmd = NewBuildCmd(NewTestClient())
+	cmd.SetArgs([]string{"logs", "--remote"})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+}
+
+// TestBuildLogs_Write ensures that the logs of each step are written under
+// a heading, with the message of a failed build.
+func TestBuildLogs_Write(t *testing.T) {
+	now := time.Now()
+	var b bytes.Buffer
+	writeBuildLogs(&b, []tekton.BuildLog{{
+		Run:       "myfn-run-2",
+		Started:   now.Add(-time.Hour),
+		Completed: now.Add(-time.Hour + 2*time.Minute),
+		Message:   "Tasks Completed: 2 (Failed: 1)",
+		Steps: []tekton.BuildStepLog{
+			{Task: "fetch-sources", Step: "clone", Log: "cloned\n"},
+			{Task: "build", Step: "build", Log: "error: cannot compile"},
+		},
+	}, {
+		Run:       "myfn-run-1",
+		Succeeded: true,
+	}}, now)
+
+	out := b.String()
+	for _, want := range []string{
+		"Build myfn-run-2 failed 1h0m0s ago after 2m0s\n  Tasks Completed: 2 (Failed: 1)\n",
+		"--- fetch-sources/clone\ncloned\n",
+		"--- build/build\nerror: cannot compile\n",
+		"Build myfn-run-1 succeeded\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%v", want, out)
+		}
+	}
+}
@@ -1,9 +1,25 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
 	. "knative.dev/func/pkg/testing"
@@ -95,8 +111,8 @@ func TestBuild_Authentication(t *testing.T) {
 	testAuthentication(NewBuildCmd, t)
 }
 
-// TestBuild_BaseImage ensures that base image is used only with the right
-// builders and propagates into f.Build.BaseImage
+// TestBuild_BaseImage ensures that base and run images are accepted by all
+// builders and propagate into f.Build.BaseImage and f.Build.RunImage
 func TestBuild_BaseImage(t *testing.T) {
 	testBaseImage(NewBuildCmd, t)
 }
@@ -158,3 +174,687 @@ func TestBuild_Push(t *testing.T) {
 		t.Fatal("push should not be invoked on a failed build")
 	}
 }
+
+// TestBuild_Platforms ensures that the function is pushed as built for the
+// platforms of --platforms, and that conflicting or malformed platforms are
+// rejected.
+func TestBuild_Platforms(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var pushed []fn.Platform
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(_ context.Context, f fn.Function) (string, error) {
+		pushed = f.Build.Platforms
+		return "", nil
+	}
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithPusher(pusher)))
+
+	cmd.SetArgs([]string{"--platforms", "linux/amd64,linux/arm64", "--push"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	want := []fn.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}
+	if !reflect.DeepEqual(pushed, want) {
+		t.Fatalf("expected the platforms %v pushed, got %v", want, pushed)
+	}
+	if f, _ = fn.NewFunction(root); !reflect.DeepEqual(f.Build.Platforms, want) {
+		t.Fatalf("expected the built platforms %v recorded, got %v", want, f.Build.Platforms)
+	}
+
+	for _, args := range [][]string{
+		{"--platforms", "linux"},
+		{"--platform", "linux/amd64,linux/arm64"},
+		{"--platform", "linux/amd64", "--platforms", "linux/arm64"},
+	} {
+		cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected an error building with %v", args)
+		}
+	}
+}
+
+// TestBuild_SBOM ensures that --sbom generates an SBOM of the given format,
+// which is persisted, and that --sbom-output writes the generated SBOM.
+func TestBuild_SBOM(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	generator := mock.NewSBOMGenerator()
+	generator.GenerateFn = func(_ context.Context, f fn.Function, w io.Writer) error {
+		_, err := fmt.Fprint(w, f.Build.SBOM)
+		return err
+	}
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithSBOMGenerator(generator)))
+	cmd.SetArgs([]string{"--sbom", "spdx", "--sbom-output", "sbom.spdx.json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "sbom.spdx.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "spdx" {
+		t.Fatalf("expected the SBOM written, got %q", data)
+	}
+	if f, _ = fn.NewFunction(root); f.Build.SBOM != fn.SBOMFormatSPDX {
+		t.Fatalf("expected the SBOM format persisted, got %q", f.Build.SBOM)
+	}
+
+	for _, args := range [][]string{
+		{"--sbom", "syft"},
+		{"--sbom", "", "--sbom-output", "sbom.json"},
+	} {
+		cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithSBOMGenerator(generator)))
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected an error building with %v", args)
+		}
+	}
+}
+
+// TestBuild_Sign ensures that --sign signs the pushed image with the key of
+// --sign-key, persisting both and recording the reference of the signature.
+func TestBuild_Sign(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := mock.NewSigner()
+	signer.SignFn = func(_ context.Context, f fn.Function) (string, error) {
+		if f.Build.Sign == nil || f.Build.Sign.Key != "cosign.key" {
+			t.Errorf("expected the image signed with cosign.key, got %+v", f.Build.Sign)
+		}
+		return "example.com/alice/myfunc:sha256-0000.sig", nil
+	}
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithPusher(mock.NewPusher()), fn.WithSigner(signer)))
+	cmd.SetArgs([]string{"--push", "--sign", "--sign-key", "cosign.key"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !signer.SignInvoked {
+		t.Fatal("expected the image to be signed")
+	}
+	f, _ = fn.NewFunction(root)
+	if f.Build.Sign == nil || f.Build.Sign.Key != "cosign.key" {
+		t.Fatalf("expected the signing key persisted, got %+v", f.Build.Sign)
+	}
+	if f.Build.Signature != "example.com/alice/myfunc:sha256-0000.sig" {
+		t.Fatalf("expected the signature recorded, got %q", f.Build.Signature)
+	}
+
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithSigner(signer)))
+	cmd.SetArgs([]string{"--sign=false", "--sign-key", "cosign.key"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error giving --sign-key without --sign")
+	}
+}
+
+// TestBuild_BuildpacksOptions ensures that the pack builder's builder image,
+// run image, trust of the builder image and build environment variables are
+// persisted from flags, and build environment variables removed with a "-".
+func TestBuild_BuildpacksOptions(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--builder", "pack", "--builder-image", "example.com/builder", "--run-image", "example.com/run",
+		"--trust-builder", "--build-env", "BP_GO_VERSION=1.23", "--build-env", "BP_KEEP_FILES=static/*"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = fn.NewFunction(root)
+	if f.Build.BuilderImages["pack"] != "example.com/builder" || f.Build.RunImage != "example.com/run" {
+		t.Fatalf("expected the builder and run images persisted, got %v and %v", f.Build.BuilderImages, f.Build.RunImage)
+	}
+	if f.Build.TrustBuilder == nil || !*f.Build.TrustBuilder {
+		t.Fatal("expected the builder image trusted")
+	}
+	if len(f.Build.BuildEnvs) != 2 {
+		t.Fatalf("expected two build envs, got %v", f.Build.BuildEnvs)
+	}
+
+	// Values not given are retained, and build envs removed with a "-".
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--build-env", "BP_KEEP_FILES-"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = fn.NewFunction(root)
+	if f.Build.TrustBuilder == nil || !*f.Build.TrustBuilder {
+		t.Fatal("expected the builder image to remain trusted")
+	}
+	if len(f.Build.BuildEnvs) != 1 || *f.Build.BuildEnvs[0].Name != "BP_GO_VERSION" {
+		t.Fatalf("expected BP_KEEP_FILES removed, got %v", f.Build.BuildEnvs)
+	}
+
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--trust-builder=false"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ = fn.NewFunction(root); f.Build.TrustBuilder == nil || *f.Build.TrustBuilder {
+		t.Fatal("expected the builder image explicitly untrusted")
+	}
+}
+
+// TestBuild_Incremental ensures that --incremental is persisted as the
+// function's build.incremental, and disabled with --incremental=false.
+func TestBuild_Incremental(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "node", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--builder", "s2i", "--incremental"}, true},
+		{[]string{}, true},
+		{[]string{"--incremental=false"}, false},
+	} {
+		cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+		cmd.SetArgs(tc.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if f, _ = fn.NewFunction(root); f.Build.Incremental != tc.want {
+			t.Fatalf("%v: expected build.incremental %v, got %v", tc.args, tc.want, f.Build.Incremental)
+		}
+	}
+}
+
+// TestBuild_Dockerfile ensures that --dockerfile is persisted as the
+// function's build.dockerfile, and that it requires the dockerfile builder.
+func TestBuild_Dockerfile(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--builder", "pack", "--dockerfile", "docker/Dockerfile"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --dockerfile to be rejected with the pack builder")
+	}
+
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--builder", "dockerfile", "--dockerfile", "../Dockerfile"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected a Dockerfile outside the function to be rejected")
+	}
+
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--builder", "dockerfile", "--dockerfile", "docker/Dockerfile"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = fn.NewFunction(root)
+	if f.Build.Builder != "dockerfile" || f.Build.Dockerfile != "docker/Dockerfile" {
+		t.Fatalf("expected the dockerfile builder and Dockerfile persisted, got %q and %q", f.Build.Builder, f.Build.Dockerfile)
+	}
+}
+
+// TestBuild_Output ensures that --output is validated, and that the image
+// of the last build is written to the archive.
+func TestBuild_Output(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--output", "function.tar"},
+		{"--output", "zip:function.tar"},
+		{"--builder", "kaniko", "--output", "oci:function.tar"},
+	} {
+		cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("expected %v to be rejected", args)
+		}
+	}
+
+	// The image of the host builder is the OCI layout of its last build
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := layout.Write(filepath.Join(root, fn.RunDataDir, "builds", "last", "oci"), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--builder", "host", "--output", "docker-archive:function.tar"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	written, err := tarball.ImageFromPath(filepath.Join(root, "function.tar"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := img.Digest()
+	if got, _ := written.Digest(); got != want {
+		t.Fatalf("expected the image %v written, got %v", want, got)
+	}
+}
+
+// TestBuild_TagStrategy ensures that the image is tagged by --tag-strategy,
+// or the expressions of --image, the strategy and tag being recorded.
+func TestBuild_TagStrategy(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--tag-strategy", "branch"},
+		{"--image", "example.com/alice/myfunc:{{git.author}}"},
+	} {
+		cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("expected %v to be rejected", args)
+		}
+	}
+
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--tag-strategy", "timestamp"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.TagStrategy != fn.TagStrategyTimestamp || f.Deploy.Tag == "" {
+		t.Fatalf("expected the tag strategy and tag recorded, got %q and %q", f.Build.TagStrategy, f.Deploy.Tag)
+	}
+	if !strings.HasSuffix(f.Build.Image, ":"+f.Deploy.Tag) {
+		t.Fatalf("expected the image built with the tag %q, got %q", f.Deploy.Tag, f.Build.Image)
+	}
+
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--image", "example.com/alice/myfunc:build-{{timestamp}}"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.Image != "example.com/alice/myfunc:"+f.Deploy.Tag || !strings.HasPrefix(f.Deploy.Tag, "build-") {
+		t.Fatalf("expected the expressions of the image expanded, got %q (tag %q)", f.Build.Image, f.Deploy.Tag)
+	}
+}
+
+// TestBuild_Scan ensures the image built is scanned with --scan, that the
+// build fails if it has vulnerabilities of the severity or above, and that
+// the report is written to --scan-output either way.
+func TestBuild_Scan(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Name: "myfunc", Runtime: "go", Registry: "example.com/alice"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"--scanner", "grype"},
+		{"--scan", "--scanner", "clair"},
+		{"--scan", "--scan-severity", "severe"},
+		{"--scan", "--builder", "kaniko"},
+	} {
+		cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())))
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil {
+			t.Fatalf("expected %v to be rejected", args)
+		}
+	}
+
+	// The image scanned is that of the last build of the host builder
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := layout.Write(filepath.Join(root, fn.RunDataDir, "builds", "last", "oci"), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+	scanner := mock.NewScanner()
+	scanner.ScanFn = func(_ context.Context, _ fn.Function, path string) (fn.ScanReport, error) {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected the image archive to be scanned: %v", err)
+		}
+		return fn.ScanReport{Vulnerabilities: []fn.Vulnerability{{ID: "CVE-2024-0001", Package: "openssl", Severity: "high"}}}, nil
+	}
+
+	cmd := NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithScanner(scanner)))
+	cmd.SetArgs([]string{"--builder", "host", "--scan", "--scan-severity", "critical", "--scan-output", "scan.json"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !scanner.ScanInvoked {
+		t.Fatal("expected the image to be scanned")
+	}
+	if f, _ = fn.NewFunction(root); f.Build.Scan == nil || f.Build.Scan.Severity != "critical" {
+		t.Fatalf("expected the scan to be persisted, got %+v", f.Build.Scan)
+	}
+
+	cmd = NewBuildCmd(NewTestClient(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithScanner(scanner)))
+	cmd.SetArgs([]string{"--scan-severity", "high", "--scan-output", "scan.json"})
+	if err = cmd.Execute(); !errors.Is(err, fn.ErrVulnerable) {
+		t.Fatalf("expected ErrVulnerable, got %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "scan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r fn.ScanReport
+	if err = json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Threshold != "high" || len(r.Vulnerabilities) != 1 || r.Vulnerabilities[0].ID != "CVE-2024-0001" {
+		t.Fatalf("unexpected report %+v", r)
+	}
+}
+
+// TestBuild_ProgressJSON ensures that with --progress json the stages of
+// the build and push are written to stdout alone, as JSON objects, and that
+// the progress of the push of each layer is reported by quarters.
+func TestBuild_ProgressJSON(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry}); err != nil {
+		t.Fatal(err)
+	}
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(ctx context.Context, f fn.Function) (string, error) {
+		layerProgress := fn.NewPushProgress(ctx, f.Build.Image)
+		for _, complete := range []int64{0, 10, 50, 100} {
+			layerProgress.Update("sha256:a", complete, 100)
+		}
+		return "sha256:1111111111111111111111111111111111111111111111111111111111111111", nil
+	}
+	var stdout bytes.Buffer
+	cmd := NewBuildCmd(NewTestClient(fn.WithPusher(pusher)))
+	cmd.SetArgs([]string{"--push", "--progress", "json"})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		stages   []fn.DeployStage
+		complete []int64
+	)
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var e progressEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("expected only JSON events on stdout, got %q", stdout.String())
+		}
+		if e.Time.IsZero() {
+			t.Errorf("expected the time of %v", e.Stage)
+		}
+		switch e.Stage {
+		case fn.StagePhase:
+			if e.Phase != fn.PhasePush {
+				t.Errorf("expected the push phase, got %q", e.Phase)
+			}
+		case fn.StagePushProgress:
+			if e.Layer != "sha256:a" || e.Total != 100 {
+				t.Errorf("unexpected push progress %+v", e.DeployEvent)
+			}
+			complete = append(complete, e.Complete)
+		}
+		stages = append(stages, e.Stage)
+	}
+	expected := []fn.DeployStage{fn.StageBuildStarted, fn.StageBuilt, fn.StagePhase,
+		fn.StagePushProgress, fn.StagePushProgress, fn.StagePushProgress, fn.StageImagePushed}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Fatalf("expected stages %v, got %v", expected, stages)
+	}
+	if !reflect.DeepEqual(complete, []int64{0, 50, 100}) {
+		t.Fatalf("expected the progress of each quarter pushed, got %v", complete)
+	}
+
+	cmd = NewBuildCmd(NewTestClient())
+	cmd.SetArgs([]string{"--progress", "xml"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--progress") {
+		t.Fatalf("expected an error of an unknown progress format, got %v", err)
+	}
+}
+
+// TestBuild_All ensures that --all builds each function of the workspace,
+// summarizing the result of each and failing if any failed, and that flags
+// of a single function are rejected.
+func TestBuild_All(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	for _, name := range []string{"a", "b", "c"} {
+		f := fn.Function{Root: filepath.Join(root, name), Name: name, Runtime: "go", Registry: TestRegistry}
+		if _, err := fn.New().Init(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, fn.WorkspaceFile), []byte("functions:\n- a\n- b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewBuildCmd(NewTestClient(fn.WithBuilder(mock.NewBuilder())))
+	cmd.SetArgs([]string{"--all", "--image", "example.com/alice/f:latest"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--image") {
+		t.Fatalf("expected --image to be rejected with --all, got %v", err)
+	}
+
+	var (
+		mu    sync.Mutex
+		built []string
+	)
+	builder := mock.NewBuilder()
+	builder.BuildFn = func(f fn.Function) error {
+		mu.Lock()
+		defer mu.Unlock()
+		built = append(built, f.Name)
+		if f.Name == "b" {
+			return errors.New("build failed")
+		}
+		return nil
+	}
+	var stdout bytes.Buffer
+	cmd = NewBuildCmd(NewTestClient(fn.WithBuilder(builder)))
+	cmd.SetArgs([]string{"--all", "--parallel", "2"})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "1 of 2 functions failed") {
+		t.Fatalf("expected the failure of one function, got %v", err)
+	}
+	slices.Sort(built)
+	if !reflect.DeepEqual(built, []string{"a", "b"}) {
+		t.Fatalf("expected the functions of the manifest to be built, got %v", built)
+	}
+	lines := strings.Split(stdout.String(), "\n") // followed by the usage
+	if len(lines) < 3 || !strings.Contains(lines[1], "built") || !strings.HasPrefix(lines[1], "a ") ||
+		!strings.Contains(lines[2], "build failed") || !strings.HasPrefix(lines[2], "b ") {
+		t.Fatalf("unexpected summary:\n%v", stdout.String())
+	}
+	if f, _ := fn.NewFunction(filepath.Join(root, "a")); !f.Built() {
+		t.Errorf("expected function a to be built")
+	}
+}
+
+// TestBuild_AllOutput ensures that the output of functions built concurrently
+// by --all is written per function, each line prefixed by its path, rather
+// than interleaved.
+func TestBuild_AllOutput(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	for _, name := range []string{"a", "b"} {
+		f := fn.Function{Root: filepath.Join(root, name), Name: name, Runtime: "go", Registry: TestRegistry}
+		if _, err := fn.New().Init(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each build writes a line, waits until the other has also, and writes
+	// another, such that their output would otherwise be interleaved.
+	var started sync.WaitGroup
+	started.Add(2)
+	builder := outputBuilder(func(ctx context.Context, f fn.Function) error {
+		out := fn.BuildOutput(ctx, os.Stderr)
+		fmt.Fprintf(out, "first of %v\n", f.Name)
+		started.Done()
+		started.Wait()
+		fmt.Fprintf(out, "second of %v\n", f.Name)
+		return nil
+	})
+	var stderr bytes.Buffer
+	cmd := NewBuildCmd(NewTestClient(fn.WithBuilder(builder)))
+	cmd.SetArgs([]string{"--all", "--parallel", "2"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(&stderr)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		expected := fmt.Sprintf("[%v] first of %v\n[%v] second of %v\n", name, name, name, name)
+		if !strings.Contains(stderr.String(), expected) {
+			t.Fatalf("expected the output of %v to be written together, prefixed, got:\n%v", name, stderr.String())
+		}
+	}
+}
+
+// outputBuilder is a builder which builds by invoking itself.
+type outputBuilder func(context.Context, fn.Function) error
+
+func (b outputBuilder) Build(ctx context.Context, f fn.Function, _ []fn.Platform) error {
+	return b(ctx, f)
+}
+
+// TestBuild_ShowContext ensures that --show-context lists the files of the
+// function's build context, honoring its .funcignore, without building.
+func TestBuild_ShowContext(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Runtime: "go"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".funcignore"), []byte("*.md\n!README.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"README.md", "NOTES.md"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	builder := mock.NewBuilder()
+	cmd := NewBuildCmd(NewTestClient(fn.WithBuilder(builder)))
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--show-context"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if builder.BuildInvoked {
+		t.Error("the function should not be built when showing its context")
+	}
+
+	files := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !slices.Contains(files, "README.md") || !slices.Contains(files, "func.yaml") {
+		t.Errorf("expected README.md and func.yaml in the context, got %v", files)
+	}
+	if slices.Contains(files, "NOTES.md") || slices.Contains(files, ".funcignore") {
+		t.Errorf("expected NOTES.md and .funcignore to be excluded, got %v", files)
+	}
+}
+
+// TestBuild_ShowContextBuilder ensures that --show-context lists the build
+// context as sent by the selected builder, less the files it excludes.
+func TestBuild_ShowContextBuilder(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f := fn.Function{Root: root, Runtime: "node"}
+	if _, err := fn.New().Init(f); err != nil {
+		t.Fatal(err)
+	}
+	// node_modules, ignored by git, is included by the function such that
+	// only the builders which exclude it do so.
+	if err := os.WriteFile(filepath.Join(root, ".funcignore"), []byte("!node_modules\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "node_modules", "dep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"node_modules/dep/index.js", ".envrc"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		builder  string
+		excluded []string
+		included []string
+	}{
+		{builder: "pack", included: []string{"node_modules/dep/index.js", ".envrc"}},
+		{builder: "host", excluded: []string{"node_modules/dep/index.js"}, included: []string{".envrc"}},
+		{builder: "s2i", excluded: []string{"node_modules/dep/index.js", ".envrc"}},
+	}
+	for _, test := range tests {
+		t.Run(test.builder, func(t *testing.T) {
+			cmd := NewBuildCmd(NewTestClient())
+			out := bytes.Buffer{}
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"--show-context", "--builder", test.builder})
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			files := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if !slices.Contains(files, "func.yaml") {
+				t.Errorf("expected func.yaml in the context, got %v", files)
+			}
+			for _, name := range test.included {
+				if !slices.Contains(files, name) {
+					t.Errorf("expected %v in the context, got %v", name, files)
+				}
+			}
+			for _, name := range test.excluded {
+				if slices.Contains(files, name) {
+					t.Errorf("expected %v to be excluded, got %v", name, files)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Export and import function projects as a single archive",
+		Long: `
+NAME
+	{{rootCmdUse}} bundle - Export and import function projects as a single archive
+
+SYNOPSIS
+	{{rootCmdUse}} bundle export [-o|--output] [--include-image] [-p|--path]
+
+	{{rootCmdUse}} bundle import <archive> [-p|--path]
+
+DESCRIPTION
+	Packages a function's project into a single archive, or bundle, which
+	may be shared with others, such as when reporting an issue or handing a
+	function to another team, and reconstructs a working project from it.
+
+	A bundle is a gzipped tar archive containing the function's source and
+	its func.yaml, as well as a manifest recording the function's runtime,
+	how it is invoked, the template and repository from which it was
+	created, and the versions of func and of the function's specification
+	with which it was exported.  Files ignored by the
+	function's .gitignore, such as dependencies and build outputs, and its
+	local runtime metadata (.func) are not included.
+
+	With --include-image, the reference of the image last built of the
+	function is included, and is recorded as built when the bundle is
+	imported, such that the function may be deployed without building.
+
+	A bundle is imported into a new directory, named for its function by
+	default, which must be empty or not yet exist.
+`,
+		Example: `
+# Export the function in the current directory to <name>.tar.gz
+{{rootCmdUse}} bundle export
+
+# Export the function, including its built image, to a given file
+{{rootCmdUse}} bundle export --include-image --output /tmp/myfunc.tar.gz
+
+# Import a bundle into ./myfunc
+{{rootCmdUse}} bundle import myfunc.tar.gz
+`,
+	}
+
+	cmd.AddCommand(NewBundleExportCmd())
+	cmd.AddCommand(NewBundleImportCmd())
+
+	return cmd
+}
+
+func NewBundleExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a function's project as a bundle",
+		Long: `Export a function's project as a bundle
+
+Writes the function's source, func.yaml and a manifest describing it to a
+single archive.  See '{{rootCmdUse}} bundle --help' for details.
+`,
+		PreRunE: bindEnv("output", "include-image", "path"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runBundleExport(cmd)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "", "Path of the archive to write. Defaults to <name>.tar.gz in the current directory. ($FUNC_OUTPUT)")
+	cmd.Flags().Bool("include-image", false, "Include the reference of the image last built of the function. ($FUNC_INCLUDE_IMAGE)")
+	addPathFlag(cmd)
+
+	return cmd
+}
+
+func runBundleExport(cmd *cobra.Command) (err error) {
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+
+	output := viper.GetString("output")
+	if output == "" {
+		output = f.Name + ".tar.gz"
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return
+	}
+	// The archive would otherwise be included in itself.
+	if rel, err := filepath.Rel(f.Root, output); err == nil && filepath.IsLocal(rel) {
+		return fmt.Errorf("the bundle may not be written within the function, choose another --output")
+	}
+
+	// Written in full before creating the archive, such that a failed export
+	// does not leave a partial archive behind.
+	var b bytes.Buffer
+	m, err := fn.ExportBundle(f, &b, viper.GetBool("include-image"))
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(output, b.Bytes(), 0644); err != nil {
+		return
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported function %v (%v) to %v\n", m.Name, m.Runtime, output)
+	if m.Image != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Including image %v\n", m.Image)
+	}
+	return nil
+}
+
+func NewBundleImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <archive>",
+		Short: "Import a function's project from a bundle",
+		Long: `Import a function's project from a bundle
+
+Reconstructs the function's project of a bundle in a new directory.  See
+'{{rootCmdUse}} bundle --help' for details.
+`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: bindEnv("path"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBundleImport(cmd, args[0])
+		},
+	}
+
+	cmd.Flags().StringP("path", "p", "", "Path into which the function is imported. Defaults to a directory named for the function. ($FUNC_PATH)")
+
+	return cmd
+}
+
+func runBundleImport(cmd *cobra.Command, archive string) (err error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// The bundle's function name is known only once read, so a default path
+	// is decided by importing to a temporary directory alongside it.
+	root := viper.GetString("path")
+	target := root
+	if root == "" {
+		if root, err = os.MkdirTemp(".", ".func-bundle"); err != nil {
+			return
+		}
+		defer os.RemoveAll(root)
+	}
+
+	f, m, err := fn.ImportBundle(file, root)
+	if err != nil {
+		return
+	}
+	if target == "" {
+		if target = m.Name; target == "" {
+			return fmt.Errorf("the bundle does not name its function, provide --path")
+		}
+		if _, err = os.Stat(target); err == nil {
+			return fmt.Errorf("'%v' already exists, provide --path to import elsewhere", target)
+		}
+		if err = os.Rename(root, target); err != nil {
+			return
+		}
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Imported function %v (%v) to %v\n", f.Name, f.Runtime, target)
+	if m.Template != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Created from template %v of %v\n", m.Template, m.Repository)
+	} else if m.Repository != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Created from %v\n", m.Repository)
+	}
+	if m.FuncVersion != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported with func %v\n", m.FuncVersion)
+	}
+	if m.Image != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Built image %v\n", m.Image)
+	}
+	return nil
+}
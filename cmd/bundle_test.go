@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestBundle_ExportImport ensures that a function exported as a bundle is
+// imported into a directory named for the function by default.
+func TestBundle_ExportImport(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	cmd := NewBundleCmd()
+	cmd.SetArgs([]string{"export", "--output", bundle})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Imported from elsewhere, into ./<name>
+	dir := t.TempDir()
+	t.Chdir(dir)
+	cmd = NewBundleCmd()
+	cmd.SetArgs([]string{"import", bundle})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	g, err := fn.NewFunction(filepath.Join(dir, f.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Initialized() || g.Name != f.Name {
+		t.Fatalf("expected function %q to be imported, got %q", f.Name, g.Name)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(dir, ".func-bundle*")); len(entries) > 0 {
+		t.Fatalf("expected temporary directories to be removed, got %v", entries)
+	}
+
+	// The default directory, of the function's name, now exists.
+	cmd = NewBundleCmd()
+	cmd.SetArgs([]string{"import", bundle})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error importing over an existing directory")
+	}
+}
+
+// TestBundle_ExportWithin ensures that a bundle may not be written within the
+// function it bundles.
+func TestBundle_ExportWithin(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewBundleCmd()
+	cmd.SetArgs([]string{"export"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error writing the bundle within the function")
+	}
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewCacheCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and prune the build caches of a function",
+		Long: `
+NAME
+	{{rootCmdUse}} cache - Inspect and prune the build caches of a function
+
+SYNOPSIS
+	{{rootCmdUse}} cache [-o|--output] [-p|--path] [-v|--verbose]
+	{{rootCmdUse}} cache prune [--older-than] [--shared] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Lists the caches retained between builds of the function, with the
+	builder which retains each, its size and when it was last modified.
+	Caches of every builder are listed, such that those of a builder no
+	longer used by the function can be found and pruned.
+
+	  pack  The volumes of the build and launch layers of the function's
+	        image, and the builder and lifecycle images.
+	  s2i   The builder image of the function's runtime.
+	  host  The base layers cached in .func/blob-cache, and the builds in
+	        .func/builds other than the last.
+
+	Builder images are shared by all functions, and are marked as such.
+
+	Pruning
+	  'prune' removes the function's caches, or with --older-than only those
+	  not modified within that long, such as "30d" or "12h".  Shared caches
+	  are removed only with --shared.  A pruned cache is rebuilt, or its image
+	  pulled, by the next build which needs it.
+`,
+		Example: `
+# List the build caches of the function in the current directory
+{{rootCmdUse}} cache
+
+# Remove the function's caches not modified within 30 days
+{{rootCmdUse}} cache prune --older-than 30d
+
+# Remove all of the function's caches, including the builder images
+{{rootCmdUse}} cache prune --shared
+`,
+		Aliases: []string{"caches"},
+		PreRunE: bindEnv("output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCache(cmd, newClient)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|yaml|csv). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, false)
+
+	cmd.AddCommand(NewCachePruneCmd(newClient))
+
+	return cmd
+}
+
+func NewCachePruneCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the build caches of a function",
+		Long: `Remove the build caches of a function
+
+Removes the function's build caches not modified within --older-than, or all
+if not provided.  Caches shared by all functions, such as builder images, are
+removed only with --shared.  See '{{rootCmdUse}} cache --help' for details.
+`,
+		PreRunE: bindEnv("older-than", "shared", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCachePrune(cmd, newClient)
+		},
+	}
+
+	cmd.Flags().String("older-than", "", "Remove only caches not modified within this long, such as 30d or 12h. ($FUNC_OLDER_THAN)")
+	cmd.Flags().Bool("shared", false, "Also remove caches shared by all functions, such as builder images. ($FUNC_SHARED)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, false)
+
+	return cmd
+}
+
+func runCache(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose")})
+	defer done()
+
+	cc, err := client.Caches(cmd.Context(), f)
+	if err != nil {
+		return
+	}
+	write(cmd.OutOrStdout(), buildCaches(cc), viper.GetString("output"))
+	return
+}
+
+func runCachePrune(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	olderThan, err := parseAge(viper.GetString("older-than"))
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose")})
+	defer done()
+
+	removed, err := client.PruneCaches(cmd.Context(), f, olderThan, viper.GetBool("shared"))
+	var freed int64
+	for _, c := range removed {
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %v %v\n", c.Kind, c.Name)
+		if c.Size > 0 {
+			freed += c.Size
+		}
+	}
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %v caches, freeing %v\n", len(removed), formatSize(freed))
+	return
+}
+
+// parseAge of a duration, which may be given in whole days, such as "30d"
+// or "1d12h".  An empty value is zero.
+func parseAge(v string) (d time.Duration, err error) {
+	if v == "" {
+		return 0, nil
+	}
+	if days, rest, ok := strings.Cut(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a duration such as 30d or 12h", v)
+		}
+		d, v = time.Duration(n)*24*time.Hour, rest
+		if v == "" {
+			return d, nil
+		}
+	}
+	rest, err := time.ParseDuration(v)
+	if err != nil || rest < 0 {
+		return 0, fmt.Errorf("%q is not a duration such as 30d or 12h", v)
+	}
+	return d + rest, nil
+}
+
+// formatSize in bytes with decimal units, or "-" if not known.
+func formatSize(n int64) string {
+	if n < 0 {
+		return "-"
+	}
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(1000), 0
+	for m := n / 1000; m >= 1000; m /= 1000 {
+		div *= 1000
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type buildCaches []fn.BuildCache
+
+func (items buildCaches) Human(w io.Writer) error {
+	if len(items) == 0 {
+		_, err := fmt.Fprintln(w, "No build caches found")
+		return err
+	}
+	var total int64
+	for _, item := range items {
+		if item.Size > 0 {
+			total += item.Size
+		}
+	}
+	if err := items.Plain(w); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%v caches, %v in total\n", len(items), formatSize(total))
+	return err
+}
+
+func (items buildCaches) Plain(w io.Writer) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", "BUILDER", "KIND", "NAME", "SIZE", "MODIFIED")
+	for _, item := range items {
+		kind := item.Kind
+		if item.Shared {
+			kind += " (shared)"
+		}
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", item.Builder, kind, item.Name, formatSize(item.Size), formatModified(item.Modified))
+	}
+	return nil
+}
+
+func (items buildCaches) JSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func (items buildCaches) XML(w io.Writer) error {
+	return errors.New("xml is not supported for build caches")
+}
+
+func (items buildCaches) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(items)
+}
+
+func (items buildCaches) URL(w io.Writer) error {
+	return errors.New("url is not supported for build caches")
+}
+
+func (items buildCaches) CSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"builder", "kind", "name", "size", "modified", "shared"})
+	for _, item := range items {
+		_ = cw.Write([]string{item.Builder, item.Kind, item.Name, strconv.FormatInt(item.Size, 10), formatModified(item.Modified), strconv.FormatBool(item.Shared)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatModified time, or "-" if not known.
+func formatModified(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
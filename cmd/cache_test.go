@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestCache_List ensures that the build caches of the function are listed.
+func TestCache_List(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cacher := mock.NewBuildCacher(
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheVolume, Name: "pack-cache-f", Size: 1500000, Modified: time.Now()},
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheImage, Name: "builder", Size: -1, Shared: true})
+
+	cmd := NewCacheCmd(NewTestClient(fn.WithBuildCachers(cacher)))
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"pack-cache-f", "1.5 MB", "image (shared)", "2 caches, 1.5 MB in total"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in the listing, got:\n%v", want, out.String())
+		}
+	}
+
+	cmd = NewCacheCmd(NewTestClient(fn.WithBuildCachers(cacher)))
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"-o", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var cc []fn.BuildCache
+	if err := json.Unmarshal(out.Bytes(), &cc); err != nil {
+		t.Fatal(err)
+	}
+	if len(cc) != 2 || !cc[0].Shared {
+		t.Fatalf("unexpected caches %+v", cc)
+	}
+}
+
+// TestCache_Prune ensures that only the function's caches older than
+// --older-than are removed, shared caches only with --shared, and that
+// invalid durations are rejected.
+func TestCache_Prune(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-31 * 24 * time.Hour)
+	cacher := mock.NewBuildCacher(
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheVolume, Name: "old", Size: 10, Modified: old},
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheVolume, Name: "new", Size: 10, Modified: time.Now()},
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheImage, Name: "builder", Size: 10, Modified: old, Shared: true})
+
+	cmd := NewCachePruneCmd(NewTestClient(fn.WithBuildCachers(cacher)))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--older-than", "30d"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cacher.Removed) != 1 || cacher.Removed[0].Name != "old" {
+		t.Fatalf("expected only the old cache removed, got %+v", cacher.Removed)
+	}
+
+	cmd = NewCachePruneCmd(NewTestClient(fn.WithBuildCachers(cacher)))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--older-than", "30d", "--shared"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cacher.Retained) != 1 || cacher.Retained[0].Name != "new" {
+		t.Fatalf("expected the shared cache removed, retained %+v", cacher.Retained)
+	}
+
+	cmd = NewCachePruneCmd(NewTestClient(fn.WithBuildCachers(cacher)))
+	cmd.SetArgs([]string{"--older-than", "a month"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid --older-than")
+	}
+}
+
+func TestCache_ParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":      0,
+		"30d":   30 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"90m":   90 * time.Minute,
+	}
+	for v, want := range tests {
+		if d, err := parseAge(v); err != nil || d != want {
+			t.Errorf("parseAge(%q) = %v, %v; expected %v", v, d, err, want)
+		}
+	}
+	for _, v := range []string{"d", "-1d", "30x", "-5h"} {
+		if _, err := parseAge(v); err == nil {
+			t.Errorf("expected an error parsing %q", v)
+		}
+	}
+}
@@ -6,16 +6,25 @@ import (
 	"os"
 
 	"knative.dev/func/cmd/prompt"
+	"knative.dev/func/pkg/artifacts"
+	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/builders/buildpacks"
+	"knative.dev/func/pkg/builders/s2i"
 	"knative.dev/func/pkg/config"
+	"knative.dev/func/pkg/cosign"
 	"knative.dev/func/pkg/creds"
 	"knative.dev/func/pkg/docker"
 	fn "knative.dev/func/pkg/functions"
 	fnhttp "knative.dev/func/pkg/http"
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/knative"
+	"knative.dev/func/pkg/metrics"
 	"knative.dev/func/pkg/oci"
 	"knative.dev/func/pkg/pipelines/tekton"
+	"knative.dev/func/pkg/policy"
+	"knative.dev/func/pkg/sbom"
+	"knative.dev/func/pkg/scan"
+	"knative.dev/func/pkg/version"
 )
 
 // ClientConfig settings for use with NewClient
@@ -28,6 +37,11 @@ type ClientConfig struct {
 
 	// Allow insecure server connections when using SSL
 	InsecureSkipVerify bool
+
+	// Deployer of functions: "knative", the default, or "k8s", deploying
+	// them as plain Deployments, with which they are also listed, described
+	// and removed.
+	Deployer string
 }
 
 // ClientFactory defines a constructor which assists in the creation of a Client
@@ -60,7 +74,12 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 		c  = newCredentialsProvider(config.Dir(), t) // for accessing registries
 		d  = newKnativeDeployer(cfg.Verbose)
 		pp = newTektonPipelinesProvider(c, cfg.Verbose)
-		o  = []fn.Option{ // standard (shared) options for all commands
+		p  = docker.NewPusher(
+			docker.WithCredentialsProvider(c),
+			docker.WithTransport(t),
+			docker.WithPlainProgress(ciMode()),
+			docker.WithVerbose(cfg.Verbose))
+		o = []fn.Option{ // standard (shared) options for all commands
 			fn.WithVerbose(cfg.Verbose),
 			fn.WithTransport(t),
 			fn.WithRepositoriesPath(config.RepositoriesPath()),
@@ -68,15 +87,49 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 			fn.WithRemover(knative.NewRemover(cfg.Verbose)),
 			fn.WithDescriber(knative.NewDescriber(cfg.Verbose)),
 			fn.WithLister(knative.NewLister(cfg.Verbose)),
+			fn.WithMetricsProvider(metrics.NewProvider(metrics.WithVerbose(cfg.Verbose))),
 			fn.WithDeployer(d),
+			fn.WithDiffer(d),
 			fn.WithPipelinesProvider(pp),
-			fn.WithPusher(docker.NewPusher(
-				docker.WithCredentialsProvider(c),
-				docker.WithTransport(t),
-				docker.WithVerbose(cfg.Verbose))),
+			fn.WithPusher(p),
+			fn.WithDigestResolver(p),
+			fn.WithTagLister(p),
+			fn.WithAttacher(artifacts.NewAttacher(
+				artifacts.WithCredentialsProvider(c),
+				artifacts.WithTransport(t),
+				artifacts.WithVerbose(cfg.Verbose))),
+			fn.WithHookRunner(k8s.NewHookRunner()),
+			fn.WithPolicy(policy.New(config.PolicyPaths()...)),
+			fn.WithVerifier(cosign.NewVerifier(
+				cosign.WithCredentialsProvider(c),
+				cosign.WithTransport(t),
+				cosign.WithVerbose(cfg.Verbose))),
+			fn.WithSigner(cosign.NewSigner(
+				cosign.WithCredentialsProvider(c),
+				cosign.WithTransport(t),
+				cosign.WithVerbose(cfg.Verbose))),
+			fn.WithSBOMGenerator(sbom.NewGenerator(
+				sbom.WithToolVersion(version.Vers))),
+			fn.WithScanner(scan.NewScanner(
+				scan.WithVerbose(cfg.Verbose))),
+			fn.WithBuildCachers(
+				buildpacks.NewBuilder(buildpacks.WithVerbose(cfg.Verbose)),
+				s2i.NewBuilder(s2i.WithVerbose(cfg.Verbose)),
+				oci.NewBuilder(builders.Host, cfg.Verbose)),
 		}
 	)
 
+	// The k8s deployer's counterparts replace those of Knative
+	if cfg.Deployer == fn.DeployerK8s {
+		kd := k8s.NewDeployer(k8s.WithDeployerVerbose(cfg.Verbose), k8s.WithDeployerDecorator(deployDecorator{}))
+		o = append(o,
+			fn.WithDeployer(kd),
+			fn.WithDiffer(kd),
+			fn.WithRemover(k8s.NewRemover(cfg.Verbose)),
+			fn.WithDescriber(k8s.NewDescriber(cfg.Verbose)),
+			fn.WithLister(k8s.NewLister(cfg.Verbose)))
+	}
+
 	// Client is constructed with standard options plus any additional options
 	// which either augment or override the defaults.
 	client := fn.New(append(o, options...)...)
@@ -92,6 +145,16 @@ func NewClient(cfg ClientConfig, options ...fn.Option) (*fn.Client, func()) {
 	return client, cleanup
 }
 
+// deployerAt the path, being that with which the function there, if any, is
+// deployed.  Empty is the default.
+func deployerAt(path string) string {
+	f, err := fn.NewFunction(path)
+	if err != nil {
+		return ""
+	}
+	return f.Deploy.Deployer
+}
+
 // newTransport returns a transport with cluster-flavor-specific variations
 // which take advantage of additional features offered by cluster variants.
 func newTransport(insecureSkipVerify bool) fnhttp.RoundTripCloser {
@@ -106,11 +169,15 @@ func newCredentialsProvider(configPath string, t http.RoundTripper) oci.Credenti
 	additionalLoaders = append(additionalLoaders, k8s.GetECRCredentialLoader()...)
 	additionalLoaders = append(additionalLoaders, k8s.GetACRCredentialLoader()...)
 	options := []creds.Opt{
-		creds.WithPromptForCredentials(prompt.NewPromptForCredentials(os.Stdin, os.Stdout, os.Stderr)),
-		creds.WithPromptForCredentialStore(prompt.NewPromptForCredentialStore()),
 		creds.WithTransport(t),
 		creds.WithAdditionalCredentialLoaders(additionalLoaders...),
 	}
+	// In CI mode, missing credentials are an error rather than prompted for.
+	if !ciMode() {
+		options = append(options,
+			creds.WithPromptForCredentials(prompt.NewPromptForCredentials(os.Stdin, os.Stdout, os.Stderr)),
+			creds.WithPromptForCredentialStore(prompt.NewPromptForCredentialStore()))
+	}
 
 	// Other cluster variants can be supported here
 	return creds.NewCredentialsProvider(configPath, options...)
@@ -120,16 +187,19 @@ func newTektonPipelinesProvider(creds oci.CredentialsProvider, verbose bool) *te
 	options := []tekton.Opt{
 		tekton.WithCredentialsProvider(creds),
 		tekton.WithVerbose(verbose),
+		tekton.WithPlainProgress(ciMode()),
 		tekton.WithPipelineDecorator(deployDecorator{}),
+		tekton.WithBuilderPolicies(builderPolicies()),
 	}
 
 	return tekton.NewPipelinesProvider(options...)
 }
 
-func newKnativeDeployer(verbose bool) fn.Deployer {
+func newKnativeDeployer(verbose bool) *knative.Deployer {
 	options := []knative.DeployerOpt{
 		knative.WithDeployerVerbose(verbose),
 		knative.WithDeployerDecorator(deployDecorator{}),
+		knative.WithDeployerOpenShift(k8s.IsOpenShift),
 	}
 
 	return knative.NewDeployer(options...)
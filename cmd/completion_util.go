@@ -17,7 +17,7 @@ import (
 func CompleteFunctionList(cmd *cobra.Command, args []string, toComplete string) (strings []string, directive cobra.ShellCompDirective) {
 	lister := knative.NewLister(false)
 
-	list, err := lister.List(cmd.Context(), "")
+	list, err := lister.List(cmd.Context(), "", "")
 	if err != nil {
 		directive = cobra.ShellCompDirectiveError
 		return
@@ -75,7 +75,32 @@ func CompleteTemplateList(cmd *cobra.Command, args []string, toComplete string,
 
 func CompleteOutputFormatList(cmd *cobra.Command, args []string, toComplete string) (strings []string, directive cobra.ShellCompDirective) {
 	directive = cobra.ShellCompDirectiveDefault
-	strings = []string{"plain", "yaml", "xml", "json"}
+	strings = []string{"plain", "yaml", "yaml=v1", "xml", "json", "json=v1", "ndjson", "csv"}
+	return
+}
+
+// CompleteListOutputFormatList completes the output formats of 'list', which
+// include wide.
+func CompleteListOutputFormatList(cmd *cobra.Command, args []string, toComplete string) (strings []string, directive cobra.ShellCompDirective) {
+	strings, directive = CompleteOutputFormatList(cmd, args, toComplete)
+	strings = append(strings, "wide")
+	return
+}
+
+func CompleteListSortByList(cmd *cobra.Command, args []string, toComplete string) (strings []string, directive cobra.ShellCompDirective) {
+	directive = cobra.ShellCompDirectiveNoFileComp
+	strings = listSortKeys
+	return
+}
+
+// CompleteListColumnsList completes the columns of 'list', following those
+// already given.
+func CompleteListColumnsList(cmd *cobra.Command, args []string, toComplete string) (columns []string, directive cobra.ShellCompDirective) {
+	directive = cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	prefix := toComplete[:strings.LastIndexByte(toComplete, ',')+1]
+	for _, c := range listColumnNames {
+		columns = append(columns, prefix+c)
+	}
 	return
 }
 
@@ -74,6 +74,9 @@ or from the directory specified with --path.
 }
 
 func runConfigCmd(cmd *cobra.Command, args []string) (err error) {
+	if ciMode() {
+		return fmt.Errorf("%w: use one of the 'config' subcommands", ErrPromptCI)
+	}
 
 	function, err := initConfigCommand(defaultLoaderSaver)
 	if err != nil {
@@ -211,6 +211,9 @@ func listEnvs(f fn.Function, w io.Writer, outputFormat Format) error {
 }
 
 func runAddEnvsPrompt(ctx context.Context, f fn.Function) (err error) {
+	if ciMode() {
+		return ErrPromptCI
+	}
 
 	insertToIndex := 0
 
@@ -488,6 +491,9 @@ func runAddEnvsPrompt(ctx context.Context, f fn.Function) (err error) {
 }
 
 func runRemoveEnvsPrompt(f fn.Function) (err error) {
+	if ciMode() {
+		return ErrPromptCI
+	}
 	if len(f.Run.Envs) == 0 {
 		fmt.Println("There aren't any configured Environment variables")
 		return
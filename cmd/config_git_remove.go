@@ -91,6 +91,9 @@ func newConfigGitRemoveConfig(_ *cobra.Command) (c configGitRemoveConfig) {
 func (c configGitRemoveConfig) Prompt(f fn.Function) (configGitRemoveConfig, error) {
 	deleteAll := true
 	// prompt if any flag hasn't been set yet
+	if !c.flagSet && ciMode() {
+		return c, fmt.Errorf("%w: specify the resources to remove", ErrPromptCI)
+	}
 	if !c.flagSet {
 		if err := survey.AskOne(&survey.Confirm{
 			Message: "Do you want to delete all Git related resources?",
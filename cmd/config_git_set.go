@@ -152,6 +152,15 @@ func (c configGitSetConfig) Prompt(f fn.Function) (configGitSetConfig, error) {
 		return c, err
 	}
 
+	// In CI mode only the repository URL is required, with the remaining
+	// settings taking their defaults rather than being prompted for.
+	if ciMode() {
+		if c.GitURL == "" {
+			return c, fmt.Errorf("%w: --git-url", ErrPromptCI)
+		}
+		return c, nil
+	}
+
 	// try to read git url from the local .git settings
 	gitInfo := pacgit.GetGitInfo(c.Path)
 
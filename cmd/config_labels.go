@@ -3,9 +3,11 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ory/viper"
@@ -47,14 +49,21 @@ If label is not set explicitly by flag, interactive prompt is used.
 
 The label can be set directly from a value or from an environment variable on
 the local machine.
+
+The label is of both the function's service and the template of its revisions
+unless scoped to either with --scope, such as to label only the service with
+its cost center.
 `,
 		Example: `# set label directly
 {{rootCmdUse}} config labels add --name=Foo --value=Bar
 
 # set label from local env $FOO
-{{rootCmdUse}} config labels add --name=Foo --value='{{"{{"}} env:FOO {{"}}"}}'`,
+{{rootCmdUse}} config labels add --name=Foo --value='{{"{{"}} env:FOO {{"}}"}}'
+
+# set label of only the service
+{{rootCmdUse}} config labels add --name=cost-center --value=Bar --scope=service`,
 		SuggestFor: []string{"ad", "create", "insert", "append"},
-		PreRunE:    bindEnv("path", "name", "value", "verbose"),
+		PreRunE:    bindEnv("path", "name", "scope", "value", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			function, err := initConfigCommand(loaderSaver)
 			if err != nil {
@@ -79,6 +88,8 @@ the local machine.
 				vp = &s
 			}
 
+			scope := viper.GetString("scope")
+
 			if np != nil && vp != nil {
 				if err := utils.ValidateLabelKey(*np); err != nil {
 					return err
@@ -86,8 +97,12 @@ the local machine.
 				if err := utils.ValidateLabelValue(*vp); err != nil {
 					return err
 				}
+				label := fn.Label{Key: np, Value: vp, Scope: scope}
+				if errs := fn.ValidateLabels([]fn.Label{label}); len(errs) > 0 {
+					return errors.New(strings.Join(errs, " "))
+				}
 
-				function.Deploy.Labels = append(function.Deploy.Labels, fn.Label{Key: np, Value: vp})
+				function.Deploy.Labels = append(function.Deploy.Labels, label)
 				return loaderSaver.Save(function)
 			}
 
@@ -145,6 +160,7 @@ directory or from the directory specified with --path.
 	configLabelsCmd.Flags().StringP("output", "o", "human", "Output format (human|json)")
 	configLabelsAddCmd.Flags().StringP("name", "", "", "Name of the label.")
 	configLabelsAddCmd.Flags().StringP("value", "", "", "Value of the label.")
+	configLabelsAddCmd.Flags().StringP("scope", "", "", fmt.Sprintf("Scope of the label. [%v|%v|%v] (default %v)", fn.ScopeBoth, fn.ScopeService, fn.ScopeRevision, fn.ScopeBoth))
 	configLabelsRemoveCmd.Flags().StringP("name", "", "", "Name of the label.")
 
 	addPathFlag(configLabelsCmd)
@@ -185,6 +201,9 @@ func listLabels(f fn.Function, w io.Writer, outputFormat Format) error {
 }
 
 func runAddLabelsPrompt(_ context.Context, f fn.Function, saver functionSaver) (err error) {
+	if ciMode() {
+		return ErrPromptCI
+	}
 
 	insertToIndex := 0
 
@@ -318,6 +337,9 @@ func runAddLabelsPrompt(_ context.Context, f fn.Function, saver functionSaver) (
 }
 
 func runRemoveLabelsPrompt(f fn.Function, saver functionSaver) (err error) {
+	if ciMode() {
+		return ErrPromptCI
+	}
 	if len(f.Deploy.Labels) == 0 {
 		fmt.Println("There aren't any configured labels")
 		return
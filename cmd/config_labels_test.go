@@ -197,3 +197,23 @@ func TestListLabels(t *testing.T) {
 	}
 
 }
+
+// TestConfigLabelsAdd_Scope ensures that a label added with --scope is of the
+// scope, and that an unknown scope is an error.
+func TestConfigLabelsAdd_Scope(t *testing.T) {
+	var loaderSaver mockFunctionLoaderSaver
+
+	cmd := NewConfigLabelsCmd(&loaderSaver)
+	cmd.SetArgs([]string{"add", "--name", "cost-center", "--value", "finance", "--scope", "service"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	k, v := "cost-center", "finance"
+	assertLabelEq(t, loaderSaver.f.Deploy.Labels, []fn.Label{{Key: &k, Value: &v, Scope: fn.ScopeService}})
+
+	cmd = NewConfigLabelsCmd(&loaderSaver)
+	cmd.SetArgs([]string{"add", "--name", "team", "--value", "a", "--scope", "pod"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error of an unknown scope")
+	}
+}
@@ -81,9 +81,14 @@ For non-interactive usage, use flags to specify the volume type and configuratio
 
 # Add an EmptyDir volume
 {{rootCmdUse}} config volumes add --type=emptydir --path=/tmp/cache
-{{rootCmdUse}} config volumes add --type=emptydir --path=/tmp/cache --size=1Gi --medium=Memory`,
+{{rootCmdUse}} config volumes add --type=emptydir --path=/tmp/cache --size=1Gi --medium=Memory
+
+# Add a projected volume of a Secret, a ConfigMap and a service account token
+{{rootCmdUse}} config volumes add --type=projected --mount-path=/etc/projected \
+  --projection=secret:my-secret --projection=configmap:my-config \
+  --projection=token:vault-token:vault`,
 		SuggestFor: []string{"ad", "create", "insert", "append"},
-		PreRunE:    bindEnv("path", "verbose", "type", "source", "mount-path", "read-only", "size", "medium"),
+		PreRunE:    bindEnv("path", "verbose", "type", "source", "mount-path", "read-only", "size", "medium", "projection"),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			function, err := initConfigCommand(defaultLoaderSaver)
 			if err != nil {
@@ -102,12 +107,13 @@ For non-interactive usage, use flags to specify the volume type and configuratio
 	}
 
 	// Add flags for non-interactive mode
-	cmd.Flags().StringP("type", "t", "", "Volume type: configmap, secret, pvc, or emptydir")
+	cmd.Flags().StringP("type", "t", "", "Volume type: configmap, secret, pvc, emptydir, or projected")
 	cmd.Flags().StringP("source", "s", "", "Name of the ConfigMap, Secret, or PVC to mount (not used for emptydir)")
 	cmd.Flags().StringP("mount-path", "m", "", "Path where the volume should be mounted in the container")
 	cmd.Flags().BoolP("read-only", "r", false, "Mount volume as read-only (only for PVC)")
 	cmd.Flags().StringP("size", "", "", "Maximum size limit for EmptyDir volume (e.g., 1Gi)")
 	cmd.Flags().StringP("medium", "", "", "Storage medium for EmptyDir volume: 'Memory' or '' (default)")
+	cmd.Flags().StringArray("projection", []string{}, "Source of a projected volume: secret:NAME, configmap:NAME or token:PATH[:AUDIENCE]. May be given more than once.")
 
 	return cmd
 }
@@ -164,6 +170,9 @@ func listVolumes(f fn.Function) {
 }
 
 func runAddVolumesPrompt(ctx context.Context, f fn.Function) (err error) {
+	if ciMode() {
+		return ErrPromptCI
+	}
 
 	secrets, err := k8s.ListSecretsNamesIfConnected(ctx, f.Deploy.Namespace)
 	if err != nil {
@@ -290,6 +299,9 @@ func runAddVolumesPrompt(ctx context.Context, f fn.Function) (err error) {
 }
 
 func runRemoveVolumesPrompt(f fn.Function) (err error) {
+	if ciMode() {
+		return ErrPromptCI
+	}
 	if len(f.Run.Volumes) == 0 {
 		fmt.Println("There aren't any configured Volume mounts")
 		return
@@ -334,12 +346,13 @@ func runRemoveVolumesPrompt(f fn.Function) (err error) {
 // runAddVolume handles adding volumes using command line flags
 func runAddVolume(cmd *cobra.Command, f fn.Function) error {
 	var (
-		volumeType, _ = cmd.Flags().GetString("type")
-		source, _     = cmd.Flags().GetString("source")
-		mountPath, _  = cmd.Flags().GetString("mount-path")
-		readOnly, _   = cmd.Flags().GetBool("read-only")
-		sizeLimit, _  = cmd.Flags().GetString("size")
-		medium, _     = cmd.Flags().GetString("medium")
+		volumeType, _  = cmd.Flags().GetString("type")
+		source, _      = cmd.Flags().GetString("source")
+		mountPath, _   = cmd.Flags().GetString("mount-path")
+		readOnly, _    = cmd.Flags().GetBool("read-only")
+		sizeLimit, _   = cmd.Flags().GetString("size")
+		medium, _      = cmd.Flags().GetString("medium")
+		projections, _ = cmd.Flags().GetStringArray("projection")
 	)
 
 	// Validate mount path
@@ -353,8 +366,8 @@ func runAddVolume(cmd *cobra.Command, f fn.Function) error {
 	// Create the volume based on type
 	newVolume := fn.Volume{Path: &mountPath}
 
-	// All volumeTypes except emptydir require a source
-	if volumeType != "emptydir" && source == "" {
+	// All volumeTypes except emptydir and projected require a source
+	if volumeType != "emptydir" && volumeType != "projected" && source == "" {
 		return fmt.Errorf("--source is required for %s volumes", volumeType)
 	}
 
@@ -385,13 +398,29 @@ func runAddVolume(cmd *cobra.Command, f fn.Function) error {
 		}
 		newVolume.EmptyDir = emptyDir
 		fmt.Fprintf(cmd.OutOrStderr(), "Please make sure to enable the EmptyDir extension flag:\nhttps://knative.dev/docs/serving/configuration/feature-flags/\n")
+	case "projected":
+		if len(projections) == 0 {
+			return fmt.Errorf("--projection is required for projected volumes")
+		}
+		projected := &fn.Projected{}
+		for _, p := range projections {
+			s, err := parseProjection(p)
+			if err != nil {
+				return err
+			}
+			projected.Sources = append(projected.Sources, s)
+		}
+		newVolume.Projected = projected
 
 	default:
-		return fmt.Errorf("invalid volume type: %s (must be one of: configmap, secret, pvc, emptydir)", volumeType)
+		return fmt.Errorf("invalid volume type: %s (must be one of: configmap, secret, pvc, emptydir, projected)", volumeType)
 	}
 
 	// Add the volume to the function
 	f.Run.Volumes = append(f.Run.Volumes, newVolume)
+	if err := f.Validate(); err != nil { // such as a token of an absolute path
+		return err
+	}
 
 	// Save the function
 	err := f.Write()
@@ -431,3 +460,22 @@ func runRemoveVolume(cmd *cobra.Command, f fn.Function, mountPath string) error
 	}
 	return err
 }
+
+// parseProjection of the form secret:NAME, configmap:NAME or
+// token:PATH[:AUDIENCE] as a source of a projected volume.
+func parseProjection(p string) (fn.ProjectedSource, error) {
+	kind, value, _ := strings.Cut(p, ":")
+	if value == "" {
+		return fn.ProjectedSource{}, fmt.Errorf("invalid projection %q: expected secret:NAME, configmap:NAME or token:PATH[:AUDIENCE]", p)
+	}
+	switch kind {
+	case "secret":
+		return fn.ProjectedSource{Secret: &value}, nil
+	case "configmap":
+		return fn.ProjectedSource{ConfigMap: &value}, nil
+	case "token":
+		path, audience, _ := strings.Cut(value, ":")
+		return fn.ProjectedSource{ServiceAccountToken: &fn.ServiceAccountToken{Path: path, Audience: audience}}, nil
+	}
+	return fn.ProjectedSource{}, fmt.Errorf("invalid projection %q: expected secret:NAME, configmap:NAME or token:PATH[:AUDIENCE]", p)
+}
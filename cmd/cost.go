@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/cost"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewCostCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Estimate the cost of running a function",
+		Long: `
+NAME
+	{{rootCmdUse}} cost - Estimate the cost of running a function
+
+SYNOPSIS
+	{{rootCmdUse}} cost estimate [--pricing] [--rate] [--window] [--duration]
+	             [-o|--output] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Estimates the monthly cost of running a function as configured, given
+	the rate at which it is invoked and a pricing table of the platform on
+	which it runs.
+
+	The resources of each instance are those requested by the function's
+	options.resources (or its limits, if no requests are configured), and
+	the number of instances running on average is derived from the request
+	rate, the duration of each request and the function's options.scale.  A
+	function which scales to zero is presumed to be running for a minute
+	after each request.  Values the function does not configure are assumed,
+	and listed with the estimate.
+
+	Observed Request Rates
+	  If the function is deployed, and the metrics of its invocations are
+	  available from Prometheus on the cluster (see '{{rootCmdUse}} describe'),
+	  its cost is estimated at the request rate observed over the --window,
+	  by default the last day, in place of the rates configured.  The
+	  estimate is for the namespace to which it is deployed, at the prices of
+	  the pricing table's environment of that name, if any.  Otherwise, or
+	  with --rate, the rates configured are used.
+
+	Pricing Tables
+	  A pricing table is a YAML file with the price of a vCPU-second, a
+	  GiB-second of memory and a million requests, and optionally the
+	  environments in which the function runs, each with its expected request
+	  rate and, if they differ, its own prices:
+
+	    currency: USD
+	    rates:
+	      cpuSecond: 0.000024
+	      memoryGiBSecond: 0.0000025
+	      millionRequests: 0.40
+	    environments:
+	      - name: staging
+	        requestsPerSecond: 0.5
+	      - name: production
+	        requestsPerSecond: 40
+
+	  A cost is estimated for each environment.  Without environments, or with
+	  --rate, the given rate is used.  Without a pricing table, illustrative
+	  prices are used which are not those of any particular platform.
+`,
+		Example: `
+# Estimate the monthly cost of the function at 5 requests per second
+{{rootCmdUse}} cost estimate --rate 5
+
+# Estimate the cost of the deployed function at the rate observed this week
+{{rootCmdUse}} cost estimate --window 168h
+
+# Estimate the cost in each environment of a pricing table
+{{rootCmdUse}} cost estimate --pricing pricing.yaml
+`,
+	}
+
+	cmd.AddCommand(NewCostEstimateCmd(newClient))
+
+	return cmd
+}
+
+func NewCostEstimateCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "estimate",
+		Short: "Estimate the monthly cost of a function",
+		Long: `Estimate the monthly cost of a function
+
+Estimates the monthly cost of the function as configured, in each environment
+of the pricing table.  See '{{rootCmdUse}} cost --help' for details.
+`,
+		PreRunE: bindEnv("pricing", "rate", "window", "duration", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runCostEstimate(cmd, newClient)
+		},
+	}
+
+	cmd.Flags().String("pricing", "", "Path to a pricing table. Defaults to illustrative prices. ($FUNC_PRICING)")
+	cmd.Flags().Float64("rate", 1, "Requests per second, on average. Overrides the rates of the pricing table's environments if provided. ($FUNC_RATE)")
+	cmd.Flags().Duration("window", costObservedWindow, "Window over which the request rate of the deployed function is observed, if its metrics are available. ($FUNC_WINDOW)")
+	cmd.Flags().Duration("duration", 0, "Duration of a request, on average. Defaults to 100ms. ($FUNC_DURATION)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, false)
+
+	return cmd
+}
+
+// costObservedWindow is the default window over which the request rate of a
+// deployed function is observed.
+const costObservedWindow = 24 * time.Hour
+
+func runCostEstimate(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	output := viper.GetString("output")
+	if output != "human" && output != "json" {
+		return fmt.Errorf("unsupported output format %q. Accepts 'human' or 'json'", output)
+	}
+	rate := viper.GetFloat64("rate")
+	if rate < 0 {
+		return fmt.Errorf("--rate may not be negative, got %v", rate)
+	}
+	window := viper.GetDuration("window")
+	if window <= 0 {
+		return fmt.Errorf("--window must be positive, got %v", window)
+	}
+
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+
+	pricing := cost.DefaultPricing
+	if path := viper.GetString("pricing"); path != "" {
+		if pricing, err = cost.LoadPricing(path); err != nil {
+			return
+		}
+	}
+	// The rate given explicitly applies to every environment.  Otherwise that
+	// observed of the deployed function is used, if available.
+	explicit := cmd.Flags().Changed("rate") || os.Getenv("FUNC_RATE") != ""
+	var observed *cost.Observation
+	if !explicit && f.Deploy.Namespace != "" {
+		observed = observeRequests(cmd, newClient, f, window)
+	}
+	if observed != nil {
+		pricing = pricing.Observed(observed.Namespace, observed.RequestsPerSecond)
+	} else if len(pricing.Environments) == 0 {
+		pricing.Environments = []cost.Environment{{Name: "default", RequestsPerSecond: rate}}
+	} else if explicit {
+		for i := range pricing.Environments {
+			pricing.Environments[i].RequestsPerSecond = rate
+		}
+	}
+
+	usage, err := cost.NewUsage(f, viper.GetDuration("duration"))
+	if err != nil {
+		return
+	}
+	report := pricing.Estimate(f.Name, usage)
+	report.Observed = observed
+
+	if output == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	writeCostReport(cmd.OutOrStdout(), report, viper.GetString("pricing") == "")
+	return nil
+}
+
+// observeRequests made of the deployed function over the window, or nil if
+// its metrics are not available.
+func observeRequests(cmd *cobra.Command, newClient ClientFactory, f fn.Function, window time.Duration) *cost.Observation {
+	verbose := viper.GetBool("verbose")
+	client, done := newClient(ClientConfig{Verbose: verbose})
+	defer done()
+	m := functionMetrics(cmd, client, f.Name, f.Deploy.Namespace, window, verbose)
+	if m == nil {
+		return nil
+	}
+	o := cost.NewObservation(f.Deploy.Namespace, window, m.Requests)
+	return &o
+}
+
+// writeCostReport in human-readable form, noting if the prices are the
+// illustrative defaults.
+func writeCostReport(w io.Writer, r cost.Report, illustrative bool) {
+	fmt.Fprintf(w, "Estimated monthly cost of %v (%v)\n\n", r.Function, r.Currency)
+	if o := r.Observed; o != nil {
+		fmt.Fprintf(w, "At the rate observed in %v over the last %v: %g requests.\n\n", o.Namespace, o.Window, o.Requests)
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENVIRONMENT\tREQ/S\tINSTANCES\tCPU\tMEMORY\tREQUESTS\tTOTAL")
+	for _, e := range r.Estimates {
+		fmt.Fprintf(tw, "%v\t%g\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n",
+			e.Environment, e.RequestsPerSecond, e.Instances, e.CPU, e.Memory, e.Requests, e.Total)
+	}
+	tw.Flush()
+
+	u := r.Usage
+	max := "unbounded"
+	if u.MaxScale > 0 {
+		max = fmt.Sprint(u.MaxScale)
+	}
+	fmt.Fprintf(w, "\nPer instance: %g vCPU, %.3g GiB memory, %g concurrent requests of %v. Scale: %v to %v instances.\n",
+		u.CPU, u.MemoryGiB, u.Concurrency, u.RequestDuration, u.MinScale, max)
+	if len(u.Assumptions) > 0 {
+		fmt.Fprintln(w, "Assumed:")
+		for _, a := range u.Assumptions {
+			fmt.Fprintf(w, "  o %v\n", a)
+		}
+	}
+	if illustrative {
+		fmt.Fprintln(w, "Prices are illustrative. Provide the prices of your platform with --pricing.")
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"knative.dev/func/pkg/cost"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestCostEstimate ensures that the cost of a function is estimated at the
+// given rate, with the assumptions made and the prices noted as
+// illustrative when no pricing table is provided.
+func TestCostEstimate(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := NewCostCmd(NewTestClient())
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"estimate", "--rate=5"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"ENVIRONMENT", "default", "Assumed:", "Prices are illustrative"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected output to contain %q:\n%v", s, out.String())
+		}
+	}
+}
+
+// TestCostEstimate_Environments ensures that a cost is estimated for each
+// environment of a pricing table.
+func TestCostEstimate_Environments(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	pricing := filepath.Join(t.TempDir(), "pricing.yaml")
+	if err := os.WriteFile(pricing, []byte(`
+rates: {cpuSecond: 0.00001, memoryGiBSecond: 0.000001, millionRequests: 1}
+environments:
+  - {name: staging, requestsPerSecond: 0.1}
+  - {name: production, requestsPerSecond: 50}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := NewCostCmd(NewTestClient())
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"estimate", "--pricing", pricing, "--output=json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var r cost.Report
+	if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Estimates) != 2 || r.Estimates[0].Environment != "staging" || r.Estimates[1].RequestsPerSecond != 50 {
+		t.Fatalf("unexpected estimates %+v", r.Estimates)
+	}
+	if r.Estimates[1].Total <= r.Estimates[0].Total {
+		t.Fatalf("expected production to cost more than staging, got %+v", r.Estimates)
+	}
+}
+
+// TestCostEstimate_Observed ensures that the cost of a deployed function is
+// estimated at the request rate observed, if its metrics are available, and
+// otherwise, or with --rate, at the rates configured.
+func TestCostEstimate_Observed(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "prod"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := mock.NewMetricsProvider()
+	provider.MetricsFn = func(_ context.Context, name, namespace string, window time.Duration) (fn.Metrics, error) {
+		if name != f.Name || namespace != "prod" || window != time.Hour {
+			t.Errorf("unexpected query of %v in %v over %v", name, namespace, window)
+		}
+		return fn.Metrics{Window: window, Requests: 7200}, nil
+	}
+	estimate := func(provider fn.MetricsProvider, args ...string) (r cost.Report) {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewCostCmd(NewTestClient(fn.WithMetricsProvider(provider)))
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"estimate", "--window=1h", "--output=json"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(out.Bytes(), &r); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	r := estimate(provider)
+	if r.Observed == nil || len(r.Estimates) != 1 || r.Estimates[0].Environment != "prod" || r.Estimates[0].RequestsPerSecond != 2 {
+		t.Fatalf("expected an estimate at the rate observed, got %+v", r)
+	}
+
+	// The rate given explicitly takes precedence
+	if r = estimate(provider, "--rate=5"); r.Observed != nil || r.Estimates[0].RequestsPerSecond != 5 {
+		t.Fatalf("expected an estimate at the rate given, got %+v", r)
+	}
+
+	// Metrics which are not available fall back to the rate configured
+	if r = estimate(mock.NewMetricsProvider()); r.Observed != nil || r.Estimates[0].Environment != "default" || r.Estimates[0].RequestsPerSecond != 1 {
+		t.Fatalf("expected an estimate at the default rate, got %+v", r)
+	}
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
 	"text/template"
@@ -37,7 +39,8 @@ NAME
 
 SYNOPSIS
 	{{.Name}} create [-l|--language] [-t|--template] [-r|--repository]
-	            [-p |--path] [-c|--confirm]  [-v|--verbose]
+	            [--from-repo] [-p |--path] [-c|--confirm] [--no-prompt]
+	            [--fix-name] [-v|--verbose]
 
 DESCRIPTION
 	Creates a new function project.
@@ -53,11 +56,29 @@ DESCRIPTION
 	To complete this command interactively, use --confirm (-c):
 	  $ {{.Name}} create -c
 
+	The default language, template and template repository may be set in
+	the global config file (` + "`language`, `template` and `repository`" + `),
+	such that no flags are necessary.  For scripted use, --no-prompt ensures
+	that the command never prompts, even if confirmation is enabled in the
+	global config, and fails instead if a required value is missing.
+
+	The function's name is derived from its directory, and must be a valid
+	DNS label: lowercase alphanumeric characters or '-', starting with a letter
+	and ending with an alphanumeric character.  When it is not, a valid name is
+	suggested, which --fix-name accepts automatically.  If the directory is
+	yet to be created, it is created with the suggested name.
+
 	Available Language Runtimes and Templates:
 {{ .Options | indent 2 " " | indent 1 "\t" }}
 
 	To install more language runtimes and their templates see '{{.Name}} repository'.
 
+	To create a function from existing code rather than a template, use
+	--from-repo with the URL of a git repository, optionally followed by
+	#<ref> (a branch, tag or commit) and /<subdir>, the directory of the
+	repository containing the code.  The language runtime is detected from
+	the code (go.mod, package.json, etc.) unless provided, and any func.yaml
+	of the code is retained.
 
 EXAMPLES
 	o Create a Node.js function in the current directory (the default path) which
@@ -69,9 +90,19 @@ EXAMPLES
 
 	o Create a Go function which handles CloudEvents in ./myfunc.
 	  $ {{.Name}} create -l go -t cloudevents myfunc
+
+	o Create a Go function in ./my-cool-function, fixing the invalid name given.
+	  $ {{.Name}} create -l go --fix-name My_Cool.Function
+
+	o Create a function in ./api from the services/api directory of the
+	  release/1.0 branch of an existing repository.
+	  $ {{.Name}} create --from-repo https://github.com/alice/services.git#release/1.0/services/api api
+
+	o Create a function from the services/api directory of the default branch.
+	  $ {{.Name}} create --from-repo https://github.com/alice/services.git#/services/api api
 		`,
 		SuggestFor: []string{"vreate", "creaet", "craete", "new"},
-		PreRunE:    bindEnv("language", "template", "repository", "confirm", "verbose", "path"),
+		PreRunE:    bindEnv("language", "template", "repository", "from-repo", "confirm", "no-prompt", "fix-name", "verbose", "path"),
 		Aliases:    []string{"init"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runCreate(cmd, args, newClient)
@@ -86,10 +117,13 @@ EXAMPLES
 
 	// Flags
 	cmd.Flags().StringP("language", "l", cfg.Language, "Language Runtime (see help text for list) ($FUNC_LANGUAGE)")
-	cmd.Flags().StringP("template", "t", fn.DefaultTemplate, "Function template. (see help text for list) ($FUNC_TEMPLATE)")
-	cmd.Flags().StringP("repository", "r", "", "URI to a Git repository containing the specified template ($FUNC_REPOSITORY)")
+	cmd.Flags().StringP("template", "t", defaultTemplate(cfg), "Function template. (see help text for list) ($FUNC_TEMPLATE)")
+	cmd.Flags().StringP("repository", "r", cfg.Repository, "URI to a Git repository containing the specified template ($FUNC_REPOSITORY)")
+	cmd.Flags().String("from-repo", "", "Create the function from the existing code of a Git repository, in the form <url>[#ref][/subdir], rather than from a template ($FUNC_FROM_REPO)")
 
 	addConfirmFlag(cmd, cfg.Confirm)
+	cmd.Flags().Bool("no-prompt", false, "Never prompt, failing instead if a required value is missing. Overrides --confirm ($FUNC_NO_PROMPT)")
+	cmd.Flags().Bool("fix-name", false, "Use the suggested valid name if the function's name is invalid ($FUNC_FIX_NAME)")
 	// Add --path flag (default "") for consistency with other commands.
 	// Retain positional [path] for backward compatibility.
 	// Empty string default means current directory.
@@ -136,6 +170,15 @@ EXAMPLES
 	return cmd
 }
 
+// defaultTemplate of new functions: that of the global config, or the
+// builtin default.
+func defaultTemplate(cfg config.Global) string {
+	if cfg.Template != "" {
+		return cfg.Template
+	}
+	return fn.DefaultTemplate
+}
+
 // Run Create
 func runCreate(cmd *cobra.Command, args []string, newClient ClientFactory) (err error) {
 	// Config
@@ -160,6 +203,31 @@ func runCreate(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 		return
 	}
 
+	// The default registry is first used when the function is built, at which
+	// point a malformed value would only be reported by the registry itself.
+	if r := defaultRegistry(); r != "" {
+		if err := utils.ValidateRegistry(r); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v. The function can not be built until the registry is corrected.\n", err)
+		}
+	}
+
+	// Import existing code
+	if cfg.FromRepo != "" {
+		var f fn.Function
+		if f, err = client.Import(cmd.Context(), fn.Function{
+			Name:    cfg.Name,
+			Root:    cfg.Path,
+			Runtime: cfg.Runtime,
+		}, cfg.FromRepo); err != nil {
+			if errors.Is(err, fn.ErrRuntimeNotDetected) {
+				return fmt.Errorf("%w. Specify the language with --language", err)
+			}
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStderr(), "Created %v function in %v from %v\n", f.Runtime, cfg.Path, cfg.FromRepo)
+		return nil
+	}
+
 	// Create
 	_, err = client.Init(fn.Function{
 		Name:     cfg.Name,
@@ -179,8 +247,11 @@ type createConfig struct {
 	Path       string // Absolute path to function source
 	Runtime    string // Language Runtime
 	Repository string // Repository URI (overrides builtin and installed)
+	FromRepo   string // Repository URI of existing code (in place of a template)
 	Verbose    bool   // Verbose output
 	Confirm    bool   // Confirm values via an interactive prompt
+	NoPrompt   bool   // Never prompt, failing if a required value is missing
+	FixName    bool   // Use the suggested name if the derived name is invalid
 
 	// Template is the code written into the new function project, including
 	// an implementation adhering to one of the supported function signatures.
@@ -242,11 +313,33 @@ func newCreateConfig(cmd *cobra.Command, args []string, newClient ClientFactory)
 		Name:       dirName, // TODO: refactor to be git-like
 		Path:       absolutePath,
 		Repository: viper.GetString("repository"),
+		FromRepo:   viper.GetString("from-repo"),
 		Runtime:    viper.GetString("language"), // users refer to it is language
 		Template:   viper.GetString("template"),
 		Confirm:    viper.GetBool("confirm"),
+		NoPrompt:   viper.GetBool("no-prompt"),
+		FixName:    viper.GetBool("fix-name"),
 		Verbose:    viper.GetBool("verbose"),
 	}
+	if cfg.NoPrompt {
+		if cmd.Flags().Changed("confirm") && cfg.Confirm {
+			return cfg, errors.New("--confirm and --no-prompt may not be used together")
+		}
+		cfg.Confirm = false
+	}
+	if cfg.FixName {
+		cfg = cfg.fixName(cmd.ErrOrStderr())
+	}
+
+	// The runtime of existing code is detected unless explicitly provided,
+	// rather than defaulted from the global config, which also does not
+	// apply a template repository.
+	if cfg.FromRepo != "" && !cmd.Flags().Changed("language") && os.Getenv("FUNC_LANGUAGE") == "" {
+		cfg.Runtime = ""
+	}
+	if cfg.FromRepo != "" && !cmd.Flags().Changed("repository") && os.Getenv("FUNC_REPOSITORY") == "" {
+		cfg.Repository = ""
+	}
 
 	// If not in confirm/prompting mode, this cfg structure is complete.
 	if !cfg.Confirm {
@@ -283,6 +376,34 @@ func newCreateConfig(cmd *cobra.Command, args []string, newClient ClientFactory)
 	return
 }
 
+// fixName replaces an invalid function name with the valid name suggested
+// for it.  A function directory which does not yet exist is given the
+// suggested name as well, such that the name continues to match the
+// directory.  An existing directory, such as the current working directory,
+// is not renamed.
+func (c createConfig) fixName(w io.Writer) createConfig {
+	r := utils.CheckFunctionName(c.Name)
+	if r.Valid() || r.Suggestion() == "" {
+		return c
+	}
+	fmt.Fprintf(w, "Using function name '%v' rather than the invalid '%v'\n", r.Suggestion(), c.Name)
+	c.Name = r.Suggestion()
+	if _, err := os.Stat(c.Path); os.IsNotExist(err) {
+		c.Path = filepath.Join(filepath.Dir(c.Path), c.Name)
+	}
+	return c
+}
+
+// defaultRegistry for functions, as provided by the environment or the global
+// config, excluding that detected from the cluster.
+func defaultRegistry() string {
+	if r := viper.GetString("registry"); r != "" {
+		return r
+	}
+	cfg, _ := config.NewDefault()
+	return cfg.Registry
+}
+
 // singleCommand that could be used by the current user to minimally recreate the current state.
 func singleCommand(cmd *cobra.Command, args []string, cfg createConfig) string {
 	var b strings.Builder
@@ -294,6 +415,12 @@ func singleCommand(cmd *cobra.Command, args []string, cfg createConfig) string {
 	if cmd.Flags().Lookup("repository").Changed {
 		b.WriteString(" -r " + cfg.Repository)
 	}
+	if cmd.Flags().Lookup("from-repo").Changed {
+		b.WriteString(" --from-repo " + cfg.FromRepo)
+	}
+	if cmd.Flags().Lookup("fix-name").Changed {
+		b.WriteString(" --fix-name")
+	}
 	if cmd.Flags().Lookup("verbose").Changed {
 		b.WriteString(fmt.Sprintf(" -v %v", cfg.Verbose))
 	}
@@ -317,9 +444,11 @@ func (c createConfig) Validate(client *fn.Client) (err error) {
 	// Service, which itself is constrained to a DNS label (a subdomain).
 	// TODO: refactor to be git-like with no name at time of creation, but rather
 	// with named deployment targets in a one-to-many configuration.
-	dirName, _ := deriveNameAndAbsolutePathFromPath(c.Path)
-	if err = utils.ValidateFunctionName(dirName); err != nil {
-		return
+	if r := utils.CheckFunctionName(c.Name); !r.Valid() {
+		if suggestion := r.Suggestion(); suggestion != "" {
+			return fmt.Errorf("%w. Try '%v', or use --fix-name to accept it", r.Err(), suggestion)
+		}
+		return r.Err()
 	}
 
 	// Validate Runtime and Template Name
@@ -334,6 +463,18 @@ func (c createConfig) Validate(client *fn.Client) (err error) {
 	// for a CLI it behooves us to be more verbose, including valid options for
 	// each.  So here, we check that the values entered (if any) are both valid
 	// and valid together.
+	// Existing code is not created from a template, and its runtime is
+	// detected if not provided.
+	if c.FromRepo != "" {
+		if c.Repository != "" {
+			return errors.New("--from-repo and --repository may not be used together")
+		}
+		if c.Runtime != "" && !isValidRuntime(client, c.Runtime) {
+			return newInvalidRuntimeError(client, c.Runtime)
+		}
+		return
+	}
+
 	if c.Runtime == "" {
 		return noRuntimeError(client)
 	}
@@ -500,6 +641,7 @@ func (c createConfig) prompt(client *fn.Client) (createConfig, error) {
 	if err := survey.Ask(qs, &c); err != nil {
 		return c, err
 	}
+	c.Name, _ = deriveNameAndAbsolutePathFromPath(c.Path)
 
 	// Second loop: choose template with autocompletion filtered by chosen runtime
 	qs = []*survey.Question{
@@ -2,8 +2,16 @@ package cmd
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	fn "knative.dev/func/pkg/functions"
 	. "knative.dev/func/pkg/testing"
 	"knative.dev/func/pkg/utils"
 )
@@ -82,6 +90,59 @@ func TestCreate_ValidatesName(t *testing.T) {
 	}
 }
 
+// TestCreate_FixName ensures that an invalid name is replaced with its
+// suggested valid name when --fix-name is provided, the function being
+// created in a directory of that name.
+func TestCreate_FixName(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--language=go", "--fix-name", "My_Cool.Function"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(filepath.Join(root, "my-cool-function"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Initialized() || f.Name != "my-cool-function" {
+		t.Fatalf("expected function 'my-cool-function' to be created, got %q", f.Name)
+	}
+
+	// Without the flag, the suggestion is offered in the error
+	cmd = NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--language=go", "My_Cool.Function"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "Try 'my-cool-function'") {
+		t.Fatalf("expected the suggested name in the error, got %v", err)
+	}
+}
+
+// TestCreate_RuntimeNames ensures that names such as keywords of a runtime,
+// or those of the packages of its templates, are not rejected, the name of a
+// function appearing nowhere in the code generated for it.
+func TestCreate_RuntimeNames(t *testing.T) {
+	for _, tt := range []struct{ runtime, name string }{
+		{"go", "main"},
+		{"go", "function"},
+		{"go", "s"},
+		{"python", "service"},
+		{"python", "lambda"},
+	} {
+		t.Run(tt.runtime+"/"+tt.name, func(t *testing.T) {
+			root := FromTempDirectory(t)
+
+			cmd := NewCreateCmd(NewClient)
+			cmd.SetArgs([]string{"--language=" + tt.runtime, tt.name})
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if f, err := fn.NewFunction(filepath.Join(root, tt.name)); err != nil || f.Name != tt.name {
+				t.Fatalf("expected function %q to be created, got %q (%v)", tt.name, f.Name, err)
+			}
+		})
+	}
+}
+
 // TestCreate_ConfigOptional ensures that the system can be used without
 // any additional configuration being required.
 func TestCreate_ConfigOptional(t *testing.T) {
@@ -98,3 +159,100 @@ func TestCreate_ConfigOptional(t *testing.T) {
 	// Not failing is success.  Config files or settings beyond what are
 	// automatically written to to the given config home are currently optional.
 }
+
+// TestCreate_FromRepo ensures that a function can be created from the
+// existing code of a git repository, its runtime being detected.
+func TestCreate_FromRepo(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	// A repository containing a Go function in its "api" directory
+	source := t.TempDir()
+	if _, err := fn.New().Init(fn.Function{Root: filepath.Join(source, "api"), Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(source, "api", fn.FunctionFile)); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := git.PlainInit(source, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wt.Add("."); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "xyz", Email: "xyz@abc.com", When: time.Now()}
+	if _, err = wt.Commit("init", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--from-repo", source + "#/api", "myfunc"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction("myfunc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "myfunc" || f.Runtime != "go" {
+		t.Fatalf("expected go function 'myfunc', got %q function %q", f.Runtime, f.Name)
+	}
+
+	// A template repository may not also be provided
+	cmd.SetArgs([]string{"--from-repo", source + "#/api", "--repository", source, "other"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected --from-repo and --repository to be mutually exclusive")
+	}
+}
+
+// TestCreate_ConfigDefaults ensures that the language and template of a new
+// function default to those of the global config.
+func TestCreate_ConfigDefaults(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, "func"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("language: go\ntemplate: cloudevents\n")
+	if err := os.WriteFile(filepath.Join(home, "func", "config.yaml"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--no-prompt", "myfunc"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(filepath.Join(root, "myfunc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Runtime != "go" || f.Invoke != "cloudevent" {
+		t.Fatalf("expected a go cloudevents function, got runtime %q invoke %q", f.Runtime, f.Invoke)
+	}
+}
+
+// TestCreate_NoPrompt ensures that --no-prompt fails rather than prompting
+// for a missing language, and may not be combined with --confirm.
+func TestCreate_NoPrompt(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	cmd := NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--no-prompt", "myfunc"})
+	var e ErrNoRuntime
+	if err := cmd.Execute(); !errors.As(err, &e) {
+		t.Fatalf("Did not receive ErrNoRuntime. Got %v", err)
+	}
+
+	cmd = NewCreateCmd(NewClient)
+	cmd.SetArgs([]string{"--no-prompt", "--confirm", "--language=go", "myfunc"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --no-prompt with --confirm to fail")
+	}
+}
@@ -22,6 +22,8 @@ This command undeploys a function from the cluster. By default the function from
 the project in the current directory is undeployed. Alternatively either the name
 of the function can be given as argument or the project path provided with --path.
 
+The domain mappings of the function's custom domains are also deleted.
+
 No local files are deleted.
 `,
 		Example: `
@@ -84,7 +86,11 @@ func runDelete(cmd *cobra.Command, args []string, newClient ClientFactory) (err
 		return
 	}
 
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
+	clientCfg := ClientConfig{Verbose: cfg.Verbose}
+	if cfg.Name == "" {
+		clientCfg.Deployer = deployerAt(cfg.Path)
+	}
+	client, done := newClient(clientCfg)
 	defer done()
 
 	if cfg.Name != "" { // Delete by name if provided
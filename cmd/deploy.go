@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -20,6 +26,7 @@ import (
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/knative"
 	"knative.dev/func/pkg/utils"
 )
 
@@ -33,11 +40,19 @@ NAME
 
 SYNOPSIS
 	{{rootCmdUse}} deploy [-R|--remote] [-r|--registry] [-i|--image] [-n|--namespace]
-	             [-e|--env] [-g|--git-url] [-t|--git-branch] [-d|--git-dir]
+	             [-e|--env] [--env-file] [-g|--git-url] [-t|--git-branch] [-d|--git-dir]
 	             [-b|--build] [--builder] [--builder-image] [-p|--push]
-	             [--domain] [--platform] [--build-timestamp] [--pvc-size]
-	             [--service-account] [-c|--confirm] [-v|--verbose]
-	             [--registry-insecure] [--remote-storage-class]
+	             [--domain] [--platform] [--platforms] [--build-timestamp] [--incremental] [--buildkit-host]
+	             [--sbom] [--sbom-output] [--sign] [--sign-key] [--build-env] [--trust-builder] [--dockerfile]
+	             [--scan] [--scanner] [--scan-severity] [--scan-output] [--tag-strategy] [--pvc-size] [--pipeline-template]
+	             [--service-account] [--traffic] [-c|--confirm] [-y|--yes] [-v|--verbose]
+	             [--registry-insecure] [--remote-storage-class] [--dry-run]
+	             [--output-manifests] [--min-scale] [--max-scale]
+	             [--concurrency-target] [--concurrency-limit] [--scale-utilization]
+	             [--scale-class] [--scale-metric] [--revision-history]
+	             [--context] [--concurrent] [--strategy] [--wait] [--wait-timeout]
+	             [--deployer] [--custom-domain] [--pin-digest] [--progress]
+	             [--retries] [--retry-timeout] [--route-visibility] [--route-annotation]
 
 DESCRIPTION
 
@@ -63,6 +78,18 @@ DESCRIPTION
 	  By default the function will be built if it has not yet been built, or if
 	  changes are detected in the function's source.  The --build flag can be
 	  used to override this behavior and force building either on or off.
+	  A multi-architecture image is built with --platforms, for example
+	  '--platforms linux/amd64,linux/arm64', and pushed as a manifest list.
+	  An SBOM of the function's dependencies is generated with --sbom, which is
+	  attached to the image when pushed (see the build subcommand).
+	  With --sign, the image is signed with cosign once pushed, with the
+	  private key of --sign-key or else keyless, such that it is admitted by
+	  clusters whose policy controllers require signed images.
+	  With --scan, the image is scanned for known vulnerabilities once built,
+	  and the deployment fails if any are of --scan-severity or above.  The
+	  trivy or grype command (--scanner) must be installed on the PATH.
+	  The image is tagged by --tag-strategy when built (see the build
+	  subcommand).
 
 	Pushing
 	  By default the function's image will be pushed to the configured container
@@ -81,6 +108,14 @@ DESCRIPTION
 	  of a git repository instead of local source, combine with '--git-url':
 	  '{{rootCmdUse}} deploy --remote --git-url=git.example.com/alice/f.git'
 
+	  The Pipeline run remotely may be customized, such as with steps which
+	  test, scan or notify, with --pipeline-template: the path of a template of
+	  the Pipeline, saved as build.pipelineTemplate of func.yaml.  A Pipeline
+	  at .tekton/pipeline.yaml is otherwise used as such a template.  The
+	  template includes the parameters, tasks and workspaces with which func
+	  builds and deploys the function as the template fields Params, Tasks and
+	  Workspaces.  See docs/reference/func_yaml.md for all of its fields.
+
 	Domain
 	  When deploying, a function's route is automatically generated using the
 	  default domain with which the target platform has been configured.  The
@@ -89,6 +124,176 @@ DESCRIPTION
 	  selectors. Note that the domain specified must be one of those configured
 	  or the flag will be ignored.
 
+	Traffic
+	  By default a new revision of a function is routed all of its traffic once
+	  ready.  The --traffic flag splits the traffic between revisions instead,
+	  such that a new revision is introduced with part of it.  Splits are of
+	  the form revision=percent, totalling 100, where the revision is 'latest'
+	  (that deployed), 'prev' (the latest ready revision prior to deploying) or
+	  the name of a revision.  The latest and previous revisions are tagged as
+	  such, each being routed a URL of its own.  Splits are saved as
+	  deploy.traffic of func.yaml, where the tag of each may be given, and
+	  apply to later deployments; use --traffic latest=100 to cut over.
+
+	  The --strategy flag chooses how the traffic of each new revision is
+	  routed.  The default strategy, 'latest', routes it all of the traffic
+	  once ready.  The 'blue-green' strategy routes the new revision none of
+	  the traffic, but tags it 'candidate', such that it is served at a URL
+	  of its own for testing, the traffic remaining routed to the revisions
+	  it was.  Use '{{rootCmdUse}} promote' to route all of the traffic to the
+	  candidate.  The strategy is saved as deploy.strategy of func.yaml, and
+	  may not be used with --traffic.
+
+	Custom Domains
+	  The --custom-domain flag serves the function also at a custom domain,
+	  such as api.example.com, by a Knative DomainMapping, the DNS of which
+	  must resolve to the cluster's ingress.  It may be given more than once,
+	  and a domain followed by a "-" (api.example.com-) is unmapped.  Domains
+	  are saved as deploy.domains of func.yaml; those removed from it are
+	  unmapped on the next deployment, and all on delete.  This differs from
+	  --domain, which selects among the domains the cluster is configured
+	  with.
+
+	OpenShift
+	  On OpenShift Serverless, a function deployed without a registry is by
+	  default pushed to the cluster's internal registry of its namespace.
+	  The --route-visibility flag chooses how it is routed: 'public', the
+	  default, by a Route from outside of the cluster; 'cluster-local', only
+	  from within the cluster (on any cluster); or 'none', without a Route
+	  created, such that one may be managed separately.  The
+	  --route-annotation flag sets an annotation of the Route, such as
+	  haproxy.router.openshift.io/timeout=600s, and may be given more than
+	  once, an annotation followed by a "-" (KEY-) being removed.  Both are
+	  saved as deploy.route of func.yaml.
+
+	Deployer
+	  By default the function is deployed as a Knative Service.  The
+	  --deployer flag chooses instead: 'k8s' deploys it as a plain Deployment
+	  and Service, for clusters without Knative Serving, exposed outside of
+	  the cluster only by an Ingress or HTTPRoute if deploy.expose of
+	  func.yaml is set.  Its scale is fixed at options.scale.min replicas, and
+	  traffic splits, the blue-green strategy and subscriptions, requiring
+	  Knative, are not supported.  The deployer is saved as deploy.deployer
+	  of func.yaml, with which the function is also described, invoked and
+	  deleted.  Remote deployments (--remote) use the Knative deployer.
+
+	Environment Variables
+	  The --env flag sets an environment variable of the function, or with a
+	  trailing '-' (NAME-) unsets it.  The --env-file flag sets those of a
+	  dotenv file, of NAME=VALUE lines, with comments ('#'), 'export'
+	  prefixes and quoted values permitted.  Those of func.yaml are
+	  overridden by those of the files, in the order given, which are in turn
+	  overridden by those of --env.  Values may reference the key of a secret
+	  or configmap, or a local variable, as those of func.yaml may.  The
+	  variables of --env are saved to run.envs of func.yaml, so a sensitive
+	  value is better referenced from a secret than written.  Those of
+	  --env-file are of the deployment alone, and are not saved, such that
+	  they are given again with each deployment.  Env files are not supported
+	  with --remote.
+
+	Waiting
+	  By default deploy returns once the revision deployed is ready.  The
+	  --wait flag chooses instead: 'none' returns once the function's
+	  resources are created or updated, 'ready' once its revision is ready,
+	  and 'traffic-shifted' once its traffic is also routed as requested.
+	  The --wait-timeout flag is the longest to wait, 2m by default.  Each
+	  stage of the deployment is reported as it is reached: revision-created,
+	  image-pulled, ready and routed, with the revision and time elapsed.
+
+	Retries
+	  Should the function's resources be updated concurrently, as by another
+	  deployment of it, or the cluster's API server fail transiently, they are
+	  applied again, backing off exponentially from half a second between
+	  attempts.  The --retries flag is the number of attempts, 5 by default,
+	  and --retry-timeout the longest for which they are made, 1m by default.
+
+	Progress
+	  The --progress flag chooses the format in which progress is reported:
+	  'text', for people, by default, or 'json', for CI.  Of 'json', each
+	  stage is written to stdout, as it is reached, as a JSON object on a line
+	  of its own, other messages being written to stderr.  The stages are
+	  build-started, the phases of the build and push and the progress of
+	  the push (see the build subcommand), built, image-pushed (with the
+	  image and its digest), revision-created, image-pulled, ready, routed,
+	  and deployed (with the URL of the function), each with the time it was
+	  reached.  JSON progress is not supported with --remote.
+
+	Pinning the Digest
+	  The --pin-digest flag deploys the image by the digest of its tag in the
+	  registry rather than by its tag, such that the service runs exactly the
+	  image pushed even should the tag later be overwritten.  Should the
+	  registry's digest of the tag not be that of the image just pushed, as
+	  when the tag was overwritten in the meantime, the deployment is refused.
+	  Pinning is not supported with --remote.
+
+	Dry Run
+	  The --dry-run flag prints the Knative Service, and the Triggers of the
+	  function's subscriptions, which deploying would create or update, as a
+	  YAML stream, without building, pushing or deploying.  A client dry run
+	  (--dry-run or --dry-run=client) renders them without contacting the
+	  cluster.  A server dry run (--dry-run=server) submits them to the
+	  cluster for admission without persisting them, such that they are
+	  validated, and rendered as defaulted, by the cluster.  The image is that
+	  given with --image, else that last built, else that which would be
+	  built.  With --deployer k8s the Deployment, Service and any Ingress or
+	  HTTPRoute are printed instead.  Dry runs are not supported with
+	  --remote.
+
+	Manifests
+	  The --output-manifests flag writes the resources which deploying would
+	  create or update to the given directory instead of deploying them, such
+	  that they may be committed to a GitOps repository.  The function is built
+	  and pushed as when deploying, such that the manifests are of the image
+	  pushed, and each resource is written to a file of its own with a
+	  kustomization.yaml listing them.  The Secrets, ConfigMaps, volume claims
+	  and service account the function references are noted in the
+	  kustomization, but are not written.  With --dry-run, the function is
+	  neither built nor pushed, and the manifests are rendered as by the dry
+	  run.
+
+	Autoscaling
+	  The flags --min-scale, --max-scale, --concurrency-target,
+	  --concurrency-limit and --scale-utilization set the scale bounds, the
+	  target of the scale metric (concurrency by default), the hard limit of
+	  concurrent requests of each instance, and the percentage of the target
+	  at which to scale.  Each is saved to options of func.yaml, replacing
+	  that there, and those not given are retained.  A --max-scale of 0 is
+	  unbounded.
+
+	  The --scale-class flag chooses the autoscaler: 'kpa', the Knative Pod
+	  Autoscaler, by default, or 'hpa', the Kubernetes Horizontal Pod
+	  Autoscaler.  The --scale-metric flag chooses the metric by which it
+	  scales: 'concurrency' or 'rps' of the kpa, 'cpu' or 'memory' of the
+	  hpa.  Of the hpa, the target is a percentage of the CPU requested
+	  (options.resources.requests.cpu, which is then required) or the memory
+	  of each instance in Mi, and the function is never scaled to zero.
+
+	Revision History
+	  The --revision-history flag sets the number of old revisions of the
+	  function retained, saved as options.revisionHistoryLimit of func.yaml.
+	  On each deploy, old revisions beyond it, newest first, are deleted.
+	  Revisions routed traffic, or tagged, are not old, and are never
+	  deleted.  All are retained unless it is set.  With --deployer k8s it is
+	  the revision history limit of the Deployment.
+
+	Multiple Clusters
+	  The --context flag deploys to the cluster of the given kubeconfig
+	  context rather than that of the current context.  Given more than once,
+	  or as a comma-separated list, the function is built and pushed once and
+	  the same image deployed to each, in turn or, with --concurrent, at
+	  once.  The result of each deployment is reported, and deployment
+	  continues to the remaining contexts should one fail.  The namespace is
+	  the same of each.  Deploying to contexts is not supported with
+	  --remote, --dry-run or --output-manifests.
+
+	First Deployment to a Cluster and Namespace
+	  Before a function is first deployed to a namespace of a cluster (the
+	  current kubeconfig context), a summary of the deployment is printed,
+	  and confirmation is requested.  This guards against deploying to the
+	  wrong cluster.  A deployment is the first if the function is not found
+	  in the namespace of the cluster.  Use --yes (-y) to skip the
+	  confirmation, as is required when not run in a terminal, such as by CI.
+
 EXAMPLES
 
 	o Deploy the function
@@ -122,6 +327,54 @@ EXAMPLES
 	  local filesystem.
 	  $ {{rootCmdUse}} deploy --build=false
 
+	o Deploy a new revision of the function routed 10% of its traffic, the
+	  previous revision being routed the remainder.
+	  $ {{rootCmdUse}} deploy --traffic latest=10,prev=90
+
+	o Print the resources the function would be deployed as, without
+	  deploying, validated by the cluster.
+	  $ {{rootCmdUse}} deploy --dry-run=server
+
+	o Deploy the function keeping at least one instance, scaling out when an
+	  instance is handling 50 concurrent requests.
+	  $ {{rootCmdUse}} deploy --min-scale 1 --concurrency-target 50
+
+	o Deploy the function scaled by the Horizontal Pod Autoscaler, adding an
+	  instance when those running use 80% of the CPU they request.
+	  $ {{rootCmdUse}} deploy --scale-class hpa --scale-metric cpu --concurrency-target 80 --min-scale 1
+
+	o Deploy a new revision of the function routed none of the traffic, for
+	  testing at its candidate URL, then route all of the traffic to it.
+	  $ {{rootCmdUse}} deploy --strategy blue-green
+	  $ {{rootCmdUse}} promote
+
+	o Deploy the function, serving it also at a custom domain.
+	  $ {{rootCmdUse}} deploy --custom-domain api.example.com
+
+	o Deploy the function with the environment variables of .env.production,
+	  overriding its LOG_LEVEL.
+	  $ {{rootCmdUse}} deploy --env-file .env.production --env LOG_LEVEL=debug
+
+	o Deploy the function by the digest of the image pushed, such that the
+	  service is unaffected by the tag being overwritten.
+	  $ {{rootCmdUse}} deploy --pin-digest
+
+	o Deploy the function from CI, waiting at most five minutes for its
+	  traffic to be routed to the new revision.
+	  $ {{rootCmdUse}} deploy --wait traffic-shifted --wait-timeout 5m
+
+	o Deploy the function from CI, reading its progress, such as the digest
+	  pushed and the URL deployed, from JSON events on stdout.
+	  $ {{rootCmdUse}} deploy --progress json | jq -r 'select(.stage=="deployed").url'
+
+	o Deploy the same image of the function to the clusters of two kubeconfig
+	  contexts at once.
+	  $ {{rootCmdUse}} deploy --context staging --context prod-eu --concurrent
+
+	o Build and push the function, writing the resources it would be deployed
+	  as to ./deploy rather than deploying.
+	  $ {{rootCmdUse}} deploy --output-manifests ./deploy
+
 	o Redeploy a function which has already been built and pushed. Works without
 	  the use of a local container engine.  For example, if the function was
 	  manually deleted from the cluster, it can be quickly redeployed with:
@@ -130,10 +383,11 @@ EXAMPLES
 `,
 		SuggestFor: []string{"delpoy", "deplyo"},
 		PreRunE: bindEnv("build", "build-timestamp", "builder", "builder-image",
-			"base-image", "confirm", "domain", "env", "git-branch", "git-dir",
-			"git-url", "image", "namespace", "path", "platform", "push", "pvc-size",
-			"service-account", "registry", "registry-insecure", "remote",
-			"username", "password", "token", "verbose", "remote-storage-class"),
+			"base-image", "run-image", "buildkit-host", "concurrency-limit",
+			"concurrency-target", "concurrent", "confirm", "context", "custom-domain", "deployer", "domain", "env", "env-file", "git-branch", "git-dir",
+			"git-url", "dry-run", "image", "incremental", "max-scale", "min-scale", "namespace", "output-manifests", "path", "pin-digest", "pipeline-template", "platform", "platforms", "progress", "push", "pvc-size", "revision-history",
+			"scale-class", "scale-metric", "scale-utilization", "service-account", "strategy", "traffic", "registry", "registry-insecure", "remote", "retries", "retry-timeout", "route-visibility",
+			"sbom", "sbom-output", "sign", "sign-key", "scan", "scanner", "scan-severity", "scan-output", "tag-strategy", "trust-builder", "dockerfile", "username", "password", "token", "verbose", "remote-storage-class", "wait", "wait-timeout", "yes"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDeploy(cmd, newClient)
 		},
@@ -169,7 +423,32 @@ EXAMPLES
 	cmd.Flags().String("builder-image", builderImage,
 		"Specify a custom builder image for use by the builder other than its default. ($FUNC_BUILDER_IMAGE)")
 	cmd.Flags().StringP("base-image", "", f.Build.BaseImage,
-		"Override the base image for your function (host builder only)")
+		"Override the image your function is built upon: the builder image of the pack and s2i builders, or the base of the host builder. ($FUNC_BASE_IMAGE)")
+	cmd.Flags().StringP("run-image", "", f.Build.RunImage,
+		"Override the image your function runs upon: the run image of the pack builder, the runtime image of the s2i builder, or the base of the host builder. ($FUNC_RUN_IMAGE)")
+	cmd.Flags().String("tag-strategy", f.Build.TagStrategy,
+		fmt.Sprintf("Tag of the image derived from the registry when building: %v. Defaults to latest. ($FUNC_TAG_STRATEGY)", strings.Join(fn.TagStrategies, ", ")))
+	cmd.Flags().String("sbom", f.Build.SBOM,
+		fmt.Sprintf("Generate an SBOM of the function's dependencies when building, in the format %q or %q. ($FUNC_SBOM)", fn.SBOMFormatCycloneDX, fn.SBOMFormatSPDX))
+	cmd.Flags().Bool("sign", f.Build.Sign != nil,
+		"Sign the function's image with cosign once pushed, keyless unless --sign-key is given. ($FUNC_SIGN)")
+	cmd.Flags().String("sign-key", signKey(f),
+		"Path to the cosign private key with which to sign the image. Its password, if any, is read from COSIGN_PASSWORD. ($FUNC_SIGN_KEY)")
+	cmd.Flags().Bool("scan", f.Build.Scan != nil,
+		"Scan the function's image for known vulnerabilities once built, failing if any are of --scan-severity or above. Requires the trivy or grype command (--scanner) to be installed on the PATH. ($FUNC_SCAN)")
+	cmd.Flags().String("scanner", scanSpec(f).Scanner,
+		fmt.Sprintf("Scanner of the image, %q or %q, whose command is run from the PATH. Defaults to %v. Requires --scan. ($FUNC_SCANNER)", fn.ScannerTrivy, fn.ScannerGrype, fn.DefaultScanner))
+	cmd.Flags().String("scan-severity", scanSpec(f).Severity,
+		fmt.Sprintf("Severity of vulnerabilities at or above which the scan fails (%v). Defaults to %v. Requires --scan. ($FUNC_SCAN_SEVERITY)", strings.Join(fn.Severities, "|"), fn.DefaultScanSeverity))
+	cmd.Flags().Bool("incremental", f.Build.Incremental, "Reuse artifacts of the function's previous image, such as downloaded dependencies, when building. This is only useful for the s2i builder. ($FUNC_INCREMENTAL)")
+	cmd.Flags().Bool("trust-builder", trustBuilder(f),
+		"Trust the builder image of the pack builder, giving its lifecycle the registry credentials. By default only builder images of known publishers are trusted. ($FUNC_TRUST_BUILDER)")
+	cmd.Flags().String("dockerfile", f.Build.Dockerfile,
+		"Path, relative to the function's root, of the Dockerfile from which the dockerfile and kaniko builders build the function. Defaults to Dockerfile. ($FUNC_DOCKERFILE)")
+	cmd.Flags().StringArray("build-env", []string{},
+		"Build environment variable to set in the form NAME=VALUE. "+
+			"You may provide this flag multiple times for setting multiple build environment variables. "+
+			"To unset, specify the variable name followed by a \"-\" (e.g., NAME-).")
 	cmd.Flags().StringP("image", "i", f.Image,
 		"Full image name in the form [registry]/[namespace]/[name]:[tag]@[digest]. This option takes precedence over --registry. Specifying digest is optional, but if it is given, 'build' and 'push' phases are disabled. ($FUNC_IMAGE)")
 
@@ -177,8 +456,22 @@ EXAMPLES
 		"Environment variable to set in the form NAME=VALUE. "+
 			"You may provide this flag multiple times for setting multiple environment variables. "+
 			"To unset, specify the environment variable name followed by a \"-\" (e.g., NAME-).")
+	cmd.Flags().StringArray("env-file", []string{},
+		"Dotenv file of environment variables to set, in the form NAME=VALUE per line. "+
+			"You may provide this flag multiple times, later files taking precedence. "+
+			"Variables given with --env take precedence over those of the files, which are of this deployment alone and are not saved to func.yaml.")
 	cmd.Flags().String("domain", f.Domain,
 		"Domain to use for the function's route.  Cluster must be configured with domain matching for the given domain (ignored if unrecognized) ($FUNC_DOMAIN)")
+	cmd.Flags().StringArray("custom-domain", []string{},
+		"Custom domain at which the function is also served, by a DomainMapping. "+
+			"May be given more than once. To unmap, specify the domain followed by a \"-\" (e.g., api.example.com-). "+
+			"Saved as deploy.domains of func.yaml.")
+	cmd.Flags().String("route-visibility", routeVisibility(f),
+		fmt.Sprintf("Visibility of the function's route. [%v|%v|%v] (default %v). Saved as deploy.route.visibility of func.yaml. ($FUNC_ROUTE_VISIBILITY)", fn.RouteVisibilityPublic, fn.RouteVisibilityClusterLocal, fn.RouteVisibilityNone, fn.RouteVisibilityPublic))
+	cmd.Flags().StringArray("route-annotation", []string{},
+		"Annotation of the function's OpenShift Route in the form KEY=VALUE. "+
+			"May be given more than once. To remove, specify the key followed by a \"-\" (e.g., KEY-). "+
+			"Saved as deploy.route.annotations of func.yaml.")
 	cmd.Flags().StringP("git-url", "g", f.Build.Git.URL,
 		"Repository url containing the function to build ($FUNC_GIT_URL)")
 	cmd.Flags().StringP("git-branch", "t", f.Build.Git.Revision,
@@ -191,18 +484,69 @@ EXAMPLES
 		"Specify a storage class to use for the volume on-cluster during remote builds")
 	cmd.Flags().String("pvc-size", f.Build.PVCSize,
 		"When triggering a remote deployment, set a custom volume size to allocate for the build operation ($FUNC_PVC_SIZE)")
+	cmd.Flags().String("pipeline-template", f.Build.PipelineTemplate,
+		"When triggering a remote deployment, path of a template of the Tekton Pipeline to run, relative to the function. Saved as build.pipelineTemplate of func.yaml. ($FUNC_PIPELINE_TEMPLATE)")
 	cmd.Flags().String("service-account", f.Deploy.ServiceAccountName,
 		"Service account to be used in the deployed function ($FUNC_SERVICE_ACCOUNT)")
+	cmd.Flags().String("traffic", "",
+		"Split the traffic between revisions, such as latest=90,prev=10. Saved as deploy.traffic of func.yaml. ($FUNC_TRAFFIC)")
+	cmd.Flags().String("deployer", f.Deploy.Deployer,
+		fmt.Sprintf("Deployer of the function. [%v|%v] (default %v). Saved as deploy.deployer of func.yaml. ($FUNC_DEPLOYER)", fn.DeployerKnative, fn.DeployerK8s, fn.DeployerKnative))
+	cmd.Flags().String("strategy", f.Deploy.Strategy,
+		fmt.Sprintf("Strategy of routing the traffic of a new revision. [%v|%v]. Saved as deploy.strategy of func.yaml. ($FUNC_STRATEGY)", fn.StrategyLatest, fn.StrategyBlueGreen))
+	cmd.Flags().Int64("revision-history", 0,
+		"Number of old revisions retained, those beyond it deleted on deploy. Saved as options.revisionHistoryLimit of func.yaml. ($FUNC_REVISION_HISTORY)")
+	cmd.Flags().Int64("min-scale", 0,
+		"Minimum number of instances. Saved as options.scale.min of func.yaml. ($FUNC_MIN_SCALE)")
+	cmd.Flags().Int64("max-scale", 0,
+		"Maximum number of instances, 0 being unbounded. Saved as options.scale.max of func.yaml. ($FUNC_MAX_SCALE)")
+	cmd.Flags().Float64("concurrency-target", 0,
+		"Target of the scale metric of each instance, concurrent requests by default, or the CPU percentage or memory in Mi of the hpa. Saved as options.scale.target of func.yaml. ($FUNC_CONCURRENCY_TARGET)")
+	cmd.Flags().Int64("concurrency-limit", 0,
+		"Maximum number of concurrent requests of each instance, 0 being unlimited. Saved as options.resources.limits.concurrency of func.yaml. ($FUNC_CONCURRENCY_LIMIT)")
+	cmd.Flags().Float64("scale-utilization", 0,
+		"Percentage of the target at which to scale, between 1 and 100. Saved as options.scale.utilization of func.yaml. ($FUNC_SCALE_UTILIZATION)")
+	cmd.Flags().String("scale-class", "",
+		fmt.Sprintf("Autoscaler of the function. [%v|%v]. Saved as options.scale.class of func.yaml. ($FUNC_SCALE_CLASS)", fn.ScaleClassKPA, fn.ScaleClassHPA))
+	cmd.Flags().String("scale-metric", "",
+		"Metric by which to scale. [concurrency|rps] of the kpa, [cpu|memory] of the hpa. Saved as options.scale.metric of func.yaml. ($FUNC_SCALE_METRIC)")
 	// Static Flags:
 	// Options which have static defaults only (not globally configurable nor
 	// persisted with the function)
+	cmd.Flags().StringSlice("context", []string{},
+		"Kubeconfig context of the cluster to which to deploy, rather than the current context. May be given more than once. ($FUNC_CONTEXT)")
+	cmd.Flags().Bool("concurrent", false,
+		"Deploy to the clusters of multiple contexts at once, rather than in turn. ($FUNC_CONCURRENT)")
+	cmd.Flags().String("wait", string(fn.WaitReady),
+		fmt.Sprintf("Condition of the deployment upon which to return. [%v|%v|%v]. ($FUNC_WAIT)", fn.WaitNone, fn.WaitReady, fn.WaitTrafficShifted))
+	cmd.Flags().Duration("wait-timeout", knative.DefaultWaitingTimeout,
+		"Longest to wait for the condition of --wait to be met. ($FUNC_WAIT_TIMEOUT)")
+	cmd.Flags().Int("retries", k8s.DefaultRetries,
+		"Attempts at applying the function's resources upon conflicts or transient errors of the cluster. ($FUNC_RETRIES)")
+	cmd.Flags().Duration("retry-timeout", k8s.DefaultRetryTimeout,
+		"Longest for which to retry applying the function's resources. ($FUNC_RETRY_TIMEOUT)")
+	cmd.Flags().String("progress", progressText,
+		fmt.Sprintf("Format in which the progress of the deployment is reported. [%v|%v]. ($FUNC_PROGRESS)", progressText, progressJSON))
+	cmd.Flags().Bool("pin-digest", false,
+		"Deploy the image by the digest of its tag in the registry, refusing should it not be that of the image pushed. ($FUNC_PIN_DIGEST)")
+	cmd.Flags().String("dry-run", "",
+		"Print the resources which would be deployed, without deploying. [client|server]. ($FUNC_DRY_RUN)")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = string(fn.DryRunClient) // register `--dry-run` as equivalent to `--dry-run=client`
+	cmd.Flags().String("output-manifests", "",
+		"Write the resources which would be deployed to this directory, without deploying. ($FUNC_OUTPUT_MANIFESTS)")
 	cmd.Flags().String("build", "auto",
 		"Build the function. [auto|true|false]. ($FUNC_BUILD)")
 	cmd.Flags().Lookup("build").NoOptDefVal = "true" // register `--build` as equivalient to `--build=true`
 	cmd.Flags().BoolP("push", "u", true,
 		"Push the function image to registry before deploying. ($FUNC_PUSH)")
 	cmd.Flags().String("platform", "",
-		"Optionally specify a specific platform to build for (e.g. linux/amd64). ($FUNC_PLATFORM)")
+		"Target platform of the image, which may be other than that of the host, for example \"linux/arm64\". The image is of that platform alone, rather than a manifest list. ($FUNC_PLATFORM)")
+	cmd.Flags().String("platforms", "",
+		"Comma-separated platforms of a multi-architecture image to build (e.g. linux/amd64,linux/arm64). ($FUNC_PLATFORMS)")
+	cmd.Flags().String("sbom-output", "",
+		"Path to which to write the SBOM generated when building. Requires --sbom. ($FUNC_SBOM_OUTPUT)")
+	cmd.Flags().String("scan-output", "",
+		"Path to which to write the JSON report of the scan of the image. Requires --scan. ($FUNC_SCAN_OUTPUT)")
 	cmd.Flags().StringP("username", "", "",
 		"Username to use when pushing to the registry.")
 	cmd.Flags().StringP("password", "", "",
@@ -210,8 +554,11 @@ EXAMPLES
 	cmd.Flags().StringP("token", "", "",
 		"Token to use when pushing to the registry.")
 	cmd.Flags().BoolP("build-timestamp", "", false, "Use the actual time as the created time for the docker image. This is only useful for buildpacks builder.")
+	cmd.Flags().String("buildkit-host", os.Getenv("BUILDKIT_HOST"), "Address of the BuildKit daemon used by the buildkit builder, such as tcp://host:1234, ssh://user@host or kube-pod://buildkitd. Defaults to BUILDKIT_HOST. ($FUNC_BUILDKIT_HOST)")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(f, false),
 		"Deploy into a specific namespace. Will use the function's current namespace by default if already deployed, and the currently active context if it can be determined. ($FUNC_NAMESPACE)")
+	cmd.Flags().BoolP("yes", "y", false,
+		"Skip confirmation of the first deployment of the function to a cluster and namespace. ($FUNC_YES)")
 
 	// Temporarily Hidden Basic Auth Flags
 	// Username, Password and Token flags, which plumb through basic auth, are
@@ -323,6 +670,13 @@ func runDeploy(cmd *cobra.Command, newClient ClientFactory) (err error) {
 		}
 	}
 
+	// On OpenShift, a function without a registry is by default pushed to
+	// the cluster's internal registry of the namespace it is deployed to.
+	if cfg.Registry == "" && cfg.Image == "" && f.Registry == "" && f.Image == "" && isOpenShift() {
+		cfg.Registry = openShiftRegistryOf(cfg, f)
+		fmt.Fprintf(cmd.ErrOrStderr(), "Info: using the OpenShift internal registry %s\n", cfg.Registry)
+	}
+
 	// Now that we know function exists, proceed with prompting
 	if cfg, err = cfg.Prompt(); err != nil {
 		// Layer 2: Catch technical errors and provide CLI-specific user-friendly messages
@@ -376,19 +730,6 @@ Cannot use both --image and --registry together. Choose one:
 
 Note: FUNC_REGISTRY environment variable doesn't conflict with --image flag
 
-For more options, run 'func deploy --help'`, err)
-		}
-		if errors.Is(err, fn.ErrPlatformNotSupported) {
-			return fmt.Errorf(`%w
-
-The --platform flag is only supported with the S2I builder.
-
-Try this:
-  func deploy --registry <registry> --builder=s2i --platform linux/amd64
-
-Or remove the --platform flag:
-  func deploy --registry <registry>
-
 For more options, run 'func deploy --help'`, err)
 		}
 		return
@@ -396,7 +737,7 @@ For more options, run 'func deploy --help'`, err)
 	if f, err = cfg.Configure(f); err != nil { // Updates f with deploy cfg
 		return
 	}
-	cmd.SetContext(cfg.WithValues(cmd.Context())) // Some optional settings are passed via context
+	cmd.SetContext(cfg.WithValues(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr())) // Some optional settings are passed via context
 
 	changingNamespace := func(f fn.Function) bool {
 		// We're changing namespace if:
@@ -409,15 +750,27 @@ For more options, run 'func deploy --help'`, err)
 	// also update the registry because there is a registry per namespace,
 	// and their name includes the namespace.
 	// This saves needing a manual flag ``--registry={destination namespace registry}``
-	if changingNamespace(f) && k8s.IsOpenShift() {
+	if changingNamespace(f) && isOpenShift() {
 		// TODO(lkingland): this appears to force use of the openshift
 		// internal registry.
-		f.Registry = "image-registry.openshift-image-registry.svc:5000/" + f.Namespace
+		f.Registry = k8s.OpenShiftRegistry(f.Namespace)
 		if cfg.Verbose {
 			fmt.Fprintf(cmd.OutOrStdout(), "Info: Overriding openshift registry to %s\n", f.Registry)
 		}
 	}
 
+	// Print the resources which would be deployed, without building, pushing
+	// or deploying.
+	if cfg.DryRun != "" {
+		return runDeployDryRun(cmd, cfg, f, newClient)
+	}
+
+	// Of JSON progress, stdout is of its events alone, the messages otherwise
+	// written to it being written to stderr.
+	if cfg.Progress == progressJSON {
+		cmd.SetOut(cmd.ErrOrStderr())
+	}
+
 	// Informative non-error messages regarding the final deployment request
 	printDeployMessages(cmd.OutOrStdout(), f)
 
@@ -427,11 +780,30 @@ For more options, run 'func deploy --help'`, err)
 	if err != nil {
 		return
 	}
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure}, clientOptions...)
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure, Deployer: f.Deploy.Deployer}, clientOptions...)
 	defer done()
 
+	// Confirm the first deployment to each cluster and namespace, unless
+	// writing manifests rather than deploying.
+	if cfg.OutputManifests == "" {
+		for _, kubeContext := range cfg.kubeContexts() {
+			ctx := k8s.WithContext(cmd.Context(), kubeContext)
+			if kubeContext != "" {
+				if _, _, err = k8s.GetCurrentContextFrom(ctx); err != nil {
+					return
+				}
+			}
+			if err = confirmTarget(ctx, cmd, cfg, f, client); err != nil {
+				return
+			}
+		}
+	}
+
 	// Deploy
 	if cfg.Remote {
+		if len(f.Build.Secrets) > 0 {
+			return errors.New("build secrets (build.secrets) are not supported when triggering remote deployments (--remote)")
+		}
 		var url string
 		// Invoke a remote build/push/deploy pipeline
 		// Returned is the function with fields like Registry, f.Deploy.Image &
@@ -464,9 +836,10 @@ For more options, run 'func deploy --help'`, err)
 			if err != nil {
 				return
 			}
-			// image is valid and undigested
+			// image is valid and undigested, tagged as last built if its tag
+			// has expressions
 			if !digested {
-				f.Deploy.Image = cfg.Image
+				f.Deploy.Image = f.TaggedImage()
 			}
 		}
 
@@ -480,6 +853,14 @@ For more options, run 'func deploy --help'`, err)
 			if f, justBuilt, err = build(cmd, cfg.Build, f, client, buildOptions); err != nil {
 				return
 			}
+			if justBuilt {
+				if err = writeSBOMOutput(f, cfg.SBOMOutput); err != nil {
+					return
+				}
+				if err = scanImage(cmd.Context(), cmd.ErrOrStderr(), client, f, cfg.Builder, cfg.ScanOutput); err != nil {
+					return
+				}
+			}
 			if cfg.Push {
 				if f, justPushed, err = client.Push(cmd.Context(), f); err != nil {
 					return
@@ -493,14 +874,35 @@ For more options, run 'func deploy --help'`, err)
 				f.Deploy.Image = f.Build.Image
 			}
 		}
-		if f, err = client.Deploy(cmd.Context(), f, fn.WithDeploySkipBuildCheck(cfg.Build == "false")); err != nil {
-			if errors.Is(err, fn.ErrInvalidKubeconfig) {
-				return wrapInvalidKubeconfigError(err)
+		if cfg.OutputManifests != "" {
+			if err = renderManifests(cmd, cfg, f, client, fn.DryRunClient); err != nil {
+				return
 			}
-			if errors.Is(err, fn.ErrClusterNotAccessible) {
-				return wrapClusterNotAccessibleError(err)
+		} else if len(cfg.Contexts) > 0 {
+			return runDeployContexts(cmd, cfg, f, newClient, clientOptions)
+		} else {
+			var deployed fn.Function
+			if deployed, err = cfg.withEnvFiles(f); err != nil {
+				return
 			}
-			return
+			if deployed, err = client.Deploy(cmd.Context(), deployed,
+				fn.WithDeploySkipBuildCheck(cfg.Build == "false"),
+				fn.WithDeployPinDigest(cfg.PinDigest)); err != nil {
+				if errors.Is(err, fn.ErrInvalidKubeconfig) {
+					return wrapInvalidKubeconfigError(err)
+				}
+				if errors.Is(err, fn.ErrClusterNotAccessible) {
+					return wrapClusterNotAccessibleError(err)
+				}
+				// Deployed regardless, so where is recorded.
+				if errors.Is(err, fn.ErrPostDeployHookFailed) {
+					f.Deploy.Namespace = deployed.Deploy.Namespace
+					return errors.Join(err, f.Write())
+				}
+				return
+			}
+			deployed.Run.Envs = f.Run.Envs
+			f = deployed
 		}
 	}
 
@@ -558,6 +960,10 @@ func NewRegistryValidator(path string) survey.Validator {
 			return err
 		}
 
+		if err := utils.ValidateRegistry(val.(string)); err != nil {
+			return err
+		}
+
 		// Set the function's registry to that provided
 		f.Registry = val.(string)
 
@@ -604,6 +1010,9 @@ type deployConfig struct {
 	// Env variables.  May include removals using a "-"
 	Env []string
 
+	// EnvFiles of environment variables, applied in order before Env.
+	EnvFiles []string
+
 	// Domain to use for the function's route.  Default is to let the cluster
 	// apply its default.  If configured to use domain matching, the given domain
 	// will be used.  This configuration, in short, is to configure the
@@ -660,6 +1069,53 @@ type deployConfig struct {
 	//Service account to be used in deployed function
 	ServiceAccountName string
 
+	// Traffic split between revisions, such as "latest=90,prev=10".  If not
+	// provided, that of the function is retained.
+	Traffic string
+
+	// Strategy of routing the traffic of a new revision: "latest" or
+	// "blue-green".
+	Strategy string
+
+	// Deployer of the function: "knative" or "k8s".
+	Deployer string
+
+	// CustomDomains to map to the function, or to unmap if suffixed "-".
+	CustomDomains []string
+
+	// RouteVisibility of the function: "public", "cluster-local" or "none".
+	RouteVisibility string
+
+	// RouteAnnotations to set of the function's OpenShift Route as KEY=VALUE,
+	// or to remove if KEY-.
+	RouteAnnotations []string
+
+	// OutputManifests is the directory to which the resources which would be
+	// deployed are written, rather than deploying.
+	OutputManifests string
+
+	// Wait is the condition of the deployment upon which to return: "none",
+	// "ready" or "traffic-shifted".
+	Wait string
+
+	// WaitTimeout is the longest to wait for the Wait condition to be met.
+	WaitTimeout time.Duration
+
+	// Retries is the number of attempts at applying the function's resources
+	// upon conflicts or transient errors of the cluster.
+	Retries int
+
+	// RetryTimeout is the longest for which the attempts are made.
+	RetryTimeout time.Duration
+
+	// PinDigest deploys the image by its digest in the registry rather than
+	// by its tag.
+	PinDigest bool
+
+	// DryRun prints the resources which would be deployed, rendered by the
+	// client or admitted by the server, rather than deploying.
+	DryRun string
+
 	// Remote indicates the deployment (and possibly build) process are to
 	// be triggered in a remote environment rather than run locally.
 	Remote bool
@@ -671,9 +1127,41 @@ type deployConfig struct {
 	// PVCSize configures the PVC size used by the pipeline if --remote flag is set.
 	PVCSize string
 
+	// PipelineTemplate is the path of a template of the Pipeline run if the
+	// --remote flag is set.
+	PipelineTemplate string
+
 	// Timestamp the built contaienr with the current date and time.
 	// This is currently only supported by the Pack builder.
 	Timestamp bool
+
+	// Yes skips confirmation of the first deployment to a target.
+	Yes bool
+
+	// Contexts of the kubeconfig, of the clusters to which to deploy rather
+	// than that of the current context.
+	Contexts []string
+
+	// Concurrent deploys to each of the Contexts at once, rather than in turn.
+	Concurrent bool
+
+	// MinScale, MaxScale, ConcurrencyTarget, ConcurrencyLimit and
+	// ScaleUtilization replace the autoscaling options of the function.  Each
+	// is nil if not provided, retaining that of the function.
+	MinScale          *int64
+	MaxScale          *int64
+	ConcurrencyTarget *float64
+	ConcurrencyLimit  *int64
+	ScaleUtilization  *float64
+
+	// ScaleClass and ScaleMetric replace the autoscaler of the function and
+	// the metric by which it scales.  Each is nil if not provided.
+	ScaleClass  *string
+	ScaleMetric *string
+
+	// RevisionHistory replaces the number of old revisions of the function
+	// retained.  It is nil if not provided.
+	RevisionHistory *int64
 }
 
 // newDeployConfig creates a buildConfig populated from command flags and
@@ -682,17 +1170,64 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 	cfg := deployConfig{
 		buildConfig:        newBuildConfig(),
 		Build:              viper.GetString("build"),
+		Concurrent:         viper.GetBool("concurrent"),
+		Contexts:           viper.GetStringSlice("context"),
 		Env:                viper.GetStringSlice("env"),
 		Domain:             viper.GetString("domain"),
+		DryRun:             viper.GetString("dry-run"),
 		GitBranch:          viper.GetString("git-branch"),
 		GitDir:             viper.GetString("git-dir"),
 		GitURL:             viper.GetString("git-url"),
 		Namespace:          viper.GetString("namespace"),
+		OutputManifests:    viper.GetString("output-manifests"),
 		Remote:             viper.GetBool("remote"),
 		RemoteStorageClass: viper.GetString("remote-storage-class"),
 		PVCSize:            viper.GetString("pvc-size"),
+		PipelineTemplate:   viper.GetString("pipeline-template"),
 		Timestamp:          viper.GetBool("build-timestamp"),
 		ServiceAccountName: viper.GetString("service-account"),
+		Strategy:           viper.GetString("strategy"),
+		Deployer:           viper.GetString("deployer"),
+		RouteVisibility:    viper.GetString("route-visibility"),
+		Traffic:            viper.GetString("traffic"),
+		PinDigest:          viper.GetBool("pin-digest"),
+		Wait:               viper.GetString("wait"),
+		Retries:            viper.GetInt("retries"),
+		RetryTimeout:       viper.GetDuration("retry-timeout"),
+		WaitTimeout:        viper.GetDuration("wait-timeout"),
+		Yes:                viper.GetBool("yes"),
+	}
+	if viper.IsSet("min-scale") {
+		v := viper.GetInt64("min-scale")
+		cfg.MinScale = &v
+	}
+	if viper.IsSet("max-scale") {
+		v := viper.GetInt64("max-scale")
+		cfg.MaxScale = &v
+	}
+	if viper.IsSet("concurrency-target") {
+		v := viper.GetFloat64("concurrency-target")
+		cfg.ConcurrencyTarget = &v
+	}
+	if viper.IsSet("concurrency-limit") {
+		v := viper.GetInt64("concurrency-limit")
+		cfg.ConcurrencyLimit = &v
+	}
+	if viper.IsSet("scale-utilization") {
+		v := viper.GetFloat64("scale-utilization")
+		cfg.ScaleUtilization = &v
+	}
+	if viper.IsSet("scale-class") {
+		v := viper.GetString("scale-class")
+		cfg.ScaleClass = &v
+	}
+	if viper.IsSet("scale-metric") {
+		v := viper.GetString("scale-metric")
+		cfg.ScaleMetric = &v
+	}
+	if viper.IsSet("revision-history") {
+		v := viper.GetInt64("revision-history")
+		cfg.RevisionHistory = &v
 	}
 	// NOTE: .Env should be viper.GetStringSlice, but this returns unparsed
 	// results and appears to be an open issue since 2017:
@@ -701,6 +1236,18 @@ func newDeployConfig(cmd *cobra.Command) deployConfig {
 	if cfg.Env, err = cmd.Flags().GetStringArray("env"); err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error reading envs: %v", err)
 	}
+	if cfg.EnvFiles, err = cmd.Flags().GetStringArray("env-file"); err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error reading env files: %v", err)
+	}
+	if cfg.BuildEnvs, err = cmd.Flags().GetStringArray("build-env"); err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error reading build envs: %v", err)
+	}
+	if cfg.CustomDomains, err = cmd.Flags().GetStringArray("custom-domain"); err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error reading custom domains: %v", err)
+	}
+	if cfg.RouteAnnotations, err = cmd.Flags().GetStringArray("route-annotation"); err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error reading route annotations: %v", err)
+	}
 
 	return cfg
 }
@@ -726,7 +1273,10 @@ func (c deployConfig) Configure(f fn.Function) (fn.Function, error) {
 	f.Build.Git.ContextDir = c.GitDir
 	f.Build.Git.Revision = c.GitBranch // TODO: should match; perhaps "refSpec"
 	f.Build.RemoteStorageClass = c.RemoteStorageClass
+	f.Build.PipelineTemplate = c.PipelineTemplate
 	f.Deploy.ServiceAccountName = c.ServiceAccountName
+	f.Deploy.Strategy = c.Strategy
+	f.Deploy.Deployer = c.Deployer
 	f.Local.Remote = c.Remote
 
 	// PVCSize
@@ -738,10 +1288,126 @@ func (c deployConfig) Configure(f fn.Function) (fn.Function, error) {
 		f.Build.PVCSize = c.PVCSize
 	}
 
+	// Traffic
+	// Splits replace those of the function, retaining the tag of each
+	// revision given in func.yaml.
+	if c.Traffic != "" {
+		tt, err := fn.ParseTraffic(c.Traffic)
+		if err != nil {
+			return f, err
+		}
+		for i := range tt {
+			for _, t := range f.Deploy.Traffic {
+				if t.Revision == tt[i].Revision {
+					tt[i].Tag = t.Tag
+				}
+			}
+		}
+		f.Deploy.Traffic = tt
+	}
+	// Custom domains
+	// Those given are added to those of the function, or removed if suffixed.
+	for _, d := range c.CustomDomains {
+		if domain, ok := strings.CutSuffix(d, "-"); ok {
+			f.Deploy.Domains = slices.DeleteFunc(f.Deploy.Domains, func(s string) bool { return s == domain })
+		} else if !slices.Contains(f.Deploy.Domains, d) {
+			f.Deploy.Domains = append(f.Deploy.Domains, d)
+		}
+	}
+
+	// Route
+	// The visibility given replaces that of the function, and the annotations
+	// given are added to its own, or removed if suffixed.
+	if c.RouteVisibility != "" || len(c.RouteAnnotations) > 0 {
+		r := fn.RouteSpec{}
+		if f.Deploy.Route != nil {
+			r = *f.Deploy.Route
+		}
+		if c.RouteVisibility != "" {
+			r.Visibility = c.RouteVisibility
+		}
+		r.Annotations = maps.Clone(r.Annotations)
+		for _, a := range c.RouteAnnotations {
+			if k, ok := strings.CutSuffix(a, "-"); ok && !strings.Contains(a, "=") {
+				delete(r.Annotations, k)
+				continue
+			}
+			if r.Annotations == nil {
+				r.Annotations = map[string]string{}
+			}
+			k, v, _ := strings.Cut(a, "=")
+			r.Annotations[k] = v
+		}
+		if len(r.Annotations) == 0 {
+			r.Annotations = nil
+		}
+		f.Deploy.Route = &r
+		if r.Visibility == "" && r.Annotations == nil {
+			f.Deploy.Route = nil
+		}
+	}
+
+	if c.Traffic != "" || c.Strategy != "" || len(c.CustomDomains) > 0 || c.RouteVisibility != "" || len(c.RouteAnnotations) > 0 {
+		if err = f.Validate(); err != nil { // such as splits not totalling 100
+			return f, err
+		}
+	}
+
+	// Autoscaling
+	// Options provided replace those of the function.
+	if c.MinScale != nil || c.MaxScale != nil || c.ConcurrencyTarget != nil || c.ScaleUtilization != nil ||
+		c.ScaleClass != nil || c.ScaleMetric != nil {
+		if f.Deploy.Options.Scale == nil {
+			f.Deploy.Options.Scale = &fn.ScaleOptions{}
+		}
+		if c.MinScale != nil {
+			f.Deploy.Options.Scale.Min = c.MinScale
+		}
+		if c.MaxScale != nil {
+			f.Deploy.Options.Scale.Max = c.MaxScale
+		}
+		if c.ConcurrencyTarget != nil {
+			f.Deploy.Options.Scale.Target = c.ConcurrencyTarget
+		}
+		if c.ScaleUtilization != nil {
+			f.Deploy.Options.Scale.Utilization = c.ScaleUtilization
+		}
+		if c.ScaleClass != nil {
+			f.Deploy.Options.Scale.Class = c.ScaleClass
+		}
+		if c.ScaleMetric != nil {
+			f.Deploy.Options.Scale.Metric = c.ScaleMetric
+		}
+	}
+	if c.ConcurrencyLimit != nil {
+		if f.Deploy.Options.Resources == nil {
+			f.Deploy.Options.Resources = &fn.ResourcesOptions{}
+		}
+		if f.Deploy.Options.Resources.Limits == nil {
+			f.Deploy.Options.Resources.Limits = &fn.ResourcesLimitsOptions{}
+		}
+		f.Deploy.Options.Resources.Limits.Concurrency = c.ConcurrencyLimit
+	}
+	if c.MinScale != nil || c.MaxScale != nil || c.ConcurrencyTarget != nil || c.ConcurrencyLimit != nil || c.ScaleUtilization != nil ||
+		c.ScaleClass != nil || c.ScaleMetric != nil {
+		if err = f.Validate(); err != nil { // such as a min exceeding the max, or the hpa class scaling to zero
+			return f, err
+		}
+	}
+
+	// Revision History
+	if c.RevisionHistory != nil {
+		f.Deploy.Options.RevisionHistoryLimit = c.RevisionHistory
+		if err = f.Validate(); err != nil {
+			return f, err
+		}
+	}
+
 	// Envs
 	// Preprocesses any Envs provided (which may include removals) into a final
-	// set
-	f.Run.Envs, err = applyEnvs(f.Run.Envs, c.Env)
+	// set.  Those of env files are not saved, being applied to each
+	// deployment alone (see withEnvFiles).
+	f.Run.Envs, err = applyEnvs(f.Run.Envs, nil, c.Env)
 	if err != nil {
 		return f, err
 	}
@@ -757,11 +1423,57 @@ func (c deployConfig) Configure(f fn.Function) (fn.Function, error) {
 	return f, nil
 }
 
+// withEnvFiles returns the function to be deployed, with the envs of the env
+// files applied over those it has other than those set or unset by --env,
+// which were applied by Configure and take precedence.  The function written
+// is that without them.
+func (c deployConfig) withEnvFiles(f fn.Function) (fn.Function, error) {
+	if len(c.EnvFiles) == 0 {
+		return f, nil
+	}
+	var fromFiles []string
+	for _, file := range c.EnvFiles {
+		envs, err := utils.ParseEnvFile(file)
+		if err != nil {
+			return f, fmt.Errorf("cannot read env file: %w", err)
+		}
+		fromFiles = append(fromFiles, envs...)
+	}
+	inserts, _, err := util.OrderedMapAndRemovalListFromArray(fromFiles, "=")
+	if err != nil {
+		return f, err
+	}
+	given, removals, err := util.OrderedMapAndRemovalListFromArray(c.Env, "=")
+	if err != nil {
+		return f, err
+	}
+	it := given.Iterator()
+	for name, _, ok := it.NextString(); ok; name, _, ok = it.NextString() {
+		inserts.Delete(name)
+	}
+	for _, name := range removals {
+		inserts.Delete(name)
+	}
+	f.Run.Envs, _, err = mergeEnvs(slices.Clone(f.Run.Envs), inserts, nil)
+	return f, err
+}
+
 // Apply Env additions/removals to a set of extant envs, returning the final
-// merged list.
-func applyEnvs(current []fn.Env, args []string) (final []fn.Env, err error) {
+// merged list.  The envs of the given env files are applied first, in order,
+// such that those of later files, and then those of args, take precedence.
+func applyEnvs(current []fn.Env, files, args []string) (final []fn.Env, err error) {
 	// TODO: validate env test cases completely validate this functionality
 
+	var fromFiles []string
+	for _, file := range files {
+		envs, err := utils.ParseEnvFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read env file: %w", err)
+		}
+		fromFiles = append(fromFiles, envs...)
+	}
+	args = append(fromFiles, args...)
+
 	// Parse and Merge
 	inserts, removals, err := util.OrderedMapAndRemovalListFromArray(args, "=")
 	if err != nil {
@@ -886,6 +1598,106 @@ func (c deployConfig) Validate(cmd *cobra.Command) (err error) {
 		return errors.New("git settings (--git-url --git-dir and --git-branch) are only applicable when triggering remote deployments (--remote)")
 	}
 
+	if c.DryRun != "" {
+		if !fn.ValidDryRun(fn.DryRun(c.DryRun)) {
+			return fmt.Errorf("unrecognized value for --dry-run '%v'.  Accepts '%v' or '%v'", c.DryRun, fn.DryRunClient, fn.DryRunServer)
+		}
+		if c.Remote {
+			return errors.New("a dry run (--dry-run) is not supported when triggering remote deployments (--remote)")
+		}
+	}
+
+	switch c.Deployer {
+	case "", fn.DeployerKnative:
+	case fn.DeployerK8s:
+		if c.Remote {
+			return errors.New("the k8s deployer (--deployer k8s) is not supported when triggering remote deployments (--remote)")
+		}
+	default:
+		return fmt.Errorf("unrecognized value for --deployer '%v'.  Accepts '%v' or '%v'", c.Deployer, fn.DeployerKnative, fn.DeployerK8s)
+	}
+
+	if !fn.ValidWait(fn.Wait(c.Wait)) {
+		return fmt.Errorf("unrecognized value for --wait '%v'.  Accepts '%v', '%v' or '%v'", c.Wait, fn.WaitNone, fn.WaitReady, fn.WaitTrafficShifted)
+	}
+	if c.WaitTimeout <= 0 {
+		return fmt.Errorf("invalid --wait-timeout '%v'.  Must be positive", c.WaitTimeout)
+	}
+	if c.Remote && (cmd.Flags().Changed("wait") || cmd.Flags().Changed("wait-timeout")) {
+		return errors.New("waiting (--wait and --wait-timeout) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Retries < 1 {
+		return fmt.Errorf("invalid --retries '%v'.  Must be at least 1", c.Retries)
+	}
+	if c.RetryTimeout <= 0 {
+		return fmt.Errorf("invalid --retry-timeout '%v'.  Must be positive", c.RetryTimeout)
+	}
+	if c.Remote && (cmd.Flags().Changed("retries") || cmd.Flags().Changed("retry-timeout")) {
+		return errors.New("retrying (--retries and --retry-timeout) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && c.Progress == progressJSON {
+		return errors.New("json progress (--progress json) is not supported when triggering remote deployments (--remote)")
+	}
+	switch c.RouteVisibility {
+	case "", fn.RouteVisibilityPublic, fn.RouteVisibilityClusterLocal, fn.RouteVisibilityNone:
+	default:
+		return fmt.Errorf("unrecognized value for --route-visibility '%v'.  Accepts '%v', '%v' or '%v'", c.RouteVisibility, fn.RouteVisibilityPublic, fn.RouteVisibilityClusterLocal, fn.RouteVisibilityNone)
+	}
+	for _, a := range c.RouteAnnotations {
+		if k, _, ok := strings.Cut(a, "="); (!ok && !strings.HasSuffix(a, "-")) || k == "" || k == "-" {
+			return fmt.Errorf("invalid --route-annotation '%v'.  Must be of the form KEY=VALUE or KEY-", a)
+		}
+	}
+	if c.Remote && c.Platforms != "" {
+		return errors.New("building for several platforms (--platforms) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && ((c.TagStrategy != "" && c.TagStrategy != fn.TagStrategyLatest) || fn.HasTagExpressions(c.Image)) {
+		return errors.New("deriving the tag of the image (--tag-strategy) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && c.SBOMOutput != "" {
+		return errors.New("writing the SBOM (--sbom-output) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && cmd.Flags().Changed("scan") && c.Scan {
+		return errors.New("scanning the image (--scan) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && cmd.Flags().Changed("sign") && c.Sign {
+		return errors.New("signing the image (--sign) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && cmd.Flags().Changed("incremental") && c.Incremental {
+		return errors.New("incremental builds (--incremental) are not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && cmd.Flags().Changed("dockerfile") {
+		return errors.New("building a Dockerfile (--dockerfile) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && c.TrustBuilder != nil && *c.TrustBuilder {
+		return errors.New("trusting the builder image (--trust-builder) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && c.PinDigest {
+		return errors.New("pinning the digest (--pin-digest) is not supported when triggering remote deployments (--remote)")
+	}
+	if c.Remote && len(c.EnvFiles) > 0 {
+		return errors.New("env files (--env-file) are not supported when triggering remote deployments (--remote)")
+	}
+
+	if len(c.Contexts) > 0 {
+		if c.Remote || c.DryRun != "" || c.OutputManifests != "" {
+			return errors.New("deploying to contexts (--context) is not supported with --remote, --dry-run or --output-manifests")
+		}
+		seen := map[string]bool{}
+		for _, name := range c.Contexts {
+			if name == "" || seen[name] {
+				return fmt.Errorf("invalid --context %q: contexts must be named, and each given once", name)
+			}
+			seen[name] = true
+		}
+	} else if c.Concurrent {
+		return errors.New("--concurrent requires the contexts to which to deploy (--context)")
+	}
+
+	if c.OutputManifests != "" && c.Remote {
+		return errors.New("writing manifests (--output-manifests) is not supported when triggering remote deployments (--remote)")
+	}
+
 	// Git URL can contain at maximum one '#'
 	urlParts := strings.Split(c.GitURL, "#")
 	if len(urlParts) > 2 {
@@ -899,6 +1711,199 @@ func (c deployConfig) Validate(cmd *cobra.Command) (err error) {
 	return
 }
 
+// WithValues returns a context populated with values from the deploy config
+// which are provided to the system via the context, including those of the
+// embedded build config.  Progress of the deployment is reported to w.
+func (c deployConfig) WithValues(ctx context.Context, stdout, stderr io.Writer) context.Context {
+	ctx = c.buildConfig.WithValues(ctx)
+	ctx = context.WithValue(ctx, fn.DeployWaitKey{}, fn.Wait(c.Wait))
+	ctx = context.WithValue(ctx, fn.DeployWaitTimeoutKey{}, c.WaitTimeout)
+	ctx = context.WithValue(ctx, fn.DeployRetriesKey{}, c.Retries)
+	ctx = context.WithValue(ctx, fn.DeployRetryTimeoutKey{}, c.RetryTimeout)
+	if c.Progress == progressJSON {
+		ctx = context.WithValue(ctx, fn.DeployEventsKey{}, jsonProgress(stdout))
+	} else {
+		ctx = context.WithValue(ctx, fn.DeployEventsKey{}, textProgress(stderr))
+	}
+	return ctx
+}
+
+const (
+	progressText = "text"
+	progressJSON = "json"
+)
+
+// textProgress reports the stages of the revision deployed as they are
+// reached.  The build, push and deployment are reported by their own
+// messages, so their stages are not.
+func textProgress(w io.Writer) func(fn.DeployEvent) {
+	return func(e fn.DeployEvent) {
+		switch e.Stage {
+		case fn.StageBuildStarted, fn.StagePhase, fn.StageBuilt, fn.StagePushProgress, fn.StageImagePushed, fn.StageDeployed:
+			return
+		}
+		fmt.Fprintf(w, "⏳ %v %v (%v)\n", e.Stage, e.Revision, e.Elapsed.Round(time.Millisecond))
+	}
+}
+
+// progressEvent is a stage of the deployment as reported in JSON, with the
+// time at which it was reached.
+type progressEvent struct {
+	fn.DeployEvent
+	Time time.Time `json:"time"`
+}
+
+// jsonProgress reports each stage of the deployment as it is reached as a
+// JSON object on a line of its own, such that it is parsed by CI.  Safe for
+// concurrent use, as are the deployments to multiple contexts.
+func jsonProgress(w io.Writer) func(fn.DeployEvent) {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e fn.DeployEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(progressEvent{DeployEvent: e, Time: time.Now().UTC()})
+	}
+}
+
+// runDeployDryRun prints the resources which deploying the function would
+// create or update.  The function is neither built nor written.
+func runDeployDryRun(cmd *cobra.Command, cfg deployConfig, f fn.Function, newClient ClientFactory) (err error) {
+	switch {
+	case cfg.Image != "":
+		f.Deploy.Image = f.TaggedImage()
+	case f.Build.Image != "":
+		f.Deploy.Image = f.Build.Image
+	default:
+		if f.Deploy.Image, err = f.ImageName(); err != nil {
+			return
+		}
+	}
+
+	clientOptions, err := cfg.clientOptions()
+	if err != nil {
+		return
+	}
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure, Deployer: f.Deploy.Deployer}, clientOptions...)
+	defer done()
+
+	return renderManifests(cmd, cfg, f, client, fn.DryRun(cfg.DryRun))
+}
+
+// renderManifests of the resources which deploying the function would create
+// or update, printed or, with --output-manifests, written to a directory.
+func renderManifests(cmd *cobra.Command, cfg deployConfig, f fn.Function, client *fn.Client, mode fn.DryRun) error {
+	f, err := cfg.withEnvFiles(f)
+	if err != nil {
+		return err
+	}
+	manifests, err := client.Render(cmd.Context(), f, mode)
+	if err != nil {
+		if errors.Is(err, fn.ErrInvalidKubeconfig) {
+			return wrapInvalidKubeconfigError(err)
+		}
+		if errors.Is(err, fn.ErrClusterNotAccessible) {
+			return wrapClusterNotAccessibleError(err)
+		}
+		return err
+	}
+	if cfg.OutputManifests == "" {
+		_, err = cmd.OutOrStdout().Write(manifests)
+		return err
+	}
+	files, err := writeManifests(cfg.OutputManifests, f, manifests)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Manifests of %v written to %v:\n", f.Name, cfg.OutputManifests)
+	for _, file := range files {
+		fmt.Fprintf(cmd.OutOrStdout(), "  %v\n", file)
+	}
+	return nil
+}
+
+// ErrDeployNotConfirmed is returned when the first deployment of a function
+// to a target is declined, or is not confirmed by --yes without a terminal
+// on which to decline it.
+var ErrDeployNotConfirmed = errors.New("deployment not confirmed")
+
+// kubeContexts to which to deploy, being only the current context (empty)
+// unless contexts are given.
+func (c deployConfig) kubeContexts() []string {
+	if len(c.Contexts) == 0 {
+		return []string{""}
+	}
+	return c.Contexts
+}
+
+// confirmTarget of a deployment: the namespace of the cluster of the
+// kubeconfig context of ctx, by default the current context.  Before the
+// first deployment of the function to such a target, being one in which the
+// function is not found, a summary is printed and confirmation requested
+// unless --yes, which is required if there is no interactive terminal.
+// Should the cluster not be reachable, no confirmation is requested, the
+// deployment failing on its own.
+func confirmTarget(ctx context.Context, cmd *cobra.Command, cfg deployConfig, f fn.Function, client *fn.Client) error {
+	kubeContext, server, err := k8s.GetCurrentContextFrom(ctx)
+	if err != nil {
+		return nil // no cluster context: deployment fails on its own
+	}
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		if namespace, _, err = k8s.GetClientConfigFrom(ctx).Namespace(); err != nil {
+			namespace = DefaultNamespace
+		}
+	}
+	if _, err = client.Describe(ctx, f.Name, namespace, f); !errors.Is(err, fn.ErrFunctionNotFound) {
+		return nil // already deployed, or the cluster is not reachable
+	}
+
+	image := f.Image
+	if image == "" {
+		image, _ = f.ImageName()
+	}
+	if image == "" {
+		image = "(none)"
+	}
+	registry := f.Registry
+	if registry == "" {
+		registry = "(none)"
+	}
+	builder := cfg.Builder
+	if cfg.Remote {
+		builder = fmt.Sprintf("%v (on cluster)", builder)
+	}
+	out := cmd.ErrOrStderr()
+	fmt.Fprintf(out, "First deployment of %q to this cluster and namespace:\n", f.Name)
+	fmt.Fprintf(out, "  Cluster:   %v\n", server)
+	fmt.Fprintf(out, "  Context:   %v\n", kubeContext)
+	fmt.Fprintf(out, "  Namespace: %v\n", namespace)
+	fmt.Fprintf(out, "  Image:     %v\n", image)
+	fmt.Fprintf(out, "  Registry:  %v\n", registry)
+	fmt.Fprintf(out, "  Builder:   %v\n", builder)
+
+	if cfg.Yes {
+		return nil
+	}
+	if !interactiveTerminal() {
+		return fmt.Errorf("%w: confirm the first deployment with --yes when not run in a terminal", ErrDeployNotConfirmed)
+	}
+	confirmed := false
+	if err = survey.AskOne(&survey.Confirm{
+		Message: "Deploy?",
+		Default: false,
+	}, &confirmed); err != nil {
+		return err
+	}
+	if !confirmed {
+		return ErrDeployNotConfirmed
+	}
+	return nil
+}
+
 // printDeployMessages to the output.  Non-error deployment messages.
 func printDeployMessages(out io.Writer, f fn.Function) {
 	digest, err := isDigested(f.Image)
@@ -987,6 +1992,9 @@ func getCurrentGitBranch() (string, error) {
 // isDigested checks that the given image reference has a digest. Invalid
 // reference return error.
 func isDigested(v string) (validDigest bool, err error) {
+	if fn.HasTagExpressions(v) {
+		return false, nil // tagged when built
+	}
 	ref, err := name.ParseReference(v)
 	if err != nil {
 		return false, err
@@ -994,3 +2002,27 @@ func isDigested(v string) (validDigest bool, err error) {
 	_, ok := ref.(name.Digest)
 	return ok, nil
 }
+
+// isOpenShift reports whether the current cluster is OpenShift.  A variable
+// such that tests may mock it.
+var isOpenShift = k8s.IsOpenShift
+
+// openShiftRegistryOf the namespace to which the function is to be deployed:
+// that requested, else that to which it was deployed, else the current.
+func openShiftRegistryOf(cfg deployConfig, f fn.Function) string {
+	switch {
+	case cfg.Namespace != "":
+		return k8s.OpenShiftRegistry(cfg.Namespace)
+	case f.Deploy.Namespace != "":
+		return k8s.OpenShiftRegistry(f.Deploy.Namespace)
+	}
+	return k8s.GetDefaultOpenShiftRegistry()
+}
+
+// routeVisibility of the function, if its route is defined.
+func routeVisibility(f fn.Function) string {
+	if f.Deploy.Route == nil {
+		return ""
+	}
+	return f.Deploy.Route.Visibility
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// contextResult of deploying a function to the cluster of a kubeconfig
+// context.
+type contextResult struct {
+	Context   string
+	Namespace string
+	Err       error
+	f         fn.Function
+}
+
+// runDeployContexts deploys the function, already built and pushed, to the
+// cluster of each of the contexts, with a client of its own, reporting the
+// result of each.  The function is written, without the envs of its env
+// files, should any deployment succeed; an error is returned should any fail.
+func runDeployContexts(cmd *cobra.Command, cfg deployConfig, f fn.Function, newClient ClientFactory, clientOptions []fn.Option) error {
+	deployed, err := cfg.withEnvFiles(f)
+	if err != nil {
+		return err
+	}
+	results := deployContexts(cmd.Context(), cfg, deployed, newClient, clientOptions)
+	writeContextResults(cmd.OutOrStdout(), results)
+
+	var failed []string
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r.Context)
+			continue
+		}
+		f.Deploy.Namespace = r.f.Deploy.Namespace
+	}
+	if len(failed) == len(results) {
+		return fmt.Errorf("deploy failed to all contexts: %w", errors.Join(contextErrors(results)...))
+	}
+	if err := f.Write(); err != nil {
+		return err
+	}
+	if err := f.Stamp(); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("deploy failed to %d of %d contexts: %w", len(failed), len(results), errors.Join(contextErrors(results)...))
+	}
+	return nil
+}
+
+// deployContexts deploys the function to each of the contexts, in turn or,
+// if configured, at once.  Results are in the order of the contexts.
+func deployContexts(ctx context.Context, cfg deployConfig, f fn.Function, newClient ClientFactory, clientOptions []fn.Option) []contextResult {
+	results := make([]contextResult, len(cfg.Contexts))
+	deploy := func(i int) {
+		client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.RegistryInsecure, Deployer: f.Deploy.Deployer}, clientOptions...)
+		defer done()
+		r := contextResult{Context: cfg.Contexts[i], Namespace: f.Namespace}
+		if r.f, r.Err = client.Deploy(k8s.WithContext(ctx, r.Context), f, fn.WithDeploySkipBuildCheck(cfg.Build == "false"), fn.WithDeployPinDigest(cfg.PinDigest)); r.Err == nil {
+			r.Namespace = r.f.Deploy.Namespace
+		}
+		results[i] = r
+	}
+	if !cfg.Concurrent {
+		for i := range cfg.Contexts {
+			deploy(i)
+		}
+		return results
+	}
+	var wg sync.WaitGroup
+	for i := range cfg.Contexts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			deploy(i)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// writeContextResults as a table of the context, namespace and result of
+// each deployment.
+func writeContextResults(w io.Writer, results []contextResult) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTEXT\tNAMESPACE\tRESULT")
+	for _, r := range results {
+		result := "deployed"
+		if r.Err != nil {
+			result = "failed: " + r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\n", r.Context, r.Namespace, result)
+	}
+	tw.Flush()
+}
+
+// contextErrors of the deployments which failed, each naming its context.
+func contextErrors(results []contextResult) (errs []error) {
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", r.Context, r.Err))
+		}
+	}
+	return
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+)
+
+// kustomizationFile of the manifests written, listing each as a resource such
+// that the directory may be applied with kubectl apply -k or synced by GitOps
+// tooling.
+const kustomizationFile = "kustomization.yaml"
+
+// writeManifests of the YAML stream rendered of a function to the directory,
+// one file per resource named by its kind and name, and a kustomization of
+// them.  The resources the function references but which are not deployed
+// with it, such as its Secrets, are noted in the kustomization.  Encrypted
+// values are rendered redacted, but those of local environment variables are
+// not, so the manifests of a function interpolating any are readable only by
+// the user.  Returned are the names of the files written.
+func writeManifests(dir string, f fn.Function, manifests []byte) (files []string, err error) {
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	mode := os.FileMode(0o644)
+	if fn.InterpolatesLocalEnvs(f.Run.Envs) {
+		mode = 0o600
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		var m map[string]any
+		if err = dec.Decode(&m); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot read the rendered manifests: %w", err)
+		}
+		kind, _ := m["kind"].(string)
+		metadata, _ := m["metadata"].(map[string]any)
+		name, _ := metadata["name"].(string)
+		file := strings.ToLower(kind) + "-" + name + ".yaml"
+		if err = writeYAML(filepath.Join(dir, file), "", m, mode); err != nil {
+			return
+		}
+		files = append(files, file)
+	}
+
+	refs, err := knative.ReferencesOf(f)
+	if err != nil {
+		return
+	}
+	kustomization := map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  files,
+	}
+	if err = writeYAML(filepath.Join(dir, kustomizationFile), referencesComment(refs), kustomization, 0o644); err != nil {
+		return
+	}
+	return append(files, kustomizationFile), nil
+}
+
+// referencesComment of the kustomization, listing the resources referenced by
+// the function which are not written, being neither the function's to render
+// nor safe to commit, and so must exist in the namespace to which the
+// manifests are applied.
+func referencesComment(r knative.References) string {
+	var b strings.Builder
+	b.WriteString("# Rendered by func deploy --output-manifests.\n")
+	list := func(kind string, names []string) {
+		for _, name := range names {
+			fmt.Fprintf(&b, "#   %v %v\n", kind, name)
+		}
+	}
+	if len(r.Secrets)+len(r.ConfigMaps)+len(r.PersistentVolumeClaims) > 0 || r.ServiceAccount != "" {
+		b.WriteString("# The function references the following, which must exist in its namespace:\n")
+		list("Secret", r.Secrets)
+		list("ConfigMap", r.ConfigMaps)
+		list("PersistentVolumeClaim", r.PersistentVolumeClaims)
+		if r.ServiceAccount != "" {
+			list("ServiceAccount", []string{r.ServiceAccount})
+		}
+	}
+	return b.String()
+}
+
+// writeYAML of the value to the file, preceded by the comment.
+func writeYAML(path, comment string, v any, mode os.FileMode) error {
+	var buf bytes.Buffer
+	buf.WriteString(comment)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), mode); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode) // of a file written before
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,6 +24,7 @@ import (
 	"knative.dev/func/pkg/k8s"
 	"knative.dev/func/pkg/mock"
 	. "knative.dev/func/pkg/testing"
+	"knative.dev/func/pkg/utils"
 )
 
 // commandConstructor is used to share test implementations between commands
@@ -465,6 +472,76 @@ func TestDeploy_Envs(t *testing.T) {
 
 }
 
+// TestDeploy_EnvFile ensures that the environment variables of env files are
+// merged with those of the function, those of later files and then of --env
+// taking precedence, that their values are retained as-is, to be
+// interpolated on deploy, and that they are of the deployment alone, not
+// being saved to func.yaml.
+func TestDeploy_EnvFile(t *testing.T) {
+	root := FromTempDirectory(t)
+	ptr := func(s string) *string { return &s }
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry,
+		Run: fn.RunSpec{Envs: fn.Envs{{Name: ptr("A"), Value: ptr("func.yaml")}, {Name: ptr("B"), Value: ptr("func.yaml")}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(".env", []byte("# defaults\nB=env\nC=env\nexport D='{{ secret:mysecret:key }}'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(".env.production", []byte("C=production\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var deployed fn.Envs
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f.Run.Envs
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--env-file=.env", "--env-file=.env.production", "--env=D=flag", "--env=A-"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	expected := fn.Envs{
+		{Name: ptr("B"), Value: ptr("env")},
+		{Name: ptr("D"), Value: ptr("flag")},
+		{Name: ptr("C"), Value: ptr("production")},
+	}
+	if !reflect.DeepEqual(deployed, expected) {
+		t.Fatalf("Expected envs deployed '%v', got '%v'", expected, deployed)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = fn.Envs{
+		{Name: ptr("B"), Value: ptr("func.yaml")},
+		{Name: ptr("D"), Value: ptr("flag")},
+	}
+	if !reflect.DeepEqual(f.Run.Envs, expected) {
+		t.Fatalf("Expected only the envs of --env saved '%v', got '%v'", expected, f.Run.Envs)
+	}
+
+	// Secret references of an env file are retained.
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--env-file=.env"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if got := *deployed[1].Value; *deployed[1].Name != "D" || got != "{{ secret:mysecret:key }}" {
+		t.Fatalf("Expected the secret reference to be retained, got '%v'", got)
+	}
+
+	// A missing env file is an error.
+	cmd = NewDeployCmd(NewTestClient())
+	cmd.SetArgs([]string{"--env-file=.env.missing"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error deploying with a missing env file")
+	}
+}
+
 // TestDeploy_FunctionContext ensures that the function contextually relevant
 // to the current command is loaded and used for flag defaults by spot-checking
 // the builder setting.
@@ -567,6 +644,706 @@ func TestDeploy_GitArgsPersist(t *testing.T) {
 	}
 }
 
+// TestDeploy_Traffic ensures that traffic splits are saved to func.yaml,
+// retaining the tag of each revision given there, and that splits not
+// totalling 100 are rejected.
+func TestDeploy_Traffic(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Traffic = []fn.TrafficSplit{{Revision: "latest", Percent: 100, Tag: "canary"}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--traffic", "latest=10,prev=90"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	want := []fn.TrafficSplit{{Revision: "latest", Percent: 10, Tag: "canary"}, {Revision: "prev", Percent: 90}}
+	if !reflect.DeepEqual(f.Deploy.Traffic, want) {
+		t.Errorf("expected traffic %v, got %v", want, f.Deploy.Traffic)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--traffic", "latest=10,prev=10"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "total 100") {
+		t.Fatalf("expected an error of splits not totalling 100, got %v", err)
+	}
+}
+
+// TestDeploy_Strategy ensures that the strategy is saved to func.yaml, and
+// that a blue-green deployment may not also split the traffic.
+func TestDeploy_Strategy(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--strategy", fn.StrategyBlueGreen})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Strategy != fn.StrategyBlueGreen {
+		t.Errorf("expected strategy %q, got %q", fn.StrategyBlueGreen, f.Deploy.Strategy)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--traffic", "latest=100"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "blue-green") {
+		t.Fatalf("expected an error of splitting the traffic of a blue-green deployment, got %v", err)
+	}
+}
+
+// TestDeploy_Wait ensures that the condition and timeout of the wait are
+// provided to the deployer, which reports its progress, and that unknown
+// conditions are rejected.
+func TestDeploy_Wait(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if w, _ := ctx.Value(fn.DeployWaitKey{}).(fn.Wait); w != fn.WaitTrafficShifted {
+			t.Errorf("expected wait %q, got %q", fn.WaitTrafficShifted, w)
+		}
+		if d, _ := ctx.Value(fn.DeployWaitTimeoutKey{}).(time.Duration); d != 5*time.Minute {
+			t.Errorf("expected wait timeout 5m, got %v", d)
+		}
+		events, _ := ctx.Value(fn.DeployEventsKey{}).(func(fn.DeployEvent))
+		if events == nil {
+			t.Fatal("expected progress reported")
+		}
+		events(fn.DeployEvent{Stage: fn.StageReady, Revision: "f-00001", Elapsed: time.Second})
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: "default"}, nil
+	}
+	var stderr bytes.Buffer
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--wait", "traffic-shifted", "--wait-timeout", "5m"})
+	cmd.SetErr(&stderr)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !deployer.DeployInvoked {
+		t.Fatal("expected the deployer invoked")
+	}
+	if !strings.Contains(stderr.String(), "ready f-00001 (1s)") {
+		t.Errorf("expected the ready stage reported, got %q", stderr.String())
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--wait", "forever"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--wait") {
+		t.Fatalf("expected an error of an unknown wait, got %v", err)
+	}
+}
+
+// TestDeploy_Retries ensures that the attempts at applying the function's
+// resources, and the longest for which they are made, are provided to the
+// deployer, and that invalid values are rejected.
+func TestDeploy_Retries(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if n, _ := ctx.Value(fn.DeployRetriesKey{}).(int); n != 10 {
+			t.Errorf("expected 10 retries, got %v", n)
+		}
+		if d, _ := ctx.Value(fn.DeployRetryTimeoutKey{}).(time.Duration); d != 3*time.Minute {
+			t.Errorf("expected retry timeout 3m, got %v", d)
+		}
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: "default"}, nil
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--retries", "10", "--retry-timeout", "3m"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !deployer.DeployInvoked {
+		t.Fatal("expected the deployer invoked")
+	}
+
+	for _, args := range [][]string{{"--retries", "0"}, {"--retry-timeout", "0s"}} {
+		cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+		cmd.SetArgs(args)
+		if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), args[0]) {
+			t.Fatalf("expected an error of %v, got %v", args, err)
+		}
+	}
+}
+
+// TestDeploy_ProgressJSON ensures that with --progress json each stage of the
+// deployment is written to stdout as a JSON object on a line of its own, and
+// that unknown formats are rejected.
+func TestDeploy_ProgressJSON(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) { return digest, nil }
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		events, _ := ctx.Value(fn.DeployEventsKey{}).(func(fn.DeployEvent))
+		events(fn.DeployEvent{Stage: fn.StageReady, Revision: "f-00001", Elapsed: time.Second})
+		return fn.DeploymentResult{Status: fn.Deployed, URL: "http://f.example.com", Namespace: "default"}, nil
+	}
+	var stdout bytes.Buffer
+	cmd := NewDeployCmd(NewTestClient(fn.WithPusher(pusher), fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--progress", "json"})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(io.Discard)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var stages []fn.DeployStage
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		var e progressEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("expected only JSON events on stdout, got %q", stdout.String())
+		}
+		if e.Time.IsZero() {
+			t.Errorf("expected the time of %v", e.Stage)
+		}
+		switch e.Stage {
+		case fn.StageImagePushed:
+			if e.Digest != digest {
+				t.Errorf("expected the digest pushed, got %q", e.Digest)
+			}
+		case fn.StageReady:
+			if e.Revision != "f-00001" {
+				t.Errorf("expected the revision ready, got %q", e.Revision)
+			}
+		case fn.StageDeployed:
+			if e.URL != "http://f.example.com" {
+				t.Errorf("expected the URL deployed, got %q", e.URL)
+			}
+		}
+		stages = append(stages, e.Stage)
+	}
+	expected := []fn.DeployStage{fn.StageBuildStarted, fn.StageBuilt, fn.StagePhase, fn.StageImagePushed, fn.StageReady, fn.StageDeployed}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Fatalf("expected stages %v, got %v", expected, stages)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--progress", "xml"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--progress") {
+		t.Fatalf("expected an error of an unknown progress format, got %v", err)
+	}
+}
+
+// TestDeploy_Deployer ensures that the deployer chosen is saved to func.yaml
+// and that with which the client is created.
+func TestDeploy_Deployer(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var deployer string
+	newClient := func(cfg ClientConfig, _ ...fn.Option) (*fn.Client, func()) {
+		deployer = cfg.Deployer
+		return fn.New(fn.WithDeployer(mock.NewDeployer())), func() {}
+	}
+	cmd := NewDeployCmd(newClient)
+	cmd.SetArgs([]string{"--deployer", "k8s"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if deployer != fn.DeployerK8s {
+		t.Errorf("expected the client of the k8s deployer, got %q", deployer)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Deployer != fn.DeployerK8s {
+		t.Fatalf("expected deploy.deployer saved, got %q", f.Deploy.Deployer)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--deployer", "nomad"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--deployer") {
+		t.Fatalf("expected an error of an unknown deployer, got %v", err)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--strategy", "blue-green"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "revisions") {
+		t.Fatalf("expected the blue-green strategy unsupported by the k8s deployer, got %v", err)
+	}
+}
+
+// TestDeploy_CustomDomains ensures that the custom domains given are added to
+// those of func.yaml, and those suffixed "-" removed.
+func TestDeploy_CustomDomains(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Domains = []string{"old.example.com"}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--custom-domain", "api.example.com", "--custom-domain", "old.example.com-"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f.Deploy.Domains, []string{"api.example.com"}) {
+		t.Fatalf("expected deploy.domains [api.example.com], got %v", f.Deploy.Domains)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--custom-domain", "https://api.example.com"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "deploy.domains") {
+		t.Fatalf("expected an error of an invalid domain, got %v", err)
+	}
+}
+
+// TestDeploy_Route ensures that the route visibility and annotations given
+// are saved as the function's route, annotations suffixed "-" removed.
+func TestDeploy_Route(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Route = &fn.RouteSpec{Annotations: map[string]string{"haproxy.router.openshift.io/balance": "roundrobin"}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--route-visibility", "public",
+		"--route-annotation", "haproxy.router.openshift.io/timeout=600s",
+		"--route-annotation", "haproxy.router.openshift.io/balance-"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	want := &fn.RouteSpec{Visibility: fn.RouteVisibilityPublic, Annotations: map[string]string{"haproxy.router.openshift.io/timeout": "600s"}}
+	if !reflect.DeepEqual(f.Deploy.Route, want) {
+		t.Fatalf("expected deploy.route %+v, got %+v", want, f.Deploy.Route)
+	}
+
+	for _, args := range [][]string{
+		{"--route-visibility", "private"},
+		{"--route-annotation", "timeout"},
+		{"--route-visibility", "none", "--route-annotation", "a=b"},
+	} {
+		cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+		cmd.SetArgs(args)
+		if err = cmd.Execute(); err == nil {
+			t.Errorf("expected an error of %v", args)
+		}
+	}
+}
+
+// TestDeploy_OpenShiftRegistry ensures that on OpenShift a function without a
+// registry is pushed to the internal registry of its namespace.
+func TestDeploy_OpenShiftRegistry(t *testing.T) {
+	root := FromTempDirectory(t)
+	defer func(f func() bool) { isOpenShift = f }(isOpenShift)
+	isOpenShift = func() bool { return true }
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewDeployCmd(NewTestClient(
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithPusher(mock.NewPusher()),
+		fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--namespace", "myns"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "image-registry.openshift-image-registry.svc:5000/myns"; f.Registry != want {
+		t.Fatalf("expected registry %q, got %q", want, f.Registry)
+	}
+}
+
+// TestDeploy_PinDigest ensures that with --pin-digest the function is deployed
+// by the digest of its image in the registry.
+func TestDeploy_PinDigest(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) { return digest, nil }
+	resolver := mock.NewDigestResolver()
+	resolver.DigestFn = func(context.Context, string) (string, error) { return digest, nil }
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if !strings.HasSuffix(f.Deploy.Image, "@"+digest) {
+			t.Errorf("expected the image deployed by its digest, got %v", f.Deploy.Image)
+		}
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: "default"}, nil
+	}
+	cmd := NewDeployCmd(NewTestClient(
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithPusher(pusher),
+		fn.WithDigestResolver(resolver),
+		fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--pin-digest"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !resolver.DigestInvoked {
+		t.Fatal("expected the digest resolved")
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--pin-digest", "--remote"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--pin-digest") {
+		t.Fatalf("expected pinning unsupported with --remote, got %v", err)
+	}
+}
+
+// TestDeploy_RevisionHistory ensures that the revision history given is saved
+// to the options of func.yaml, and that a negative history is an error.
+func TestDeploy_RevisionHistory(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--revision-history", "3"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := f.Deploy.Options.RevisionHistoryLimit; limit == nil || *limit != 3 {
+		t.Fatalf("expected options.revisionHistoryLimit 3, got %v", limit)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--revision-history", "-1"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "revisionHistoryLimit") {
+		t.Fatalf("expected an error of a negative history, got %v", err)
+	}
+}
+
+// TestDeploy_Autoscaling ensures that the autoscaling flags are saved to the
+// options of func.yaml, retaining those not given.
+func TestDeploy_Autoscaling(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	max, metric := int64(10), "rps"
+	f.Deploy.Options.Scale = &fn.ScaleOptions{Max: &max, Metric: &metric}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--min-scale", "1", "--concurrency-target", "50",
+		"--concurrency-limit", "100", "--scale-utilization", "70"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	scale := f.Deploy.Options.Scale
+	if *scale.Min != 1 || *scale.Max != 10 || *scale.Metric != "rps" || *scale.Target != 50 || *scale.Utilization != 70 {
+		t.Errorf("unexpected scale options %+v", scale)
+	}
+	if limits := f.Deploy.Options.Resources.Limits; *limits.Concurrency != 100 {
+		t.Errorf("expected a concurrency limit of 100, got %v", *limits.Concurrency)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--min-scale", "20"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error of a min exceeding the max")
+	}
+
+	// The hpa class scales by cpu or memory, never to zero
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--scale-class", "hpa", "--scale-metric", "memory"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error of the utilization retained, of the kpa class only")
+	}
+	f.Deploy.Options.Scale.Utilization = nil
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(mock.NewDeployer())))
+	cmd.SetArgs([]string{"--scale-class", "hpa", "--scale-metric", "memory", "--concurrency-target", "256"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if scale := f.Deploy.Options.Scale; *scale.Class != "hpa" || *scale.Metric != "memory" || *scale.Target != 256 {
+		t.Errorf("unexpected scale options %+v", scale)
+	}
+}
+
+// TestDeploy_Contexts ensures that the function is deployed to the cluster
+// of each kubeconfig context given, continuing past a failure, the result of
+// each reported.
+func TestDeploy_Contexts(t *testing.T) {
+	root := FromTempDirectory(t) // sets test KUBECONFIG, of two contexts
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		first  = "default/cluster-example-com:6443/kube:admin"
+		second = "func/cluster-example-com:6443/kube:admin"
+	)
+	var (
+		mu       sync.Mutex
+		deployed []string
+	)
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deployed = append(deployed, k8s.ContextFrom(ctx))
+		if k8s.ContextFrom(ctx) == second {
+			return fn.DeploymentResult{}, errors.New("unreachable")
+		}
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	builder := mock.NewBuilder()
+	cmd := NewDeployCmd(NewTestClient(fn.WithBuilder(builder), fn.WithDeployer(deployer)))
+	stdout := &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"--context", first + "," + second, "--concurrent"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "1 of 2 contexts") {
+		t.Fatalf("expected an error of one context failing, got %v", err)
+	}
+	sort.Strings(deployed)
+	if !reflect.DeepEqual(deployed, []string{first, second}) {
+		t.Errorf("expected a deployment to each context, got %v", deployed)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "deployed") || !strings.Contains(out, "failed: deploy error. unreachable") {
+		t.Errorf("expected the result of each context reported, got:\n%v", out)
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Namespace == "" {
+		t.Errorf("expected the namespace of the deployment which succeeded written")
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--context", "nonexistent"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error of an unknown context")
+	}
+}
+
+// TestDeploy_DryRun ensures that a dry run prints the resources rendered by
+// the deployer, of the image which would be built, without building or
+// deploying, and that only client and server dry runs are accepted.
+func TestDeploy_DryRun(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	_, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mode fn.DryRun
+	builder := mock.NewBuilder()
+	deployer := mock.NewDeployer()
+	deployer.RenderFn = func(_ context.Context, f fn.Function, m fn.DryRun) ([]byte, error) {
+		mode = m
+		return []byte("image: " + f.Deploy.Image + "\n"), nil
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithBuilder(builder), fn.WithDeployer(deployer)))
+	stdout := &bytes.Buffer{}
+	cmd.SetOut(stdout)
+	cmd.SetArgs([]string{"--dry-run"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if builder.BuildInvoked || deployer.DeployInvoked {
+		t.Fatal("expected a dry run to neither build nor deploy")
+	}
+	if mode != fn.DryRunClient {
+		t.Errorf("expected a client dry run by default, got %q", mode)
+	}
+	if want := "image: " + TestRegistry + "/" + filepath.Base(root) + ":latest\n"; stdout.String() != want {
+		t.Errorf("expected %q, got %q", want, stdout.String())
+	}
+	f, err := fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Image != "" || f.Deploy.Namespace != "" {
+		t.Errorf("expected the function not written of a dry run, got %+v", f.Deploy)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--dry-run=server"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if mode != fn.DryRunServer {
+		t.Errorf("expected a server dry run, got %q", mode)
+	}
+
+	cmd = NewDeployCmd(NewTestClient(fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--dry-run=none"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error of an unrecognized dry run")
+	}
+}
+
+// TestDeploy_OutputManifests ensures that the function is built, but rather
+// than deployed its manifests are written a file per resource, with a
+// kustomization of them noting the secrets it references.
+func TestDeploy_OutputManifests(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	name, secret := "PASSWORD", "{{ secret:db:password }}"
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Run.Envs = []fn.Env{{Name: &name, Value: &secret}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	builder := mock.NewBuilder()
+	deployer := mock.NewDeployer()
+	deployer.RenderFn = func(_ context.Context, f fn.Function, m fn.DryRun) ([]byte, error) {
+		if m != fn.DryRunClient {
+			t.Errorf("expected manifests rendered by the client, got %q", m)
+		}
+		return []byte("apiVersion: serving.knative.dev/v1\nkind: Service\nmetadata:\n  name: f\n---\n" +
+			"apiVersion: eventing.knative.dev/v1\nkind: Trigger\nmetadata:\n  name: f-function-trigger-0\n"), nil
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithBuilder(builder), fn.WithDeployer(deployer)))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--output-manifests", "deploy"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !builder.BuildInvoked || deployer.DeployInvoked {
+		t.Fatal("expected the function built but not deployed")
+	}
+
+	for _, file := range []string{"service-f.yaml", "trigger-f-function-trigger-0.yaml"} {
+		bb, err := os.ReadFile(filepath.Join(root, "deploy", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(bb), "metadata:") {
+			t.Errorf("expected a manifest in %v, got:\n%s", file, bb)
+		}
+	}
+	bb, err := os.ReadFile(filepath.Join(root, "deploy", "kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"#   Secret db", "- service-f.yaml", "- trigger-f-function-trigger-0.yaml"} {
+		if !strings.Contains(string(bb), want) {
+			t.Errorf("expected the kustomization to contain %q, got:\n%s", want, bb)
+		}
+	}
+}
+
+// TestDeploy_OutputManifestsLocalEnvs ensures that the manifests of a function
+// interpolating local environment variables, whose values they hold, are
+// readable only by the user.
+func TestDeploy_OutputManifestsLocalEnvs(t *testing.T) {
+	root := FromTempDirectory(t)
+	t.Setenv("TOKEN", "token")
+
+	name, local := "TOKEN", "{{ env:TOKEN }}"
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Run.Envs = []fn.Env{{Name: &name, Value: &local}}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	deployer := mock.NewDeployer()
+	deployer.RenderFn = func(context.Context, fn.Function, fn.DryRun) ([]byte, error) {
+		return []byte("apiVersion: serving.knative.dev/v1\nkind: Service\nmetadata:\n  name: f\n"), nil
+	}
+	cmd := NewDeployCmd(NewTestClient(fn.WithBuilder(mock.NewBuilder()), fn.WithDeployer(deployer)))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--output-manifests", "deploy"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(filepath.Join(root, "deploy", "service-f.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("expected the manifest written 0600, got %v", fi.Mode().Perm())
+	}
+}
+
 // TestDeploy_GitArgsUsed ensures that any git values provided as flags are used
 // when invoking a remote deployment.
 func TestDeploy_GitArgsUsed(t *testing.T) {
@@ -788,12 +1565,12 @@ func TestDeploy_ImageWithDigestErrors(t *testing.T) {
 		{
 			name:      "invalid digest prefix 'Xsha256', expect error",
 			image:     "example.com/mynamespace/myfunction@Xsha256:7d66645b0add6de7af77ef332ecd4728649a2f03b9a2716422a054805b595c4e",
-			errPrefix: "could not parse reference",
+			errPrefix: "invalid image",
 		},
 		{
 			name:      "invalid sha hash length(added X at the end), expect error",
 			image:     "example.com/mynamespace/myfunction@sha256:7d66645b0add6de7af77ef332ecd4728649a2f03b9a2716422a054805b595c4eX",
-			errPrefix: "could not parse reference",
+			errPrefix: "invalid image",
 		},
 	}
 
@@ -991,9 +1768,23 @@ func testInvalidRegistry(cmdFn commandConstructor, t *testing.T) {
 	cmd.SetArgs([]string{"--registry=foo/bar/invald/myfunc"})
 
 	if err := cmd.Execute(); err == nil {
-		// TODO: typed ErrInvalidRegistry
 		t.Fatal("invalid registry did not generate expected error")
 	}
+
+	// A malformed registry or image is rejected before building, with the
+	// offending part identified.
+	cmd = cmdFn(NewTestClient())
+	cmd.SetArgs([]string{"--registry=example.com:port/alice"})
+	var errRegistry utils.ErrInvalidRegistry
+	if err := cmd.Execute(); !errors.As(err, &errRegistry) || !strings.Contains(err.Error(), "registry port 'port'") {
+		t.Fatalf("expected ErrInvalidRegistry identifying the port, got %v", err)
+	}
+	cmd = cmdFn(NewTestClient())
+	cmd.SetArgs([]string{"--image=example.com/alice/myfunc@sha256:1234"})
+	var errImage utils.ErrInvalidImage
+	if err := cmd.Execute(); !errors.As(err, &errImage) || !strings.Contains(err.Error(), "image digest") {
+		t.Fatalf("expected ErrInvalidImage identifying the digest, got %v", err)
+	}
 }
 
 // TestDeploy_Namespace ensures that the namespace provided to the client
@@ -2192,7 +2983,10 @@ func TestDeploy_BaseImage(t *testing.T) {
 }
 
 func testBaseImage(cmdFn commandConstructor, t *testing.T) {
-	const baseImage = "example.com/repo/baseImage"
+	const (
+		baseImage = "example.com/repo/baseImage"
+		runImage  = "example.com/repo/runImage"
+	)
 	tests := []struct {
 		name    string
 		runtime string
@@ -2210,10 +3004,14 @@ func testBaseImage(cmdFn commandConstructor, t *testing.T) {
 			builder: "host",
 		},
 		{
-			name:    "should-fail: python-runtime with pack-builder",
+			name:    "should-succeed: python-runtime with pack-builder",
 			runtime: "python",
 			builder: "pack",
-			expErr:  true,
+		},
+		{
+			name:    "should-succeed: node-runtime with s2i-builder",
+			runtime: "node",
+			builder: "s2i",
 		},
 	}
 	for _, tt := range tests {
@@ -2238,6 +3036,7 @@ func testBaseImage(cmdFn commandConstructor, t *testing.T) {
 			args := []string{
 				fmt.Sprintf("--builder=%s", tt.builder),
 				fmt.Sprintf("--base-image=%s", baseImage),
+				fmt.Sprintf("--run-image=%s", runImage),
 			}
 
 			cmd.SetArgs(args)
@@ -2255,6 +3054,15 @@ func testBaseImage(cmdFn commandConstructor, t *testing.T) {
 			if err == nil && tt.expErr {
 				t.Fatal(fmt.Errorf("Expected error but test succeeded"))
 			}
+
+			// both images are persisted
+			if f, err = fn.NewFunction(root); err != nil {
+				t.Fatal(err)
+			}
+			if f.Build.BaseImage != baseImage || f.Build.RunImage != runImage {
+				t.Fatalf("expected base image %q and run image %q, got %q and %q",
+					baseImage, runImage, f.Build.BaseImage, f.Build.RunImage)
+			}
 		})
 	}
 }
@@ -2409,3 +3217,73 @@ func TestDeploy_ValidDomain(t *testing.T) {
 		})
 	}
 }
+
+// TestDeploy_FirstTarget ensures that a summary is printed before the first
+// deployment of a function to a cluster and namespace, being one in which it
+// is not found, which without a terminal requires --yes, and that subsequent
+// deployments are not summarized.
+func TestDeploy_FirstTarget(t *testing.T) {
+	root := FromTempDirectory(t) // sets test KUBECONFIG
+
+	var (
+		mu       sync.Mutex
+		deployed = map[string]bool{}
+	)
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deployed[f.Namespace] = true
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	describer := mock.NewDescriber()
+	describer.DescribeFn = func(_ context.Context, name, namespace string) (fn.Instance, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !deployed[namespace] {
+			return fn.Instance{}, fn.ErrFunctionNotFound
+		}
+		return fn.Instance{Name: name, Namespace: namespace}, nil
+	}
+	testClientFn := NewTestClient(fn.WithDeployer(deployer), fn.WithDescriber(describer))
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry}); err != nil {
+		t.Fatal(err)
+	}
+
+	deploy := func(args ...string) string {
+		t.Helper()
+		var stderr bytes.Buffer
+		cmd := NewDeployCmd(testClientFn)
+		cmd.SetArgs(args)
+		cmd.SetErr(&stderr)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return stderr.String()
+	}
+
+	cmd := NewDeployCmd(testClientFn)
+	cmd.SetArgs([]string{})
+	cmd.SetErr(&bytes.Buffer{})
+	if err := cmd.Execute(); !errors.Is(err, ErrDeployNotConfirmed) {
+		t.Fatalf("expected the first deployment without a terminal to require --yes, got %v", err)
+	}
+	if out := deploy("--yes"); !strings.Contains(out, "First deployment") || !strings.Contains(out, "Namespace: func") {
+		t.Fatalf("expected a summary of the first deployment, got %q", out)
+	}
+	if out := deploy(); strings.Contains(out, "First deployment") {
+		t.Fatalf("expected no summary when redeploying, got %q", out)
+	}
+	if out := deploy("--namespace=other", "--yes"); !strings.Contains(out, "Namespace: other") {
+		t.Fatalf("expected a summary of the deployment to a new namespace, got %q", out)
+	}
+
+	// A fresh copy of the function's source, without its .func directory,
+	// is not a first deployment of a function already in the cluster.
+	if err := os.RemoveAll(filepath.Join(root, fn.RunDataDir)); err != nil {
+		t.Fatal(err)
+	}
+	if out := deploy(); strings.Contains(out, "First deployment") {
+		t.Fatalf("expected no summary deploying a fresh copy of a deployed function, got %q", out)
+	}
+}
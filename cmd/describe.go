@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
@@ -14,6 +18,7 @@ import (
 
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/metrics"
 )
 
 var ErrNameAndPathConflict = errors.New("cannot specify both name and path")
@@ -25,7 +30,30 @@ func NewDescribeCmd(newClient ClientFactory) *cobra.Command {
 		Long: `Describe a function
 
 Prints the name, route and event subscriptions for a deployed function in
-the current directory or from the directory specified with --path.
+the current directory or from the directory specified with --path, with the
+CPU and memory requested and limited of its container and the bounds of its
+scale, as configured.
+
+The Triggers, SinkBindings and channel Subscriptions of Knative Eventing
+whose sink is the function are listed as its event bindings, with the origin
+of their events: the Broker of a Trigger, the subject of a SinkBinding or the
+channel of a Subscription.
+
+With --artifacts, the artifacts attached to the function's image (see
+build.artifacts of func.yaml) are listed as well.  Use --artifact to write
+the content of one of them, identified by its path or digest, to stdout.
+
+The number of requests of the last hour, the fraction of them which failed
+and their 95th percentile latency are shown as well when Prometheus is found
+on the cluster, such as in the monitoring namespace as installed by
+kube-prometheus.  Use --metrics-url to query another, such as that of a port
+forward, or --metrics=false not to query metrics.
+
+The json and yaml output is a Function of apiVersion func.knative.dev/v1,
+with its fields inline.  Field names are stable within a version, which may
+be given with the format, such as --output json=v1.
+With --output jsonpath=<expression> the JSONPath expression is evaluated
+against the json output, as with kubectl, such as '{.image}'.
 `,
 		Example: `
 # Show the details of a function as declared in the local func.yaml
@@ -33,12 +61,27 @@ the current directory or from the directory specified with --path.
 
 # Show the details of the function in the directory with yaml output
 {{rootCmdUse}} describe --output yaml --path myotherfunc
+
+# Show the details of the function as JSON of a stable version for scripts
+{{rootCmdUse}} describe --output json=v1
+
+# Show the image deployed of the function with a template
+{{rootCmdUse}} describe --output go-template='{{"{{"}}.Image{{"}}"}}'
+
+# Show the URL of the function with a JSONPath expression
+{{rootCmdUse}} describe --output jsonpath='{.route}'
+
+# List the artifacts attached to the function's image
+{{rootCmdUse}} describe --artifacts
+
+# Fetch the OpenAPI specification attached to the function's image
+{{rootCmdUse}} describe --artifact openapi.yaml > openapi.yaml
 `,
 		SuggestFor: []string{"ifno", "fino", "get"},
 
 		ValidArgsFunction: CompleteFunctionList,
 		Aliases:           []string{"info", "desc"},
-		PreRunE:           bindEnv("output", "path", "namespace", "verbose"),
+		PreRunE:           bindEnv("output", "path", "namespace", "artifacts", "artifact", "metrics", "metrics-url", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runDescribe(cmd, args, newClient)
 		},
@@ -51,8 +94,12 @@ the current directory or from the directory specified with --path.
 	}
 
 	// Flags
-	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml|url) ($FUNC_OUTPUT)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json[=v1]|ndjson[=v1]|xml|yaml[=v1]|url|csv|go-template=<template>|jsonpath=<expression>) ($FUNC_OUTPUT)")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace in which to look for the named function. ($FUNC_NAMESPACE)")
+	cmd.Flags().Bool("artifacts", false, "List the artifacts attached to the function's image. ($FUNC_ARTIFACTS)")
+	cmd.Flags().String("artifact", "", "Write the content of the artifact of the given path or digest, attached to the function's image, to stdout. ($FUNC_ARTIFACT)")
+	cmd.Flags().Bool("metrics", true, "Show the metrics of the function's invocations of the last hour, if available. ($FUNC_METRICS)")
+	cmd.Flags().String("metrics-url", "", "URL of the Prometheus of which metrics are queried, rather than that found on the cluster. ($FUNC_METRICS_URL)")
 	addPathFlag(cmd)
 	addVerboseFlag(cmd, cfg.Verbose)
 
@@ -70,17 +117,29 @@ func runDescribe(cmd *cobra.Command, args []string, newClient ClientFactory) (er
 	}
 	// TODO cfg.Prompt()
 
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
+	var clientOptions []fn.Option
+	if cfg.MetricsURL != "" {
+		clientOptions = append(clientOptions, fn.WithMetricsProvider(metrics.NewProvider(
+			metrics.WithURL(cfg.MetricsURL), metrics.WithVerbose(cfg.Verbose))))
+	}
+	clientCfg := ClientConfig{Verbose: cfg.Verbose}
+	if cfg.Name == "" {
+		clientCfg.Deployer = deployerAt(cfg.Path)
+	}
+	client, done := newClient(clientCfg, clientOptions...)
 	defer done()
 
-	var details fn.Instance
+	var (
+		details fn.Instance
+		f       fn.Function
+	)
 	if cfg.Name != "" { // Describe by name if provided
 		details, err = client.Describe(cmd.Context(), cfg.Name, cfg.Namespace, fn.Function{})
 		if err != nil {
 			return err
 		}
 	} else {
-		f, err := fn.NewFunction(cfg.Path)
+		f, err = fn.NewFunction(cfg.Path)
 		if err != nil {
 			return err
 		}
@@ -93,15 +152,52 @@ func runDescribe(cmd *cobra.Command, args []string, newClient ClientFactory) (er
 		}
 	}
 
-	write(os.Stdout, info(details), cfg.Output)
+	// Artifacts are those of the image actually deployed.
+	if details.Image != "" {
+		f.Deploy.Image = details.Image
+	}
+	if cfg.Artifact != "" {
+		return client.FetchArtifact(cmd.Context(), f, cfg.Artifact, cmd.OutOrStdout())
+	}
+	if cfg.Artifacts {
+		if details.Artifacts, err = client.Artifacts(cmd.Context(), f); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Metrics && details.Name != "" {
+		details.Metrics = functionMetrics(cmd, client, details.Name, details.Namespace, describeMetricsWindow, cfg.Verbose)
+	}
+
+	write(cmd.OutOrStdout(), info(details), cfg.Output)
 	return
 }
 
+// describeMetricsWindow of the invocations of which metrics are described.
+const describeMetricsWindow = time.Hour
+
+// functionMetrics of the function's invocations over the window, or nil if
+// they are not available.  Commands proceed regardless, with a warning if
+// metrics could not be queried of a source found.
+func functionMetrics(cmd *cobra.Command, client *fn.Client, name, namespace string, window time.Duration, verbose bool) *fn.Metrics {
+	m, err := client.Metrics(cmd.Context(), name, namespace, window)
+	if errors.Is(err, fn.ErrMetricsNotAvailable) {
+		if verbose {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Metrics of %v are not available: %v\n", name, err)
+		}
+		return nil
+	} else if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: cannot query the metrics of %v: %v\n", name, err)
+		return nil
+	}
+	return &m
+}
+
 // formatError wraps ErrNotInitialized with user-friendly guidance
 func formatError(err error) error {
 	var errNotInitialized *fn.ErrNotInitialized
 	if errors.As(err, &errNotInitialized) {
-		return fmt.Errorf(`%s
+		return fmt.Errorf(`%w
 
 No function found in provided path (current directory or via --path).
 You need to be in a function directory (or use --path).
@@ -118,7 +214,7 @@ Or if you have an existing function:
 Or use --path to describe from anywhere:
   func describe --path /path/to/function
 
-For more information try 'func describe --help'`, errNotInitialized.Error())
+For more information try 'func describe --help'`, err)
 	}
 	return err
 }
@@ -127,11 +223,15 @@ For more information try 'func describe --help'`, errNotInitialized.Error())
 // ------------------------------
 
 type describeConfig struct {
-	Name      string
-	Namespace string
-	Output    string
-	Path      string
-	Verbose   bool
+	Name       string
+	Namespace  string
+	Output     string
+	Path       string
+	Artifacts  bool
+	Artifact   string
+	Metrics    bool
+	MetricsURL string
+	Verbose    bool
 }
 
 func newDescribeConfig(cmd *cobra.Command, args []string) (cfg describeConfig, err error) {
@@ -140,11 +240,15 @@ func newDescribeConfig(cmd *cobra.Command, args []string) (cfg describeConfig, e
 		name = args[0]
 	}
 	cfg = describeConfig{
-		Name:      name,
-		Namespace: viper.GetString("namespace"),
-		Output:    viper.GetString("output"),
-		Path:      viper.GetString("path"),
-		Verbose:   viper.GetBool("verbose"),
+		Name:       name,
+		Namespace:  viper.GetString("namespace"),
+		Output:     viper.GetString("output"),
+		Path:       viper.GetString("path"),
+		Artifacts:  viper.GetBool("artifacts"),
+		Artifact:   viper.GetString("artifact"),
+		Metrics:    viper.GetBool("metrics"),
+		MetricsURL: viper.GetString("metrics-url"),
+		Verbose:    viper.GetBool("verbose"),
 	}
 	if cfg.Name == "" && cmd.Flags().Changed("namespace") {
 		// logicially inconsistent to supply only a namespace.
@@ -158,6 +262,15 @@ func newDescribeConfig(cmd *cobra.Command, args []string) (cfg describeConfig, e
 		// a name and a namespace to ignore any local function source.
 		err = ErrNameAndPathConflict
 	}
+	if err == nil {
+		cfg.Output, err = versionedFormat(cfg.Output)
+	}
+	if err == nil {
+		_, err = goTemplate(cfg.Output)
+	}
+	if err == nil {
+		_, err = jsonPath(cfg.Output)
+	}
 	return
 }
 
@@ -179,6 +292,39 @@ func (i info) Human(w io.Writer) error {
 		fmt.Fprintf(w, "  %v\n", route)
 	}
 
+	// Traffic is shown only when split, as otherwise all is routed to the
+	// latest revision.
+	if len(i.Traffic) > 1 {
+		fmt.Fprintln(w, "Traffic (Percent, Revision, Tag):")
+		for _, t := range i.Traffic {
+			fmt.Fprintf(w, "  %v%% %v %v\n", t.Percent, t.Revision, t.Tag)
+		}
+	}
+
+	if p := i.Provisioning; p != (fn.Provisioning{}) {
+		fmt.Fprintln(w, "Provisioning:")
+		if cpu := formatRequestLimit(p.CPURequest, p.CPULimit); cpu != "" {
+			fmt.Fprintf(w, "  CPU (request/limit): %v\n", cpu)
+		}
+		if memory := formatRequestLimit(p.MemoryRequest, p.MemoryLimit); memory != "" {
+			fmt.Fprintf(w, "  Memory (request/limit): %v\n", memory)
+		}
+		if scale := formatScale(p); scale != "" {
+			fmt.Fprintf(w, "  Scale (min-max): %v\n", scale)
+		}
+	}
+
+	if i.Metrics != nil {
+		fmt.Fprintf(w, "Metrics (last %v):\n", formatWindow(i.Metrics.Window))
+		fmt.Fprintf(w, "  Requests: %v\n", math.Round(i.Metrics.Requests))
+		if i.Metrics.Requests > 0 {
+			fmt.Fprintf(w, "  Error rate: %.2f%%\n", i.Metrics.ErrorRate*100)
+		}
+		if i.Metrics.LatencyP95 > 0 {
+			fmt.Fprintf(w, "  Latency p95: %v\n", i.Metrics.LatencyP95.Round(time.Millisecond))
+		}
+	}
+
 	if len(i.Subscriptions) > 0 {
 		fmt.Fprintln(w, "Subscriptions (Source, Type, Broker):")
 		for _, s := range i.Subscriptions {
@@ -186,12 +332,26 @@ func (i info) Human(w io.Writer) error {
 		}
 	}
 
+	if len(i.Bindings) > 0 {
+		fmt.Fprintln(w, "Event bindings (Kind, Name, From, Filter):")
+		for _, b := range i.Bindings {
+			fmt.Fprintf(w, "  %v %v %v %v\n", b.Kind, b.Name, b.From, b.Filter)
+		}
+	}
+
 	if len(i.Labels) > 0 {
 		fmt.Fprintln(w, "Labels:")
 		for k, v := range i.Labels {
 			fmt.Fprintf(w, "  %v: %v\n", k, v)
 		}
 	}
+
+	if len(i.Artifacts) > 0 {
+		fmt.Fprintln(w, "Artifacts (Path, Type, Digest):")
+		for _, a := range i.Artifacts {
+			fmt.Fprintf(w, "  %v %v %v\n", a.Path, a.Type, a.Digest)
+		}
+	}
 	return nil
 }
 
@@ -204,22 +364,68 @@ func (i info) Plain(w io.Writer) error {
 		fmt.Fprintf(w, "Route %v\n", route)
 	}
 
+	if len(i.Traffic) > 1 {
+		for _, t := range i.Traffic {
+			fmt.Fprintf(w, "Traffic %v %v %v\n", t.Percent, t.Revision, t.Tag)
+		}
+	}
+
+	if cpu := formatRequestLimit(i.Provisioning.CPURequest, i.Provisioning.CPULimit); cpu != "" {
+		fmt.Fprintf(w, "CPU %v\n", cpu)
+	}
+	if memory := formatRequestLimit(i.Provisioning.MemoryRequest, i.Provisioning.MemoryLimit); memory != "" {
+		fmt.Fprintf(w, "Memory %v\n", memory)
+	}
+	if scale := formatScale(i.Provisioning); scale != "" {
+		fmt.Fprintf(w, "Scale %v\n", scale)
+	}
+
+	if i.Metrics != nil {
+		fmt.Fprintf(w, "Requests %v\n", math.Round(i.Metrics.Requests))
+		fmt.Fprintf(w, "ErrorRate %v\n", i.Metrics.ErrorRate)
+		fmt.Fprintf(w, "LatencyP95 %v\n", i.Metrics.LatencyP95)
+	}
+
 	if len(i.Subscriptions) > 0 {
 		for _, s := range i.Subscriptions {
 			fmt.Fprintf(w, "Subscription %v %v %v\n", s.Source, s.Type, s.Broker)
 		}
 	}
 
+	for _, b := range i.Bindings {
+		fmt.Fprintf(w, "Binding %v %v %v %v\n", b.Kind, b.Name, b.From, b.Filter)
+	}
+
 	if len(i.Labels) > 0 {
 		for k, v := range i.Labels {
 			fmt.Fprintf(w, "Label %v %v\n", k, v)
 		}
 	}
+
+	for _, a := range i.Artifacts {
+		fmt.Fprintf(w, "Artifact %v %v %v\n", a.Path, a.Type, a.Digest)
+	}
 	return nil
 }
 
+// functionEnvelope of versioned output, of the fields of the function inline.
+type functionEnvelope struct {
+	APIVersion  string `json:"apiVersion" yaml:"apiVersion"`
+	Kind        string `json:"kind" yaml:"kind"`
+	fn.Instance `yaml:",inline"`
+}
+
+func (i info) envelope() functionEnvelope {
+	return functionEnvelope{APIVersion: OutputAPIVersion, Kind: "Function", Instance: fn.Instance(i)}
+}
+
 func (i info) JSON(w io.Writer) error {
-	return json.NewEncoder(w).Encode(i)
+	return json.NewEncoder(w).Encode(i.envelope())
+}
+
+// NDJSON of the function is its json output, which is a single line.
+func (i info) NDJSON(w io.Writer) error {
+	return i.JSON(w)
 }
 
 func (i info) XML(w io.Writer) error {
@@ -227,7 +433,41 @@ func (i info) XML(w io.Writer) error {
 }
 
 func (i info) YAML(w io.Writer) error {
-	return yaml.NewEncoder(w).Encode(i)
+	return yaml.NewEncoder(w).Encode(i.envelope())
+}
+
+// formatWindow of time, such as "1h" rather than "1h0m0s".
+func formatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// CSV of the function as a single record.  Fields of several values are
+// space separated, such as the routes, or the labels as key=value.
+func (i info) CSV(w io.Writer) error {
+	var subscriptions, labels []string
+	for _, s := range i.Subscriptions {
+		subscriptions = append(subscriptions, fmt.Sprintf("%v/%v/%v", s.Source, s.Type, s.Broker))
+	}
+	for k, v := range i.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"name", "namespace", "image", "routes", "subscriptions", "labels", "traffic", "cpu", "memory", "scale"})
+	_ = cw.Write([]string{i.Name, i.Namespace, i.Image, strings.Join(i.Routes, " "),
+		strings.Join(subscriptions, " "), strings.Join(labels, " "), formatTraffic(i.Traffic),
+		formatRequestLimit(i.Provisioning.CPURequest, i.Provisioning.CPULimit),
+		formatRequestLimit(i.Provisioning.MemoryRequest, i.Provisioning.MemoryLimit), formatScale(i.Provisioning)})
+	cw.Flush()
+	return cw.Error()
 }
 
 func (i info) URL(w io.Writer) error {
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
@@ -135,3 +140,217 @@ func TestDescribe_NameAndPathExclusivity(t *testing.T) {
 		t.Fatal("describer was invoked when conflicting flags were provided")
 	}
 }
+
+// TestDescribe_Artifact ensures that an artifact attached to the image of the
+// function as deployed is fetched by its path.
+func TestDescribe_Artifact(t *testing.T) {
+	const image = "example.com/alice/testfunc@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+	var (
+		describer = mock.NewDescriber()
+		attacher  = mock.NewAttacher()
+	)
+	describer.DescribeFn = func(context.Context, string, string) (fn.Instance, error) {
+		return fn.Instance{Name: "testfunc", Image: image}, nil
+	}
+	attacher.ArtifactsFn = func(_ context.Context, img string) ([]fn.Artifact, error) {
+		if img != image {
+			t.Errorf("expected the artifacts of %v, got %v", image, img)
+		}
+		return []fn.Artifact{{Path: "openapi.yaml", Digest: "sha256:1111"}}, nil
+	}
+	attacher.FetchFn = func(_ context.Context, _ string, a fn.Artifact, w io.Writer) error {
+		_, err := fmt.Fprintf(w, "openapi: 3.0.0 # %v", a.Digest)
+		return err
+	}
+
+	var out bytes.Buffer
+	cmd := NewDescribeCmd(NewTestClient(fn.WithDescriber(describer), fn.WithAttacher(attacher)))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"testfunc", "--artifact", "openapi.yaml"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "openapi: 3.0.0 # sha256:1111" {
+		t.Fatalf("unexpected artifact content %q", out.String())
+	}
+}
+
+// TestDescribe_CSV ensures that the CSV output is a header row and a single
+// record, with fields of several values space separated.
+func TestDescribe_CSV(t *testing.T) {
+	i := info{
+		Name:      "myfunc",
+		Namespace: "ns",
+		Image:     "example.com/myfunc@sha256:abc",
+		Routes:    []string{"http://a", "http://b"},
+		Labels:    map[string]string{"team": "payments", "env": "dev"},
+	}
+	out := bytes.Buffer{}
+	if err := i.CSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "name,namespace,image,routes,subscriptions,labels,traffic,cpu,memory,scale\n" +
+		"myfunc,ns,example.com/myfunc@sha256:abc,http://a http://b,,env=dev team=payments,,,,\n"
+	if out.String() != want {
+		t.Errorf("expected:\n%v\ngot:\n%v", want, out.String())
+	}
+}
+
+// TestDescribe_GoTemplate ensures that the output of a go-template is
+// executed with the function's details.
+func TestDescribe_GoTemplate(t *testing.T) {
+	out := bytes.Buffer{}
+	write(&out, info{Name: "myfunc", Image: "example.com/myfunc@sha256:abc"}, "go-template={{.Name}} {{.Image}}")
+	if want := "myfunc example.com/myfunc@sha256:abc"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
+
+// TestDescribe_JSONPath ensures that a JSONPath expression is evaluated
+// against the json output of the function, of its envelope's fields.
+func TestDescribe_JSONPath(t *testing.T) {
+	out := bytes.Buffer{}
+	write(&out, info{Name: "myfunc", Image: "example.com/myfunc@sha256:abc"}, "jsonpath={.kind} {.name} {.image}")
+	if want := "Function myfunc example.com/myfunc@sha256:abc"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+}
+
+// TestDescribe_Traffic ensures that traffic split between revisions is shown,
+// and that traffic routed entirely to one revision is not.
+func TestDescribe_Traffic(t *testing.T) {
+	i := info{Name: "myfunc", Traffic: []fn.TrafficTarget{
+		{Revision: "myfunc-00002", Percent: 90, Latest: true},
+		{Revision: "myfunc-00001", Percent: 10, Tag: "canary"},
+	}}
+	out := bytes.Buffer{}
+	if err := i.Human(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "90% myfunc-00002") || !strings.Contains(out.String(), "10% myfunc-00001 canary") {
+		t.Errorf("expected the traffic split, got:\n%v", out.String())
+	}
+
+	i.Traffic = i.Traffic[:1]
+	out.Reset()
+	if err := i.Human(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Traffic") {
+		t.Errorf("expected no traffic when not split, got:\n%v", out.String())
+	}
+}
+
+// TestDescribe_Bindings ensures that the event bindings of a function are
+// described with the origin of their events.
+func TestDescribe_Bindings(t *testing.T) {
+	i := info{Name: "myfunc", Bindings: []fn.EventBinding{
+		{Kind: "Trigger", Name: "myfunc-trigger", From: "Broker/default", Filter: "type=example"},
+		{Kind: "SinkBinding", Name: "heartbeat", From: "Deployment/heartbeat"},
+	}}
+	out := bytes.Buffer{}
+	if err := i.Human(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Trigger myfunc-trigger Broker/default type=example", "SinkBinding heartbeat Deployment/heartbeat"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q, got:\n%v", s, out.String())
+		}
+	}
+}
+
+// TestDescribe_Provisioning ensures that the resources and scale bounds of a
+// function are described, and are not when none are configured.
+func TestDescribe_Provisioning(t *testing.T) {
+	min := int64(1)
+	i := info{Name: "myfunc", Provisioning: fn.Provisioning{CPURequest: "100m", CPULimit: "500m", MemoryLimit: "256Mi", MinScale: &min}}
+	out := bytes.Buffer{}
+	if err := i.Human(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"CPU (request/limit): 100m/500m", "Memory (request/limit): -/256Mi", "Scale (min-max): 1-*"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q, got:\n%v", s, out.String())
+		}
+	}
+
+	i.Provisioning = fn.Provisioning{}
+	out.Reset()
+	if err := i.Human(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Provisioning") {
+		t.Errorf("expected no provisioning when none is configured, got:\n%v", out.String())
+	}
+}
+
+// TestDescribe_Versioned ensures that the json output is of the function's
+// fields inline with its version and kind.
+func TestDescribe_Versioned(t *testing.T) {
+	out := bytes.Buffer{}
+	if err := (info{Name: "myfunc", Route: "http://myfunc"}).JSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["apiVersion"] != "func.knative.dev/v1" || got["kind"] != "Function" || got["name"] != "myfunc" || got["route"] != "http://myfunc" {
+		t.Errorf("unexpected output %v", out.String())
+	}
+}
+
+// TestDescribe_Metrics ensures that the metrics of a function's invocations
+// are described when available, and that it is described regardless when not.
+func TestDescribe_Metrics(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	describer := mock.NewDescriber()
+	describer.DescribeFn = func(_ context.Context, name, namespace string) (fn.Instance, error) {
+		return fn.Instance{Name: name, Namespace: namespace}, nil
+	}
+	provider := mock.NewMetricsProvider()
+	provider.MetricsFn = func(_ context.Context, name, namespace string, window time.Duration) (fn.Metrics, error) {
+		if name != "myfunc" || namespace != "ns" {
+			t.Errorf("unexpected function %v in %v", name, namespace)
+		}
+		return fn.Metrics{Window: window, Requests: 200, ErrorRate: 0.025, LatencyP95: 120 * time.Millisecond}, nil
+	}
+
+	cmd := NewDescribeCmd(NewTestClient(fn.WithDescriber(describer), fn.WithMetricsProvider(provider)))
+	cmd.SetArgs([]string{"myfunc", "--namespace", "ns"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"Metrics (last 1h):", "Requests: 200", "Error rate: 2.50%", "Latency p95: 120ms"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q, got:\n%v", s, out.String())
+		}
+	}
+
+	// Without metrics available the function is described without them.
+	cmd = NewDescribeCmd(NewTestClient(fn.WithDescriber(describer), fn.WithMetricsProvider(mock.NewMetricsProvider())))
+	cmd.SetArgs([]string{"myfunc", "--namespace", "ns"})
+	out.Reset()
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "myfunc") || strings.Contains(out.String(), "Metrics") {
+		t.Errorf("expected the function without metrics, got:\n%v", out.String())
+	}
+
+	// Nor are they queried with --metrics=false.
+	provider.MetricsInvoked = false
+	cmd = NewDescribeCmd(NewTestClient(fn.WithDescriber(describer), fn.WithMetricsProvider(provider)))
+	cmd.SetArgs([]string{"myfunc", "--namespace", "ns", "--metrics=false"})
+	cmd.SetOut(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if provider.MetricsInvoked {
+		t.Error("expected metrics not to be queried")
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/devloop"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewDevloopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "devloop",
+		Short: "Integrate a function with development loop tools",
+		Long: `
+NAME
+	{{rootCmdUse}} devloop - Integrate a function with development loop tools
+
+SYNOPSIS
+	{{rootCmdUse}} devloop init --tool [-f|--force] [-p|--path]
+
+DESCRIPTION
+	Generates the configuration of a development loop tool, such as Skaffold
+	or Tilt, which builds and deploys the function with {{rootCmdUse}} each
+	time its source changes.  Teams already using such a tool can thereby
+	iterate on functions as on their other services.
+
+	The function is built with the host builder where it supports the
+	function's runtime, being fastest, and otherwise with its configured
+	builder.  Skaffold names and tags the image of each build, and so
+	requires the function to have a registry or image.
+`,
+		Example: `
+# Generate a skaffold.yaml for the function in the current directory
+{{rootCmdUse}} devloop init --tool skaffold
+
+# Generate a Tiltfile, replacing that which exists
+{{rootCmdUse}} devloop init --tool tilt --force
+`,
+	}
+	cmd.AddCommand(NewDevloopInitCmd())
+	return cmd
+}
+
+func NewDevloopInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Generate the configuration of a development loop tool",
+		Long: `Generate the configuration of a development loop tool
+
+Writes skaffold.yaml or a Tiltfile into the function's directory.  See
+'{{rootCmdUse}} devloop --help' for details.
+`,
+		PreRunE: bindEnv("tool", "force", "path"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDevloopInit(cmd)
+		},
+	}
+	cmd.Flags().String("tool", "", fmt.Sprintf("Development loop tool (%v). ($FUNC_TOOL)", strings.Join(devloop.Tools, "|")))
+	cmd.Flags().BoolP("force", "f", false, "Replace the tool's configuration if it exists. ($FUNC_FORCE)")
+	addPathFlag(cmd)
+
+	if err := cmd.RegisterFlagCompletionFunc("tool", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return devloop.Tools, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+	return cmd
+}
+
+func runDevloopInit(cmd *cobra.Command) error {
+	tool := viper.GetString("tool")
+	if tool == "" {
+		return fmt.Errorf("provide the tool with --tool (%v)", strings.Join(devloop.Tools, "|"))
+	}
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return err
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	path, err := devloop.Init(f, tool, viper.GetBool("force"))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %v\n", path)
+	return nil
+}
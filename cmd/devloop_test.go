@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"knative.dev/func/pkg/devloop"
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestDevloopInit ensures that the tool's configuration is written into the
+// function, and not replaced unless forced.
+func TestDevloopInit(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: "example.com/alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewDevloopCmd()
+	cmd.SetArgs([]string{"init", "--tool", "skaffold"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "skaffold.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = NewDevloopCmd()
+	cmd.SetArgs([]string{"init", "--tool", "skaffold"})
+	if err := cmd.Execute(); !errors.Is(err, devloop.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+}
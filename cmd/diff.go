@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/progress"
+)
+
+func NewDiffCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show how the deployed function differs from what deploying would produce",
+		Long: `
+NAME
+	{{rootCmdUse}} diff - Show how the deployed function differs from what deploying would produce
+
+SYNOPSIS
+	{{rootCmdUse}} diff [-o|--output] [-p|--path]
+
+DESCRIPTION
+	Renders the service which deploying the function in the current directory
+	would produce, and compares it to the service deployed on the cluster, or
+	to the Deployment of a function deployed with --deployer k8s.  Shown are
+	the changes deploying would make of the image and its digest,
+	environment variables, resources, scale, service account, labels,
+	annotations and triggers, such as to revert edits made to the service on
+	the cluster with kubectl, or to review changes to func.yaml.
+
+	Each change is shown as the field changed, from its deployed value to
+	that of deploying: '+' marks a field deploying would set, '-' one it
+	would unset, and '~' one it would change.  On a terminal, changes are
+	colored as such, unless the NO_COLOR environment variable is set.
+
+	The function is neither built nor deployed.  The image compared is that
+	last built; if the function has changed since, deploying it would also
+	build a new image.  Values of environment variables read from secrets and
+	config maps are shown as their references.
+`,
+		Example: `
+# Show how the function in the current directory has drifted on the cluster
+{{rootCmdUse}} diff
+
+# Show the changes deploying would make as JSON
+{{rootCmdUse}} diff --output json
+`,
+		SuggestFor: []string{"drift"},
+		PreRunE:    bindEnv("output", "path"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDiff(cmd, newClient)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, newClient ClientFactory) error {
+	output := viper.GetString("output")
+	if output != "human" && output != "json" {
+		return fmt.Errorf("unsupported output format %q. Accepts 'human' or 'json'", output)
+	}
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return err
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	if f.Deploy.Namespace == "" {
+		return ErrNotDeployed
+	}
+
+	client, done := newClient(ClientConfig{Deployer: f.Deploy.Deployer})
+	defer done()
+	changes, err := client.Diff(cmd.Context(), f)
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	}
+	writeDiff(cmd.OutOrStdout(), f, changes, colorOutput(cmd.OutOrStdout()))
+	if !f.Built() {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Note: the function has changed since it was last built; deploying it would also build a new image.")
+	}
+	return nil
+}
+
+// ANSI escapes of the colors of changes.
+const (
+	colorAdded   = "\033[32m"
+	colorRemoved = "\033[31m"
+	colorChanged = "\033[33m"
+	colorReset   = "\033[0m"
+)
+
+// colorOutput to w if it is a terminal and color is not disabled by
+// NO_COLOR.
+func colorOutput(w io.Writer) bool {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return !noColor && progress.IsTerminal(w)
+}
+
+// writeDiff as a list of the fields changed, from deployed to local, each
+// marked as added, removed or changed, and colored as such if color.
+func writeDiff(w io.Writer, f fn.Function, changes []fn.Change, color bool) {
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "%v in namespace %q is as deploying would produce\n", f.Name, f.Deploy.Namespace)
+		return
+	}
+	fmt.Fprintf(w, "Changes deploying %v to namespace %q would make\n\n", f.Name, f.Deploy.Namespace)
+	unset := func(s string) string {
+		if s == "" {
+			return "(unset)"
+		}
+		return s
+	}
+	for _, c := range changes {
+		mark, code := "~", colorChanged
+		switch {
+		case c.Added():
+			mark, code = "+", colorAdded
+		case c.Removed():
+			mark, code = "-", colorRemoved
+		}
+		line := fmt.Sprintf("%v %v: %v -> %v", mark, c.Field, unset(c.From), unset(c.To))
+		if color {
+			line = code + line + colorReset
+		}
+		fmt.Fprintf(w, "  %v\n", line)
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ory/viper"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestDiff_NotDeployed ensures that diffing requires the function to have
+// been deployed.
+func TestDiff_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewDiffCmd(NewTestClient())
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+}
+
+// TestDiff_Write ensures that changes are written from the deployed to the
+// local value, with unset values shown as such.
+func TestDiff_Write(t *testing.T) {
+	f := fn.Function{Name: "myfn", Deploy: fn.DeploySpec{Namespace: "prod"}}
+
+	var b bytes.Buffer
+	writeDiff(&b, f, nil, false)
+	if !strings.Contains(b.String(), `myfn in namespace "prod" is as deploying would produce`) {
+		t.Fatalf("unexpected output without changes:\n%v", b.String())
+	}
+
+	b.Reset()
+	changes := []fn.Change{{Field: "labels.team", From: "a"}, {Field: "envs.A", From: "2", To: "1"}, {Field: "envs.B", To: "3"}}
+	writeDiff(&b, f, changes, false)
+	for _, want := range []string{"- labels.team: a -> (unset)", "~ envs.A: 2 -> 1", "+ envs.B: (unset) -> 3"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%v", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "\033[") {
+		t.Errorf("expected no color, got:\n%q", b.String())
+	}
+
+	b.Reset()
+	writeDiff(&b, f, changes, true)
+	for _, want := range []string{colorRemoved + "- labels.team", colorChanged + "~ envs.A", colorAdded + "+ envs.B"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%q", want, b.String())
+		}
+	}
+}
+
+// TestDiff_Differ ensures that the changes are those of the client's differ,
+// from the snapshot of the deployed function to that of deploying it.
+func TestDiff_Differ(t *testing.T) {
+	root := FromTempDirectory(t)
+	t.Cleanup(viper.Reset)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "prod"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	differ := mock.NewDiffer()
+	differ.SnapshotsFn = func(context.Context, fn.Function) (fn.Snapshot, fn.Snapshot, error) {
+		return fn.Snapshot{Image: "example.com/f:v1"}, fn.Snapshot{Image: "example.com/f:v2"}, nil
+	}
+	var b bytes.Buffer
+	cmd := NewDiffCmd(NewTestClient(fn.WithDiffer(differ)))
+	cmd.SetArgs([]string{"--output", "json"})
+	cmd.SetOut(&b)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !differ.SnapshotsInvoked {
+		t.Fatal("expected the differ invoked")
+	}
+	var changes []fn.Change
+	if err = json.Unmarshal(b.Bytes(), &changes); err != nil {
+		t.Fatal(err)
+	}
+	want := []fn.Change{{Field: "image", From: "example.com/f:v1", To: "example.com/f:v2"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected changes %v, got %v", want, changes)
+	}
+}
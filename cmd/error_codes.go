@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/ory/viper"
+
+	"knative.dev/func/pkg/creds"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/policy"
+)
+
+// ErrorCode of the cause of a command's failure, by which automation may
+// branch on the cause rather than on the message.  Each has a distinct exit
+// code, and is included in the error printed as JSON when the command's
+// output is JSON.
+type ErrorCode string
+
+const (
+	ErrCodeGeneral           ErrorCode = "FUNC_E_GENERAL"
+	ErrCodeNotInitialized    ErrorCode = "FUNC_E_NOT_INITIALIZED"
+	ErrCodeNotDeployed       ErrorCode = "FUNC_E_NOT_DEPLOYED"
+	ErrCodeNotFound          ErrorCode = "FUNC_E_NOT_FOUND"
+	ErrCodeNoCluster         ErrorCode = "FUNC_E_NO_CLUSTER"
+	ErrCodeRegistryRequired  ErrorCode = "FUNC_E_REGISTRY_REQUIRED"
+	ErrCodeRegistryAuth      ErrorCode = "FUNC_E_REGISTRY_AUTH"
+	ErrCodeNoContainerEngine ErrorCode = "FUNC_E_NO_CONTAINER_ENGINE"
+	ErrCodePolicyViolation   ErrorCode = "FUNC_E_POLICY_VIOLATION"
+	ErrCodeInputRequired     ErrorCode = "FUNC_E_INPUT_REQUIRED"
+	ErrCodeCanceled          ErrorCode = "FUNC_E_CANCELED"
+)
+
+// errorExitCodes of the error codes.  Codes are not reassigned, such that
+// automation may rely on them.
+var errorExitCodes = map[ErrorCode]int{
+	ErrCodeGeneral:           1,
+	ErrCodeNotInitialized:    3,
+	ErrCodeNotDeployed:       4,
+	ErrCodeNotFound:          5,
+	ErrCodeNoCluster:         6,
+	ErrCodeRegistryRequired:  7,
+	ErrCodeRegistryAuth:      8,
+	ErrCodeNoContainerEngine: 9,
+	ErrCodePolicyViolation:   10,
+	ErrCodeInputRequired:     11,
+	ErrCodeCanceled:          130,
+}
+
+// codedError is an error with the code of its cause, for errors whose cause
+// is not otherwise known by its type or by an error which it wraps.
+type codedError struct {
+	code ErrorCode
+	err  error
+}
+
+func (e codedError) Error() string { return e.err.Error() }
+func (e codedError) Unwrap() error { return e.err }
+
+// withErrorCode attaches the code to the error, if any.
+func withErrorCode(err error, code ErrorCode) error {
+	if err == nil {
+		return nil
+	}
+	return codedError{code: code, err: err}
+}
+
+// ErrorCodeOf the error: that attached to it or to an error which it wraps,
+// or else that of its cause if known, or else ErrCodeGeneral.
+func ErrorCodeOf(err error) ErrorCode {
+	var coded codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	// Not initialized errors are both values and pointers.
+	var notInitialized fn.ErrNotInitialized
+	var notInitializedPtr *fn.ErrNotInitialized
+	var violations policy.ErrViolations
+	var transportErr *transport.Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, fn.ErrContextCanceled):
+		return ErrCodeCanceled
+	case errors.As(err, &notInitialized), errors.As(err, &notInitializedPtr):
+		return ErrCodeNotInitialized
+	case errors.Is(err, ErrNotDeployed):
+		return ErrCodeNotDeployed
+	case errors.Is(err, fn.ErrFunctionNotFound):
+		return ErrCodeNotFound
+	case errors.Is(err, fn.ErrClusterNotAccessible), errors.Is(err, fn.ErrInvalidKubeconfig):
+		return ErrCodeNoCluster
+	case errors.Is(err, fn.ErrRegistryRequired):
+		return ErrCodeRegistryRequired
+	case errors.Is(err, creds.ErrUnauthorized), errors.Is(err, creds.ErrCredentialsNotFound):
+		return ErrCodeRegistryAuth
+	case errors.As(err, &transportErr) &&
+		(transportErr.StatusCode == http.StatusUnauthorized || transportErr.StatusCode == http.StatusForbidden):
+		return ErrCodeRegistryAuth
+	case errors.Is(err, docker.ErrNoDocker):
+		return ErrCodeNoContainerEngine
+	case errors.As(err, &violations):
+		return ErrCodePolicyViolation
+	case errors.Is(err, ErrPromptCI):
+		return ErrCodeInputRequired
+	}
+	return ErrCodeGeneral
+}
+
+// ExitCode of the process for the error, by its code.
+func ExitCode(err error) int {
+	return errorExitCodes[ErrorCodeOf(err)]
+}
+
+// jsonError is the JSON of an error, alongside the output of a command.
+type jsonError struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Code       ErrorCode `json:"code"`
+	ExitCode   int       `json:"exitCode"`
+	Message    string    `json:"message"`
+}
+
+// PrintError of a command: as JSON of its code and message if the command's
+// output is json or ndjson, else as its message.
+func PrintError(w io.Writer, err error) {
+	if output := viper.GetString("output"); strings.HasPrefix(output, JSON) || strings.HasPrefix(output, NDJSON) {
+		code := ErrorCodeOf(err)
+		_ = json.NewEncoder(w).Encode(jsonError{
+			APIVersion: OutputAPIVersion,
+			Kind:       "Error",
+			Code:       code,
+			ExitCode:   errorExitCodes[code],
+			Message:    err.Error(),
+		})
+		return
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
+}
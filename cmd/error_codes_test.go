@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/ory/viper"
+
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/policy"
+)
+
+// TestErrorCodeOf ensures that errors are coded by the code attached or by
+// their cause, also when wrapped, and that each code has a distinct exit code.
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{errors.New("unknown"), ErrCodeGeneral},
+		{withErrorCode(errListConnect, ErrCodeNoCluster), ErrCodeNoCluster},
+		{fmt.Errorf("deploying: %w", fn.ErrClusterNotAccessible), ErrCodeNoCluster},
+		{formatError(fn.NewErrNotInitialized("/path")), ErrCodeNotInitialized},
+		{ErrNotDeployed, ErrCodeNotDeployed},
+		{fmt.Errorf("%w: myfunc", fn.ErrFunctionNotFound), ErrCodeNotFound},
+		{wrapRegistryRequiredError(fn.ErrRegistryRequired, "deploy"), ErrCodeRegistryRequired},
+		{fmt.Errorf("pushing: %w", &transport.Error{StatusCode: http.StatusUnauthorized}), ErrCodeRegistryAuth},
+		{docker.ErrNoDocker, ErrCodeNoContainerEngine},
+		{policy.ErrViolations{{Message: "no"}}, ErrCodePolicyViolation},
+		{ErrPromptCI, ErrCodeInputRequired},
+		{context.Canceled, ErrCodeCanceled},
+	}
+	for _, test := range tests {
+		if code := ErrorCodeOf(test.err); code != test.code {
+			t.Errorf("expected %v for %q, got %v", test.code, test.err, code)
+		}
+	}
+
+	exitCodes := map[int]ErrorCode{}
+	for code, exitCode := range errorExitCodes {
+		if other, ok := exitCodes[exitCode]; ok {
+			t.Errorf("exit code %v of both %v and %v", exitCode, code, other)
+		}
+		exitCodes[exitCode] = code
+	}
+}
+
+// TestPrintError ensures that errors are printed as JSON of their code when
+// the output is JSON, and as their message otherwise.
+func TestPrintError(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	err := withErrorCode(errors.New("no cluster"), ErrCodeNoCluster)
+
+	out := bytes.Buffer{}
+	PrintError(&out, err)
+	if out.String() != "Error: no cluster\n" {
+		t.Errorf("unexpected error %q", out.String())
+	}
+
+	viper.Set("output", "json=v1")
+	out.Reset()
+	PrintError(&out, err)
+	var got jsonError
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Code != ErrCodeNoCluster || got.ExitCode != 6 || got.Message != "no cluster" || got.Kind != "Error" {
+		t.Errorf("unexpected error %+v", got)
+	}
+}
@@ -0,0 +1,393 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// DefaultEventsRecordAddress is the address on which 'events record' listens
+// for CloudEvents when no address is provided.
+const DefaultEventsRecordAddress = "127.0.0.1:8181"
+
+func NewEventsCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Record and replay events",
+		Long: `
+NAME
+	{{rootCmdUse}} events - Record and replay CloudEvents
+
+SYNOPSIS
+	{{rootCmdUse}} events record [--address] [--clear] [--validate] [-p|--path] [-v|--verbose]
+	{{rootCmdUse}} events list [--type] [--since] [--until] [-p|--path]
+	{{rootCmdUse}} events replay [-t|--target] [--type] [--since] [--until]
+	             [-i|--insecure] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Captures CloudEvents into a local event store kept in the function's
+	runtime metadata directory (.func/events.ndjson) such that they can later
+	be replayed against a locally running or deployed instance of the function.
+
+	Recording
+	  'record' starts a CloudEvents receiver which appends every event it
+	  receives to the store.  Point a broker trigger, source or any other
+	  event producer at the receiver to capture events.  With --validate, the
+	  receiver acts as a contract check for a producing function: events which
+	  do not match the function's declared 'events.produces' are rejected.
+
+	Replaying
+	  'replay' sends recorded events, in the order they were received, to the
+	  function.  The --target flag accepts the same values as 'invoke':
+	  "local", "remote" or a URL.  Events can be filtered by type and by the
+	  time at which they were received.  Times are either RFC3339 timestamps or
+	  durations relative to now, such as "24h".
+`,
+		Example: `
+# Record events sent to the default address
+{{rootCmdUse}} events record
+
+# List events of type 'com.example.order' recorded within the last day
+{{rootCmdUse}} events list --type com.example.order --since 24h
+
+# Replay yesterday's events against the locally running function
+{{rootCmdUse}} events replay --target local --since 48h --until 24h
+`,
+		Aliases: []string{"event"},
+	}
+
+	cmd.AddCommand(NewEventsRecordCmd())
+	cmd.AddCommand(NewEventsListCmd())
+	cmd.AddCommand(NewEventsReplayCmd(newClient))
+
+	return cmd
+}
+
+func NewEventsRecordCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record received events into the function's event store",
+		Long: `Record received events into the function's event store
+
+Starts a CloudEvents receiver which appends each event it receives to the
+function's local event store.  Runs until interrupted.
+
+When --validate is provided, received events are checked against the events
+the function declares it produces (events.produces in func.yaml).  Events
+which violate the contract are rejected and not recorded.  Set the receiver
+as the sink of the function to verify the events it emits.
+`,
+		SuggestFor: []string{"rec", "capture"},
+		PreRunE:    bindEnv("address", "clear", "validate", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runEventsRecord(cmd)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().String("address", DefaultEventsRecordAddress, "Address on which to listen for events. ($FUNC_ADDRESS)")
+	cmd.Flags().Bool("clear", false, "Remove previously recorded events before recording. ($FUNC_CLEAR)")
+	cmd.Flags().Bool("validate", false, "Reject events which do not match the function's declared produced events. ($FUNC_VALIDATE)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func NewEventsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded events",
+		Long: `List recorded events
+
+Lists the events held in the function's local event store, optionally
+filtered by type and by the time at which they were received.
+`,
+		Aliases: []string{"ls"},
+		PreRunE: bindEnv("type", "since", "until", "path", "output"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runEventsList(cmd)
+		},
+	}
+
+	addEventFilterFlags(cmd)
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|yaml|csv). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+
+	return cmd
+}
+
+func NewEventsReplayCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay recorded events against a function instance",
+		Long: `Replay recorded events against a function instance
+
+Sends the events held in the function's local event store, in the order they
+were received, to a local or remote instance of the function.
+`,
+		PreRunE: bindEnv("target", "type", "since", "until", "insecure", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runEventsReplay(cmd, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().StringP("target", "t", "", "Function instance to receive the events.  Can be 'local', 'remote' or a URL.  Defaults to auto-discovery if not provided. ($FUNC_TARGET)")
+	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
+	addEventFilterFlags(cmd)
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func addEventFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("type", []string{}, "Only include events of this type.  May be provided multiple times. ($FUNC_TYPE)")
+	cmd.Flags().String("since", "", "Only include events received at or after this time (RFC3339 or a duration such as 24h). ($FUNC_SINCE)")
+	cmd.Flags().String("until", "", "Only include events received at or before this time (RFC3339 or a duration such as 1h). ($FUNC_UNTIL)")
+}
+
+func runEventsRecord(cmd *cobra.Command) (err error) {
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	store, err := fn.NewFunctionEventStore(f)
+	if err != nil {
+		return
+	}
+	if viper.GetBool("clear") {
+		if err = store.Clear(); err != nil {
+			return
+		}
+	}
+
+	l, err := net.Listen("tcp", viper.GetString("address"))
+	if err != nil {
+		return fmt.Errorf("unable to listen for events: %w", err)
+	}
+	defer l.Close()
+
+	var validators []func(cloudevents.Event) error
+	if viper.GetBool("validate") {
+		if len(f.Events.Produces) == 0 {
+			return errors.New("--validate requires the function to declare the events it produces (events.produces)")
+		}
+		validators = append(validators, func(e cloudevents.Event) error {
+			err := f.ValidateEvent(e)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Rejected event %v: %v\n", e.ID(), err)
+			}
+			return err
+		})
+	}
+
+	handler := store.Handler(validators...)
+	if viper.GetBool("verbose") {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(cmd.OutOrStdout(), "Received event %v\n", r.Header.Get("Ce-Id"))
+			next.ServeHTTP(w, r)
+		})
+	}
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Recording events sent to http://%v into %v\n", l.Addr(), store.Path())
+	if err = server.Serve(l); errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return
+}
+
+func runEventsList(cmd *cobra.Command) (err error) {
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	switch Format(viper.GetString("output")) {
+	case Human, Plain, JSON, YAML, CSV:
+	default:
+		return fmt.Errorf("unsupported output format %q, expected human, plain, json, yaml or csv", viper.GetString("output"))
+	}
+	filter, err := newEventFilter(cmd, time.Now())
+	if err != nil {
+		return
+	}
+	store, err := fn.NewFunctionEventStore(f)
+	if err != nil {
+		return
+	}
+	events, err := store.Events(filter)
+	if err != nil {
+		return
+	}
+	write(cmd.OutOrStdout(), recordedEvents(events), viper.GetString("output"))
+	return
+}
+
+func runEventsReplay(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return fn.NewErrNotInitialized(f.Root)
+	}
+	filter, err := newEventFilter(cmd, time.Now())
+	if err != nil {
+		return
+	}
+	store, err := fn.NewFunctionEventStore(f)
+	if err != nil {
+		return
+	}
+	events, err := store.Events(filter)
+	if err != nil {
+		return
+	}
+	if len(events) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No recorded events match")
+		return
+	}
+
+	client, done := newClient(ClientConfig{
+		Verbose:            viper.GetBool("verbose"),
+		InsecureSkipVerify: viper.GetBool("insecure"),
+	})
+	defer done()
+
+	sent, err := client.ReplayEvents(cmd.Context(), f, viper.GetString("target"), events)
+	fmt.Fprintf(cmd.OutOrStdout(), "Replayed %v of %v events\n", sent, len(events))
+	return
+}
+
+// newEventFilter from the filter flags, with relative times calculated
+// against now.
+func newEventFilter(cmd *cobra.Command, now time.Time) (filter fn.EventFilter, err error) {
+	// NOTE: viper.GetStringSlice does not parse string arrays (see subscribe)
+	if filter.Types, err = cmd.Flags().GetStringArray("type"); err != nil {
+		return
+	}
+	if filter.Since, err = parseEventTime(viper.GetString("since"), now); err != nil {
+		return filter, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.Until, err = parseEventTime(viper.GetString("until"), now); err != nil {
+		return filter, fmt.Errorf("invalid --until: %w", err)
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return filter, errors.New("--until must not be before --since")
+	}
+	return
+}
+
+// parseEventTime accepts either an RFC3339 timestamp or a duration which is
+// interpreted as that long before now.  An empty value is the zero time.
+func parseEventTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type recordedEvents []fn.RecordedEvent
+
+func (items recordedEvents) Human(w io.Writer) error {
+	return items.Plain(w)
+}
+
+func (items recordedEvents) Plain(w io.Writer) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n", "RECEIVED", "ID", "TYPE", "SOURCE")
+	for _, item := range items {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n", item.Received.Format(time.RFC3339), item.Event.ID(), item.Event.Type(), item.Event.Source())
+	}
+	return nil
+}
+
+func (items recordedEvents) JSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func (items recordedEvents) XML(w io.Writer) error {
+	return errors.New("xml is not supported for events")
+}
+
+func (items recordedEvents) YAML(w io.Writer) error {
+	// Events have a JSON representation only; convert through it such that
+	// the YAML output carries the same attribute names.
+	b, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	var v any
+	if err = json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	return yaml.NewEncoder(w).Encode(v)
+}
+
+func (items recordedEvents) URL(w io.Writer) error {
+	return errors.New("url is not supported for events")
+}
+
+func (items recordedEvents) CSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"received", "id", "type", "source"})
+	for _, item := range items {
+		_ = cw.Write([]string{item.Received.Format(time.RFC3339), item.Event.ID(), item.Event.Type(), item.Event.Source()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestEvents_List ensures that recorded events are listed and filtered.
+func TestEvents_List(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	f, err := fn.New().Init(fn.Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := fn.NewFunctionEventStore(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{"com.example.a", "com.example.b"} {
+		e := cloudevents.NewEvent()
+		e.SetID(typ + "-id")
+		e.SetSource("/test")
+		e.SetType(typ)
+		if err := store.Append(e, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := bytes.Buffer{}
+	cmd := NewEventsCmd(NewTestClient())
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list", "--type", "com.example.b", "--since", "1h"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "com.example.b-id") {
+		t.Fatalf("expected event b to be listed, got:\n%v", out.String())
+	}
+	if strings.Contains(out.String(), "com.example.a-id") {
+		t.Fatalf("expected event a to be filtered, got:\n%v", out.String())
+	}
+}
+
+// TestEvents_RecordValidateReleasesAddress ensures that the address listened
+// upon is released when recording fails to start, such as when --validate is
+// given for a function which declares no events.
+func TestEvents_RecordValidateReleasesAddress(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+
+	cmd := NewEventsCmd(NewTestClient())
+	cmd.SetArgs([]string{"record", "--address", address, "--validate"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected --validate to fail for a function which declares no events")
+	}
+	if l, err = net.Listen("tcp", address); err != nil {
+		t.Fatalf("expected the address to be released. %v", err)
+	}
+	l.Close()
+}
+
+// TestEvents_ParseTime ensures both durations and timestamps are accepted.
+func TestEvents_ParseTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"2024-01-01T12:00:00Z", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseEventTime(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%q: unexpected error state: %v", tt.value, err)
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Fatalf("%q: expected %v, got %v", tt.value, tt.want, got)
+		}
+	}
+}
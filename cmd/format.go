@@ -1,21 +1,72 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
 )
 
 type Format string
 
 const (
-	Human Format = "human" // Headers, indentation, justification etc.
-	Plain        = "plain" // Suitable for cli automation via sed/awk etc.
-	JSON         = "json"  // Technically a ⊆ yaml, but no one likes yaml.
-	XML          = "xml"
-	YAML         = "yaml"
-	URL          = "url"
+	Human  Format = "human" // Headers, indentation, justification etc.
+	Plain         = "plain" // Suitable for cli automation via sed/awk etc.
+	JSON          = "json"  // Technically a ⊆ yaml, but no one likes yaml.
+	XML           = "xml"
+	YAML          = "yaml"
+	URL           = "url"
+	CSV           = "csv"    // Header row and records, for spreadsheets etc.
+	NDJSON        = "ndjson" // A JSON object per line, writable as listed.
+
+	// GoTemplate is given as go-template=<template>, as with kubectl, the
+	// template being executed with the formatter's data.
+	GoTemplate = "go-template"
+
+	// JSONPath is given as jsonpath=<expression>, as with kubectl, the
+	// expression being evaluated against the formatter's JSON output.
+	JSONPath = "jsonpath"
 )
 
+// OutputAPIVersion is the apiVersion of the envelope of the json and yaml
+// output of list and describe.  Field names are stable within a version; a
+// version is selected as, for example, json=v1, such that scripts are not
+// broken by the output of a later version.
+const OutputAPIVersion = "func.knative.dev/v1"
+
+// outputVersions which may be selected, the last being that of unversioned
+// json and yaml.
+var outputVersions = []string{"v1"}
+
+// listEnvelope of versioned list output, of the items of the kind.
+type listEnvelope struct {
+	APIVersion string `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string `json:"kind" yaml:"kind"`
+	Items      any    `json:"items" yaml:"items"`
+}
+
+// versionedFormat of an output format given with its version, such as
+// json=v1, returning the format alone.  Only json, ndjson and yaml output are
+// versioned.
+func versionedFormat(formatName string) (string, error) {
+	format, version, ok := strings.Cut(formatName, "=")
+	if !ok || format == GoTemplate || format == JSONPath {
+		return formatName, nil
+	}
+	if format != JSON && format != YAML && format != NDJSON {
+		return "", fmt.Errorf("output format %q is not versioned", format)
+	}
+	if !slices.Contains(outputVersions, version) {
+		return "", fmt.Errorf("unsupported %v output version %q. Accepts %v", format, version, strings.Join(outputVersions, ", "))
+	}
+	return format, nil
+}
+
 // formatter is any structure which has methods for serialization.
 type Formatter interface {
 	Human(io.Writer) error
@@ -24,12 +75,31 @@ type Formatter interface {
 	XML(io.Writer) error
 	YAML(io.Writer) error
 	URL(io.Writer) error
+	CSV(io.Writer) error
 }
 
 // write to the output the output of the formatter's appropriate serilization function.
 // the command to exit with value 2.
 func write(out io.Writer, s Formatter, formatName string) {
-	var err error
+	t, err := goTemplate(formatName)
+	if t != nil || err != nil {
+		if err == nil {
+			err = t.Execute(out, templateData(s))
+		}
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+	if p, err := jsonPath(formatName); p != nil || err != nil {
+		if err == nil {
+			err = executeJSONPath(out, p, s)
+		}
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
 	switch Format(formatName) {
 	case Human:
 		err = s.Human(out)
@@ -43,6 +113,14 @@ func write(out io.Writer, s Formatter, formatName string) {
 		err = s.YAML(out)
 	case URL:
 		err = s.URL(out)
+	case CSV:
+		err = s.CSV(out)
+	case NDJSON:
+		if n, ok := s.(interface{ NDJSON(io.Writer) error }); ok {
+			err = n.NDJSON(out)
+		} else {
+			err = fmt.Errorf("format not supported: %v", formatName)
+		}
 	default:
 		err = fmt.Errorf("format not recognized: %v", formatName)
 	}
@@ -50,3 +128,75 @@ func write(out io.Writer, s Formatter, formatName string) {
 		panic(err)
 	}
 }
+
+// writeStream writes each formatter received from the channel, as would
+// write, once it is received and until the channel is closed.  It is the
+// variant of write for output which is produced incrementally, such as the
+// pages of a list written as they are listed.
+func writeStream(out io.Writer, ss <-chan Formatter, formatName string) {
+	for s := range ss {
+		write(out, s, formatName)
+	}
+}
+
+// goTemplate of the output format if it is of the form go-template=<template>,
+// otherwise nil.
+func goTemplate(formatName string) (*template.Template, error) {
+	text, ok := strings.CutPrefix(formatName, GoTemplate+"=")
+	if !ok {
+		return nil, nil
+	}
+	t, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v output: %w", GoTemplate, err)
+	}
+	return t, nil
+}
+
+// templateData of the formatter, being the formatter itself unless it wraps
+// its data, for example with options of its output.
+func templateData(s Formatter) any {
+	if d, ok := s.(interface{ templateData() any }); ok {
+		return d.templateData()
+	}
+	return s
+}
+
+// jsonPath of the output format if it is of the form jsonpath=<expression>,
+// otherwise nil.  As with kubectl, the braces of an expression of a single
+// path may be omitted, such as jsonpath=.items[*].url, and fields missing of
+// some of the output are printed as empty.
+func jsonPath(formatName string) (*jsonpath.JSONPath, error) {
+	text, ok := strings.CutPrefix(formatName, JSONPath+"=")
+	if !ok {
+		return nil, nil
+	}
+	if !strings.Contains(text, "{") {
+		if !strings.HasPrefix(text, ".") {
+			text = "." + text
+		}
+		text = "{" + text + "}"
+	}
+	p := jsonpath.New("output").AllowMissingKeys(true)
+	if err := p.Parse(text); err != nil {
+		return nil, fmt.Errorf("invalid %v output: %w", JSONPath, err)
+	}
+	return p, nil
+}
+
+// executeJSONPath against the JSON output of the formatter, being that of its
+// versioned envelope, such that expressions are of its field names, such as
+// {.items[*].url} of a list.
+func executeJSONPath(out io.Writer, p *jsonpath.JSONPath, s Formatter) error {
+	buf := bytes.Buffer{}
+	if err := s.JSON(&buf); err != nil {
+		return err
+	}
+	var data any
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return err
+	}
+	return p.Execute(out, data)
+}
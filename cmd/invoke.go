@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/ory/viper"
@@ -25,7 +27,7 @@ NAME
 SYNOPSIS
 	{{rootCmdUse}} invoke [-t|--target] [-f|--format]
 	             [--id] [--source] [--type] [--data] [--file] [--content-type]
-	             [-s|--save] [-p|--path] [-i|--insecure] [-c|--confirm] [-v|--verbose]
+	             [--stream] [-s|--save] [-p|--path] [-i|--insecure] [-c|--confirm] [-v|--verbose]
 
 DESCRIPTION
 	Invokes the function by sending a test request to the currently running
@@ -66,6 +68,16 @@ DESCRIPTION
 	  To override this behavior, use the --format (-f) flag.
 	    {{rootCmdUse}} invoke -f=cloudevent -t=http://my-sink.my-cluster
 
+	Streaming Responses
+	  Functions which respond with a stream, such as those producing output
+	  incrementally, can be invoked with the --stream flag.  By default the
+	  function is expected to respond with Server-Sent Events, each of which
+	  is printed as it is received.  With --stream=websocket the connection
+	  is instead upgraded to a WebSocket, over which the data is sent, and
+	  each message received is printed until the function closes the
+	  connection.  Interrupt (^C) to end a stream early.
+	    {{rootCmdUse}} invoke --stream --data='{"prompt":"Hello"}'
+
 EXAMPLES
 
 	o Invoke the default (local or remote) running function with default values
@@ -101,12 +113,18 @@ EXAMPLES
 
 	o In case you need to specifically send GET request
 		$ {{rootCmdUse}} invoke --request-type=GET
+
+	o Invoke a function which responds with Server-Sent Events
+		$ {{rootCmdUse}} invoke --stream
+
+	o Invoke a function over a WebSocket
+		$ {{rootCmdUse}} invoke --stream=websocket --data="Hello"
 `,
 		SuggestFor: []string{"emit", "emti", "send", "emit", "exec", "nivoke",
 			"onvoke", "unvoke", "knvoke", "imvoke", "ihvoke", "ibvoke"},
 		PreRunE: bindEnv("path", "format", "target", "id", "source", "type",
-			"data", "content-type", "request-type", "file", "insecure",
-			"confirm", "verbose"),
+			"data", "content-type", "request-type", "file", "stream",
+			"insecure", "confirm", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runInvoke(cmd, args, newClient)
 		},
@@ -128,6 +146,8 @@ EXAMPLES
 	cmd.Flags().StringP("request-type", "", fn.DefaultInvokeRequestType, "Type of request to use. Can be POST or GET. ($FUNC_REQUEST_TYPE)")
 	cmd.Flags().StringP("data", "", fn.DefaultInvokeData, "Data to send in the request. ($FUNC_DATA)")
 	cmd.Flags().StringP("file", "", "", "Path to a file to use as data. Overrides --data flag and should be sent with a correct --content-type. ($FUNC_FILE)")
+	cmd.Flags().StringP("stream", "", "", fmt.Sprintf("Consume a streamed response.  Can be '%v' (Server-Sent Events) or '%v'.  Defaults to '%v' if provided without a value. ($FUNC_STREAM)", fn.StreamSSE, fn.StreamWebSocket, fn.StreamSSE))
+	cmd.Flags().Lookup("stream").NoOptDefVal = fn.StreamSSE
 	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
 	addConfirmFlag(cmd, cfg.Confirm)
 	addPathFlag(cmd)
@@ -166,7 +186,7 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 	}
 
 	// Client instance from env vars, flags, args and user prompts (if --confirm)
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure})
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, InsecureSkipVerify: cfg.Insecure, Deployer: f.Deploy.Deployer})
 	defer done()
 
 	// Message to send the running function built from parameters gathered
@@ -190,6 +210,22 @@ func runInvoke(cmd *cobra.Command, _ []string, newClient ClientFactory) (err err
 		m.Data = content
 	}
 
+	// Invoke, consuming a streamed response
+	if cfg.Stream != "" {
+		if cfg.Verbose {
+			fmt.Fprintln(cmd.OutOrStdout(), "Function invoked.  Streaming response:")
+		}
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		return client.InvokeStream(ctx, cfg.Path, cfg.Target, m, cfg.Stream, func(msg fn.StreamMessage) error {
+			if cfg.Verbose && (msg.Event != "" || msg.ID != "") {
+				fmt.Fprintf(cmd.OutOrStdout(), "  [event: %v id: %v]\n", msg.Event, msg.ID)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), msg.Data)
+			return nil
+		})
+	}
+
 	// Invoke
 	metadata, body, err := client.Invoke(cmd.Context(), cfg.Path, cfg.Target, m)
 	if err != nil {
@@ -236,6 +272,7 @@ type invokeConfig struct {
 	ContentType string
 	RequestType string
 	File        string
+	Stream      string
 	Confirm     bool
 	Verbose     bool
 	Insecure    bool
@@ -253,6 +290,7 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 		ContentType: viper.GetString("content-type"),
 		RequestType: viper.GetString("request-type"),
 		File:        viper.GetString("file"),
+		Stream:      strings.ToLower(viper.GetString("stream")),
 		Confirm:     viper.GetBool("confirm"),
 		Verbose:     viper.GetBool("verbose"),
 		Insecure:    viper.GetBool("insecure"),
@@ -272,6 +310,14 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 		cfg.Format = "cloudevent"
 	}
 
+	switch cfg.Stream {
+	case "", fn.StreamSSE, fn.StreamWebSocket:
+	case "ws":
+		cfg.Stream = fn.StreamWebSocket
+	default:
+		return cfg, fmt.Errorf("invalid --stream %q, expected '%v' or '%v'", cfg.Stream, fn.StreamSSE, fn.StreamWebSocket)
+	}
+
 	// if not in confirm/prompting mode, the cfg structure is complete.
 	if !cfg.Confirm {
 		return
@@ -292,6 +338,7 @@ func newInvokeConfig() (cfg invokeConfig, err error) {
 	fmt.Printf("Data: %v\n", cfg.Data)
 	fmt.Printf("Content Type: %v\n", cfg.ContentType)
 	fmt.Printf("File: %v\n", cfg.File)
+	fmt.Printf("Stream: %v\n", cfg.Stream)
 	fmt.Printf("Insecure: %v\n", cfg.Insecure)
 	return
 }
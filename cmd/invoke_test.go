@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -78,3 +80,36 @@ func TestInvoke(t *testing.T) {
 		t.Fatal("function was not invoked")
 	}
 }
+
+// TestInvoke_Stream ensures that each event of a streamed response is
+// printed, and that only known stream protocols are accepted.
+func TestInvoke_Stream(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	if _, err := fn.New().Init(fn.Function{Runtime: "go", Root: root}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: Hello\n\ndata: World\n\n")
+	}))
+	t.Cleanup(s.Close)
+
+	out := strings.Builder{}
+	cmd := NewInvokeCmd(NewClient)
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--target", s.URL, "--stream"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Hello\nWorld\n" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	cmd = NewInvokeCmd(NewClient)
+	cmd.SetArgs([]string{"--target", s.URL, "--stream=grpc"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an unknown stream protocol to error")
+	}
+}
@@ -1,22 +1,55 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/progress"
 )
 
+// errListConnect is returned when functions cannot be listed, describing
+// what is required to list them.
+var errListConnect = errors.New(`cannot connect to Knative cluster
+
+The 'func list' command shows functions deployed to your Knative cluster.
+
+To use this command, you need:
+  1. A running Kubernetes cluster
+  2. Knative Serving installed on the cluster
+  3. kubectl configured to access your cluster
+
+Workflow:
+  func create --language go myfunction    Create a function
+  func deploy --registry <registry>       Deploy to cluster
+  func list                               See your deployed functions
+
+Troubleshooting:
+  kubectl get pods -n knative-serving     Check Knative installation
+  kubectl config current-context          Verify cluster connection
+
+Installation guide: https://knative.dev/docs/serving/#installation`)
+
+// listStreamPageSize is the number of functions listed per request when
+// streamed as ndjson.
+const listStreamPageSize = 500
+
 func NewListCmd(newClient ClientFactory) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -24,6 +57,72 @@ func NewListCmd(newClient ClientFactory) *cobra.Command {
 		Long: `List deployed functions
 
 Lists deployed functions.
+
+With --selector only the functions whose labels match the Kubernetes label
+selector are listed, such as 'team=payments,env!=prod'.  Functions are
+selected by the cluster, by the labels of their services.
+
+With --sort-by the functions are ordered by name, namespace, runtime, ready
+status or creation time, ascending unless --descending.  Functions equal by
+that are ordered by namespace and then name.
+
+With --output wide the human output includes the age of each function, the
+image deployed, by digest once resolved by the cluster, the latest ready
+revision, the percentage of traffic routed to each revision, such as when
+split between revisions, and the provisioning of each function: its CPU and
+memory as request/limit, such as 100m/500m, and its scale as min-max, such as
+1-10, '*' being unbounded.
+
+With --columns the human, plain and csv output is of the given columns, in
+order, from name, namespace, runtime, url, ready, age, created, image,
+revision, traffic, cpu, memory, scale, events, location and path.  By default
+those of the human and plain output are name, namespace, runtime, url and
+ready, and those of csv are all but age.  Other output formats include all
+fields.
+
+With --events the number of event bindings of each function is listed: the
+triggers, sink bindings and channel subscriptions whose sink it is, such that
+those which are event-driven are told at a glance.  The bindings are included
+in other output formats, and are those which 'describe' details.
+
+With --local the functions initialized in the directories beneath --root,
+by default the current directory, are listed as well, including those not
+deployed.  Each is marked as local if only initialized locally, deployed if
+only deployed, or both.  Hidden directories and those of dependencies such
+as node_modules are not searched.
+
+With --limit at most that many functions are listed, as a page of those
+deployed, and the command to list the next page with --continue is printed
+to stderr.  Pages are listed by the cluster, such that functions are ordered
+with --sort-by only within each page.  A continue token expires some minutes
+after it is issued, after which the list is started again from the first
+page.
+
+The json and yaml output is a FunctionList of apiVersion
+func.knative.dev/v1, whose items are the functions.  Field names are stable
+within a version, which may be given with the format, such as --output
+json=v1, so as not to be affected by the output of later versions.
+
+With --output jsonpath=<expression> the JSONPath expression is evaluated
+against the json output, as with kubectl, such as '{.items[*].url}'.
+
+The ndjson output is a line of JSON per function, of the fields of the items
+of a FunctionList, written as functions are listed rather than once all are,
+such as of all namespaces.  With --sort-by or --local they are written once
+listed, and with --watch each is written as it is deployed or changes.
+
+With --cache the functions listed are cached beneath ~/.cache/func, or
+$XDG_CACHE_HOME/func if defined, by kubeconfig context, namespace and
+selector.
+With --cached those last cached are listed should the cluster be
+unreachable, such as of a flaky VPN, noting the time at which they were
+cached on stderr.  Caching is enabled by default with listCache of the
+global config.
+
+With --watch the list is kept updated as functions are deployed, change
+status or are deleted, until interrupted.  In a terminal the list is
+redrawn in place; otherwise, and with an --output other than human, the
+list is written anew on each change.
 `,
 		Example: `
 # List all functions in the current namespace with human readable output
@@ -34,10 +133,52 @@ Lists deployed functions.
 
 # List all functions in all namespaces with JSON output
 {{rootCmdUse}} list --all-namespaces --output json
+
+# List the functions of the payments team other than those in production
+{{rootCmdUse}} list --selector 'team=payments,env!=prod'
+
+# List the functions in all namespaces, most recently created first
+{{rootCmdUse}} list --all-namespaces --sort-by created --descending
+
+# List the name and URL of each function with a template
+{{rootCmdUse}} list --output go-template='{{"{{"}}range .{{"}}"}}{{"{{"}}.Name{{"}}"}} {{"{{"}}.URL{{"}}"}}{{"{{"}}"\n"{{"}}"}}{{"{{"}}end{{"}}"}}'
+
+# List the URL of each function with a JSONPath expression, as with kubectl
+{{rootCmdUse}} list --output jsonpath='{.items[*].url}'
+
+# List functions with the image and revision deployed of each
+{{rootCmdUse}} list --output wide
+
+# List the name, URL and image of each function
+{{rootCmdUse}} list --columns name,url,image
+
+# List the number of event bindings of each function
+{{rootCmdUse}} list --events
+
+# List the functions of a workspace, whether deployed or not
+{{rootCmdUse}} list --local --root ~/src/functions
+
+# List the first page of 100 functions of a namespace of many
+{{rootCmdUse}} list --namespace test --limit 100
+
+# List the next page, continuing from the token printed of the first
+{{rootCmdUse}} list --namespace test --limit 100 --continue <token>
+
+# Stream the functions of all namespaces as a line of JSON each
+{{rootCmdUse}} list --all-namespaces --output ndjson
+
+# List the functions last cached should the cluster be unreachable
+{{rootCmdUse}} list --cache --cached
+
+# Keep the list updated as functions are deployed, become ready or are deleted
+{{rootCmdUse}} list --watch
+
+# List the functions deployed as plain Deployments by the k8s deployer
+{{rootCmdUse}} list --deployer k8s
 `,
 		SuggestFor: []string{"lsit"},
 		Aliases:    []string{"ls"},
-		PreRunE:    bindEnv("all-namespaces", "output", "namespace", "verbose"),
+		PreRunE:    bindEnv("all-namespaces", "output", "namespace", "selector", "sort-by", "descending", "columns", "local", "root", "limit", "continue", "watch", "events", "cache", "cached", "deployer", "verbose"),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runList(cmd, args, newClient)
 		},
@@ -69,10 +210,29 @@ Lists deployed functions.
 	// Flags
 	cmd.Flags().BoolP("all-namespaces", "A", false, "List functions in all namespaces. If set, the --namespace flag is ignored.")
 	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace for which to list functions. ($FUNC_NAMESPACE)")
-	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|xml|yaml) ($FUNC_OUTPUT)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|wide|plain|json[=v1]|ndjson[=v1]|xml|yaml[=v1]|csv|go-template=<template>|jsonpath=<expression>) ($FUNC_OUTPUT)")
+	cmd.Flags().StringP("selector", "l", "", "List only functions whose labels match the label selector, such as 'team=payments,env!=prod'. ($FUNC_SELECTOR)")
+	cmd.Flags().String("sort-by", "", fmt.Sprintf("Order functions by %v. ($FUNC_SORT_BY)", strings.Join(listSortKeys, "|")))
+	cmd.Flags().Bool("descending", false, "Order functions descending rather than ascending with --sort-by. ($FUNC_DESCENDING)")
+	cmd.Flags().String("columns", "", fmt.Sprintf("Comma separated columns of human, plain and csv output, from %v. ($FUNC_COLUMNS)", strings.Join(listColumnNames, ",")))
+	cmd.Flags().Bool("events", false, "Also list the number of event bindings of each function. ($FUNC_EVENTS)")
+	cmd.Flags().Bool("local", false, "Also list the functions initialized beneath --root, deployed or not. ($FUNC_LOCAL)")
+	cmd.Flags().String("root", ".", "Directory beneath which local functions are listed with --local. ($FUNC_ROOT)")
+	cmd.Flags().Int64("limit", 0, "List at most this many functions, as a page continued with --continue. 0 is all. ($FUNC_LIMIT)")
+	cmd.Flags().String("continue", "", "List the page of functions continuing from the token printed of the previous page. ($FUNC_CONTINUE)")
+	cmd.Flags().BoolP("watch", "w", false, "Keep the list updated as functions change, until interrupted. ($FUNC_WATCH)")
+	cmd.Flags().Bool("cache", cfg.ListCache, "Cache the functions listed, to be listed with --cached should the cluster be unreachable. ($FUNC_CACHE)")
+	cmd.Flags().String("deployer", fn.DeployerKnative, fmt.Sprintf("List the functions deployed by this deployer. [%v|%v] ($FUNC_DEPLOYER)", fn.DeployerKnative, fn.DeployerK8s))
+	cmd.Flags().Bool("cached", false, "List the functions last cached should the cluster be unreachable. ($FUNC_CACHED)")
 	addVerboseFlag(cmd, cfg.Verbose)
 
-	if err := cmd.RegisterFlagCompletionFunc("output", CompleteOutputFormatList); err != nil {
+	if err := cmd.RegisterFlagCompletionFunc("output", CompleteListOutputFormatList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+	if err := cmd.RegisterFlagCompletionFunc("sort-by", CompleteListSortByList); err != nil {
+		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
+	}
+	if err := cmd.RegisterFlagCompletionFunc("columns", CompleteListColumnsList); err != nil {
 		fmt.Println("internal: error while calling RegisterFlagCompletionFunc: ", err)
 	}
 
@@ -85,34 +245,62 @@ func runList(cmd *cobra.Command, _ []string, newClient ClientFactory) (err error
 		return err
 	}
 
-	client, done := newClient(ClientConfig{Verbose: cfg.Verbose})
-	defer done()
-
-	items, err := client.List(cmd.Context(), cfg.Namespace)
-	if err != nil {
-		return fmt.Errorf(`cannot connect to Knative cluster
+	var local []fn.Function
+	if cfg.Local {
+		if local, err = fn.FindFunctions(cfg.Root); err != nil {
+			return err
+		}
+	}
 
-The 'func list' command shows functions deployed to your Knative cluster.
+	client, done := newClient(ClientConfig{Verbose: cfg.Verbose, Deployer: cfg.Deployer})
+	defer done()
 
-To use this command, you need:
-  1. A running Kubernetes cluster
-  2. Knative Serving installed on the cluster
-  3. kubectl configured to access your cluster
+	if cfg.Watch {
+		return runListWatch(cmd, client, cfg, local)
+	}
+	if cfg.Output == NDJSON && cfg.Limit == 0 && cfg.SortBy == "" && !cfg.Local && !cfg.Cache && !cfg.Cached {
+		return runListStream(cmd, client, cfg)
+	}
 
-Workflow:
-  func create --language go myfunction    Create a function
-  func deploy --registry <registry>       Deploy to cluster
-  func list                               See your deployed functions
+	var items []fn.ListItem
+	var next string
+	if cfg.Limit > 0 || cfg.Continue != "" {
+		items, next, err = client.ListPage(cmd.Context(), cfg.Namespace, fn.WithListSelector(cfg.Selector),
+			fn.WithListLimit(cfg.Limit), fn.WithListContinue(cfg.Continue))
+	} else {
+		items, err = client.List(cmd.Context(), cfg.Namespace, fn.WithListSelector(cfg.Selector))
+	}
+	if err == nil && cfg.Cache {
+		if err := writeListCache(cfg, items); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: the functions listed could not be cached. %v\n", err)
+		}
+	} else if err != nil && cfg.Cached {
+		var c listCache
+		if c, err = readListCache(cfg); err != nil {
+			return withErrorCode(fmt.Errorf("the cluster is unreachable, and %w", err), ErrCodeNoCluster)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "The cluster is unreachable. Listing the functions cached %v (%v ago)\n",
+			c.Listed.Format(time.RFC3339), time.Since(c.Listed).Round(time.Second))
+		items = c.Items
+	}
+	if err != nil && cfg.Continue != "" {
+		return err
+	} else if err != nil {
+		return withErrorCode(errListConnect, ErrCodeNoCluster)
+	}
 
-Troubleshooting:
-  kubectl get pods -n knative-serving     Check Knative installation
-  kubectl config current-context          Verify cluster connection
+	if cfg.Local {
+		items = mergeLocalFunctions(items, local, cfg)
+	}
 
-Installation guide: https://knative.dev/docs/serving/#installation`)
+	if next != "" {
+		defer fmt.Fprintf(cmd.ErrOrStderr(), "More functions are listed with --continue %v\n", next)
 	}
 
-	if len(items) == 0 {
-		if cfg.Namespace != "" {
+	if len(items) == 0 && cfg.Output != NDJSON {
+		if cfg.Selector != "" {
+			fmt.Printf("no functions found matching selector '%v'\n", cfg.Selector)
+		} else if cfg.Namespace != "" {
 			fmt.Printf(`no functions found in namespace '%v'
 
 'func list' shows functions that have been deployed to your cluster.
@@ -138,25 +326,126 @@ To see functions here:
 		return
 	}
 
-	write(os.Stdout, listItems(items), cfg.Output)
+	sortListItems(items, cfg.SortBy, cfg.Descending)
+	write(cmd.OutOrStdout(), listTable{items, cfg.Columns}, cfg.Output)
 
 	return
 }
 
+// runListStream writes the functions as ndjson as they are listed, by pages,
+// rather than once all are listed.
+func runListStream(cmd *cobra.Command, client *fn.Client, cfg listConfig) error {
+	pages := make(chan Formatter)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(pages)
+		first, token := true, cfg.Continue
+		for first || token != "" {
+			items, next, err := client.ListPage(cmd.Context(), cfg.Namespace, fn.WithListSelector(cfg.Selector),
+				fn.WithListLimit(listStreamPageSize), fn.WithListContinue(token))
+			if err != nil {
+				if first && token == "" {
+					err = withErrorCode(errListConnect, ErrCodeNoCluster)
+				}
+				errs <- err
+				return
+			}
+			pages <- listItems(items)
+			first, token = false, next
+		}
+		errs <- nil
+	}()
+	writeStream(cmd.OutOrStdout(), pages, cfg.Output)
+	return <-errs
+}
+
+// runListWatch writes the list of functions each time it changes, until the
+// command's context is done.  In an interactive terminal the human output is
+// redrawn in place.
+func runListWatch(cmd *cobra.Command, client *fn.Client, cfg listConfig, local []fn.Function) error {
+	ch, err := client.Watch(cmd.Context(), cfg.Namespace, fn.WithListSelector(cfg.Selector))
+	if err != nil {
+		return fmt.Errorf("cannot watch the functions deployed: %w", err)
+	}
+	w := cmd.OutOrStdout()
+	redraw := cfg.Output == "human" && !ciMode() && progress.IsTerminal(w)
+	first := true
+	listed := map[string]fn.ListItem{}
+	for items := range ch {
+		if cfg.Output == NDJSON {
+			if cfg.Local {
+				items = mergeLocalFunctions(items, local, cfg)
+			}
+			write(w, listItems(changedListItems(listed, items)), cfg.Output)
+			continue
+		}
+		if redraw {
+			fmt.Fprint(w, "\033[H\033[2J") // cursor home, clear screen
+		} else if !first && (cfg.Output == "human" || cfg.Output == "plain") {
+			fmt.Fprintln(w)
+		}
+		first = false
+		if cfg.Local {
+			items = mergeLocalFunctions(items, local, cfg)
+		}
+		sortListItems(items, cfg.SortBy, cfg.Descending)
+		write(w, listTable{items, cfg.Columns}, cfg.Output)
+	}
+	return nil
+}
+
+// changedListItems of those listed, being those not previously listed or
+// which differ from when they were, updating those previously listed.
+func changedListItems(listed map[string]fn.ListItem, items []fn.ListItem) (changed []fn.ListItem) {
+	for _, item := range items {
+		key := item.Namespace + "/" + item.Name
+		if previous, ok := listed[key]; !ok || !reflect.DeepEqual(previous, item) {
+			changed = append(changed, item)
+		}
+		listed[key] = item
+	}
+	return
+}
+
 // CLI Configuration (parameters)
 // ------------------------------
 
 type listConfig struct {
-	Namespace string
-	Output    string
-	Verbose   bool
+	Namespace  string
+	Output     string
+	Selector   string
+	SortBy     string
+	Descending bool
+	Columns    []string
+	Events     bool
+	Local      bool
+	Root       string
+	Limit      int64
+	Continue   string
+	Watch      bool
+	Cache      bool
+	Cached     bool
+	Deployer   string
+	Verbose    bool
 }
 
 func newListConfig(cmd *cobra.Command) (cfg listConfig, err error) {
 	cfg = listConfig{
-		Namespace: viper.GetString("namespace"),
-		Output:    viper.GetString("output"),
-		Verbose:   viper.GetBool("verbose"),
+		Namespace:  viper.GetString("namespace"),
+		Output:     viper.GetString("output"),
+		Selector:   viper.GetString("selector"),
+		SortBy:     viper.GetString("sort-by"),
+		Descending: viper.GetBool("descending"),
+		Events:     viper.GetBool("events"),
+		Local:      viper.GetBool("local"),
+		Root:       viper.GetString("root"),
+		Limit:      viper.GetInt64("limit"),
+		Continue:   viper.GetString("continue"),
+		Watch:      viper.GetBool("watch"),
+		Cache:      viper.GetBool("cache"),
+		Cached:     viper.GetBool("cached"),
+		Deployer:   viper.GetString("deployer"),
+		Verbose:    viper.GetBool("verbose"),
 	}
 	// If --all-namespaces, zero out any value for namespace (such as)
 	// "all" to the lister.
@@ -167,11 +456,309 @@ func newListConfig(cmd *cobra.Command) (cfg listConfig, err error) {
 	// specifying both -A and --namespace is logically inconsistent
 	if cmd.Flags().Changed("namespace") && viper.GetBool("all-namespaces") {
 		err = errors.New("both --namespace and --all-namespaces specified")
+		return
+	}
+
+	if _, err = labels.Parse(cfg.Selector); err != nil {
+		err = fmt.Errorf("invalid --selector %q: %w", cfg.Selector, err)
+		return
+	}
+
+	if cfg.Deployer != fn.DeployerKnative && cfg.Deployer != fn.DeployerK8s {
+		err = fmt.Errorf("unrecognized value for --deployer '%v'.  Accepts '%v' or '%v'", cfg.Deployer, fn.DeployerKnative, fn.DeployerK8s)
+		return
+	}
+
+	if cfg.Limit < 0 {
+		err = fmt.Errorf("--limit may not be negative, got %v", cfg.Limit)
+		return
+	}
+	if cfg.Limit > 0 || cfg.Continue != "" {
+		if cfg.Watch {
+			err = errors.New("--limit and --continue may not be given with --watch")
+			return
+		}
+		if cfg.Local {
+			err = errors.New("--limit and --continue may not be given with --local")
+			return
+		}
+	}
+
+	if (cfg.Cache || cfg.Cached) && (cfg.Watch || cfg.Limit > 0 || cfg.Continue != "") {
+		err = errors.New("--cache and --cached may not be given with --watch, --limit or --continue")
+		return
+	}
+
+	if cfg.SortBy != "" && !slices.Contains(listSortKeys, cfg.SortBy) {
+		err = fmt.Errorf("unsupported --sort-by %q. Accepts %v", cfg.SortBy, strings.Join(listSortKeys, ", "))
+		return
+	}
+
+	cfg.Columns, err = parseListColumns(viper.GetString("columns"))
+	if err != nil {
+		return
+	}
+
+	if cfg.Output, err = versionedFormat(cfg.Output); err != nil {
+		return
+	}
+	if _, err = goTemplate(cfg.Output); err != nil {
+		return
+	}
+	if _, err = jsonPath(cfg.Output); err != nil {
+		return
+	}
+
+	// Wide is the human output of more columns, unless given.
+	if cfg.Output == "wide" {
+		cfg.Output = "human"
+		if len(cfg.Columns) == 0 {
+			cfg.Columns = wideListColumns
+		}
+	}
+
+	// Functions are listed with their number of event bindings, and local
+	// functions with their location and path, unless the columns are given.
+	var extra []string
+	if cfg.Events {
+		extra = append(extra, "events")
+	}
+	if cfg.Local {
+		extra = append(extra, "location", "path")
+	}
+	if len(extra) > 0 && viper.GetString("columns") == "" {
+		columns := cfg.Columns
+		if len(columns) == 0 {
+			columns = defaultListColumns
+			if cfg.Output == CSV {
+				columns = csvListColumns
+			}
+		}
+		cfg.Columns = append(slices.Clone(columns), extra...)
 	}
 
 	return
 }
 
+// Local Functions
+// ---------------
+
+// Locations of functions listed with local functions.
+const (
+	listLocationLocal    = "local"
+	listLocationDeployed = "deployed"
+	listLocationBoth     = "both"
+)
+
+// mergeLocalFunctions into the items deployed, marking each with its
+// location.  Local functions are merged with those deployed of the same name
+// in the namespace to which they were last deployed.  Those deployed to a
+// namespace other than that listed, or whose labels do not match the
+// selector, are omitted.
+func mergeLocalFunctions(items []fn.ListItem, local []fn.Function, cfg listConfig) []fn.ListItem {
+	merged := make([]fn.ListItem, len(items))
+	deployed := map[string]int{}
+	for i, item := range items {
+		item.Location = listLocationDeployed
+		merged[i] = item
+		deployed[item.Namespace+"/"+item.Name] = i
+	}
+	selector, _ := labels.Parse(cfg.Selector)
+	for _, f := range local {
+		namespace := f.Deploy.Namespace
+		if i, ok := deployed[namespace+"/"+f.Name]; ok && namespace != "" {
+			merged[i].Location = listLocationBoth
+			merged[i].Path = f.Root
+			continue
+		}
+		if namespace != "" && cfg.Namespace != "" && namespace != cfg.Namespace {
+			continue
+		}
+		if !selector.Empty() {
+			ll, err := f.LabelsMap()
+			if err != nil || !selector.Matches(labels.Set(ll)) {
+				continue
+			}
+		}
+		merged = append(merged, fn.ListItem{
+			Name:      f.Name,
+			Namespace: namespace,
+			Runtime:   f.Runtime,
+			Image:     f.Build.Image,
+			Location:  listLocationLocal,
+			Path:      f.Root,
+		})
+	}
+	return merged
+}
+
+// Sorting
+// -------
+
+// listSortKeys by which functions may be ordered.
+var listSortKeys = []string{"name", "namespace", "runtime", "ready", "created"}
+
+// sortListItems by the key, leaving them as listed if it is empty.  Items
+// equal by the key are ordered by namespace and then name, also descending
+// if so ordered.
+func sortListItems(items []fn.ListItem, by string, descending bool) {
+	if by == "" {
+		return
+	}
+	compare := func(a, b fn.ListItem) int {
+		var c int
+		switch by {
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "namespace":
+			c = strings.Compare(a.Namespace, b.Namespace)
+		case "runtime":
+			c = strings.Compare(a.Runtime, b.Runtime)
+		case "ready":
+			c = strings.Compare(a.Ready, b.Ready)
+		case "created":
+			c = a.Created.Compare(b.Created)
+		}
+		if c == 0 {
+			c = strings.Compare(a.Namespace, b.Namespace)
+		}
+		if c == 0 {
+			c = strings.Compare(a.Name, b.Name)
+		}
+		if descending {
+			c = -c
+		}
+		return c
+	}
+	slices.SortStableFunc(items, compare)
+}
+
+// Columns
+// -------
+
+// listColumn of the human and plain output, with the value of each item
+// given the time at which the list is written.
+type listColumn struct {
+	header string
+	value  func(item fn.ListItem, now time.Time) string
+}
+
+// listColumns by name.  New fields of listed functions are exposed by adding
+// their column here, and to listColumnNames.
+var listColumns = map[string]listColumn{
+	"name":      {"NAME", func(i fn.ListItem, _ time.Time) string { return i.Name }},
+	"namespace": {"NAMESPACE", func(i fn.ListItem, _ time.Time) string { return i.Namespace }},
+	"runtime":   {"RUNTIME", func(i fn.ListItem, _ time.Time) string { return i.Runtime }},
+	"url":       {"URL", func(i fn.ListItem, _ time.Time) string { return i.URL }},
+	"ready":     {"READY", func(i fn.ListItem, _ time.Time) string { return i.Ready }},
+	"age": {"AGE", func(i fn.ListItem, now time.Time) string {
+		if i.Created.IsZero() {
+			return ""
+		}
+		return now.Sub(i.Created).Round(time.Second).String()
+	}},
+	"created": {"CREATED", func(i fn.ListItem, _ time.Time) string {
+		if i.Created.IsZero() {
+			return ""
+		}
+		return i.Created.Format(time.RFC3339)
+	}},
+	"image":    {"IMAGE", func(i fn.ListItem, _ time.Time) string { return i.Image }},
+	"revision": {"REVISION", func(i fn.ListItem, _ time.Time) string { return i.Revision }},
+	"traffic":  {"TRAFFIC", func(i fn.ListItem, _ time.Time) string { return formatTraffic(i.Traffic) }},
+	"cpu": {"CPU", func(i fn.ListItem, _ time.Time) string {
+		return formatRequestLimit(i.Provisioning.CPURequest, i.Provisioning.CPULimit)
+	}},
+	"memory": {"MEMORY", func(i fn.ListItem, _ time.Time) string {
+		return formatRequestLimit(i.Provisioning.MemoryRequest, i.Provisioning.MemoryLimit)
+	}},
+	"scale": {"SCALE", func(i fn.ListItem, _ time.Time) string { return formatScale(i.Provisioning) }},
+	"events": {"EVENTS", func(i fn.ListItem, _ time.Time) string {
+		if i.Location == listLocationLocal {
+			return "" // not deployed, and so bound to no events
+		}
+		return strconv.Itoa(len(i.Bindings))
+	}},
+	"location": {"LOCATION", func(i fn.ListItem, _ time.Time) string { return i.Location }},
+	"path":     {"PATH", func(i fn.ListItem, _ time.Time) string { return i.Path }},
+}
+
+// formatTraffic as the percentage routed to each revision, with its tag if
+// any, for example "myfunc-00002=90%,myfunc-00001=10%(canary)".
+func formatTraffic(tt []fn.TrafficTarget) string {
+	ss := make([]string, 0, len(tt))
+	for _, t := range tt {
+		s := fmt.Sprintf("%v=%v%%", t.Revision, t.Percent)
+		if t.Tag != "" {
+			s += "(" + t.Tag + ")"
+		}
+		ss = append(ss, s)
+	}
+	return strings.Join(ss, ",")
+}
+
+// formatRequestLimit of a resource as request/limit, such as "100m/500m",
+// either being "-" if not configured, or empty if neither is.
+func formatRequestLimit(request, limit string) string {
+	if request == "" && limit == "" {
+		return ""
+	}
+	if request == "" {
+		request = "-"
+	}
+	if limit == "" {
+		limit = "-"
+	}
+	return request + "/" + limit
+}
+
+// formatScale bounds as min-max, such as "1-10", an unbounded maximum being
+// "*" and the minimum by default 0.
+func formatScale(p fn.Provisioning) string {
+	if p.MinScale == nil && p.MaxScale == nil {
+		return ""
+	}
+	min, max := "0", "*"
+	if p.MinScale != nil {
+		min = strconv.FormatInt(*p.MinScale, 10)
+	}
+	if p.MaxScale != nil && *p.MaxScale > 0 {
+		max = strconv.FormatInt(*p.MaxScale, 10)
+	}
+	return min + "-" + max
+}
+
+// listColumnNames in the order in which they are suggested.
+var listColumnNames = []string{"name", "namespace", "runtime", "url", "ready", "age", "created", "image", "revision", "traffic",
+	"cpu", "memory", "scale", "events", "location", "path"}
+
+// defaultListColumns of the human and plain output.
+var defaultListColumns = []string{"name", "namespace", "runtime", "url", "ready"}
+
+// wideListColumns of the human output with --output wide.
+var wideListColumns = []string{"name", "namespace", "runtime", "url", "ready", "age", "image", "revision", "traffic",
+	"cpu", "memory", "scale"}
+
+// csvListColumns by default, of all fields, the time created being absolute.
+var csvListColumns = []string{"name", "namespace", "runtime", "url", "ready", "created", "image", "revision", "traffic",
+	"cpu", "memory", "scale"}
+
+// parseListColumns from a comma separated list of names, which may be empty
+// for those by default.
+func parseListColumns(s string) (columns []string, err error) {
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	for _, c := range strings.Split(s, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if _, ok := listColumns[c]; !ok {
+			return nil, fmt.Errorf("unsupported column %q. Accepts %v", c, strings.Join(listColumnNames, ", "))
+		}
+		columns = append(columns, c)
+	}
+	return
+}
+
 // Output Formatting (serializers)
 // -------------------------------
 
@@ -182,20 +769,83 @@ func (items listItems) Human(w io.Writer) error {
 }
 
 func (items listItems) Plain(w io.Writer) error {
+	return writeListTable(w, items, defaultListColumns)
+}
+
+// listTable of items, whose human, plain and csv output is of the given
+// columns; those of other formats are of all fields.
+type listTable struct {
+	listItems
+	columns []string
+}
+
+func (t listTable) Human(w io.Writer) error {
+	return t.Plain(w)
+}
+
+func (t listTable) Plain(w io.Writer) error {
+	columns := t.columns
+	if len(columns) == 0 {
+		columns = defaultListColumns
+	}
+	return writeListTable(w, t.listItems, columns)
+}
 
+func (t listTable) templateData() any {
+	return []fn.ListItem(t.listItems)
+}
+
+func (t listTable) CSV(w io.Writer) error {
+	columns := t.columns
+	if len(columns) == 0 {
+		columns = csvListColumns
+	}
+	return writeListCSV(w, t.listItems, columns)
+}
+
+func writeListTable(w io.Writer, items []fn.ListItem, columns []string) error {
 	// minwidth, tabwidth, padding, padchar, flags
 	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
 	defer tabWriter.Flush()
 
-	fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", "NAME", "NAMESPACE", "RUNTIME", "URL", "READY")
+	row := make([]string, len(columns))
+	for i, c := range columns {
+		row[i] = listColumns[c].header
+	}
+	fmt.Fprintln(tabWriter, strings.Join(row, "\t"))
+	now := time.Now()
 	for _, item := range items {
-		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\t%s\n", item.Name, item.Namespace, item.Runtime, item.URL, item.Ready)
+		for i, c := range columns {
+			row[i] = listColumns[c].value(item, now)
+		}
+		fmt.Fprintln(tabWriter, strings.Join(row, "\t"))
 	}
 	return nil
 }
 
+// envelope of the items as a FunctionList, whose items are empty rather
+// than null if none.
+func (items listItems) envelope() listEnvelope {
+	if items == nil {
+		items = listItems{}
+	}
+	return listEnvelope{APIVersion: OutputAPIVersion, Kind: "FunctionList", Items: []fn.ListItem(items)}
+}
+
 func (items listItems) JSON(w io.Writer) error {
-	return json.NewEncoder(w).Encode(items)
+	return json.NewEncoder(w).Encode(items.envelope())
+}
+
+// NDJSON of the items, one per line, of the fields of the items of the
+// FunctionList of the json output.
+func (items listItems) NDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (items listItems) XML(w io.Writer) error {
@@ -203,7 +853,31 @@ func (items listItems) XML(w io.Writer) error {
 }
 
 func (items listItems) YAML(w io.Writer) error {
-	return yaml.NewEncoder(w).Encode(items)
+	return yaml.NewEncoder(w).Encode(items.envelope())
+}
+
+func (items listItems) CSV(w io.Writer) error {
+	return writeListCSV(w, items, csvListColumns)
+}
+
+// writeListCSV of the items with a header row of the columns' names.
+func writeListCSV(w io.Writer, items []fn.ListItem, columns []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	now := time.Now()
+	record := make([]string, len(columns))
+	for _, item := range items {
+		for i, c := range columns {
+			record[i] = listColumns[c].value(item, now)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
 }
 
 func (items listItems) URL(w io.Writer) error {
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// listCacheDir beneath the func cache in which the functions last listed are
+// cached.
+const listCacheDir = "list"
+
+// listCache of the functions last listed of a kubeconfig context, namespace
+// (empty being all) and selector.
+type listCache struct {
+	Context   string        `json:"context"`
+	Namespace string        `json:"namespace"`
+	Selector  string        `json:"selector,omitempty"`
+	Listed    time.Time     `json:"listed"`
+	Items     []fn.ListItem `json:"items"`
+}
+
+// newListCache of the items listed of the current kubeconfig context, which
+// is empty if there is none.
+func newListCache(cfg listConfig, items []fn.ListItem) listCache {
+	context, _, _ := k8s.GetCurrentContext()
+	return listCache{
+		Context:   context,
+		Namespace: cfg.Namespace,
+		Selector:  cfg.Selector,
+		Listed:    time.Now(),
+		Items:     items,
+	}
+}
+
+// path of the cache, by a digest of its context, namespace and selector, any
+// of which may contain characters not allowed in a file name.
+func (c listCache) path() string {
+	sum := sha256.Sum256([]byte(c.Context + "\x00" + c.Namespace + "\x00" + c.Selector))
+	return filepath.Join(config.CacheDir(), listCacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// writeListCache of the items listed.
+func writeListCache(cfg listConfig, items []fn.ListItem) error {
+	c := newListCache(cfg, items)
+	if err := os.MkdirAll(filepath.Dir(c.path()), 0o700); err != nil {
+		return err
+	}
+	bb, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	// Written by rename, such that a concurrent list never reads a partial
+	// cache.
+	tmp := c.path() + ".tmp"
+	if err = os.WriteFile(tmp, bb, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path())
+}
+
+// readListCache of the functions last listed of the current kubeconfig
+// context, namespace and selector.
+func readListCache(cfg listConfig) (c listCache, err error) {
+	bb, err := os.ReadFile(newListCache(cfg, nil).path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("no functions have been cached of this context and namespace. Enable caching with --cache")
+		}
+		return
+	}
+	err = json.Unmarshal(bb, &c)
+	return
+}
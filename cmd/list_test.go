@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
@@ -62,7 +69,7 @@ func TestList_Namespace(t *testing.T) {
 			// create a mock lister implementation which validates the expected
 			// value has been passed.
 			lister := mock.NewLister()
-			lister.ListFn = func(_ context.Context, namespace string) ([]fn.ListItem, error) {
+			lister.ListFn = func(_ context.Context, namespace, _ string) ([]fn.ListItem, error) {
 				if namespace != test.expected {
 					t.Fatalf("expected list namespace %q, got %q", test.expected, namespace)
 				}
@@ -107,3 +114,531 @@ func TestList_Namespace(t *testing.T) {
 		})
 	}
 }
+
+// TestList_Watch ensures that with --watch the list is written anew each time
+// it changes, until the lister's watch ends.
+func TestList_Watch(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.WatchFn = func(ctx context.Context, ns, _ string) (<-chan []fn.ListItem, error) {
+		ch := make(chan []fn.ListItem, 2)
+		ch <- []fn.ListItem{{Name: "first", Namespace: ns, Ready: "False"}}
+		ch <- []fn.ListItem{{Name: "first", Namespace: ns, Ready: "True"}, {Name: "second", Namespace: ns}}
+		close(ch)
+		return ch, nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--watch", "--namespace", "test-ns", "--output", "plain"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !lister.WatchInvoked {
+		t.Fatal("the lister was not watched")
+	}
+	if lister.ListInvoked {
+		t.Error("expected only the watch of the lister to be used")
+	}
+	if n := strings.Count(out.String(), "first"); n != 2 {
+		t.Errorf("expected the list to be written twice, got:\n%v", out.String())
+	}
+	if !strings.Contains(out.String(), "second") {
+		t.Errorf("expected the second function in the updated list, got:\n%v", out.String())
+	}
+}
+
+// TestList_Selector ensures that the label selector is passed to the lister,
+// and that invalid selectors are rejected before listing.
+func TestList_Selector(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	var selector string
+	lister := mock.NewLister()
+	lister.ListFn = func(_ context.Context, _, s string) ([]fn.ListItem, error) {
+		selector = s
+		return []fn.ListItem{}, nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"-l", "team=payments,env!=prod"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if selector != "team=payments,env!=prod" {
+		t.Errorf("expected the selector to be passed to the lister, got %q", selector)
+	}
+
+	lister = mock.NewLister()
+	cmd = NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--selector", "team in (payments"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid selector")
+	}
+	if lister.ListInvoked {
+		t.Error("expected the lister not to be invoked with an invalid selector")
+	}
+}
+
+// TestList_SortBy ensures that functions are ordered by the given key,
+// ascending or descending, with those equal by it ordered by namespace and
+// name.
+func TestList_SortBy(t *testing.T) {
+	now := time.Now()
+	items := []fn.ListItem{
+		{Name: "b", Namespace: "ns2", Runtime: "go", Created: now.Add(-time.Hour)},
+		{Name: "c", Namespace: "ns1", Runtime: "node", Created: now},
+		{Name: "a", Namespace: "ns2", Runtime: "go", Created: now.Add(-2 * time.Hour)},
+	}
+	names := func(items []fn.ListItem) (s string) {
+		for _, item := range items {
+			s += item.Name
+		}
+		return
+	}
+	tests := []struct {
+		by         string
+		descending bool
+		want       string
+	}{
+		{"", false, "bca"},
+		{"name", false, "abc"},
+		{"name", true, "cba"},
+		{"namespace", false, "cab"},
+		{"runtime", false, "abc"},
+		{"created", false, "abc"},
+		{"created", true, "cba"},
+	}
+	for _, test := range tests {
+		ii := append([]fn.ListItem{}, items...)
+		sortListItems(ii, test.by, test.descending)
+		if got := names(ii); got != test.want {
+			t.Errorf("sort by %q (descending %v): expected %v, got %v", test.by, test.descending, test.want, got)
+		}
+	}
+
+	cmd := NewListCmd(NewTestClient())
+	cmd.SetArgs([]string{"--sort-by", "url"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error sorting by an unsupported key")
+	}
+}
+
+// TestList_Columns ensures that the human output is of the columns given, in
+// order, and that unsupported columns are rejected.
+func TestList_Columns(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	items := listTable{
+		listItems: []fn.ListItem{{Name: "myfunc", Namespace: "ns", URL: "http://myfunc.ns", Image: "example.com/myfunc@sha256:abc"}},
+		columns:   []string{"name", "url", "image"},
+	}
+	out := bytes.Buffer{}
+	if err := items.Human(&out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "NAME URL IMAGE" {
+		t.Errorf("expected the header of the columns given, got %q", lines[0])
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "myfunc http://myfunc.ns example.com/myfunc@sha256:abc" {
+		t.Errorf("expected the values of the columns given, got %q", lines[1])
+	}
+
+	out.Reset()
+	items.columns = nil
+	if err := items.Plain(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "NAME") || !strings.Contains(out.String(), "READY") || strings.Contains(out.String(), "IMAGE") || strings.Contains(out.String(), "AGE") {
+		t.Errorf("expected the default columns, got %q", out.String())
+	}
+
+	cmd := NewListCmd(NewTestClient())
+	cmd.SetArgs([]string{"--columns", "name,colour"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an unsupported column")
+	}
+}
+
+// TestList_CSV ensures that the CSV output is of all fields by default, and
+// of the columns given otherwise, with values quoted as necessary.
+func TestList_CSV(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	items := []fn.ListItem{{Name: "myfunc", Namespace: "ns", Ready: "True", URL: "http://myfunc.ns", Created: created, Image: "example.com/my,func"}}
+
+	out := bytes.Buffer{}
+	write(&out, listTable{listItems: items}, "csv")
+	want := "name,namespace,runtime,url,ready,created,image,revision,traffic,cpu,memory,scale\n" +
+		"myfunc,ns,,http://myfunc.ns,True,2024-01-02T03:04:05Z,\"example.com/my,func\",,,,,\n"
+	if out.String() != want {
+		t.Errorf("expected:\n%v\ngot:\n%v", want, out.String())
+	}
+
+	out.Reset()
+	write(&out, listTable{listItems: items, columns: []string{"name", "url"}}, "csv")
+	if want := "name,url\nmyfunc,http://myfunc.ns\n"; out.String() != want {
+		t.Errorf("expected:\n%v\ngot:\n%v", want, out.String())
+	}
+}
+
+// TestList_Wide ensures that the wide output includes the image, revision,
+// traffic and provisioning of each function.
+func TestList_Wide(t *testing.T) {
+	_ = FromTempDirectory(t)
+	minScale, maxScale := int64(1), int64(10)
+
+	lister := mock.NewLister()
+	lister.ListFn = func(_ context.Context, ns, _ string) ([]fn.ListItem, error) {
+		return []fn.ListItem{{Name: "myfunc", Namespace: ns, Image: "example.com/myfunc@sha256:abc", Revision: "myfunc-00002",
+			Traffic:      []fn.TrafficTarget{{Revision: "myfunc-00002", Percent: 90}, {Revision: "myfunc-00001", Percent: 10, Tag: "canary"}},
+			Provisioning: fn.Provisioning{CPURequest: "100m", CPULimit: "500m", MinScale: &minScale, MaxScale: &maxScale}}}, nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", "wide"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"IMAGE", "REVISION", "AGE", "TRAFFIC", "example.com/myfunc@sha256:abc", "myfunc-00002=90%,myfunc-00001=10%(canary)",
+		"CPU", "100m/500m", "SCALE", "1-10"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("expected %q in the wide output, got:\n%v", s, out.String())
+		}
+	}
+}
+
+// TestList_Events ensures that the number of event bindings of each function
+// is listed with --events.
+func TestList_Events(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.ListFn = func(_ context.Context, ns, _ string) ([]fn.ListItem, error) {
+		return []fn.ListItem{
+			{Name: "consumer", Namespace: ns, Bindings: []fn.EventBinding{
+				{Kind: "Trigger", Name: "a", From: "Broker/default"},
+				{Kind: "Subscription", Name: "b", From: "InMemoryChannel/orders"},
+			}},
+			{Name: "api", Namespace: ns},
+		}, nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--events", "--sort-by", "name", "--output", "plain"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "EVENTS") ||
+		!strings.HasSuffix(lines[1], " 0") || !strings.HasSuffix(lines[2], " 2") {
+		t.Errorf("expected api with no bindings and consumer with two, got:\n%v", out.String())
+	}
+}
+
+// TestList_GoTemplate ensures that the output of a go-template is executed
+// with the list of functions, and that invalid templates are rejected.
+func TestList_GoTemplate(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.ListFn = func(context.Context, string, string) ([]fn.ListItem, error) {
+		return []fn.ListItem{{Name: "a", URL: "http://a"}, {Name: "b", URL: "http://b"}}, nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", `go-template={{range .}}{{.Name}} {{.URL}}{{"\n"}}{{end}}`})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a http://a\nb http://b\n"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+
+	cmd = NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", "go-template={{range .}"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid template")
+	}
+}
+
+// TestList_Cached ensures that the functions listed with --cache are listed
+// with --cached once the cluster is unreachable, noting when they were cached,
+// and that without a cache the error connecting is returned.
+func TestList_Cached(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.ListFn = func(_ context.Context, ns, _ string) ([]fn.ListItem, error) {
+		return []fn.ListItem{{Name: "myfunc", Namespace: ns}}, nil
+	}
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--cache", "--output", "plain"})
+	cmd.SetOut(&bytes.Buffer{})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	unreachable := mock.NewLister()
+	unreachable.ListFn = func(context.Context, string, string) ([]fn.ListItem, error) {
+		return nil, errors.New("connection refused")
+	}
+	cmd = NewListCmd(NewTestClient(fn.WithLister(unreachable)))
+	cmd.SetArgs([]string{"--cached", "--output", "plain"})
+	out, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&stderr)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "myfunc") {
+		t.Errorf("expected the cached function, got:\n%v", out.String())
+	}
+	if !strings.Contains(stderr.String(), "Listing the functions cached") {
+		t.Errorf("expected the time cached on stderr, got %q", stderr.String())
+	}
+
+	// Those of another namespace have not been cached.
+	cmd = NewListCmd(NewTestClient(fn.WithLister(unreachable)))
+	cmd.SetArgs([]string{"--cached", "--namespace", "other"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	if err := cmd.Execute(); ErrorCodeOf(err) != ErrCodeNoCluster {
+		t.Fatalf("expected the cluster to be unreachable, got %v", err)
+	}
+}
+
+// TestList_JSONPath ensures that a JSONPath expression is evaluated against
+// the json output of the list, with or without its braces, and that invalid
+// expressions are rejected.
+func TestList_JSONPath(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.ListFn = func(context.Context, string, string) ([]fn.ListItem, error) {
+		return []fn.ListItem{{Name: "a", URL: "http://a"}, {Name: "b", URL: "http://b"}}, nil
+	}
+
+	for _, expression := range []string{"{.items[*].url}", ".items[*].url", "items[*].url"} {
+		cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+		cmd.SetArgs([]string{"--output", "jsonpath=" + expression})
+		out := bytes.Buffer{}
+		cmd.SetOut(&out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if want := "http://a http://b"; out.String() != want {
+			t.Errorf("expected %q of %v, got %q", want, expression, out.String())
+		}
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", "jsonpath={.items[*}"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid expression")
+	}
+}
+
+// TestList_Local ensures that functions initialized locally are listed with
+// those deployed, each marked as local, deployed or both.
+func TestList_Local(t *testing.T) {
+	root := FromTempDirectory(t)
+
+	deployed, err := fn.New().Init(fn.Function{Root: filepath.Join(root, "deployed"), Name: "deployed", Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployed.Deploy.Namespace = "ns"
+	if err = deployed.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fn.New().Init(fn.Function{Root: filepath.Join(root, "ws", "undeployed"), Name: "undeployed", Runtime: "python"}); err != nil {
+		t.Fatal(err)
+	}
+
+	lister := mock.NewLister()
+	lister.ListFn = func(_ context.Context, ns, _ string) ([]fn.ListItem, error) {
+		return []fn.ListItem{{Name: "deployed", Namespace: ns}, {Name: "remote", Namespace: ns}}, nil
+	}
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--local", "--namespace", "ns", "--sort-by", "name", "--output", "json"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var list struct{ Items []fn.ListItem }
+	if err = json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	items := list.Items
+	got := map[string]string{}
+	for _, item := range items {
+		got[item.Name] = item.Location
+	}
+	want := map[string]string{"deployed": "both", "remote": "deployed", "undeployed": "local"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for name, location := range want {
+		if got[name] != location {
+			t.Errorf("expected %v to be %v, got %q", name, location, got[name])
+		}
+	}
+	if items[0].Path != deployed.Root {
+		t.Errorf("expected the path of the local source, got %q", items[0].Path)
+	}
+}
+
+// TestList_Limit ensures that a page of functions is listed with --limit and
+// --continue, with the command to list the next page printed to stderr.
+func TestList_Limit(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.ListPageFn = func(_ context.Context, _, _ string, limit int64, token string) ([]fn.ListItem, string, error) {
+		if limit != 1 || token != "page-2" {
+			t.Errorf("expected limit 1 from page-2, got %v from %q", limit, token)
+		}
+		return []fn.ListItem{{Name: "b"}}, "page-3", nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--limit", "1", "--continue", "page-2", "--output", "plain"})
+	out, stderr := bytes.Buffer{}, bytes.Buffer{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&stderr)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if lister.ListInvoked || !lister.ListPageInvoked {
+		t.Fatal("expected a page of functions to be listed")
+	}
+	if !strings.Contains(out.String(), "b") {
+		t.Errorf("expected function b, got:\n%v", out.String())
+	}
+	if !strings.Contains(stderr.String(), "--continue page-3") {
+		t.Errorf("expected the token of the next page, got %q", stderr.String())
+	}
+
+	cmd = NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--limit", "1", "--watch"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error for --limit with --watch")
+	}
+}
+
+// TestList_Versioned ensures that the json and yaml output is of a versioned
+// envelope, that the version may be given, and that unsupported versions are
+// rejected.
+func TestList_Versioned(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	lister.ListFn = func(_ context.Context, ns, _ string) ([]fn.ListItem, error) {
+		return []fn.ListItem{{Name: "myfunc", Namespace: ns}}, nil
+	}
+
+	for _, output := range []string{"json", "json=v1"} {
+		cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+		cmd.SetArgs([]string{"--namespace", "ns", "--output", output})
+		out := bytes.Buffer{}
+		cmd.SetOut(&out)
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		var list struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Items      []fn.ListItem
+		}
+		if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.APIVersion != "func.knative.dev/v1" || list.Kind != "FunctionList" || len(list.Items) != 1 {
+			t.Errorf("unexpected %v output %v", output, out.String())
+		}
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", "yaml=v1"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "apiVersion: func.knative.dev/v1\nkind: FunctionList\nitems:\n") {
+		t.Errorf("unexpected yaml output %v", out.String())
+	}
+
+	for _, output := range []string{"json=v2", "plain=v1"} {
+		cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+		cmd.SetArgs([]string{"--output", output})
+		if err := cmd.Execute(); err == nil {
+			t.Errorf("expected an error for output %v", output)
+		}
+	}
+}
+
+// TestList_NDJSON ensures that the functions are streamed as a line of JSON
+// each, page by page, and that with --watch only those which changed are
+// written anew.
+func TestList_NDJSON(t *testing.T) {
+	_ = FromTempDirectory(t)
+
+	lister := mock.NewLister()
+	var tokens []string
+	lister.ListPageFn = func(_ context.Context, _, _ string, _ int64, token string) ([]fn.ListItem, string, error) {
+		tokens = append(tokens, token)
+		if token == "" {
+			return []fn.ListItem{{Name: "a"}, {Name: "b"}}, "page-2", nil
+		}
+		return []fn.ListItem{{Name: "c"}}, "", nil
+	}
+
+	cmd := NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", "ndjson"})
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", "page-2"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("expected the pages of tokens %q, got %q", want, tokens)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a line per function, got:\n%v", out.String())
+	}
+	var item fn.ListItem
+	if err := json.Unmarshal([]byte(lines[2]), &item); err != nil || item.Name != "c" {
+		t.Errorf("expected function c, got %q (%v)", lines[2], err)
+	}
+
+	lister.WatchFn = func(context.Context, string, string) (<-chan []fn.ListItem, error) {
+		ch := make(chan []fn.ListItem, 2)
+		ch <- []fn.ListItem{{Name: "a", Ready: "False"}, {Name: "b"}}
+		ch <- []fn.ListItem{{Name: "a", Ready: "True"}, {Name: "b"}}
+		close(ch)
+		return ch, nil
+	}
+	cmd = NewListCmd(NewTestClient(fn.WithLister(lister)))
+	cmd.SetArgs([]string{"--output", "ndjson", "--watch"})
+	out.Reset()
+	cmd.SetOut(&out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(out.String(), "\n"); n != 3 {
+		t.Errorf("expected both functions and then the one changed, got:\n%v", out.String())
+	}
+}
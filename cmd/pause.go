@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+)
+
+func NewPauseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pause <name>",
+		Short: "Take a deployed function offline until resumed",
+		Long: `
+NAME
+	{{rootCmdUse}} pause - Take a deployed function offline until resumed
+
+SYNOPSIS
+	{{rootCmdUse}} pause [name] [-n|--namespace] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Takes a deployed function offline temporarily, such as while responding
+	to an incident or to reduce costs, without deleting it.  The function is
+	no longer routed from outside the cluster, a network policy denies all
+	ingress to its pods, such that requests from within the cluster fail,
+	and its minimum scale is removed such that it scales to zero once idle.
+
+	The settings the function had are recorded in an annotation of its
+	service, and are restored by '{{rootCmdUse}} resume', which deletes the
+	network policy.  Deploying the function also brings it back online, with
+	the settings of its func.yaml.
+
+	By default the function of the project in the current directory is
+	paused.  Alternatively the name of a deployed function may be given,
+	with its --namespace.
+`,
+		Example: `
+# Pause the function in the current directory
+{{rootCmdUse}} pause
+
+# Pause the function 'myfn' in namespace 'prod'
+{{rootCmdUse}} pause myfn -n prod
+`,
+		ValidArgsFunction: CompleteFunctionList,
+		Args:              cobra.MaximumNArgs(1),
+		PreRunE:           bindEnv("namespace", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, namespace, err := pausedFunction(cmd, args)
+			if err != nil {
+				return err
+			}
+			if err = knative.Pause(cmd.Context(), name, namespace); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Paused %v in namespace %q\n", name, namespace)
+			return nil
+		},
+	}
+	addPausedFunctionFlags(cmd, "pausing")
+	return cmd
+}
+
+func NewResumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume <name>",
+		Short: "Bring a paused function back online",
+		Long: `Bring a paused function back online
+
+Restores the settings a function had before it was paused with
+'{{rootCmdUse}} pause': its visibility outside the cluster and its minimum
+scale, and admits requests to it from within the cluster again.  See
+'{{rootCmdUse}} pause --help' for details.
+`,
+		Example: `
+# Resume the function in the current directory
+{{rootCmdUse}} resume
+
+# Resume the function 'myfn' in namespace 'prod'
+{{rootCmdUse}} resume myfn -n prod
+`,
+		ValidArgsFunction: CompleteFunctionList,
+		Args:              cobra.MaximumNArgs(1),
+		PreRunE:           bindEnv("namespace", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, namespace, err := pausedFunction(cmd, args)
+			if err != nil {
+				return err
+			}
+			if err = knative.Resume(cmd.Context(), name, namespace); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Resumed %v in namespace %q\n", name, namespace)
+			return nil
+		},
+	}
+	addPausedFunctionFlags(cmd, "resuming")
+	return cmd
+}
+
+func addPausedFunctionFlags(cmd *cobra.Command, verb string) {
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), fmt.Sprintf("The namespace when %v by name. ($FUNC_NAMESPACE)", verb))
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+}
+
+// pausedFunction returns the name and namespace of the function to pause or
+// resume: that named, or else that of the project at --path.
+func pausedFunction(cmd *cobra.Command, args []string) (name, namespace string, err error) {
+	if len(args) > 0 {
+		if cmd.Flags().Changed("path") {
+			return "", "", errors.New("only one of --path and [NAME] should be provided")
+		}
+		return args[0], viper.GetString("namespace"), nil
+	}
+	if cmd.Flags().Changed("namespace") {
+		return "", "", errors.New("must also specify a name when specifying namespace")
+	}
+	f, err := fn.NewFunction(viper.GetString("path"))
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return "", "", formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	if f.Deploy.Namespace == "" {
+		return "", "", ErrNotDeployed
+	}
+	return f.Name, f.Deploy.Namespace, nil
+}
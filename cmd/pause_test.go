@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestPause_NotDeployed ensures that pausing or resuming the function of the
+// current directory requires it to have been deployed.
+func TestPause_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPauseCmd().Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed pausing, got %v", err)
+	}
+	if err := NewResumeCmd().Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed resuming, got %v", err)
+	}
+}
+
+// TestPause_NamespaceWithoutName ensures that a namespace is only accepted
+// with the name of the function.
+func TestPause_NamespaceWithoutName(t *testing.T) {
+	_ = FromTempDirectory(t)
+	cmd := NewPauseCmd()
+	cmd.SetArgs([]string{"--namespace", "prod"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "must also specify a name") {
+		t.Fatalf("expected an error specifying only a namespace, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+	"knative.dev/func/pkg/perf"
+)
+
+// ErrNotDeployed indicates that a command which requires a deployed function
+// was invoked for a function which has not been deployed.
+var ErrNotDeployed = errors.New("function has not been deployed. Deploy it with 'func deploy'")
+
+func NewPerfCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "perf",
+		Short: "Analyze the performance of a deployed function",
+		Long: `
+NAME
+	{{rootCmdUse}} perf - Analyze the performance of a deployed function
+
+SYNOPSIS
+	{{rootCmdUse}} perf coldstart [--count] [--timeout] [--scale-down-window]
+	             [-i|--insecure] [-o|--output] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Measures aspects of the performance of a function as deployed to the
+	cluster, reporting where time is spent and suggesting improvements.
+
+	Cold Starts
+	  'coldstart' waits for the function to scale to zero, then invokes it,
+	  timing the request from being sent until the first byte of the response
+	  is received.  This is repeated --count times, and the time of each is
+	  broken down into its phases using the times reported by the cluster for
+	  the pod which served the request:
+
+	    activator        until the pod is created, the request being held by
+	                     the activator while the function scales from zero
+	    container-start  until the function's container starts, including
+	                     scheduling and pulling its image
+	    readiness        until the container is ready, as the function
+	                     initializes
+	    first-byte       until the first byte of the response is received
+
+	  Waiting for the function to scale to zero is governed by the cluster's
+	  autoscaler, and typically takes a minute or more after the last request
+	  to the function; each wait is bounded by --timeout.  To wait less,
+	  --scale-down-window temporarily sets the function's autoscaling window
+	  (at least 6s) and retains none of its pods once scaled to zero, such
+	  that it scales to zero the window after its last request plus the
+	  cluster's scale-to-zero grace period (30s by default).  This deploys a
+	  new revision of the function, and another restoring its settings once
+	  measured.  The function should not receive other traffic while being
+	  measured.  A function whose minimum scale is greater than zero
+	  (options.scale.min) never scales to zero, and so is not measured.
+`,
+		Example: `
+# Measure three cold starts of the function in the current directory
+{{rootCmdUse}} perf coldstart
+
+# Measure five cold starts, reporting as JSON
+{{rootCmdUse}} perf coldstart --count 5 --output json
+
+# Measure cold starts without waiting for the cluster's autoscaling window
+{{rootCmdUse}} perf coldstart --scale-down-window 6s
+`,
+	}
+
+	cmd.AddCommand(NewPerfColdStartCmd(newClient))
+
+	return cmd
+}
+
+func NewPerfColdStartCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coldstart",
+		Short: "Measure the cold starts of a deployed function",
+		Long: `Measure the cold starts of a deployed function
+
+Waits for the function to scale to zero and times an invocation, repeatedly,
+reporting the time spent in each phase of the cold start, and suggesting how
+it might be reduced.  See '{{rootCmdUse}} perf --help' for details.
+`,
+		Aliases: []string{"cold-start"},
+		PreRunE: bindEnv("count", "timeout", "scale-down-window", "insecure", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPerfColdStart(cmd, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().Int("count", 3, "Number of cold starts to measure. ($FUNC_COUNT)")
+	cmd.Flags().Duration("timeout", 5*time.Minute, "Maximum time to wait for the function to scale to zero before each measurement. ($FUNC_TIMEOUT)")
+	cmd.Flags().Duration("scale-down-window", 0, "Temporarily set the function's autoscaling window, from 6s to 1h, such that it scales to zero sooner. The cluster's autoscaler governs scaling to zero if not set. ($FUNC_SCALE_DOWN_WINDOW)")
+	cmd.Flags().BoolP("insecure", "i", false, "Allow insecure server connections when using SSL. ($FUNC_INSECURE)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func runPerfColdStart(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	var (
+		count    = viper.GetInt("count")
+		timeout  = viper.GetDuration("timeout")
+		window   = viper.GetDuration("scale-down-window")
+		insecure = viper.GetBool("insecure")
+		output   = viper.GetString("output")
+		verbose  = viper.GetBool("verbose")
+	)
+	if count < 1 {
+		return fmt.Errorf("--count must be at least 1, got %v", count)
+	}
+	if window != 0 && (window < 6*time.Second || window > time.Hour) {
+		return fmt.Errorf("--scale-down-window must be from 6s to 1h, got %v", window)
+	}
+	if output != "human" && output != "json" {
+		return fmt.Errorf("unsupported output format %q. Accepts 'human' or 'json'", output)
+	}
+
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	if f.Deploy.Namespace == "" {
+		return ErrNotDeployed
+	}
+
+	client, done := newClient(ClientConfig{Verbose: verbose})
+	defer done()
+
+	instance, err := client.Describe(cmd.Context(), "", "", f)
+	if err != nil {
+		return
+	}
+	if instance.Route == "" {
+		return fmt.Errorf("no route found for function %v", f.Name)
+	}
+
+	// Progress is written to stderr, such that the report alone may be
+	// redirected.
+	progress := cmd.ErrOrStderr()
+	if window != 0 {
+		fmt.Fprintf(progress, "Setting the autoscaling window of %v to %v\n", f.Name, window)
+		restore, err := knative.ScaleDownWindow(cmd.Context(), f.Name, f.Deploy.Namespace, window)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			fmt.Fprintf(progress, "Restoring the autoscaling window of %v\n", f.Name)
+			// Restored even when interrupted
+			if rerr := restore(context.WithoutCancel(cmd.Context())); rerr != nil {
+				err = errors.Join(err, rerr)
+			}
+		}()
+	}
+	var samples []perf.Sample
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(progress, "Cold start %v of %v: waiting for %v to scale to zero\n", i, count, f.Name)
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		err = knative.WaitForScaleToZero(ctx, f.Name, f.Deploy.Namespace)
+		cancel()
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(progress, "Cold start %v of %v: invoking %v\n", i, count, instance.Route)
+		s, err := knative.ColdStart(cmd.Context(), f.Name, f.Deploy.Namespace, instance.Route, insecure)
+		if err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Fprintf(progress, "Cold start %v of %v: %v in %v (pod %v)\n", i, count,
+				s.StatusCode, s.FirstByte.Sub(s.Requested).Round(time.Millisecond), s.Pod)
+		}
+		samples = append(samples, s)
+	}
+
+	// The compressed image size informs suggestions, but is optional.
+	var size int64
+	if f.Deploy.Image != "" {
+		if size, err = perf.ImageSize(cmd.Context(), f.Deploy.Image); err != nil && verbose {
+			fmt.Fprintf(progress, "Unable to determine the size of image %v: %v\n", f.Deploy.Image, err)
+		}
+	}
+
+	report := perf.NewReport(f, samples, size)
+	if output == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	writeColdStartReport(cmd.OutOrStdout(), report)
+	return nil
+}
+
+// writeColdStartReport in human-readable form.
+func writeColdStartReport(w io.Writer, r perf.Report) {
+	fmt.Fprintf(w, "Cold starts of %v (%v samples)\n\n", r.Function, len(r.Samples))
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PHASE\tMIN\tMEDIAN\tMAX")
+	for _, s := range r.Stats {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", s.Phase,
+			s.Min.Round(time.Millisecond), s.Median.Round(time.Millisecond), s.Max.Round(time.Millisecond))
+	}
+	tw.Flush()
+	if len(r.Suggestions) > 0 {
+		fmt.Fprintln(w, "\nSuggestions:")
+		for _, s := range r.Suggestions {
+			fmt.Fprintf(w, "  o %v\n", s)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestPerfColdStart_NotDeployed ensures that measuring the cold starts of a
+// function which has not been deployed fails without attempting to contact
+// the cluster.
+func TestPerfColdStart_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewPerfCmd(NewTestClient())
+	cmd.SetArgs([]string{"coldstart"})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+
+	cmd = NewPerfCmd(NewTestClient())
+	cmd.SetArgs([]string{"coldstart", "--count=0"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an invalid count to be rejected")
+	}
+
+	for _, window := range []string{"1s", "2h"} {
+		cmd = NewPerfCmd(NewTestClient())
+		cmd.SetArgs([]string{"coldstart", "--scale-down-window", window})
+		if err := cmd.Execute(); err == nil || errors.Is(err, ErrNotDeployed) {
+			t.Fatalf("expected a scale down window of %v to be rejected, got %v", window, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/knative"
+)
+
+func NewPromoteCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "promote",
+		Short: "Promote a function's candidate revision, or its image to another namespace",
+		Long: `
+NAME
+	{{rootCmdUse}} promote - Promote a function's candidate revision, or its image to another namespace
+
+SYNOPSIS
+	{{rootCmdUse}} promote [--to] [--from] [--to-context] [--from-context]
+	              [--profile] [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Without --to, routes all of the traffic of the deployed function to its
+	candidate revision: that last deployed with the blue-green strategy
+	('{{rootCmdUse}} deploy --strategy blue-green'), which is routed none of
+	the traffic until promoted.  The candidate's tag is removed, such that
+	the next blue-green deployment is the candidate in its stead.
+
+	With --to, deploys exactly the image of the function deployed in one namespace, by
+	its digest, to another, such as from staging to production.  The function
+	is not built, such that the image deployed to each namespace is that
+	built once and tested in the first.
+
+	By default the image is that deployed in the function's namespace.  Use
+	--from to promote that of another namespace.  The function remains
+	deployed in the namespace from which it is promoted, and func.yaml is not
+	changed.
+
+	The namespaces may be of different clusters: --from-context and
+	--to-context name the kubeconfig contexts of the clusters from and to
+	which the function is promoted, each defaulting to the current context.
+	With --to-context, --to defaults to the namespace promoted from.
+
+	The configuration of the function may differ between namespaces by way of
+	profiles: overlays of func.yaml named for the profile, such as
+	func.prod.yaml, which replace the values they set.  The profile applied is
+	that given with --profile, or else that named for the target namespace if
+	it exists.
+`,
+		Example: `
+# Route all of the traffic of the function in the current directory to its
+# candidate revision
+{{rootCmdUse}} promote
+
+# Promote the function in the current directory from its namespace to 'prod'
+{{rootCmdUse}} promote --to prod
+
+# Promote from 'staging' to 'prod', applying the profile in func.production.yaml
+{{rootCmdUse}} promote --from staging --to prod --profile production
+
+# Promote from the 'apps' namespace of the staging cluster to that of the
+# production cluster
+{{rootCmdUse}} promote --from apps --from-context staging --to-context production
+`,
+		PreRunE: bindEnv("to", "from", "to-context", "from-context", "profile", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPromote(cmd, newClient)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().String("to", "", "Namespace to which the function is promoted. Default is to promote its candidate revision. ($FUNC_TO)")
+	cmd.Flags().String("from", "", "Namespace from which the function's image is promoted. Default is the namespace of the deployed function. ($FUNC_FROM)")
+	cmd.Flags().String("to-context", "", "Kubeconfig context of the cluster to which the function is promoted. Default is the current context. ($FUNC_TO_CONTEXT)")
+	cmd.Flags().String("from-context", "", "Kubeconfig context of the cluster from which the function's image is promoted. Default is the current context. ($FUNC_FROM_CONTEXT)")
+	cmd.Flags().String("profile", "", "Profile applied to the function when promoted. Default is that named for the target namespace, if it exists. ($FUNC_PROFILE)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func runPromote(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	var (
+		to          = viper.GetString("to")
+		from        = viper.GetString("from")
+		toContext   = viper.GetString("to-context")
+		fromContext = viper.GetString("from-context")
+		profile     = viper.GetString("profile")
+	)
+	f, err := fn.NewFunction(viper.GetString("path"))
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	if to == "" && toContext == "" {
+		return runPromoteCandidate(cmd, f, from, fromContext, profile)
+	}
+	if from == "" {
+		if f.Deploy.Namespace == "" {
+			return ErrNotDeployed
+		}
+		from = f.Deploy.Namespace
+	}
+	if to == "" {
+		to = from
+	}
+	if from == to && fromContext == toContext {
+		return fmt.Errorf("cannot promote %v from namespace %q to itself. Promote to another namespace (--to) or cluster (--to-context)", f.Name, from)
+	}
+	var (
+		toCtx   = k8s.WithContext(cmd.Context(), toContext)
+		fromCtx = k8s.WithContext(cmd.Context(), fromContext)
+	)
+	for _, ctx := range []context.Context{toCtx, fromCtx} {
+		if k8s.ContextFrom(ctx) == "" {
+			continue
+		}
+		if _, _, err = k8s.GetCurrentContextFrom(ctx); err != nil {
+			return
+		}
+	}
+	if profile == "" && f.HasProfile(to) {
+		profile = to
+	}
+	if profile != "" {
+		if f, err = f.WithProfile(profile); err != nil {
+			return
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Applying profile %v from %v\n", profile, fn.ProfileFile(profile))
+	}
+
+	client, done := newClient(ClientConfig{Verbose: viper.GetBool("verbose"), Deployer: f.Deploy.Deployer})
+	defer done()
+
+	var oo []fn.PromoteOption
+	if fromContext != toContext {
+		oo = append(oo, fn.WithPromoteSource(fromCtx))
+	}
+	_, err = client.Promote(toCtx, f, from, to, oo...)
+	return
+}
+
+// runPromoteCandidate routes all of the traffic of the deployed function to
+// its candidate revision.
+func runPromoteCandidate(cmd *cobra.Command, f fn.Function, from, fromContext, profile string) error {
+	if from != "" || fromContext != "" || profile != "" {
+		return errors.New("--from, --from-context and --profile are of promoting to a namespace, and require --to or --to-context")
+	}
+	if f.Deploy.Namespace == "" {
+		return ErrNotDeployed
+	}
+	revision, err := knative.PromoteCandidate(cmd.Context(), f.Name, f.Deploy.Namespace)
+	if errors.Is(err, knative.ErrNoCandidate) {
+		return fmt.Errorf("%w. Deploy one with '%v deploy --strategy blue-green', or promote to a namespace with --to", err, cmd.Root().Name())
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Promoted %v revision %v, routed all of its traffic\n", f.Name, revision)
+	return nil
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestPromote ensures that the image deployed in the function's namespace is
+// deployed to the target with the target's profile applied, leaving
+// func.yaml unchanged.
+func TestPromote(t *testing.T) {
+	const digest = "example.com/alice/myfunc@sha256:aaaa"
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Name: "myfunc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "staging"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	overlay := "run:\n  envs:\n  - name: ENV\n    value: prod\n"
+	if err = os.WriteFile(filepath.Join(root, "func.prod.yaml"), []byte(overlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	describer := mock.NewDescriber()
+	describer.DescribeFn = func(_ context.Context, name, namespace string) (fn.Instance, error) {
+		return fn.Instance{Name: name, Namespace: namespace, Image: digest}, nil
+	}
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if f.Namespace != "prod" || f.Deploy.Image != digest {
+			t.Errorf("expected %v deployed to prod, got %v to %q", digest, f.Deploy.Image, f.Namespace)
+		}
+		if len(f.Run.Envs) != 1 || *f.Run.Envs[0].Value != "prod" {
+			t.Errorf("expected the prod profile applied, got envs %v", f.Run.Envs)
+		}
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: f.Namespace}, nil
+	}
+
+	cmd := NewPromoteCmd(NewTestClient(fn.WithDescriber(describer), fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--to", "prod"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !deployer.DeployInvoked {
+		t.Fatal("expected the function deployed")
+	}
+
+	f, err = fn.NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Deploy.Namespace != "staging" {
+		t.Errorf("expected func.yaml unchanged, got namespace %q", f.Deploy.Namespace)
+	}
+}
+
+// TestPromote_Contexts ensures that the image is described in the cluster
+// of the context promoted from, and deployed to that of the context promoted
+// to, the namespace defaulting to that promoted from.
+func TestPromote_Contexts(t *testing.T) {
+	const (
+		digest      = "example.com/alice/myfunc@sha256:aaaa"
+		fromContext = "default/cluster-example-com:6443/kube:admin"
+		toContext   = "func/cluster-example-com:6443/kube:admin"
+	)
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Name: "myfunc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Namespace = "apps"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	describer := mock.NewDescriber()
+	describer.DescribeFn = func(ctx context.Context, name, namespace string) (fn.Instance, error) {
+		if c := k8s.ContextFrom(ctx); c != fromContext || namespace != "apps" {
+			t.Errorf("expected to describe namespace apps of context %v, got %q of %q", fromContext, namespace, c)
+		}
+		return fn.Instance{Name: name, Namespace: namespace, Image: digest}, nil
+	}
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if c := k8s.ContextFrom(ctx); c != toContext || f.Namespace != "apps" {
+			t.Errorf("expected to deploy to namespace apps of context %v, got %q of %q", toContext, f.Namespace, c)
+		}
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: f.Namespace}, nil
+	}
+
+	cmd := NewPromoteCmd(NewTestClient(fn.WithDescriber(describer), fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--from-context", fromContext, "--to-context", toContext})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !deployer.DeployInvoked {
+		t.Fatal("expected the function deployed")
+	}
+
+	cmd = NewPromoteCmd(NewTestClient(fn.WithDescriber(describer), fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--to-context", "missing"})
+	if err = cmd.Execute(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected an error of the missing context, got %v", err)
+	}
+
+	cmd = NewPromoteCmd(NewTestClient(fn.WithDescriber(describer), fn.WithDeployer(deployer)))
+	cmd.SetArgs([]string{"--to", "apps"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected an error promoting to the namespace promoted from")
+	}
+}
+
+// TestPromote_NotDeployed ensures that the namespace from which to promote
+// is required.
+func TestPromote_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewPromoteCmd(NewTestClient())
+	cmd.SetArgs([]string{"--to", "prod"})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+}
+
+// TestPromote_Candidate ensures that promoting without a namespace promotes
+// the candidate revision of the deployed function, such that it must be
+// deployed, and that the options of promoting to a namespace require one.
+func TestPromote_Candidate(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewPromoteCmd(NewTestClient())
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+
+	cmd = NewPromoteCmd(NewTestClient())
+	cmd.SetArgs([]string{"--from", "staging"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected an error of --from without --to")
+	}
+}
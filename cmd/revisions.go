@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+)
+
+func NewRevisionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revisions",
+		Short: "List the revisions of a deployed function and what changed",
+		Long: `
+NAME
+	{{rootCmdUse}} revisions - List the revisions of a deployed function
+
+SYNOPSIS
+	{{rootCmdUse}} revisions [-o|--output] [-p|--path]
+
+	{{rootCmdUse}} revisions diff <revision> <revision> [-o|--output] [-p|--path]
+
+DESCRIPTION
+	Lists the revisions of the deployed function, newest first, with the
+	image of each, when it was created, and the traffic routed to it.  Each
+	deployment of a function, and each change of its scale, creates a new
+	revision.
+
+	'diff' shows what changed between two revisions: their images,
+	environment variables, resources and scale.  Revisions are given by
+	their name, or by their number, such that '3' is the revision of the
+	function whose name ends with '-00003'.  Values of environment variables
+	read from secrets and config maps are shown as their references.
+`,
+		Example: `
+# List the revisions of the function in the current directory
+{{rootCmdUse}} revisions
+
+# Show what changed between the third and fourth revisions
+{{rootCmdUse}} revisions diff 3 4
+`,
+		Aliases: []string{"revision", "rev"},
+		PreRunE: bindEnv("output", "path"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRevisions(cmd)
+		},
+	}
+
+	addRevisionsFlags(cmd)
+	cmd.AddCommand(NewRevisionsDiffCmd())
+
+	return cmd
+}
+
+func NewRevisionsDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <revision> <revision>",
+		Short: "Show what changed between two revisions of a function",
+		Long: `Show what changed between two revisions of a function
+
+Shows the changes from the first revision to the second of their images,
+environment variables, resources and scale.  See '{{rootCmdUse}} revisions
+--help' for details.
+`,
+		Args:    cobra.ExactArgs(2),
+		PreRunE: bindEnv("output", "path"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRevisionsDiff(cmd, args[0], args[1])
+		},
+	}
+
+	addRevisionsFlags(cmd)
+
+	return cmd
+}
+
+func addRevisionsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|json). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+}
+
+// deployedRevisions of the function at --path.
+func deployedRevisions(cmd *cobra.Command) (f fn.Function, rr []knative.Revision, err error) {
+	output := viper.GetString("output")
+	if output != "human" && output != "json" {
+		return f, nil, fmt.Errorf("unsupported output format %q. Accepts 'human' or 'json'", output)
+	}
+	if f, err = fn.NewFunction(effectivePath()); err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return f, nil, formatError(fn.NewErrNotInitialized(f.Root))
+	}
+	if f.Deploy.Namespace == "" {
+		return f, nil, ErrNotDeployed
+	}
+	rr, err = knative.Revisions(cmd.Context(), f.Name, f.Deploy.Namespace)
+	return
+}
+
+func runRevisions(cmd *cobra.Command) error {
+	f, rr, err := deployedRevisions(cmd)
+	if err != nil {
+		return err
+	}
+	if viper.GetString("output") == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(rr)
+	}
+	writeRevisions(cmd.OutOrStdout(), f.Name, rr, time.Now())
+	return nil
+}
+
+func runRevisionsDiff(cmd *cobra.Command, from, to string) error {
+	_, rr, err := deployedRevisions(cmd)
+	if err != nil {
+		return err
+	}
+	a, err := findRevision(rr, from)
+	if err != nil {
+		return err
+	}
+	b, err := findRevision(rr, to)
+	if err != nil {
+		return err
+	}
+	changes := knative.Diff(a, b)
+	if viper.GetString("output") == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(changes)
+	}
+	writeRevisionsDiff(cmd.OutOrStdout(), a, b, changes)
+	return nil
+}
+
+// findRevision by its name, or by its number, the suffix of its name.
+func findRevision(rr []knative.Revision, name string) (knative.Revision, error) {
+	for _, r := range rr {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	for _, r := range rr {
+		i := strings.LastIndex(r.Name, "-")
+		if i >= 0 && strings.TrimLeft(r.Name[i+1:], "0") == strings.TrimLeft(name, "0") {
+			return r, nil
+		}
+	}
+	return knative.Revision{}, fmt.Errorf("revision %q not found. List the revisions with 'func revisions'", name)
+}
+
+// writeRevisions as a table, newest first.
+func writeRevisions(w io.Writer, name string, rr []knative.Revision, now time.Time) {
+	fmt.Fprintf(w, "Revisions of %v\n\n", name)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tAGE\tTRAFFIC\tTAGS\tREADY\tIMAGE")
+	for _, r := range rr {
+		fmt.Fprintf(tw, "%v\t%v\t%v%%\t%v\t%v\t%v\n", r.Name, now.Sub(r.Created).Round(time.Second),
+			r.Traffic, strings.Join(r.Tags, ","), r.Ready, r.Image)
+	}
+	tw.Flush()
+}
+
+// writeRevisionsDiff as a list of the fields changed.
+func writeRevisionsDiff(w io.Writer, a, b knative.Revision, changes []fn.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "No changes from %v to %v\n", a.Name, b.Name)
+		return
+	}
+	fmt.Fprintf(w, "Changes from %v to %v\n\n", a.Name, b.Name)
+	unset := func(s string) string {
+		if s == "" {
+			return "(unset)"
+		}
+		return s
+	}
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %v: %v -> %v\n", c.Field, unset(c.From), unset(c.To))
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestRevisions_NotDeployed ensures that listing and diffing revisions
+// requires the function to have been deployed.
+func TestRevisions_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{}, {"diff", "1", "2"}} {
+		cmd := NewRevisionsCmd()
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+			t.Fatalf("expected ErrNotDeployed for %v, got %v", args, err)
+		}
+	}
+}
+
+// TestRevisions_Find ensures that revisions are found by their name or by
+// their number.
+func TestRevisions_Find(t *testing.T) {
+	rr := []knative.Revision{{Name: "myfn-00012"}, {Name: "myfn-00002"}}
+	for _, name := range []string{"myfn-00002", "2", "00002"} {
+		r, err := findRevision(rr, name)
+		if err != nil || r.Name != "myfn-00002" {
+			t.Errorf("expected %q to find myfn-00002, got %q (%v)", name, r.Name, err)
+		}
+	}
+	if _, err := findRevision(rr, "3"); err == nil {
+		t.Error("expected an error finding a revision which does not exist")
+	}
+}
+
+// TestRevisions_WriteDiff ensures that unset values are shown as such.
+func TestRevisions_WriteDiff(t *testing.T) {
+	var b bytes.Buffer
+	writeRevisionsDiff(&b, knative.Revision{Name: "a"}, knative.Revision{Name: "b"},
+		[]fn.Change{{Field: "envs.A", To: "1"}})
+	if !strings.Contains(b.String(), "envs.A: (unset) -> 1") {
+		t.Fatalf("unexpected diff:\n%v", b.String())
+	}
+}
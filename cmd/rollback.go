@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/knative"
+)
+
+func NewRollbackCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Route the traffic of a deployed function back to a previous revision",
+		Long: `
+NAME
+	{{rootCmdUse}} rollback - Route the traffic of a function to a previous revision
+
+SYNOPSIS
+	{{rootCmdUse}} rollback [--to-revision] [--list] [-o|--output] [-p|--path]
+
+DESCRIPTION
+	Routes all of the traffic of the deployed function to its previous
+	revision: the newest ready revision older than that currently routed the
+	most traffic.  Rolling back again routes the traffic to the revision
+	before that.  Use --to-revision to route the traffic to a revision given
+	by its name or number, such that '3' is the revision of the function
+	whose name ends with '-00003'.  Use --list to list the revisions to
+	which the function may be rolled back, newest first, without rolling
+	back.
+
+	Traffic is routed to the revision by name, such that the revision of a
+	later deployment is routed none of it.  Deploy with --traffic latest=100
+	to route all of the traffic to the latest revision once again.  Tagged
+	traffic is retained, routed none of the traffic, such that the URL of
+	each tag continues to resolve.
+`,
+		Example: `
+# Roll back the function in the current directory to its previous revision
+{{rootCmdUse}} rollback
+
+# List the revisions to which the function may be rolled back
+{{rootCmdUse}} rollback --list
+
+# Roll back to the third revision of the function
+{{rootCmdUse}} rollback --to-revision 3
+`,
+		PreRunE: bindEnv("to-revision", "list", "output", "path"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runRollback(cmd)
+		},
+	}
+
+	cmd.Flags().String("to-revision", "", "Revision to which to roll back, by name or number. Default is the previous revision. ($FUNC_TO_REVISION)")
+	cmd.Flags().Bool("list", false, "List the revisions to which the function may be rolled back, without rolling back. ($FUNC_LIST)")
+	addRevisionsFlags(cmd)
+
+	return cmd
+}
+
+func runRollback(cmd *cobra.Command) error {
+	f, rr, err := deployedRevisions(cmd)
+	if err != nil {
+		return err
+	}
+	output := viper.GetString("output")
+
+	if viper.GetBool("list") {
+		if output == "json" {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(rr)
+		}
+		writeRevisions(cmd.OutOrStdout(), f.Name, rr, time.Now())
+		return nil
+	}
+
+	var target knative.Revision
+	if name := viper.GetString("to-revision"); name != "" {
+		if target, err = findRevision(rr, name); err != nil {
+			return err
+		}
+		if !target.Ready {
+			return fmt.Errorf("revision %v is not ready, and so may not be rolled back to", target.Name)
+		}
+	} else if target, err = knative.PreviousRevision(rr); err != nil {
+		return err
+	}
+
+	if err = knative.Rollback(cmd.Context(), f.Name, f.Deploy.Namespace, target.Name); err != nil {
+		return err
+	}
+	if output == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(target)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Rolled back %v to revision %v, routed all of its traffic\n", f.Name, target.Name)
+	return nil
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestRollback_NotDeployed ensures that rolling back the function of the
+// current directory requires it to have been deployed.
+func TestRollback_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewRollbackCmd()
+	cmd.SetArgs([]string{"--to-revision", "1"})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+}
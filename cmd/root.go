@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver"
+	"github.com/heroku/color"
 	"github.com/ory/viper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -64,6 +66,16 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 	viper.SetEnvPrefix("func") // ensure that all have the prefix
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 
+	// CI mode
+	// Disables all interactivity for use in pipelines (see ciMode).
+	cmd.PersistentFlags().Bool("ci", false, "Run non-interactively: never prompt, and write plain output without color or progress animations ($FUNC_CI)")
+	_ = viper.BindPFlag("ci", cmd.PersistentFlags().Lookup("ci"))
+	cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if ciMode() {
+			color.Disable(true)
+		}
+	}
+
 	// check if permissions for FUNC HOME are sufficient; warn if otherwise
 	cp := config.File()
 	if _, err := os.ReadFile(cp); os.IsPermission(err) {
@@ -84,6 +96,13 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 				NewCreateCmd(newClient),
 				NewDescribeCmd(newClient),
 				NewDeployCmd(newClient),
+				NewPromoteCmd(newClient),
+				NewScaleCmd(),
+				NewPauseCmd(),
+				NewResumeCmd(),
+				NewRevisionsCmd(),
+				NewRollbackCmd(),
+				NewDiffCmd(newClient),
 				NewDeleteCmd(newClient),
 				NewListCmd(newClient),
 				NewSubscribeCmd(),
@@ -95,6 +114,13 @@ Learn more about Knative at: https://knative.dev`, cfg.Name),
 				NewRunCmd(newClient),
 				NewInvokeCmd(newClient),
 				NewBuildCmd(newClient),
+				NewCacheCmd(newClient),
+				NewUpgradeBaseCmd(newClient),
+				NewEventsCmd(newClient),
+				NewPerfCmd(newClient),
+				NewCostCmd(newClient),
+				NewBundleCmd(),
+				NewDevloopCmd(),
 			},
 		},
 		{
@@ -204,10 +230,22 @@ func defaultNamespace(f fn.Function, verbose bool) string {
 // interactiveTerminal returns whether or not the currently attached process
 // terminal is interactive.  Used for determining whether or not to
 // interactively prompt the user to confirm default choices, etc.
+// Never interactive in CI mode.
 func interactiveTerminal() bool {
-	return term.IsTerminal(int(os.Stdin.Fd()))
+	return !ciMode() && term.IsTerminal(int(os.Stdin.Fd()))
 }
 
+// ciMode returns whether the command is running in a CI pipeline, as
+// indicated by --ci or FUNC_CI.  In CI mode nothing is prompted for, such
+// that all values must be provided by flags or environment variables, and
+// output is written as plain lines without color or progress animations.
+func ciMode() bool {
+	return viper.GetBool("ci")
+}
+
+// ErrPromptCI is returned in CI mode in place of prompting for a value.
+var ErrPromptCI = errors.New("prompting is disabled in CI mode; provide the value using flags or environment variables")
+
 // bindFunc which conforms to the cobra PreRunE method signature
 type bindFunc func(*cobra.Command, []string) error
 
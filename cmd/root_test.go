@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -385,3 +386,35 @@ func piped(t *testing.T) func() string {
 		return strings.TrimSpace(b.String())
 	}
 }
+
+// TestRoot_CI ensures that CI mode, enabled by either --ci or FUNC_CI,
+// fails rather than prompting.
+func TestRoot_CI(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  string
+	}{
+		{name: "flag", args: []string{"config", "--ci"}},
+		{name: "environment variable", args: []string{"config"}, env: "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = FromTempDirectory(t)
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			if tt.env != "" {
+				t.Setenv("FUNC_CI", tt.env)
+			}
+
+			cmd := NewRootCmd(RootCommandConfig{Name: "func"})
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); !errors.Is(err, ErrPromptCI) {
+				t.Fatalf("expected ErrPromptCI, got %v", err)
+			}
+			if interactiveTerminal() {
+				t.Fatal("expected a non-interactive terminal in CI mode")
+			}
+		})
+	}
+}
@@ -28,8 +28,8 @@ NAME
 	{{rootCmdUse}} run - Run a function locally
 
 SYNOPSIS
-	{{rootCmdUse}} run [-r|--registry] [-i|--image] [-e|--env] [--build]
-				 [-b|--builder] [--builder-image] [-c|--confirm]
+	{{rootCmdUse}} run [-r|--registry] [-i|--image] [-e|--env] [--env-file] [--build]
+				 [-b|--builder] [--builder-image] [--dockerfile] [-c|--confirm]
 	             [--address] [--json] [-v|--verbose]
 
 DESCRIPTION
@@ -52,6 +52,12 @@ DESCRIPTION
 	  is transient, written for each build or run, and should in most cases be
 	  transparent to a function author.
 
+	Environment Variables
+	  The --env-file flag sets the environment variables of a dotenv file, of
+	  NAME=VALUE lines, for the run.  Those of func.yaml are overridden by
+	  those of the files, in the order given, which are in turn overridden by
+	  those of --env.
+
 EXAMPLES
 
 	o Run the function locally from within its container.
@@ -65,6 +71,9 @@ EXAMPLES
 	o Run the function locally on the host with no containerization (Go/Python only).
 	  $ {{rootCmdUse}} run --builder=host
 
+	o Run the function locally with the environment variables of .env.
+	  $ {{rootCmdUse}} run --env-file .env
+
 	o Run the function locally on a specific address.
 	  $ {{rootCmdUse}} run --address='[::]:8081'
 
@@ -72,8 +81,8 @@ EXAMPLES
 	  $ {{rootCmdUse}} run --json
 `,
 		SuggestFor: []string{"rnu"},
-		PreRunE: bindEnv("build", "builder", "builder-image", "base-image",
-			"confirm", "env", "image", "path", "registry",
+		PreRunE: bindEnv("build", "builder", "builder-image", "base-image", "run-image", "dockerfile",
+			"confirm", "env", "env-file", "image", "path", "registry",
 			"start-timeout", "verbose", "address", "json"),
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			return runRun(cmd, newClient)
@@ -107,13 +116,21 @@ EXAMPLES
 	cmd.Flags().String("builder-image", builderImage,
 		"Specify a custom builder image for use by the builder other than its default. ($FUNC_BUILDER_IMAGE)")
 	cmd.Flags().StringP("base-image", "", f.Build.BaseImage,
-		"Override the base image for your function (host builder only)")
+		"Override the image your function is built upon: the builder image of the pack and s2i builders, or the base of the host builder. ($FUNC_BASE_IMAGE)")
+	cmd.Flags().StringP("run-image", "", f.Build.RunImage,
+		"Override the image your function runs upon: the run image of the pack builder, the runtime image of the s2i builder, or the base of the host builder. ($FUNC_RUN_IMAGE)")
+	cmd.Flags().String("dockerfile", f.Build.Dockerfile,
+		"Path, relative to the function's root, of the Dockerfile from which the dockerfile and kaniko builders build the function. Defaults to Dockerfile. ($FUNC_DOCKERFILE)")
 	cmd.Flags().StringP("image", "i", f.Image,
 		"Full image name in the form [registry]/[namespace]/[name]:[tag]. This option takes precedence over --registry. Specifying tag is optional. ($FUNC_IMAGE)")
 	cmd.Flags().StringArrayP("env", "e", []string{},
 		"Environment variable to set in the form NAME=VALUE. "+
 			"You may provide this flag multiple times for setting multiple environment variables. "+
 			"To unset, specify the environment variable name followed by a \"-\" (e.g., NAME-).")
+	cmd.Flags().StringArray("env-file", []string{},
+		"Dotenv file of environment variables to set, in the form NAME=VALUE per line. "+
+			"You may provide this flag multiple times, later files taking precedence. "+
+			"Variables given with --env take precedence over those of the files.")
 	cmd.Flags().Duration("start-timeout", f.Run.StartTimeout, fmt.Sprintf("time this function needs in order to start. If not provided, the client default %v will be in effect. ($FUNC_START_TIMEOUT)", fn.DefaultStartTimeout))
 
 	// TODO: Without the "Host" builder enabled, this code-path is unreachable,
@@ -342,6 +359,9 @@ type runConfig struct {
 	// Env variables.  may include removals using a "-"
 	Env []string
 
+	// EnvFiles of environment variables, applied in order before Env.
+	EnvFiles []string
+
 	// StartTimeout optionally adjusts the startup timeout from the client's
 	// default of fn.DefaultStartTimeout.
 	StartTimeout time.Duration
@@ -369,6 +389,9 @@ func newRunConfig(cmd *cobra.Command) (c runConfig) {
 	if c.Env, err = cmd.Flags().GetStringArray("env"); err != nil {
 		fmt.Fprintf(cmd.OutOrStdout(), "error reading envs: %v", err)
 	}
+	if c.EnvFiles, err = cmd.Flags().GetStringArray("env-file"); err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error reading env files: %v", err)
+	}
 	return
 }
 
@@ -381,7 +404,7 @@ func (c runConfig) Configure(f fn.Function) (fn.Function, error) {
 
 	f.Run.StartTimeout = c.StartTimeout
 
-	f.Run.Envs, err = applyEnvs(f.Run.Envs, c.Env)
+	f.Run.Envs, err = applyEnvs(f.Run.Envs, c.EnvFiles, c.Env)
 
 	// The other members; build and path; are not part of function
 	// state, so are not mentioned here in Configure.
@@ -460,10 +483,18 @@ For more options, run 'func run --help'`, portNum)
 		}
 	}
 
-	if f.Build.Builder == "host" && !oci.IsSupported(f.Runtime) {
+	// Node and TypeScript functions may be built by the host builder, but are
+	// not yet run directly on the host.
+	if f.Build.Builder == "host" && (!oci.IsSupported(f.Runtime) || f.Runtime == "node" || f.Runtime == "typescript") {
 		return fmt.Errorf("the %q runtime currently requires being run in a container", f.Runtime)
 	}
 
+	// Images built by a BuildKit daemon are not available to the local
+	// container engine.
+	if f.Build.Builder == "buildkit" {
+		return errors.New("functions built by the buildkit builder cannot be run locally. Use a different --builder")
+	}
+
 	// When the docker runner respects the StartTimeout, this validation check
 	// can be removed
 	if c.StartTimeout != 0 && f.Build.Builder != "host" {
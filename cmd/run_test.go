@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -518,6 +519,57 @@ func TestRun_Address(t *testing.T) {
 	}
 }
 
+// TestRun_EnvFile ensures that the environment variables of an env file are
+// those of the function run, overridden by those of --env.
+func TestRun_EnvFile(t *testing.T) {
+	root := FromTempDirectory(t)
+	_, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(".env", []byte("A=file\nB=file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := mock.NewRunner()
+	runner.RunFn = func(_ context.Context, f fn.Function, _ string, _ time.Duration) (*fn.Job, error) {
+		envs := map[string]string{}
+		for _, e := range f.Run.Envs {
+			envs[*e.Name] = *e.Value
+		}
+		if envs["A"] != "file" || envs["B"] != "flag" {
+			return nil, fmt.Errorf("unexpected envs %v", envs)
+		}
+		errs := make(chan error, 1)
+		stop := func() error { return nil }
+		return fn.NewJob(f, "127.0.0.1", "8080", errs, stop, false)
+	}
+
+	cmd := NewRunCmd(NewTestClient(
+		fn.WithRunner(runner),
+		fn.WithRegistry("ghcr.com/reg"),
+	))
+	cmd.SetArgs([]string{"--env-file=.env", "--env=B=flag"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErrCh := make(chan error, 1)
+	go func() {
+		if _, err := cmd.ExecuteContextC(ctx); err != nil {
+			runErrCh <- err
+			return
+		}
+		if !runner.RunInvoked {
+			runErrCh <- fmt.Errorf("the function was not run")
+		}
+		close(runErrCh)
+	}()
+	cancel()
+	<-ctx.Done()
+	if err := <-runErrCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestRun_BaseImage ensures that running func run --base-image with various
 // other
 func TestRun_BaseImage(t *testing.T) {
@@ -539,10 +591,9 @@ func TestRun_BaseImage(t *testing.T) {
 			builder: "host",
 		},
 		{
-			name:        "should-fail: python-runtime with pack-builder",
-			runtime:     "python",
-			builder:     "pack",
-			expectError: true,
+			name:    "should-succeed: python-runtime with pack-builder",
+			runtime: "python",
+			builder: "pack",
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/knative"
+)
+
+func NewScaleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale <name>",
+		Short: "Adjust the scale bounds of a deployed function",
+		Long: `
+NAME
+	{{rootCmdUse}} scale - Adjust the scale bounds of a deployed function
+
+SYNOPSIS
+	{{rootCmdUse}} scale [name] [--min] [--max] [-n|--namespace] [--save]
+	             [-p|--path] [-v|--verbose]
+
+DESCRIPTION
+	Sets the minimum and maximum number of instances of a deployed function,
+	patching only the scale bounds of its service on the cluster.  The
+	function is neither built nor redeployed, though the cluster creates a
+	new revision of it with the new bounds.
+
+	By default the function of the project in the current directory is
+	scaled.  Alternatively the name of a deployed function may be given,
+	with its --namespace.
+
+	Only the bounds provided are changed.  A --max of 0 is unbounded, and a
+	--min of 0 allows the function to scale to zero.
+
+	The bounds set on the cluster are replaced by those of func.yaml when
+	the function is next deployed.  Use --save to also write them to
+	options.scale of func.yaml, such that they are retained.
+`,
+		Example: `
+# Keep at least one instance of the function in the current directory
+{{rootCmdUse}} scale --min 1
+
+# Scale the function 'myfn' in namespace 'prod' to between 1 and 10 instances
+{{rootCmdUse}} scale --min 1 --max 10 myfn -n prod
+
+# Remove the maximum, and retain the change in func.yaml
+{{rootCmdUse}} scale --max 0 --save
+`,
+		ValidArgsFunction: CompleteFunctionList,
+		Args:              cobra.MaximumNArgs(1),
+		PreRunE:           bindEnv("min", "max", "namespace", "save", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScale(cmd, args)
+		},
+	}
+
+	cfg, err := config.NewDefault()
+	if err != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "error loading config at '%v'. %v\n", config.File(), err)
+	}
+
+	cmd.Flags().Int64("min", 0, "Minimum number of instances. ($FUNC_MIN)")
+	cmd.Flags().Int64("max", 0, "Maximum number of instances, 0 being unbounded. ($FUNC_MAX)")
+	cmd.Flags().StringP("namespace", "n", defaultNamespace(fn.Function{}, false), "The namespace when scaling by name. ($FUNC_NAMESPACE)")
+	cmd.Flags().Bool("save", false, "Write the scale bounds to func.yaml. ($FUNC_SAVE)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, cfg.Verbose)
+
+	return cmd
+}
+
+func runScale(cmd *cobra.Command, args []string) (err error) {
+	cfg, err := newScaleConfig(cmd, args)
+	if err != nil {
+		return
+	}
+
+	// The function of the project, required unless scaling by name, and
+	// when saving the bounds.
+	var f fn.Function
+	if cfg.Name == "" || cfg.Save {
+		if f, err = fn.NewFunction(cfg.Path); err != nil {
+			return
+		}
+		if !f.Initialized() {
+			return formatError(fn.NewErrNotInitialized(f.Root))
+		}
+		if cfg.Name != "" && cfg.Name != f.Name {
+			return fmt.Errorf("cannot save the scale of %v to the function %v in '%v'", cfg.Name, f.Name, f.Root)
+		}
+	}
+	name, namespace := cfg.Name, cfg.Namespace
+	if name == "" {
+		if f.Deploy.Namespace == "" {
+			return ErrNotDeployed
+		}
+		name, namespace = f.Name, f.Deploy.Namespace
+	}
+
+	if err = knative.Scale(cmd.Context(), name, namespace, cfg.Min, cfg.Max); err != nil {
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Scaled %v in namespace %q to %v\n", name, namespace, describeScale(cfg.Min, cfg.Max))
+
+	if !cfg.Save {
+		return nil
+	}
+	if f.Deploy.Options.Scale == nil {
+		f.Deploy.Options.Scale = &fn.ScaleOptions{}
+	}
+	if cfg.Min != nil {
+		f.Deploy.Options.Scale.Min = cfg.Min
+	}
+	if cfg.Max != nil {
+		f.Deploy.Options.Scale.Max = cfg.Max
+	}
+	return f.Write()
+}
+
+// describeScale bounds, for example "min 1, max unbounded".
+func describeScale(min, max *int64) (s string) {
+	if min != nil {
+		s = fmt.Sprintf("min %v", *min)
+	}
+	if max != nil {
+		if s != "" {
+			s += ", "
+		}
+		if *max == 0 {
+			s += "max unbounded"
+		} else {
+			s += fmt.Sprintf("max %v", *max)
+		}
+	}
+	return
+}
+
+type scaleConfig struct {
+	Name      string
+	Namespace string
+	Path      string
+	Min       *int64 // nil if unchanged
+	Max       *int64 // nil if unchanged
+	Save      bool
+	Verbose   bool
+}
+
+func newScaleConfig(cmd *cobra.Command, args []string) (cfg scaleConfig, err error) {
+	cfg = scaleConfig{
+		Namespace: viper.GetString("namespace"),
+		Path:      viper.GetString("path"),
+		Save:      viper.GetBool("save"),
+		Verbose:   viper.GetBool("verbose"),
+	}
+	if len(args) > 0 {
+		cfg.Name = args[0]
+	}
+	if viper.IsSet("min") {
+		v := viper.GetInt64("min")
+		cfg.Min = &v
+	}
+	if viper.IsSet("max") {
+		v := viper.GetInt64("max")
+		cfg.Max = &v
+	}
+	return cfg, cfg.Validate(cmd)
+}
+
+// Validate the config, which must change at least one bound.
+func (c scaleConfig) Validate(cmd *cobra.Command) error {
+	if c.Min == nil && c.Max == nil {
+		return errors.New("provide --min, --max, or both")
+	}
+	if c.Min != nil && *c.Min < 0 {
+		return fmt.Errorf("--min may not be negative, got %v", *c.Min)
+	}
+	if c.Max != nil && *c.Max < 0 {
+		return fmt.Errorf("--max may not be negative, got %v", *c.Max)
+	}
+	if c.Min != nil && c.Max != nil && *c.Max > 0 && *c.Min > *c.Max {
+		return fmt.Errorf("--min %v may not exceed --max %v", *c.Min, *c.Max)
+	}
+	if c.Name == "" && cmd.Flags().Changed("namespace") {
+		return errors.New("must also specify a name when specifying namespace")
+	}
+	if c.Name != "" && cmd.Flags().Changed("path") {
+		return errors.New("only one of --path and [NAME] should be provided")
+	}
+	return nil
+}
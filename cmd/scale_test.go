@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestScale_Validation ensures that scale bounds are validated before the
+// cluster is contacted.
+func TestScale_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"no bounds", []string{"myfn"}, "provide --min, --max"},
+		{"negative min", []string{"myfn", "--min=-1"}, "--min may not be negative"},
+		{"min above max", []string{"myfn", "--min=5", "--max=2"}, "may not exceed"},
+		{"namespace without name", []string{"--min=1", "--namespace=prod"}, "must also specify a name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = FromTempDirectory(t)
+			cmd := NewScaleCmd()
+			cmd.SetArgs(tt.args)
+			if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+// TestScale_NotDeployed ensures that scaling the function of the current
+// directory requires it to have been deployed.
+func TestScale_NotDeployed(t *testing.T) {
+	root := FromTempDirectory(t)
+	if _, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewScaleCmd()
+	cmd.SetArgs([]string{"--max=3"})
+	if err := cmd.Execute(); !errors.Is(err, ErrNotDeployed) {
+		t.Fatalf("expected ErrNotDeployed, got %v", err)
+	}
+}
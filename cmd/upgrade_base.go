@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ory/viper"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/config"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func NewUpgradeBaseCmd(newClient ClientFactory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade-base",
+		Short: "Check for and apply newer patch versions of a function's base images",
+		Long: `
+NAME
+	{{rootCmdUse}} upgrade-base - Check for and apply newer patch versions of a
+	function's base images
+
+SYNOPSIS
+	{{rootCmdUse}} upgrade-base [--apply] [--push] [-o|--output] [-p|--path]
+	             [-v|--verbose]
+
+DESCRIPTION
+	Checks whether the images upon which the function is built, being those
+	its func.yaml defines as build.builderImages, build.baseImage and
+	build.runImage, have newer patch versions in their registries, such as
+	those fixing vulnerabilities, and lists each.  Only images tagged with a
+	patch version (3.13.1-slim, v1.24.2) are checked, and only for patches of
+	the same minor version and variant: an image tagged with a minor version
+	alone, or "latest", is updated in place by its publisher.
+
+	With --apply, func.yaml is updated to the newer images, and the function
+	is built upon them.  When the function is built by pack and its run
+	image alone is upgraded, its image is rebased upon the newer run image
+	rather than built again, replacing only the layers of the run image.
+	With --push, the image is then pushed.
+`,
+		Example: `
+# List the newer patch versions of the function's base images
+{{rootCmdUse}} upgrade-base
+
+# Upgrade the function's base images, rebuild and push it
+{{rootCmdUse}} upgrade-base --apply --push
+`,
+		PreRunE: bindEnv("apply", "push", "output", "path", "verbose"),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runUpgradeBase(cmd, newClient)
+		},
+	}
+
+	cmd.Flags().Bool("apply", false, "Update func.yaml to the newer images and build the function upon them. ($FUNC_APPLY)")
+	cmd.Flags().Bool("push", false, "Push the image once upgraded. Requires --apply. ($FUNC_PUSH)")
+	cmd.Flags().StringP("output", "o", "human", "Output format (human|plain|json|yaml|csv). ($FUNC_OUTPUT)")
+	addPathFlag(cmd)
+	addVerboseFlag(cmd, false)
+
+	return cmd
+}
+
+func runUpgradeBase(cmd *cobra.Command, newClient ClientFactory) (err error) {
+	apply, push, verbose := viper.GetBool("apply"), viper.GetBool("push"), viper.GetBool("verbose")
+	if push && !apply {
+		return errors.New("--push requires applying the upgrades (--apply)")
+	}
+	f, err := fn.NewFunction(effectivePath())
+	if err != nil {
+		return
+	}
+	if !f.Initialized() {
+		return formatError(fn.NewErrNotInitialized(f.Root))
+	}
+
+	// The function is rebuilt as last configured
+	cfg := buildConfig{
+		Global: config.Global{
+			Builder:      f.Build.Builder,
+			Registry:     f.Registry,
+			Verbose:      verbose,
+			Builders:     builderPolicies(),
+			Dependencies: dependencies(),
+		},
+		BuildKitHost: os.Getenv("BUILDKIT_HOST"),
+		Incremental:  f.Build.Incremental,
+	}
+	if cfg.Builder == "" {
+		cfg.Builder = builders.Default
+	}
+	clientOptions, err := cfg.clientOptions()
+	if err != nil {
+		return
+	}
+	client, done := newClient(ClientConfig{Verbose: verbose}, clientOptions...)
+	defer done()
+
+	uu, err := client.ImageUpgrades(cmd.Context(), f)
+	if err != nil {
+		return
+	}
+	write(cmd.OutOrStdout(), imageUpgrades(uu), viper.GetString("output"))
+	if !apply || len(uu) == 0 {
+		return
+	}
+
+	f = f.ApplyImageUpgrades(uu)
+	if cfg.Builder == builders.Pack && f.Build.Image != "" && runImageOnly(uu) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Rebasing %v upon %v\n", f.Build.Image, f.Build.RunImage)
+		if f, err = client.Rebase(cmd.Context(), f); err != nil {
+			return
+		}
+	} else {
+		var oo []fn.BuildOption
+		if len(f.Build.Platforms) > 1 {
+			oo = append(oo, fn.BuildWithPlatforms(f.Build.Platforms))
+		}
+		if f, err = client.Build(cmd.Context(), f, oo...); err != nil {
+			return
+		}
+	}
+	if push {
+		if f, _, err = client.Push(cmd.Context(), f); err != nil {
+			return
+		}
+	}
+	if err = f.Write(); err != nil {
+		return
+	}
+	return f.Stamp()
+}
+
+// runImageOnly returns whether the run image is the only image upgraded.
+func runImageOnly(uu []fn.ImageUpgrade) bool {
+	return len(uu) == 1 && uu[0].Field == "build.runImage"
+}
+
+// Output Formatting (serializers)
+// -------------------------------
+
+type imageUpgrades []fn.ImageUpgrade
+
+func (items imageUpgrades) Human(w io.Writer) error {
+	if len(items) == 0 {
+		_, err := fmt.Fprintln(w, "The function's base images are up to date")
+		return err
+	}
+	return items.Plain(w)
+}
+
+func (items imageUpgrades) Plain(w io.Writer) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", "FIELD", "IMAGE", "LATEST")
+	for _, item := range items {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\n", item.Field, item.Image, item.Latest)
+	}
+	return nil
+}
+
+func (items imageUpgrades) JSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(items)
+}
+
+func (items imageUpgrades) XML(w io.Writer) error {
+	return errors.New("xml is not supported for image upgrades")
+}
+
+func (items imageUpgrades) YAML(w io.Writer) error {
+	return yaml.NewEncoder(w).Encode(items)
+}
+
+func (items imageUpgrades) URL(w io.Writer) error {
+	return errors.New("url is not supported for image upgrades")
+}
+
+func (items imageUpgrades) CSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"field", "image", "latest"})
+	for _, item := range items {
+		_ = cw.Write([]string{item.Field, item.Image, item.Latest})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/mock"
+	. "knative.dev/func/pkg/testing"
+)
+
+// TestUpgradeBase ensures that newer patch versions of the function's base
+// images are listed, and with --apply written to func.yaml and built upon.
+func TestUpgradeBase(t *testing.T) {
+	root := FromTempDirectory(t)
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Registry: TestRegistry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Builder = "s2i"
+	f.Build.BuilderImages = map[string]string{"s2i": "example.com/builder:1.2.0"}
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	lister := mock.NewTagLister()
+	lister.TagsFn = func(context.Context, string) ([]string, error) {
+		return []string{"1.2.0", "1.2.3", "1.3.0"}, nil
+	}
+
+	cmd := NewUpgradeBaseCmd(NewTestClient(fn.WithTagLister(lister)))
+	cmd.SetArgs([]string{"--push"})
+	if err = cmd.Execute(); err == nil {
+		t.Fatal("expected --push to require --apply")
+	}
+
+	out := bytes.Buffer{}
+	cmd = NewUpgradeBaseCmd(NewTestClient(fn.WithTagLister(lister)))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "example.com/builder:1.2.3") {
+		t.Fatalf("expected the newer builder image listed, got:\n%v", out.String())
+	}
+
+	builder := mock.NewBuilder()
+	cmd = NewUpgradeBaseCmd(NewTestClient(fn.WithTagLister(lister), fn.WithBuilder(builder)))
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--apply"})
+	if err = cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !builder.BuildInvoked {
+		t.Fatal("expected the function to be built upon the upgraded images")
+	}
+	if f, err = fn.NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.BuilderImages["s2i"] != "example.com/builder:1.2.3" {
+		t.Fatalf("expected the upgraded builder image persisted, got %v", f.Build.BuilderImages)
+	}
+}
@@ -39,7 +39,7 @@ func Main() {
 
 	if err := cmd.NewRootCmd(cfg).ExecuteContext(ctx); err != nil {
 		if !errors.Is(err, terminal.InterruptErr) {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			cmd.PrintError(os.Stderr, err)
 		}
 		if ctx.Err() != nil || errors.Is(err, terminal.InterruptErr) {
 			os.Exit(130)
@@ -58,7 +58,7 @@ Please consider installing one of these:
 			}
 		}
 
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
 
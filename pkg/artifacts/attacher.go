@@ -0,0 +1,268 @@
+/*
+Package artifacts attaches files of a function, such as its OpenAPI
+specification, event schemas or README, to the function's image as OCI
+artifacts referring to it, such that they are discoverable from the registry
+alongside the image itself.
+*/
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
+)
+
+// Annotations of the artifacts' manifests.
+const (
+	// TitleAnnotation holds the path of the attached file.
+	TitleAnnotation = "org.opencontainers.image.title"
+	// FunctionAnnotation holds the name of the function.
+	FunctionAnnotation = "dev.knative.func.name"
+)
+
+// Opt is an option for the attacher.
+type Opt func(*Attacher)
+
+// WithCredentialsProvider used when accessing the registry.
+func WithCredentialsProvider(cp oci.CredentialsProvider) Opt {
+	return func(a *Attacher) {
+		a.credentialsProvider = cp
+	}
+}
+
+// WithTransport used when accessing the registry.
+func WithTransport(t http.RoundTripper) Opt {
+	return func(a *Attacher) {
+		a.transport = t
+	}
+}
+
+// WithVerbose logging.
+func WithVerbose(verbose bool) Opt {
+	return func(a *Attacher) {
+		a.verbose = verbose
+	}
+}
+
+// Attacher of artifacts to images.  Implements fn.Attacher.
+type Attacher struct {
+	credentialsProvider oci.CredentialsProvider
+	transport           http.RoundTripper
+	verbose             bool
+}
+
+// NewAttacher creates an attacher of artifacts using the OCI referrers API,
+// or its fallback tag scheme on registries which do not support it.
+func NewAttacher(opts ...Opt) *Attacher {
+	a := &Attacher{
+		credentialsProvider: oci.EmptyCredentialsProvider,
+		transport:           http.DefaultTransport,
+	}
+	for _, o := range opts {
+		o(a)
+	}
+	return a
+}
+
+// Attach each of the function's artifacts to its pushed image, each as an
+// artifact manifest whose subject is the image.
+func (a *Attacher) Attach(ctx context.Context, f fn.Function) (attached []fn.Artifact, err error) {
+	opts, err := a.remoteOptions(ctx, f.Build.Image)
+	if err != nil {
+		return
+	}
+	repo, subject, err := a.subject(f.Build.Image, opts)
+	if err != nil {
+		return
+	}
+	for _, spec := range f.Build.Artifacts {
+		artifact, img, err := newArtifact(f, spec, subject)
+		if err != nil {
+			return attached, err
+		}
+		h, err := img.Digest()
+		if err != nil {
+			return attached, err
+		}
+		if err = remote.Write(repo.Digest(h.String()), img, opts...); err != nil {
+			return attached, fmt.Errorf("cannot push artifact %v: %w", spec.Path, err)
+		}
+		artifact.Digest = h.String()
+		if a.verbose {
+			fmt.Fprintf(os.Stderr, "Attached %v (%v) as %v\n", artifact.Path, artifact.Type, artifact.Digest)
+		}
+		attached = append(attached, artifact)
+	}
+	return
+}
+
+// newArtifact image of the function's file, referring to the subject.
+func newArtifact(f fn.Function, spec fn.ArtifactSpec, subject v1.Descriptor) (artifact fn.Artifact, img v1.Image, err error) {
+	bb, err := os.ReadFile(filepath.Join(f.Root, spec.Path))
+	if err != nil {
+		return
+	}
+	artifact = fn.Artifact{
+		Path:      filepath.ToSlash(spec.Path),
+		Type:      spec.Type,
+		MediaType: spec.MediaType,
+		Size:      int64(len(bb)),
+	}
+	if artifact.Type == "" {
+		artifact.Type = fn.DefaultArtifactType
+	}
+	if artifact.MediaType == "" {
+		artifact.MediaType = mediaType(spec.Path)
+	}
+
+	// The artifact's type is that of its config, as registries derive the
+	// type of referrers without an explicit artifactType from it.
+	img = mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, types.MediaType(artifact.Type))
+	if img, err = mutate.AppendLayers(img, static.NewLayer(bb, types.MediaType(artifact.MediaType))); err != nil {
+		return
+	}
+	img = mutate.Annotations(img, map[string]string{
+		TitleAnnotation:    artifact.Path,
+		FunctionAnnotation: f.Name,
+	}).(v1.Image)
+	img = mutate.Subject(img, subject).(v1.Image)
+	return
+}
+
+// mediaType of a file, by its extension.
+func mediaType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "application/json"
+	case ".yaml", ".yml":
+		return "application/yaml"
+	case ".md":
+		return "text/markdown"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// Artifacts attached to the image.
+func (a *Attacher) Artifacts(ctx context.Context, image string) (aa []fn.Artifact, err error) {
+	opts, err := a.remoteOptions(ctx, image)
+	if err != nil {
+		return
+	}
+	repo, subject, err := a.subject(image, opts)
+	if err != nil {
+		return
+	}
+	ii, err := remote.Referrers(repo.Digest(subject.Digest.String()), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the artifacts of %v: %w", image, err)
+	}
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return
+	}
+	for _, d := range im.Manifests {
+		artifact := fn.Artifact{
+			Path:   d.Annotations[TitleAnnotation],
+			Type:   d.ArtifactType,
+			Digest: d.Digest.String(),
+			Size:   d.Size,
+		}
+		// Not all registries include the annotations of referrers in their
+		// listing, in which case they are read from the manifest itself.
+		if artifact.Path == "" {
+			if m, err := remote.Get(repo.Digest(d.Digest.String()), opts...); err == nil {
+				if mf, err := v1.ParseManifest(bytes.NewReader(m.Manifest)); err == nil {
+					artifact.Path = mf.Annotations[TitleAnnotation]
+					if len(mf.Layers) == 1 {
+						artifact.MediaType = string(mf.Layers[0].MediaType)
+					}
+				}
+			}
+		}
+		aa = append(aa, artifact)
+	}
+	return
+}
+
+// Fetch the content of the artifact, the single layer of its manifest.
+func (a *Attacher) Fetch(ctx context.Context, image string, artifact fn.Artifact, w io.Writer) error {
+	opts, err := a.remoteOptions(ctx, image)
+	if err != nil {
+		return err
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return err
+	}
+	img, err := remote.Image(ref.Context().Digest(artifact.Digest), opts...)
+	if err != nil {
+		return fmt.Errorf("cannot fetch artifact %v: %w", artifact.Digest, err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return err
+	}
+	if len(layers) != 1 {
+		return errors.New("artifact has no single file to fetch")
+	}
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(w, rc)
+	return err
+}
+
+// subject of the artifacts: the repository of the image and its descriptor
+// as found in the registry, such that a tag is resolved to its digest.
+func (a *Attacher) subject(image string, opts []remote.Option) (repo name.Repository, d v1.Descriptor, err error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return
+	}
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return repo, d, fmt.Errorf("cannot find image %v: %w", image, err)
+	}
+	return ref.Context(), *desc, nil
+}
+
+func (a *Attacher) remoteOptions(ctx context.Context, image string) ([]remote.Option, error) {
+	creds, err := a.credentialsProvider(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	var auth authn.Authenticator = authn.Anonymous
+	if creds.Username != "" || creds.Password != "" {
+		auth = &authn.Basic{Username: creds.Username, Password: creds.Password}
+	}
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuth(auth),
+		remote.WithTransport(a.transport),
+	}, nil
+}
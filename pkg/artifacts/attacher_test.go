@@ -0,0 +1,92 @@
+package artifacts_test
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"knative.dev/func/pkg/artifacts"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestAttacher ensures that the artifacts of a function are attached to its
+// image, listed by the image's tag or digest, and fetched, both from
+// registries supporting the referrers API and from those which do not.
+func TestAttacher(t *testing.T) {
+	t.Run("referrers API", func(t *testing.T) { testAttacher(t, true) })
+	t.Run("referrers tag", func(t *testing.T) { testAttacher(t, false) })
+}
+
+func testAttacher(t *testing.T, referrers bool) {
+	server := httptest.NewServer(registry.New(registry.WithReferrersSupport(referrers)))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	repo, err := name.NewRepository(u.Host + "/test/func")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(repo.Tag("latest"), img); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	openapi := []byte(`{"openapi": "3.0.0"}`)
+	if err = os.WriteFile(filepath.Join(root, "openapi.json"), openapi, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "README.md"), []byte("# myfunc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := fn.Function{Root: root, Name: "myfunc"}
+	f.Build.Image = repo.Digest(digest.String()).String()
+	f.Build.Artifacts = []fn.ArtifactSpec{
+		{Path: "openapi.json", Type: "application/vnd.oai.openapi"},
+		{Path: "README.md"},
+	}
+
+	a := artifacts.NewAttacher()
+	attached, err := a.Attach(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attached) != 2 || attached[0].MediaType != "application/json" || attached[1].Type != fn.DefaultArtifactType {
+		t.Fatalf("unexpected artifacts attached: %+v", attached)
+	}
+
+	listed, err := a.Artifacts(context.Background(), repo.Tag("latest").String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]string{}
+	for _, l := range listed {
+		types[l.Path] = l.Type
+	}
+	if len(listed) != 2 || types["openapi.json"] != "application/vnd.oai.openapi" || types["README.md"] != fn.DefaultArtifactType {
+		t.Fatalf("unexpected artifacts listed: %+v", listed)
+	}
+
+	var b bytes.Buffer
+	if err = a.Fetch(context.Background(), f.Build.Image, attached[0], &b); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), openapi) {
+		t.Fatalf("unexpected artifact content %q", b.String())
+	}
+}
@@ -5,25 +5,32 @@ shared error types and convienience functions.
 package builders
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
+	"github.com/google/go-containerregistry/pkg/name"
+
 	fn "knative.dev/func/pkg/functions"
 )
 
 const (
-	Host    = "host"
-	Pack    = "pack"
-	S2I     = "s2i"
-	Default = Pack
+	BuildKit   = "buildkit"
+	Dockerfile = "dockerfile"
+	Host       = "host"
+	Kaniko     = "kaniko"
+	Pack       = "pack"
+	S2I        = "s2i"
+	Default    = Pack
 )
 
 // Known builder names with a pretty-printed string representation
 type Known []string
 
 func All() Known {
-	return Known([]string{Host, Pack, S2I})
+	return Known([]string{BuildKit, Dockerfile, Host, Kaniko, Pack, S2I})
 }
 
 func (k Known) String() string {
@@ -66,6 +73,16 @@ func (e ErrBuilderNotSupported) Error() string {
 	return fmt.Sprintf("builder %q is not supported", e.Builder)
 }
 
+// ErrSecretsNotSupported is returned by builders which cannot provide the
+// function's build secrets (build.secrets) without writing them to its image.
+type ErrSecretsNotSupported struct {
+	Builder string
+}
+
+func (e ErrSecretsNotSupported) Error() string {
+	return fmt.Sprintf("build secrets (build.secrets) are not supported by the %v builder. Use the buildkit or pack builder", e.Builder)
+}
+
 // ErrRuntimeRequired
 type ErrRuntimeRequired struct {
 	Builder string
@@ -86,7 +103,9 @@ func (e ErrNoDefaultImage) Error() string {
 }
 
 // Image is a convenience function for choosing the correct builder image
-// given a function, a builder, and defaults grouped by runtime.
+// given a function, a builder, and defaults grouped by runtime.  An image
+// defined for the builder in the function's BuilderImages is preferred,
+// followed by the function's BaseImage.
 //   - ErrRuntimeRequired if no runtime was provided on the given function
 //   - ErrNoDefaultImage if the function has no builder image already defined
 //     for the given runtime and there is no default in the provided map.
@@ -95,6 +114,9 @@ func Image(f fn.Function, builder string, defaults map[string]string) (string, e
 	if ok {
 		return v, nil // found value
 	}
+	if f.Build.BaseImage != "" {
+		return f.Build.BaseImage, nil
+	}
 	if f.Runtime == "" {
 		return "", ErrRuntimeRequired{Builder: builder}
 	}
@@ -103,5 +125,107 @@ func Image(f fn.Function, builder string, defaults map[string]string) (string, e
 		return v, nil // Found default
 	}
 	return "", ErrNoDefaultImage{Builder: builder, Runtime: f.Runtime}
+}
+
+// ErrBuilderNotAllowed is returned when a builder image is not among those
+// allowed by a builder's Policy.
+type ErrBuilderNotAllowed struct {
+	Builder string
+	Image   string
+	Allowed []string
+}
+
+func (e ErrBuilderNotAllowed) Error() string {
+	return fmt.Sprintf("builder image %q is not allowed for the '%v' builder by policy. Allowed images are: %v",
+		e.Image, e.Builder, strings.Join(e.Allowed, ", "))
+}
+
+// Policy for the builder images a builder may use, as defined in global
+// config by platform administrators.  The zero value allows all images and
+// defines no defaults.
+type Policy struct {
+	// Allowed builder images.  Each entry is either a prefix terminated with
+	// "/", allowing all images beneath it, a repository allowing any tag or
+	// digest thereof, or an image reference with a tag or digest which must
+	// match exactly.  Empty allows all images.
+	Allowed []string `yaml:"allowed,omitempty"`
+
+	// Defaults are builder images by runtime which take the place of the
+	// builder's own defaults.
+	Defaults map[string]string `yaml:"defaults,omitempty"`
+}
+
+// Image chooses the builder image as does the package-static Image, with
+// the Policy's defaults taking precedence over those provided; returning
+// ErrBuilderNotAllowed if the image chosen is not allowed.
+func (p Policy) Image(f fn.Function, builder string, defaults map[string]string) (string, error) {
+	if len(p.Defaults) > 0 {
+		merged := make(map[string]string, len(defaults)+len(p.Defaults))
+		for k, v := range defaults {
+			merged[k] = v
+		}
+		for k, v := range p.Defaults {
+			merged[k] = v
+		}
+		defaults = merged
+	}
+	image, err := Image(f, builder, defaults)
+	if err != nil {
+		return "", err
+	}
+	if !p.Allows(image) {
+		return "", ErrBuilderNotAllowed{Builder: builder, Image: image, Allowed: p.Allowed}
+	}
+	return image, nil
+}
+
+// Allows returns whether or not the image is allowed by the policy.
+func (p Policy) Allows(image string) bool {
+	if len(p.Allowed) == 0 {
+		return true
+	}
+	for _, entry := range p.Allowed {
+		if allows(entry, image) {
+			return true
+		}
+	}
+	return false
+}
 
+// allows returns whether or not a single allow-list entry permits the image.
+func allows(entry, image string) bool {
+	if strings.HasSuffix(entry, "/") {
+		return strings.HasPrefix(image, entry)
+	}
+	e, err := name.ParseReference(entry)
+	if err != nil {
+		return entry == image
+	}
+	i, err := name.ParseReference(image)
+	if err != nil || e.Context().Name() != i.Context().Name() {
+		return false
+	}
+	if !strings.ContainsAny(strings.TrimPrefix(entry, e.Context().RegistryStr()), ":@") {
+		return true // repository only: any tag or digest
+	}
+	if d, ok := e.(name.Digest); ok {
+		id, ok := i.(name.Digest)
+		return ok && id.DigestStr() == d.DigestStr()
+	}
+	return i.Identifier() == e.Identifier()
+}
+
+// BuildPlatforms builds the function for each of several platforms in turn,
+// as its fn.PlatformImage, for builders which build an image of a single
+// platform at a time.  The images are assembled into an index on push.
+func BuildPlatforms(ctx context.Context, f fn.Function, platforms []fn.Platform, build func(context.Context, fn.Function, []fn.Platform) error) error {
+	for _, p := range platforms {
+		pf := f
+		pf.Build.Image = fn.PlatformImage(f.Build.Image, p)
+		fmt.Fprintf(fn.BuildOutput(ctx, os.Stderr), "Building function image for %v\n", p)
+		if err := build(ctx, pf, []fn.Platform{p}); err != nil {
+			return fmt.Errorf("cannot build the function for %v: %w", p, err)
+		}
+	}
+	return nil
 }
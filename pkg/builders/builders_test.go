@@ -1,7 +1,10 @@
 package builders_test
 
 import (
+	"context"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
 	"knative.dev/func/pkg/builders"
@@ -70,6 +73,31 @@ func TestImage_Defaults(t *testing.T) {
 	}
 }
 
+// TestImage_BaseImage ensures that the function's base image is chosen in
+// preference to the defaults, but that an image named for the builder is
+// preferred over both.
+func TestImage_BaseImage(t *testing.T) {
+	defaults := map[string]string{
+		"go": "example.com/go/default-builder-image",
+	}
+	f := fn.Function{Runtime: "go", Build: fn.BuildSpec{BaseImage: "example.com/hardened/base"}}
+	builderImage, err := builders.Image(f, builders.Pack, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builderImage != "example.com/hardened/base" {
+		t.Fatalf("expected the base image, got '%v'", builderImage)
+	}
+
+	f.Build.BuilderImages = map[string]string{builders.Pack: "example.com/my/builder-image"}
+	if builderImage, err = builders.Image(f, builders.Pack, defaults); err != nil {
+		t.Fatal(err)
+	}
+	if builderImage != "example.com/my/builder-image" {
+		t.Fatalf("expected the named builder image, got '%v'", builderImage)
+	}
+}
+
 // Test_ErrUnknownBuilder ensures that the error properfly formats.
 // This error is used externally by packages which share builders but may
 // define their own custom builder, thus actually throwing this error
@@ -95,3 +123,90 @@ func Test_ErrUnknownBuilder(t *testing.T) {
 		}
 	}
 }
+
+// TestPolicy_Allows ensures allow-list entries match by prefix, by
+// repository, and exactly by tag or digest.
+func TestPolicy_Allows(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	p := builders.Policy{Allowed: []string{
+		"example.com/trusted/",
+		"example.com/builders/go",
+		"example.com/builders/node:20",
+		"example.com/builders/python@" + digest,
+	}}
+	tests := map[string]bool{
+		"example.com/trusted/any:latest":                                true,
+		"example.com/trustedother/any":                                  false,
+		"example.com/builders/go:1.23":                                  true,
+		"example.com/builders/go@" + digest:                             true,
+		"example.com/builders/gox":                                      false,
+		"example.com/builders/node:20":                                  true,
+		"example.com/builders/node:22":                                  false,
+		"example.com/builders/python@" + digest:                         true,
+		"example.com/builders/python:3@" + digest:                       true,
+		"example.com/builders/python:3":                                 false,
+		"example.com/builders/python@sha256:" + strings.Repeat("b", 64): false,
+		"docker.io/paketobuildpacks/builder":                            false,
+	}
+	for image, expected := range tests {
+		if p.Allows(image) != expected {
+			t.Errorf("expected allows(%v) to be %v", image, expected)
+		}
+	}
+	if !(builders.Policy{}).Allows("example.com/anything") {
+		t.Error("expected an empty allow-list to allow all images")
+	}
+}
+
+// TestPolicy_Image ensures the policy's defaults take precedence over those
+// of the builder, and that images not allowed are refused.
+func TestPolicy_Image(t *testing.T) {
+	defaults := map[string]string{
+		"go":   "example.com/go/default-builder-image",
+		"node": "example.com/node/default-builder-image",
+	}
+	p := builders.Policy{
+		Allowed:  []string{"example.com/hardened/"},
+		Defaults: map[string]string{"go": "example.com/hardened/go"},
+	}
+
+	image, err := p.Image(fn.Function{Runtime: "go"}, builders.Pack, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image != "example.com/hardened/go" {
+		t.Fatalf("expected the policy default, got %v", image)
+	}
+
+	var notAllowed builders.ErrBuilderNotAllowed
+	_, err = p.Image(fn.Function{Runtime: "node"}, builders.Pack, defaults)
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("expected ErrBuilderNotAllowed, got %v", err)
+	}
+	if notAllowed.Image != "example.com/node/default-builder-image" || notAllowed.Builder != builders.Pack {
+		t.Fatalf("unexpected error %v", notAllowed)
+	}
+}
+
+// TestBuildPlatforms ensures that a function is built for each platform in
+// turn, as the image of that platform.
+func TestBuildPlatforms(t *testing.T) {
+	f := fn.Function{Build: fn.BuildSpec{Image: "example.com/alice/f:latest"}}
+	platforms := []fn.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}
+
+	var built []string
+	err := builders.BuildPlatforms(context.Background(), f, platforms, func(_ context.Context, f fn.Function, pp []fn.Platform) error {
+		if len(pp) != 1 {
+			t.Fatalf("expected a single platform per build, got %v", pp)
+		}
+		built = append(built, f.Build.Image)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"example.com/alice/f:latest-linux-amd64", "example.com/alice/f:latest-linux-arm64"}
+	if !reflect.DeepEqual(built, want) {
+		t.Fatalf("expected images %v, got %v", want, built)
+	}
+}
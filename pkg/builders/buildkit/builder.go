@@ -0,0 +1,304 @@
+/*
+Package buildkit builds functions using a BuildKit daemon, which may be
+remote, such that functions can be built quickly without a local container
+engine.
+
+The daemon is addressed as with buildctl's --addr, for example
+tcp://buildkitd.example.com:1234, ssh://user@host, kube-pod://buildkitd or
+docker-container://buildkitd.  The resultant image is written to the
+function's build directory as an OCI layout, from which it is pushed by the
+OCI pusher as are images built by the host builder.
+*/
+package buildkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dockerconfig "github.com/docker/cli/cli/config"
+	bk "github.com/moby/buildkit/client"
+	_ "github.com/moby/buildkit/client/connhelper/dockercontainer" // docker-container://
+	_ "github.com/moby/buildkit/client/connhelper/kubepod"         // kube-pod://
+	_ "github.com/moby/buildkit/client/connhelper/ssh"             // ssh://
+	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
+	"github.com/moby/buildkit/util/progress/progressui"
+	"github.com/tonistiigi/fsutil"
+	"github.com/tonistiigi/fsutil/types"
+	"golang.org/x/sync/errgroup"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/scaffolding"
+)
+
+// DefaultName when no WithName option is provided to NewBuilder
+const DefaultName = builders.BuildKit
+
+// Builder of functions using a BuildKit daemon.
+type Builder struct {
+	name    string
+	verbose bool
+	plain   bool
+	address string
+	impl    Impl
+}
+
+// Impl allows for the underlying implementation to be mocked for tests.
+type Impl interface {
+	Solve(context.Context, *llb.Definition, bk.SolveOpt, chan *bk.SolveStatus) (*bk.SolveResponse, error)
+}
+
+type Option func(*Builder)
+
+func WithName(n string) Option {
+	return func(b *Builder) {
+		b.name = n
+	}
+}
+
+// WithVerbose toggles verbose logging, in which the output of each build
+// step is streamed in full.
+func WithVerbose(v bool) Option {
+	return func(b *Builder) {
+		b.verbose = v
+	}
+}
+
+// WithPlainProgress writes the progress of the build as plain lines rather
+// than as an animated display, regardless of the terminal.
+func WithPlainProgress(p bool) Option {
+	return func(b *Builder) {
+		b.plain = p
+	}
+}
+
+// WithAddress of the BuildKit daemon.
+func WithAddress(a string) Option {
+	return func(b *Builder) {
+		b.address = a
+	}
+}
+
+// WithImpl sets an optional implementation override to use in place of a
+// client of the daemon at the builder's address.  Used for mocking the
+// implementation during tests.
+func WithImpl(i Impl) Option {
+	return func(b *Builder) {
+		b.impl = i
+	}
+}
+
+// NewBuilder creates a new instance of a Builder with static defaults.
+func NewBuilder(options ...Option) *Builder {
+	b := &Builder{name: DefaultName}
+	for _, o := range options {
+		o(b)
+	}
+	return b
+}
+
+// Context returns the paths, relative to the function's root, of the files
+// which comprise the function's build context as sent to the daemon.
+func Context(f fn.Function) ([]string, error) {
+	ignorer, err := contextIgnorer(f)
+	if err != nil {
+		return nil, err
+	}
+	return ignorer.Context()
+}
+
+// contextIgnorer of the function's source.  Dependencies are installed by
+// the build, so those of the function are not sent.
+func contextIgnorer(f fn.Function) (fn.Ignorer, error) {
+	return fn.NewIgnorer(f.Root, "node_modules")
+}
+
+// Build the function using the BuildKit daemon, writing the image for each
+// of the given platforms (the platform of the daemon by default) as an OCI
+// layout to the function's last build directory.
+func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platform) (err error) {
+	args, err := fn.Interpolate(f.Build.BuildEnvs)
+	if err != nil {
+		return
+	}
+	df, err := dockerfile(f, b.name, args)
+	if err != nil {
+		return
+	}
+
+	// Build directory containing the Dockerfile, scaffolding and result
+	dir := filepath.Join(f.Root, fn.RunDataDir, "builds", b.name)
+	if err = os.RemoveAll(dir); err != nil {
+		return
+	}
+	if err = os.MkdirAll(dir, 0774); err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Join(dir, "dockerfile"), 0774); err != nil {
+		return
+	}
+	if err = os.WriteFile(filepath.Join(dir, "dockerfile", "Dockerfile"), df, 0644); err != nil {
+		return
+	}
+
+	// Local sources: the function itself, the directory containing the
+	// Dockerfile, and the scaffolding (if the runtime requires it), excluding
+	// the scaffolding's link to the function.
+	mounts := map[string]fsutil.FS{}
+	ignorer, err := contextIgnorer(f)
+	if err != nil {
+		return
+	}
+	if mounts["context"], err = contextFS(f.Root, ignorer); err != nil {
+		return
+	}
+	if mounts["dockerfile"], err = localFS(filepath.Join(dir, "dockerfile"), nil); err != nil {
+		return
+	}
+	if f.Runtime != "node" {
+		var repo fn.Repository
+		if repo, err = fn.NewRepository("", ""); err != nil {
+			return
+		}
+		scaffold := filepath.Join(dir, "scaffolding")
+		if err = scaffolding.Write(scaffold, f.Root, f.Runtime, f.Invoke, repo.FS()); err != nil {
+			return
+		}
+		if mounts["scaffolding"], err = localFS(scaffold, []string{"f"}); err != nil {
+			return
+		}
+	}
+
+	opt := bk.SolveOpt{
+		Frontend:      "dockerfile.v0",
+		FrontendAttrs: map[string]string{"filename": "Dockerfile"},
+		LocalMounts:   mounts,
+		Exports: []bk.ExportEntry{{
+			Type:      bk.ExporterOCI,
+			Attrs:     map[string]string{"name": f.Build.Image, "tar": "false"},
+			OutputDir: filepath.Join(dir, "oci"),
+		}},
+		Session: []session.Attachable{authprovider.NewDockerAuthProvider(
+			authprovider.DockerAuthProviderConfig{ConfigFile: dockerconfig.LoadDefaultConfigFile(os.Stderr)})},
+	}
+	// Build secrets are served to the build by the session rather than being
+	// of its context, so are not written to the image
+	if len(f.Build.Secrets) > 0 {
+		values := make(map[string][]byte, len(f.Build.Secrets))
+		for _, s := range f.Build.Secrets {
+			if values[s.ID], err = s.Value(f.Root); err != nil {
+				return
+			}
+		}
+		opt.Session = append(opt.Session, secretsprovider.FromMap(values))
+	}
+	if _, ok := mounts["scaffolding"]; ok {
+		opt.FrontendAttrs["context:scaffolding"] = "local:scaffolding"
+	}
+	for k, v := range args {
+		opt.FrontendAttrs["build-arg:"+k] = v
+	}
+	if len(platforms) > 0 {
+		pp := make([]string, len(platforms))
+		for i, p := range platforms {
+			pp[i] = strings.ToLower(p.OS + "/" + p.Architecture)
+			if p.Variant != "" {
+				pp[i] += "/" + p.Variant
+			}
+		}
+		opt.FrontendAttrs["platform"] = strings.Join(pp, ",")
+	}
+
+	impl := b.impl
+	if impl == nil {
+		if b.address == "" {
+			return ErrAddressRequired
+		}
+		var c *bk.Client
+		if c, err = bk.New(ctx, b.address); err != nil {
+			return fmt.Errorf("cannot connect to the BuildKit daemon at %v: %w", b.address, err)
+		}
+		defer c.Close()
+		impl = c
+	}
+
+	// Solve, streaming progress of the build
+	mode := progressui.AutoMode
+	if b.verbose || b.plain {
+		mode = progressui.PlainMode
+	}
+	display, err := progressui.NewDisplay(fn.BuildOutput(ctx, os.Stderr), mode)
+	if err != nil {
+		return
+	}
+	ch := make(chan *bk.SolveStatus)
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		_, err := impl.Solve(ctx, nil, opt, ch)
+		return err
+	})
+	eg.Go(func() error {
+		_, err := display.UpdateFrom(context.WithoutCancel(ctx), ch)
+		return err
+	})
+	if err = eg.Wait(); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		return fmt.Errorf("failed to build the function: %w", err)
+	}
+
+	// The pusher expects the OCI layout at .func/builds/last/oci
+	last := filepath.Join(f.Root, fn.RunDataDir, "builds", "last")
+	_ = os.RemoveAll(last)
+	return os.Symlink(b.name, last)
+}
+
+// contextFS of the function at root, excluding the files which are not of its
+// build context.
+func contextFS(root string, ignorer fn.Ignorer) (fsutil.FS, error) {
+	fs, err := fsutil.NewFS(root)
+	if err != nil {
+		return nil, err
+	}
+	return fsutil.NewFilterFS(fs, &fsutil.FilterOpt{
+		Map: func(path string, stat *types.Stat) fsutil.MapResult {
+			dir := os.FileMode(stat.Mode).IsDir()
+			if !ignorer.Ignored(path, dir) {
+				return fsutil.MapResultKeep
+			} else if dir {
+				return fsutil.MapResultSkipDir
+			}
+			return fsutil.MapResultExclude
+		},
+	})
+}
+
+// localFS of the directory at root, excluding the given patterns.
+func localFS(root string, excludes []string) (fsutil.FS, error) {
+	fs, err := fsutil.NewFS(root)
+	if err != nil {
+		return nil, err
+	}
+	return fsutil.NewFilterFS(fs, &fsutil.FilterOpt{ExcludePatterns: excludes})
+}
+
+// Errors
+
+// ErrAddressRequired is returned when no BuildKit daemon address is provided.
+var ErrAddressRequired = errors.New("the buildkit builder requires the address of a BuildKit daemon (set BUILDKIT_HOST or --buildkit-host)")
+
+type ErrRuntimeNotSupported struct {
+	Runtime string
+}
+
+func (e ErrRuntimeNotSupported) Error() string {
+	return fmt.Sprintf("the buildkit builder does not support the '%v' runtime", e.Runtime)
+}
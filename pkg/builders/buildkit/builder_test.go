@@ -0,0 +1,176 @@
+package buildkit_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bk "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/client/llb"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/builders/buildkit"
+	fn "knative.dev/func/pkg/functions"
+	. "knative.dev/func/pkg/testing"
+)
+
+type mockImpl struct {
+	SolveFn func(bk.SolveOpt) error
+}
+
+func (m *mockImpl) Solve(_ context.Context, _ *llb.Definition, opt bk.SolveOpt, ch chan *bk.SolveStatus) (*bk.SolveResponse, error) {
+	defer close(ch)
+	return &bk.SolveResponse{}, m.SolveFn(opt)
+}
+
+// TestBuild ensures the function's scaffolded source, Dockerfile, build
+// arguments and platforms are submitted to the daemon, and that the image
+// exported is that found by the OCI pusher.
+func TestBuild(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/f:latest"
+	name, value := "EXAMPLE", "example-value"
+	f.Build.BuildEnvs = []fn.Env{{Name: &name, Value: &value}}
+
+	i := &mockImpl{}
+	i.SolveFn = func(opt bk.SolveOpt) error {
+		for _, m := range []string{"context", "dockerfile", "scaffolding"} {
+			if opt.LocalMounts[m] == nil {
+				t.Errorf("expected local mount %q", m)
+			}
+		}
+		if opt.FrontendAttrs["build-arg:EXAMPLE"] != value {
+			t.Errorf("expected build arg, got %v", opt.FrontendAttrs)
+		}
+		if opt.FrontendAttrs["platform"] != "linux/amd64,linux/arm/v7" {
+			t.Errorf("unexpected platforms %q", opt.FrontendAttrs["platform"])
+		}
+		if len(opt.Exports) != 1 || opt.Exports[0].Type != bk.ExporterOCI {
+			t.Fatalf("expected an OCI export, got %v", opt.Exports)
+		}
+		if err := os.MkdirAll(opt.Exports[0].OutputDir, 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(opt.Exports[0].OutputDir, "index.json"), []byte("{}"), 0644)
+	}
+
+	b := buildkit.NewBuilder(buildkit.WithImpl(i))
+	platforms := []fn.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm", Variant: "v7"}}
+	if err = b.Build(context.Background(), f, platforms); err != nil {
+		t.Fatal(err)
+	}
+
+	dockerfile, err := os.ReadFile(filepath.Join(root, fn.RunDataDir, "builds", builders.BuildKit, "dockerfile", "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerfile), "ARG EXAMPLE") || !strings.Contains(string(dockerfile), "FROM scratch") {
+		t.Errorf("unexpected Dockerfile:\n%s", dockerfile)
+	}
+	if _, err = os.Stat(filepath.Join(root, fn.RunDataDir, "builds", "last", "oci", "index.json")); err != nil {
+		t.Fatalf("expected the image at the last build directory. %v", err)
+	}
+}
+
+// TestBuild_Base ensures the function's base image is that upon which it is
+// built.
+func TestBuild_Base(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.BaseImage = "example.com/hardened/node"
+
+	i := &mockImpl{SolveFn: func(opt bk.SolveOpt) error {
+		if opt.LocalMounts["scaffolding"] != nil {
+			t.Error("node functions should not be scaffolded")
+		}
+		return nil
+	}}
+	if err = buildkit.NewBuilder(buildkit.WithImpl(i)).Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+	dockerfile, err := os.ReadFile(filepath.Join(root, fn.RunDataDir, "builds", builders.BuildKit, "dockerfile", "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerfile), "FROM example.com/hardened/node") {
+		t.Errorf("expected the base image, got:\n%s", dockerfile)
+	}
+}
+
+// TestBuild_Secrets ensures the function's build secrets are served by the
+// session and mounted by the Dockerfile, not written to the build context.
+func TestBuild_Secrets(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "python"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FUNC_TEST_PYPI_TOKEN", "token")
+	f.Build.Secrets = []fn.SecretSpec{{ID: "pypi-token", Env: "FUNC_TEST_PYPI_TOKEN"}}
+
+	i := &mockImpl{SolveFn: func(opt bk.SolveOpt) error {
+		if len(opt.Session) != 2 {
+			t.Errorf("expected the secrets provider of the session, got %v", opt.Session)
+		}
+		return nil
+	}}
+	if err = buildkit.NewBuilder(buildkit.WithImpl(i)).Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+	dockerfile, err := os.ReadFile(filepath.Join(root, fn.RunDataDir, "builds", builders.BuildKit, "dockerfile", "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerfile), "RUN --mount=type=secret,id=pypi-token python -m pip") {
+		t.Errorf("expected the secret mounted, got:\n%s", dockerfile)
+	}
+
+	f.Build.Secrets[0].Env = "FUNC_TEST_MISSING_TOKEN"
+	if err = buildkit.NewBuilder(buildkit.WithImpl(i)).Build(context.Background(), f, nil); err == nil {
+		t.Fatal("expected an error building without the secret's value")
+	}
+}
+
+// TestBuild_Errors ensures that unsupported runtimes, a missing address and
+// build failures are reported.
+func TestBuild_Errors(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = buildkit.NewBuilder().Build(context.Background(), f, nil); !errors.Is(err, buildkit.ErrAddressRequired) {
+		t.Fatalf("expected ErrAddressRequired, got %v", err)
+	}
+
+	failure := errors.New("build failed")
+	i := &mockImpl{SolveFn: func(bk.SolveOpt) error { return failure }}
+	if err = buildkit.NewBuilder(buildkit.WithImpl(i)).Build(context.Background(), f, nil); !errors.Is(err, failure) {
+		t.Fatalf("expected the build failure, got %v", err)
+	}
+
+	f.Runtime = "rust"
+	var unsupported buildkit.ErrRuntimeNotSupported
+	if err = buildkit.NewBuilder(buildkit.WithImpl(i)).Build(context.Background(), f, nil); !errors.As(err, &unsupported) {
+		t.Fatalf("expected ErrRuntimeNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package buildkit
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+var DefaultGoBuilder = "golang:1.24"
+var DefaultPythonBase = "python:3.13-slim"
+var DefaultNodeBase = "node:22-slim"
+
+// dockerfileData are the values with which a runtime's Dockerfile template
+// is rendered.
+type dockerfileData struct {
+	Builder string   // image in which the function is compiled (Go)
+	Base    string   // image upon which the function is run
+	Args    []string // names of build environment variables
+	Main    string   // main module (Node)
+	Secrets []string // IDs of build secrets, mounted by each RUN instruction
+}
+
+// goDockerfile cross-compiles the scaffolded function on the platform of the
+// BuildKit daemon, and layers the statically linked binary upon the base,
+// which is empty by default.
+var goDockerfile = template.Must(template.New("go").Parse(`
+FROM --platform=$BUILDPLATFORM {{.Builder}} AS build
+ARG TARGETOS TARGETARCH
+{{range .Args}}ARG {{.}}
+{{end -}}
+WORKDIR /build
+COPY --from=scaffolding . .
+COPY . ./f
+RUN {{range .Secrets}}--mount=type=secret,id={{.}} {{end}}go mod tidy && CGO_ENABLED=0 GOOS=$TARGETOS GOARCH=$TARGETARCH go build -o /build/f.bin .
+
+FROM {{.Base}}
+COPY --from=scaffolding ca-certificates.crt /etc/ssl/certs/ca-certificates.crt
+COPY --chown=1000:1000 . /func/
+COPY --from=build --chown=1000:1000 /build/f.bin /func/f
+WORKDIR /func
+USER 1000:1000
+ENV LISTEN_ADDRESS=[::]:8080
+EXPOSE 8080
+CMD ["/func/f"]
+`))
+
+// pythonDockerfile installs the scaffolded function and its dependencies.
+var pythonDockerfile = template.Must(template.New("python").Parse(`
+FROM {{.Base}}
+{{range .Args}}ARG {{.}}
+{{end -}}
+COPY --from=scaffolding . /build/
+COPY . /build/f/
+RUN {{range .Secrets}}--mount=type=secret,id={{.}} {{end}}python -m pip install --no-cache-dir /build --target /build/lib
+WORKDIR /build
+USER 1000:1000
+ENV PYTHONPATH=/build/lib LISTEN_ADDRESS=[::]:8080
+EXPOSE 8080
+CMD ["python", "/build/service/main.py"]
+`))
+
+// nodeDockerfile installs the function's production dependencies, among
+// which is the faas-js-runtime which serves it.
+var nodeDockerfile = template.Must(template.New("node").Parse(`
+FROM {{.Base}}
+{{range .Args}}ARG {{.}}
+{{end -}}
+WORKDIR /func
+COPY --chown=1000:1000 . .
+RUN {{range .Secrets}}--mount=type=secret,id={{.}} {{end}}if [ -f package-lock.json ] || [ -f npm-shrinkwrap.json ]; then npm ci --omit=dev; else npm install --omit=dev; fi
+USER 1000:1000
+ENV NODE_ENV=production
+EXPOSE 8080
+CMD ["node", "/func/node_modules/faas-js-runtime/bin/cli.js", "/func/{{.Main}}"]
+`))
+
+// dockerfile for the given function, by runtime, built by the named builder
+// with the given build arguments.
+func dockerfile(f fn.Function, builder string, args map[string]string) ([]byte, error) {
+	var (
+		t    *template.Template
+		data = dockerfileData{}
+		err  error
+	)
+	for name := range args {
+		data.Args = append(data.Args, name)
+	}
+	sort.Strings(data.Args)
+	for _, s := range f.Build.Secrets {
+		data.Secrets = append(data.Secrets, s.ID)
+	}
+
+	switch f.Runtime {
+	case "go":
+		t = goDockerfile
+		if data.Builder = f.Build.BuilderImages[builder]; data.Builder == "" {
+			data.Builder = DefaultGoBuilder
+		}
+		data.Base = base(f, "scratch")
+	case "python":
+		t = pythonDockerfile
+		data.Base = image(f, builder, DefaultPythonBase)
+	case "node":
+		t = nodeDockerfile
+		data.Base = image(f, builder, DefaultNodeBase)
+		if data.Main, err = builders.NodeMain(f.Root, "index.js"); err != nil {
+			return nil, err
+		}
+	case "":
+		return nil, builders.ErrRuntimeRequired{Builder: builder}
+	default:
+		return nil, ErrRuntimeNotSupported{Runtime: f.Runtime}
+	}
+
+	var b bytes.Buffer
+	err = t.Execute(&b, data)
+	return b.Bytes(), err
+}
+
+// base image upon which the function is run: its run image, base image or
+// the given default.
+func base(f fn.Function, def string) string {
+	if f.Build.RunImage != "" {
+		return f.Build.RunImage
+	}
+	if f.Build.BaseImage != "" {
+		return f.Build.BaseImage
+	}
+	return def
+}
+
+// image in which the function is both built and run, preferring that named
+// for the builder.
+func image(f fn.Function, builder, def string) string {
+	if v := f.Build.BuilderImages[builder]; v != "" {
+		return v
+	}
+	return base(f, def)
+}
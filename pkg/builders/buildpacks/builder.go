@@ -3,10 +3,10 @@ package buildpacks
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -66,11 +66,14 @@ type Builder struct {
 	logger        logging.Logger
 	impl          Impl
 	withTimestamp bool
+	policy        builders.Policy
+	dependencies  builders.Dependencies
 }
 
 // Impl allows for the underlying implementation to be mocked for tests.
 type Impl interface {
 	Build(context.Context, pack.BuildOptions) error
+	Rebase(context.Context, pack.RebaseOptions) error
 }
 
 // NewBuilder instantiates a Buildpack-based Builder
@@ -116,41 +119,57 @@ func WithTimestamp(v bool) Option {
 	}
 }
 
+// WithPolicy restricts the builder images which may be used, and provides
+// defaults by runtime which take precedence over DefaultBuilderImages.
+func WithPolicy(p builders.Policy) Option {
+	return func(b *Builder) {
+		b.policy = p
+	}
+}
+
+// WithDependencies configures the package managers of builds with the
+// private registries of dependencies, and their credentials.
+func WithDependencies(d builders.Dependencies) Option {
+	return func(b *Builder) {
+		b.dependencies = d
+	}
+}
+
 var DefaultLifecycleImage = "docker.io/buildpacksio/lifecycle:553c041"
 
-// Build the Function at path.
+// Build the Function at path.  A platform requested is that for which pack
+// builds, its builder image being of that platform.  When several platforms
+// are requested, the function is built once for each.
 func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platform) (err error) {
-	if len(platforms) != 0 {
-		return errors.New("the pack builder does not support specifying target platforms directly")
+	if len(platforms) > 1 {
+		return builders.BuildPlatforms(ctx, f, platforms, b.Build)
 	}
 
-	// Builder image from the function if defined, default otherwise.
-	image, err := BuilderImage(f, b.name)
+	// Builder image from the function if defined, default otherwise; which
+	// must be allowed by policy.
+	image, err := b.policy.Image(f, b.name, DefaultBuilderImages)
 	if err != nil {
 		return
 	}
 
-	buildpacks := f.Build.Buildpacks
+	buildpacks := buildpackLocators(f)
 	if len(buildpacks) == 0 {
 		buildpacks = defaultBuildpacks[f.Runtime]
 	}
 
-	// Reading .funcignore file
-	var excludes []string
-	filePath := filepath.Join(f.Root, ".funcignore")
-	file, err := os.Open(filePath)
+	// Files excluded from the build context, listed as paths anchored to the
+	// function's root such that pack excludes exactly those of other builders.
+	ignorer, err := fn.NewIgnorer(f.Root)
 	if err != nil {
-		if !os.IsNotExist(err) {
-			return fmt.Errorf("\nfailed to open file: %s", err)
-		}
-	} else {
-		defer file.Close()
-		buf := new(bytes.Buffer)
-		_, err := io.Copy(buf, file)
-		if err != nil {
-			return fmt.Errorf("\nfailed to read file: %s", err)
-		}
-		excludes = strings.Split(buf.String(), "\n")
+		return
+	}
+	excluded, err := ignorer.Excluded()
+	if err != nil {
+		return fmt.Errorf("cannot determine the function's build context: %w", err)
+	}
+	excludes := make([]string, len(excluded))
+	for i, path := range excluded {
+		excludes[i] = "/" + path
 	}
 	// Pack build options
 	opts := pack.BuildOptions{
@@ -160,7 +179,10 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		Image:          f.Build.Image,
 		LifecycleImage: DefaultLifecycleImage,
 		Builder:        image,
+		RunImage:       f.Build.RunImage,
 		Buildpacks:     buildpacks,
+		// Paths of buildpacks declared by the function are relative to it
+		RelativeBaseDir: f.Root,
 		ProjectDescriptor: types.Descriptor{
 			Build: types.Build{
 				Exclude: excludes,
@@ -171,6 +193,14 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 			Volumes []string
 		}{Network: "", Volumes: nil},
 	}
+	if len(platforms) == 1 {
+		opts.Platform = platforms[0].String()
+	}
+	// The SBOMs of the buildpacks' layers are surfaced along with that of
+	// the function's dependencies (.func/sbom.json), when it is generated.
+	if f.Build.SBOM != "" {
+		opts.SBOMDestinationDir = filepath.Join(f.Root, fn.RunDataDir, "buildpacks-sbom")
+	}
 	if b.withTimestamp {
 		now := time.Now()
 		opts.CreationTime = &now
@@ -190,10 +220,51 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	for _, m := range f.Build.Mounts {
 		bindings = append(bindings, fmt.Sprintf("%s:%s", m.Source, m.Destination))
 	}
+
+	// Build secrets are written to a directory of their own, outside of the
+	// function, and mounted read-only, so are not of the image's layers.
+	if len(f.Build.Secrets) > 0 {
+		var dir string
+		if dir, err = os.MkdirTemp("", "func-secrets-"); err != nil {
+			return
+		}
+		defer os.RemoveAll(dir)
+		for _, s := range f.Build.Secrets {
+			var value []byte
+			if value, err = s.Value(f.Root); err != nil {
+				return
+			}
+			if err = os.WriteFile(filepath.Join(dir, s.ID), value, 0644); err != nil {
+				return
+			}
+			bindings = append(bindings, fmt.Sprintf("%s:%s:ro", filepath.Join(dir, s.ID), path.Join(fn.SecretsPath, s.ID)))
+		}
+	}
+
+	// The package managers' configuration of private registries, being of
+	// credentials, is likewise mounted rather than given as a build env.
+	// Those the function's build envs define take precedence.
+	mounts, env, done, err := b.dependencies.Mount()
+	if err != nil {
+		return
+	}
+	defer done()
+	for _, m := range mounts {
+		bindings = append(bindings, fmt.Sprintf("%s:%s:ro", m.Source, m.Destination))
+	}
+	for k, v := range env {
+		if _, ok := opts.Env[k]; !ok {
+			opts.Env[k] = v
+		}
+	}
 	opts.ContainerConfig.Volumes = bindings
 
-	// only trust our known builders
+	// only trust our known builders, unless the function says otherwise
 	opts.TrustBuilder = TrustBuilder
+	if f.Build.TrustBuilder != nil {
+		trusted := *f.Build.TrustBuilder
+		opts.TrustBuilder = func(string) bool { return trusted }
+	}
 
 	var impl = b.impl
 	// Instantiate the pack build client implementation
@@ -229,7 +300,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		}
 
 		// Client with a logger which is enabled if in Verbose mode and a dockerClient that supports SSH docker daemon connection.
-		if impl, err = pack.NewClient(pack.WithLogger(b.logger), pack.WithDockerClient(cli)); err != nil {
+		if impl, err = pack.NewClient(pack.WithLogger(b.phaseLogger(ctx)), pack.WithDockerClient(cli)); err != nil {
 			return fmt.Errorf("cannot create pack client: %w", err)
 		}
 	}
@@ -240,14 +311,145 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 			return // SIGINT
 		} else if b.verbose {
 			err = fmt.Errorf("failed to build the function: %w", err)
-			fmt.Fprintln(color.Stderr(), "")
-			_, _ = io.Copy(color.Stderr(), &b.outBuff)
-			fmt.Fprintln(color.Stderr(), "")
+			stderr := fn.BuildOutput(ctx, color.Stderr())
+			fmt.Fprintln(stderr, "")
+			_, _ = io.Copy(stderr, &b.outBuff)
+			fmt.Fprintln(stderr, "")
 		}
 	}
 	return
 }
 
+// phaseLogger is the builder's logger, which also reports each phase of the
+// lifecycle as it starts to the context's func(fn.DeployEvent), if any.
+func (b *Builder) phaseLogger(ctx context.Context) logging.Logger {
+	phases := &phaseWriter{ctx: ctx}
+	if b.verbose {
+		return logging.NewLogWithWriters(io.MultiWriter(fn.BuildOutput(ctx, color.Stdout()), phases), fn.BuildOutput(ctx, color.Stderr()), logging.WithVerbose())
+	}
+	return logging.NewSimpleLogger(io.MultiWriter(&b.outBuff, phases))
+}
+
+// lifecyclePhases by the step of the lifecycle's log which starts each, for
+// example "===> DETECTING".
+var lifecyclePhases = map[string]string{
+	"DETECTING": fn.PhaseDetect,
+	"ANALYZING": fn.PhaseAnalyze,
+	"RESTORING": fn.PhaseRestore,
+	"BUILDING":  fn.PhaseBuild,
+	"EXPORTING": fn.PhaseExport,
+}
+
+// phaseWriter reports the phase started by each step of the lifecycle's log
+// written to it.
+type phaseWriter struct {
+	ctx  context.Context
+	line []byte
+}
+
+func (w *phaseWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(w.line[:i])
+		w.line = w.line[i+1:]
+		if _, step, ok := strings.Cut(line, "===> "); ok {
+			if phase, ok := lifecyclePhases[strings.TrimSpace(stripANSI(step))]; ok {
+				fn.ReportDeployEvent(w.ctx, fn.DeployEvent{Stage: fn.StagePhase, Phase: phase})
+			}
+		}
+	}
+}
+
+// ansiRegex matches the escape sequences by which pack colors its log.
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripANSI(s string) string {
+	return ansiRegex.ReplaceAllString(s, "")
+}
+
+// Rebase the function's built image in the daemon upon its run image, such
+// as a newer patch version of that upon which it was built, replacing the
+// layers of the run image alone.  Implements fn.Rebaser.
+func (b *Builder) Rebase(ctx context.Context, f fn.Function) (err error) {
+	opts := pack.RebaseOptions{
+		RepoName: f.Build.Image,
+		RunImage: f.Build.RunImage,
+	}
+	impl := b.impl
+	if impl == nil {
+		var cli client.APIClient
+		if cli, _, err = docker.NewClient(client.DefaultDockerHost); err != nil {
+			return fmt.Errorf("cannot create docker client: %w", err)
+		}
+		defer cli.Close()
+		if impl, err = pack.NewClient(pack.WithLogger(b.logger), pack.WithDockerClient(cli)); err != nil {
+			return fmt.Errorf("cannot create pack client: %w", err)
+		}
+	}
+	if err = impl.Rebase(ctx, opts); err != nil && !b.verbose {
+		_, _ = io.Copy(color.Stderr(), &b.outBuff)
+	}
+	return
+}
+
+// Caches of the function retained by pack: the volumes of the build and
+// launch layers of its image, and the builder and lifecycle images, which
+// are shared by all functions.
+func (b *Builder) Caches(ctx context.Context, f fn.Function) (cc []fn.BuildCache, err error) {
+	cli, _, err := docker.NewClient(client.DefaultDockerHost)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create docker client: %w", err)
+	}
+	defer cli.Close()
+
+	if repo := f.CacheRepository(); repo != "" {
+		if cc, err = docker.VolumeCaches(ctx, cli, b.name, cacheVolumes(repo)); err != nil {
+			return
+		}
+	}
+	builder, _ := b.policy.Image(f, b.name, DefaultBuilderImages) // none if of an unknown runtime
+	images, err := docker.ImageCaches(ctx, cli, b.name, builder, DefaultLifecycleImage)
+	return append(cc, images...), err
+}
+
+// Remove the cache volume or image.
+func (b *Builder) Remove(ctx context.Context, c fn.BuildCache) error {
+	cli, _, err := docker.NewClient(client.DefaultDockerHost)
+	if err != nil {
+		return fmt.Errorf("cannot create docker client: %w", err)
+	}
+	defer cli.Close()
+	if c.Kind == fn.CacheImage {
+		return docker.RemoveImageCache(ctx, cli, c.Name)
+	}
+	return docker.RemoveVolumeCache(ctx, cli, c.Name)
+}
+
+// buildpackLocators of the buildpacks declared by the function, as given to
+// pack.  Those declared by file:// URIs, which pack requires be absolute, are
+// given as the paths of the function to which they are relative.
+func buildpackLocators(f fn.Function) []string {
+	locators := make([]string, len(f.Build.Buildpacks))
+	for i, b := range f.Build.Buildpacks {
+		if p := fn.BuildpackPath(f.Root, b); p != "" {
+			b = p
+		}
+		locators[i] = b
+	}
+	return locators
+}
+
+// cacheVolumes matches the names pack gives the cache volumes of images of
+// the repository: the repository less its registry, with slashes as
+// underscores, the image's tag, a hash of a key and the kind of cache.
+func cacheVolumes(repo string) *regexp.Regexp {
+	return regexp.MustCompile(`^pack-cache-` + regexp.QuoteMeta(strings.ReplaceAll(repo, "/", "_")) + `_\w[\w.-]*-[0-9a-f]{12}\.(build|launch)$`)
+}
+
 func isPodmanV43(ctx context.Context, cli client.APIClient) (b bool, err error) {
 	version, err := cli.ServerVersion(ctx)
 	if err != nil {
@@ -2,9 +2,11 @@ package buildpacks
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	pack "github.com/buildpacks/pack/pkg/client"
@@ -103,6 +105,50 @@ func TestBuild_BuildpacksDefault(t *testing.T) {
 
 }
 
+// TestBuild_Buildpacks ensures that the buildpacks declared by the function
+// are given to pack in order, those of file:// URIs as paths of the function,
+// with relative paths resolved against the function.
+func TestBuild_Buildpacks(t *testing.T) {
+	var (
+		i = &mockImpl{}
+		b = NewBuilder(WithImpl(i))
+		f = fn.Function{
+			Root:    "/func",
+			Runtime: "go",
+			Build: fn.BuildSpec{
+				Buildpacks: []string{
+					"docker://registry.example.com/buildpacks/apm:1",
+					"file://./buildpacks/compliance",
+					"file:///opt/buildpacks/audit",
+					"from=builder",
+					"./buildpacks/local",
+				},
+			},
+		}
+	)
+
+	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
+		expected := []string{
+			"docker://registry.example.com/buildpacks/apm:1",
+			filepath.Join("/func", "buildpacks", "compliance"),
+			filepath.FromSlash("/opt/buildpacks/audit"),
+			"from=builder",
+			"./buildpacks/local",
+		}
+		if !reflect.DeepEqual(expected, opts.Buildpacks) {
+			t.Fatalf("expected buildpacks '%v', got '%v'", expected, opts.Buildpacks)
+		}
+		if opts.RelativeBaseDir != "/func" {
+			t.Fatalf("expected buildpacks relative to the function, got %q", opts.RelativeBaseDir)
+		}
+		return nil
+	}
+
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestBuild_BuilderImageConfigurable ensures that the builder will use the builder
 // image defined on the given Function if provided.
 func TestBuild_BuilderImageConfigurable(t *testing.T) {
@@ -133,6 +179,96 @@ func TestBuild_BuilderImageConfigurable(t *testing.T) {
 	}
 }
 
+// TestBuild_BaseAndRunImage ensures that the function's base image is used
+// as the builder and its run image as the run image of the pack build.
+func TestBuild_BaseAndRunImage(t *testing.T) {
+	var (
+		i = &mockImpl{}
+		b = NewBuilder(WithName(builders.Pack), WithImpl(i))
+		f = fn.Function{
+			Runtime: "node",
+			Build: fn.BuildSpec{
+				BaseImage: "example.com/hardened/builder",
+				RunImage:  "example.com/hardened/run",
+			},
+		}
+	)
+
+	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
+		if opts.Builder != f.Build.BaseImage {
+			t.Errorf("expected builder image '%v', got '%v'", f.Build.BaseImage, opts.Builder)
+		}
+		if opts.RunImage != f.Build.RunImage {
+			t.Errorf("expected run image '%v', got '%v'", f.Build.RunImage, opts.RunImage)
+		}
+		return nil
+	}
+
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestBuild_TrustBuilder ensures that the function's trustBuilder overrides
+// whether the builder image is trusted, which is otherwise by its publisher.
+func TestBuild_TrustBuilder(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		trust *bool
+		image string
+		want  bool
+	}{
+		{"default untrusted", nil, "example.com/builder", false},
+		{"default trusted", nil, DefaultBaseBuilder, true},
+		{"trusted", &[]bool{true}[0], "example.com/builder", true},
+		{"untrusted", &[]bool{false}[0], DefaultBaseBuilder, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			i := &mockImpl{}
+			b := NewBuilder(WithName(builders.Pack), WithImpl(i))
+			f := fn.Function{Runtime: "node", Build: fn.BuildSpec{BaseImage: tc.image, TrustBuilder: tc.trust}}
+			i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
+				if got := opts.TrustBuilder(opts.Builder); got != tc.want {
+					t.Errorf("expected builder image %v trusted to be %v, got %v", tc.image, tc.want, got)
+				}
+				return nil
+			}
+			if err := b.Build(context.Background(), f, nil); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestBuild_Policy ensures that the policy's default builder image for the
+// runtime is used, and that builder images it does not allow are refused.
+func TestBuild_Policy(t *testing.T) {
+	var (
+		i = &mockImpl{}
+		b = NewBuilder(WithName(builders.Pack), WithImpl(i), WithPolicy(builders.Policy{
+			Allowed:  []string{"example.com/hardened/"},
+			Defaults: map[string]string{"node": "example.com/hardened/node-builder"},
+		}))
+		f = fn.Function{Runtime: "node"}
+	)
+
+	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
+		if opts.Builder != "example.com/hardened/node-builder" {
+			t.Errorf("expected the policy default builder image, got '%v'", opts.Builder)
+		}
+		return nil
+	}
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Build.BuilderImages = map[string]string{builders.Pack: "example.com/untrusted/builder"}
+	var notAllowed builders.ErrBuilderNotAllowed
+	if err := b.Build(context.Background(), f, nil); !errors.As(err, &notAllowed) {
+		t.Fatalf("expected ErrBuilderNotAllowed, got %v", err)
+	}
+}
+
 // TestBuild_BuilderImageExclude ensures that ignored files are not added to the func
 // image
 func TestBuild_BuilderImageExclude(t *testing.T) {
@@ -145,8 +281,9 @@ func TestBuild_BuilderImageExclude(t *testing.T) {
 		}
 	)
 	funcIgnoreContent := []byte(`#testing comments
-hello.txt`)
-	expected := []string{"hello.txt"}
+*.txt
+!keep.txt`)
+	expected := []string{"/.funcignore", "/hello.txt"}
 
 	tempdir := t.TempDir()
 	f.Root = tempdir
@@ -156,13 +293,15 @@ hello.txt`)
 	if err != nil {
 		t.Fatal(err)
 	}
+	for _, name := range []string{"hello.txt", "keep.txt"} {
+		if err = os.WriteFile(filepath.Join(f.Root, name), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
-		if len(opts.ProjectDescriptor.Build.Exclude) != 2 {
-			t.Fatalf("expected 2 lines of exclusions , got %v", len(opts.ProjectDescriptor.Build.Exclude))
-		}
-		if opts.ProjectDescriptor.Build.Exclude[1] != expected[0] {
-			t.Fatalf("expected excluded file to be '%v', got '%v'", expected[1], opts.ProjectDescriptor.Build.Exclude[1])
+		if !reflect.DeepEqual(opts.ProjectDescriptor.Build.Exclude, expected) {
+			t.Fatalf("expected exclusions %v, got %v", expected, opts.ProjectDescriptor.Build.Exclude)
 		}
 		return nil
 	}
@@ -204,6 +343,101 @@ func TestBuild_Envs(t *testing.T) {
 	}
 }
 
+// TestBuild_Secrets ensures that build secrets are mounted read-only into
+// the build container, from files which are removed once it is built.
+func TestBuild_Secrets(t *testing.T) {
+	t.Setenv("FUNC_TEST_NPM_TOKEN", "token")
+	var (
+		f = fn.Function{
+			Runtime: "node",
+			Build: fn.BuildSpec{
+				Secrets: []fn.SecretSpec{{ID: "npm-token", Env: "FUNC_TEST_NPM_TOKEN"}},
+			},
+		}
+		i      = &mockImpl{}
+		b      = NewBuilder(WithImpl(i))
+		source string
+	)
+	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
+		if len(opts.ContainerConfig.Volumes) != 1 {
+			t.Fatalf("expected the secret mounted, got %v", opts.ContainerConfig.Volumes)
+		}
+		parts := strings.Split(opts.ContainerConfig.Volumes[0], ":")
+		if len(parts) != 3 || parts[1] != "/run/secrets/npm-token" || parts[2] != "ro" {
+			t.Fatalf("unexpected mount %v", opts.ContainerConfig.Volumes[0])
+		}
+		source = parts[0]
+		if data, err := os.ReadFile(source); err != nil || string(data) != "token" {
+			t.Fatalf("expected the secret's value, got %q (%v)", data, err)
+		}
+		return nil
+	}
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(source); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the secret's file to be removed, got %v", err)
+	}
+}
+
+// TestBuild_Dependencies ensures that the configuration of private registries
+// of dependencies is mounted read-only into the build container, and the
+// build envs by which package managers read it are set.
+func TestBuild_Dependencies(t *testing.T) {
+	var (
+		f = fn.Function{Runtime: "node"}
+		d = builders.Dependencies{NPM: &builders.NPMRegistry{URL: "https://npm.example.com", Token: "token"}}
+		i = &mockImpl{}
+		b = NewBuilder(WithImpl(i), WithDependencies(d))
+	)
+	i.BuildFn = func(ctx context.Context, opts pack.BuildOptions) error {
+		if len(opts.ContainerConfig.Volumes) != 1 {
+			t.Fatalf("expected the npmrc mounted, got %v", opts.ContainerConfig.Volumes)
+		}
+		parts := strings.Split(opts.ContainerConfig.Volumes[0], ":")
+		if len(parts) != 3 || parts[1] != "/run/func/dependencies/npmrc" || parts[2] != "ro" {
+			t.Fatalf("unexpected mount %v", opts.ContainerConfig.Volumes[0])
+		}
+		if data, err := os.ReadFile(parts[0]); err != nil || !strings.Contains(string(data), ":_authToken=token") {
+			t.Fatalf("expected the npmrc of the token, got %q (%v)", data, err)
+		}
+		if opts.Env["NPM_CONFIG_USERCONFIG"] != "/run/func/dependencies/npmrc" {
+			t.Fatalf("expected NPM_CONFIG_USERCONFIG, got %v", opts.Env)
+		}
+		for _, v := range opts.Env {
+			if strings.Contains(v, "token") {
+				t.Fatalf("expected no credentials in the build envs, got %v", opts.Env)
+			}
+		}
+		return nil
+	}
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRebase ensures that the function's built image is rebased upon its
+// run image.
+func TestRebase(t *testing.T) {
+	var (
+		f = fn.Function{
+			Runtime: "go",
+			Build:   fn.BuildSpec{Image: "example.com/alice/f:latest", RunImage: "example.com/run:1.0.1"},
+		}
+		i = &mockImpl{}
+		b = NewBuilder(WithImpl(i))
+	)
+	i.RebaseFn = func(_ context.Context, opts pack.RebaseOptions) error {
+		if opts.RepoName != f.Build.Image || opts.RunImage != f.Build.RunImage {
+			t.Fatalf("expected %v rebased upon %v, got %+v", f.Build.Image, f.Build.RunImage, opts)
+		}
+		return nil
+	}
+	if err := b.Rebase(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestBuild_Errors confirms error scenarios.
 func TestBuild_Errors(t *testing.T) {
 	testCases := []struct {
@@ -233,9 +467,50 @@ func TestBuild_Errors(t *testing.T) {
 }
 
 type mockImpl struct {
-	BuildFn func(context.Context, pack.BuildOptions) error
+	BuildFn  func(context.Context, pack.BuildOptions) error
+	RebaseFn func(context.Context, pack.RebaseOptions) error
 }
 
 func (i mockImpl) Build(ctx context.Context, opts pack.BuildOptions) error {
 	return i.BuildFn(ctx, opts)
 }
+
+func (i mockImpl) Rebase(ctx context.Context, opts pack.RebaseOptions) error {
+	return i.RebaseFn(ctx, opts)
+}
+
+// TestBuild_CacheVolumes ensures that the cache volumes pack names for the
+// function's repository are matched, of any tag, and not those of others.
+func TestBuild_CacheVolumes(t *testing.T) {
+	match := cacheVolumes("alice/myfunc")
+	for name, want := range map[string]bool{
+		"pack-cache-alice_myfunc_latest-0123456789ab.build":              true,
+		"pack-cache-alice_myfunc_latest-linux-arm64-0123456789ab.launch": true,
+		"pack-cache-alice_other_latest-0123456789ab.build":               false,
+		"pack-cache-alice_myfunc_latest-0123456789ab.other":              false,
+	} {
+		if match.MatchString(name) != want {
+			t.Errorf("expected match of %v to be %v", name, want)
+		}
+	}
+}
+
+// TestBuild_Phases ensures the phases of the lifecycle are reported as its
+// log starts each, however the log is written.
+func TestBuild_Phases(t *testing.T) {
+	var phases []string
+	ctx := context.WithValue(context.Background(), fn.DeployEventsKey{}, func(e fn.DeployEvent) {
+		phases = append(phases, e.Phase)
+	})
+	w := &phaseWriter{ctx: ctx}
+	log := "===> ANALYZING\nPrevious image not found\n===> DETECTING\n\x1b[36m===> RESTORING\x1b[0m\n[builder] ===> BUILDING\n===> UNKNOWN\n===> EXPORTING\n"
+	for _, chunk := range []string{log[:10], log[10:40], log[40:]} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{fn.PhaseAnalyze, fn.PhaseDetect, fn.PhaseRestore, fn.PhaseBuild, fn.PhaseExport}
+	if !reflect.DeepEqual(phases, expected) {
+		t.Fatalf("expected phases %v, got %v", expected, phases)
+	}
+}
@@ -0,0 +1,238 @@
+package builders
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// DependenciesPath is the directory of the build container at which the
+// files configuring package managers with the credentials of private
+// registries of dependencies are mounted, read-only.  Being mounted, they
+// are not of the layers of the image built.
+const DependenciesPath = "/run/func/dependencies"
+
+// Dependencies are the private registries of dependencies, and their
+// credentials, from which the package managers of functions' builds install
+// dependencies.  Defined in global config, and by environment variables (see
+// WithEnv), they are of the user building rather than of the function.
+type Dependencies struct {
+	NPM   *NPMRegistry     `yaml:"npm,omitempty"`
+	Pip   *PipIndex        `yaml:"pip,omitempty"`
+	Maven *MavenRepository `yaml:"maven,omitempty"`
+	Go    *GoProxy         `yaml:"go,omitempty"`
+}
+
+// NPMRegistry from which npm installs packages, authenticated by its token.
+type NPMRegistry struct {
+	URL   string `yaml:"url,omitempty"`
+	Token string `yaml:"token,omitempty"`
+}
+
+// PipIndex from which pip installs packages, in place of PyPI.
+type PipIndex struct {
+	URL      string `yaml:"url,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// MavenRepository which mirrors all others for Maven.
+type MavenRepository struct {
+	URL      string `yaml:"url,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// GoProxy from which Go modules are downloaded (GOPROXY).  NoSumDB are the
+// patterns of the paths of private modules, which are not checked against
+// the public checksum database (GONOSUMDB).
+type GoProxy struct {
+	URL      string `yaml:"url,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	NoSumDB  string `yaml:"noSumDB,omitempty"`
+}
+
+// WithEnv returns the dependencies with those defined by environment
+// variables, as read by getenv, taking precedence:
+//
+//	FUNC_NPM_REGISTRY, FUNC_NPM_TOKEN
+//	FUNC_PIP_INDEX_URL, FUNC_PIP_USERNAME, FUNC_PIP_PASSWORD
+//	FUNC_MAVEN_URL, FUNC_MAVEN_USERNAME, FUNC_MAVEN_PASSWORD
+//	FUNC_GOPROXY, FUNC_GOPROXY_USERNAME, FUNC_GOPROXY_PASSWORD, FUNC_GONOSUMDB
+func (d Dependencies) WithEnv(getenv func(string) string) Dependencies {
+	// set the field to the variable, if defined, allocating the registry of
+	// the field upon the first defined.
+	set := func(field *string, name string, alloc func()) {
+		if v := getenv(name); v != "" {
+			alloc()
+			*field = v
+		}
+	}
+	npm := valueOf(d.NPM)
+	set(&npm.URL, "FUNC_NPM_REGISTRY", func() { d.NPM = &npm })
+	set(&npm.Token, "FUNC_NPM_TOKEN", func() { d.NPM = &npm })
+	pip := valueOf(d.Pip)
+	set(&pip.URL, "FUNC_PIP_INDEX_URL", func() { d.Pip = &pip })
+	set(&pip.Username, "FUNC_PIP_USERNAME", func() { d.Pip = &pip })
+	set(&pip.Password, "FUNC_PIP_PASSWORD", func() { d.Pip = &pip })
+	maven := valueOf(d.Maven)
+	set(&maven.URL, "FUNC_MAVEN_URL", func() { d.Maven = &maven })
+	set(&maven.Username, "FUNC_MAVEN_USERNAME", func() { d.Maven = &maven })
+	set(&maven.Password, "FUNC_MAVEN_PASSWORD", func() { d.Maven = &maven })
+	goproxy := valueOf(d.Go)
+	set(&goproxy.URL, "FUNC_GOPROXY", func() { d.Go = &goproxy })
+	set(&goproxy.Username, "FUNC_GOPROXY_USERNAME", func() { d.Go = &goproxy })
+	set(&goproxy.Password, "FUNC_GOPROXY_PASSWORD", func() { d.Go = &goproxy })
+	set(&goproxy.NoSumDB, "FUNC_GONOSUMDB", func() { d.Go = &goproxy })
+	return d
+}
+
+// valueOf the pointer, or the zero value if nil.
+func valueOf[T any](p *T) (v T) {
+	if p != nil {
+		v = *p
+	}
+	return
+}
+
+// Files configuring the package manager of each registry defined, by their
+// names beneath DependenciesPath, and the environment variables of the build
+// by which the package managers read them.  Both are empty if no registries
+// are defined.
+func (d Dependencies) Files() (files map[string][]byte, env map[string]string, err error) {
+	files, env = map[string][]byte{}, map[string]string{}
+	if d.NPM != nil {
+		u, err := parseURL("npm registry", d.NPM.URL, "https://registry.npmjs.org/")
+		if err != nil {
+			return nil, nil, err
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		npmrc := fmt.Sprintf("registry=%v\n", u)
+		if d.NPM.Token != "" {
+			npmrc += fmt.Sprintf("//%v%v:_authToken=%v\n", u.Host, u.Path, d.NPM.Token)
+		}
+		files["npmrc"] = []byte(npmrc)
+		env["NPM_CONFIG_USERCONFIG"] = path.Join(DependenciesPath, "npmrc")
+	}
+	if d.Pip != nil {
+		u, err := parseURL("pip index", d.Pip.URL, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if d.Pip.Username != "" {
+			u.User = url.UserPassword(d.Pip.Username, d.Pip.Password)
+		}
+		files["pip.conf"] = []byte(fmt.Sprintf("[global]\nindex-url = %v\n", u))
+		env["PIP_CONFIG_FILE"] = path.Join(DependenciesPath, "pip.conf")
+	}
+	if d.Maven != nil {
+		u, err := parseURL("maven repository", d.Maven.URL, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if files["settings.xml"], err = mavenSettings(u.String(), d.Maven.Username, d.Maven.Password); err != nil {
+			return nil, nil, err
+		}
+		settings := path.Join(DependenciesPath, "settings.xml")
+		env["BP_MAVEN_SETTINGS_PATH"] = settings    // buildpacks
+		env["MAVEN_ARGS_APPEND"] = "-s " + settings // s2i
+	}
+	if d.Go != nil {
+		u, err := parseURL("go proxy", d.Go.URL, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		env["GOPROXY"] = u.String()
+		if d.Go.Username != "" {
+			files["netrc"] = []byte(fmt.Sprintf("machine %v login %v password %v\n", u.Hostname(), d.Go.Username, d.Go.Password))
+			env["NETRC"] = path.Join(DependenciesPath, "netrc")
+		}
+		if d.Go.NoSumDB != "" {
+			env["GONOSUMDB"] = d.Go.NoSumDB
+		}
+	}
+	return
+}
+
+// Mount writes the files of the dependencies to a temporary directory,
+// readable only by the user, returning the mounts of each in the build
+// container and the environment of the build.  The directory is removed by
+// calling done, which is never nil.
+func (d Dependencies) Mount() (mounts []fn.MountSpec, env map[string]string, done func(), err error) {
+	done = func() {}
+	files, env, err := d.Files()
+	if err != nil || len(files) == 0 {
+		return
+	}
+	dir, err := os.MkdirTemp("", "func-dependencies-")
+	if err != nil {
+		return
+	}
+	done = func() { os.RemoveAll(dir) }
+	for name, data := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			done()
+			return nil, nil, func() {}, err
+		}
+		mounts = append(mounts, fn.MountSpec{Source: filepath.Join(dir, name), Destination: path.Join(DependenciesPath, name)})
+	}
+	return
+}
+
+// parseURL of the registry described, or its default if empty, which must
+// be absolute.
+func parseURL(description, s, defaultURL string) (*url.URL, error) {
+	if s == "" {
+		s = defaultURL
+	}
+	if s == "" {
+		return nil, fmt.Errorf("the url of the %v is required", description)
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of the %v: %w", description, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid url of the %v: %v is not absolute", description, s)
+	}
+	return u, nil
+}
+
+// mavenSettings mirroring all repositories with that at url, authenticated
+// by the username and password if given.
+func mavenSettings(url, username, password string) ([]byte, error) {
+	type server struct {
+		ID       string `xml:"id"`
+		Username string `xml:"username"`
+		Password string `xml:"password"`
+	}
+	type mirror struct {
+		ID       string `xml:"id"`
+		MirrorOf string `xml:"mirrorOf"`
+		URL      string `xml:"url"`
+	}
+	settings := struct {
+		XMLName xml.Name `xml:"settings"`
+		Servers []server `xml:"servers>server,omitempty"`
+		Mirrors []mirror `xml:"mirrors>mirror"`
+	}{
+		Mirrors: []mirror{{ID: "func-dependencies", MirrorOf: "*", URL: url}},
+	}
+	if username != "" {
+		settings.Servers = []server{{ID: "func-dependencies", Username: username, Password: password}}
+	}
+	data, err := xml.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
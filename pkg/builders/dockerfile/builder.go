@@ -0,0 +1,313 @@
+/*
+Package dockerfile builds functions from a Dockerfile of their own, using the
+container engine's daemon, for functions whose image is not that of a
+supported runtime, or which require more of their image than the other
+builders allow.
+
+The function's Dockerfile is built as is, less that its final stage is
+labeled with the function's metadata, and listens by default on the port
+expected of functions (8080).  The resultant image is that of the daemon,
+from which it is pushed by the docker pusher as are images built by pack and
+s2i.
+*/
+package dockerfile
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/build"
+	dockerClient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"golang.org/x/term"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s/labels"
+)
+
+// DefaultName when no WithName option is provided to NewBuilder
+const DefaultName = builders.Dockerfile
+
+// DefaultDockerfile is the path, relative to the function's root, of the
+// Dockerfile built when the function does not define one.
+const DefaultDockerfile = "Dockerfile"
+
+// AmendedPath is the path within the build context of the function's
+// Dockerfile as amended by Amend.  The function's .func directory is never
+// of its context, so the path cannot collide with its files.
+const AmendedPath = fn.RunDataDir + "/Dockerfile"
+
+// Builder of functions from their Dockerfile.
+type Builder struct {
+	name    string
+	verbose bool
+	cli     DockerClient
+}
+
+// DockerClient is the subset of the docker API with which images are built.
+type DockerClient interface {
+	ImageBuild(ctx context.Context, buildContext io.Reader, options build.ImageBuildOptions) (build.ImageBuildResponse, error)
+}
+
+type Option func(*Builder)
+
+func WithName(n string) Option {
+	return func(b *Builder) {
+		b.name = n
+	}
+}
+
+// WithVerbose toggles verbose logging, in which the output of the daemon is
+// streamed in full rather than only when the build fails.
+func WithVerbose(v bool) Option {
+	return func(b *Builder) {
+		b.verbose = v
+	}
+}
+
+// WithDockerClient sets the client of the daemon in place of that of the
+// environment (DOCKER_HOST).  Used for mocking the daemon during tests.
+func WithDockerClient(c DockerClient) Option {
+	return func(b *Builder) {
+		b.cli = c
+	}
+}
+
+// NewBuilder creates a new instance of a Builder with static defaults.
+func NewBuilder(options ...Option) *Builder {
+	b := &Builder{name: DefaultName}
+	for _, o := range options {
+		o(b)
+	}
+	return b
+}
+
+// Build the function's image from its Dockerfile (f.Build.Dockerfile), tagged
+// as f.Build.Image in the daemon.  Several platforms are built in turn.
+func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platform) (err error) {
+	if len(f.Build.Secrets) > 0 {
+		return builders.ErrSecretsNotSupported{Builder: b.name}
+	}
+	if len(platforms) > 1 {
+		return builders.BuildPlatforms(ctx, f, platforms, b.Build)
+	}
+
+	df, err := Amend(f)
+	if err != nil {
+		return
+	}
+	args, err := fn.Interpolate(f.Build.BuildEnvs)
+	if err != nil {
+		return
+	}
+	buildArgs := make(map[string]*string, len(args))
+	for k, v := range args {
+		buildArgs[k] = &v
+	}
+	opts := build.ImageBuildOptions{
+		Tags:        []string{f.Build.Image},
+		Dockerfile:  AmendedPath,
+		BuildArgs:   buildArgs,
+		Labels:      map[string]string{labels.FunctionNameKey: f.Name},
+		Remove:      true,
+		ForceRemove: true,
+	}
+	if f.Runtime != "" {
+		opts.Labels[labels.FunctionRuntimeKey] = f.Runtime
+	}
+	if len(platforms) == 1 {
+		p := platforms[0]
+		opts.Platform = strings.ToLower(p.OS + "/" + p.Architecture)
+		if p.Variant != "" {
+			opts.Platform += "/" + p.Variant
+		}
+	}
+
+	cli := b.cli
+	if cli == nil {
+		var c dockerClient.APIClient
+		if c, _, err = docker.NewClient(dockerClient.DefaultDockerHost); err != nil {
+			return fmt.Errorf("cannot create docker client: %w", err)
+		}
+		defer c.Close()
+		cli = c
+	}
+
+	// The build context is streamed to the daemon as it is read
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(WriteContext(pw, f.Root, df))
+	}()
+	defer pr.Close()
+
+	resp, err := cli.ImageBuild(ctx, pr, opts)
+	if err != nil {
+		return fmt.Errorf("cannot build the function: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Verbose output is that of the daemon.  Otherwise it is retained and
+	// written only should the build fail.
+	var out bytes.Buffer
+	stderr := fn.BuildOutput(ctx, os.Stderr)
+	w, fd, isTerminal := io.Writer(&out), os.Stderr.Fd(), false
+	if b.verbose {
+		w, isTerminal = stderr, stderr == os.Stderr && term.IsTerminal(int(fd))
+	}
+	if err = jsonmessage.DisplayJSONMessagesStream(resp.Body, w, fd, isTerminal, nil); err != nil {
+		if !b.verbose {
+			_, _ = io.Copy(stderr, &out)
+		}
+		return fmt.Errorf("failed to build the function: %w", err)
+	}
+	return
+}
+
+// Amend the function's Dockerfile such that the image listens on the port
+// expected of functions unless its Dockerfile defines another.
+func Amend(f fn.Function) ([]byte, error) {
+	path := f.Build.Dockerfile
+	if path == "" {
+		path = DefaultDockerfile
+	}
+	if !filepath.IsLocal(path) {
+		return nil, fmt.Errorf("the Dockerfile %q must be relative to, and within, the function root", path)
+	}
+	df, err := os.ReadFile(filepath.Join(f.Root, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrDockerfileNotFound{Path: path}
+	} else if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.Write(df)
+	if len(df) > 0 && df[len(df)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	b.WriteString("ENV LISTEN_ADDRESS=${LISTEN_ADDRESS:-[::]:8080}\n")
+	b.WriteString("EXPOSE 8080\n")
+	return b.Bytes(), nil
+}
+
+// Context returns the paths, relative to the function's root at root, of the
+// files which comprise its build context, less those excluded by its
+// .dockerignore.
+func Context(root string) ([]string, error) {
+	patterns, err := dockerignore(root)
+	if err != nil {
+		return nil, err
+	}
+	ignorer, err := fn.NewIgnorer(root, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	return ignorer.Context()
+}
+
+// WriteContext of the function at root to w as a tar stream, with its
+// amended Dockerfile at AmendedPath.  Files excluded from the function's
+// build context, or by its .dockerignore, are not written.
+func WriteContext(w io.Writer, root string, df []byte) error {
+	patterns, err := dockerignore(root)
+	if err != nil {
+		return err
+	}
+	ignorer, err := fn.NewIgnorer(root, patterns...)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(w)
+	err = ignorer.Walk(func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = tw.WriteHeader(&tar.Header{Name: AmendedPath, Mode: 0644, Size: int64(len(df))}); err != nil {
+		return err
+	}
+	if _, err = tw.Write(df); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// dockerignore patterns of the function at root, if it has a .dockerignore,
+// as patterns of the ignorer.  Those of a .dockerignore are relative to the
+// root of the context, so are anchored to it.
+func dockerignore(root string) (patterns []string, err error) {
+	data, err := os.ReadFile(filepath.Join(root, ".dockerignore"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negate := strings.HasPrefix(line, "!")
+		line = "/" + strings.TrimLeft(strings.TrimPrefix(line, "!"), "/")
+		if negate {
+			line = "!" + line
+		}
+		patterns = append(patterns, line)
+	}
+	return
+}
+
+// Errors
+
+// ErrDockerfileNotFound is returned when the function has no Dockerfile at
+// the path from which it is built.
+type ErrDockerfileNotFound struct {
+	Path string
+}
+
+func (e ErrDockerfileNotFound) Error() string {
+	return fmt.Sprintf("building the function requires a Dockerfile, and none was found at %q. Set its path with --dockerfile", e.Path)
+}
@@ -0,0 +1,196 @@
+package dockerfile_test
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/build"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/builders/dockerfile"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s/labels"
+	. "knative.dev/func/pkg/testing"
+)
+
+type mockDocker struct {
+	body    string
+	options []build.ImageBuildOptions
+	files   map[string]string
+}
+
+func (m *mockDocker) ImageBuild(_ context.Context, buildContext io.Reader, options build.ImageBuildOptions) (build.ImageBuildResponse, error) {
+	m.options = append(m.options, options)
+	m.files = map[string]string{}
+	tr := tar.NewReader(buildContext)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return build.ImageBuildResponse{}, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return build.ImageBuildResponse{}, err
+		}
+		m.files[hdr.Name] = string(data)
+	}
+	return build.ImageBuildResponse{Body: io.NopCloser(strings.NewReader(m.body))}, nil
+}
+
+// TestBuild ensures that the function's Dockerfile is built with its build
+// context, labeled with the function's metadata and listening on the
+// function port by default, and with the build environment as arguments.
+func TestBuild(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Join(root, "docker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "docker", "Dockerfile"), []byte("FROM scratch\nCOPY . /"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, fn.IgnoreFile), []byte("secret.txt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, ".dockerignore"), []byte("# docs\n*.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "NOTES.md"), []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/f:latest"
+	f.Build.Dockerfile = "docker/Dockerfile"
+	name, value := "EXAMPLE", "example-value"
+	f.Build.BuildEnvs = []fn.Env{{Name: &name, Value: &value}}
+
+	cli := &mockDocker{body: `{"stream":"Step 1/4 : FROM scratch\n"}`}
+	b := dockerfile.NewBuilder(dockerfile.WithDockerClient(cli))
+	if err = b.Build(context.Background(), f, []fn.Platform{{OS: "linux", Architecture: "arm64"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := cli.options[0]
+	if len(opts.Tags) != 1 || opts.Tags[0] != f.Build.Image {
+		t.Errorf("expected the image to be tagged %v, got %v", f.Build.Image, opts.Tags)
+	}
+	if opts.Platform != "linux/arm64" {
+		t.Errorf("expected platform linux/arm64, got %q", opts.Platform)
+	}
+	if opts.Labels[labels.FunctionNameKey] != f.Name || opts.Labels[labels.FunctionRuntimeKey] != "go" {
+		t.Errorf("expected the function's labels, got %v", opts.Labels)
+	}
+	if v := opts.BuildArgs["EXAMPLE"]; v == nil || *v != value {
+		t.Errorf("expected build arg EXAMPLE=%v, got %v", value, opts.BuildArgs)
+	}
+	df, ok := cli.files[opts.Dockerfile]
+	if !ok {
+		t.Fatalf("expected the Dockerfile %v in the build context", opts.Dockerfile)
+	}
+	if !strings.HasPrefix(df, "FROM scratch\nCOPY . /\n") || !strings.Contains(df, "ENV LISTEN_ADDRESS=${LISTEN_ADDRESS:-[::]:8080}\nEXPOSE 8080\n") {
+		t.Errorf("unexpected Dockerfile:\n%v", df)
+	}
+	if _, ok := cli.files["handle.go"]; !ok {
+		t.Errorf("expected the function's source in the build context, got %v", keys(cli.files))
+	}
+	if _, ok := cli.files["secret.txt"]; ok {
+		t.Error("expected the file excluded by .funcignore not to be in the build context")
+	}
+	if _, ok := cli.files["NOTES.md"]; ok {
+		t.Error("expected the file excluded by .dockerignore not to be in the build context")
+	}
+}
+
+// TestBuild_Platforms ensures that the image of each of several platforms
+// is built in turn.
+func TestBuild_Platforms(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/f:latest"
+
+	cli := &mockDocker{}
+	platforms := []fn.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm", Variant: "v7"}}
+	if err = dockerfile.NewBuilder(dockerfile.WithDockerClient(cli)).Build(context.Background(), f, platforms); err != nil {
+		t.Fatal(err)
+	}
+	if len(cli.options) != 2 {
+		t.Fatalf("expected a build of each platform, got %v", len(cli.options))
+	}
+	for i, p := range platforms {
+		if want := fn.PlatformImage(f.Build.Image, p); cli.options[i].Tags[0] != want {
+			t.Errorf("expected the image %v, got %v", want, cli.options[i].Tags[0])
+		}
+	}
+	if cli.options[1].Platform != "linux/arm/v7" {
+		t.Errorf("expected platform linux/arm/v7, got %q", cli.options[1].Platform)
+	}
+}
+
+// TestBuild_Errors ensures that a missing or external Dockerfile, build
+// secrets, and an error reported by the daemon, fail the build.
+func TestBuild_Errors(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/f:latest"
+	cli := &mockDocker{body: `{"errorDetail":{"message":"COPY failed"},"error":"COPY failed"}`}
+	b := dockerfile.NewBuilder(dockerfile.WithDockerClient(cli))
+
+	err = b.Build(context.Background(), f, nil)
+	if !errors.As(err, &dockerfile.ErrDockerfileNotFound{}) {
+		t.Fatalf("expected ErrDockerfileNotFound, got %v", err)
+	}
+
+	f.Build.Dockerfile = "../Dockerfile"
+	if err = b.Build(context.Background(), f, nil); err == nil {
+		t.Fatal("expected an error building a Dockerfile outside the function")
+	}
+
+	f.Build.Dockerfile = ""
+	f.Build.Secrets = []fn.SecretSpec{{ID: "npmrc", Path: "~/.npmrc"}}
+	if err = b.Build(context.Background(), f, nil); !errors.As(err, &builders.ErrSecretsNotSupported{}) {
+		t.Fatalf("expected ErrSecretsNotSupported, got %v", err)
+	}
+
+	f.Build.Secrets = nil
+	if err = os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Build(context.Background(), f, nil); err == nil || !strings.Contains(err.Error(), "COPY failed") {
+		t.Fatalf("expected the daemon's error, got %v", err)
+	}
+}
+
+func keys(m map[string]string) (kk []string) {
+	for k := range m {
+		kk = append(kk, k)
+	}
+	return
+}
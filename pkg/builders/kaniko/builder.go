@@ -0,0 +1,301 @@
+/*
+Package kaniko builds functions on the cluster, as a pod running the kaniko
+executor, such that functions can be built without a local container engine
+nor a BuildKit daemon.
+
+The function's Dockerfile, amended as by the dockerfile builder, is built
+from its build context, which is streamed to the pod over its stdin, and the
+output of the build is streamed back as it runs.  The executor pushes the
+image itself, with the credentials of the function's registry, so images are
+pushed whether or not the build is followed by a push; the push records
+the digest of the image built.
+*/
+package kaniko
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"knative.dev/func/pkg/builders"
+	"knative.dev/func/pkg/builders/dockerfile"
+	"knative.dev/func/pkg/docker"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/k8s/labels"
+	"knative.dev/func/pkg/oci"
+)
+
+// DefaultName when no WithName option is provided to NewBuilder
+const DefaultName = builders.Kaniko
+
+// DefaultImage of the kaniko executor, used unless the function defines a
+// builder image for the builder.
+var DefaultImage = "gcr.io/kaniko-project/executor:v1.23.2"
+
+// Builder of functions using a kaniko pod on the cluster.
+type Builder struct {
+	name                string
+	verbose             bool
+	namespace           string
+	credentialsProvider oci.CredentialsProvider
+	impl                Impl
+}
+
+// Impl allows for the underlying implementation to be mocked for tests.
+type Impl interface {
+	RunPod(ctx context.Context, namespace string, pod *corev1.Pod, secret *corev1.Secret, in io.Reader, out io.Writer) (corev1.ContainerStateTerminated, error)
+}
+
+// ImplFunc adapts a function to an Impl.
+type ImplFunc func(ctx context.Context, namespace string, pod *corev1.Pod, secret *corev1.Secret, in io.Reader, out io.Writer) (corev1.ContainerStateTerminated, error)
+
+func (f ImplFunc) RunPod(ctx context.Context, namespace string, pod *corev1.Pod, secret *corev1.Secret, in io.Reader, out io.Writer) (corev1.ContainerStateTerminated, error) {
+	return f(ctx, namespace, pod, secret, in, out)
+}
+
+type Option func(*Builder)
+
+func WithName(n string) Option {
+	return func(b *Builder) {
+		b.name = n
+	}
+}
+
+// WithVerbose toggles verbose logging, in which the executor logs at the
+// debug level.
+func WithVerbose(v bool) Option {
+	return func(b *Builder) {
+		b.verbose = v
+	}
+}
+
+// WithNamespace in which to run the build, that of the function or else the
+// current namespace by default.
+func WithNamespace(ns string) Option {
+	return func(b *Builder) {
+		b.namespace = ns
+	}
+}
+
+// WithCredentialsProvider of the credentials of the registry to which the
+// executor pushes the image.
+func WithCredentialsProvider(cp oci.CredentialsProvider) Option {
+	return func(b *Builder) {
+		b.credentialsProvider = cp
+	}
+}
+
+// WithImpl sets an optional implementation override to use in place of
+// running the pod on the cluster.  Used for mocking the implementation
+// during tests.
+func WithImpl(i Impl) Option {
+	return func(b *Builder) {
+		b.impl = i
+	}
+}
+
+// NewBuilder creates a new instance of a Builder with static defaults.
+func NewBuilder(options ...Option) *Builder {
+	b := &Builder{
+		name:                DefaultName,
+		credentialsProvider: oci.EmptyCredentialsProvider,
+		impl:                ImplFunc(k8s.RunPod),
+	}
+	for _, o := range options {
+		o(b)
+	}
+	return b
+}
+
+// Build the function's image on the cluster, pushing it to f.Build.Image,
+// and recording its digest for the push which follows.
+func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platform) (err error) {
+	if len(f.Build.Secrets) > 0 {
+		return builders.ErrSecretsNotSupported{Builder: b.name}
+	}
+	if len(platforms) > 1 {
+		return ErrPlatformsNotSupported
+	}
+	// The digest of a previous build is not that of this build
+	if err = os.Remove(digestPath(f.Root, b.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	df, err := dockerfile.Amend(f)
+	if err != nil {
+		return
+	}
+	args, err := fn.Interpolate(f.Build.BuildEnvs)
+	if err != nil {
+		return
+	}
+
+	podName := "func-kaniko-" + rand.String(5)
+	image := DefaultImage
+	if i := f.Build.BuilderImages[b.name]; i != "" {
+		image = i
+	}
+	cmd := []string{
+		"--context=tar://stdin",
+		"--dockerfile=" + dockerfile.AmendedPath,
+		"--destination=" + f.Build.Image,
+		"--digest-file=/dev/termination-log",
+		"--label=" + labels.FunctionNameKey + "=" + f.Name,
+	}
+	if f.Runtime != "" {
+		cmd = append(cmd, "--label="+labels.FunctionRuntimeKey+"="+f.Runtime)
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd = append(cmd, "--build-arg="+k+"="+args[k])
+	}
+	if len(platforms) == 1 {
+		p := strings.ToLower(platforms[0].OS + "/" + platforms[0].Architecture)
+		if platforms[0].Variant != "" {
+			p += "/" + platforms[0].Variant
+		}
+		cmd = append(cmd, "--custom-platform="+p)
+	}
+	if b.verbose {
+		cmd = append(cmd, "--verbosity=debug")
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   podName,
+			Labels: map[string]string{labels.FunctionNameKey: f.Name},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:      podName,
+				Image:     image,
+				Args:      cmd,
+				Stdin:     true,
+				StdinOnce: true,
+			}},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+
+	// The credentials of the registry, if any, are those of the executor's
+	// Docker config.
+	secret, err := b.dockerConfig(ctx, f.Build.Image, podName)
+	if err != nil {
+		return
+	}
+	if secret != nil {
+		pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "docker-config", MountPath: "/kaniko/.docker"}}
+		pod.Spec.Volumes = []corev1.Volume{{
+			Name:         "docker-config",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret.Name}},
+		}}
+	}
+
+	namespace := b.namespace
+	if namespace == "" {
+		namespace = f.Namespace
+	}
+
+	// The build context is streamed to the pod, compressed, as it is read
+	pr, pw := io.Pipe()
+	go func() {
+		gw := gzip.NewWriter(pw)
+		if err := dockerfile.WriteContext(gw, f.Root, df); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(gw.Close())
+	}()
+	defer pr.Close()
+
+	state, err := b.impl.RunPod(ctx, namespace, pod, secret, pr, fn.BuildOutput(ctx, os.Stderr))
+	if err != nil {
+		return fmt.Errorf("cannot build the function: %w", err)
+	}
+	if state.ExitCode != 0 {
+		return fmt.Errorf("failed to build the function: the kaniko executor exited with code %d", state.ExitCode)
+	}
+	digest := strings.TrimSpace(state.Message)
+	if !strings.HasPrefix(digest, "sha256:") {
+		return fmt.Errorf("the kaniko executor reported no digest of the image built (%q)", digest)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(digestPath(f.Root, b.name)), 0774); err != nil {
+		return
+	}
+	return os.WriteFile(digestPath(f.Root, b.name), []byte(digest), 0644)
+}
+
+// digestPath of the image last built by the named builder for the function
+// at root, from which it is read by the pusher.
+func digestPath(root, name string) string {
+	return filepath.Join(root, fn.RunDataDir, "builds", name, "digest")
+}
+
+// dockerConfig secret of the credentials of the image's registry, or nil if
+// there are none.
+func (b *Builder) dockerConfig(ctx context.Context, image, secretName string) (*corev1.Secret, error) {
+	registry, err := docker.GetRegistry(image)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := b.credentialsProvider(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the credentials of %v: %w", registry, err)
+	}
+	if creds.Username == "" && creds.Password == "" {
+		return nil, nil
+	}
+	if registry == name.DefaultRegistry {
+		registry = authn.DefaultAuthKey
+	}
+	config, err := k8s.HandleDockerCfgJSONContent(creds.Username, creds.Password, "", registry)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"config.json": config},
+	}, nil
+}
+
+// Pusher of functions built by the kaniko builder, whose images are pushed
+// by the build itself.  Implements fn.Pusher.
+type Pusher struct {
+	name string
+}
+
+// NewPusher of the images of the named kaniko builder.
+func NewPusher(name string) *Pusher {
+	return &Pusher{name: name}
+}
+
+// Push returns the digest of the image pushed by the function's last build.
+func (p *Pusher) Push(ctx context.Context, f fn.Function) (digest string, err error) {
+	data, err := os.ReadFile(digestPath(f.Root, p.name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("the function was not built by the %v builder", p.name)
+	}
+	return strings.TrimSpace(string(data)), err
+}
+
+// Errors
+
+// ErrPlatformsNotSupported is returned when building for several platforms.
+var ErrPlatformsNotSupported = errors.New("the kaniko builder builds the image of a single platform (--platform)")
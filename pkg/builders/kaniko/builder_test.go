@@ -0,0 +1,141 @@
+package kaniko_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/func/pkg/builders/dockerfile"
+	"knative.dev/func/pkg/builders/kaniko"
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
+	. "knative.dev/func/pkg/testing"
+)
+
+const digest = "sha256:0bd7d9ff1c8e58ac8ae4c8ab7e6bb2e53ef9ec42bd36da0e69ebbc1e1b2e11b8"
+
+// TestBuild ensures that the function's Dockerfile and build context are
+// streamed to a pod of the executor which pushes the image with the
+// registry's credentials, and that the digest it reports is that pushed.
+func TestBuild(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Namespace: "builds"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\nCOPY . /\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/f:latest"
+	name, value := "EXAMPLE", "example-value"
+	f.Build.BuildEnvs = []fn.Env{{Name: &name, Value: &value}}
+
+	var files []string
+	impl := kaniko.ImplFunc(func(_ context.Context, namespace string, pod *corev1.Pod, secret *corev1.Secret, in io.Reader, _ io.Writer) (corev1.ContainerStateTerminated, error) {
+		if namespace != "builds" {
+			t.Errorf("expected the build in the function's namespace, got %q", namespace)
+		}
+		c := pod.Spec.Containers[0]
+		if c.Name != pod.Name || c.Image != kaniko.DefaultImage || !c.Stdin || !c.StdinOnce {
+			t.Errorf("unexpected container %+v", c)
+		}
+		for _, arg := range []string{
+			"--context=tar://stdin",
+			"--dockerfile=" + dockerfile.AmendedPath,
+			"--destination=" + f.Build.Image,
+			"--build-arg=EXAMPLE=example-value",
+			"--custom-platform=linux/arm64",
+		} {
+			if !slices.Contains(c.Args, arg) {
+				t.Errorf("expected the argument %v, got %v", arg, c.Args)
+			}
+		}
+		if secret == nil || !strings.Contains(string(secret.Data["config.json"]), "example.com") {
+			t.Fatalf("expected the Docker config of the registry, got %v", secret)
+		}
+		if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].Secret.SecretName != secret.Name {
+			t.Errorf("expected the Docker config mounted, got %v", pod.Spec.Volumes)
+		}
+		gr, err := gzip.NewReader(in)
+		if err != nil {
+			return corev1.ContainerStateTerminated{}, err
+		}
+		tr := tar.NewReader(gr)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return corev1.ContainerStateTerminated{}, err
+			}
+			files = append(files, hdr.Name)
+		}
+		return corev1.ContainerStateTerminated{Message: digest}, nil
+	})
+	creds := oci.CredentialsProvider(func(context.Context, string) (oci.Credentials, error) {
+		return oci.Credentials{Username: "alice", Password: "secret"}, nil
+	})
+
+	b := kaniko.NewBuilder(kaniko.WithImpl(impl), kaniko.WithCredentialsProvider(creds))
+	if err = b.Build(context.Background(), f, []fn.Platform{{OS: "linux", Architecture: "arm64"}}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(files, dockerfile.AmendedPath) || !slices.Contains(files, "handle.go") {
+		t.Errorf("expected the Dockerfile and source in the build context, got %v", files)
+	}
+
+	pushed, err := kaniko.NewPusher(kaniko.DefaultName).Push(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pushed != digest {
+		t.Fatalf("expected the digest %v, got %v", digest, pushed)
+	}
+}
+
+// TestBuild_Errors ensures that building several platforms, a failed build,
+// and pushing an image not built by kaniko are errors.
+func TestBuild_Errors(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/f:latest"
+
+	impl := kaniko.ImplFunc(func(_ context.Context, _ string, _ *corev1.Pod, secret *corev1.Secret, in io.Reader, _ io.Writer) (corev1.ContainerStateTerminated, error) {
+		if secret != nil {
+			t.Error("expected no Docker config without credentials")
+		}
+		_, _ = io.Copy(io.Discard, in)
+		return corev1.ContainerStateTerminated{ExitCode: 1}, nil
+	})
+	b := kaniko.NewBuilder(kaniko.WithImpl(impl))
+
+	platforms := []fn.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}
+	if err = b.Build(context.Background(), f, platforms); !errors.Is(err, kaniko.ErrPlatformsNotSupported) {
+		t.Fatalf("expected ErrPlatformsNotSupported, got %v", err)
+	}
+	if err = b.Build(context.Background(), f, nil); err == nil || !strings.Contains(err.Error(), "exited with code 1") {
+		t.Fatalf("expected the failed build to be reported, got %v", err)
+	}
+	if _, err = kaniko.NewPusher(kaniko.DefaultName).Push(context.Background(), f); err == nil {
+		t.Fatal("expected an error pushing a function not built by kaniko")
+	}
+}
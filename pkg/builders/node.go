@@ -0,0 +1,29 @@
+package builders
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// NodeMain returns the main module of the Node function at root as declared
+// by its package.json, or the default given, as a slash-separated path
+// relative to the function's root.
+func NodeMain(root, defaultMain string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return "", fmt.Errorf("node functions require a package.json: %w", err)
+	}
+	var pkg struct {
+		Main string `json:"main"`
+	}
+	if err = json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("invalid package.json: %w", err)
+	}
+	if pkg.Main == "" {
+		return defaultMain, nil
+	}
+	return path.Clean(filepath.ToSlash(pkg.Main)), nil
+}
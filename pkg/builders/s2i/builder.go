@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 
@@ -27,6 +28,10 @@ import (
 // DefaultName when no WithName option is provided to NewBuilder
 const DefaultName = builders.S2I
 
+// excludeRegExp matches the paths which s2i excludes from the source it
+// sends to the builder in addition to those excluded by the function.
+var excludeRegExp = regexp.MustCompile("(^|/)\\.git|\\.env|\\.func|node_modules(/|$)")
+
 var DefaultNodeBuilder = "registry.access.redhat.com/ubi8/nodejs-20-minimal"
 var DefaultQuarkusBuilder = "registry.access.redhat.com/ubi8/openjdk-21"
 var DefaultPythonBuilder = "registry.access.redhat.com/ubi8/python-39"
@@ -42,12 +47,20 @@ var DefaultBuilderImages = map[string]string{
 	"typescript": DefaultNodeBuilder,
 }
 
+// globEscaper escapes the metacharacters of a path matched by s2i as a glob.
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
+
 // Builder of functions using the s2i subsystem.
 type Builder struct {
 	name    string
 	verbose bool
 	impl    build.Builder // S2I builder implementation (aka "Strategy")
 	cli     s2idocker.Client
+	policy  builders.Policy
+
+	dependencies builders.Dependencies
+
+	incremental bool
 }
 
 type Option func(*Builder)
@@ -80,6 +93,34 @@ func WithDockerClient(cli s2idocker.Client) Option {
 	}
 }
 
+// WithIncremental enables incremental builds of all functions, in addition
+// to those which enable them (f.Build.Incremental), in which the artifacts saved
+// by the builder image's save-artifacts script from the function's previous
+// image (such as downloaded dependencies) are restored prior to assembly.
+// Builds proceed non-incrementally when there is no previous image or the
+// builder image provides no save-artifacts script.
+func WithIncremental(i bool) Option {
+	return func(b *Builder) {
+		b.incremental = i
+	}
+}
+
+// WithPolicy restricts the builder images which may be used, and provides
+// defaults by runtime which take precedence over DefaultBuilderImages.
+func WithPolicy(p builders.Policy) Option {
+	return func(b *Builder) {
+		b.policy = p
+	}
+}
+
+// WithDependencies configures the package managers of builds with the
+// private registries of dependencies, and their credentials.
+func WithDependencies(d builders.Dependencies) Option {
+	return func(b *Builder) {
+		b.dependencies = d
+	}
+}
+
 // NewBuilder creates a new instance of a Builder with static defaults.
 func NewBuilder(options ...Option) *Builder {
 	b := &Builder{name: DefaultName}
@@ -89,19 +130,46 @@ func NewBuilder(options ...Option) *Builder {
 	return b
 }
 
+// Context returns the paths, relative to the function's root, of the files
+// which comprise the function's build context as sent to the builder: those
+// of the function's context not otherwise excluded by s2i.
+func Context(f fn.Function) (paths []string, err error) {
+	ignorer, err := fn.NewIgnorer(f.Root)
+	if err != nil {
+		return
+	}
+	files, err := ignorer.Context()
+	if err != nil {
+		return
+	}
+	for _, path := range files {
+		if !excludeRegExp.MatchString(path) {
+			paths = append(paths, path)
+		}
+	}
+	return
+}
+
 // Build the function using the S2I builder.
 //
 // Platforms:
-// The S2I builder supports at most a single platform to target, and the
-// platform specified must be available in the provided builder image.
-// If the provided builder image is not a multi-architecture image index
-// container, specifying a target platform is redundant, so if provided it
-// must match that of the single-architecture container or the request is
-// invalid.
+// The S2I builder targets a single platform per build, which must be
+// available in the provided builder image.  If the provided builder image is
+// not a multi-architecture image index container, specifying a target
+// platform is redundant, so if provided it must match that of the
+// single-architecture container or the request is invalid.  When several
+// platforms are requested, the function is built once for each.
 func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platform) (err error) {
+	if len(f.Build.Secrets) > 0 {
+		return builders.ErrSecretsNotSupported{Builder: b.name}
+	}
+	if len(platforms) > 1 {
+		return builders.BuildPlatforms(ctx, f, platforms, b.Build)
+	}
 
-	// Builder image from the function if defined, default otherwise.
-	builderImage, err := BuilderImage(f, b.name)
+	// Builder image from the function if defined, default otherwise; which
+	// must be allowed by policy.
+	builderImage, err := b.policy.Image(f, b.name, DefaultBuilderImages)
 	if err != nil {
 		return
 	}
@@ -115,9 +183,6 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		if builderImage, err = docker.GetPlatformImage(builderImage, platform); err != nil {
 			return fmt.Errorf("cannot get platform image reference for %q: %w", platform, err)
 		}
-	} else if len(platforms) > 1 {
-		// Only a single requestd platform supported.
-		return errors.New("the S2I builder currently only supports specifying a single target platform")
 	}
 
 	var client = b.cli
@@ -131,14 +196,26 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		client = c
 	}
 
-	// Link .s2iignore -> .funcignore
-	funcignorePath := filepath.Join(f.Root, ".funcignore")
+	// Write the files excluded from the build context as an .s2iignore, each
+	// escaped such that s2i's glob matches the file exactly.
 	s2iignorePath := filepath.Join(f.Root, ".s2iignore")
-	if _, err := os.Stat(funcignorePath); err == nil {
-		if _, err := os.Stat(s2iignorePath); err == nil {
-			fmt.Fprintln(os.Stderr, "Warning: an existing .s2iignore was detected.  Using this with preference over .funcignore")
-		} else {
-			if err = os.Symlink("./.funcignore", s2iignorePath); err != nil {
+	if _, err := os.Stat(s2iignorePath); err == nil {
+		fmt.Fprintln(fn.BuildOutput(ctx, os.Stderr), "Warning: an existing .s2iignore was detected.  Using this with preference over .funcignore")
+	} else {
+		ignorer, err := fn.NewIgnorer(f.Root)
+		if err != nil {
+			return err
+		}
+		excluded, err := ignorer.Excluded()
+		if err != nil {
+			return fmt.Errorf("cannot determine the function's build context: %w", err)
+		}
+		if len(excluded) > 0 {
+			var sb strings.Builder
+			for _, path := range append(excluded, ".s2iignore") {
+				sb.WriteString(globEscaper.Replace(filepath.FromSlash(path)) + "\n")
+			}
+			if err = os.WriteFile(s2iignorePath, []byte(sb.String()), 0644); err != nil {
 				return err
 			}
 			defer os.Remove(s2iignorePath)
@@ -154,10 +231,12 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 		Quiet:                   !b.verbose,
 		Tag:                     f.Build.Image,
 		BuilderImage:            builderImage,
+		RuntimeImage:            f.Build.RunImage,
 		BuilderPullPolicy:       api.DefaultBuilderPullPolicy,
 		PreviousImagePullPolicy: api.DefaultPreviousImagePullPolicy,
 		RuntimeImagePullPolicy:  api.DefaultRuntimeImagePullPolicy,
 		DockerConfig:            s2idocker.GetDefaultDockerConfig(),
+		Incremental:             b.incremental || f.Build.Incremental,
 	}
 
 	// Scaffold
@@ -170,7 +249,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	// (node_modules, etc) in the tar file sent to the builder, as this both
 	// bloats the build process and can cause unexpected errors in the resultant
 	// function.
-	cfg.ExcludeRegExp = "(^|/)\\.git|\\.env|\\.func|node_modules(/|$)"
+	cfg.ExcludeRegExp = excludeRegExp.String()
 
 	// Environment variables
 	// Build Envs have local env var references interpolated then added to the
@@ -181,6 +260,19 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	}
 
 	buildEnvs["LISTEN_ADDRESS"] = "[::]:8080"
+
+	// The package managers' configuration of private registries is mounted
+	// read-only from outside of the function, and so is not of the image.
+	mounts, env, done, err := b.dependencies.Mount()
+	if err != nil {
+		return
+	}
+	defer done()
+	for k, v := range env {
+		if _, ok := buildEnvs[k]; !ok {
+			buildEnvs[k] = v
+		}
+	}
 	for k, v := range buildEnvs {
 		cfg.Environment = append(cfg.Environment, api.EnvironmentSpec{Name: k, Value: v})
 	}
@@ -188,6 +280,9 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	for _, m := range f.Build.Mounts {
 		cfg.BuildVolumes = append(cfg.BuildVolumes, fmt.Sprintf("%s:%s:ro,Z", m.Source, m.Destination))
 	}
+	for _, m := range mounts {
+		cfg.BuildVolumes = append(cfg.BuildVolumes, fmt.Sprintf("%s:%s:ro,Z", m.Source, m.Destination))
+	}
 
 	if runtime.GOOS == "linux" {
 		cfg.DockerNetworkMode = "host"
@@ -196,7 +291,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	// Validate the config
 	if errs := validation.ValidateConfig(cfg); len(errs) > 0 {
 		for _, e := range errs {
-			fmt.Fprintf(os.Stderr, "ERROR: %s\n", e)
+			fmt.Fprintf(fn.BuildOutput(ctx, os.Stderr), "ERROR: %s\n", e)
 		}
 		return errors.New("unable to build via the s2i builder")
 	}
@@ -215,6 +310,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 	}
 
 	// Perform the build
+	fn.ReportDeployEvent(ctx, fn.DeployEvent{Stage: fn.StagePhase, Phase: fn.PhaseBuild})
 	result, err := impl.Build(cfg)
 	if err != nil {
 		return
@@ -222,12 +318,51 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, platforms []fn.Platf
 
 	if b.verbose {
 		for _, message := range result.Messages {
-			fmt.Fprintln(os.Stderr, message)
+			fmt.Fprintln(fn.BuildOutput(ctx, os.Stderr), message)
 		}
 	}
 	return nil
 }
 
+// Caches of the function retained by s2i: the builder image of its runtime,
+// shared by all functions.  The artifacts restored by incremental builds are
+// those of the function's previous image, which is not a cache.
+func (b *Builder) Caches(ctx context.Context, f fn.Function) ([]fn.BuildCache, error) {
+	builder, err := b.policy.Image(f, b.name, DefaultBuilderImages)
+	if err != nil {
+		return nil, nil // no builder image of an unknown runtime
+	}
+	client, done, err := b.dockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	return docker.ImageCaches(ctx, client, b.name, builder)
+}
+
+// Remove the cache image.
+func (b *Builder) Remove(ctx context.Context, c fn.BuildCache) error {
+	client, done, err := b.dockerClient()
+	if err != nil {
+		return err
+	}
+	defer done()
+	return docker.RemoveImageCache(ctx, client, c.Name)
+}
+
+// dockerClient provided, or else of the default host, and a function to
+// close it when done.
+func (b *Builder) dockerClient() (s2idocker.Client, func(), error) {
+	if b.cli != nil {
+		return b.cli, func() {}, nil
+	}
+	c, _, err := docker.NewClient(dockerClient.DefaultDockerHost)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create docker client: %w", err)
+	}
+	return c, func() { _ = c.Close() }, nil
+}
+
 // Builder Image chooses the correct builder image or defaults.
 func BuilderImage(f fn.Function, builderName string) (string, error) {
 	// delegate as the logic is shared amongst builders
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/docker/docker/api/types"
@@ -155,6 +158,134 @@ func Test_BuilderImageConfigurable(t *testing.T) {
 	}
 }
 
+// Test_BaseAndRunImage ensures that the function's base image is used as the
+// builder image and its run image as the runtime image of the s2i build.
+func Test_BaseAndRunImage(t *testing.T) {
+	var (
+		i = &mockImpl{}
+		c = mockDocker{}
+		b = s2i.NewBuilder(s2i.WithName(builders.S2I), s2i.WithImpl(i), s2i.WithDockerClient(c))
+		f = fn.Function{
+			Runtime: "node",
+			Build: fn.BuildSpec{
+				BaseImage: "example.com/hardened/builder",
+				RunImage:  "example.com/hardened/runtime",
+			},
+		}
+	)
+
+	i.BuildFn = func(cfg *api.Config) (*api.Result, error) {
+		if cfg.BuilderImage != f.Build.BaseImage {
+			t.Errorf("expected builder image '%v', got '%v'", f.Build.BaseImage, cfg.BuilderImage)
+		}
+		if cfg.RuntimeImage != f.Build.RunImage {
+			t.Errorf("expected runtime image '%v', got '%v'", f.Build.RunImage, cfg.RuntimeImage)
+		}
+		return nil, nil
+	}
+
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test_Policy ensures that the policy's default builder image for the runtime
+// is used, and that builder images it does not allow are refused.
+func Test_Policy(t *testing.T) {
+	var (
+		i = &mockImpl{}
+		c = mockDocker{}
+		b = s2i.NewBuilder(s2i.WithName(builders.S2I), s2i.WithImpl(i), s2i.WithDockerClient(c),
+			s2i.WithPolicy(builders.Policy{
+				Allowed:  []string{"example.com/hardened/node-builder"},
+				Defaults: map[string]string{"node": "example.com/hardened/node-builder:20"},
+			}))
+		f = fn.Function{Runtime: "node"}
+	)
+
+	i.BuildFn = func(cfg *api.Config) (*api.Result, error) {
+		if cfg.BuilderImage != "example.com/hardened/node-builder:20" {
+			t.Errorf("expected the policy default builder image, got '%v'", cfg.BuilderImage)
+		}
+		return nil, nil
+	}
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Build.BaseImage = "example.com/untrusted/builder"
+	var notAllowed builders.ErrBuilderNotAllowed
+	if err := b.Build(context.Background(), f, nil); !errors.As(err, &notAllowed) {
+		t.Fatalf("expected ErrBuilderNotAllowed, got %v", err)
+	}
+}
+
+// Test_Incremental ensures that incremental builds are requested of the S2I
+// implementation only when enabled, of the builder or by the function.
+func Test_Incremental(t *testing.T) {
+	for _, tc := range []struct {
+		builder, function, want bool
+	}{
+		{false, false, false},
+		{true, false, true},
+		{false, true, true},
+	} {
+		var (
+			i = &mockImpl{}
+			c = mockDocker{}
+			b = s2i.NewBuilder(s2i.WithName(builders.S2I), s2i.WithImpl(i), s2i.WithDockerClient(c),
+				s2i.WithIncremental(tc.builder))
+		)
+		i.BuildFn = func(cfg *api.Config) (*api.Result, error) {
+			if cfg.Incremental != tc.want {
+				t.Errorf("expected incremental %v, got %v", tc.want, cfg.Incremental)
+			}
+			return nil, nil
+		}
+		f := fn.Function{Runtime: "node", Build: fn.BuildSpec{Incremental: tc.function}}
+		if err := b.Build(context.Background(), f, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Test_Ignore ensures that the files excluded from the function's build
+// context, including by negated patterns of its .funcignore, are those
+// ignored by S2I.
+func Test_Ignore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".funcignore": "*.txt\n!keep.txt\n",
+		"hello.txt":   "",
+		"keep.txt":    "",
+		"index.js":    "",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i := &mockImpl{}
+	i.BuildFn = func(cfg *api.Config) (*api.Result, error) {
+		data, err := os.ReadFile(filepath.Join(root, ".s2iignore"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := ".funcignore\nhello.txt\n.s2iignore\n"; string(data) != expected {
+			t.Errorf("expected .s2iignore %q, got %q", expected, data)
+		}
+		return nil, nil
+	}
+	b := s2i.NewBuilder(s2i.WithName(builders.S2I), s2i.WithImpl(i), s2i.WithDockerClient(mockDocker{}))
+	if err := b.Build(context.Background(), fn.Function{Root: root, Runtime: "node"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".s2iignore")); !os.IsNotExist(err) {
+		t.Error("expected the generated .s2iignore to be removed after the build")
+	}
+}
+
 // Test_Verbose ensures that the verbosity flag is propagated to the
 // S2I builder implementation.
 func Test_BuilderVerbose(t *testing.T) {
@@ -210,6 +341,54 @@ func Test_BuildEnvs(t *testing.T) {
 	}
 }
 
+// Test_Dependencies ensures that the configuration of private registries of
+// dependencies is mounted read-only into the build, and that the function's
+// build envs take precedence over those which reference it.
+func Test_Dependencies(t *testing.T) {
+	var (
+		name  = "PIP_CONFIG_FILE"
+		value = "/workspace/pip.conf"
+		f     = fn.Function{
+			Root:    t.TempDir(),
+			Runtime: "python",
+			Build: fn.BuildSpec{
+				BuildEnvs: []fn.Env{{Name: &name, Value: &value}},
+			},
+		}
+		d = builders.Dependencies{
+			Pip:   &builders.PipIndex{URL: "https://pypi.example.com/simple"},
+			Maven: &builders.MavenRepository{URL: "https://maven.example.com", Username: "user", Password: "pass"},
+		}
+		i = &mockImpl{}
+		c = mockDocker{}
+		b = s2i.NewBuilder(s2i.WithImpl(i), s2i.WithDockerClient(c), s2i.WithDependencies(d))
+	)
+	i.BuildFn = func(cfg *api.Config) (r *api.Result, err error) {
+		if len(cfg.BuildVolumes) != 2 {
+			t.Fatalf("expected pip.conf and settings.xml mounted, got %v", cfg.BuildVolumes)
+		}
+		for _, v := range cfg.BuildVolumes {
+			if !strings.Contains(v, ":/run/func/dependencies/") || !strings.HasSuffix(v, ":ro,Z") {
+				t.Fatalf("unexpected mount %v", v)
+			}
+		}
+		envs := map[string]string{}
+		for _, e := range cfg.Environment {
+			envs[e.Name] = e.Value
+		}
+		if envs[name] != value {
+			t.Fatalf("expected the build env %v to take precedence, got %q", name, envs[name])
+		}
+		if envs["MAVEN_ARGS_APPEND"] != "-s /run/func/dependencies/settings.xml" {
+			t.Fatalf("expected MAVEN_ARGS_APPEND, got %q", envs["MAVEN_ARGS_APPEND"])
+		}
+		return
+	}
+	if err := b.Build(context.Background(), f, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestBuildFail(t *testing.T) {
 	cli := mockDocker{
 		inspect: func(ctx context.Context, image string) (typesImage.InspectResponse, []byte, error) {
@@ -22,6 +22,10 @@ const (
 	// Repositories is the default directory for repositoires.
 	Repositories = "repositories"
 
+	// Policies is the default directory for deployment policies, both within
+	// the global config and within each installed repository.
+	Policies = "policies"
+
 	// DefaultLanguage is intentionaly undefined.
 	DefaultLanguage = ""
 
@@ -42,6 +46,28 @@ type Global struct {
 	// getter/setter accessors to match requests.
 
 	RegistryInsecure bool `yaml:"registryInsecure,omitempty"`
+
+	// Template and Repository are the defaults of new functions.  The
+	// repository, if defined, is the URI of that from which the template is
+	// created.
+	Template   string `yaml:"template,omitempty"`
+	Repository string `yaml:"repository,omitempty"`
+
+	// ListCache enables caching the functions last listed of each kubeconfig
+	// context and namespace, such that they may be listed with --cached
+	// should the cluster be unreachable.
+	ListCache bool `yaml:"listCache,omitempty"`
+
+	// Builders policy by builder short name ("pack" or "s2i"): the builder
+	// images allowed and the default builder image of each runtime.
+	// Configurable only in the config file.
+	Builders map[string]builders.Policy `yaml:"builders,omitempty"`
+
+	// Dependencies are the private registries of dependencies, and their
+	// credentials, from which functions' builds install packages.  Those of
+	// the environment take precedence (see builders.Dependencies.WithEnv).
+	// Configurable only in the config file.
+	Dependencies builders.Dependencies `yaml:"dependencies,omitempty"`
 }
 
 // New Config struct with all members set to static defaults.  See NewDefaults
@@ -181,6 +207,20 @@ func Dir() (path string) {
 	return
 }
 
+// CacheDir is the path of the func cache, such as of functions last listed.
+// It is not created if it does not already exist.  Order of precedence:
+//  1. ~/.cache/func if it can be expanded (user has a home dir)
+//  2. The value of $XDG_CACHE_HOME/func if the environment variable exists.
+func CacheDir() (path string) {
+	if home, err := os.UserHomeDir(); err == nil {
+		path = filepath.Join(home, ".cache", "func")
+	}
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		path = filepath.Join(xdg, "func")
+	}
+	return
+}
+
 // File returns the full path at which to look for a config file.
 // Use FUNC_CONFIG_FILE to override default.
 func File() string {
@@ -201,6 +241,33 @@ func RepositoriesPath() string {
 	return path
 }
 
+// PoliciesPath returns the full path at which to look for deployment
+// policies. Use FUNC_POLICIES_PATH to override default.
+func PoliciesPath() string {
+	path := filepath.Join(Dir(), Policies)
+	if e := os.Getenv("FUNC_POLICIES_PATH"); e != "" {
+		path = e
+	}
+	return path
+}
+
+// PolicyPaths returns all paths from which deployment policies are loaded:
+// the global policies path followed by the policies directory of each
+// installed repository, in lexical order. Paths need not exist.
+func PolicyPaths() []string {
+	paths := []string{PoliciesPath()}
+	entries, err := os.ReadDir(RepositoriesPath())
+	if err != nil {
+		return paths // no repositories installed
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			paths = append(paths, filepath.Join(RepositoriesPath(), e.Name(), Policies))
+		}
+	}
+	return paths
+}
+
 // CreatePaths is a convenience function for creating the on-disk func config
 // structure.  All operations should be tolerant of nonexistant disk
 // footprint where possible (for example listing repositories should not
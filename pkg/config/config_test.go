@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/config"
 	fn "knative.dev/func/pkg/functions"
 
@@ -41,6 +42,42 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+// TestLoad_Builders ensures that builder policies are loaded from the
+// config file.
+func TestLoad_Builders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+builders:
+  pack:
+    allowed:
+    - registry.example.com/builders/
+    defaults:
+      go: registry.example.com/builders/go@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+  s2i:
+    allowed:
+    - registry.example.com/s2i/node:20
+`), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]builders.Policy{
+		builders.Pack: {
+			Allowed:  []string{"registry.example.com/builders/"},
+			Defaults: map[string]string{"go": "registry.example.com/builders/go@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		},
+		builders.S2I: {
+			Allowed: []string{"registry.example.com/s2i/node:20"},
+		},
+	}
+	if !reflect.DeepEqual(cfg.Builders, expected) {
+		t.Fatalf("unexpected builders policy %+v", cfg.Builders)
+	}
+}
+
 // TestWrite ensures that writing a config persists.
 func TestWrite(t *testing.T) {
 	root, cleanup := Mktemp(t)
@@ -173,6 +210,25 @@ func TestRepositoriesPath(t *testing.T) {
 	}
 }
 
+// TestPolicyPaths ensures policies are loaded from the global config and
+// from each installed repository.
+func TestPolicyPaths(t *testing.T) {
+	home, cleanup := Mktemp(t)
+	t.Cleanup(cleanup)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	if err := os.MkdirAll(filepath.Join(config.RepositoriesPath(), "acme"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(home, "func", config.Policies),
+		filepath.Join(home, "func", config.Repositories, "acme", config.Policies),
+	}
+	if !reflect.DeepEqual(config.PolicyPaths(), expected) {
+		t.Fatalf("unexpected policy paths: %v", config.PolicyPaths())
+	}
+}
+
 // TestApply ensures that applying a function as context to a config results
 // in every member of config in the intersection of the two sets, global config
 // and function, to be set to the values of the function.
@@ -373,11 +429,16 @@ func TestList(t *testing.T) {
 	values := config.List()
 	expected := []string{
 		"builder",
+		"builders",
 		"confirm",
+		"dependencies",
 		"language",
+		"listCache",
 		"namespace",
 		"registry",
 		"registryInsecure",
+		"repository",
+		"template",
 		"verbose",
 	}
 
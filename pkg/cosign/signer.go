@@ -0,0 +1,433 @@
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Endpoints of the public sigstore instance, used for keyless signing.
+const (
+	DefaultFulcioURL = "https://fulcio.sigstore.dev"
+	DefaultRekorURL  = "https://rekor.sigstore.dev"
+)
+
+// WithFulcioURL overrides the certificate authority from which the signer
+// requests the certificates of keyless signatures.
+func WithFulcioURL(u string) Opt {
+	return func(o *options) {
+		o.fulcioURL = u
+	}
+}
+
+// WithRekorURL overrides the transparency log to which the signer uploads
+// keyless signatures.
+func WithRekorURL(u string) Opt {
+	return func(o *options) {
+		o.rekorURL = u
+	}
+}
+
+// WithIdentityToken overrides the source of the OIDC identity token of
+// keyless signatures, which is otherwise that of the environment.
+func WithIdentityToken(token func(context.Context) (string, error)) Opt {
+	return func(o *options) {
+		o.identityToken = token
+	}
+}
+
+// Signer of images.  Implements fn.Signer.
+type Signer struct {
+	options
+}
+
+// NewSigner creates a signer of images which stores signatures as does
+// 'cosign sign'.
+func NewSigner(opts ...Opt) *Signer {
+	return &Signer{options: newOptions(opts)}
+}
+
+// Sign the function's pushed image (f.Build.Image) with its signing key, or
+// keyless if it has none (see fn.SignSpec), returning the reference of the
+// signature: the tag at which cosign stores the signatures of the digest.
+func (s *Signer) Sign(ctx context.Context, f fn.Function) (string, error) {
+	if f.Build.Sign == nil {
+		return "", nil
+	}
+	if f.Build.Image == "" {
+		return "", errors.New("no image to sign")
+	}
+	ref, err := name.ParseReference(f.Build.Image)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse image reference: %w", err)
+	}
+	opts, err := s.remoteOptions(ctx, f.Build.Image)
+	if err != nil {
+		return "", err
+	}
+	digest, err := resolveDigest(ref, opts)
+	if err != nil {
+		return "", err
+	}
+
+	payload := fmt.Appendf(nil, `{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`,
+		ref.Context().String(), digest.String())
+
+	var annotations map[string]string
+	if f.Build.Sign.Keyless() {
+		annotations, err = s.signKeyless(ctx, payload)
+	} else {
+		annotations, err = signKeyed(f.Root, f.Build.Sign.Key, payload)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	tag := tagFor(ref.Context(), digest, "sig")
+	if err = appendSignature(tag, payload, annotations, opts); err != nil {
+		return "", fmt.Errorf("cannot push signature: %w", err)
+	}
+	if s.verbose {
+		fmt.Fprintf(os.Stderr, "✅ Signed %v@%v as %v\n", ref.Context(), digest, tag)
+	}
+	return tag.String(), nil
+}
+
+// appendSignature to those of the tag, creating it if none exist.
+func appendSignature(tag name.Tag, payload []byte, annotations map[string]string, opts []remote.Option) error {
+	var base v1.Image = empty.Image
+	if img, err := remote.Image(tag, opts...); err == nil {
+		base = img
+	} else if !isNotFound(err) {
+		return err
+	}
+	img, err := mutate.Append(base, mutate.Addendum{
+		Layer:       static.NewLayer(payload, types.MediaType(SimpleSigningMediaType)),
+		Annotations: annotations,
+	})
+	if err != nil {
+		return err
+	}
+	return remote.Write(tag, img, opts...)
+}
+
+// isNotFound returns true if the error is of a manifest not found.
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+// signKeyed signs the payload with the private key at path (relative to
+// root), returning the annotations of its signature.  An encrypted key, as
+// generated by 'cosign generate-key-pair', is decrypted with the password of
+// COSIGN_PASSWORD.
+func signKeyed(root, path string, payload []byte) (map[string]string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read signing key: %w", err)
+	}
+	priv, err := cryptoutils.UnmarshalPEMToPrivateKey(pem, cryptoutils.StaticPasswordFunc([]byte(os.Getenv("COSIGN_PASSWORD"))))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load signing key: %w", err)
+	}
+	signer, err := signature.LoadSigner(priv, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(sig)}, nil
+}
+
+// signKeyless signs the payload with an ephemeral key certified by Fulcio to
+// belong to the OIDC identity of the environment, and enters the signature
+// into the Rekor transparency log, returning the annotations of the
+// signature, its certificate and its log entry.  These are verified against
+// the trust root, as they would be by a verifier, before being returned: the
+// certificate must chain to the Fulcio root and be of the ephemeral key, and
+// the log entry must be signed by a trusted log.
+func (s *Signer) signKeyless(ctx context.Context, payload []byte) (map[string]string, error) {
+	root, err := s.trustRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load sigstore trust root: %w", err)
+	}
+	token, err := s.identityToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := signature.LoadSigner(priv, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, err := s.requestCertificate(ctx, token, priv, signer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain signing certificate: %w", err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := s.uploadEntry(ctx, payload, sig, chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot enter signature into the transparency log: %w", err)
+	}
+
+	annotations := map[string]string{
+		SignatureAnnotation:   base64.StdEncoding.EncodeToString(sig),
+		CertificateAnnotation: chain[0],
+		BundleAnnotation:      bundle,
+	}
+	if len(chain) > 1 {
+		annotations[ChainAnnotation] = strings.Join(chain[1:], "")
+	}
+
+	cert, err := verifyCertified(root, payload, sig, annotations)
+	if err != nil {
+		return nil, fmt.Errorf("the keyless signature is not valid: %w", err)
+	}
+	if err = cryptoutils.EqualKeys(cert.PublicKey, priv.Public()); err != nil {
+		return nil, fmt.Errorf("the keyless signature is not valid: the certificate issued is not of its key: %w", err)
+	}
+	return annotations, nil
+}
+
+// requestCertificate of the key from Fulcio, proving possession of it by
+// signing the subject of the identity token.  Returns the PEM certificates
+// of the chain, the first being that of the key.
+func (s *Signer) requestCertificate(ctx context.Context, token string, priv *ecdsa.PrivateKey, signer signature.Signer) ([]string, error) {
+	subject, err := tokenSubject(token)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := signer.SignMessage(strings.NewReader(subject))
+	if err != nil {
+		return nil, err
+	}
+	pub, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	var request struct {
+		Credentials struct {
+			OIDCIdentityToken string `json:"oidcIdentityToken"`
+		} `json:"credentials"`
+		PublicKeyRequest struct {
+			PublicKey struct {
+				Algorithm string `json:"algorithm"`
+				Content   string `json:"content"`
+			} `json:"publicKey"`
+			ProofOfPossession []byte `json:"proofOfPossession"`
+		} `json:"publicKeyRequest"`
+	}
+	request.Credentials.OIDCIdentityToken = token
+	request.PublicKeyRequest.PublicKey.Algorithm = "ECDSA"
+	request.PublicKeyRequest.PublicKey.Content = string(pub)
+	request.PublicKeyRequest.ProofOfPossession = proof
+
+	type chain struct {
+		Certificates []string `json:"certificates"`
+	}
+	var response struct {
+		Embedded struct {
+			Chain chain `json:"chain"`
+		} `json:"signedCertificateEmbeddedSct"`
+		Detached struct {
+			Chain chain `json:"chain"`
+		} `json:"signedCertificateDetachedSct"`
+	}
+	if err = s.post(ctx, s.fulcioURL+"/api/v2/signingCert", request, &response); err != nil {
+		return nil, err
+	}
+	certs := response.Embedded.Chain.Certificates
+	if len(certs) == 0 {
+		certs = response.Detached.Chain.Certificates
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate was issued")
+	}
+	return certs, nil
+}
+
+// uploadEntry of the signature and its certificate to Rekor as a
+// hashedrekord, returning the bundle of the entry as annotated on the
+// signature by cosign.
+func (s *Signer) uploadEntry(ctx context.Context, payload, sig []byte, cert string) (string, error) {
+	sum := sha256.Sum256(payload)
+	var entry struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Spec       struct {
+			Data struct {
+				Hash struct {
+					Algorithm string `json:"algorithm"`
+					Value     string `json:"value"`
+				} `json:"hash"`
+			} `json:"data"`
+			Signature struct {
+				Content   []byte `json:"content"`
+				PublicKey struct {
+					Content []byte `json:"content"`
+				} `json:"publicKey"`
+			} `json:"signature"`
+		} `json:"spec"`
+	}
+	entry.APIVersion = "0.0.1"
+	entry.Kind = "hashedrekord"
+	entry.Spec.Data.Hash.Algorithm = "sha256"
+	entry.Spec.Data.Hash.Value = hex.EncodeToString(sum[:])
+	entry.Spec.Signature.Content = sig
+	entry.Spec.Signature.PublicKey.Content = []byte(cert)
+
+	var response map[string]struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+		Verification   struct {
+			SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+		} `json:"verification"`
+	}
+	if err := s.post(ctx, s.rekorURL+"/api/v1/log/entries", entry, &response); err != nil {
+		return "", err
+	}
+	for _, e := range response { // keyed by the UUID of the single entry
+		var b rekorBundle
+		b.SignedEntryTimestamp = e.Verification.SignedEntryTimestamp
+		b.Payload.Body = e.Body
+		b.Payload.IntegratedTime = e.IntegratedTime
+		b.Payload.LogID = e.LogID
+		b.Payload.LogIndex = e.LogIndex
+		bundle, err := json.Marshal(b)
+		return string(bundle), err
+	}
+	return "", errors.New("no log entry was created")
+}
+
+// post the request as JSON, decoding the JSON response.
+func (s *Signer) post(ctx context.Context, url string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	res, err := (&http.Client{Transport: s.transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%v responded %v: %s", url, res.Status, bytes.TrimSpace(data))
+	}
+	return json.Unmarshal(data, response)
+}
+
+// tokenSubject of a JWT, unverified, which is left to Fulcio: its verified
+// email if any, or else its subject, being that which Fulcio certifies.
+func tokenSubject(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("the identity token is not a JWT")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", fmt.Errorf("cannot decode the identity token: %w", err)
+	}
+	var claims struct {
+		Email    string `json:"email"`
+		Verified any    `json:"email_verified"` // a boolean, or a string of one
+		Subject  string `json:"sub"`
+	}
+	if err = json.Unmarshal(data, &claims); err != nil {
+		return "", fmt.Errorf("cannot decode the identity token: %w", err)
+	}
+	if claims.Email != "" {
+		if claims.Verified != true && claims.Verified != "true" {
+			return "", errors.New("the email of the identity token is not verified")
+		}
+		return claims.Email, nil
+	}
+	if claims.Subject == "" {
+		return "", errors.New("the identity token has no subject")
+	}
+	return claims.Subject, nil
+}
+
+// ambientIdentityToken is the OIDC identity token of the environment, with
+// the audience "sigstore": that of SIGSTORE_ID_TOKEN, or else that issued to
+// the GitHub Actions workflow being run.
+func ambientIdentityToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("SIGSTORE_ID_TOKEN"); token != "" {
+		return token, nil
+	}
+	url, bearer := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"), os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if url == "" || bearer == "" {
+		return "", errors.New("keyless signing requires an OIDC identity token, such as that of SIGSTORE_ID_TOKEN or of a GitHub Actions workflow with the id-token permission; otherwise sign with a key")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"&audience=sigstore", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "bearer "+bearer)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot request the GitHub Actions identity token: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cannot request the GitHub Actions identity token: %v", res.Status)
+	}
+	var token struct {
+		Value string `json:"value"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	return token.Value, nil
+}
@@ -0,0 +1,287 @@
+package cosign_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"knative.dev/func/pkg/cosign"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestSigner_Keyed ensures that an image signed with an encrypted cosign key
+// is verified against its public key, and that signing again appends to the
+// signatures of the image.
+func TestSigner_Keyed(t *testing.T) {
+	repo, digest := pushImage(t)
+	priv, pub, err := cryptoutils.GeneratePEMEncodedECDSAKeyPair(elliptic.P256(), cryptoutils.StaticPasswordFunc([]byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err = os.WriteFile(filepath.Join(root, "cosign.key"), priv, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("COSIGN_PASSWORD", "secret")
+
+	f := fn.Function{Root: root}
+	f.Build.Image = repo.Digest(digest.String()).String()
+	f.Build.Sign = &fn.SignSpec{Key: "cosign.key"}
+	signer := cosign.NewSigner()
+	ref, err := signer.Sign(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repo.String() + ":sha256-" + digest.Hex + ".sig"; ref != want {
+		t.Fatalf("expected the signature reference %v, got %v", want, ref)
+	}
+	if _, err = signer.Sign(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Deploy.Verify = &fn.VerifySpec{Key: string(pub)}
+	if _, err = cosign.NewVerifier().Verify(context.Background(), f); err != nil {
+		t.Fatalf("expected the signed image to verify. %v", err)
+	}
+	img, err := remote.Image(repo.Tag("sha256-" + digest.Hex + ".sig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layers, _ := img.Layers(); len(layers) != 2 {
+		t.Fatalf("expected both signatures, got %v", len(layers))
+	}
+
+	t.Setenv("COSIGN_PASSWORD", "wrong")
+	if _, err = signer.Sign(context.Background(), f); err == nil {
+		t.Fatal("expected an error signing with the wrong password")
+	}
+}
+
+// TestSigner_Keyless ensures that an image signed keyless, with a
+// certificate of the identity token from Fulcio and an entry in Rekor, is
+// verified against that identity.
+func TestSigner_Keyless(t *testing.T) {
+	const (
+		identity = "alice@example.com"
+		issuer   = "https://issuer.example.com"
+	)
+	repo, _ := pushImage(t)
+	ca, caCert := newCA(t)
+	fulcio := httptest.NewServer(fakeFulcio(t, ca, caCert, issuer))
+	t.Cleanup(fulcio.Close)
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rekor := httptest.NewServer(fakeRekor(t, rekorKey))
+	t.Cleanup(rekor.Close)
+
+	logID, err := cosign.LogID(rekorKey.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	trustRoot := cosign.TrustRoot{
+		Roots:     roots,
+		RekorKeys: map[string]crypto.PublicKey{logID: rekorKey.Public()},
+	}
+
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"email":"` + identity + `","email_verified":true,"iss":"` + issuer + `"}`))
+	token := cosign.WithIdentityToken(func(context.Context) (string, error) { return "e30." + claims + ".sig", nil })
+	signer := cosign.NewSigner(
+		cosign.WithFulcioURL(fulcio.URL),
+		cosign.WithRekorURL(rekor.URL),
+		cosign.WithTrustRoot(trustRoot),
+		token)
+
+	f := fn.Function{Root: t.TempDir()}
+	f.Build.Image = repo.Tag("latest").String()
+	f.Build.Sign = &fn.SignSpec{}
+	if _, err = signer.Sign(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := cosign.NewVerifier(cosign.WithTrustRoot(trustRoot))
+	f.Deploy.Verify = &fn.VerifySpec{Identity: identity, Issuer: issuer}
+	if _, err = verifier.Verify(context.Background(), f); err != nil {
+		t.Fatalf("expected the keyless signature to verify. %v", err)
+	}
+	f.Deploy.Verify = &fn.VerifySpec{Identity: "bob@example.com", Issuer: issuer}
+	if _, err = verifier.Verify(context.Background(), f); err == nil {
+		t.Fatal("expected the keyless signature of another identity to fail verification")
+	}
+
+	// A certificate not of the trust root, or a log entry not of a trusted
+	// log, is not attached.
+	repo, digest := pushImage(t)
+	f.Build.Image = repo.Tag("latest").String()
+	for name, root := range map[string]cosign.TrustRoot{
+		"untrusted certificate": {Roots: x509.NewCertPool(), RekorKeys: trustRoot.RekorKeys},
+		"untrusted log":         {Roots: roots},
+	} {
+		signer = cosign.NewSigner(
+			cosign.WithFulcioURL(fulcio.URL),
+			cosign.WithRekorURL(rekor.URL),
+			cosign.WithTrustRoot(root),
+			token)
+		if _, err = signer.Sign(context.Background(), f); err == nil {
+			t.Fatalf("%v: expected an error signing", name)
+		}
+	}
+	if _, err = remote.Image(repo.Tag("sha256-" + digest.Hex + ".sig")); err == nil {
+		t.Fatal("expected no signature attached of an untrusted certificate or log")
+	}
+
+	// Without an identity token, keyless signing fails.
+	signer = cosign.NewSigner(cosign.WithTrustRoot(trustRoot), cosign.WithIdentityToken(func(context.Context) (string, error) { return "not a jwt", nil }))
+	if _, err = signer.Sign(context.Background(), f); err == nil {
+		t.Fatal("expected an error signing with an invalid identity token")
+	}
+}
+
+func newCA(t *testing.T) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-fulcio"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+// fakeFulcio issues certificates of the email of the identity token.
+func fakeFulcio(t *testing.T, ca *ecdsa.PrivateKey, caCert *x509.Certificate, issuer string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Credentials struct {
+				OIDCIdentityToken string `json:"oidcIdentityToken"`
+			} `json:"credentials"`
+			PublicKeyRequest struct {
+				PublicKey struct {
+					Content string `json:"content"`
+				} `json:"publicKey"`
+				ProofOfPossession []byte `json:"proofOfPossession"`
+			} `json:"publicKeyRequest"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		pub, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(req.PublicKeyRequest.PublicKey.Content))
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(strings.Split(req.Credentials.OIDCIdentityToken, ".")[1])
+		var token struct {
+			Email string `json:"email"`
+		}
+		_ = json.Unmarshal(claims, &token)
+		verifier, _ := signature.LoadVerifier(pub, crypto.SHA256)
+		if err = verifier.VerifySignature(strings.NewReader(string(req.PublicKeyRequest.ProofOfPossession)), strings.NewReader(token.Email)); err != nil {
+			t.Errorf("invalid proof of possession: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		issuerExt, _ := asn1.Marshal(issuer)
+		template := &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			NotBefore:       time.Now().Add(-time.Minute),
+			NotAfter:        time.Now().Add(10 * time.Minute),
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			EmailAddresses:  []string{token.Email},
+			ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}, Value: issuerExt}},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, pub, ca)
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		leaf, _ := x509.ParseCertificate(der)
+		leafPEM, _ := cryptoutils.MarshalCertificateToPEM(leaf)
+		caPEM, _ := cryptoutils.MarshalCertificateToPEM(caCert)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"signedCertificateEmbeddedSct": map[string]any{
+				"chain": map[string]any{"certificates": []string{string(leafPEM), string(caPEM)}},
+			},
+		})
+	}
+}
+
+// fakeRekor enters hashedrekords, signing the entry timestamp with key.
+func fakeRekor(t *testing.T, key *ecdsa.PrivateKey) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		logID, _ := cosign.LogID(key.Public())
+		payload := struct {
+			Body           string `json:"body"`
+			IntegratedTime int64  `json:"integratedTime"`
+			LogID          string `json:"logID"`
+			LogIndex       int64  `json:"logIndex"`
+		}{base64.StdEncoding.EncodeToString(body), time.Now().Unix(), logID, 1}
+		canonical, _ := json.Marshal(payload)
+		signer, _ := signature.LoadSigner(key, crypto.SHA256)
+		set, err := signer.SignMessage(strings.NewReader(string(canonical)))
+		if err != nil {
+			t.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"24296fb24b8ad77a": map[string]any{
+				"body":           payload.Body,
+				"integratedTime": payload.IntegratedTime,
+				"logID":          payload.LogID,
+				"logIndex":       payload.LogIndex,
+				"verification":   map[string]any{"signedEntryTimestamp": set},
+			},
+		})
+	}
+}
@@ -0,0 +1,640 @@
+/*
+Package cosign signs and verifies container image signatures and attestations
+stored in a registry using the conventions of sigstore's cosign, such that
+images signed with 'cosign sign' and 'cosign attest' can be verified prior to
+deployment, and images signed once pushed are accepted by verifiers such as
+cosign and the sigstore policy controller.
+*/
+package cosign
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/fulcioroots"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/tuf"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
+)
+
+// Annotations and media types used by cosign
+const (
+	SignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	CertificateAnnotation = "dev.sigstore.cosign/certificate"
+	ChainAnnotation       = "dev.sigstore.cosign/chain"
+	BundleAnnotation      = "dev.sigstore.cosign/bundle"
+
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	DSSEMediaType          = "application/vnd.dsse.envelope.v1+json"
+	InTotoPayloadType      = "application/vnd.in-toto+json"
+)
+
+// OIDs of the Fulcio certificate extensions holding the OIDC issuer.
+var (
+	oidIssuerV1 = []int{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = []int{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// TrustRoot holds the material used to verify keyless signatures: the
+// Fulcio certificate authority and the transparency log (Rekor) keys.
+type TrustRoot struct {
+	Roots         *x509.CertPool
+	Intermediates *x509.CertPool
+	// RekorKeys by log ID (the hex SHA-256 of the DER public key)
+	RekorKeys map[string]crypto.PublicKey
+}
+
+// Opt is an option for the verifier and the signer.
+type Opt func(*options)
+
+// options of the verifier and the signer.
+type options struct {
+	credentialsProvider oci.CredentialsProvider
+	transport           http.RoundTripper
+	verbose             bool
+	trustRoot           func(context.Context) (TrustRoot, error)
+	fulcioURL           string
+	rekorURL            string
+	identityToken       func(context.Context) (string, error)
+}
+
+func newOptions(opts []Opt) options {
+	o := options{
+		credentialsProvider: oci.EmptyCredentialsProvider,
+		transport:           http.DefaultTransport,
+		trustRoot:           publicTrustRoot,
+		fulcioURL:           DefaultFulcioURL,
+		rekorURL:            DefaultRekorURL,
+		identityToken:       ambientIdentityToken,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCredentialsProvider used when accessing the registry.
+func WithCredentialsProvider(cp oci.CredentialsProvider) Opt {
+	return func(o *options) {
+		o.credentialsProvider = cp
+	}
+}
+
+// WithTransport used when accessing the registry.
+func WithTransport(t http.RoundTripper) Opt {
+	return func(o *options) {
+		o.transport = t
+	}
+}
+
+// WithVerbose logging.
+func WithVerbose(verbose bool) Opt {
+	return func(o *options) {
+		o.verbose = verbose
+	}
+}
+
+// WithTrustRoot overrides the public sigstore trust root, which is otherwise
+// retrieved via TUF on first use of keyless verification.
+func WithTrustRoot(r TrustRoot) Opt {
+	return func(o *options) {
+		o.trustRoot = func(context.Context) (TrustRoot, error) { return r, nil }
+	}
+}
+
+// Verifier of image signatures.  Implements fn.Verifier.
+type Verifier struct {
+	options
+}
+
+// NewVerifier creates a verifier of cosign signatures.
+func NewVerifier(opts ...Opt) *Verifier {
+	return &Verifier{options: newOptions(opts)}
+}
+
+// Verify that the image to be deployed for the function is signed as
+// required by the function's signature policy (f.Deploy.Verify), and that
+// any required attestations exist and are signed by the same signer.
+// Returned is the digest of the image verified, that to which a tag is
+// resolved.
+func (v *Verifier) Verify(ctx context.Context, f fn.Function) (string, error) {
+	if f.Deploy.Verify == nil {
+		return "", errors.New("no signature policy to verify")
+	}
+	policy := *f.Deploy.Verify
+
+	image := f.Deploy.Image
+	if image == "" {
+		image = f.Build.Image
+	}
+	if image == "" {
+		return "", errors.New("no image to verify")
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse image reference: %w", err)
+	}
+
+	opts, err := v.remoteOptions(ctx, image)
+	if err != nil {
+		return "", err
+	}
+	digest, err := resolveDigest(ref, opts)
+	if err != nil {
+		return "", err
+	}
+
+	check, err := v.newChecker(ctx, f.Root, policy)
+	if err != nil {
+		return "", err
+	}
+
+	if err = verifySignatures(ref.Context(), digest, check, opts); err != nil {
+		return "", err
+	}
+	if v.verbose {
+		fmt.Fprintf(os.Stderr, "✅ Verified signature of %v@%v\n", ref.Context(), digest)
+	}
+
+	if len(policy.Attestations) > 0 {
+		if err = verifyAttestations(ref.Context(), digest, policy.Attestations, check, opts); err != nil {
+			return "", err
+		}
+		if v.verbose {
+			fmt.Fprintf(os.Stderr, "✅ Verified attestations %v\n", strings.Join(policy.Attestations, ", "))
+		}
+	}
+	return digest.String(), nil
+}
+
+func (o options) remoteOptions(ctx context.Context, image string) ([]remote.Option, error) {
+	creds, err := o.credentialsProvider(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	var auth authn.Authenticator = authn.Anonymous
+	if creds.Username != "" || creds.Password != "" {
+		auth = &authn.Basic{Username: creds.Username, Password: creds.Password}
+	}
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuth(auth),
+		remote.WithTransport(o.transport),
+	}, nil
+}
+
+// resolveDigest of the reference, querying the registry for tags.
+func resolveDigest(ref name.Reference, opts []remote.Option) (v1.Hash, error) {
+	if d, ok := ref.(name.Digest); ok {
+		return v1.NewHash(d.DigestStr())
+	}
+	desc, err := remote.Head(ref, opts...)
+	if err != nil {
+		return v1.Hash{}, fmt.Errorf("cannot resolve digest of %v: %w", ref, err)
+	}
+	return desc.Digest, nil
+}
+
+// tagFor returns the tag at which cosign stores the artifacts of the given
+// kind ("sig" or "att") for the digest.
+func tagFor(repo name.Repository, digest v1.Hash, kind string) name.Tag {
+	return repo.Tag(fmt.Sprintf("%v-%v.%v", digest.Algorithm, digest.Hex, kind))
+}
+
+// checker verifies a signature over a payload, given the annotations of the
+// layer in which the signature was found.
+type checker func(ctx context.Context, payload, sig []byte, annotations map[string]string) error
+
+func verifySignatures(repo name.Repository, digest v1.Hash, check checker, opts []remote.Option) error {
+	layers, err := artifactLayers(tagFor(repo, digest, "sig"), opts)
+	if err != nil {
+		return fmt.Errorf("no signatures found for %v@%v: %w", repo, digest, err)
+	}
+	var reasons []string
+	for _, l := range layers {
+		if l.mediaType != SimpleSigningMediaType {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(l.annotations[SignatureAnnotation])
+		if err != nil {
+			reasons = append(reasons, "malformed signature annotation")
+			continue
+		}
+		if err = check(context.Background(), l.content, sig, l.annotations); err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		var payload struct {
+			Critical struct {
+				Image struct {
+					Digest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+			} `json:"critical"`
+		}
+		if err = json.Unmarshal(l.content, &payload); err != nil {
+			reasons = append(reasons, "malformed signature payload")
+			continue
+		}
+		if payload.Critical.Image.Digest != digest.String() {
+			reasons = append(reasons, fmt.Sprintf("signature is for %v", payload.Critical.Image.Digest))
+			continue
+		}
+		return nil // one valid signature suffices
+	}
+	return noneValid("signature", repo, digest, reasons)
+}
+
+func verifyAttestations(repo name.Repository, digest v1.Hash, required []string, check checker, opts []remote.Option) error {
+	layers, err := artifactLayers(tagFor(repo, digest, "att"), opts)
+	if err != nil {
+		return fmt.Errorf("no attestations found for %v@%v: %w", repo, digest, err)
+	}
+	verified := map[string]bool{}
+	var reasons []string
+	for _, l := range layers {
+		if l.mediaType != DSSEMediaType {
+			continue
+		}
+		var envelope struct {
+			PayloadType string `json:"payloadType"`
+			Payload     string `json:"payload"`
+			Signatures  []struct {
+				Sig string `json:"sig"`
+			} `json:"signatures"`
+		}
+		if err := json.Unmarshal(l.content, &envelope); err != nil {
+			reasons = append(reasons, "malformed attestation envelope")
+			continue
+		}
+		body, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		if err != nil {
+			reasons = append(reasons, "malformed attestation payload")
+			continue
+		}
+		pae := fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(envelope.PayloadType), envelope.PayloadType, len(body), body)
+		signed := false
+		for _, s := range envelope.Signatures {
+			sig, err := base64.StdEncoding.DecodeString(s.Sig)
+			if err != nil {
+				continue
+			}
+			if err = check(context.Background(), pae, sig, l.annotations); err != nil {
+				reasons = append(reasons, err.Error())
+				continue
+			}
+			signed = true
+			break
+		}
+		if !signed || envelope.PayloadType != InTotoPayloadType {
+			continue
+		}
+		var statement struct {
+			PredicateType string `json:"predicateType"`
+			Subject       []struct {
+				Digest map[string]string `json:"digest"`
+			} `json:"subject"`
+		}
+		if err = json.Unmarshal(body, &statement); err != nil {
+			reasons = append(reasons, "malformed in-toto statement")
+			continue
+		}
+		if !slices.ContainsFunc(statement.Subject, func(s struct {
+			Digest map[string]string `json:"digest"`
+		}) bool {
+			return s.Digest[digest.Algorithm] == digest.Hex
+		}) {
+			reasons = append(reasons, fmt.Sprintf("%v attestation is for another subject", statement.PredicateType))
+			continue
+		}
+		verified[statement.PredicateType] = true
+	}
+	for _, r := range required {
+		if !verified[r] {
+			return noneValid(fmt.Sprintf("%q attestation", r), repo, digest, reasons)
+		}
+	}
+	return nil
+}
+
+func noneValid(what string, repo name.Repository, digest v1.Hash, reasons []string) error {
+	if len(reasons) == 0 {
+		return fmt.Errorf("no %v found for %v@%v", what, repo, digest)
+	}
+	slices.Sort(reasons)
+	return fmt.Errorf("no valid %v found for %v@%v: %v", what, repo, digest, strings.Join(slices.Compact(reasons), "; "))
+}
+
+type artifactLayer struct {
+	mediaType   string
+	annotations map[string]string
+	content     []byte
+}
+
+// artifactLayers returns the layers of the cosign artifact image at tag.
+func artifactLayers(tag name.Tag, opts []remote.Option) (layers []artifactLayer, err error) {
+	img, err := remote.Image(tag, opts...)
+	if err != nil {
+		return
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return
+	}
+	for _, desc := range manifest.Layers {
+		l, err := img.LayerByDigest(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		rc, err := l.Compressed() // cosign stores raw blobs
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, artifactLayer{
+			mediaType:   string(desc.MediaType),
+			annotations: desc.Annotations,
+			content:     content,
+		})
+	}
+	return
+}
+
+// newChecker returns the signature checker for the policy: a public key or
+// a keyless identity.
+func (v *Verifier) newChecker(ctx context.Context, root string, policy fn.VerifySpec) (checker, error) {
+	if !policy.Keyless() {
+		pem := []byte(policy.Key)
+		if !strings.HasPrefix(strings.TrimSpace(policy.Key), "-----BEGIN") {
+			path := policy.Key
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			var err error
+			if pem, err = os.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("cannot read verification key: %w", err)
+			}
+		}
+		pub, err := cryptoutils.UnmarshalPEMToPublicKey(pem)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse verification key: %w", err)
+		}
+		verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ context.Context, payload, sig []byte, _ map[string]string) error {
+			if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(payload)); err != nil {
+				return errors.New("signature does not match the verification key")
+			}
+			return nil
+		}, nil
+	}
+
+	trustRoot, err := v.trustRoot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load sigstore trust root: %w", err)
+	}
+	return func(_ context.Context, payload, sig []byte, annotations map[string]string) error {
+		return verifyKeyless(trustRoot, policy, payload, sig, annotations)
+	}, nil
+}
+
+// verifyKeyless verifies a signature made with a short-lived Fulcio
+// certificate (see verifyCertified) whose identity must satisfy the policy.
+func verifyKeyless(root TrustRoot, policy fn.VerifySpec, payload, sig []byte, annotations map[string]string) error {
+	cert, err := verifyCertified(root, payload, sig, annotations)
+	if err != nil {
+		return err
+	}
+	return checkIdentity(cert, policy)
+}
+
+// verifyCertified verifies a signature made with a short-lived Fulcio
+// certificate, returning the certificate: it must chain to the trust root at
+// the time the signature was entered into the transparency log, the log
+// entry must be signed by the log, and the signature must be of the key of
+// the certificate.
+func verifyCertified(root TrustRoot, payload, sig []byte, annotations map[string]string) (*x509.Certificate, error) {
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM([]byte(annotations[CertificateAnnotation]))
+	if err != nil || len(certs) == 0 {
+		return nil, errors.New("signature has no signing certificate")
+	}
+	cert := certs[0]
+
+	integratedTime, err := verifyBundle(root, annotations[BundleAnnotation], sig)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	if root.Intermediates != nil {
+		intermediates = root.Intermediates.Clone()
+	}
+	if chain, err := cryptoutils.UnmarshalCertificatesFromPEM([]byte(annotations[ChainAnnotation])); err == nil {
+		for _, c := range chain {
+			intermediates.AddCert(c)
+		}
+	}
+	if _, err = cert.Verify(x509.VerifyOptions{
+		Roots:         root.Roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		CurrentTime:   integratedTime,
+	}); err != nil {
+		return nil, fmt.Errorf("signing certificate is not trusted: %w", err)
+	}
+
+	verifier, err := signature.LoadVerifier(cert.PublicKey, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	if err = verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(payload)); err != nil {
+		return nil, errors.New("signature does not match the signing certificate")
+	}
+	return cert, nil
+}
+
+// checkIdentity of a Fulcio certificate against the policy.
+func checkIdentity(cert *x509.Certificate, policy fn.VerifySpec) error {
+	identities := append([]string{}, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		identities = append(identities, u.String())
+	}
+	if !matchAny(identities, policy.Identity, policy.IdentityRegexp) {
+		return fmt.Errorf("certificate identity %v does not match the required identity", identities)
+	}
+
+	var issuer string
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var v string
+			if _, err := asn1.Unmarshal(ext.Value, &v); err == nil {
+				issuer = v
+			}
+		case ext.Id.Equal(oidIssuerV1) && issuer == "":
+			issuer = string(ext.Value)
+		}
+	}
+	if !matchAny([]string{issuer}, policy.Issuer, policy.IssuerRegexp) {
+		return fmt.Errorf("certificate issuer %q does not match the required issuer", issuer)
+	}
+	return nil
+}
+
+func matchAny(values []string, exact, expr string) bool {
+	for _, v := range values {
+		if exact != "" && v == exact {
+			return true
+		}
+		if expr != "" {
+			if re, err := regexp.Compile(expr); err == nil && re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rekorBundle is the transparency log entry attached to a keyless signature.
+type rekorBundle struct {
+	SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+	Payload              struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	} `json:"Payload"`
+}
+
+// verifyBundle checks the log's signature over the entry (the signed entry
+// timestamp), that the entry records the given signature, and returns the
+// time at which the entry was integrated into the log.
+func verifyBundle(root TrustRoot, bundle string, sig []byte) (time.Time, error) {
+	if bundle == "" {
+		return time.Time{}, errors.New("keyless signature has no transparency log bundle")
+	}
+	var b rekorBundle
+	if err := json.Unmarshal([]byte(bundle), &b); err != nil {
+		return time.Time{}, errors.New("malformed transparency log bundle")
+	}
+	pub, ok := root.RekorKeys[b.Payload.LogID]
+	if !ok {
+		return time.Time{}, fmt.Errorf("transparency log %v is not trusted", b.Payload.LogID)
+	}
+	// The entry timestamp is a signature over the canonical JSON of the
+	// payload, whose keys are already in canonical (sorted) order.
+	canonical, err := json.Marshal(b.Payload)
+	if err != nil {
+		return time.Time{}, err
+	}
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err = verifier.VerifySignature(bytes.NewReader(b.SignedEntryTimestamp), bytes.NewReader(canonical)); err != nil {
+		return time.Time{}, errors.New("transparency log bundle signature is invalid")
+	}
+
+	body, err := base64.StdEncoding.DecodeString(b.Payload.Body)
+	if err != nil {
+		return time.Time{}, errors.New("malformed transparency log entry")
+	}
+	var entry struct {
+		Spec struct {
+			Signature struct {
+				Content string `json:"content"`
+			} `json:"signature"`
+		} `json:"spec"`
+	}
+	if err = json.Unmarshal(body, &entry); err != nil {
+		return time.Time{}, errors.New("malformed transparency log entry")
+	}
+	if entry.Spec.Signature.Content != base64.StdEncoding.EncodeToString(sig) {
+		return time.Time{}, errors.New("transparency log entry does not record this signature")
+	}
+	return time.Unix(b.Payload.IntegratedTime, 0), nil
+}
+
+// LogID of a transparency log public key.
+func LogID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+var (
+	publicTrustRootOnce sync.Once
+	publicTrustRootVal  TrustRoot
+	publicTrustRootErr  error
+)
+
+// publicTrustRoot of the public sigstore instance, retrieved and cached via
+// TUF (see the TUF_ROOT environment variable to relocate the cache).
+func publicTrustRoot(ctx context.Context) (TrustRoot, error) {
+	publicTrustRootOnce.Do(func() {
+		var r TrustRoot
+		if r.Roots, publicTrustRootErr = fulcioroots.Get(); publicTrustRootErr != nil {
+			return
+		}
+		if r.Intermediates, publicTrustRootErr = fulcioroots.GetIntermediates(); publicTrustRootErr != nil {
+			return
+		}
+		var client *tuf.TUF
+		if client, publicTrustRootErr = tuf.NewFromEnv(ctx); publicTrustRootErr != nil {
+			return
+		}
+		var targets []tuf.TargetFile
+		if targets, publicTrustRootErr = client.GetTargetsByMeta(tuf.Rekor, []string{"rekor.pub"}); publicTrustRootErr != nil {
+			return
+		}
+		r.RekorKeys = map[string]crypto.PublicKey{}
+		for _, t := range targets {
+			pub, err := cryptoutils.UnmarshalPEMToPublicKey(t.Target)
+			if err != nil {
+				publicTrustRootErr = err
+				return
+			}
+			id, err := LogID(pub)
+			if err != nil {
+				publicTrustRootErr = err
+				return
+			}
+			r.RekorKeys[id] = pub
+		}
+		publicTrustRootVal = r
+	})
+	return publicTrustRootVal, publicTrustRootErr
+}
@@ -0,0 +1,196 @@
+package cosign_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"knative.dev/func/pkg/cosign"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestVerifier_Keyed ensures that an image signed with a key is verified
+// against the corresponding public key, and rejected with any other.
+func TestVerifier_Keyed(t *testing.T) {
+	repo, digest := pushImage(t)
+	signer, pub := newKey(t)
+	_, otherPub := newKey(t)
+	sign(t, repo, digest, signer)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "cosign.pub"), pub, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"key path", "cosign.pub", false},
+		{"inline key", string(pub), false},
+		{"other key", string(otherPub), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := fn.Function{Root: root}
+			f.Build.Image = repo.Tag("latest").String()
+			f.Deploy.Verify = &fn.VerifySpec{Key: tt.key}
+			verified, err := cosign.NewVerifier().Verify(context.Background(), f)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error state. wantErr=%v, err=%v", tt.wantErr, err)
+			}
+			if !tt.wantErr && verified != digest.String() {
+				t.Fatalf("expected the verified digest %v, got %v", digest, verified)
+			}
+		})
+	}
+}
+
+// TestVerifier_Unsigned ensures an image with no signature is rejected.
+func TestVerifier_Unsigned(t *testing.T) {
+	repo, _ := pushImage(t)
+	_, pub := newKey(t)
+
+	f := fn.Function{Root: t.TempDir()}
+	f.Deploy.Image = repo.Tag("latest").String()
+	f.Deploy.Verify = &fn.VerifySpec{Key: string(pub)}
+	if _, err := cosign.NewVerifier().Verify(context.Background(), f); err == nil {
+		t.Fatal("expected an unsigned image to fail verification")
+	}
+}
+
+// TestVerifier_Attestations ensures required attestation predicate types must
+// be present and signed.
+func TestVerifier_Attestations(t *testing.T) {
+	repo, digest := pushImage(t)
+	signer, pub := newKey(t)
+	sign(t, repo, digest, signer)
+	attest(t, repo, digest, signer, "https://slsa.dev/provenance/v1")
+
+	f := fn.Function{Root: t.TempDir()}
+	f.Build.Image = repo.Tag("latest").String()
+
+	f.Deploy.Verify = &fn.VerifySpec{Key: string(pub), Attestations: []string{"https://slsa.dev/provenance/v1"}}
+	if _, err := cosign.NewVerifier().Verify(context.Background(), f); err != nil {
+		t.Fatalf("expected present attestation to verify. %v", err)
+	}
+
+	f.Deploy.Verify = &fn.VerifySpec{Key: string(pub), Attestations: []string{"https://spdx.dev/Document"}}
+	if _, err := cosign.NewVerifier().Verify(context.Background(), f); err == nil {
+		t.Fatal("expected missing attestation to fail verification")
+	}
+}
+
+// pushImage to a new in-memory registry, returning its repository and digest.
+func pushImage(t *testing.T) (name.Repository, v1.Hash) {
+	t.Helper()
+	server := httptest.NewServer(registry.New())
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	repo, err := name.NewRepository(u.Host + "/test/func")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(repo.Tag("latest"), img); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, digest
+}
+
+func newKey(t *testing.T) (signature.Signer, []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := cryptoutils.MarshalPublicKeyToPEM(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := signature.LoadSigner(priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, pub
+}
+
+// sign the digest as 'cosign sign' would.
+func sign(t *testing.T, repo name.Repository, digest v1.Hash, signer signature.Signer) {
+	t.Helper()
+	payload := fmt.Appendf(nil, `{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, repo.String(), digest.String())
+	sig, err := signer.SignMessage(strings.NewReader(string(payload)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeArtifact(t, repo, digest, "sig", payload, cosign.SimpleSigningMediaType, map[string]string{
+		cosign.SignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+	})
+}
+
+// attest to the digest with the predicate type as 'cosign attest' would.
+func attest(t *testing.T, repo name.Repository, digest v1.Hash, signer signature.Signer, predicateType string) {
+	t.Helper()
+	statement := fmt.Appendf(nil, `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,"subject":[{"name":%q,"digest":{%q:%q}}],"predicate":{}}`,
+		predicateType, repo.String(), digest.Algorithm, digest.Hex)
+	pae := fmt.Sprintf("DSSEv1 %d %s %d %s", len(cosign.InTotoPayloadType), cosign.InTotoPayloadType, len(statement), statement)
+	sig, err := signer.SignMessage(strings.NewReader(pae))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(map[string]any{
+		"payloadType": cosign.InTotoPayloadType,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []map[string]string{{"sig": base64.StdEncoding.EncodeToString(sig)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeArtifact(t, repo, digest, "att", envelope, cosign.DSSEMediaType, map[string]string{})
+}
+
+func writeArtifact(t *testing.T, repo name.Repository, digest v1.Hash, kind string, content []byte, mediaType string, annotations map[string]string) {
+	t.Helper()
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(content, types.MediaType(mediaType)),
+		Annotations: annotations,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := repo.Tag(fmt.Sprintf("%v-%v.%v", digest.Algorithm, digest.Hex, kind))
+	if err = remote.Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+}
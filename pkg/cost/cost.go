@@ -0,0 +1,291 @@
+// Package cost estimates the cost of running a function as configured, given
+// a pricing table and the rate at which the function is expected to be
+// invoked.
+package cost
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Defaults assumed of a function which does not configure them, and of
+// the cluster's autoscaler.
+const (
+	DefaultCPU               = "100m"
+	DefaultMemory            = "128Mi"
+	DefaultConcurrency       = 100 // Knative's default container concurrency target
+	DefaultRequestDuration   = 100 * time.Millisecond
+	DefaultStableWindow      = 60 * time.Second // before scaling to zero
+	secondsPerMonth          = 30 * 24 * 60 * 60
+	requestsPerPricedRequest = 1_000_000
+)
+
+// Rates of a pricing table.
+type Rates struct {
+	// CPUSecond is the price of one vCPU for one second.
+	CPUSecond float64 `yaml:"cpuSecond" json:"cpuSecond"`
+	// MemoryGiBSecond is the price of one GiB of memory for one second.
+	MemoryGiBSecond float64 `yaml:"memoryGiBSecond" json:"memoryGiBSecond"`
+	// MillionRequests is the price of one million requests.
+	MillionRequests float64 `yaml:"millionRequests" json:"millionRequests"`
+}
+
+// Environment in which a function runs, such as "staging" or "production",
+// with the rate at which it is expected to be invoked there.
+type Environment struct {
+	Name string `yaml:"name" json:"name"`
+	// RequestsPerSecond expected, on average.
+	RequestsPerSecond float64 `yaml:"requestsPerSecond" json:"requestsPerSecond"`
+	// Rates of the environment, if they differ from those of the table.
+	Rates *Rates `yaml:"rates,omitempty" json:"rates,omitempty"`
+}
+
+// Pricing table of the platform on which functions are run.
+type Pricing struct {
+	Currency     string        `yaml:"currency" json:"currency"`
+	Rates        Rates         `yaml:"rates" json:"rates"`
+	Environments []Environment `yaml:"environments,omitempty" json:"environments,omitempty"`
+}
+
+// DefaultPricing is an illustrative pricing table, of the order of the list
+// prices of the serverless container platforms of major cloud providers.  It
+// is not the price of any particular platform; provide a pricing table for
+// an accurate estimate.
+var DefaultPricing = Pricing{
+	Currency: "USD",
+	Rates: Rates{
+		CPUSecond:       0.000024,
+		MemoryGiBSecond: 0.0000025,
+		MillionRequests: 0.40,
+	},
+}
+
+// LoadPricing table from the YAML file at path.
+func LoadPricing(path string) (p Pricing, err error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return p, fmt.Errorf("cannot read pricing table: %w", err)
+	}
+	if err = yaml.UnmarshalStrict(bb, &p); err != nil {
+		return p, fmt.Errorf("cannot parse pricing table %v: %w", path, err)
+	}
+	if p.Currency == "" {
+		p.Currency = DefaultPricing.Currency
+	}
+	return p, p.Validate()
+}
+
+// Validate the pricing table, whose rates may not be negative.
+func (p Pricing) Validate() error {
+	check := func(name string, r Rates) error {
+		if r.CPUSecond < 0 || r.MemoryGiBSecond < 0 || r.MillionRequests < 0 {
+			return fmt.Errorf("rates of %v may not be negative", name)
+		}
+		return nil
+	}
+	if err := check("the pricing table", p.Rates); err != nil {
+		return err
+	}
+	for _, e := range p.Environments {
+		if e.Name == "" {
+			return fmt.Errorf("environments of the pricing table must be named")
+		}
+		if e.RequestsPerSecond < 0 {
+			return fmt.Errorf("requests per second of environment %v may not be negative", e.Name)
+		}
+		if e.Rates != nil {
+			if err := check("environment "+e.Name, *e.Rates); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Observed returns the pricing table with, in place of its environments, the
+// namespace to which the function is deployed, invoked at the request rate
+// observed there.  It is priced at the rates of the table's environment of
+// the same name, if any.
+func (p Pricing) Observed(namespace string, requestsPerSecond float64) Pricing {
+	e := Environment{Name: namespace, RequestsPerSecond: requestsPerSecond}
+	for _, configured := range p.Environments {
+		if configured.Name == namespace {
+			e.Rates = configured.Rates
+		}
+	}
+	p.Environments = []Environment{e}
+	return p
+}
+
+// Observation of the requests of a deployed function, at the rate of which
+// its cost is estimated.
+type Observation struct {
+	Namespace         string        `json:"namespace"`
+	Window            time.Duration `json:"window"`
+	Requests          float64       `json:"requests"`
+	RequestsPerSecond float64       `json:"requestsPerSecond"`
+}
+
+// NewObservation of the requests made of the function in the namespace over
+// a window.
+func NewObservation(namespace string, window time.Duration, requests float64) Observation {
+	o := Observation{Namespace: namespace, Window: window, Requests: requests}
+	if window > 0 {
+		o.RequestsPerSecond = requests / window.Seconds()
+	}
+	return o
+}
+
+// Usage of a function as configured: the resources of each instance and
+// how it scales.
+type Usage struct {
+	CPU             float64       `json:"cpu"`       // vCPU per instance
+	MemoryGiB       float64       `json:"memoryGiB"` // per instance
+	Concurrency     float64       `json:"concurrency"`
+	MinScale        int64         `json:"minScale"`
+	MaxScale        int64         `json:"maxScale,omitempty"` // zero is unbounded
+	RequestDuration time.Duration `json:"requestDuration"`
+	// Assumptions made of values the function does not configure.
+	Assumptions []string `json:"assumptions,omitempty"`
+}
+
+// NewUsage of the function, whose requests take the given duration on
+// average (or DefaultRequestDuration, if zero).  The resources of an instance
+// are its configured requests, or the limits if no requests are configured.
+func NewUsage(f fn.Function, requestDuration time.Duration) (u Usage, err error) {
+	var (
+		options     = f.Deploy.Options
+		cpu, memory string
+		target      float64 // concurrent requests per instance
+	)
+	if r := options.Resources; r != nil {
+		if r.Requests != nil && r.Requests.CPU != nil {
+			cpu = *r.Requests.CPU
+		} else if r.Limits != nil && r.Limits.CPU != nil {
+			cpu = *r.Limits.CPU
+		}
+		if r.Requests != nil && r.Requests.Memory != nil {
+			memory = *r.Requests.Memory
+		} else if r.Limits != nil && r.Limits.Memory != nil {
+			memory = *r.Limits.Memory
+		}
+		if r.Limits != nil && r.Limits.Concurrency != nil && *r.Limits.Concurrency > 0 {
+			target = float64(*r.Limits.Concurrency)
+		}
+	}
+	if cpu == "" {
+		cpu = DefaultCPU
+		u.Assumptions = append(u.Assumptions, fmt.Sprintf("%v CPU per instance, as none is configured", DefaultCPU))
+	}
+	if memory == "" {
+		memory = DefaultMemory
+		u.Assumptions = append(u.Assumptions, fmt.Sprintf("%v memory per instance, as none is configured", DefaultMemory))
+	}
+	q, err := resource.ParseQuantity(cpu)
+	if err != nil {
+		return u, fmt.Errorf("invalid CPU %q: %w", cpu, err)
+	}
+	u.CPU = q.AsApproximateFloat64()
+	if q, err = resource.ParseQuantity(memory); err != nil {
+		return u, fmt.Errorf("invalid memory %q: %w", memory, err)
+	}
+	u.MemoryGiB = q.AsApproximateFloat64() / (1 << 30)
+
+	if s := options.Scale; s != nil {
+		if s.Min != nil {
+			u.MinScale = *s.Min
+		}
+		if s.Max != nil {
+			u.MaxScale = *s.Max
+		}
+		// A target of concurrency, rather than requests per second.
+		if s.Target != nil && (s.Metric == nil || *s.Metric == "concurrency") && (target == 0 || *s.Target < target) {
+			target = *s.Target
+		}
+	}
+	if target == 0 {
+		target = DefaultConcurrency
+		u.Assumptions = append(u.Assumptions, fmt.Sprintf("%v concurrent requests per instance, Knative's default", DefaultConcurrency))
+	}
+	u.Concurrency = target
+
+	if u.RequestDuration = requestDuration; u.RequestDuration <= 0 {
+		u.RequestDuration = DefaultRequestDuration
+		u.Assumptions = append(u.Assumptions, fmt.Sprintf("requests take %v on average", DefaultRequestDuration))
+	}
+	return
+}
+
+// Instances running on average when invoked at the given rate.  Requests in
+// flight, by Little's law, are the rate multiplied by their duration.  A
+// function which scales to zero is presumed to be running for the stable
+// window after each request.
+func (u Usage) Instances(requestsPerSecond float64) float64 {
+	inFlight := requestsPerSecond * u.RequestDuration.Seconds()
+	busy := math.Ceil(inFlight / u.Concurrency)
+	if u.MaxScale > 0 && busy > float64(u.MaxScale) {
+		busy = float64(u.MaxScale)
+	}
+	if u.MinScale > 0 {
+		return math.Max(float64(u.MinScale), busy)
+	}
+	if requestsPerSecond <= 0 {
+		return 0
+	}
+	// Probability of at least one request within the stable window.
+	up := 1 - math.Exp(-requestsPerSecond*DefaultStableWindow.Seconds())
+	return math.Max(busy, 1) * up
+}
+
+// Estimate of the monthly cost of a function in an environment.
+type Estimate struct {
+	Environment       string  `json:"environment"`
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Instances         float64 `json:"instances"` // on average
+	CPU               float64 `json:"cpu"`
+	Memory            float64 `json:"memory"`
+	Requests          float64 `json:"requests"`
+	Total             float64 `json:"total"`
+}
+
+// Report of the estimated monthly cost of a function in each environment.
+type Report struct {
+	Function string `json:"function"`
+	Currency string `json:"currency"`
+	Usage    Usage  `json:"usage"`
+	// Observed request rate at which the cost is estimated, if it is that of
+	// the deployed function rather than as configured.
+	Observed  *Observation `json:"observed,omitempty"`
+	Estimates []Estimate   `json:"estimates"`
+}
+
+// Estimate the monthly cost of a function with the given usage in each of
+// the environments of the pricing table.
+func (p Pricing) Estimate(name string, u Usage) Report {
+	r := Report{Function: name, Currency: p.Currency, Usage: u}
+	for _, e := range p.Environments {
+		rates := p.Rates
+		if e.Rates != nil {
+			rates = *e.Rates
+		}
+		instances := u.Instances(e.RequestsPerSecond)
+		est := Estimate{
+			Environment:       e.Name,
+			RequestsPerSecond: e.RequestsPerSecond,
+			Instances:         instances,
+			CPU:               instances * secondsPerMonth * u.CPU * rates.CPUSecond,
+			Memory:            instances * secondsPerMonth * u.MemoryGiB * rates.MemoryGiBSecond,
+			Requests:          e.RequestsPerSecond * secondsPerMonth / requestsPerPricedRequest * rates.MillionRequests,
+		}
+		est.Total = est.CPU + est.Memory + est.Requests
+		r.Estimates = append(r.Estimates, est)
+	}
+	return r
+}
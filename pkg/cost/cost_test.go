@@ -0,0 +1,161 @@
+package cost_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"knative.dev/func/pkg/cost"
+	fn "knative.dev/func/pkg/functions"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// TestNewUsage ensures that the resources and scale of a function are read
+// from its options, with defaults assumed of those not configured.
+func TestNewUsage(t *testing.T) {
+	u, err := cost.NewUsage(fn.Function{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.CPU != 0.1 || u.MemoryGiB != 0.125 || u.Concurrency != cost.DefaultConcurrency || u.RequestDuration != cost.DefaultRequestDuration {
+		t.Fatalf("unexpected default usage %+v", u)
+	}
+	if len(u.Assumptions) != 4 {
+		t.Fatalf("expected 4 assumptions, got %v", u.Assumptions)
+	}
+
+	f := fn.Function{}
+	f.Deploy.Options = fn.Options{
+		Scale: &fn.ScaleOptions{Min: ptr(int64(1)), Max: ptr(int64(5)), Target: ptr(10.0)},
+		Resources: &fn.ResourcesOptions{
+			Requests: &fn.ResourcesRequestsOptions{CPU: ptr("500m")},
+			Limits:   &fn.ResourcesLimitsOptions{CPU: ptr("1"), Memory: ptr("1Gi"), Concurrency: ptr(int64(20))},
+		},
+	}
+	if u, err = cost.NewUsage(f, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if u.CPU != 0.5 || u.MemoryGiB != 1 || u.Concurrency != 10 || u.MinScale != 1 || u.MaxScale != 5 || len(u.Assumptions) != 0 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+}
+
+// TestUsage_Instances ensures that instances are derived from the requests
+// in flight, bounded by the scale of the function.
+func TestUsage_Instances(t *testing.T) {
+	u := cost.Usage{Concurrency: 10, RequestDuration: time.Second}
+	if n := u.Instances(0); n != 0 {
+		t.Errorf("expected no instances without requests, got %v", n)
+	}
+	if n := u.Instances(95); n < 9.99 || n > 10 {
+		t.Errorf("expected 10 instances for 95 requests in flight, got %v", n)
+	}
+	if n := u.Instances(0.001); n > 0.1 {
+		t.Errorf("expected a rarely invoked function to be mostly scaled to zero, got %v", n)
+	}
+	u.MinScale, u.MaxScale = 2, 4
+	if n := u.Instances(0); n != 2 {
+		t.Errorf("expected the minimum scale, got %v", n)
+	}
+	if n := u.Instances(1000); n != 4 {
+		t.Errorf("expected the maximum scale, got %v", n)
+	}
+}
+
+// TestPricing_Estimate ensures that the cost of each environment is
+// estimated with its own rates if it has them.
+func TestPricing_Estimate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	err := os.WriteFile(path, []byte(`
+rates:
+  cpuSecond: 0.00001
+  memoryGiBSecond: 0.000001
+  millionRequests: 1
+environments:
+  - name: staging
+    requestsPerSecond: 0
+  - name: production
+    requestsPerSecond: 10
+    rates:
+      cpuSecond: 0.00002
+      memoryGiBSecond: 0.000002
+      millionRequests: 2
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cost.LoadPricing(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Currency != "USD" {
+		t.Fatalf("expected the default currency, got %q", p.Currency)
+	}
+
+	u := cost.Usage{CPU: 1, MemoryGiB: 1, Concurrency: 100, MinScale: 1, RequestDuration: 100 * time.Millisecond}
+	r := p.Estimate("f", u)
+	if len(r.Estimates) != 2 {
+		t.Fatalf("expected 2 estimates, got %v", len(r.Estimates))
+	}
+	const month = 30 * 24 * 60 * 60
+	staging, production := r.Estimates[0], r.Estimates[1]
+	if !near(staging.Total, month*(0.00001+0.000001)) {
+		t.Errorf("unexpected staging estimate %+v", staging)
+	}
+	if !near(production.Total, month*(0.00002+0.000002)+10*month/1e6*2) {
+		t.Errorf("unexpected production estimate %+v", production)
+	}
+}
+
+// TestPricing_Observed ensures that an observed request rate replaces the
+// environments of the table, priced at the rates of that of the namespace.
+func TestPricing_Observed(t *testing.T) {
+	production := &cost.Rates{CPUSecond: 2}
+	p := cost.Pricing{
+		Rates: cost.Rates{CPUSecond: 1},
+		Environments: []cost.Environment{
+			{Name: "staging", RequestsPerSecond: 1},
+			{Name: "production", RequestsPerSecond: 10, Rates: production},
+		},
+	}
+	o := cost.NewObservation("production", time.Hour, 7200)
+	if o.RequestsPerSecond != 2 {
+		t.Fatalf("expected 2 requests per second, got %v", o.RequestsPerSecond)
+	}
+	observed := p.Observed(o.Namespace, o.RequestsPerSecond)
+	if len(observed.Environments) != 1 {
+		t.Fatalf("expected the observed environment alone, got %+v", observed.Environments)
+	}
+	if e := observed.Environments[0]; e.Name != "production" || e.RequestsPerSecond != 2 || e.Rates != production {
+		t.Fatalf("unexpected observed environment %+v", e)
+	}
+	if e := p.Observed("dev", 2).Environments[0]; e.Rates != nil {
+		t.Fatalf("expected the rates of the table for an unlisted namespace, got %+v", e.Rates)
+	}
+	if len(p.Environments) != 2 {
+		t.Fatalf("expected the table to be unchanged, got %+v", p.Environments)
+	}
+}
+
+// TestLoadPricing_Invalid ensures that unknown fields and negative rates
+// are rejected.
+func TestLoadPricing_Invalid(t *testing.T) {
+	for _, content := range []string{
+		"rates:\n  cpuSeconds: 1\n",
+		"rates:\n  cpuSecond: -1\n",
+		"environments:\n  - requestsPerSecond: 1\n",
+	} {
+		path := filepath.Join(t.TempDir(), "pricing.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cost.LoadPricing(path); err == nil {
+			t.Errorf("expected an error loading %q", content)
+		}
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
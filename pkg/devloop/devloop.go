@@ -0,0 +1,136 @@
+// Package devloop generates the configuration of third-party development
+// loop tools, such as Skaffold and Tilt, which build and deploy a function
+// with func each time its source changes.
+package devloop
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/oci"
+)
+
+// Tools for which configuration is generated.
+const (
+	Skaffold = "skaffold"
+	Tilt     = "tilt"
+)
+
+// Tools supported, in order.
+var Tools = []string{Skaffold, Tilt}
+
+// ErrExists indicates that the configuration file of a tool already exists.
+var ErrExists = errors.New("configuration file already exists")
+
+// File of the tool's configuration, relative to the function's root.
+func File(tool string) (string, error) {
+	switch tool {
+	case Skaffold:
+		return "skaffold.yaml", nil
+	case Tilt:
+		return "Tiltfile", nil
+	}
+	return "", fmt.Errorf("unsupported tool %q. Supported are %v", tool, Tools)
+}
+
+// Init writes the configuration of the tool into the function's root,
+// returning the path of the file written.  An existing file is replaced only
+// if force is set.
+func Init(f fn.Function, tool string, force bool) (string, error) {
+	file, err := File(tool)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(f.Root, file)
+	if _, err = os.Stat(path); err == nil && !force {
+		return path, fmt.Errorf("%w: %v", ErrExists, path)
+	}
+	bb, err := Generate(f, tool)
+	if err != nil {
+		return path, err
+	}
+	return path, os.WriteFile(path, bb, 0644)
+}
+
+// Generate the configuration of the tool for the function.  Functions are
+// built by the host builder where it supports their runtime, being fastest,
+// and otherwise by their configured builder.
+func Generate(f fn.Function, tool string) ([]byte, error) {
+	file, err := File(tool)
+	if err != nil {
+		return nil, err
+	}
+	data := struct {
+		Name    string
+		Image   string
+		Builder string
+		File    string
+	}{Name: f.Name, Builder: f.Build.Builder, File: file}
+	if oci.IsSupported(f.Runtime) {
+		data.Builder = "host"
+	}
+
+	t := tiltTemplate
+	if tool == Skaffold {
+		t = skaffoldTemplate
+		// Skaffold names the image, tagging it each build.
+		image, err := f.ImageName()
+		if err != nil {
+			return nil, fmt.Errorf("skaffold requires the name of the function's image: %w", err)
+		}
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return nil, err
+		}
+		data.Image = ref.Context().Name()
+	}
+
+	var b bytes.Buffer
+	if err = t.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+var skaffoldTemplate = template.Must(template.New(Skaffold).Parse(`# Skaffold configuration of the function {{.Name}}, generated by 'func devloop init'.
+# Run 'skaffold dev' to build and deploy the function each time it changes.
+apiVersion: skaffold/v4beta11
+kind: Config
+metadata:
+  name: {{.Name}}
+build:
+  local:
+    push: true
+  tagPolicy:
+    sha256: {}
+  artifacts:
+  - image: {{.Image}}
+    custom:
+      # Built, pushed and deployed by func as the image tagged by Skaffold.
+      buildCommand: func deploy{{if .Builder}} --builder {{.Builder}}{{end}} --image "$IMAGE"
+      dependencies:
+        paths:
+        - .
+        ignore:
+        - .func/**
+        - .git/**
+        - {{.File}}
+`))
+
+var tiltTemplate = template.Must(template.New(Tilt).Parse(`# Tilt configuration of the function {{.Name}}, generated by 'func devloop init'.
+# Run 'tilt up' to build and deploy the function each time it changes.
+local_resource(
+    '{{.Name}}',
+    cmd='func deploy{{if .Builder}} --builder {{.Builder}}{{end}}',
+    deps=['.'],
+    ignore=['.func', '.git', '{{.File}}'],
+    labels=['functions'],
+)
+`))
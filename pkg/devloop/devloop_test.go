@@ -0,0 +1,70 @@
+package devloop_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"knative.dev/func/pkg/devloop"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestGenerate ensures that the configuration of each tool builds with the
+// host builder where it supports the runtime, and that Skaffold names the
+// function's image without its tag.
+func TestGenerate(t *testing.T) {
+	f := fn.Function{Name: "myfn", Runtime: "go", Registry: "example.com/alice"}
+
+	bb, err := devloop.Generate(f, devloop.Skaffold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- image: example.com/alice/myfn\n",
+		`buildCommand: func deploy --builder host --image "$IMAGE"`,
+		"- skaffold.yaml\n",
+	} {
+		if !strings.Contains(string(bb), want) {
+			t.Errorf("expected skaffold.yaml to contain %q, got:\n%s", want, bb)
+		}
+	}
+
+	f.Runtime = "rust"
+	f.Build.Builder = "pack"
+	bb, err = devloop.Generate(f, devloop.Tilt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"'myfn',", "cmd='func deploy --builder pack'", "'Tiltfile'"} {
+		if !strings.Contains(string(bb), want) {
+			t.Errorf("expected Tiltfile to contain %q, got:\n%s", want, bb)
+		}
+	}
+
+	if _, err = devloop.Generate(fn.Function{Name: "myfn"}, devloop.Skaffold); err == nil {
+		t.Error("expected skaffold to require an image name")
+	}
+	if _, err = devloop.Generate(f, "docker-compose"); err == nil {
+		t.Error("expected an unsupported tool to error")
+	}
+}
+
+// TestInit ensures that an existing configuration is only replaced if forced.
+func TestInit(t *testing.T) {
+	f := fn.Function{Root: t.TempDir(), Name: "myfn", Runtime: "go"}
+
+	path, err := devloop.Init(f, devloop.Tilt, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = devloop.Init(f, devloop.Tilt, false); !errors.Is(err, devloop.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, err = devloop.Init(f, devloop.Tilt, true); err != nil {
+		t.Fatal(err)
+	}
+}
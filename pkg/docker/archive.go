@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// DaemonIndex of the image of the function's last build in the daemon, or
+// of the image of each platform when built for several, as written to an
+// archive by oci.WriteArchive.
+func DaemonIndex(ctx context.Context, f fn.Function) (v1.ImageIndex, error) {
+	cli, _, err := NewClient(client.DefaultDockerHost)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create docker client: %w", err)
+	}
+	defer cli.Close()
+
+	images := []string{f.Build.Image}
+	if len(f.Build.Platforms) > 1 {
+		images = make([]string, len(f.Build.Platforms))
+		for i, p := range f.Build.Platforms {
+			images[i] = fn.PlatformImage(f.Build.Image, p)
+		}
+	}
+
+	var addenda []mutate.IndexAddendum
+	for _, image := range images {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return nil, err
+		}
+		img, err := daemon.Image(ref, daemon.WithContext(ctx), daemon.WithClient(cli))
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the image %v from the daemon: %w", image, err)
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get config file for the image: %w", err)
+		}
+		desc, err := partial.Descriptor(img)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get partial descriptor for the image: %w", err)
+		}
+		desc.Platform = cf.Platform()
+		addenda = append(addenda, mutate.IndexAddendum{Add: img, Descriptor: *desc})
+	}
+	return mutate.AppendManifests(empty.Index, addenda...), nil
+}
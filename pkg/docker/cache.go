@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// ImageCacheClient is the sub-interface of client.APIClient required to
+// inspect and remove the images retained by builders, such as the builder
+// images they pull.
+type ImageCacheClient interface {
+	ImageInspectWithRaw(ctx context.Context, image string) (types.ImageInspect, []byte, error)
+	ImageRemove(ctx context.Context, image string, options image.RemoveOptions) ([]image.DeleteResponse, error)
+}
+
+// VolumeCacheClient is the sub-interface of client.APIClient required to
+// inspect and remove the volumes retained by builders.
+type VolumeCacheClient interface {
+	DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
+	VolumeRemove(ctx context.Context, volumeID string, force bool) error
+}
+
+// ImageCaches of the builder: those of the images referenced which are
+// present, each shared by all functions.  An image is modified when last
+// tagged, such as when pulled.
+func ImageCaches(ctx context.Context, cli ImageCacheClient, builder string, refs ...string) (cc []fn.BuildCache, err error) {
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		img, _, err := cli.ImageInspectWithRaw(ctx, ref)
+		if cerrdefs.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot inspect image %v: %w", ref, err)
+		}
+		modified := img.Metadata.LastTagTime
+		if modified.IsZero() {
+			modified, _ = time.Parse(time.RFC3339Nano, img.Created)
+		}
+		cc = append(cc, fn.BuildCache{
+			Builder:  builder,
+			Kind:     fn.CacheImage,
+			Name:     ref,
+			Size:     img.Size,
+			Modified: modified,
+			Shared:   true,
+		})
+	}
+	return cc, nil
+}
+
+// VolumeCaches of the builder: the volumes whose names match.  A volume is
+// modified when created, the engine recording no later writes.
+func VolumeCaches(ctx context.Context, cli VolumeCacheClient, builder string, match *regexp.Regexp) (cc []fn.BuildCache, err error) {
+	usage, err := cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list volumes: %w", err)
+	}
+	for _, v := range usage.Volumes {
+		if v == nil || !match.MatchString(v.Name) {
+			continue
+		}
+		size := int64(-1)
+		if v.UsageData != nil {
+			size = v.UsageData.Size
+		}
+		created, _ := time.Parse(time.RFC3339, v.CreatedAt)
+		cc = append(cc, fn.BuildCache{
+			Builder:  builder,
+			Kind:     fn.CacheVolume,
+			Name:     v.Name,
+			Size:     size,
+			Modified: created,
+		})
+	}
+	return cc, nil
+}
+
+// RemoveImageCache by reference.  An image already removed is not an error.
+func RemoveImageCache(ctx context.Context, cli ImageCacheClient, ref string) error {
+	_, err := cli.ImageRemove(ctx, ref, image.RemoveOptions{PruneChildren: true})
+	if err != nil && !cerrdefs.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveVolumeCache by name.  A volume already removed is not an error.
+func RemoveVolumeCache(ctx context.Context, cli VolumeCacheClient, name string) error {
+	if err := cli.VolumeRemove(ctx, name, true); err != nil && !cerrdefs.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
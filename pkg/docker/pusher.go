@@ -15,6 +15,7 @@ import (
 
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/oci"
+	"knative.dev/func/pkg/progress"
 
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/registry"
@@ -46,6 +47,7 @@ type PusherDockerClientFactory func() (PusherDockerClient, error)
 // Pusher of images from local to remote registry.
 type Pusher struct {
 	verbose             bool // verbose logging.
+	plain               bool // plain progress lines, even on a terminal.
 	credentialsProvider oci.CredentialsProvider
 	transport           http.RoundTripper
 	dockerClientFactory PusherDockerClientFactory
@@ -75,6 +77,14 @@ func WithVerbose(verbose bool) Opt {
 	}
 }
 
+// WithPlainProgress reports the progress of a push as a summary of each
+// phase rather than redrawing it in place, even on a terminal.
+func WithPlainProgress(plain bool) Opt {
+	return func(pusher *Pusher) {
+		pusher.plain = plain
+	}
+}
+
 // NewPusher creates an instance of a docker-based image pusher.
 func NewPusher(opts ...Opt) *Pusher {
 	result := &Pusher{
@@ -92,6 +102,43 @@ func NewPusher(opts ...Opt) *Pusher {
 	return result
 }
 
+// Digest of the image in its registry, being that of its index or manifest,
+// as a reference by tag or digest.
+func (n *Pusher) Digest(ctx context.Context, image string) (string, error) {
+	credentials, err := n.credentialsProvider(ctx, image)
+	if err != nil {
+		return "", fmt.Errorf("failed to get credentials: %w", err)
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse image ref: %w", err)
+	}
+	desc, err := remote.Head(ref,
+		remote.WithAuth(&authn.Basic{Username: credentials.Username, Password: credentials.Password}),
+		remote.WithTransport(n.transport),
+		remote.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// Tags of the repository in its registry.
+func (n *Pusher) Tags(ctx context.Context, repository string) ([]string, error) {
+	credentials, err := n.credentialsProvider(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse repository: %w", err)
+	}
+	return remote.List(repo,
+		remote.WithAuth(&authn.Basic{Username: credentials.Username, Password: credentials.Password}),
+		remote.WithTransport(n.transport),
+		remote.WithContext(ctx))
+}
+
 func GetRegistry(img string) (string, error) {
 	ref, err := name.ParseReference(img, name.WeakValidation)
 	if err != nil {
@@ -101,18 +148,14 @@ func GetRegistry(img string) (string, error) {
 	return registry, nil
 }
 
-// Push the image index of the function.
+// Push the image index of the function.  When built for several platforms,
+// the image of each is pushed and the index refers to them all.
 func (n *Pusher) Push(ctx context.Context, f fn.Function) (string, error) {
 	credentials, err := n.credentialsProvider(ctx, f.Build.Image)
 	if err != nil {
 		return "", fmt.Errorf("failed to get credentials: %w", err)
 	}
 
-	imgDigest, err := n.pushImage(ctx, f, credentials)
-	if err != nil {
-		return "", fmt.Errorf("cannot push image: %w", err)
-	}
-
 	auth := &authn.Basic{
 		Username: credentials.Username,
 		Password: credentials.Password,
@@ -123,31 +166,50 @@ func (n *Pusher) Push(ctx context.Context, f fn.Function) (string, error) {
 		remote.WithTransport(n.transport),
 	}
 
-	imgRef, err := name.ParseReference(f.ImageNameWithDigest(imgDigest))
-	if err != nil {
-		return "", fmt.Errorf("cannot parse image ref: %w", err)
-	}
-	img, err := remote.Image(imgRef, remoteOpts...)
-	if err != nil {
-		return "", fmt.Errorf("cannot get the image: %w", err)
+	images := []string{f.Build.Image}
+	if len(f.Build.Platforms) > 1 {
+		images = make([]string, len(f.Build.Platforms))
+		for i, p := range f.Build.Platforms {
+			images[i] = fn.PlatformImage(f.Build.Image, p)
+		}
 	}
 
-	cf, err := img.ConfigFile()
-	if err != nil {
-		return "", fmt.Errorf("cannot get config file for the image: %w", err)
-	}
+	var addenda []mutate.IndexAddendum
+	for _, image := range images {
+		pf := f
+		pf.Build.Image = image
+		imgDigest, err := n.pushImage(ctx, pf, credentials)
+		if err != nil {
+			return "", fmt.Errorf("cannot push image: %w", err)
+		}
 
-	newDesc, err := partial.Descriptor(img)
-	if err != nil {
-		return "", fmt.Errorf("cannot get partial descriptor for the image: %w", err)
+		imgRef, err := name.ParseReference(pf.ImageNameWithDigest(imgDigest))
+		if err != nil {
+			return "", fmt.Errorf("cannot parse image ref: %w", err)
+		}
+		img, err := remote.Image(imgRef, remoteOpts...)
+		if err != nil {
+			return "", fmt.Errorf("cannot get the image: %w", err)
+		}
+
+		cf, err := img.ConfigFile()
+		if err != nil {
+			return "", fmt.Errorf("cannot get config file for the image: %w", err)
+		}
+
+		newDesc, err := partial.Descriptor(img)
+		if err != nil {
+			return "", fmt.Errorf("cannot get partial descriptor for the image: %w", err)
+		}
+		newDesc.Platform = cf.Platform()
+		addenda = append(addenda, mutate.IndexAddendum{
+			Add:        img,
+			Descriptor: *newDesc,
+		})
 	}
-	newDesc.Platform = cf.Platform()
 
 	base := mutate.IndexMediaType(empty.Index, types2.DockerManifestList)
-	idx := mutate.AppendManifests(base, mutate.IndexAddendum{
-		Add:        img,
-		Descriptor: *newDesc,
-	})
+	idx := mutate.AppendManifests(base, addenda...)
 
 	idxRef, err := name.ParseReference(f.Build.Image)
 	if err != nil {
@@ -196,7 +258,7 @@ func (n *Pusher) pushImage(ctx context.Context, f fn.Function, credentials oci.C
 		strings.Contains(errStr, "failure in name resolution") ||
 		regexp.MustCompile(`lookup .*: server misbehaving`).MatchString(errStr) {
 		// push with custom transport to be able to push into cluster private registries
-		return n.push(ctx, f, credentials, output)
+		return n.push(ctx, f, credentials)
 	}
 	return "", err
 }
@@ -226,16 +288,22 @@ func (n *Pusher) daemonPush(ctx context.Context, f fn.Function, credentials oci.
 	}
 	defer r.Close()
 
-	var outBuff bytes.Buffer
-	output = io.MultiWriter(&outBuff, output)
+	// Verbose output is that of the daemon itself.  Otherwise the progress of
+	// its layers is summarized.
+	if output == io.Discard {
+		return n.displayPush(ctx, r, f.Build.Image)
+	}
 
 	var isTerminal bool
 	var fd uintptr
 	if outF, ok := output.(*os.File); ok {
 		fd = outF.Fd()
-		isTerminal = term.IsTerminal(int(outF.Fd()))
+		isTerminal = !n.plain && term.IsTerminal(int(outF.Fd()))
 	}
 
+	var outBuff bytes.Buffer
+	output = io.MultiWriter(&outBuff, output)
+
 	err = jsonmessage.DisplayJSONMessagesStream(r, output, fd, isTerminal, nil)
 	if err != nil {
 		return "", err
@@ -244,6 +312,70 @@ func (n *Pusher) daemonPush(ctx context.Context, f fn.Function, credentials oci.
 	return ParseDigest(outBuff.String()), nil
 }
 
+// displayPush reads the daemon's stream of push messages, reporting the
+// overall progress of its layers as a transfer.  When not interactive, the
+// completion of each layer is also reported.  The progress of each layer is
+// reported to the context's func(fn.DeployEvent), if any.
+func (n *Pusher) displayPush(ctx context.Context, r io.Reader, image string) (digest string, err error) {
+	interactive := !n.plain && progress.IsTerminal(os.Stderr)
+	transfer := progress.NewTransfer(os.Stderr, "pushing "+image, progress.WithInteractive(interactive))
+	layerProgress := fn.NewPushProgress(ctx, image)
+
+	type layer struct{ current, total int64 }
+	var (
+		layers = map[string]*layer{}
+		order  []string
+		dec    = json.NewDecoder(r)
+	)
+	for {
+		var jm jsonmessage.JSONMessage
+		if err = dec.Decode(&jm); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if jm.Error != nil {
+			return "", jm.Error
+		}
+		if d := ParseDigest(jm.Status); d != "" {
+			digest = d
+		}
+		if jm.ID == "" || jm.Status == "" || strings.Contains(jm.Status, "digest:") {
+			continue
+		}
+		l, ok := layers[jm.ID]
+		if !ok {
+			l = &layer{}
+			layers[jm.ID] = l
+			order = append(order, jm.ID)
+		}
+		switch {
+		case jm.Progress != nil && jm.Progress.Total > 0:
+			l.current, l.total = jm.Progress.Current, jm.Progress.Total
+		case jm.Status == "Pushed" || jm.Status == "Layer already exists" || strings.HasPrefix(jm.Status, "Mounted from"):
+			l.current = l.total
+			if !interactive {
+				if l.total > 0 {
+					fmt.Fprintf(os.Stderr, "layer %v: %v (%v)\n", jm.ID, strings.ToLower(jm.Status), progress.FormatBytes(l.total))
+				} else {
+					fmt.Fprintf(os.Stderr, "layer %v: %v\n", jm.ID, strings.ToLower(jm.Status))
+				}
+			}
+		default:
+			continue
+		}
+		layerProgress.Update(jm.ID, l.current, l.total)
+		var current, total int64
+		for _, id := range order {
+			current += layers[id].current
+			total += layers[id].total
+		}
+		transfer.Update(current, total)
+	}
+	transfer.Finish()
+	return digest, nil
+}
+
 var digestRE = regexp.MustCompile(`digest:\s+(sha256:\w{64})`)
 
 // ParseDigest tries to parse the last line from the output, which holds the pushed image digest
@@ -257,7 +389,7 @@ func ParseDigest(output string) string {
 	return ""
 }
 
-func (n *Pusher) push(ctx context.Context, f fn.Function, credentials oci.Credentials, output io.Writer) (digest string, err error) {
+func (n *Pusher) push(ctx context.Context, f fn.Function, credentials oci.Credentials) (digest string, err error) {
 	auth := &authn.Basic{
 		Username: credentials.Username,
 		Password: credentials.Password,
@@ -283,17 +415,19 @@ func (n *Pusher) push(ctx context.Context, f fn.Function, credentials oci.Creden
 
 	progressChannel := make(chan v1.Update, 1024)
 	errChan := make(chan error)
+	transfer := progress.NewTransfer(os.Stderr, "pushing "+f.Build.Image,
+		progress.WithInteractive(!n.plain && progress.IsTerminal(os.Stderr)))
+	pushProgress := fn.NewPushProgress(ctx, f.Build.Image)
 	go func() {
-		defer fmt.Fprint(output, "\n")
-
-		for progress := range progressChannel {
-			if progress.Error != nil {
-				errChan <- progress.Error
+		for update := range progressChannel {
+			if update.Error != nil {
+				errChan <- update.Error
 				return
 			}
-			fmt.Fprintf(output, "\rprogress: %d%%", progress.Complete*100/progress.Total)
+			transfer.Update(update.Complete, update.Total)
+			pushProgress.Update("", update.Complete, update.Total)
 		}
-
+		transfer.Finish()
 		errChan <- nil
 	}()
 
@@ -18,6 +18,7 @@ import (
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"slices"
@@ -31,6 +32,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	regTypes "github.com/google/go-containerregistry/pkg/v1/types"
@@ -481,3 +483,35 @@ func (c conn) SetDeadline(t time.Time) error { return nil }
 func (c conn) SetReadDeadline(t time.Time) error { return nil }
 
 func (c conn) SetWriteDeadline(t time.Time) error { return nil }
+
+// TestDigest ensures the digest of an image in its registry is resolved of
+// its tag.
+func TestDigest(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	image := strings.TrimPrefix(server.URL, "http://") + "/testuser/func:latest"
+
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := docker.NewPusher().Digest(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != expected.String() {
+		t.Fatalf("expected digest %v, got %v", expected, digest)
+	}
+}
@@ -0,0 +1,230 @@
+package functions
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"knative.dev/func/pkg/filesystem"
+	functar "knative.dev/func/pkg/tar"
+	"knative.dev/func/pkg/version"
+)
+
+const (
+	// BundleManifestFile is the name of the manifest of a bundle, at the root
+	// of its archive.
+	BundleManifestFile = "bundle.yaml"
+	// BundleSourceDir is the directory of a bundle's archive containing the
+	// function's project.
+	BundleSourceDir = "source"
+	// bundleVersion of the archives written.
+	bundleVersion = 1
+)
+
+// ErrBundleVersion indicates that a bundle was written by a newer version of
+// func than that importing it.
+var ErrBundleVersion = errors.New("unsupported bundle version")
+
+// BundleManifest describes the function of a bundle: a gzipped tar archive
+// of a function's project, for sharing the function with others.
+type BundleManifest struct {
+	BundleVersion int       `yaml:"bundleVersion"`
+	Exported      time.Time `yaml:"exported"`
+	// FuncVersion is the version of func by which the bundle was exported,
+	// and so that with which the project and its template are known to work.
+	FuncVersion string `yaml:"funcVersion,omitempty"`
+	Name        string `yaml:"name"`
+	Runtime     string `yaml:"runtime"`
+	Invoke      string `yaml:"invoke,omitempty"`
+	SpecVersion string `yaml:"specVersion"`
+	// Template and Repository from which the function was created, if known
+	// (see Function.Origin), such that the bundle may be traced to its source.
+	Template   string `yaml:"template,omitempty"`
+	Repository string `yaml:"repository,omitempty"`
+	// Image last built of the function, if included when exported.
+	Image string `yaml:"image,omitempty"`
+}
+
+// ExportBundle writes the function's project to w as a bundle, optionally
+// including the image last built of the function.  The project is that of
+// the function's build context, as well as its .gitignore and .funcignore,
+// such that files ignored by git (dependencies, build outputs etc.) are not
+// included.  The function's runtime metadata (.func) is not included.
+func ExportBundle(f Function, w io.Writer, includeImage bool) (m BundleManifest, err error) {
+	if !f.Initialized() {
+		return m, NewErrNotInitialized(f.Root)
+	}
+	m = BundleManifest{
+		BundleVersion: bundleVersion,
+		Exported:      time.Now().UTC(),
+		FuncVersion:   strings.TrimSpace(version.Vers + " " + version.Hash),
+		Name:          f.Name,
+		Runtime:       f.Runtime,
+		Invoke:        f.Invoke,
+		SpecVersion:   f.SpecVersion,
+		Template:      f.Origin.Template,
+		Repository:    f.Origin.Repository,
+	}
+	if includeImage {
+		if m.Image = f.Build.Image; m.Image == "" {
+			return m, fmt.Errorf("the function has not been built, so it has no image to include")
+		}
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	bb, err := yaml.Marshal(&m)
+	if err != nil {
+		return
+	}
+	if err = tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: BundleManifestFile, Mode: 0644,
+		Size: int64(len(bb)), ModTime: m.Exported}); err != nil {
+		return
+	}
+	if _, err = tw.Write(bb); err != nil {
+		return
+	}
+
+	ignorer, err := NewIgnorer(f.Root, "!"+IgnoreFile, "!.gitignore")
+	if err != nil {
+		return m, fmt.Errorf("cannot determine the function's files: %w", err)
+	}
+	err = ignorer.Walk(func(path string, fi fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(f.Root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = BundleSourceDir + "/" + filepath.ToSlash(rel)
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			if hdr.Linkname, err = bundleLink(f.Root, path); err != nil {
+				return err
+			}
+		}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return m, fmt.Errorf("cannot write bundle: %w", err)
+	}
+	if err = tw.Close(); err != nil {
+		return
+	}
+	return m, gw.Close()
+}
+
+// bundleLink returns the target of the symlink at path relative to the
+// link, which must be within the function's root.
+func bundleLink(root, path string) (string, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", err
+	}
+	abs := target
+	if !filepath.IsAbs(target) {
+		abs = filepath.Join(filepath.Dir(path), target)
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("link %q points outside the function", path)
+	}
+	if filepath.IsAbs(target) {
+		if target, err = filepath.Rel(filepath.Dir(path), abs); err != nil {
+			return "", err
+		}
+	}
+	return filepath.ToSlash(target), nil
+}
+
+// ImportBundle reads a bundle from r, writing its function's project to
+// root, which must be empty or not yet exist.  The image of the bundle, if
+// included, is recorded as that last built of the function.
+func ImportBundle(r io.Reader, root string) (f Function, m BundleManifest, err error) {
+	if root, err = filepath.Abs(root); err != nil {
+		return
+	}
+	if err = os.MkdirAll(root, 0755); err != nil {
+		return
+	}
+	if err = assertEmptyRoot(root); err != nil {
+		return
+	}
+
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return f, m, fmt.Errorf("not a function bundle: %w", err)
+	}
+	defer gr.Close()
+
+	// Extracted first to a temporary directory, such that the manifest is
+	// verified before anything is written to root.
+	tmp, err := os.MkdirTemp("", "func-bundle")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(tmp)
+	if err = functar.Extract(gr, tmp); err != nil {
+		return f, m, fmt.Errorf("cannot extract bundle: %w", err)
+	}
+	bb, err := os.ReadFile(filepath.Join(tmp, BundleManifestFile))
+	if err != nil {
+		return f, m, fmt.Errorf("not a function bundle, %v not found", BundleManifestFile)
+	}
+	if err = yaml.Unmarshal(bb, &m); err != nil {
+		return f, m, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if m.BundleVersion > bundleVersion {
+		return f, m, fmt.Errorf("%w %v. Upgrade func to import this bundle", ErrBundleVersion, m.BundleVersion)
+	}
+	if _, err = os.Stat(filepath.Join(tmp, BundleSourceDir, FunctionFile)); err != nil {
+		return f, m, fmt.Errorf("not a function bundle, %v not found", FunctionFile)
+	}
+	if err = filesystem.CopyFromFS(BundleSourceDir, root, filesystem.NewOsFilesystem(tmp)); err != nil {
+		return
+	}
+
+	if err = ensureRunDataDir(root); err != nil {
+		return
+	}
+	if f, err = NewFunction(root); err != nil {
+		return
+	}
+	if m.Image != "" {
+		f.Build.Image = m.Image
+		if err = f.WriteRuntimeBuiltImage(false); err != nil {
+			return
+		}
+	}
+	f, err = NewFunction(root)
+	return
+}
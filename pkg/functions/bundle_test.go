@@ -0,0 +1,140 @@
+package functions_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestBundle ensures that a function's project is exported as a bundle,
+// excluding files ignored by git and its runtime metadata, and that it can
+// be imported as a working project, with its built image if included.
+func TestBundle(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "original")
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go", Invoke: "cloudevent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		".gitignore":       "node_modules/\n",
+		"node_modules/x":   "ignored",
+		"docs/README.md":   "docs",
+		".func/local.yaml": "",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.Symlink("docs/README.md", filepath.Join(root, "GUIDE.md")); err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = "example.com/alice/original@sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	var bundle bytes.Buffer
+	m, err := fn.ExportBundle(f, &bundle, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "original" || m.Runtime != "go" || m.Image != f.Build.Image {
+		t.Fatalf("unexpected manifest %+v", m)
+	}
+	if m.Template != fn.DefaultTemplate || m.Repository != fn.DefaultRepositoryName {
+		t.Fatalf("expected the manifest to record the template of the function, got %+v", m)
+	}
+
+	imported := filepath.Join(t.TempDir(), "imported")
+	g, m, err := fn.ImportBundle(&bundle, imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Name != "original" || g.Runtime != "go" || g.Invoke != "cloudevent" || m.Invoke != "cloudevent" {
+		t.Fatalf("unexpected imported function %q (%v, %v)", g.Name, g.Runtime, g.Invoke)
+	}
+	if g.Build.Image != f.Build.Image {
+		t.Fatalf("expected the built image to be imported, got %q", g.Build.Image)
+	}
+	for _, name := range []string{"handle.go", "go.mod", "docs/README.md", ".gitignore", fn.IgnoreFile} {
+		if _, err = os.Stat(filepath.Join(imported, filepath.FromSlash(name))); err != nil {
+			t.Errorf("expected %v to be imported. %v", name, err)
+		}
+	}
+	for _, name := range []string{"node_modules", ".func/local.yaml"} {
+		if _, err = os.Stat(filepath.Join(imported, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("expected %v not to be imported", name)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(imported, "GUIDE.md")); err != nil || target != "docs/README.md" {
+		t.Errorf("expected the symlink to be imported, got %q (%v)", target, err)
+	}
+
+	// Importing into a project which is not empty is an error.
+	if _, _, err = fn.ImportBundle(bytes.NewReader(nil), imported); err == nil {
+		t.Fatal("expected an error importing into an existing function")
+	}
+}
+
+// TestExportBundle_Errors ensures that an image can not be included for a
+// function which has not been built, and that a symlink pointing outside the
+// function is rejected.
+func TestExportBundle_Errors(t *testing.T) {
+	root := t.TempDir()
+	f, err := fn.New().Init(fn.Function{Root: root, Runtime: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle bytes.Buffer
+	if _, err = fn.ExportBundle(f, &bundle, true); err == nil {
+		t.Fatal("expected an error including the image of an unbuilt function")
+	}
+	if err = os.Symlink(t.TempDir(), filepath.Join(root, "outside")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = fn.ExportBundle(f, &bundle, false); err == nil {
+		t.Fatal("expected an error for a symlink outside the function")
+	}
+	if _, _, err = fn.ImportBundle(bytes.NewReader([]byte("not a bundle")), t.TempDir()); err == nil {
+		t.Fatal("expected an error importing an invalid bundle")
+	}
+}
+
+// TestImportBundle_Version ensures that a bundle written by a newer version
+// of func is rejected.
+func TestImportBundle_Version(t *testing.T) {
+	var bundle bytes.Buffer
+	gw := gzip.NewWriter(&bundle)
+	tw := tar.NewWriter(gw)
+	for name, content := range map[string]string{
+		fn.BundleManifestFile:                      "bundleVersion: 99\nname: newer\n",
+		fn.BundleSourceDir + "/" + fn.FunctionFile: "name: newer\n",
+	} {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if _, _, err := fn.ImportBundle(&bundle, root); !errors.Is(err, fn.ErrBundleVersion) {
+		t.Fatalf("expected ErrBundleVersion, got %v", err)
+	}
+	if entries, _ := os.ReadDir(root); len(entries) > 0 {
+		t.Fatalf("expected nothing to be written importing an unsupported bundle")
+	}
+}
@@ -0,0 +1,51 @@
+package functions
+
+import (
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Kinds of build caches
+const (
+	CacheVolume    = "volume"    // a volume of the container engine
+	CacheImage     = "image"     // an image of the container engine
+	CacheDirectory = "directory" // a directory of the function
+)
+
+// BuildCache retained by a builder between builds to speed them, such as the
+// dependencies downloaded by pack kept in volumes, the images of builders
+// pulled, or the base layers of the host builder.
+type BuildCache struct {
+	// Builder which retains the cache, such as "pack".
+	Builder string `json:"builder"`
+	// Kind of the cache: CacheVolume, CacheImage or CacheDirectory.
+	Kind string `json:"kind"`
+	// Name of the volume, reference of the image, or path of the directory.
+	Name string `json:"name"`
+	// Size in bytes, or -1 if not known.
+	Size int64 `json:"size"`
+	// Modified is when the cache was created or last written.
+	Modified time.Time `json:"modified"`
+	// Shared caches are of all functions, such as the images of builders,
+	// rather than of the function alone.
+	Shared bool `json:"shared,omitempty"`
+}
+
+// CacheRepository of the function's image, less its registry and tag, by
+// which builders name the caches of its builds; empty if the function has no
+// image nor registry.
+func (f Function) CacheRepository() string {
+	image := f.Build.Image
+	if image == "" {
+		image = f.Image
+	}
+	if image == "" {
+		image, _ = f.ImageName()
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return ""
+	}
+	return ref.Context().RepositoryStr()
+}
@@ -13,9 +13,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	"gopkg.in/yaml.v2"
 
 	"knative.dev/func/pkg/scaffolding"
@@ -66,11 +69,23 @@ type Client struct {
 	verbose           bool              // print verbose logs
 	builder           Builder           // Builds a runnable image source
 	pusher            Pusher            // Pushes function image to a remote
+	verifier          Verifier          // Verifies image signatures
+	policy            PolicyEvaluator   // Evaluates deployment policies
+	digestResolver    DigestResolver    // Resolves the digests of images
+	tagLister         TagLister         // Lists the tags of images
+	differ            Differ            // Snapshots deployments for diffing
+	sbomGenerator     SBOMGenerator     // Generates software bills of materials
+	signer            Signer            // Signs pushed images
+	scanner           Scanner           // Scans built images for vulnerabilities
+	buildCachers      []BuildCacher     // Inspect and prune build caches
+	hookRunner        HookRunner        // Runs the container image hooks
+	attacher          Attacher          // Attaches artifacts to images
 	deployer          Deployer          // Deploys or Updates a function
 	runner            Runner            // Runs the function locally
 	remover           Remover           // Removes remote services
 	lister            Lister            // Lists remote services
 	describer         Describer         // Describes function instances
+	metricsProvider   MetricsProvider   // Queries invocation metrics
 	dnsProvider       DNSProvider       // Provider of DNS services
 	registry          string            // default registry for OCI image tags
 	repositories      *Repositories     // Repositories management
@@ -88,6 +103,21 @@ type Builder interface {
 	Build(context.Context, Function, []Platform) error
 }
 
+// BuildOutputKey is a type available for use as a context key for providing
+// an io.Writer to which the client, and builders which support it, write the
+// output of a build in place of stdout and stderr, such as to keep apart the
+// output of builds run concurrently.
+type BuildOutputKey struct{}
+
+// BuildOutput is the io.Writer of the context for the output of a build, or
+// w if none.
+func BuildOutput(ctx context.Context, w io.Writer) io.Writer {
+	if out, ok := ctx.Value(BuildOutputKey{}).(io.Writer); ok && out != nil {
+		return out
+	}
+	return w
+}
+
 // Pusher of function image to a registry.
 type Pusher interface {
 	// Push the image of the function.
@@ -95,6 +125,99 @@ type Pusher interface {
 	Push(ctx context.Context, f Function) (string, error)
 }
 
+// Verifier of function image signatures.
+type Verifier interface {
+	// Verify the image to be deployed satisfies the function's signature
+	// policy (f.Deploy.Verify).  Returns the digest of the image verified, or
+	// an error if it does not.
+	Verify(ctx context.Context, f Function) (string, error)
+}
+
+// PolicyEvaluator of the manifests rendered for a function before they are
+// applied.
+type PolicyEvaluator interface {
+	// Evaluate the manifests, returning an error if they violate policy.
+	Evaluate(ctx context.Context, manifests ...map[string]any) error
+}
+
+// BuildCacher of the caches a builder retains between builds.
+type BuildCacher interface {
+	// Caches of the function, including those shared by all functions.
+	Caches(ctx context.Context, f Function) ([]BuildCache, error)
+
+	// Remove the cache.
+	Remove(ctx context.Context, c BuildCache) error
+}
+
+// Signer of function images.
+type Signer interface {
+	// Sign the function's pushed image (f.Build.Image) as configured by
+	// f.Build.Sign, returning the reference of the signature.
+	Sign(ctx context.Context, f Function) (string, error)
+}
+
+// Scanner of function images for known vulnerabilities.
+type Scanner interface {
+	// Scan the function's built image, written as an OCI archive to path,
+	// with the scanner of f.Build.Scan.
+	Scan(ctx context.Context, f Function, path string) (ScanReport, error)
+}
+
+// DigestResolver of the digests of images in their registries.
+type DigestResolver interface {
+	// Digest of the image, as a reference by tag or digest, in its registry.
+	Digest(ctx context.Context, image string) (string, error)
+}
+
+// TagLister of the tags of image repositories in their registries.
+type TagLister interface {
+	// Tags of the repository, such as docker.io/library/python.
+	Tags(ctx context.Context, repository string) ([]string, error)
+}
+
+// Rebaser is implemented by builders able to replace the run image of the
+// function's built image without building it again.
+type Rebaser interface {
+	// Rebase the function's built image (f.Build.Image) upon its run image
+	// (f.Build.RunImage).
+	Rebase(ctx context.Context, f Function) error
+}
+
+// SBOMGenerator of software bills of materials of functions.
+type SBOMGenerator interface {
+	// Generate the SBOM of the function's dependencies in its format
+	// (f.Build.SBOM), writing it to w.
+	Generate(ctx context.Context, f Function, w io.Writer) error
+}
+
+// Differ of a function's deployment from that which deploying it would
+// produce.
+type Differ interface {
+	// Snapshots of the function's deployment, as described by the cluster,
+	// and as it would be were the function deployed.
+	Snapshots(ctx context.Context, f Function) (deployed, desired Snapshot, err error)
+}
+
+// HookRunner of the hooks of a function which are container images.
+type HookRunner interface {
+	// Run the image of the hook to completion, with the environment given,
+	// returning an error if it fails.
+	Run(ctx context.Context, f Function, h Hook, env []string) error
+}
+
+// Attacher of artifacts to function images, and of their retrieval.
+type Attacher interface {
+	// Attach the function's artifacts (f.Build.Artifacts) to its pushed
+	// image (f.Build.Image, including its digest).
+	Attach(ctx context.Context, f Function) ([]Artifact, error)
+
+	// Artifacts attached to the image.
+	Artifacts(ctx context.Context, image string) ([]Artifact, error)
+
+	// Fetch the content of an artifact attached to the image, writing it to w.
+	Fetch(ctx context.Context, image string, a Artifact, w io.Writer) error
+}
+
 // PushUsernameKey is a type available for use to communicate a basic
 // authentication username to pushers which support this method.
 type PushUsernameKey struct{}
@@ -111,6 +234,176 @@ type PushTokenKey struct{}
 type Deployer interface {
 	// Deploy a function of given name, using given backing image.
 	Deploy(context.Context, Function) (DeploymentResult, error)
+
+	// Render the resources which would be created or updated on deployment
+	// of the function, as a YAML stream, without creating or updating them.
+	Render(context.Context, Function, DryRun) ([]byte, error)
+}
+
+// DryRun is the mode of rendering a deployment without deploying it.
+type DryRun string
+
+const (
+	// DryRunClient renders the resources of a deployment without contacting
+	// the cluster.
+	DryRunClient DryRun = "client"
+	// DryRunServer submits the resources of a deployment to the cluster for
+	// admission, such that they are validated and defaulted as they would be
+	// on deployment, but not persisted.
+	DryRunServer DryRun = "server"
+)
+
+// ValidDryRun reports whether the mode is one of the dry run modes.
+func ValidDryRun(mode DryRun) bool {
+	return mode == DryRunClient || mode == DryRunServer
+}
+
+// Wait is the condition of a deployment upon which a deployer returns.
+type Wait string
+
+const (
+	// WaitNone returns once the resources of the deployment are created or
+	// updated, without waiting for them to become ready.
+	WaitNone Wait = "none"
+	// WaitReady returns once the revision deployed is ready.  The default.
+	WaitReady Wait = "ready"
+	// WaitTrafficShifted returns once the revision deployed is ready and the
+	// traffic of the function is routed as requested.
+	WaitTrafficShifted Wait = "traffic-shifted"
+)
+
+// ValidWait reports whether the condition is one of the wait conditions.
+func ValidWait(w Wait) bool {
+	return w == WaitNone || w == WaitReady || w == WaitTrafficShifted
+}
+
+// DeployWaitKey is a type available for use as a context key for providing
+// the Wait condition to deployers which support it.
+type DeployWaitKey struct{}
+
+// DeployWaitTimeoutKey is a type available for use as a context key for
+// providing the maximum duration for which a deployer waits, as a
+// time.Duration, to deployers which support it.
+type DeployWaitTimeoutKey struct{}
+
+// DeployRetriesKey is a type available for use as a context key for
+// providing the number of attempts, as an int, at applying the resources of
+// a deployment upon conflicts or transient errors of the cluster, to
+// deployers which support it.
+type DeployRetriesKey struct{}
+
+// DeployRetryTimeoutKey is a type available for use as a context key for
+// providing the longest, as a time.Duration, for which a deployer retries.
+type DeployRetryTimeoutKey struct{}
+
+// DeployEventsKey is a type available for use as a context key for providing
+// a func(DeployEvent) to which the client, as it builds, pushes and deploys,
+// and builders, pushers and deployers which support it report progress.
+type DeployEventsKey struct{}
+
+// DeployStage is a stage of the progress of a deployment.
+type DeployStage string
+
+const (
+	// StageBuildStarted is reached once the build of the function's image is
+	// started.
+	StageBuildStarted DeployStage = "build-started"
+	// StagePhase is reached as each phase of the build or push is started
+	// (see Phase).
+	StagePhase DeployStage = "phase"
+	// StageBuilt is reached once the image is built.
+	StageBuilt DeployStage = "built"
+	// StagePushProgress is reached as each quarter of a layer of the image,
+	// or of the image as a whole when its layers are not reported, is pushed.
+	StagePushProgress DeployStage = "push-progress"
+	// StageImagePushed is reached once the image is pushed, its digest
+	// known.
+	StageImagePushed DeployStage = "image-pushed"
+	// StageRevisionCreated is reached once the revision deployed is created.
+	StageRevisionCreated DeployStage = "revision-created"
+	// StageImagePulled is reached once the image of the revision is pulled
+	// and its containers started.
+	StageImagePulled DeployStage = "image-pulled"
+	// StageReady is reached once the revision is ready to serve.
+	StageReady DeployStage = "ready"
+	// StageRouted is reached once the traffic is routed as requested.
+	StageRouted DeployStage = "routed"
+	// StageDeployed is reached once the deployer returns, the URL of the
+	// function known.
+	StageDeployed DeployStage = "deployed"
+)
+
+// Phases of a build or push, as reported by StagePhase.  The pack builder
+// reports those of the buildpacks lifecycle, detect through export; others
+// those of their own which correspond.
+const (
+	PhaseDetect  = "detect"
+	PhaseAnalyze = "analyze"
+	PhaseRestore = "restore"
+	PhaseBuild   = "build"
+	PhaseExport  = "export"
+	PhasePush    = "push"
+)
+
+// DeployEvent reports a stage of a deployment's progress being reached.
+// Elapsed is the time since the step of which it is a stage was started:
+// the build, the push, or the deployment.  Of StagePushProgress, Complete
+// and Total are the bytes of the Layer pushed thus far and in all.
+type DeployEvent struct {
+	Stage    DeployStage   `json:"stage"`
+	Phase    string        `json:"phase,omitempty"`
+	Revision string        `json:"revision,omitempty"`
+	Image    string        `json:"image,omitempty"`
+	Digest   string        `json:"digest,omitempty"`
+	Layer    string        `json:"layer,omitempty"`
+	Complete int64         `json:"complete,omitempty"`
+	Total    int64         `json:"total,omitempty"`
+	URL      string        `json:"url,omitempty"`
+	Elapsed  time.Duration `json:"elapsed"`
+}
+
+// ReportDeployEvent to the func(DeployEvent) of the context, if any.  Used
+// by builders and pushers to report their phases and progress.
+func ReportDeployEvent(ctx context.Context, e DeployEvent) {
+	if events, ok := ctx.Value(DeployEventsKey{}).(func(DeployEvent)); ok && events != nil {
+		events(e)
+	}
+}
+
+// PushProgress reports the progress of the push of an image as
+// StagePushProgress events: of each layer as it is first reported, as each
+// quarter of it completes, and as it completes, such that the events are few
+// however often the pusher is updated.  Safe for concurrent use.
+type PushProgress struct {
+	ctx      context.Context
+	image    string
+	mu       sync.Mutex
+	quarters map[string]int64
+}
+
+// NewPushProgress of the image, reported to the func(DeployEvent) of the
+// context, if any.
+func NewPushProgress(ctx context.Context, image string) *PushProgress {
+	return &PushProgress{ctx: ctx, image: image, quarters: map[string]int64{}}
+}
+
+// Update the progress of the layer, or of the image as a whole if "", with
+// the bytes pushed thus far of its total.  A layer of unknown total is
+// reported as complete once complete equals it.
+func (p *PushProgress) Update(layer string, complete, total int64) {
+	q := int64(4)
+	if total > 0 && complete < total {
+		q = complete * 4 / total
+	}
+	p.mu.Lock()
+	last, seen := p.quarters[layer]
+	if seen && q <= last {
+		p.mu.Unlock()
+		return
+	}
+	p.quarters[layer] = q
+	p.mu.Unlock()
+	ReportDeployEvent(p.ctx, DeployEvent{Stage: StagePushProgress, Image: p.image, Layer: layer, Complete: complete, Total: total})
 }
 
 type DeploymentResult struct {
@@ -145,16 +438,42 @@ type Remover interface {
 
 // Lister of deployed functions.
 type Lister interface {
-	// List the functions currently deployed.
-	List(ctx context.Context, namespace string) ([]ListItem, error)
+	// List the functions currently deployed, optionally only those matching
+	// a Kubernetes label selector such as "team=payments,env!=prod".
+	List(ctx context.Context, namespace, selector string) ([]ListItem, error)
+
+	// ListPage lists at most limit functions, continuing from the token of a
+	// previous page if given.  Returned is the token from which the next page
+	// is listed, which is empty once all functions have been listed.
+	ListPage(ctx context.Context, namespace, selector string, limit int64, token string) ([]ListItem, string, error)
+
+	// Watch the functions deployed, receiving their list initially and again
+	// each time a function is deployed, changes status or is deleted.  The
+	// channel is closed once the context is done.
+	Watch(ctx context.Context, namespace, selector string) (<-chan []ListItem, error)
 }
 
 type ListItem struct {
-	Name      string `json:"name" yaml:"name"`
-	Namespace string `json:"namespace" yaml:"namespace"`
-	Runtime   string `json:"runtime" yaml:"runtime"`
-	URL       string `json:"url" yaml:"url"`
-	Ready     string `json:"ready" yaml:"ready"`
+	Name      string    `json:"name" yaml:"name"`
+	Namespace string    `json:"namespace" yaml:"namespace"`
+	Runtime   string    `json:"runtime" yaml:"runtime"`
+	URL       string    `json:"url" yaml:"url"`
+	Ready     string    `json:"ready" yaml:"ready"`
+	Created   time.Time `json:"created" yaml:"created"`
+	Image     string    `json:"image" yaml:"image"`
+	Revision  string    `json:"revision" yaml:"revision"`
+	// Traffic of the function as routed to its revisions.
+	Traffic []TrafficTarget `json:"traffic,omitempty" yaml:"traffic,omitempty"`
+	// Provisioning of the function's resources and scale.
+	Provisioning Provisioning `json:"provisioning" yaml:"provisioning"`
+	// Bindings of events to the function, by the triggers, sink bindings and
+	// channel subscriptions whose sink it is.
+	Bindings []EventBinding `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	// Location of the function when listed with local functions: "local" if
+	// only initialized locally, "deployed" if only deployed, or "both".
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+	// Path of the function's local source, if any.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
 }
 
 // Describer of function instances
@@ -163,6 +482,25 @@ type Describer interface {
 	Describe(ctx context.Context, name, namespace string) (Instance, error)
 }
 
+// MetricsProvider of the metrics of deployed functions' invocations.
+type MetricsProvider interface {
+	// Metrics of the named function's invocations over the window of time
+	// until now.  Returns ErrMetricsNotAvailable if there is no source of
+	// metrics.
+	Metrics(ctx context.Context, name, namespace string, window time.Duration) (Metrics, error)
+}
+
+// Metrics of a function's invocations over a window of time.
+type Metrics struct {
+	Window   time.Duration `json:"window" yaml:"window"`
+	Requests float64       `json:"requests" yaml:"requests"`
+	// ErrorRate is the fraction of requests which failed with a server error.
+	ErrorRate float64 `json:"errorRate" yaml:"errorRate"`
+	// LatencyP95 is the 95th percentile of the latency of requests, or zero
+	// if not known.
+	LatencyP95 time.Duration `json:"latencyP95" yaml:"latencyP95"`
+}
+
 // Instance data about the runtime state of a function in a given environment.
 //
 // A function instance is a logical running function space, which share
@@ -174,7 +512,7 @@ type Describer interface {
 // function information structures.
 type Instance struct {
 	// Route is the primary route of a function instance.
-	Route string
+	Route string `json:"route" yaml:"route"`
 	// Routes is the primary route plus any other route at which the function
 	// can be contacted.
 	Routes        []string          `json:"routes" yaml:"routes"`
@@ -183,6 +521,39 @@ type Instance struct {
 	Namespace     string            `json:"namespace" yaml:"namespace"`
 	Subscriptions []Subscription    `json:"subscriptions" yaml:"subscriptions"`
 	Labels        map[string]string `json:"labels" yaml:"labels" xml:"-"`
+	// Bindings of events to the instance, by the triggers, sink bindings and
+	// channel subscriptions whose sink it is.
+	Bindings []EventBinding `json:"bindings,omitempty" yaml:"bindings,omitempty"`
+	// Artifacts attached to the instance's image, if requested.
+	Artifacts []Artifact `json:"artifacts,omitempty" yaml:"artifacts,omitempty"`
+	// Traffic of the instance as routed to its revisions.
+	Traffic []TrafficTarget `json:"traffic,omitempty" yaml:"traffic,omitempty"`
+	// Provisioning of the instance's resources and scale.
+	Provisioning Provisioning `json:"provisioning" yaml:"provisioning"`
+	// Metrics of the instance's recent invocations, if available.
+	Metrics *Metrics `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// TrafficTarget is the percentage of a function's traffic routed to one of
+// its revisions, optionally also at the URL of a tag.
+type TrafficTarget struct {
+	Revision string `json:"revision" yaml:"revision"`
+	Percent  int64  `json:"percent" yaml:"percent"`
+	Tag      string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Latest is true if the traffic follows the latest ready revision.
+	Latest bool `json:"latest,omitempty" yaml:"latest,omitempty"`
+}
+
+// Provisioning of a deployed function as configured: the CPU and memory
+// requested and limited of its container, such as "100m" and "256Mi", and the
+// bounds of its scale.  Empty values are not configured.
+type Provisioning struct {
+	CPURequest    string `json:"cpuRequest,omitempty" yaml:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty" yaml:"cpuLimit,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty" yaml:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty" yaml:"memoryLimit,omitempty"`
+	MinScale      *int64 `json:"minScale,omitempty" yaml:"minScale,omitempty"`
+	MaxScale      *int64 `json:"maxScale,omitempty" yaml:"maxScale,omitempty"`
 }
 
 // Subscriptions currently active to event sources
@@ -192,6 +563,17 @@ type Subscription struct {
 	Broker string `json:"broker" yaml:"broker"`
 }
 
+// EventBinding of events to a function instance, such as a Trigger of a
+// Broker.  From is the origin of the events as Kind/Name: the Broker of a
+// Trigger, the subject of a SinkBinding or the channel of a Subscription.
+type EventBinding struct {
+	Kind string `json:"kind" yaml:"kind"`
+	Name string `json:"name" yaml:"name"`
+	From string `json:"from" yaml:"from"`
+	// Filter of the events of a Trigger as attribute=value.
+	Filter string `json:"filter,omitempty" yaml:"filter,omitempty"`
+}
+
 // DNSProvider exposes DNS services necessary for serving the function.
 type DNSProvider interface {
 	// Provide the given name by routing requests to address.
@@ -218,10 +600,21 @@ func New(options ...Option) *Client {
 	c := &Client{
 		builder:           &noopBuilder{output: os.Stdout},
 		pusher:            &noopPusher{output: os.Stdout},
+		verifier:          &noopVerifier{},
+		policy:            &noopPolicyEvaluator{},
+		digestResolver:    &noopDigestResolver{},
+		tagLister:         &noopTagLister{},
+		differ:            &noopDiffer{},
+		sbomGenerator:     &noopSBOMGenerator{},
+		signer:            &noopSigner{},
+		scanner:           &noopScanner{},
+		hookRunner:        &noopHookRunner{},
+		attacher:          &noopAttacher{},
 		deployer:          &noopDeployer{output: os.Stdout},
 		remover:           &noopRemover{output: os.Stdout},
 		lister:            &noopLister{output: os.Stdout},
 		describer:         &noopDescriber{output: os.Stdout},
+		metricsProvider:   &noopMetricsProvider{},
 		dnsProvider:       &noopDNSProvider{output: os.Stdout},
 		pipelinesProvider: &noopPipelinesProvider{},
 		mcpServer:         &noopMCPServer{},
@@ -284,6 +677,94 @@ func WithPusher(d Pusher) Option {
 	}
 }
 
+// WithAttacher provides the concrete implementation of an attacher of
+// artifacts to function images.
+func WithAttacher(a Attacher) Option {
+	return func(c *Client) {
+		c.attacher = a
+	}
+}
+
+// WithVerifier provides the concrete implementation of an image signature
+// verifier.
+func WithVerifier(v Verifier) Option {
+	return func(c *Client) {
+		c.verifier = v
+	}
+}
+
+// WithPolicy provides the concrete implementation of an evaluator of the
+// deployment policies which the resources rendered of a function must
+// satisfy in order to be deployed, by any deployer or pipeline.
+func WithPolicy(p PolicyEvaluator) Option {
+	return func(c *Client) {
+		c.policy = p
+	}
+}
+
+// WithBuildCachers provides the concrete implementations of the build
+// caches of builders.  By default no caches are listed.
+func WithBuildCachers(cc ...BuildCacher) Option {
+	return func(c *Client) {
+		c.buildCachers = cc
+	}
+}
+
+// WithSigner provides the concrete implementation of an image signer.
+func WithSigner(s Signer) Option {
+	return func(c *Client) {
+		c.signer = s
+	}
+}
+
+// WithScanner provides the concrete implementation of a scanner of images
+// for vulnerabilities.
+func WithScanner(s Scanner) Option {
+	return func(c *Client) {
+		c.scanner = s
+	}
+}
+
+// WithDigestResolver provides the concrete implementation of a resolver of
+// the digests of images in their registries.
+func WithDigestResolver(r DigestResolver) Option {
+	return func(c *Client) {
+		c.digestResolver = r
+	}
+}
+
+// WithTagLister provides the concrete implementation of a lister of the
+// tags of images in their registries.
+func WithTagLister(l TagLister) Option {
+	return func(c *Client) {
+		c.tagLister = l
+	}
+}
+
+// WithDiffer provides the concrete implementation of the snapshots of
+// deployments from which their drift is diffed.
+func WithDiffer(d Differ) Option {
+	return func(c *Client) {
+		c.differ = d
+	}
+}
+
+// WithSBOMGenerator provides the concrete implementation of a generator of
+// the software bills of materials of functions.
+func WithSBOMGenerator(g SBOMGenerator) Option {
+	return func(c *Client) {
+		c.sbomGenerator = g
+	}
+}
+
+// WithHookRunner provides the concrete implementation of a runner of the
+// container image hooks of functions.
+func WithHookRunner(r HookRunner) Option {
+	return func(c *Client) {
+		c.hookRunner = r
+	}
+}
+
 // WithDeployer provides the concrete implementation of a deployer.
 func WithDeployer(d Deployer) Option {
 	return func(c *Client) {
@@ -319,6 +800,14 @@ func WithDescriber(describer Describer) Option {
 	}
 }
 
+// WithMetricsProvider provides a concrete implementation of a provider of
+// the metrics of functions' invocations.
+func WithMetricsProvider(provider MetricsProvider) Option {
+	return func(c *Client) {
+		c.metricsProvider = provider
+	}
+}
+
 // WithDNSProvider proivdes a DNS provider implementation for registering the
 // effective DNS name which is either explicitly set via WithName or is derived
 // from the root path.
@@ -644,7 +1133,8 @@ func BuildWithPlatforms(pp []Platform) BuildOption {
 // Build the function at path. Errors if the function is either unloadable or does
 // not contain a populated Image.
 func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption) (Function, error) {
-	fmt.Fprintf(os.Stderr, "Building function image\n")
+	out := BuildOutput(ctx, os.Stderr)
+	fmt.Fprintf(out, "Building function image\n")
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -668,19 +1158,50 @@ func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption)
 
 	// If no image name has been specified by user (--image), calculate.
 	// Image name is stored on the function for later use by deploy, etc.
+	// Its tag is derived by the function's tag strategy, or the expressions
+	// of its image, and recorded for the push and deploy of the build.
 	var err error
+	if f.Deploy.Tag, err = f.deriveTag(time.Now()); err != nil {
+		return f, fmt.Errorf("cannot derive the tag of the function's image: %w", err)
+	}
 	if f.Image == "" {
 		if f.Build.Image, err = f.ImageName(); err != nil {
 			return f, err
 		}
 	} else {
-		f.Build.Image = f.Image
+		f.Build.Image = f.TaggedImage()
 	}
 
-	if err = c.builder.Build(ctx, f, oo.Platforms); err != nil {
+	// Encrypted build environment variables are provided to the builder in
+	// plaintext, leaving the function itself (and thus func.yaml) encrypted.
+	decrypted, err := f.Decrypt()
+	if err != nil {
+		return f, err
+	}
+	if err = c.runHooks(ctx, decrypted, HookPreBuild, ""); err != nil {
+		return f, err
+	}
+	start := time.Now()
+	ReportDeployEvent(ctx, DeployEvent{Stage: StageBuildStarted, Image: f.Build.Image})
+	if err = c.builder.Build(ctx, decrypted, oo.Platforms); err != nil {
+		return f, err
+	}
+	ReportDeployEvent(ctx, DeployEvent{Stage: StageBuilt, Image: f.Build.Image, Elapsed: time.Since(start)})
+	if f.Build.SBOM != "" {
+		if err = c.writeSBOM(ctx, decrypted); err != nil {
+			return f, err
+		}
+	}
+	if err = c.runHooks(ctx, decrypted, HookPostBuild, ""); err != nil {
 		return f, err
 	}
 
+	// The images of each of several platforms are assembled on push.
+	f.Build.Platforms = nil
+	if len(oo.Platforms) > 1 {
+		f.Build.Platforms = oo.Platforms
+	}
+
 	// write .func/built-name as running metadata which is not persisted in yaml
 	if err = f.WriteRuntimeBuiltImage(c.verbose); err != nil {
 		return f, err
@@ -696,7 +1217,7 @@ func (c *Client) Build(ctx context.Context, f Function, options ...BuildOption)
 	if runtime.GOOS == "windows" {
 		message = fmt.Sprintf("Function built: %v", f.Build.Image)
 	}
-	fmt.Fprintf(os.Stderr, "%s\n", message)
+	fmt.Fprintf(out, "%s\n", message)
 
 	return f, err
 }
@@ -724,12 +1245,13 @@ func (c *Client) printBuildActivity(ctx context.Context) {
 		"This is taking a while",
 	}
 	i := 0
+	out := BuildOutput(ctx, os.Stderr)
 	ticker := time.NewTicker(10 * time.Second)
 	go func() {
 		for {
 			select {
 			case <-ticker.C:
-				fmt.Fprintf(os.Stderr, "%v\n", m[i])
+				fmt.Fprintf(out, "%v\n", m[i])
 				i++
 				i = i % len(m)
 			case <-ctx.Done():
@@ -742,6 +1264,7 @@ func (c *Client) printBuildActivity(ctx context.Context) {
 
 type DeployOptions struct {
 	skipBuiltCheck bool
+	pinDigest      bool
 }
 type DeployOption func(f *DeployOptions)
 
@@ -751,6 +1274,15 @@ func WithDeploySkipBuildCheck(skipBuiltCheck bool) DeployOption {
 	}
 }
 
+// WithDeployPinDigest deploys the image by the digest it has in its
+// registry, rather than by its tag, refusing to deploy it should that not be
+// the digest of the image as built and pushed.
+func WithDeployPinDigest(pinDigest bool) DeployOption {
+	return func(f *DeployOptions) {
+		f.pinDigest = pinDigest
+	}
+}
+
 // Deploy the function at path.
 // Errors if the function has not been built unless explicitly instructed
 // to ignore this build check.
@@ -777,6 +1309,40 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 		return f, ErrNameRequired
 	}
 
+	// Pin the image before it is verified, such that that verified is that
+	// deployed.
+	if options.pinDigest {
+		var err error
+		if f, err = c.pinDigest(ctx, f); err != nil {
+			return f, err
+		}
+	}
+
+	// Enforce the signature policy, if any, before anything is created,
+	// updated or removed on the cluster.  The image deployed is that verified,
+	// by digest, such that its tag can not be moved in the meantime.
+	if f.Deploy.Verify != nil {
+		if c.verbose {
+			fmt.Fprintf(os.Stderr, "🔏 Verifying image signature\n")
+		}
+		digest, err := c.verifier.Verify(ctx, f)
+		if err != nil {
+			return f, fmt.Errorf("image verification failed. %w", err)
+		}
+		if f, err = pinVerified(f, digest); err != nil {
+			return f, err
+		}
+	}
+
+	// As are deployment policies, whichever the deployer.
+	decrypted, err := f.Decrypt()
+	if err != nil {
+		return f, err
+	}
+	if err = c.checkPolicy(ctx, decrypted); err != nil {
+		return f, err
+	}
+
 	// Warn if moving
 	changingNamespace := func(f Function) bool {
 		// We're changing namespace if:
@@ -811,12 +1377,23 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 	if c.verbose {
 		fmt.Fprintf(os.Stderr, "⬆️  Deploying \n")
 	}
-	result, err := c.deployer.Deploy(ctx, f)
+	if err = c.runHooks(ctx, decrypted, HookPreDeploy, ""); err != nil {
+		return f, err
+	}
+	start := time.Now()
+	result, err := c.deployer.Deploy(ctx, decrypted)
 	if err != nil {
 		return f, fmt.Errorf("deploy error. %w", err)
 	}
-	// Update the function to reflect the new deployed state of the Function
+	ReportDeployEvent(ctx, DeployEvent{Stage: StageDeployed, URL: result.URL, Elapsed: time.Since(start)})
+
+	// Update the function to reflect the new deployed state of the Function,
+	// which is so even should a postDeploy hook fail.
 	f.Deploy.Namespace = result.Namespace
+	decrypted.Deploy.Namespace = result.Namespace
+	if err = c.runHooks(ctx, decrypted, HookPostDeploy, result.URL); err != nil {
+		return f, fmt.Errorf("%w: %w", ErrPostDeployHookFailed, err)
+	}
 
 	switch result.Status {
 	case Deployed:
@@ -829,15 +1406,190 @@ func (c *Client) Deploy(ctx context.Context, f Function, oo ...DeployOption) (Fu
 	return f, nil
 }
 
+// pinDigest of the image to be deployed, resolving the digest of its tag in
+// the registry.  An image pushed has the digest of its push, which that of
+// its tag must yet be; it is otherwise deployed as it has been overwritten
+// since.
+func (c *Client) pinDigest(ctx context.Context, f Function) (Function, error) {
+	image := f.Deploy.Image
+	if image == "" {
+		image = f.Build.Image
+	}
+	if image == "" {
+		return f, ErrNotBuilt
+	}
+	tagged, pushed, _ := strings.Cut(image, "@")
+	if pushed != "" {
+		// The tag of a pushed image is that as which it was pushed
+		switch {
+		case f.Image != "" && !strings.Contains(f.Image, "@"):
+			tagged = f.TaggedImage()
+		case f.Registry != "":
+			if name, err := f.ImageName(); err == nil {
+				tagged = name
+			}
+		default:
+			tagged = image // the digest is verified to exist
+		}
+	}
+	ref, err := name.ParseReference(tagged)
+	if err != nil {
+		return f, fmt.Errorf("cannot parse image %q: %w", tagged, err)
+	}
+	digest, err := c.digestResolver.Digest(ctx, tagged)
+	if err != nil {
+		return f, fmt.Errorf("cannot resolve the digest of %v: %w", tagged, err)
+	}
+	if pushed != "" && digest != pushed {
+		return f, fmt.Errorf("%w: %v is %v in the registry, but %v was pushed", ErrDigestMismatch, tagged, digest, pushed)
+	}
+	f.Deploy.Image = ref.Context().Name() + "@" + digest
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "📌 Pinned the image to %v\n", f.Deploy.Image)
+	}
+	return f, nil
+}
+
+// pinVerified sets the image deployed to that of the digest verified.
+func pinVerified(f Function, digest string) (Function, error) {
+	if digest == "" {
+		return f, errors.New("image verification failed. no digest was verified")
+	}
+	image := f.Deploy.Image
+	if image == "" {
+		image = f.Build.Image
+	}
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return f, fmt.Errorf("cannot parse image %q: %w", image, err)
+	}
+	if d, ok := ref.(name.Digest); ok && d.DigestStr() != digest {
+		return f, fmt.Errorf("%w: %v was verified as %v", ErrDigestMismatch, image, digest)
+	}
+	f.Deploy.Image = ref.Context().Name() + "@" + digest
+	return f, nil
+}
+
+// Render the resources which deploying the function would create or update,
+// without deploying it.  Unlike Deploy, the function need not have been
+// built, as the image it would be deployed with is rendered as given.
+// Encrypted values are rendered redacted (see Function.Redact), as rendered
+// resources are printed or written to be committed.  Deployment policies are
+// evaluated against the resources rendered.
+func (c *Client) Render(ctx context.Context, f Function, mode DryRun) ([]byte, error) {
+	if f.Name == "" {
+		return nil, ErrNameRequired
+	}
+	if !ValidDryRun(mode) {
+		return nil, fmt.Errorf("invalid dry run %q. Expected %q or %q", mode, DryRunClient, DryRunServer)
+	}
+	manifests, err := c.deployer.Render(ctx, f.Redact(), mode)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.evaluatePolicy(ctx, manifests); err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// PromoteOptions of promoting a function.
+type PromoteOptions struct {
+	// SourceContext in which the function is described in the namespace from
+	// which it is promoted, such as one of another cluster.  Default is the
+	// context of the promotion, in which it is deployed to the target.
+	SourceContext context.Context
+}
+
+type PromoteOption func(*PromoteOptions)
+
+// WithPromoteSource sets the context in which the function is described in
+// the namespace from which it is promoted, such that it may be promoted
+// between clusters.
+func WithPromoteSource(ctx context.Context) PromoteOption {
+	return func(o *PromoteOptions) {
+		o.SourceContext = ctx
+	}
+}
+
+// Promote the function deployed in one namespace to another, deploying
+// exactly the image deployed there, by digest, without building.  The
+// function deployed to the target is that given, such as with a profile
+// applied, so its configuration may differ between namespaces but its image
+// does not.  The function's record of the namespace in which it is deployed
+// is not changed.  Returned is the function as deployed to the target.
+//
+// The namespaces may be the same of a source in another context, such as of
+// another cluster (see WithPromoteSource).
+func (c *Client) Promote(ctx context.Context, f Function, from, to string, oo ...PromoteOption) (Function, error) {
+	o := PromoteOptions{}
+	for _, opt := range oo {
+		opt(&o)
+	}
+	if f.Name == "" {
+		return f, ErrNameRequired
+	}
+	if from == "" || to == "" {
+		return f, ErrNamespaceRequired
+	}
+	if from == to && o.SourceContext == nil {
+		return f, fmt.Errorf("cannot promote %v from namespace %q to itself", f.Name, from)
+	}
+	sourceCtx := ctx
+	if o.SourceContext != nil {
+		sourceCtx = o.SourceContext
+	}
+	source, err := c.describer.Describe(sourceCtx, f.Name, from)
+	if err != nil {
+		return f, fmt.Errorf("cannot find %v in namespace %q. %w", f.Name, from, err)
+	}
+	if !strings.Contains(source.Image, "@") {
+		return f, fmt.Errorf("%w: %v in namespace %q is deployed as %q", ErrImageNotResolved, f.Name, from, source.Image)
+	}
+
+	// Deployed as if for the first time to the target, such that the instance
+	// in the source namespace is not moved.
+	f.Namespace = to
+	f.Deploy.Namespace = ""
+	f.Build.Image = source.Image
+	f.Deploy.Image = source.Image
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "Promoting %v from %q to %q\n", source.Image, from, to)
+	}
+	return c.Deploy(ctx, f, WithDeploySkipBuildCheck(true))
+}
+
 // RunPipeline runs a Pipeline to build and deploy the function.
 // Returned function contains applicable registry and deployed image name.
 // String is the default route.
 func (c *Client) RunPipeline(ctx context.Context, f Function) (string, Function, error) {
+	// The image is built by the pipeline, and deployed upon being pushed, so
+	// there is no point at which its signature could be verified.
+	if f.Deploy.Verify != nil {
+		return "", f, errors.New("verifying the image signature (deploy.verify) is not supported when triggering remote deployments (--remote)")
+	}
+	// Nor are the stages about which hooks are run those of the client.
+	if f.Hooks.defined() {
+		return "", f, errors.New("hooks (hooks) are not supported when triggering remote deployments (--remote)")
+	}
+	// Nor is an encrypted func.yaml, which the pipeline would otherwise
+	// deploy with its values' ciphertext.
+	if f.Encrypted() {
+		return "", f, errors.New("SOPS encrypted functions are not supported when triggering remote deployments (--remote)")
+	}
+
 	// Default function registry to the client's global registry
 	if f.Registry == "" {
 		f.Registry = c.registry
 	}
 
+	// Deployment policies are enforced before the pipeline is run, against
+	// the resources it would deploy, encrypted values being redacted as
+	// they are not decrypted locally.
+	if err := c.checkPolicy(ctx, f.Redact()); err != nil {
+		return "", f, err
+	}
+
 	// Build and deploy function using Pipeline
 	return c.pipelinesProvider.Run(ctx, f)
 }
@@ -967,7 +1719,11 @@ func (c *Client) Run(ctx context.Context, f Function, options ...RunOption) (job
 
 	// Run the function, which returns a Job for use interacting (at arms length)
 	// with that running task (which is likely inside a container process).
-	if job, err = c.runner.Run(ctx, f, oo.Address, timeout); err != nil {
+	decrypted, err := f.Decrypt()
+	if err != nil {
+		return
+	}
+	if job, err = c.runner.Run(ctx, decrypted, oo.Address, timeout); err != nil {
 		return
 	}
 
@@ -1005,14 +1761,77 @@ func (c *Client) Describe(ctx context.Context, name, namespace string, f Functio
 	return c.describer.Describe(ctx, f.Name, f.Deploy.Namespace)
 }
 
+// Metrics of the named function's invocations over the window of time until
+// now, such as the last hour.  Returns ErrMetricsNotAvailable if the client
+// has no source of metrics.
+func (c *Client) Metrics(ctx context.Context, name, namespace string, window time.Duration) (Metrics, error) {
+	return c.metricsProvider.Metrics(ctx, name, namespace, window)
+}
+
 // List currently deployed functions.
 // If namespace is empty, the static implementation of the current
 // "Lister" is used, which for example with the knative lister defaults to
 // using the current kubernetes context namespace, falling back to the static
 // default "namespace".
-func (c *Client) List(ctx context.Context, namespace string) ([]ListItem, error) {
+func (c *Client) List(ctx context.Context, namespace string, oo ...ListOption) ([]ListItem, error) {
+	options := newListOptions(oo)
 	// delegate to concrete implementation of lister entirely.
-	return c.lister.List(ctx, namespace)
+	return c.lister.List(ctx, namespace, options.selector)
+}
+
+// ListPage of the functions deployed, of at most the limit given by
+// WithListLimit and continuing from that given by WithListContinue.  Returned
+// is the token from which to continue listing, empty if all were listed.
+func (c *Client) ListPage(ctx context.Context, namespace string, oo ...ListOption) ([]ListItem, string, error) {
+	options := newListOptions(oo)
+	return c.lister.ListPage(ctx, namespace, options.selector, options.limit, options.token)
+}
+
+// Watch the functions deployed in the given namespace, or in all namespaces
+// if empty, receiving their list initially and on each change until the
+// context is done.
+func (c *Client) Watch(ctx context.Context, namespace string, oo ...ListOption) (<-chan []ListItem, error) {
+	options := newListOptions(oo)
+	return c.lister.Watch(ctx, namespace, options.selector)
+}
+
+type ListOptions struct {
+	selector string
+	limit    int64
+	token    string
+}
+type ListOption func(o *ListOptions)
+
+// WithListSelector lists only the functions whose labels match the
+// Kubernetes label selector, which is evaluated by the cluster.
+func WithListSelector(selector string) ListOption {
+	return func(o *ListOptions) {
+		o.selector = selector
+	}
+}
+
+// WithListLimit lists at most limit functions per page.  Not positive is no
+// limit.
+func WithListLimit(limit int64) ListOption {
+	return func(o *ListOptions) {
+		o.limit = limit
+	}
+}
+
+// WithListContinue lists the page of functions following that from which the
+// token was returned.
+func WithListContinue(token string) ListOption {
+	return func(o *ListOptions) {
+		o.token = token
+	}
+}
+
+func newListOptions(oo []ListOption) *ListOptions {
+	options := &ListOptions{}
+	for _, o := range oo {
+		o(options)
+	}
+	return options
 }
 
 // Remove a function. Name takes precedence. If no name is provided, the
@@ -1099,6 +1918,21 @@ func (c *Client) Invoke(ctx context.Context, root string, target string, m Invok
 	return invoke(ctx, c, f, target, m, c.verbose)
 }
 
+// InvokeStream invokes the function as does Invoke, but for functions which
+// respond with a stream.  The protocol is either StreamSSE, in which case
+// the function is expected to respond with Server-Sent Events, or
+// StreamWebSocket, in which case the connection is upgraded and the
+// message's data sent over it.  onMessage is called with each message
+// received until the stream ends or the context is canceled; an error it
+// returns ends the stream.
+func (c *Client) InvokeStream(ctx context.Context, root string, target string, m InvokeMessage, protocol string, onMessage func(StreamMessage) error) error {
+	f, err := NewFunction(root)
+	if err != nil {
+		return err
+	}
+	return invokeStream(ctx, c, f, target, m, protocol, onMessage, c.verbose)
+}
+
 // Push the image for the named service to the configured registry
 // returns in this order: 1)Function structure 2)bool indicating if push succeeded
 // 3) error
@@ -1108,6 +1942,8 @@ func (c *Client) Push(ctx context.Context, f Function) (Function, bool, error) {
 	}
 	var err error
 
+	start := time.Now()
+	ReportDeployEvent(ctx, DeployEvent{Stage: StagePhase, Phase: PhasePush, Image: f.Build.Image})
 	imageDigest, err := c.pusher.Push(ctx, f)
 	if err != nil {
 		return f, false, err
@@ -1119,10 +1955,201 @@ func (c *Client) Push(ctx context.Context, f Function) (Function, bool, error) {
 	// its populated here. This will eventually be moved to build stage where we get
 	// the full image name and its digest right after building
 	f.Build.Image = f.ImageNameWithDigest(imageDigest)
+	ReportDeployEvent(ctx, DeployEvent{Stage: StageImagePushed, Image: f.Build.Image, Digest: imageDigest, Elapsed: time.Since(start)})
+
+	// The SBOM of the build is attached along with the function's artifacts.
+	af := f
+	if a, ok := f.sbomArtifact(); ok {
+		af.Build.Artifacts = append(append([]ArtifactSpec{}, f.Build.Artifacts...), a)
+	}
+	if len(af.Build.Artifacts) > 0 {
+		if _, err = c.attacher.Attach(ctx, af); err != nil {
+			return f, true, fmt.Errorf("cannot attach artifacts to %v: %w", f.Build.Image, err)
+		}
+	}
+
+	// A signature recorded is of the image pushed, being that last signed.
+	f.Build.Signature = ""
+	if f.Build.Sign != nil {
+		if c.verbose {
+			fmt.Fprintf(os.Stderr, "🔏 Signing image %v\n", f.Build.Image)
+		}
+		if f.Build.Signature, err = c.signer.Sign(ctx, f); err != nil {
+			return f, true, fmt.Errorf("cannot sign %v: %w", f.Build.Image, err)
+		}
+	}
 
 	return f, true, err
 }
 
+// writeSBOM of the function to .func/sbom.json, to be attached to its image
+// when pushed.
+func (c *Client) writeSBOM(ctx context.Context, f Function) (err error) {
+	if err = ensureRunDataDir(f.Root); err != nil {
+		return
+	}
+	file, err := os.Create(f.SBOMPath())
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if err = c.sbomGenerator.Generate(ctx, f, file); err != nil {
+		return fmt.Errorf("cannot generate the SBOM of the function: %w", err)
+	}
+	return
+}
+
+// Artifacts attached to the function's image: that deployed, or else that
+// last built.
+func (c *Client) Artifacts(ctx context.Context, f Function) ([]Artifact, error) {
+	image := f.Deploy.Image
+	if image == "" {
+		image = f.Build.Image
+	}
+	if image == "" {
+		return nil, ErrNotBuilt
+	}
+	return c.attacher.Artifacts(ctx, image)
+}
+
+// Scan the function's built image, written as an OCI archive to path, for
+// known vulnerabilities as configured by f.Build.Scan.  The report is
+// returned whether or not the scan passes; it fails with ErrVulnerable if any
+// vulnerability is at or above the severity of the scan.
+func (c *Client) Scan(ctx context.Context, f Function, path string) (r ScanReport, err error) {
+	if f.Build.Scan == nil {
+		return
+	}
+	if r, err = c.scanner.Scan(ctx, f, path); err != nil {
+		return r, fmt.Errorf("cannot scan the image: %w", err)
+	}
+	r.Image, r.Scanner, r.Threshold = f.Build.Image, f.Build.Scan.ScannerName(), f.Build.Scan.Threshold()
+	if failing := r.Failing(); len(failing) > 0 {
+		return r, fmt.Errorf("%w: %d of severity %v or above", ErrVulnerable, len(failing), r.Threshold)
+	}
+	return
+}
+
+// ImageUpgrades of the images upon which the function is built, as defined
+// by its func.yaml, which have newer patch versions in their registries.
+// Images referenced by digest, or by a tag which is not of a patch version,
+// are not upgraded.  Ordered by field.
+func (c *Client) ImageUpgrades(ctx context.Context, f Function) (uu []ImageUpgrade, err error) {
+	if !f.Initialized() {
+		return nil, NewErrNotInitialized(f.Root)
+	}
+	for field, image := range f.baseImages() {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %v %q: %w", field, image, err)
+		}
+		tag, ok := ref.(name.Tag)
+		if !ok || !strings.HasSuffix(image, ":"+tag.TagStr()) {
+			continue // by digest, or implicitly latest
+		}
+		tags, err := c.tagLister.Tags(ctx, tag.Context().Name())
+		if err != nil {
+			return nil, fmt.Errorf("cannot list the tags of %v: %w", image, err)
+		}
+		if latest := LatestPatch(tag.TagStr(), tags); latest != "" {
+			uu = append(uu, ImageUpgrade{
+				Field:  field,
+				Image:  image,
+				Latest: strings.TrimSuffix(image, tag.TagStr()) + latest,
+			})
+		}
+	}
+	sort.Slice(uu, func(i, j int) bool { return uu[i].Field < uu[j].Field })
+	return uu, nil
+}
+
+// Rebase the function's built image upon its run image, without building
+// it again, if its builder is able to.  The image is then pushed as is a
+// built image.
+func (c *Client) Rebase(ctx context.Context, f Function) (Function, error) {
+	if !f.Initialized() {
+		return f, NewErrNotInitialized(f.Root)
+	}
+	r, ok := c.builder.(Rebaser)
+	if !ok {
+		return f, ErrRebaseNotSupported
+	}
+	if f.Build.Image == "" {
+		return f, ErrNotBuilt
+	}
+	if err := r.Rebase(ctx, f); err != nil {
+		return f, fmt.Errorf("cannot rebase the function: %w", err)
+	}
+	return f, nil
+}
+
+// Caches retained by the builders between builds of the function, ordered
+// by builder and name.
+func (c *Client) Caches(ctx context.Context, f Function) (cc []BuildCache, err error) {
+	if !f.Initialized() {
+		return nil, NewErrNotInitialized(f.Root)
+	}
+	for _, b := range c.buildCachers {
+		found, err := b.Caches(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		cc = append(cc, found...)
+	}
+	sort.SliceStable(cc, func(i, j int) bool {
+		if cc[i].Builder != cc[j].Builder {
+			return cc[i].Builder < cc[j].Builder
+		}
+		return cc[i].Name < cc[j].Name
+	})
+	return cc, nil
+}
+
+// PruneCaches of the function last modified longer ago than olderThan (all
+// if zero), including those shared by all functions if shared.  Returns the
+// caches removed; an error removing one stops the removal of the remainder.
+func (c *Client) PruneCaches(ctx context.Context, f Function, olderThan time.Duration, shared bool) (removed []BuildCache, err error) {
+	if !f.Initialized() {
+		return nil, NewErrNotInitialized(f.Root)
+	}
+	cutoff := time.Now().Add(-olderThan)
+	for _, b := range c.buildCachers {
+		cc, err := b.Caches(ctx, f)
+		if err != nil {
+			return removed, err
+		}
+		for _, cache := range cc {
+			if (cache.Shared && !shared) || (olderThan > 0 && cache.Modified.After(cutoff)) {
+				continue
+			}
+			if err = b.Remove(ctx, cache); err != nil {
+				return removed, fmt.Errorf("cannot remove %v %v: %w", cache.Kind, cache.Name, err)
+			}
+			removed = append(removed, cache)
+		}
+	}
+	return removed, nil
+}
+
+// FetchArtifact attached to the function's image, identified by the path
+// from which it was attached or by its digest, writing its content to w.
+func (c *Client) FetchArtifact(ctx context.Context, f Function, pathOrDigest string, w io.Writer) error {
+	aa, err := c.Artifacts(ctx, f)
+	if err != nil {
+		return err
+	}
+	image := f.Deploy.Image
+	if image == "" {
+		image = f.Build.Image
+	}
+	for _, a := range aa {
+		if a.Digest == pathOrDigest || (a.Path != "" && filepath.Clean(a.Path) == filepath.Clean(pathOrDigest)) {
+			return c.attacher.Fetch(ctx, image, a, w)
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrArtifactNotFound, pathOrDigest)
+}
+
 // StartMCPServer is currently a passthrough to the configured MCP Server
 // intance.
 func (c *Client) StartMCPServer(ctx context.Context, writeEnabled bool) error {
@@ -1190,7 +2217,7 @@ func ensureRunDataDir(root string) error {
 }
 
 func ensureFuncIgnore(root string) error {
-	filePath := filepath.Join(root, ".funcignore")
+	filePath := filepath.Join(root, IgnoreFile)
 
 	// Check if the file exists
 	_, err := os.Stat(filePath)
@@ -1214,9 +2241,10 @@ func ensureFuncIgnore(root string) error {
 	// Write the desired string to the file
 	_, err = file.WriteString(`
 # Use the .funcignore file to exclude files which should not be
-# tracked in the image build. To instruct the system not to track
-# files in the image build, add the regex pattern or file information
-# to this file.
+# tracked in the image build. Patterns use .gitignore syntax, and
+# apply in addition to those of .gitignore. Prefix a pattern with !
+# to include files which would otherwise be excluded. Run
+# "func build --show-context" to list the files which are included.
 `)
 	if err != nil {
 		return err
@@ -1361,6 +2389,92 @@ type noopPusher struct{ output io.Writer }
 
 func (n *noopPusher) Push(ctx context.Context, f Function) (string, error) { return "", nil }
 
+// Verifier
+// Unlike other defaults, the noop verifier fails: a function which declares a
+// signature policy must never be deployed without it being enforced.
+type noopVerifier struct{}
+
+func (n *noopVerifier) Verify(context.Context, Function) (string, error) {
+	return "", ErrVerifierRequired
+}
+
+// PolicyEvaluator
+type noopPolicyEvaluator struct{}
+
+func (n *noopPolicyEvaluator) Evaluate(context.Context, ...map[string]any) error { return nil }
+
+// Signer
+// As does the noop verifier, the noop signer fails: an image to be signed is
+// never left unsigned.
+type noopSigner struct{}
+
+func (n *noopSigner) Sign(context.Context, Function) (string, error) {
+	return "", ErrSignerRequired
+}
+
+// Scanner
+// As does the noop verifier, the noop scanner fails: an image to be scanned
+// is never passed unscanned.
+type noopScanner struct{}
+
+func (n *noopScanner) Scan(context.Context, Function, string) (ScanReport, error) {
+	return ScanReport{}, ErrScannerRequired
+}
+
+// DigestResolver
+// As does the noop verifier, the noop digest resolver fails: an image to be
+// pinned is never deployed unpinned.
+type noopDigestResolver struct{}
+
+func (n *noopDigestResolver) Digest(context.Context, string) (string, error) {
+	return "", ErrDigestResolverRequired
+}
+
+// TagLister
+type noopTagLister struct{}
+
+func (n *noopTagLister) Tags(context.Context, string) ([]string, error) {
+	return nil, ErrTagListerRequired
+}
+
+// Differ
+// As does the noop verifier, the noop differ fails: a deployment is never
+// reported unchanged for want of a description of it.
+type noopDiffer struct{}
+
+func (n *noopDiffer) Snapshots(context.Context, Function) (Snapshot, Snapshot, error) {
+	return Snapshot{}, Snapshot{}, ErrDifferRequired
+}
+
+// SBOMGenerator
+// As does the noop verifier, the noop SBOM generator fails: a function which
+// declares an SBOM format is never built without one.
+type noopSBOMGenerator struct{}
+
+func (n *noopSBOMGenerator) Generate(context.Context, Function, io.Writer) error {
+	return ErrSBOMGeneratorRequired
+}
+
+// HookRunner
+// As does the noop verifier, the noop hook runner fails: a function's hooks
+// are never silently skipped.
+type noopHookRunner struct{}
+
+func (n *noopHookRunner) Run(context.Context, Function, Hook, []string) error {
+	return ErrHookRunnerRequired
+}
+
+// Attacher
+type noopAttacher struct{}
+
+func (n *noopAttacher) Attach(context.Context, Function) ([]Artifact, error) { return nil, nil }
+
+func (n *noopAttacher) Artifacts(context.Context, string) ([]Artifact, error) { return nil, nil }
+
+func (n *noopAttacher) Fetch(context.Context, string, Artifact, io.Writer) error {
+	return ErrArtifactNotFound
+}
+
 // Deployer
 type noopDeployer struct{ output io.Writer }
 
@@ -1368,6 +2482,8 @@ func (n *noopDeployer) Deploy(ctx context.Context, f Function) (DeploymentResult
 	return DeploymentResult{Namespace: f.Namespace}, nil
 }
 
+func (n *noopDeployer) Render(context.Context, Function, DryRun) ([]byte, error) { return nil, nil }
+
 // Remover
 type noopRemover struct{ output io.Writer }
 
@@ -1376,7 +2492,21 @@ func (n *noopRemover) Remove(context.Context, string, string) error { return nil
 // Lister
 type noopLister struct{ output io.Writer }
 
-func (n *noopLister) List(context.Context, string) ([]ListItem, error) { return []ListItem{}, nil }
+func (n *noopLister) List(context.Context, string, string) ([]ListItem, error) {
+	return []ListItem{}, nil
+}
+func (n *noopLister) ListPage(context.Context, string, string, int64, string) ([]ListItem, string, error) {
+	return []ListItem{}, "", nil
+}
+func (n *noopLister) Watch(ctx context.Context, _, _ string) (<-chan []ListItem, error) {
+	ch := make(chan []ListItem, 1)
+	ch <- []ListItem{}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
 
 // Describer
 type noopDescriber struct{ output io.Writer }
@@ -1385,6 +2515,13 @@ func (n *noopDescriber) Describe(context.Context, string, string) (Instance, err
 	return Instance{}, nil
 }
 
+// MetricsProvider
+type noopMetricsProvider struct{}
+
+func (n *noopMetricsProvider) Metrics(context.Context, string, string, time.Duration) (Metrics, error) {
+	return Metrics{}, ErrMetricsNotAvailable
+}
+
 // PipelinesProvider
 type noopPipelinesProvider struct{}
 
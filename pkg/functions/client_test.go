@@ -8,17 +8,21 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/gorilla/websocket"
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/mock"
@@ -1516,6 +1520,749 @@ func TestClient_Deploy_UnbuiltErrors(t *testing.T) {
 	}
 }
 
+// TestClient_Deploy_Verify ensures that a function with a signature policy
+// is verified prior to deployment, is not deployed when verification fails,
+// and is never deployed unverified when no verifier is configured.
+func TestClient_Deploy_Verify(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	verifier := mock.NewVerifier()
+	deployer := mock.NewDeployer()
+	remover := mock.NewRemover()
+	pipelines := mock.NewPipelinesProvider()
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithVerifier(verifier),
+		fn.WithDeployer(deployer),
+		fn.WithRemover(remover),
+		fn.WithPipelinesProvider(pipelines))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Deploy.Verify = &fn.VerifySpec{Key: "cosign.pub"}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	// Verification failure prevents deployment
+	verifier.VerifyFn = func(context.Context, fn.Function) (string, error) { return "", errors.New("unsigned") }
+	if _, err = client.Deploy(context.Background(), f); err == nil {
+		t.Fatal("expected deploy to fail verification")
+	}
+	if !verifier.VerifyInvoked || deployer.DeployInvoked {
+		t.Fatalf("expected verify but not deploy. verified=%v deployed=%v", verifier.VerifyInvoked, deployer.DeployInvoked)
+	}
+
+	// Nor is a function moving namespace removed from its previous
+	f.Deploy.Namespace = "previous"
+	if _, err = client.Deploy(context.Background(), f); err == nil {
+		t.Fatal("expected deploy to fail verification")
+	}
+	if remover.RemoveInvoked {
+		t.Fatal("expected the function not removed from its previous namespace")
+	}
+	f.Deploy.Namespace = ""
+
+	// Remote deployments, which could not verify, are refused
+	if _, _, err = client.RunPipeline(context.Background(), f); err == nil || pipelines.RunInvoked {
+		t.Fatal("expected a remote deployment to be refused")
+	}
+
+	// Successful verification deploys the image verified, by its digest
+	verifier.VerifyFn = func(context.Context, fn.Function) (string, error) { return mock.VerifiedDigest, nil }
+	var deployed string
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		deployed = f.Deploy.Image
+		return fn.DeploymentResult{Namespace: f.Namespace}, nil
+	}
+	if _, err = client.Deploy(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !deployer.DeployInvoked {
+		t.Fatal("expected deploy after successful verification")
+	}
+	repo, _, _ := strings.Cut(f.Build.Image, ":latest")
+	if expected := repo + "@" + mock.VerifiedDigest; deployed != expected {
+		t.Fatalf("expected the verified image %v deployed, got %v", expected, deployed)
+	}
+
+	// An image already pinned to a digest other than that verified is not
+	// deployed.
+	f.Deploy.Image = repo + "@sha256:" + strings.Repeat("f", 64)
+	if _, err = client.Deploy(context.Background(), f); !errors.Is(err, fn.ErrDigestMismatch) {
+		t.Fatalf("expected ErrDigestMismatch, got %v", err)
+	}
+	f.Deploy.Image = ""
+
+	// Without a verifier, the policy can not be satisfied
+	client = fn.New(fn.WithRegistry(TestRegistry), fn.WithDeployer(mock.NewDeployer()))
+	if _, err = client.Deploy(context.Background(), f); !errors.Is(err, fn.ErrVerifierRequired) {
+		t.Fatalf("expected ErrVerifierRequired, got %v", err)
+	}
+}
+
+// TestClient_Render_Redacted ensures that a function's encrypted values are
+// rendered redacted, neither decrypted nor as encrypted.
+func TestClient_Render_Redacted(t *testing.T) {
+	const encrypted = "ENC[AES256_GCM,data:c2VjcmV0,iv:aXY=,tag:dGFn,type:str]"
+
+	var rendered fn.Function
+	deployer := mock.NewDeployer()
+	deployer.RenderFn = func(_ context.Context, f fn.Function, _ fn.DryRun) ([]byte, error) {
+		rendered = f
+		return nil, nil
+	}
+	client := fn.New(fn.WithDeployer(deployer))
+
+	secret, value, plain := "SECRET", encrypted, "PLAIN"
+	f := fn.Function{Name: "f", Sops: &fn.SopsMetadata{}} // no key is available
+	f.Run.Envs = []fn.Env{{Name: &secret, Value: &value}, {Name: &plain, Value: &plain}}
+	if _, err := client.Render(context.Background(), f, fn.DryRunClient); err != nil {
+		t.Fatal(err)
+	}
+	if v := *rendered.Run.Envs[0].Value; v != fn.RedactedValue {
+		t.Fatalf("expected encrypted env rendered %q, got %q", fn.RedactedValue, v)
+	}
+	if v := *rendered.Run.Envs[1].Value; v != plain {
+		t.Fatalf("expected env rendered as given, got %q", v)
+	}
+	if *f.Run.Envs[0].Value != encrypted {
+		t.Fatal("expected the function given not to be modified")
+	}
+}
+
+// TestClient_Deploy_Policy ensures that deployment policies are evaluated
+// against the manifests rendered by the deployer, a violation preventing the
+// function's deployment by the deployer or a pipeline, and its render.
+func TestClient_Deploy_Policy(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	deployer := mock.NewDeployer()
+	deployer.RenderFn = func(context.Context, fn.Function, fn.DryRun) ([]byte, error) {
+		return []byte("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: f\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: f\n"), nil
+	}
+	var kinds []any
+	policy := mock.NewPolicyEvaluator()
+	policy.EvaluateFn = func(_ context.Context, mm ...map[string]any) error {
+		kinds = kinds[:0]
+		for _, m := range mm {
+			kinds = append(kinds, m["kind"])
+		}
+		return errors.New("denied")
+	}
+	pipelines := mock.NewPipelinesProvider()
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithDeployer(deployer),
+		fn.WithPolicy(policy),
+		fn.WithPipelinesProvider(pipelines))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Deploy(context.Background(), f); err == nil || deployer.DeployInvoked {
+		t.Fatalf("expected the policy to prevent deployment, got %v", err)
+	}
+	if !reflect.DeepEqual(kinds, []any{"Deployment", "Service"}) {
+		t.Fatalf("expected each manifest rendered evaluated, got %v", kinds)
+	}
+	if _, _, err = client.RunPipeline(context.Background(), f); err == nil || pipelines.RunInvoked {
+		t.Fatalf("expected the policy to prevent the pipeline run, got %v", err)
+	}
+	if _, err = client.Render(context.Background(), f, fn.DryRunServer); err == nil {
+		t.Fatal("expected the policy to fail the render")
+	}
+}
+
+// TestClient_RunPipeline_Encrypted ensures that a remote deployment of a
+// SOPS encrypted function, which the pipeline would deploy with its values'
+// ciphertext, is refused.
+func TestClient_RunPipeline_Encrypted(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	pipelines := mock.NewPipelinesProvider()
+	client := fn.New(fn.WithRegistry(TestRegistry), fn.WithPipelinesProvider(pipelines))
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Sops = &fn.SopsMetadata{}
+	if _, _, err = client.RunPipeline(context.Background(), f); err == nil || pipelines.RunInvoked {
+		t.Fatalf("expected a remote deployment of an encrypted function to be refused, got %v", err)
+	}
+}
+
+// TestClient_Deploy_PinDigest ensures that a function deployed with its
+// digest pinned is deployed by the digest of its tag in the registry, and not
+// at all should that not be the digest pushed.
+func TestClient_Deploy_PinDigest(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	const pushed = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	resolver := mock.NewDigestResolver()
+	deployer := mock.NewDeployer()
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithDigestResolver(resolver),
+		fn.WithDeployer(deployer))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	tagged, err := f.ImageName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.Image = f.ImageNameWithDigest(pushed)
+
+	// The tag pushed has since been overwritten
+	resolver.DigestFn = func(_ context.Context, image string) (string, error) {
+		if image != tagged {
+			t.Errorf("expected the digest of %v resolved, got %v", tagged, image)
+		}
+		return "sha256:2222222222222222222222222222222222222222222222222222222222222222", nil
+	}
+	if _, err = client.Deploy(context.Background(), f, fn.WithDeployPinDigest(true)); !errors.Is(err, fn.ErrDigestMismatch) {
+		t.Fatalf("expected ErrDigestMismatch, got %v", err)
+	}
+	if deployer.DeployInvoked {
+		t.Fatal("expected no deployment of a mismatched digest")
+	}
+
+	// An image deployed by tag is pinned to its digest
+	f.Build.Image = tagged
+	f.Deploy.Image = tagged
+	resolver.DigestFn = func(context.Context, string) (string, error) { return pushed, nil }
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if expected := TestRegistry + "/" + f.Name + "@" + pushed; f.Deploy.Image != expected {
+			t.Errorf("expected the image %v deployed, got %v", expected, f.Deploy.Image)
+		}
+		return fn.DeploymentResult{Namespace: TestNamespace}, nil
+	}
+	if _, err = client.Deploy(context.Background(), f, fn.WithDeployPinDigest(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a resolver, the digest can not be pinned
+	client = fn.New(fn.WithRegistry(TestRegistry), fn.WithDeployer(mock.NewDeployer()))
+	if _, err = client.Deploy(context.Background(), f, fn.WithDeployPinDigest(true)); !errors.Is(err, fn.ErrDigestResolverRequired) {
+		t.Fatalf("expected ErrDigestResolverRequired, got %v", err)
+	}
+}
+
+// TestClient_DeployEvents ensures that the build, push and deployment of a
+// function are reported to the events of the context: the build as started,
+// the image pushed with its digest, and the function deployed with its URL.
+func TestClient_DeployEvents(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	const digest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) { return digest, nil }
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(context.Context, fn.Function) (fn.DeploymentResult, error) {
+		return fn.DeploymentResult{Status: fn.Deployed, URL: "http://f.example.com", Namespace: TestNamespace}, nil
+	}
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithPusher(pusher),
+		fn.WithDeployer(deployer))
+
+	var events []fn.DeployEvent
+	ctx := context.WithValue(context.Background(), fn.DeployEventsKey{}, func(e fn.DeployEvent) {
+		events = append(events, e)
+	})
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if f, _, err = client.Push(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Deploy(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 5 {
+		t.Fatalf("expected 5 events, got %v", events)
+	}
+	if e := events[0]; e.Stage != fn.StageBuildStarted || e.Image == "" {
+		t.Errorf("expected the build started of an image, got %+v", e)
+	}
+	if e := events[1]; e.Stage != fn.StageBuilt || e.Image == "" {
+		t.Errorf("expected the image built, got %+v", e)
+	}
+	if e := events[2]; e.Stage != fn.StagePhase || e.Phase != fn.PhasePush {
+		t.Errorf("expected the push phase started, got %+v", e)
+	}
+	if e := events[3]; e.Stage != fn.StageImagePushed || e.Digest != digest || !strings.HasSuffix(e.Image, "@"+digest) {
+		t.Errorf("expected the image pushed with its digest, got %+v", e)
+	}
+	if e := events[4]; e.Stage != fn.StageDeployed || e.URL != "http://f.example.com" {
+		t.Errorf("expected the function deployed at its URL, got %+v", e)
+	}
+}
+
+// TestClient_Hooks ensures that the hooks of a function are run about its
+// build and deploy, in order, commands locally and images by the hook runner,
+// each provided the function's environment, and that a failed hook fails its
+// stage.
+func TestClient_Hooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands of this test are of a POSIX shell")
+	}
+	root, rm := Mktemp(t)
+	defer rm()
+
+	var images []string
+	hooks := mock.NewHookRunner()
+	hooks.RunFn = func(_ context.Context, _ fn.Function, h fn.Hook, env []string) error {
+		images = append(images, h.Image)
+		if !slices.Contains(env, "FUNC_HOOK=preDeploy") {
+			t.Errorf("expected the stage in the environment, got %v", env)
+		}
+		return nil
+	}
+	deployer := mock.NewDeployer()
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithHookRunner(hooks),
+		fn.WithDeployer(deployer))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Hooks = fn.HooksSpec{
+		PreBuild:   []fn.Hook{{Command: "echo preBuild $FUNC_NAME >> hooks.log"}},
+		PostBuild:  []fn.Hook{{Command: "echo postBuild >> hooks.log"}},
+		PreDeploy:  []fn.Hook{{Name: "migrate", Image: "example.com/migrate"}},
+		PostDeploy: []fn.Hook{{Command: "echo postDeploy $FUNC_NAMESPACE >> hooks.log"}},
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Deploy(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	log, err := os.ReadFile(filepath.Join(root, "hooks.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Sprintf("preBuild %v\npostBuild\npostDeploy %v\n", f.Name, TestNamespace)
+	if string(log) != expected {
+		t.Errorf("expected hooks run\n%q\ngot\n%q", expected, log)
+	}
+	if !reflect.DeepEqual(images, []string{"example.com/migrate"}) {
+		t.Errorf("expected the image hook run, got %v", images)
+	}
+
+	// A failed pre-deploy hook prevents deployment
+	deployer = mock.NewDeployer()
+	client = fn.New(fn.WithRegistry(TestRegistry), fn.WithHookRunner(hooks), fn.WithDeployer(deployer))
+	f.Hooks = fn.HooksSpec{PreDeploy: []fn.Hook{{Command: "exit 1"}}}
+	if _, err = client.Deploy(context.Background(), f); err == nil {
+		t.Fatal("expected the failed hook to fail the deploy")
+	}
+	if deployer.DeployInvoked {
+		t.Fatal("expected no deploy after a failed pre-deploy hook")
+	}
+
+	// Remote deployments, which would not run them, are refused
+	pipelines := mock.NewPipelinesProvider()
+	client = fn.New(fn.WithRegistry(TestRegistry), fn.WithPipelinesProvider(pipelines))
+	if _, _, err = client.RunPipeline(context.Background(), f); err == nil || pipelines.RunInvoked {
+		t.Fatal("expected a remote deployment of a function with hooks to be refused")
+	}
+}
+
+// TestClient_Deploy_PostDeployHookFailed ensures that the namespace to which
+// a function is deployed is recorded even should a postDeploy hook fail.
+func TestClient_Deploy_PostDeployHookFailed(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	hooks := mock.NewHookRunner()
+	hooks.RunFn = func(context.Context, fn.Function, fn.Hook, []string) error {
+		return errors.New("hook failed")
+	}
+	deployer := mock.NewDeployer()
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithHookRunner(hooks),
+		fn.WithDeployer(deployer))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root, Namespace: TestNamespace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Hooks = fn.HooksSpec{PostDeploy: []fn.Hook{{Name: "notify", Image: "example.com/notify"}}}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	f, err = client.Deploy(context.Background(), f)
+	if !errors.Is(err, fn.ErrPostDeployHookFailed) {
+		t.Fatalf("expected the failed postDeploy hook to be reported, got %v", err)
+	}
+	if !deployer.DeployInvoked || !hooks.RunInvoked {
+		t.Fatal("expected the function to be deployed and its hook run")
+	}
+	if f.Deploy.Namespace != TestNamespace {
+		t.Fatalf("expected the namespace deployed to be recorded, got %q", f.Deploy.Namespace)
+	}
+}
+
+// TestClient_Push_Artifacts ensures that the artifacts of a function are
+// attached to its image once pushed, and that an attached artifact may be
+// fetched by the path from which it was attached.
+func TestClient_Push_Artifacts(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) {
+		return "sha256:0000000000000000000000000000000000000000000000000000000000000000", nil
+	}
+	attacher := mock.NewAttacher()
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithPusher(pusher),
+		fn.WithAttacher(attacher))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without artifacts, nothing is attached.
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if attacher.AttachInvoked {
+		t.Fatal("expected no artifacts to be attached")
+	}
+
+	f.Build.Artifacts = []fn.ArtifactSpec{{Path: "README.md"}}
+	attacher.AttachFn = func(_ context.Context, f fn.Function) ([]fn.Artifact, error) {
+		if !strings.Contains(f.Build.Image, "@sha256:") {
+			t.Errorf("expected artifacts to be attached to the pushed digest, got %v", f.Build.Image)
+		}
+		return nil, nil
+	}
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !attacher.AttachInvoked {
+		t.Fatal("expected artifacts to be attached")
+	}
+
+	attacher.ArtifactsFn = func(context.Context, string) ([]fn.Artifact, error) {
+		return []fn.Artifact{{Path: "README.md", Digest: "sha256:1111"}}, nil
+	}
+	attacher.FetchFn = func(_ context.Context, _ string, a fn.Artifact, w io.Writer) error {
+		_, err := fmt.Fprint(w, a.Digest)
+		return err
+	}
+	var b strings.Builder
+	if err = client.FetchArtifact(context.Background(), f, "./README.md", &b); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "sha256:1111" {
+		t.Fatalf("expected the artifact to be fetched, got %q", b.String())
+	}
+	if err = client.FetchArtifact(context.Background(), f, "openapi.json", &b); !errors.Is(err, fn.ErrArtifactNotFound) {
+		t.Fatalf("expected ErrArtifactNotFound, got %v", err)
+	}
+}
+
+// TestClient_Build_SBOM ensures that the SBOM of a function declaring an SBOM
+// format is generated when it is built, and attached to its image along with
+// its artifacts when pushed.
+func TestClient_Build_SBOM(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	// Without a generator, a function which declares an SBOM is not built.
+	f, err := fn.New(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder())).
+		Init(fn.Function{Runtime: TestRuntime, Root: root, Build: fn.BuildSpec{SBOM: fn.SBOMFormatSPDX}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fn.New(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()))
+	if _, err = client.Build(context.Background(), f); !errors.Is(err, fn.ErrSBOMGeneratorRequired) {
+		t.Fatalf("expected ErrSBOMGeneratorRequired, got %v", err)
+	}
+
+	generator := mock.NewSBOMGenerator()
+	generator.GenerateFn = func(_ context.Context, f fn.Function, w io.Writer) error {
+		_, err := fmt.Fprintf(w, `{"format":%q}`, f.Build.SBOM)
+		return err
+	}
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) {
+		return "sha256:0000000000000000000000000000000000000000000000000000000000000000", nil
+	}
+	attacher := mock.NewAttacher()
+	attacher.AttachFn = func(_ context.Context, f fn.Function) ([]fn.Artifact, error) {
+		if len(f.Build.Artifacts) != 1 {
+			t.Fatalf("expected only the SBOM to be attached, got %v", f.Build.Artifacts)
+		}
+		a := f.Build.Artifacts[0]
+		if a.Path != ".func/sbom.json" || a.MediaType != "application/spdx+json" {
+			t.Errorf("unexpected SBOM artifact %+v", a)
+		}
+		return nil, nil
+	}
+	client = fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithSBOMGenerator(generator),
+		fn.WithPusher(pusher),
+		fn.WithAttacher(attacher))
+
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !generator.GenerateInvoked {
+		t.Fatal("expected the SBOM to be generated")
+	}
+	data, err := os.ReadFile(f.SBOMPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"format":"spdx"}` {
+		t.Fatalf("unexpected SBOM %s", data)
+	}
+
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !attacher.AttachInvoked {
+		t.Fatal("expected the SBOM to be attached")
+	}
+	if len(f.Build.Artifacts) != 0 {
+		t.Fatalf("expected the SBOM not to be added to the function's artifacts, got %v", f.Build.Artifacts)
+	}
+}
+
+// TestClient_Push_Sign ensures that a function to be signed is signed once
+// pushed, recording the reference of its signature, and that a function is
+// never pushed unsigned for want of a signer.
+func TestClient_Push_Sign(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	pusher := mock.NewPusher()
+	pusher.PushFn = func(context.Context, fn.Function) (string, error) {
+		return "sha256:0000000000000000000000000000000000000000000000000000000000000000", nil
+	}
+	signer := mock.NewSigner()
+	signer.SignFn = func(_ context.Context, f fn.Function) (string, error) {
+		if !strings.Contains(f.Build.Image, "@sha256:") {
+			t.Errorf("expected the pushed digest to be signed, got %v", f.Build.Image)
+		}
+		return "example.com/alice/f:sha256-0000.sig", nil
+	}
+	client := fn.New(
+		fn.WithRegistry(TestRegistry),
+		fn.WithBuilder(mock.NewBuilder()),
+		fn.WithPusher(pusher),
+		fn.WithSigner(signer))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Build.Sign = &fn.SignSpec{Key: "cosign.key"}
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !signer.SignInvoked {
+		t.Fatal("expected the image to be signed")
+	}
+	if f.Build.Signature != "example.com/alice/f:sha256-0000.sig" {
+		t.Fatalf("expected the signature recorded, got %q", f.Build.Signature)
+	}
+
+	// A signature is recorded only of the image it signs.
+	f.Build.Sign = nil
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.Signature != "" {
+		t.Fatalf("expected the signature of the previous image cleared, got %q", f.Build.Signature)
+	}
+
+	f.Build.Sign = &fn.SignSpec{}
+	client = fn.New(fn.WithRegistry(TestRegistry), fn.WithBuilder(mock.NewBuilder()), fn.WithPusher(pusher))
+	if _, _, err = client.Push(context.Background(), f); !errors.Is(err, fn.ErrSignerRequired) {
+		t.Fatalf("expected ErrSignerRequired, got %v", err)
+	}
+}
+
+// TestClient_PruneCaches ensures that caches of all builders are listed,
+// and that those pruned are the function's own modified before the cutoff,
+// or shared also if requested.
+// TestClient_ImageUpgrades ensures that only the images of func.yaml tagged
+// with a patch version are upgraded, to the latest patch of their registry,
+// and that a builder which cannot rebase is reported as such.
+func TestClient_ImageUpgrades(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	tags := map[string][]string{
+		"index.docker.io/library/python": {"3.13.1-slim", "3.13.3-slim", "3.13.4", "3.14.0-slim", "3.13-slim"},
+		"registry.example.com/run":       {"1.0.0", "1.0.1"},
+	}
+	lister := mock.NewTagLister()
+	lister.TagsFn = func(_ context.Context, repo string) ([]string, error) {
+		return tags[repo], nil
+	}
+	client := fn.New(fn.WithRegistry(TestRegistry), fn.WithTagLister(lister))
+
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Build.BuilderImages = map[string]string{"s2i": "python:3.13.1-slim", "pack": "registry.example.com/builder"}
+	f.Build.RunImage = "registry.example.com/run:1.0.0"
+	f.Build.BaseImage = "registry.example.com/run@sha256:0bd7d9ff1c8e58ac8ae4c8ab7e6bb2e53ef9ec42bd36da0e69ebbc1e1b2e11b8"
+
+	uu, err := client.ImageUpgrades(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []fn.ImageUpgrade{
+		{Field: "build.builderImages.s2i", Image: "python:3.13.1-slim", Latest: "python:3.13.3-slim"},
+		{Field: "build.runImage", Image: "registry.example.com/run:1.0.0", Latest: "registry.example.com/run:1.0.1"},
+	}
+	if !reflect.DeepEqual(uu, want) {
+		t.Fatalf("expected upgrades %v, got %v", want, uu)
+	}
+
+	if _, err = client.Rebase(context.Background(), f); !errors.Is(err, fn.ErrRebaseNotSupported) {
+		t.Fatalf("expected ErrRebaseNotSupported, got %v", err)
+	}
+}
+
+// TestClient_Scan ensures scanning the image fails only when a
+// vulnerability is at or above the severity of the scan, returning the
+// report in either case.
+func TestClient_Scan(t *testing.T) {
+	scanner := mock.NewScanner()
+	scanner.ScanFn = func(context.Context, fn.Function, string) (fn.ScanReport, error) {
+		return fn.ScanReport{Vulnerabilities: []fn.Vulnerability{{ID: "CVE-1", Severity: "high"}}}, nil
+	}
+	client := fn.New(fn.WithScanner(scanner))
+
+	f := fn.Function{Build: fn.BuildSpec{Image: "example.com/alice/f:latest"}}
+	if _, err := client.Scan(context.Background(), f, "image.tar"); err != nil || scanner.ScanInvoked {
+		t.Fatalf("expected a function without a scan not to be scanned, got %v", err)
+	}
+
+	f.Build.Scan = &fn.ScanSpec{Severity: "critical"}
+	r, err := client.Scan(context.Background(), f, "image.tar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Image != f.Build.Image || r.Scanner != fn.DefaultScanner || r.Threshold != "critical" || len(r.Vulnerabilities) != 1 {
+		t.Fatalf("unexpected report %+v", r)
+	}
+
+	f.Build.Scan = &fn.ScanSpec{}
+	if r, err = client.Scan(context.Background(), f, "image.tar"); !errors.Is(err, fn.ErrVulnerable) {
+		t.Fatalf("expected ErrVulnerable, got %v", err)
+	}
+	if len(r.Failing()) != 1 {
+		t.Fatalf("expected the report of the failed scan, got %+v", r)
+	}
+
+	if _, err = fn.New().Scan(context.Background(), f, "image.tar"); !errors.Is(err, fn.ErrScannerRequired) {
+		t.Fatalf("expected ErrScannerRequired, got %v", err)
+	}
+}
+
+func TestClient_PruneCaches(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	old := time.Now().Add(-45 * 24 * time.Hour)
+	pack := mock.NewBuildCacher(
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheVolume, Name: "pack-cache-b", Size: 10, Modified: old},
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheVolume, Name: "pack-cache-a", Size: 20, Modified: time.Now()},
+		fn.BuildCache{Builder: "pack", Kind: fn.CacheImage, Name: "builder", Size: 30, Modified: old, Shared: true})
+	host := mock.NewBuildCacher(
+		fn.BuildCache{Builder: "host", Kind: fn.CacheDirectory, Name: "blob-cache", Size: 40, Modified: old})
+	client := fn.New(fn.WithRegistry(TestRegistry), fn.WithBuildCachers(pack, host))
+
+	if _, err := client.Caches(context.Background(), fn.Function{Root: root}); err == nil {
+		t.Fatal("expected an error listing the caches of an uninitialized function")
+	}
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc, err := client.Caches(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range cc {
+		names = append(names, c.Name)
+	}
+	if want := "blob-cache builder pack-cache-a pack-cache-b"; strings.Join(names, " ") != want {
+		t.Fatalf("expected caches ordered by builder and name %q, got %q", want, strings.Join(names, " "))
+	}
+
+	removed, err := client.PruneCaches(context.Background(), f, 30*24*time.Hour, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || len(pack.Retained) != 2 || len(host.Retained) != 0 {
+		t.Fatalf("expected the function's caches older than 30 days removed, removed %v", removed)
+	}
+	if removed, err = client.PruneCaches(context.Background(), f, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || len(pack.Retained) != 0 {
+		t.Fatalf("expected the remaining caches removed, removed %v", removed)
+	}
+}
+
 // TestClient_New_BuilderImagesPersisted Asserts that the client preserves user-
 // provided Builder Images
 func TestClient_New_BuildersPersisted(t *testing.T) {
@@ -1906,6 +2653,116 @@ func TestClient_Invoke_CloudEvent(t *testing.T) {
 	}
 }
 
+// TestClient_InvokeStream_SSE ensures that a function responding with
+// Server-Sent Events has each event delivered as it is received, including
+// events whose data spans multiple lines.
+func TestClient_InvokeStream_SSE(t *testing.T) {
+	root := "testdata/example.com/test-invoke-stream-sse"
+	defer Using(t, root)()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("expected an event stream to be accepted, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": a comment\n\ndata: Hello\n\n")
+		w.(http.Flusher).Flush()
+		fmt.Fprint(w, "event: token\r\nid: 2\r\ndata: World\r\ndata: !\r\n\r\n")
+	}))
+	t.Cleanup(s.Close)
+
+	client := fn.New(fn.WithRegistry(TestRegistry))
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received []fn.StreamMessage
+	err = client.InvokeStream(context.Background(), f.Root, s.URL, fn.NewInvokeMessage(), fn.StreamSSE, func(m fn.StreamMessage) error {
+		received = append(received, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []fn.StreamMessage{{Data: "Hello"}, {Event: "token", ID: "2", Data: "World\n!"}}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("expected events %v, got %v", expected, received)
+	}
+}
+
+// TestClient_InvokeStream_SSERequired ensures that a function which does
+// not respond with an event stream when one is requested is an error.
+func TestClient_InvokeStream_SSERequired(t *testing.T) {
+	root := "testdata/example.com/test-invoke-stream-sse-required"
+	defer Using(t, root)()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	t.Cleanup(s.Close)
+
+	client := fn.New(fn.WithRegistry(TestRegistry))
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.InvokeStream(context.Background(), f.Root, s.URL, fn.NewInvokeMessage(), fn.StreamSSE, func(fn.StreamMessage) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "event stream") {
+		t.Fatalf("expected an error because of the missing event stream, got %v", err)
+	}
+}
+
+// TestClient_InvokeStream_WebSocket ensures that the message data is sent
+// over an upgraded connection and each message received is delivered until
+// the function closes the connection.
+func TestClient_InvokeStream_WebSocket(t *testing.T) {
+	root := "testdata/example.com/test-invoke-stream-websocket"
+	defer Using(t, root)()
+
+	message := fn.NewInvokeMessage()
+	upgrader := websocket.Upgrader{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, p, err := conn.ReadMessage()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if string(p) != string(message.Data) {
+			t.Errorf("expected message data %q, got %q", message.Data, p)
+		}
+		for _, m := range []string{"one", "two"} {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(m))
+		}
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	t.Cleanup(s.Close)
+
+	client := fn.New(fn.WithRegistry(TestRegistry))
+	f, err := client.Init(fn.Function{Runtime: TestRuntime, Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var received []string
+	err = client.InvokeStream(context.Background(), f.Root, s.URL, message, fn.StreamWebSocket, func(m fn.StreamMessage) error {
+		received = append(received, m.Data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(received, ",") != "one,two" {
+		t.Fatalf("unexpected messages received: %v", received)
+	}
+}
+
 // TestClient_Instances ensures that when a function is run (locally) its metadata
 // is available to other clients inspecting the same function using .Instances
 func TestClient_Instances(t *testing.T) {
@@ -2215,3 +3072,105 @@ func TestClient_BuildPopulatesRuntimeImage(t *testing.T) {
 		t.Fatalf("written image in ./.func/built-image '%s' does not match expected '%s'", got, expect)
 	}
 }
+
+// TestClient_BuildTagStrategy ensures that the image derived from the
+// registry is tagged by the function's tag strategy, the tag being recorded
+// such that the image is pushed and deployed as built.
+func TestClient_BuildTagStrategy(t *testing.T) {
+	root, cleanup := Mktemp(t)
+	defer cleanup()
+
+	client := fn.New()
+	f, err := client.Init(fn.Function{Runtime: "go", Root: root, Registry: TestRegistry,
+		Build: fn.BuildSpec{TagStrategy: fn.TagStrategyTimestamp}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^\d{8}-\d{6}$`).MatchString(f.Deploy.Tag) {
+		t.Fatalf("expected a tag of the time of the build, got %q", f.Deploy.Tag)
+	}
+	if expected := f.Registry + "/" + f.Name + ":" + f.Deploy.Tag; f.Build.Image != expected {
+		t.Fatalf("expected image %q, got %q", expected, f.Build.Image)
+	}
+	if image, _ := f.ImageName(); image != f.Build.Image {
+		t.Fatalf("expected the image name of the tag built, got %q", image)
+	}
+
+	// An explicit image is tagged as given
+	f.Image = "example.com/alice/myfunc:v1"
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Build.Image != f.Image || f.Deploy.Tag != "" {
+		t.Fatalf("expected the explicit image, got %q (tag %q)", f.Build.Image, f.Deploy.Tag)
+	}
+}
+
+// TestClient_Promote ensures that promoting deploys the image deployed in the
+// source namespace, by digest, to the target without building, and without
+// moving the function from the source.
+func TestClient_Promote(t *testing.T) {
+	const digest = "example.com/alice/myfunc@sha256:aaaa"
+
+	describer := mock.NewDescriber()
+	describer.DescribeFn = func(_ context.Context, name, namespace string) (fn.Instance, error) {
+		if namespace != "staging" {
+			t.Fatalf("expected to describe the source namespace, got %q", namespace)
+		}
+		return fn.Instance{Name: name, Namespace: namespace, Image: digest}, nil
+	}
+	builder := mock.NewBuilder()
+	remover := mock.NewRemover()
+	deployer := mock.NewDeployer()
+	deployer.DeployFn = func(_ context.Context, f fn.Function) (fn.DeploymentResult, error) {
+		if f.Deploy.Image != digest {
+			t.Errorf("expected to deploy %v, got %v", digest, f.Deploy.Image)
+		}
+		return fn.DeploymentResult{Status: fn.Deployed, Namespace: f.Namespace}, nil
+	}
+	client := fn.New(fn.WithDescriber(describer), fn.WithBuilder(builder),
+		fn.WithRemover(remover), fn.WithDeployer(deployer))
+
+	f := fn.Function{Name: "myfunc", Deploy: fn.DeploySpec{Namespace: "staging", Image: "example.com/alice/myfunc:latest"}}
+	promoted, err := client.Promote(context.Background(), f, "staging", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if promoted.Deploy.Namespace != "prod" {
+		t.Errorf("expected the function promoted to prod, got %q", promoted.Deploy.Namespace)
+	}
+	if builder.BuildInvoked || remover.RemoveInvoked {
+		t.Error("expected the function neither built nor removed from the source")
+	}
+	if !deployer.DeployInvoked {
+		t.Error("expected the function deployed")
+	}
+
+	// The image must be known by digest
+	describer.DescribeFn = func(_ context.Context, name, namespace string) (fn.Instance, error) {
+		return fn.Instance{Image: "example.com/alice/myfunc:latest"}, nil
+	}
+	if _, err = client.Promote(context.Background(), f, "staging", "prod"); !errors.Is(err, fn.ErrImageNotResolved) {
+		t.Fatalf("expected ErrImageNotResolved, got %v", err)
+	}
+	if _, err = client.Promote(context.Background(), f, "prod", "prod"); err == nil {
+		t.Fatal("expected an error promoting to the source namespace")
+	}
+
+	// The namespace may be the same of a source in another context, in which
+	// the function is described.
+	type key struct{}
+	source := context.WithValue(context.Background(), key{}, "staging-cluster")
+	describer.DescribeFn = func(ctx context.Context, name, namespace string) (fn.Instance, error) {
+		if ctx.Value(key{}) != "staging-cluster" {
+			t.Error("expected the function described in the source context")
+		}
+		return fn.Instance{Name: name, Namespace: namespace, Image: digest}, nil
+	}
+	if _, err = client.Promote(context.Background(), f, "prod", "prod", fn.WithPromoteSource(source)); err != nil {
+		t.Fatal(err)
+	}
+}
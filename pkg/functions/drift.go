@@ -0,0 +1,105 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Snapshot of a function's deployment, as described by the cluster or as
+// rendered from the function, in the terms in which deployments are compared.
+// Fields not known, such as the digest of an image given by tag, are empty.
+type Snapshot struct {
+	Image              string
+	ImageDigest        string
+	Envs               map[string]string
+	EnvFrom            []string
+	Resources          map[string]string
+	Scale              map[string]string
+	ServiceAccountName string
+	Labels             map[string]string
+	Annotations        map[string]string
+	Triggers           map[string]string
+}
+
+// Change of a field between two snapshots.  From or To is empty if the field
+// is not set in the respective snapshot.
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// Added reports whether the field is set only in the snapshot changed to.
+func (c Change) Added() bool { return c.From == "" && c.To != "" }
+
+// Removed reports whether the field is set only in the snapshot changed from.
+func (c Change) Removed() bool { return c.From != "" && c.To == "" }
+
+// Diff the snapshots, returning the changes from s to to of their image and
+// digest, environment, resources, scale, service account, labels, annotations
+// and triggers, in that order, those of a map field ordered by key.
+func (s Snapshot) Diff(to Snapshot) (changes []Change) {
+	changes = append(changes, DiffValue("image", s.Image, to.Image)...)
+	changes = append(changes, DiffValue("imageDigest", s.ImageDigest, to.ImageDigest)...)
+	changes = append(changes, DiffMaps("envs.", s.Envs, to.Envs)...)
+	changes = append(changes, DiffValue("envFrom", strings.Join(s.EnvFrom, ","), strings.Join(to.EnvFrom, ","))...)
+	changes = append(changes, DiffMaps("resources.", s.Resources, to.Resources)...)
+	changes = append(changes, DiffMaps("scale.", s.Scale, to.Scale)...)
+	changes = append(changes, DiffValue("serviceAccountName", s.ServiceAccountName, to.ServiceAccountName)...)
+	changes = append(changes, DiffMaps("labels.", s.Labels, to.Labels)...)
+	changes = append(changes, DiffMaps("annotations.", s.Annotations, to.Annotations)...)
+	changes = append(changes, DiffMaps("triggers.", s.Triggers, to.Triggers)...)
+	return
+}
+
+// DiffValue of the field, the change from one value to another, if any.
+func DiffValue(field, from, to string) []Change {
+	if from == to {
+		return nil
+	}
+	return []Change{{Field: field, From: from, To: to}}
+}
+
+// DiffMaps returns the changes of each key, in order, as fields of prefix.
+func DiffMaps(prefix string, from, to map[string]string) (changes []Change) {
+	keys := map[string]bool{}
+	for k := range from {
+		keys[k] = true
+	}
+	for k := range to {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		changes = append(changes, DiffValue(prefix+k, from[k], to[k])...)
+	}
+	return
+}
+
+// Diff of the function's deployment from that which deploying it would
+// produce, such as from edits made on the cluster or to the function since
+// it was deployed.  Changes are from the deployment to the function.  The
+// function is neither built nor deployed.
+func (c *Client) Diff(ctx context.Context, f Function) ([]Change, error) {
+	if f.Name == "" {
+		return nil, ErrNameRequired
+	}
+	if f.Deploy.Namespace == "" {
+		return nil, fmt.Errorf("function %v has not been deployed", f.Name)
+	}
+	decrypted, err := f.Decrypt()
+	if err != nil {
+		return nil, err
+	}
+	deployed, desired, err := c.differ.Snapshots(ctx, decrypted)
+	if err != nil {
+		return nil, err
+	}
+	return deployed.Diff(desired), nil
+}
@@ -0,0 +1,83 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestSnapshot_Diff ensures that snapshots are diffed field by field, in
+// order, those of maps by key, with changes marked as added or removed.
+func TestSnapshot_Diff(t *testing.T) {
+	a := Snapshot{
+		Image:              "example.com/f:v1",
+		Envs:               map[string]string{"A": "1", "B": "2"},
+		Scale:              map[string]string{"min-scale": "1"},
+		ServiceAccountName: "sa",
+		Labels:             map[string]string{"team": "a"},
+		Triggers:           map[string]string{"t": "broker=default"},
+	}
+	b := Snapshot{
+		Image:       "example.com/f:v2",
+		ImageDigest: "sha256:aaa",
+		Envs:        map[string]string{"A": "1", "C": "3"},
+		EnvFrom:     []string{"secret:s"},
+		Scale:       map[string]string{"min-scale": "1"},
+		Labels:      map[string]string{"team": "b"},
+		Annotations: map[string]string{"note": "x"},
+		Triggers:    map[string]string{"t": "broker=default"},
+	}
+	want := []Change{
+		{Field: "image", From: "example.com/f:v1", To: "example.com/f:v2"},
+		{Field: "imageDigest", To: "sha256:aaa"},
+		{Field: "envs.B", From: "2"},
+		{Field: "envs.C", To: "3"},
+		{Field: "envFrom", To: "secret:s"},
+		{Field: "serviceAccountName", From: "sa"},
+		{Field: "labels.team", From: "a", To: "b"},
+		{Field: "annotations.note", To: "x"},
+	}
+	got := a.Diff(b)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+	if !got[1].Added() || got[2].Added() || !got[2].Removed() || got[0].Added() || got[0].Removed() {
+		t.Errorf("unexpected added or removed of %+v", got[:3])
+	}
+	if got := a.Diff(a); len(got) != 0 {
+		t.Fatalf("expected no changes, got %+v", got)
+	}
+}
+
+// TestClient_Diff ensures that the diff of a deployed function is that of
+// the snapshots of the client's differ, and that without a differ, or
+// deployment, it is an error.
+func TestClient_Diff(t *testing.T) {
+	f := Function{Name: "f", Deploy: DeploySpec{Namespace: "prod"}}
+	if _, err := New().Diff(context.Background(), f); !errors.Is(err, ErrDifferRequired) {
+		t.Fatalf("expected ErrDifferRequired, got %v", err)
+	}
+
+	client := New(WithDiffer(testDiffer{
+		deployed: Snapshot{Image: "example.com/f:v1"},
+		desired:  Snapshot{Image: "example.com/f:v2"},
+	}))
+	changes, err := client.Diff(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Change{{Field: "image", From: "example.com/f:v1", To: "example.com/f:v2"}}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected %v, got %v", want, changes)
+	}
+
+	if _, err = client.Diff(context.Background(), Function{Name: "f"}); err == nil {
+		t.Fatal("expected an error diffing a function not deployed")
+	}
+}
+
+type testDiffer struct{ deployed, desired Snapshot }
+
+func (d testDiffer) Snapshots(context.Context, Function) (Snapshot, Snapshot, error) {
+	return d.deployed, d.desired, nil
+}
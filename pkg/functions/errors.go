@@ -20,6 +20,7 @@ var (
 	ErrRootRequired              = errors.New("function root path is required")
 	ErrRuntimeNotFound           = errors.New("language runtime not found")
 	ErrRuntimeRequired           = errors.New("language runtime required")
+	ErrRuntimeNotDetected        = errors.New("language runtime of the imported code could not be detected")
 	ErrTemplateMissingRepository = errors.New("template name missing repository prefix")
 	ErrTemplateNotFound          = errors.New("template not found")
 	ErrTemplatesNotFound         = errors.New("templates path (runtimes) not found")
@@ -36,6 +37,70 @@ var (
 	// ErrConflictingImageAndRegistry is returned when both --image and --registry flags are explicitly provided
 	ErrConflictingImageAndRegistry = errors.New("both --image and --registry flags provided")
 
+	// ErrVerifierRequired is returned when a function defines a signature
+	// policy (deploy.verify) but the client has no verifier with which to
+	// enforce it.
+	ErrVerifierRequired = errors.New("image signature verification is required but no verifier is configured")
+
+	// ErrHookRunnerRequired is returned when a function defines a hook of a
+	// container image but the client has no hook runner with which to run it.
+	ErrHookRunnerRequired = errors.New("a container image hook is defined but no hook runner is configured")
+
+	// ErrDigestResolverRequired is returned when deploying with the image's
+	// digest pinned but the client has no resolver of digests.
+	ErrDigestResolverRequired = errors.New("pinning the image digest is required but no digest resolver is configured")
+
+	// ErrTagListerRequired is returned when checking the function's images
+	// for upgrades but the client has no lister of their tags.
+	ErrTagListerRequired = errors.New("listing the tags of images is required but no tag lister is configured")
+
+	// ErrRebaseNotSupported is returned when rebasing a function whose
+	// builder cannot rebase its image onto a newer run image.
+	ErrRebaseNotSupported = errors.New("the function's builder does not support rebasing its image")
+
+	// ErrDifferRequired is returned when diffing a function's deployment
+	// but the client has no differ with which to snapshot it.
+	ErrDifferRequired = errors.New("diffing the deployment is required but no differ is configured")
+
+	// ErrSBOMGeneratorRequired is returned when a function defines the format
+	// of its SBOM (build.sbom) but the client has no generator of SBOMs.
+	ErrSBOMGeneratorRequired = errors.New("generating an SBOM is required but no SBOM generator is configured")
+
+	// ErrSignerRequired is returned when a function is to be signed once
+	// pushed (build.sign) but the client has no signer with which to sign it.
+	ErrSignerRequired = errors.New("signing the image is required but no signer is configured")
+
+	// ErrScannerRequired is returned when a function's image is to be
+	// scanned (build.scan) but the client has no scanner with which to scan it.
+	ErrScannerRequired = errors.New("scanning the image is required but no scanner is configured")
+
+	// ErrVulnerable is returned when the scan of a function's image finds
+	// vulnerabilities at or above the severity of its scan.
+	ErrVulnerable = errors.New("the image has known vulnerabilities")
+
+	// ErrDigestMismatch is returned when the digest of an image in its
+	// registry is not that of the image built and pushed.
+	ErrDigestMismatch = errors.New("image digest mismatch")
+
+	// ErrArtifactNotFound is returned when an artifact is not attached to the
+	// function's image.
+	ErrArtifactNotFound = errors.New("artifact not found")
+
+	// ErrProfileNotFound is returned when a profile has no overlay file.
+	ErrProfileNotFound = errors.New("profile not found")
+
+	// ErrImageNotResolved is returned when promoting a function whose deployed
+	// image is not known by digest.
+	ErrImageNotResolved = errors.New("deployed image is not resolved to a digest")
+
+	// ErrPostDeployHookFailed is returned when a postDeploy hook of a function
+	// fails, the function having nonetheless been deployed.
+	ErrPostDeployHookFailed = errors.New("function deployed but postDeploy hook failed")
+
+	// ErrMetricsNotAvailable is returned when there is no source of the
+	// metrics of functions' invocations, such as Prometheus on the cluster.
+	ErrMetricsNotAvailable = errors.New("metrics not available")
+
 	// ErrInvalidDomain is returned when a domain name doesn't meet DNS subdomain requirements
 	ErrInvalidDomain = errors.New("invalid domain")
 
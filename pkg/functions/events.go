@@ -0,0 +1,199 @@
+package functions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// EventsFile is the name of the file within the runtime metadata directory
+// (RunDataDir) in which captured events are persisted.
+const EventsFile = "events.ndjson"
+
+// RecordedEvent is a CloudEvent as captured by an EventStore, along with
+// the time at which it was received.
+type RecordedEvent struct {
+	Received time.Time         `json:"received"`
+	Event    cloudevents.Event `json:"event"`
+}
+
+// EventFilter selects a subset of recorded events.  Zero values match all.
+type EventFilter struct {
+	// Types of events to include.  Empty includes all types.
+	Types []string
+	// Since excludes events received before this time.
+	Since time.Time
+	// Until excludes events received after this time.
+	Until time.Time
+}
+
+// Matches returns true if the recorded event passes the filter.
+func (ef EventFilter) Matches(e RecordedEvent) bool {
+	if len(ef.Types) > 0 && !slices.Contains(ef.Types, e.Event.Type()) {
+		return false
+	}
+	if !ef.Since.IsZero() && e.Received.Before(ef.Since) {
+		return false
+	}
+	if !ef.Until.IsZero() && e.Received.After(ef.Until) {
+		return false
+	}
+	return true
+}
+
+// EventStore is a local, file-backed store of captured events.  Events are
+// appended as newline-delimited JSON such that the store can be inspected
+// and edited with ordinary tools.
+type EventStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewEventStore returns an event store persisted at the given path.
+func NewEventStore(path string) *EventStore {
+	return &EventStore{path: path}
+}
+
+// NewFunctionEventStore returns the event store of the given function, which
+// lives in its runtime metadata directory.
+func NewFunctionEventStore(f Function) (*EventStore, error) {
+	if err := ensureRunDataDir(f.Root); err != nil {
+		return nil, err
+	}
+	return NewEventStore(filepath.Join(f.Root, RunDataDir, EventsFile)), nil
+}
+
+// Path of the underlying file.
+func (s *EventStore) Path() string {
+	return s.path
+}
+
+// Append an event to the store, recording it as received at t.
+func (s *EventStore) Append(e cloudevents.Event, t time.Time) error {
+	if err := e.Validate(); err != nil {
+		return fmt.Errorf("invalid event: %w", err)
+	}
+	b, err := json.Marshal(RecordedEvent{Received: t.UTC(), Event: e})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(b, '\n'))
+	return err
+}
+
+// Events returns the recorded events which match the filter in the order
+// they were received.  A store which does not yet exist contains no events.
+func (s *EventStore) Events(filter EventFilter) (events []RecordedEvent, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []RecordedEvent{}, nil
+	} else if err != nil {
+		return
+	}
+	defer file.Close()
+
+	events = []RecordedEvent{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e RecordedEvent
+		if err = json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("unable to read event on line %v of %v: %w", line, s.path, err)
+		}
+		if filter.Matches(e) {
+			events = append(events, e)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// Clear removes all recorded events.
+func (s *EventStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Handler returns an HTTP handler which accepts CloudEvents in either binary
+// or structured mode and appends them to the store.  It can be used as the
+// sink of a broker or source in order to capture events for later replay.
+// Events rejected by any of the optional validators are not recorded, and
+// their sender receives a 422 (Unprocessable Entity).
+func (s *EventStore) Handler(validators ...func(cloudevents.Event) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event, err := cehttp.NewEventFromHTTPRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, validate := range validators {
+			if err = validate(*event); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+		}
+		if err = s.Append(*event, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// ReplayEvents sends the given recorded events, in order, to the target
+// instance of the function.  The target follows the same semantics as Invoke
+// ('local', 'remote', an environment or an explicit URL).  Each event is sent
+// as received, retaining its original ID, source and extensions.  Replay
+// stops at the first event which can not be delivered.
+func (c *Client) ReplayEvents(ctx context.Context, f Function, target string, events []RecordedEvent) (sent int, err error) {
+	route, err := invocationRoute(ctx, c, f, target)
+	if err != nil {
+		return
+	}
+	client, err := cloudevents.NewClientHTTP(
+		cloudevents.WithTarget(route),
+		cloudevents.WithRoundTripper(c.transport))
+	if err != nil {
+		return
+	}
+	for _, e := range events {
+		if c.verbose {
+			fmt.Printf("Replaying event %v (%v) to %v\n", e.Event.ID(), e.Event.Type(), route)
+		}
+		if result := client.Send(cloudevents.ContextWithTarget(ctx, route), e.Event); cloudevents.IsUndelivered(result) {
+			return sent, fmt.Errorf("unable to replay event %v: %v", e.Event.ID(), result)
+		} else if !cloudevents.IsACK(result) {
+			return sent, fmt.Errorf("event %v was not accepted: %v", e.Event.ID(), result)
+		}
+		sent++
+	}
+	return
+}
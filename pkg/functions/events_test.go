@@ -0,0 +1,143 @@
+package functions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+
+	. "knative.dev/func/pkg/testing"
+)
+
+func newTestEvent(t *testing.T, id, typ string) cloudevents.Event {
+	t.Helper()
+	e := cloudevents.NewEvent()
+	e.SetID(id)
+	e.SetSource("/test")
+	e.SetType(typ)
+	if err := e.SetData(cloudevents.ApplicationJSON, map[string]string{"id": id}); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// TestEventStore_Filter ensures events are persisted in order and can be
+// filtered by type and received time.
+func TestEventStore_Filter(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFunctionEventStore(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, typ := range []string{"a", "b", "a", "c"} {
+		if err := store.Append(newTestEvent(t, string(rune('1'+i)), typ), t0.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter EventFilter
+		want   []string // IDs
+	}{
+		{"all", EventFilter{}, []string{"1", "2", "3", "4"}},
+		{"type", EventFilter{Types: []string{"a"}}, []string{"1", "3"}},
+		{"types", EventFilter{Types: []string{"b", "c"}}, []string{"2", "4"}},
+		{"since", EventFilter{Since: t0.Add(2 * time.Hour)}, []string{"3", "4"}},
+		{"until", EventFilter{Until: t0.Add(time.Hour)}, []string{"1", "2"}},
+		{"range and type", EventFilter{Types: []string{"a"}, Since: t0.Add(time.Hour), Until: t0.Add(3 * time.Hour)}, []string{"3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := store.Events(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != len(tt.want) {
+				t.Fatalf("expected %v events, got %v", len(tt.want), len(events))
+			}
+			for i, e := range events {
+				if e.Event.ID() != tt.want[i] {
+					t.Fatalf("expected event %v at position %v, got %v", tt.want[i], i, e.Event.ID())
+				}
+			}
+		})
+	}
+
+	if err := store.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if events, err := store.Events(EventFilter{}); err != nil || len(events) != 0 {
+		t.Fatalf("expected no events after clear, got %v (%v)", len(events), err)
+	}
+}
+
+// TestEventStore_RecordReplay ensures events received by the store's handler
+// are replayed, unchanged, to a given target.
+func TestEventStore_RecordReplay(t *testing.T) {
+	root, rm := Mktemp(t)
+	defer rm()
+
+	f, err := New().Init(Function{Runtime: "go", Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewFunctionEventStore(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record an event by sending it to the store's handler
+	recorder := httptest.NewServer(store.Handler())
+	defer recorder.Close()
+	sender, err := cloudevents.NewClientHTTP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := cloudevents.ContextWithTarget(context.Background(), recorder.URL)
+	if result := sender.Send(ctx, newTestEvent(t, "recorded", "com.example")); !cloudevents.IsACK(result) {
+		t.Fatal(result)
+	}
+
+	// Replay recorded events to a receiving "function"
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	fnServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e, err := cehttp.NewEventFromHTTPRequest(r)
+		if err != nil {
+			t.Error(err)
+		} else {
+			mu.Lock()
+			received = append(received, e.ID())
+			mu.Unlock()
+		}
+	}))
+	defer fnServer.Close()
+
+	events, err := store.Events(EventFilter{Types: []string{"com.example"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := New().ReplayEvents(context.Background(), f, fnServer.URL, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(received) != 1 || received[0] != "recorded" {
+		t.Fatalf("expected the recorded event to be replayed, sent %v, received %v", sent, received)
+	}
+}
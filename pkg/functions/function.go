@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -72,6 +73,10 @@ type Function struct {
 	// Template for the function.
 	Template string `yaml:"-"`
 
+	// Origin records the template, and repository, from which the function
+	// was created, such that it may be traced to its source.
+	Origin Origin `yaml:"origin,omitempty"`
+
 	// Registry at which to store interstitial containers, in the form
 	// [registry]/[user].
 	Registry string `yaml:"registry,omitempty"`
@@ -84,7 +89,9 @@ type Function struct {
 	// example:
 	//   alice/my.function.name
 	// If Image is provided, it overrides the default of concatenating
-	// "Registry+Name:latest" to derive the Image.
+	// "Registry+Name:latest" to derive the Image.  Its tag may include
+	// expressions expanded when built, such as {{git.sha}} (see
+	// TagExpressions).
 	Image string `yaml:"image,omitempty"`
 
 	// Namespace in which to deploy the Function
@@ -99,6 +106,9 @@ type Function struct {
 	// See Client.Invoke for usage.
 	Invoke string `yaml:"invoke,omitempty" jsonschema:"enum=http,enum=cloudevent"`
 
+	// Events declares the events produced by the function.
+	Events EventsSpec `yaml:"events,omitempty"`
+
 	// Build defines the build properties for a function
 	Build BuildSpec `yaml:"build,omitempty"`
 
@@ -108,9 +118,29 @@ type Function struct {
 	// Deploy defines the deployment properties for a function
 	Deploy DeploySpec `yaml:"deploy,omitempty"`
 
+	// Hooks defines the commands and jobs run about the building and
+	// deploying of the function
+	Hooks HooksSpec `yaml:"hooks,omitempty"`
+
+	// Sops is the metadata of a func.yaml whose values have been encrypted
+	// using SOPS.  It is managed by the sops tool and should not be edited.
+	Sops *SopsMetadata `yaml:"sops,omitempty"`
+
 	Local Local `yaml:"-"`
 }
 
+// Origin of a function: the template and repository from which it was
+// created, or the repository of the existing code it was created from.
+type Origin struct {
+	// Template of the repository from which the function was created, if any.
+	Template string `yaml:"template,omitempty"`
+
+	// Repository of the template: the URL of a git repository, or the name of
+	// the repository if it has none ("default" being that embedded).  For a
+	// function created from existing code, the repository of the code.
+	Repository string `yaml:"repository,omitempty"`
+}
+
 // KnativeSubscription
 type KnativeSubscription struct {
 	Source  string            `yaml:"source"`
@@ -130,7 +160,15 @@ type BuildSpec struct {
 	//   s2i: example.com/user/my-s2i-node-builder
 	BuilderImages map[string]string `yaml:"builderImages,omitempty"`
 
-	// Optional list of buildpacks to use when building the function
+	// TagStrategy by which the tag of the image derived from the registry is
+	// chosen when built: latest (the default), git-sha, semver or timestamp.
+	TagStrategy string `yaml:"tagStrategy,omitempty" jsonschema:"enum=latest,enum=git-sha,enum=semver,enum=timestamp"`
+
+	// Optional list of buildpacks to use when building the function with
+	// pack, in order, in place of those of the builder image.  Each is an
+	// image (docker://), a path or file:// URI relative to the function, an
+	// http(s):// URL of an archive or the ID of a buildpack; "from=builder"
+	// stands for the builder image's own order.
 	Buildpacks []string `yaml:"buildpacks,omitempty"`
 
 	// Builder is the name of the subsystem that will complete the underlying
@@ -148,15 +186,74 @@ type BuildSpec struct {
 	// on-cluster during when built remotely.
 	RemoteStorageClass string `yaml:"remoteStorageClass,omitempty"`
 
+	// PipelineTemplate is the path, relative to the function's root, of a
+	// template of the Tekton Pipeline used when deployed remotely, in lieu of
+	// that which is generated.  See docs/reference/func_yaml.md for the
+	// parameters with which it is rendered.
+	PipelineTemplate string `yaml:"pipelineTemplate,omitempty"`
+
+	// Dockerfile is the path, relative to the function's root, of the
+	// Dockerfile from which the dockerfile and kaniko builders build the
+	// function's image.  "Dockerfile" by default.
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+
 	// Image stores last built image name NOT in func.yaml, but instead
 	// in .func/built-image
 	Image string `yaml:"-"`
 
-	// BaseImage defines an override for the function to be built upon (host bulder only)
+	// Platforms of the last built image when built for several, stored NOT
+	// in func.yaml, but instead in .func/built-platforms
+	Platforms []Platform `yaml:"-"`
+
+	// BaseImage defines an override for the image the function is built
+	// upon: the builder image of the pack and s2i builders (unless a
+	// builder-specific image is defined in BuilderImages), or the image the
+	// host builder layers the function upon.
 	BaseImage string `yaml:"baseImage,omitempty"`
 
+	// RunImage defines an override for the image the built function runs
+	// upon: the run image of the pack builder, the runtime image of the s2i
+	// builder, or, taking precedence over BaseImage, the image the host
+	// builder layers the function upon.
+	RunImage string `yaml:"runImage,omitempty"`
+
+	// Incremental builds of the s2i builder restore the artifacts saved from
+	// the function's previous image, such as its downloaded dependencies.
+	Incremental bool `yaml:"incremental,omitempty"`
+
+	// TrustBuilder overrides whether the pack builder trusts the builder
+	// image, running its lifecycle in a single container which has access to
+	// the credentials of the registry.  By default, only builder images of
+	// known publishers are trusted.
+	TrustBuilder *bool `yaml:"trustBuilder,omitempty"`
+
 	// Mounts used in build phase. This is useful in particular for paketo bindings.
 	Mounts []MountSpec `yaml:"volumes,omitempty"`
+
+	// Secrets of the build, such as the token of a private package index,
+	// which are available to its steps but are not written to the image.
+	// Supported by the buildkit and pack builders.
+	Secrets []SecretSpec `yaml:"secrets,omitempty"`
+
+	// Artifacts are files of the function attached to its image, as OCI
+	// artifacts referring to it, when it is pushed.
+	Artifacts []ArtifactSpec `yaml:"artifacts,omitempty"`
+
+	// SBOM is the format, cyclonedx or spdx, of a software bill of materials
+	// of the function's dependencies generated on each build, and attached to
+	// its image as an OCI artifact when it is pushed.
+	SBOM string `yaml:"sbom,omitempty" jsonschema:"enum=cyclonedx,enum=spdx"`
+
+	// Scan the function's image for known vulnerabilities once it is built,
+	// failing the build if any are found at or above its severity.
+	Scan *ScanSpec `yaml:"scan,omitempty"`
+
+	// Sign the function's image with cosign once it is pushed.
+	Sign *SignSpec `yaml:"sign,omitempty"`
+
+	// Signature is the reference of the cosign signature of the image last
+	// pushed, recorded when it is signed.
+	Signature string `yaml:"signature,omitempty"`
 }
 
 type MountSpec struct {
@@ -189,10 +286,22 @@ type DeploySpec struct {
 	// Image is the deployed image including sha256
 	Image string `yaml:"image,omitempty"`
 
+	// Tag of the image as last built, derived by build.tagStrategy or the
+	// expressions of image.  Empty if tagged "latest" or given explicitly.
+	Tag string `yaml:"tag,omitempty"`
+
 	// Map containing user-supplied annotations
 	// Example: { "division": "finance" }
 	Annotations map[string]string `yaml:"annotations,omitempty"`
 
+	// ServiceAnnotations are annotations of only the service of the function,
+	// replacing those of Annotations of the same key.
+	ServiceAnnotations map[string]string `yaml:"serviceAnnotations,omitempty"`
+
+	// RevisionAnnotations are annotations of only the template of the
+	// function's revisions, replacing those of Annotations of the same key.
+	RevisionAnnotations map[string]string `yaml:"revisionAnnotations,omitempty"`
+
 	// Options to be set on deployed function (scaling, etc.)
 	Options Options `yaml:"options,omitempty"`
 
@@ -209,6 +318,43 @@ type DeploySpec struct {
 	ServiceAccountName string `yaml:"serviceAccountName,omitempty"`
 
 	Subscriptions []KnativeSubscription `yaml:"subscriptions,omitempty"`
+
+	// Verify the signature of the image prior to deployment.
+	Verify *VerifySpec `yaml:"verify,omitempty"`
+
+	// Traffic split between the function's revisions on deployment.  By
+	// default the traffic is routed as it was, all of it to the latest
+	// revision once first deployed.
+	Traffic []TrafficSplit `yaml:"traffic,omitempty"`
+
+	// Strategy of deployment: "latest", the default, routing the traffic to
+	// each new revision once ready, or "blue-green", routing a new revision
+	// none of the traffic but a URL of its own, tagged "candidate", until
+	// promoted.
+	Strategy string `yaml:"strategy,omitempty" jsonschema:"enum=latest,enum=blue-green"`
+
+	// Deployer of the function: "knative", the default, deploying a Knative
+	// Service, or "k8s", deploying a plain Deployment and Service for
+	// clusters without Knative Serving.
+	Deployer string `yaml:"deployer,omitempty" jsonschema:"enum=knative,enum=k8s"`
+
+	// Expose the function outside of the cluster when deployed by the "k8s"
+	// deployer.  By default it is reachable only within the cluster.
+	Expose *ExposeSpec `yaml:"expose,omitempty"`
+
+	// Domains at which the function is also served, each by a Knative
+	// DomainMapping.  Unlike Domain, which selects among the domains the
+	// cluster is configured with, these are custom domains, such as
+	// api.example.com, whose DNS must resolve to the cluster's ingress.
+	Domains []string `yaml:"domains,omitempty"`
+
+	// Scheduling of the function's instances onto nodes: a node selector,
+	// tolerations and affinity.
+	Scheduling *SchedulingSpec `yaml:"scheduling,omitempty"`
+
+	// Route to the function: its visibility and, on OpenShift Serverless,
+	// the annotations of its Route.
+	Route *RouteSpec `yaml:"route,omitempty"`
 }
 
 // HealthEndpoints specify the liveness and readiness endpoints for a Runtime
@@ -314,7 +460,10 @@ func NewFunction(root string) (f Function, err error) {
 	}
 	// ---- LOCAL SETTINGS - STUFF NOT IN FUNC.YAML ---- //
 
-	f.Build.Image, err = f.getLastBuiltImage()
+	if f.Build.Image, err = f.getLastBuiltImage(); err != nil {
+		return
+	}
+	f.Build.Platforms, err = f.getBuiltPlatforms()
 
 	return
 }
@@ -334,6 +483,21 @@ func (f Function) Validate() error {
 		validateOptions(f.Deploy.Options),
 		ValidateLabels(f.Deploy.Labels),
 		validateGit(f.Build.Git),
+		validateEvents(f.Root, f.Events),
+		validateVerify(f.Deploy.Verify),
+		validateTraffic(f.Deploy.Traffic),
+		validateStrategy(f.Deploy),
+		validateDeployer(f.Deploy),
+		validateDomains(f.Deploy.Domains),
+		validateScheduling(f.Deploy.Scheduling),
+		validateRoute(f.Deploy.Route),
+		validateArtifacts(f.Root, f.Build.Artifacts),
+		validateSecrets(f.Build.Secrets),
+		validateSBOM(f.Build.SBOM),
+		validateScan(f.Build.Scan),
+		validateBuildpacks(f.Root, f.Build.Buildpacks),
+		validateTags(f),
+		validateHooks(f.Hooks),
 	}
 
 	var b strings.Builder
@@ -358,6 +522,21 @@ func (f Function) Validate() error {
 
 var envPattern = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
 
+// InterpolatesLocalEnvs returns whether any of the envs takes its value from
+// a local environment variable ({{ env:NAME }}), whose value the resources
+// rendered of the function then hold.
+func InterpolatesLocalEnvs(ee []Env) bool {
+	for _, e := range ee {
+		if e.Value == nil {
+			continue
+		}
+		if parts := envPattern.FindStringSubmatch(*e.Value); len(parts) > 2 && parts[1] == "env" {
+			return true
+		}
+	}
+	return false
+}
+
 // Interpolate Env slice
 // Values with no special format are preserved as simple values.
 // Values which do include the interpolation format (begin with {{) but are not
@@ -586,6 +765,12 @@ func (f Function) Initialized() bool {
 //   - key: EXAMPLE2                            # Label from the local ENV var
 //     value: {{ env:MY_ENV }}
 func (f Function) LabelsMap() (map[string]string, error) {
+	return f.LabelsMapOf(ScopeBoth)
+}
+
+// LabelsMapOf the given scope, being the default labels and those of the
+// function of the scope or of both scopes.  The labels of ScopeBoth are all.
+func (f Function) LabelsMapOf(scope string) (map[string]string, error) {
 	defaultLabels := []Label{
 		{
 			Key:   ptr.String(fnlabels.FunctionNameKey),
@@ -601,6 +786,7 @@ func (f Function) LabelsMap() (map[string]string, error) {
 	if err := ValidateLabels(labels); len(err) != 0 {
 		return nil, errors.New(strings.Join(err, " "))
 	}
+	labels = slices.DeleteFunc(labels, func(l Label) bool { return !l.inScope(scope) })
 
 	l := map[string]string{}
 	for _, label := range labels {
@@ -620,6 +806,24 @@ func (f Function) LabelsMap() (map[string]string, error) {
 	return l, nil
 }
 
+// AnnotationsOf the given scope, being the function's annotations with those
+// of only the service (ScopeService) or only its revisions (ScopeRevision).
+// The annotations of ScopeBoth are those of Annotations alone.
+func (f Function) AnnotationsOf(scope string) map[string]string {
+	aa := make(map[string]string, len(f.Deploy.Annotations))
+	for k, v := range f.Deploy.Annotations {
+		aa[k] = v
+	}
+	scoped := map[string]map[string]string{
+		ScopeService:  f.Deploy.ServiceAnnotations,
+		ScopeRevision: f.Deploy.RevisionAnnotations,
+	}[scope]
+	for k, v := range scoped {
+		aa[k] = v
+	}
+	return aa
+}
+
 // ImageName returns a full image name (OCI container tag) for the
 // Function based off of the Function's `Registry` member plus `Name`.
 // Used to calculate the final value for .Deploy.Image when none is provided
@@ -644,12 +848,16 @@ func (f Function) ImageName() (image string, err error) {
 
 	f.Registry = strings.Trim(f.Registry, "/") // too defensive?
 
-	// Explicitly append :latest tag.  We expect source control to drive
-	// versioning, rather than rely on image tags with explicitly pinned version
-	// numbers, as is seen in many serverless solutions.  This will be updated
-	// to branch name when we add source-driven canary/ bluegreen deployments.
+	// Explicitly append :latest tag, unless another was derived by the tag
+	// strategy of the last build (see Deploy.Tag).  We expect source control
+	// to drive versioning, rather than rely on image tags with explicitly
+	// pinned version numbers, as is seen in many serverless solutions.
 	// For pinning to an exact container image, see ImageWithDigest
-	refStr := f.Registry + "/" + f.Name + ":latest"
+	tag := f.Deploy.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+	refStr := f.Registry + "/" + f.Name + ":" + tag
 
 	ref, err := name.ParseReference(refStr)
 	if err != nil {
@@ -766,8 +974,8 @@ func (f Function) newLocal() (localConfig Local, err error) {
 	return
 }
 
-// WriteRuntimeBuiltImage writes built image name into runtime metadata
-// directory (.func/) from f.Build.Image
+// WriteRuntimeBuiltImage writes built image name and the platforms for which
+// it was built into runtime metadata directory (.func/) from f.Build.Image
 func (f Function) WriteRuntimeBuiltImage(verbose bool) error {
 	path := filepath.Join(f.Root, RunDataDir, BuiltImage)
 
@@ -780,7 +988,10 @@ func (f Function) WriteRuntimeBuiltImage(verbose bool) error {
 		fmt.Printf("Writing built image: '%s' at path: '%s'\n", f.Build.Image, path)
 	}
 
-	return os.WriteFile(path, []byte(f.Build.Image), os.ModePerm)
+	if err := os.WriteFile(path, []byte(f.Build.Image), os.ModePerm); err != nil {
+		return err
+	}
+	return f.writeBuiltPlatforms()
 }
 
 // getLastBuiltImage reads .func/built-image and returns its value or empty string
@@ -0,0 +1,65 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ArtifactSpec declares a file of the function's project, such as an OpenAPI
+// specification, an event schema or a README, which is attached to the
+// function's image as an OCI artifact when it is pushed.
+type ArtifactSpec struct {
+	// Path of the file, relative to the function root.
+	Path string `yaml:"path"`
+
+	// Type of the artifact (its OCI artifactType), for example
+	// "application/vnd.oai.openapi".  Defaults to DefaultArtifactType.
+	Type string `yaml:"type,omitempty"`
+
+	// MediaType of the file's content.  Defaults by the file's extension.
+	MediaType string `yaml:"mediaType,omitempty"`
+}
+
+// DefaultArtifactType of artifacts which do not declare their type.
+const DefaultArtifactType = "application/vnd.knative.func.artifact.v1"
+
+// Artifact attached to a function's image, as found in its registry.
+type Artifact struct {
+	// Path of the file from which the artifact was attached, if known.
+	Path      string `json:"path,omitempty" yaml:"path,omitempty"`
+	Type      string `json:"type" yaml:"type"`
+	MediaType string `json:"mediaType,omitempty" yaml:"mediaType,omitempty"`
+	// Digest of the artifact's manifest, by which it may be fetched.
+	Digest string `json:"digest" yaml:"digest"`
+	Size   int64  `json:"size" yaml:"size"`
+}
+
+// validateArtifacts checks that each declared artifact is a distinct file
+// within the function.
+// Returns array of error messages, empty if no errors are found
+func validateArtifacts(root string, artifacts []ArtifactSpec) (errs []string) {
+	seen := map[string]bool{}
+	for i, a := range artifacts {
+		if a.Path == "" {
+			errs = append(errs, fmt.Sprintf("build.artifacts entry #%d is missing path field", i))
+			continue
+		}
+		if !filepath.IsLocal(a.Path) {
+			errs = append(errs, fmt.Sprintf("build.artifacts path %q must be relative to, and within, the function root", a.Path))
+			continue
+		}
+		if seen[filepath.Clean(a.Path)] {
+			errs = append(errs, fmt.Sprintf("build.artifacts path %q is declared more than once", a.Path))
+		}
+		seen[filepath.Clean(a.Path)] = true
+		fi, err := os.Stat(filepath.Join(root, a.Path))
+		if errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Sprintf("build.artifacts path %q does not exist", a.Path))
+		} else if err == nil && fi.IsDir() {
+			errs = append(errs, fmt.Sprintf("build.artifacts path %q is a directory", a.Path))
+		}
+	}
+	return
+}
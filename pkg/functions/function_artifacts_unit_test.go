@@ -0,0 +1,38 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_validateArtifacts(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "openapi.yaml"), []byte("openapi: 3.0.0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "schemas"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		artifacts []ArtifactSpec
+		errs      int
+	}{
+		{"none", nil, 0},
+		{"file", []ArtifactSpec{{Path: "openapi.yaml", Type: "application/vnd.oai.openapi"}}, 0},
+		{"missing path", []ArtifactSpec{{Type: "application/vnd.oai.openapi"}}, 1},
+		{"absolute", []ArtifactSpec{{Path: filepath.Join(root, "openapi.yaml")}}, 1},
+		{"outside", []ArtifactSpec{{Path: "../openapi.yaml"}}, 1},
+		{"duplicate", []ArtifactSpec{{Path: "openapi.yaml"}, {Path: "./openapi.yaml"}}, 1},
+		{"not found", []ArtifactSpec{{Path: "README.md"}}, 1},
+		{"directory", []ArtifactSpec{{Path: "schemas"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateArtifacts(root, tt.artifacts); len(errs) != tt.errs {
+				t.Errorf("validateArtifacts() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// BuildpacksFromBuilder is the entry of build.buildpacks standing for the
+// order of the builder image's own buildpacks, such that those declared are
+// run before or after them rather than in their place.
+const BuildpacksFromBuilder = "from=builder"
+
+// BuildpackPath of a buildpack declared as a file:// URI, which is relative
+// to the function's root unless absolute, as in file://./buildpacks/apm.
+// Returns "" if the buildpack is not so declared.
+func BuildpackPath(root, buildpack string) string {
+	p, ok := strings.CutPrefix(buildpack, "file://")
+	if !ok {
+		return ""
+	}
+	p = filepath.FromSlash(p)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(root, p)
+	}
+	return p
+}
+
+// validateBuildpacks declared by the function: each an image (docker://),
+// a path or file:// URI of a directory or archive, an http(s) URL of an
+// archive, the ID of a buildpack of the builder or registry, or the builder's
+// own order (BuildpacksFromBuilder, at most once).
+// Returns array of error messages, empty if no errors are found
+func validateBuildpacks(root string, buildpacks []string) (errs []string) {
+	var fromBuilder bool
+	for i, b := range buildpacks {
+		switch {
+		case strings.TrimSpace(b) == "":
+			errs = append(errs, fmt.Sprintf("build.buildpacks[%d] is empty", i))
+		case b == BuildpacksFromBuilder:
+			if fromBuilder {
+				errs = append(errs, fmt.Sprintf("build.buildpacks may include %q only once", BuildpacksFromBuilder))
+			}
+			fromBuilder = true
+		case strings.HasPrefix(b, "docker://"):
+			if _, err := name.ParseReference(strings.TrimPrefix(b, "docker://")); err != nil {
+				errs = append(errs, fmt.Sprintf("build.buildpacks[%d] %q is not a valid image: %v", i, b, err))
+			}
+		case strings.HasPrefix(b, "file://"):
+			if _, err := os.Stat(BuildpackPath(root, b)); errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, fmt.Sprintf("build.buildpacks[%d] %q does not exist", i, b))
+			}
+		case strings.Contains(b, "://"):
+			if u, err := url.Parse(b); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				errs = append(errs, fmt.Sprintf("build.buildpacks[%d] %q is not supported. Expected an image (docker://), a file:// or http(s):// URI, a path or a buildpack ID", i, b))
+			}
+		}
+	}
+	return
+}
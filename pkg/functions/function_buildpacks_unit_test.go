@@ -0,0 +1,56 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_validateBuildpacks(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "buildpacks", "apm"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		buildpacks []string
+		errs       int
+	}{
+		{"none", nil, 0},
+		{"image", []string{"docker://gcr.io/paketo-buildpacks/go:4"}, 0},
+		{"invalid image", []string{"docker://gcr.io/Paketo:latest:4"}, 1},
+		{"relative file", []string{"file://./buildpacks/apm"}, 0},
+		{"absolute file", []string{"file://" + filepath.ToSlash(filepath.Join(root, "buildpacks", "apm"))}, 0},
+		{"missing file", []string{"file://./buildpacks/missing"}, 1},
+		{"archive", []string{"https://example.com/apm.tgz"}, 0},
+		{"unsupported scheme", []string{"ftp://example.com/apm.tgz"}, 1},
+		{"ids and paths", []string{"paketo-buildpacks/go", "urn:cnb:registry:example/apm@1.0.0", "./buildpacks/apm"}, 0},
+		{"from builder", []string{"docker://example.com/apm", BuildpacksFromBuilder}, 0},
+		{"from builder twice", []string{BuildpacksFromBuilder, BuildpacksFromBuilder}, 1},
+		{"empty", []string{" "}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateBuildpacks(root, tt.buildpacks); len(errs) != tt.errs {
+				t.Errorf("validateBuildpacks() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
+
+func TestBuildpackPath(t *testing.T) {
+	root := filepath.FromSlash("/func")
+	tests := []struct {
+		buildpack, path string
+	}{
+		{"file://./buildpacks/apm", filepath.Join(root, "buildpacks", "apm")},
+		{"file://buildpacks/apm.tgz", filepath.Join(root, "buildpacks", "apm.tgz")},
+		{"docker://example.com/apm", ""},
+		{"./buildpacks/apm", ""},
+	}
+	for _, tt := range tests {
+		if p := BuildpackPath(root, tt.buildpack); p != tt.path {
+			t.Errorf("BuildpackPath(%q) = %q, want %q", tt.buildpack, p, tt.path)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package functions
+
+import (
+	"fmt"
+)
+
+const (
+	// DeployerKnative deploys a function as a Knative Service, the default.
+	DeployerKnative = "knative"
+	// DeployerK8s deploys a function as a plain Deployment and Service, for
+	// clusters without Knative Serving.
+	DeployerK8s = "k8s"
+)
+
+const (
+	// ExposeIngress exposes a function by an Ingress.
+	ExposeIngress = "ingress"
+	// ExposeHTTPRoute exposes a function by an HTTPRoute of the Gateway API.
+	ExposeHTTPRoute = "httproute"
+)
+
+// ExposeSpec defines how a function deployed by the "k8s" deployer is exposed
+// outside of the cluster.  Knative exposes functions itself.
+type ExposeSpec struct {
+	// Kind of resource by which the function is exposed: "ingress" or
+	// "httproute".
+	Kind string `yaml:"kind" jsonschema:"enum=ingress,enum=httproute"`
+
+	// Host at which the function is exposed.  Defaults to
+	// <name>.<namespace>.<domain> if the function has a domain.
+	Host string `yaml:"host,omitempty"`
+
+	// IngressClass of the Ingress, else that of the cluster's default.
+	IngressClass string `yaml:"ingressClass,omitempty"`
+
+	// Gateway to which the HTTPRoute is attached, as "name" or
+	// "namespace/name".  Required of "httproute".
+	Gateway string `yaml:"gateway,omitempty"`
+}
+
+// validateDeployer checks that the deployer is known, and that the features
+// of the deployment are supported by it.
+// Returns array of error messages, empty if no errors are found
+func validateDeployer(d DeploySpec) (errs []string) {
+	switch d.Deployer {
+	case "", DeployerKnative:
+		if d.Expose != nil {
+			errs = append(errs, "deploy.expose applies only to the k8s deployer; Knative exposes functions itself")
+		}
+	case DeployerK8s:
+		if len(d.Traffic) > 0 {
+			errs = append(errs, "deploy.traffic requires revisions, which the k8s deployer does not have")
+		}
+		if d.Strategy == StrategyBlueGreen {
+			errs = append(errs, "deploy.strategy blue-green requires revisions, which the k8s deployer does not have")
+		}
+		if len(d.Subscriptions) > 0 {
+			errs = append(errs, "deploy.subscriptions require Knative Eventing, which the k8s deployer does not use")
+		}
+		if len(d.Domains) > 0 {
+			errs = append(errs, "deploy.domains are mapped by Knative Serving, which the k8s deployer does not use; use deploy.expose.host")
+		}
+		if d.Route != nil {
+			errs = append(errs, "deploy.route is of Knative Serving, which the k8s deployer does not use; use deploy.expose")
+		}
+		if d.Expose != nil {
+			switch d.Expose.Kind {
+			case ExposeIngress:
+				if d.Expose.Gateway != "" {
+					errs = append(errs, "deploy.expose.gateway applies only to an httproute")
+				}
+			case ExposeHTTPRoute:
+				if d.Expose.Gateway == "" {
+					errs = append(errs, "deploy.expose.gateway is required of an httproute")
+				}
+				if d.Expose.IngressClass != "" {
+					errs = append(errs, "deploy.expose.ingressClass applies only to an ingress")
+				}
+			default:
+				errs = append(errs, fmt.Sprintf("deploy.expose.kind %q is unknown. Expected %q or %q", d.Expose.Kind, ExposeIngress, ExposeHTTPRoute))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("deploy.deployer %q is unknown. Expected %q or %q", d.Deployer, DeployerKnative, DeployerK8s))
+	}
+	return
+}
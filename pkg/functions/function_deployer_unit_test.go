@@ -0,0 +1,34 @@
+package functions
+
+import "testing"
+
+func Test_validateDeployer(t *testing.T) {
+	tests := []struct {
+		name   string
+		deploy DeploySpec
+		errs   int
+	}{
+		{"default", DeploySpec{}, 0},
+		{"knative", DeploySpec{Deployer: DeployerKnative, Strategy: StrategyBlueGreen}, 0},
+		{"knative exposed", DeploySpec{Expose: &ExposeSpec{Kind: ExposeIngress}}, 1},
+		{"k8s", DeploySpec{Deployer: DeployerK8s}, 0},
+		{"k8s ingress", DeploySpec{Deployer: DeployerK8s, Expose: &ExposeSpec{Kind: ExposeIngress, IngressClass: "nginx"}}, 0},
+		{"k8s httproute", DeploySpec{Deployer: DeployerK8s, Expose: &ExposeSpec{Kind: ExposeHTTPRoute, Gateway: "infra/public"}}, 0},
+		{"k8s httproute without gateway", DeploySpec{Deployer: DeployerK8s, Expose: &ExposeSpec{Kind: ExposeHTTPRoute}}, 1},
+		{"k8s ingress with gateway", DeploySpec{Deployer: DeployerK8s, Expose: &ExposeSpec{Kind: ExposeIngress, Gateway: "public"}}, 1},
+		{"k8s unknown exposure", DeploySpec{Deployer: DeployerK8s, Expose: &ExposeSpec{Kind: "loadbalancer"}}, 1},
+		{"k8s traffic", DeploySpec{Deployer: DeployerK8s, Traffic: []TrafficSplit{{Revision: "latest", Percent: 100}}}, 1},
+		{"k8s blue-green", DeploySpec{Deployer: DeployerK8s, Strategy: StrategyBlueGreen}, 1},
+		{"k8s subscriptions", DeploySpec{Deployer: DeployerK8s, Subscriptions: []KnativeSubscription{{Source: "default"}}}, 1},
+		{"k8s domains", DeploySpec{Deployer: DeployerK8s, Domains: []string{"api.example.com"}}, 1},
+		{"k8s route", DeploySpec{Deployer: DeployerK8s, Route: &RouteSpec{Visibility: RouteVisibilityClusterLocal}}, 1},
+		{"unknown", DeploySpec{Deployer: "nomad"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateDeployer(tt.deploy); len(errs) != tt.errs {
+				t.Errorf("validateDeployer() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
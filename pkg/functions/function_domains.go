@@ -0,0 +1,26 @@
+package functions
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateDomains checks that each custom domain is a DNS subdomain, being
+// the name of its DomainMapping, and is given once.
+// Returns array of error messages, empty if no errors are found
+func validateDomains(domains []string) (errs []string) {
+	seen := map[string]bool{}
+	for _, d := range domains {
+		if msgs := validation.IsDNS1123Subdomain(d); len(msgs) > 0 {
+			errs = append(errs, fmt.Sprintf("deploy.domains entry %q is not a valid domain: %v", d, strings.Join(msgs, ", ")))
+			continue
+		}
+		if seen[d] {
+			errs = append(errs, fmt.Sprintf("deploy.domains entry %q is given more than once", d))
+		}
+		seen[d] = true
+	}
+	return
+}
@@ -0,0 +1,25 @@
+package functions
+
+import "testing"
+
+func Test_validateDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []string
+		errs    int
+	}{
+		{"none", nil, 0},
+		{"valid", []string{"api.example.com", "www.example.com"}, 0},
+		{"uppercase", []string{"API.example.com"}, 1},
+		{"url", []string{"https://api.example.com"}, 1},
+		{"wildcard", []string{"*.example.com"}, 1},
+		{"duplicate", []string{"api.example.com", "api.example.com"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateDomains(tt.domains); len(errs) != tt.errs {
+				t.Errorf("validateDomains() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
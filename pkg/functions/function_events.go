@@ -0,0 +1,117 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// EventsSpec declares the events a function produces.  It is the contract
+// between the function and its consumers.
+type EventsSpec struct {
+	// Produces is the set of event types the function may emit, each
+	// optionally with a JSON Schema to which the event's data must conform.
+	Produces []EventContract `yaml:"produces,omitempty"`
+
+	// Validate enables checking of emitted events against the declared
+	// contracts, for example on invoke or when captured by 'events record'.
+	Validate bool `yaml:"validate,omitempty"`
+}
+
+// EventContract describes a single produced event type.
+type EventContract struct {
+	// Type of the produced CloudEvent, for example "com.example.order.created"
+	Type string `yaml:"type" jsonschema:"required"`
+
+	// Schema is the path, relative to the function's root, of a JSON Schema
+	// document which the data of events of this type must satisfy.
+	Schema string `yaml:"schema,omitempty"`
+}
+
+// ErrEventContract is returned when an event does not match the contract
+// declared by the function.
+type ErrEventContract struct {
+	Type   string
+	Reason string
+}
+
+func (e ErrEventContract) Error() string {
+	return fmt.Sprintf("event of type %q violates the declared contract: %v", e.Type, e.Reason)
+}
+
+// ValidateEvent checks that the event is one which the function declares it
+// produces, and that its data conforms to the declared schema, if any.
+// Functions which declare no produced events accept any event.
+func (f Function) ValidateEvent(e cloudevents.Event) error {
+	if len(f.Events.Produces) == 0 {
+		return nil
+	}
+	var contract *EventContract
+	for i := range f.Events.Produces {
+		if f.Events.Produces[i].Type == e.Type() {
+			contract = &f.Events.Produces[i]
+			break
+		}
+	}
+	if contract == nil {
+		return ErrEventContract{Type: e.Type(), Reason: "type is not declared in events.produces"}
+	}
+	if contract.Schema == "" {
+		return nil
+	}
+
+	schema, err := os.ReadFile(filepath.Join(f.Root, contract.Schema))
+	if err != nil {
+		return fmt.Errorf("unable to read schema for event type %q: %w", e.Type(), err)
+	}
+	if len(e.Data()) == 0 {
+		return ErrEventContract{Type: e.Type(), Reason: "event has no data"}
+	}
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(e.Data()))
+	if err != nil {
+		return ErrEventContract{Type: e.Type(), Reason: err.Error()}
+	}
+	if !result.Valid() {
+		reasons := make([]string, 0, len(result.Errors()))
+		for _, re := range result.Errors() {
+			reasons = append(reasons, re.String())
+		}
+		return ErrEventContract{Type: e.Type(), Reason: strings.Join(reasons, "; ")}
+	}
+	return nil
+}
+
+// validateEvents checks the declared event contracts are well formed.
+// Returns array of error messages, empty if no errors are found
+func validateEvents(root string, events EventsSpec) (errs []string) {
+	seen := map[string]bool{}
+	for i, c := range events.Produces {
+		if c.Type == "" {
+			errs = append(errs, fmt.Sprintf("produced event entry #%d is missing type field", i))
+			continue
+		}
+		if seen[c.Type] {
+			errs = append(errs, fmt.Sprintf("produced event type %q is declared more than once", c.Type))
+		}
+		seen[c.Type] = true
+		if c.Schema == "" {
+			continue
+		}
+		if filepath.IsAbs(c.Schema) {
+			errs = append(errs, fmt.Sprintf("produced event type %q schema must be relative to the function root", c.Type))
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, c.Schema)); errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Sprintf("produced event type %q schema %q does not exist", c.Type, c.Schema))
+		}
+	}
+	if events.Validate && len(events.Produces) == 0 {
+		errs = append(errs, "events.validate is enabled but no produced events are declared")
+	}
+	return
+}
@@ -0,0 +1,94 @@
+package functions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+const testOrderSchema = `{
+  "type": "object",
+  "properties": { "id": { "type": "string" } },
+  "required": ["id"]
+}`
+
+func Test_ValidateEvent(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "order.json"), []byte(testOrderSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := Function{Root: root, Events: EventsSpec{Produces: []EventContract{
+		{Type: "com.example.order", Schema: "order.json"},
+		{Type: "com.example.ping"},
+	}}}
+
+	newEvent := func(typ string, data any) cloudevents.Event {
+		e := cloudevents.NewEvent()
+		e.SetID("1")
+		e.SetSource("/test")
+		e.SetType(typ)
+		if data != nil {
+			if err := e.SetData(cloudevents.ApplicationJSON, data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		event   cloudevents.Event
+		wantErr bool
+	}{
+		{"conforming data", newEvent("com.example.order", map[string]string{"id": "a"}), false},
+		{"type without schema", newEvent("com.example.ping", nil), false},
+		{"undeclared type", newEvent("com.example.other", nil), true},
+		{"nonconforming data", newEvent("com.example.order", map[string]int{"count": 1}), true},
+		{"missing data", newEvent("com.example.order", nil), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := f.ValidateEvent(tt.event)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+			if err != nil && !errors.As(err, &ErrEventContract{}) {
+				t.Fatalf("expected ErrEventContract, got %T: %v", err, err)
+			}
+		})
+	}
+
+	// Functions which declare nothing accept everything
+	if err := (Function{Root: root}).ValidateEvent(newEvent("any", nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_validateEvents(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "order.json"), []byte(testOrderSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		events EventsSpec
+		errs   int
+	}{
+		{"empty", EventsSpec{}, 0},
+		{"valid", EventsSpec{Validate: true, Produces: []EventContract{{Type: "a", Schema: "order.json"}, {Type: "b"}}}, 0},
+		{"missing type", EventsSpec{Produces: []EventContract{{Schema: "order.json"}}}, 1},
+		{"duplicate type", EventsSpec{Produces: []EventContract{{Type: "a"}, {Type: "a"}}}, 1},
+		{"missing schema", EventsSpec{Produces: []EventContract{{Type: "a", Schema: "missing.json"}}}, 1},
+		{"validate without contracts", EventsSpec{Validate: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateEvents(root, tt.events); len(errs) != tt.errs {
+				t.Fatalf("expected %v errors, got %v: %v", tt.errs, len(errs), errs)
+			}
+		})
+	}
+}
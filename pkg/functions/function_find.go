@@ -0,0 +1,49 @@
+package functions
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// findSkippedDirs are not searched for functions, being those of
+// dependencies rather than source.
+var findSkippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"__pycache__":  true,
+}
+
+// FindFunctions initialized at or beneath root, ordered by path.  Hidden
+// directories and those of dependencies, such as node_modules, are not
+// searched, nor are the directories of the functions found.
+func FindFunctions(root string) (ff []Function, err error) {
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || findSkippedDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, FunctionFile)); err != nil {
+			return nil
+		}
+		f, err := NewFunction(path)
+		if err != nil {
+			return fmt.Errorf("cannot load the function at %v: %w", path, err)
+		}
+		ff = append(ff, f)
+		return filepath.SkipDir
+	})
+	return
+}
@@ -0,0 +1,37 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindFunctions ensures that the functions beneath a directory are found,
+// other than those in hidden directories, those of dependencies, and those
+// within other functions.
+func TestFindFunctions(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b/c", "a/nested", ".hidden/d", "node_modules/e"} {
+		path := filepath.Join(root, dir)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, FunctionFile), []byte("name: "+filepath.Base(dir)+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ff, err := FindFunctions(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ff) != 2 || ff[0].Name != "a" || ff[1].Name != "c" {
+		t.Fatalf("expected functions a and c, got %v", ff)
+	}
+	if ff[1].Root != filepath.Join(root, "b", "c") {
+		t.Errorf("expected the root of the function, got %v", ff[1].Root)
+	}
+}
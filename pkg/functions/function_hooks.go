@@ -0,0 +1,164 @@
+package functions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// HookStage is a stage of the lifecycle of a function about which its hooks
+// are run.
+type HookStage string
+
+const (
+	// HookPreBuild hooks are run before the function is built.
+	HookPreBuild HookStage = "preBuild"
+	// HookPostBuild hooks are run once the function is built.
+	HookPostBuild HookStage = "postBuild"
+	// HookPreDeploy hooks are run before the function is deployed, such as
+	// to migrate a database before traffic is routed to the new revision.
+	HookPreDeploy HookStage = "preDeploy"
+	// HookPostDeploy hooks are run once the function is deployed.
+	HookPostDeploy HookStage = "postDeploy"
+)
+
+// HooksSpec defines the hooks run about each stage of the function's
+// lifecycle, in order.  A hook which fails fails the stage.
+type HooksSpec struct {
+	PreBuild   []Hook `yaml:"preBuild,omitempty"`
+	PostBuild  []Hook `yaml:"postBuild,omitempty"`
+	PreDeploy  []Hook `yaml:"preDeploy,omitempty"`
+	PostDeploy []Hook `yaml:"postDeploy,omitempty"`
+}
+
+// Hook is either a command run locally, in the function's root, or a
+// container image run to completion as a job of the cluster to which the
+// function is deployed.
+type Hook struct {
+	// Name of the hook, used in messages and to name its job.
+	Name string `yaml:"name,omitempty"`
+
+	// Command run by the shell, in the function's root.
+	Command string `yaml:"command,omitempty"`
+
+	// Image of the container run as a job of the cluster.  Alternative to
+	// Command.
+	Image string `yaml:"image,omitempty"`
+
+	// Args of the container, replacing those of its image.
+	Args []string `yaml:"args,omitempty"`
+}
+
+// Of the given stage, the hooks run.
+func (h HooksSpec) Of(stage HookStage) []Hook {
+	switch stage {
+	case HookPreBuild:
+		return h.PreBuild
+	case HookPostBuild:
+		return h.PostBuild
+	case HookPreDeploy:
+		return h.PreDeploy
+	case HookPostDeploy:
+		return h.PostDeploy
+	}
+	return nil
+}
+
+// defined returns whether any hooks are defined, of any stage.
+func (h HooksSpec) defined() bool {
+	return len(h.PreBuild)+len(h.PostBuild)+len(h.PreDeploy)+len(h.PostDeploy) > 0
+}
+
+// String of the hook, being its name if named.
+func (h Hook) String() string {
+	switch {
+	case h.Name != "":
+		return h.Name
+	case h.Command != "":
+		return h.Command
+	}
+	return h.Image
+}
+
+// validateHooks checks each hook is either a command or an image.
+// Returns array of error messages, empty if no errors are found
+func validateHooks(h HooksSpec) (errs []string) {
+	for _, stage := range []HookStage{HookPreBuild, HookPostBuild, HookPreDeploy, HookPostDeploy} {
+		for i, hook := range h.Of(stage) {
+			switch {
+			case hook.Command == "" && hook.Image == "":
+				errs = append(errs, fmt.Sprintf("hooks.%v entry #%d requires either a command or an image", stage, i))
+			case hook.Command != "" && hook.Image != "":
+				errs = append(errs, fmt.Sprintf("hooks.%v entry #%d may specify either a command or an image, not both", stage, i))
+			case hook.Command != "" && len(hook.Args) > 0:
+				errs = append(errs, fmt.Sprintf("hooks.%v entry #%d args apply only to an image", stage, i))
+			}
+		}
+	}
+	return
+}
+
+// HookEnv is the environment of the hooks of the stage, describing the
+// function to them.  The URL is that at which it is deployed, if known.
+func HookEnv(f Function, stage HookStage, url string) []string {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	image := f.Deploy.Image
+	if image == "" {
+		image = f.Build.Image
+	}
+	env := []string{
+		"FUNC_HOOK=" + string(stage),
+		"FUNC_NAME=" + f.Name,
+		"FUNC_NAMESPACE=" + namespace,
+		"FUNC_IMAGE=" + image,
+		"FUNC_RUNTIME=" + f.Runtime,
+	}
+	if url != "" {
+		env = append(env, "FUNC_URL="+url)
+	}
+	return env
+}
+
+// runHooks of the stage, in order, stopping at that which fails.  Commands
+// are run locally and images by the client's hook runner.
+func (c *Client) runHooks(ctx context.Context, f Function, stage HookStage, url string) error {
+	env := HookEnv(f, stage, url)
+	for _, h := range f.Hooks.Of(stage) {
+		fmt.Fprintf(os.Stderr, "🪝 Running %v hook %v\n", stage, h)
+		var err error
+		if h.Command != "" {
+			err = runHookCommand(ctx, f.Root, h.Command, env)
+		} else {
+			err = c.hookRunner.Run(ctx, f, h, env)
+		}
+		if err != nil {
+			return fmt.Errorf("%v hook %v failed. %w", stage, h, err)
+		}
+	}
+	return nil
+}
+
+// runHookCommand in the directory using the platform's shell, its
+// environment that of the process with env added.
+func runHookCommand(ctx context.Context, dir, command string, env []string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%q: %w", strings.TrimSpace(command), err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package functions
+
+import "testing"
+
+func Test_validateHooks(t *testing.T) {
+	tests := []struct {
+		name  string
+		hooks HooksSpec
+		errs  int
+	}{
+		{
+			name:  "command and image hooks",
+			hooks: HooksSpec{PreBuild: []Hook{{Command: "make generate"}}, PreDeploy: []Hook{{Image: "example.com/migrate", Args: []string{"up"}}}},
+			errs:  0,
+		},
+		{
+			name:  "neither command nor image",
+			hooks: HooksSpec{PostDeploy: []Hook{{Name: "empty"}}},
+			errs:  1,
+		},
+		{
+			name:  "both command and image",
+			hooks: HooksSpec{PostBuild: []Hook{{Command: "true", Image: "example.com/scan"}}},
+			errs:  1,
+		},
+		{
+			name:  "args of a command",
+			hooks: HooksSpec{PreDeploy: []Hook{{Command: "migrate", Args: []string{"up"}}}},
+			errs:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateHooks(tt.hooks); len(errs) != tt.errs {
+				t.Errorf("validateHooks() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package functions
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// IgnoreFile is the name of the file in a function's root which lists, using
+// .gitignore syntax, files to exclude from the function's build context.
+const IgnoreFile = ".funcignore"
+
+// defaultIgnored are excluded from every build context.  Being the first
+// patterns considered, they may be negated by those of the function.
+var defaultIgnored = []string{".git", "/" + RunDataDir, IgnoreFile, ".gitignore"}
+
+// Ignorer determines which files of a function are excluded from its build
+// context: the source which is sent to a builder, be it local or on-cluster.
+//
+// Patterns follow .gitignore semantics, including negation (!) to re-include
+// files excluded by an earlier pattern.  They are, in order: the defaults,
+// those of the function's .gitignore, those of its .funcignore, and any
+// provided by the builder.  As with git, the last matching pattern wins, and
+// a file cannot be re-included if a parent directory is excluded.
+type Ignorer struct {
+	root    string
+	matcher gitignore.Matcher
+}
+
+// NewIgnorer for the function rooted at root, with optional patterns which
+// take precedence over those of the function.
+func NewIgnorer(root string, patterns ...string) (Ignorer, error) {
+	var ps []gitignore.Pattern
+	for _, p := range defaultIgnored {
+		ps = append(ps, gitignore.ParsePattern(p, nil))
+	}
+	for _, name := range []string{".gitignore", IgnoreFile} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return Ignorer{}, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, " \r")
+			if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+				continue
+			}
+			ps = append(ps, gitignore.ParsePattern(line, nil))
+		}
+	}
+	for _, p := range patterns {
+		ps = append(ps, gitignore.ParsePattern(p, nil))
+	}
+	return Ignorer{root: root, matcher: gitignore.NewMatcher(ps)}, nil
+}
+
+// Ignored returns true if the given path, relative to the function's root, is
+// excluded from the build context, either directly or by way of one of its
+// parent directories.
+func (i Ignorer) Ignored(path string, isDir bool) bool {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(path)), "/")
+	if len(parts) == 1 && parts[0] == "." {
+		return false
+	}
+	for n := 1; n < len(parts); n++ {
+		if i.matcher.Match(parts[:n], true) {
+			return true
+		}
+	}
+	return i.matcher.Match(parts, isDir)
+}
+
+// Walk the function's build context, calling fn for the root and for each
+// file and directory which is not ignored.  Ignored directories are not
+// descended into.
+func (i Ignorer) Walk(fn filepath.WalkFunc) error {
+	return filepath.Walk(i.root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil && path == i.root && errors.Is(err, fs.ErrNotExist) {
+			return nil // a function not yet written to disk has no context
+		} else if err != nil {
+			return fn(path, info, err)
+		}
+		rel, err := filepath.Rel(i.root, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && i.matcher.Match(strings.Split(filepath.ToSlash(rel), "/"), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path, info, nil)
+	})
+}
+
+// Excluded returns the paths, relative to the function's root, of the files
+// and directories excluded from its build context.  Directories are listed
+// without their contents, which are also excluded.
+func (i Ignorer) Excluded() (paths []string, err error) {
+	err = filepath.Walk(i.root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil && path == i.root && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(i.root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if i.matcher.Match(strings.Split(filepath.ToSlash(rel), "/"), info.IsDir()) {
+			paths = append(paths, filepath.ToSlash(rel))
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// Context returns the paths, relative to the function's root, of the files
+// (excluding directories) which comprise the function's build context.
+func (i Ignorer) Context() (paths []string, err error) {
+	err = i.Walk(func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(i.root, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	return
+}
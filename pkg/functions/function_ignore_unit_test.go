@@ -0,0 +1,71 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestIgnorer ensures that the build context of a function honors the
+// .gitignore semantics of its .gitignore and .funcignore, including
+// negation, and that the defaults are always excluded.
+func TestIgnorer(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "build/\n*.log\n",
+		".funcignore":         "# comment\n\n!important.log\nsecrets/\n/docs\nfixtures/*\n!fixtures/keep.txt\nbuild/keep.txt\n!build/keep.txt\n",
+		".func/built-image":   "",
+		".git/HEAD":           "",
+		"main.go":             "",
+		"debug.log":           "",
+		"important.log":       "",
+		"build/keep.txt":      "",
+		"secrets/key":         "",
+		"docs/README.md":      "",
+		"pkg/docs/README.md":  "",
+		"fixtures/drop.txt":   "",
+		"fixtures/keep.txt":   "",
+		"pkg/secrets/key.txt": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	i, err := NewIgnorer(root, "pkg/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	context, err := i.Context()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"fixtures/keep.txt", "important.log", "main.go"}
+	if !reflect.DeepEqual(context, expected) {
+		t.Fatalf("expected context %v, got %v", expected, context)
+	}
+
+	excluded, err := i.Excluded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []string{".func", ".funcignore", ".git", ".gitignore", "build", "debug.log", "docs", "fixtures/drop.txt", "pkg/docs", "pkg/secrets", "secrets"}
+	if !reflect.DeepEqual(excluded, expected) {
+		t.Fatalf("expected excluded %v, got %v", expected, excluded)
+	}
+
+	// A file within an excluded directory can not be re-included
+	if !i.Ignored("build/keep.txt", false) {
+		t.Error("expected build/keep.txt to be ignored by way of its directory")
+	}
+	if i.Ignored("pkg", true) || i.Ignored(".", true) {
+		t.Error("expected pkg and the root to be included")
+	}
+}
@@ -8,15 +8,43 @@ import (
 	"knative.dev/func/pkg/utils"
 )
 
+const (
+	// ScopeBoth labels (and annotations) both the service of the function and
+	// the template of its revisions.  It is the scope of those given none.
+	ScopeBoth = "both"
+	// ScopeService labels only the service, such as for its cost center.
+	ScopeService = "service"
+	// ScopeRevision labels only the template of the function's revisions, such
+	// as for the scaling of their pods.
+	ScopeRevision = "revision"
+)
+
 type Label struct {
 	// Key consist of optional prefix part (ended by '/') and name part
 	// Prefix part validation pattern: [a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*
 	// Name part validation pattern: ([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]
 	Key   *string `yaml:"key" jsonschema:"pattern=^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*\\/)?([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$"`
 	Value *string `yaml:"value,omitempty" jsonschema:"pattern=^(([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9])?$"`
+	// Scope of the label: the service, the template of its revisions, or
+	// both (the default).
+	Scope string `yaml:"scope,omitempty" jsonschema:"enum=both,enum=service,enum=revision"`
+}
+
+// inScope returns true if the label applies to the given scope, such that
+// labels of both scopes apply to either.
+func (l Label) inScope(scope string) bool {
+	return l.Scope == "" || l.Scope == ScopeBoth || scope == ScopeBoth || l.Scope == scope
 }
 
 func (l Label) String() string {
+	s := l.string()
+	if s != "" && l.Scope != "" && l.Scope != ScopeBoth {
+		s += fmt.Sprintf(" (%s only)", l.Scope)
+	}
+	return s
+}
+
+func (l Label) string() string {
 	if l.Key != nil && l.Value == nil {
 		return fmt.Sprintf("Label with key \"%s\"", *l.Key)
 	} else if l.Key != nil && l.Value != nil {
@@ -44,6 +72,9 @@ func ValidateLabels(labels []Label) (errors []string) {
 		} else if label.Key == nil && label.Value != nil {
 			errors = append(errors, fmt.Sprintf("label entry #%d is missing key field, only value '%s' is set", i, *label.Value))
 		} else {
+			if !validScope(label.Scope) {
+				errors = append(errors, fmt.Sprintf("label entry #%d has invalid scope set: %q; allowed are '%s', '%s' or '%s'", i, label.Scope, ScopeBoth, ScopeService, ScopeRevision))
+			}
 			if err := utils.ValidateLabelKey(*label.Key); err != nil {
 				errors = append(errors, fmt.Sprintf("label entry #%d has invalid key set: %q; %s", i, *label.Key, err.Error()))
 			}
@@ -73,3 +104,7 @@ func ValidateLabels(labels []Label) (errors []string) {
 
 	return
 }
+
+func validScope(scope string) bool {
+	return scope == "" || scope == ScopeBoth || scope == ScopeService || scope == ScopeRevision
+}
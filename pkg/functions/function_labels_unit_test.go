@@ -54,6 +54,31 @@ func Test_validateLabels(t *testing.T) {
 				},
 			},
 			0,
+		}, {
+			"correct entry - scoped labels",
+			[]Label{
+				{
+					Key:   &key,
+					Value: &value,
+					Scope: ScopeService,
+				},
+				{
+					Key:   &key2,
+					Value: &value2,
+					Scope: ScopeRevision,
+				},
+			},
+			0,
+		}, {
+			"incorrect entry - invalid scope",
+			[]Label{
+				{
+					Key:   &key,
+					Value: &value,
+					Scope: "pod",
+				},
+			},
+			1,
 		}, {
 			"incorrect entry - missing key",
 			[]Label{
@@ -2,19 +2,35 @@ package functions
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type Options struct {
 	Scale     *ScaleOptions     `yaml:"scale,omitempty"`
 	Resources *ResourcesOptions `yaml:"resources,omitempty"`
+	// RevisionHistoryLimit is the number of old revisions retained, those
+	// beyond it being deleted on deploy.  Revisions routed traffic are not
+	// old.  All are retained if not set.
+	RevisionHistoryLimit *int64 `yaml:"revisionHistoryLimit,omitempty" jsonschema_extras:"minimum=0"`
 }
 
+// Autoscaler classes of ScaleOptions.Class.  The Knative Pod Autoscaler (kpa)
+// scales by concurrency or requests per second, and to zero.  The Kubernetes
+// Horizontal Pod Autoscaler (hpa) scales by CPU or memory, but never to zero.
+const (
+	ScaleClassKPA = "kpa"
+	ScaleClassHPA = "hpa"
+)
+
 type ScaleOptions struct {
 	Min         *int64   `yaml:"min,omitempty" jsonschema_extras:"minimum=0"`
 	Max         *int64   `yaml:"max,omitempty" jsonschema_extras:"minimum=0"`
-	Metric      *string  `yaml:"metric,omitempty" jsonschema:"enum=concurrency,enum=rps"`
+	Class       *string  `yaml:"class,omitempty" jsonschema:"enum=kpa,enum=hpa"`
+	Metric      *string  `yaml:"metric,omitempty" jsonschema:"enum=concurrency,enum=rps,enum=cpu,enum=memory"`
 	Target      *float64 `yaml:"target,omitempty" jsonschema_extras:"minimum=0.01"`
 	Utilization *float64 `yaml:"utilization,omitempty" jsonschema:"minimum=1,maximum=100"`
 }
@@ -22,6 +38,10 @@ type ScaleOptions struct {
 type ResourcesOptions struct {
 	Requests *ResourcesRequestsOptions `yaml:"requests,omitempty"`
 	Limits   *ResourcesLimitsOptions   `yaml:"limits,omitempty"`
+	// Extended resources of each instance by name, such as "nvidia.com/gpu",
+	// each both requested and limited, as extended resources can not be
+	// overcommitted.
+	Extended map[string]int64 `yaml:"extended,omitempty"`
 }
 
 type ResourcesLimitsOptions struct {
@@ -55,19 +75,44 @@ func validateOptions(options Options) (errors []string) {
 			}
 		}
 
+		// A max of 0 is unbounded.
 		if options.Scale.Min != nil && options.Scale.Max != nil {
-			if *options.Scale.Max < *options.Scale.Min {
+			if *options.Scale.Max > 0 && *options.Scale.Max < *options.Scale.Min {
 				errors = append(errors, "options field \"scale.max\" value must be greater or equal to \"scale.min\"")
 			}
 		}
 
+		hpa := options.Scale.Class != nil && *options.Scale.Class == ScaleClassHPA
+		if options.Scale.Class != nil && !hpa && *options.Scale.Class != ScaleClassKPA {
+			errors = append(errors, fmt.Sprintf("options field \"scale.class\" has invalid value set: %s, allowed is only \"kpa\" or \"hpa\"",
+				*options.Scale.Class))
+		}
+
 		if options.Scale.Metric != nil {
-			if *options.Scale.Metric != "concurrency" && *options.Scale.Metric != "rps" {
-				errors = append(errors, fmt.Sprintf("options field \"scale.metric\" has invalid value set: %s, allowed is only \"concurrency\" or \"rps\"",
+			if hpa && *options.Scale.Metric != "cpu" && *options.Scale.Metric != "memory" {
+				errors = append(errors, fmt.Sprintf("options field \"scale.metric\" has invalid value set: %s, allowed with the \"hpa\" class is only \"cpu\" or \"memory\"",
+					*options.Scale.Metric))
+			} else if !hpa && *options.Scale.Metric != "concurrency" && *options.Scale.Metric != "rps" {
+				errors = append(errors, fmt.Sprintf("options field \"scale.metric\" has invalid value set: %s, allowed is only \"concurrency\" or \"rps\", or \"cpu\" or \"memory\" with the \"hpa\" class",
 					*options.Scale.Metric))
 			}
 		}
 
+		if hpa {
+			if options.Scale.Min != nil && *options.Scale.Min < 1 {
+				errors = append(errors, "options field \"scale.min\" must be at least 1 with the \"hpa\" class, which does not scale to zero")
+			}
+			if options.Scale.Utilization != nil {
+				errors = append(errors, "options field \"scale.utilization\" applies only to the \"kpa\" class")
+			}
+			// CPU utilization is relative to that requested, by default
+			if options.Scale.Metric == nil || *options.Scale.Metric == "cpu" {
+				if options.Resources == nil || options.Resources.Requests == nil || options.Resources.Requests.CPU == nil {
+					errors = append(errors, "options field \"resources.requests.cpu\" is required to scale by cpu with the \"hpa\" class")
+				}
+			}
+		}
+
 		if options.Scale.Target != nil {
 			if *options.Scale.Target < 0.01 {
 				errors = append(errors, fmt.Sprintf("options field \"scale.target\" has value set to \"%f\", but it must not be less than 0.01",
@@ -84,6 +129,12 @@ func validateOptions(options Options) (errors []string) {
 		}
 	}
 
+	// options.revisionHistoryLimit
+	if options.RevisionHistoryLimit != nil && *options.RevisionHistoryLimit < 0 {
+		errors = append(errors, fmt.Sprintf("options field \"revisionHistoryLimit\" has invalid value set: %d, the value must not be less than \"0\"",
+			*options.RevisionHistoryLimit))
+	}
+
 	// options.resource
 	if options.Resources != nil {
 
@@ -133,7 +184,43 @@ func validateOptions(options Options) (errors []string) {
 				}
 			}
 		}
+
+		// options.resource.extended
+		for _, name := range sortedKeys(options.Resources.Extended) {
+			if err := validateExtendedResourceName(name); err != "" {
+				errors = append(errors, fmt.Sprintf("options field \"resources.extended\" has invalid resource name set: \"%s\"; %s", name, err))
+			}
+			if n := options.Resources.Extended[name]; n < 1 {
+				errors = append(errors, fmt.Sprintf("options field \"resources.extended\" has value of \"%s\" set to \"%d\", but it must not be less than 1", name, n))
+			}
+		}
 	}
 
 	return
 }
+
+// validateExtendedResourceName returns why the name is not that of an
+// extended resource, or "" if it is: a qualified name of a domain other than
+// that of Kubernetes, by which its standard resources are named.
+func validateExtendedResourceName(name string) string {
+	domain, _, ok := strings.Cut(name, "/")
+	if !ok {
+		return "it must be prefixed by a domain, such as \"nvidia.com/gpu\""
+	}
+	if domain == "kubernetes.io" || strings.HasSuffix(domain, ".kubernetes.io") {
+		return "the domain \"kubernetes.io\" is of standard resources"
+	}
+	if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+		return strings.Join(errs, "; ")
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
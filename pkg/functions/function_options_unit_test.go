@@ -40,6 +40,72 @@ func Test_validateOptions(t *testing.T) {
 			},
 			1,
 		},
+		{
+			"correct 'scale.class' - hpa by cpu",
+			Options{
+				Scale: &ScaleOptions{
+					Class:  ptr.String("hpa"),
+					Metric: ptr.String("cpu"),
+					Min:    ptr.Int64(1),
+				},
+				Resources: &ResourcesOptions{
+					Requests: &ResourcesRequestsOptions{
+						CPU: ptr.String("100m"),
+					},
+				},
+			},
+			0,
+		},
+		{
+			"correct 'scale.class' - hpa by memory",
+			Options{
+				Scale: &ScaleOptions{
+					Class:  ptr.String("hpa"),
+					Metric: ptr.String("memory"),
+					Target: ptr.Float64(256),
+				},
+			},
+			0,
+		},
+		{
+			"incorrect 'scale.class'",
+			Options{
+				Scale: &ScaleOptions{
+					Class: ptr.String("foo"),
+				},
+			},
+			1,
+		},
+		{
+			"incorrect 'scale.metric' - cpu without hpa",
+			Options{
+				Scale: &ScaleOptions{
+					Metric: ptr.String("cpu"),
+				},
+			},
+			1,
+		},
+		{
+			"incorrect 'scale.metric' - concurrency with hpa",
+			Options{
+				Scale: &ScaleOptions{
+					Class:  ptr.String("hpa"),
+					Metric: ptr.String("concurrency"),
+				},
+			},
+			1,
+		},
+		{
+			"incorrect 'scale.class' - hpa by cpu without request, to zero, with utilization",
+			Options{
+				Scale: &ScaleOptions{
+					Class:       ptr.String("hpa"),
+					Min:         ptr.Int64(0),
+					Utilization: ptr.Float64(70),
+				},
+			},
+			3,
+		},
 		{
 			"correct 'scale.min'",
 			Options{
@@ -78,6 +144,16 @@ func Test_validateOptions(t *testing.T) {
 			},
 			1,
 		},
+		{
+			"correct 'scale.min' & unbounded 'scale.max'",
+			Options{
+				Scale: &ScaleOptions{
+					Min: ptr.Int64(1),
+					Max: ptr.Int64(0),
+				},
+			},
+			0,
+		},
 		{
 			"incorrect 'scale.min' - negative value",
 			Options{
@@ -310,6 +386,43 @@ func Test_validateOptions(t *testing.T) {
 			},
 			10,
 		},
+		{
+			"correct 'resources.extended'",
+			Options{
+				Resources: &ResourcesOptions{
+					Extended: map[string]int64{"nvidia.com/gpu": 1, "example.com/fpga": 2},
+				},
+			},
+			0,
+		},
+		{
+			"incorrect 'resources.extended' - names and values",
+			Options{
+				Resources: &ResourcesOptions{
+					Extended: map[string]int64{
+						"gpu":               1,
+						"kubernetes.io/gpu": 1,
+						"nvidia.com/gpu!":   1,
+						"amd.com/gpu":       0,
+					},
+				},
+			},
+			4,
+		},
+		{
+			"correct 'revisionHistoryLimit'",
+			Options{
+				RevisionHistoryLimit: ptr.Int64(0),
+			},
+			0,
+		},
+		{
+			"incorrect 'revisionHistoryLimit'",
+			Options{
+				RevisionHistoryLimit: ptr.Int64(-1),
+			},
+			1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,102 @@
+package functions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BuiltPlatforms is the name of the file in the runtime metadata directory
+// (RunDataDir) listing the platforms of the last build, if for more than one.
+const BuiltPlatforms = "built-platforms"
+
+// ParsePlatform of the form OS/ARCHITECTURE[/VARIANT], for example
+// "linux/amd64" or "linux/arm/v7".
+func ParsePlatform(s string) (p Platform, err error) {
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return p, fmt.Errorf("platform %q must be in the form OS/ARCHITECTURE[/VARIANT], for example \"linux/amd64\"", s)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return p, fmt.Errorf("platform %q must be in the form OS/ARCHITECTURE[/VARIANT], for example \"linux/amd64\"", s)
+		}
+	}
+	p = Platform{OS: strings.ToLower(parts[0]), Architecture: strings.ToLower(parts[1])}
+	if len(parts) == 3 {
+		p.Variant = strings.ToLower(parts[2])
+	}
+	return
+}
+
+// ParsePlatforms of a comma-separated list, for example
+// "linux/amd64,linux/arm64".
+func ParsePlatforms(s string) (pp []Platform, err error) {
+	for _, v := range strings.Split(s, ",") {
+		p, err := ParsePlatform(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		pp = append(pp, p)
+	}
+	return
+}
+
+// String of the platform in the form OS/ARCHITECTURE[/VARIANT].
+func (p Platform) String() string {
+	if p.Variant == "" {
+		return p.OS + "/" + p.Architecture
+	}
+	return p.OS + "/" + p.Architecture + "/" + p.Variant
+}
+
+// PlatformImage is the image of the platform when a function is built for
+// several by a builder which produces an image of one platform at a time:
+// that of the function with its tag suffixed by the platform, for example
+// "example.com/alice/f:latest-linux-arm64".  The images of each platform are
+// assembled into the image index of the function when it is pushed.
+func PlatformImage(image string, p Platform) string {
+	image, _, _ = strings.Cut(image, "@")
+	suffix := strings.ReplaceAll(p.String(), "/", "-")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image + "-" + suffix
+	}
+	return image + ":latest-" + suffix
+}
+
+// writeBuiltPlatforms into the runtime metadata directory (.func/) from
+// f.Build.Platforms, removing those of a previous build if built for one.
+func (f Function) writeBuiltPlatforms() error {
+	path := filepath.Join(f.Root, RunDataDir, BuiltPlatforms)
+	if len(f.Build.Platforms) < 2 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	var sb strings.Builder
+	for _, p := range f.Build.Platforms {
+		sb.WriteString(p.String() + "\n")
+	}
+	return os.WriteFile(path, []byte(sb.String()), os.ModePerm)
+}
+
+// getBuiltPlatforms reads .func/built-platforms, returning none if the
+// function was not built for several.
+func (f Function) getBuiltPlatforms() (pp []Platform, err error) {
+	b, err := os.ReadFile(filepath.Join(f.Root, RunDataDir, BuiltPlatforms))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return
+	}
+	for _, line := range strings.Fields(string(b)) {
+		p, err := ParsePlatform(line)
+		if err != nil {
+			return nil, err
+		}
+		pp = append(pp, p)
+	}
+	return
+}
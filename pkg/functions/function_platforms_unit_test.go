@@ -0,0 +1,81 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []Platform
+		wantErr bool
+	}{
+		{"linux/amd64", []Platform{{OS: "linux", Architecture: "amd64"}}, false},
+		{"linux/amd64, linux/arm/v7", []Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm", Variant: "v7"}}, false},
+		{"Linux/ARM64", []Platform{{OS: "linux", Architecture: "arm64"}}, false},
+		{"linux", nil, true},
+		{"linux/", nil, true},
+		{"linux/amd64,", nil, true},
+		{"linux/arm/v7/x", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePlatforms(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlatforms() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParsePlatforms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlatformImage(t *testing.T) {
+	arm := Platform{OS: "linux", Architecture: "arm", Variant: "v7"}
+	tests := []struct {
+		image, want string
+	}{
+		{"example.com/alice/f:latest", "example.com/alice/f:latest-linux-arm-v7"},
+		{"example.com/alice/f", "example.com/alice/f:latest-linux-arm-v7"},
+		{"localhost:5000/f", "localhost:5000/f:latest-linux-arm-v7"},
+		{"example.com/alice/f:v1@sha256:0123", "example.com/alice/f:v1-linux-arm-v7"},
+	}
+	for _, tt := range tests {
+		if got := PlatformImage(tt.image, arm); got != tt.want {
+			t.Errorf("PlatformImage(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+// TestFunction_BuiltPlatforms ensures that the platforms for which a function
+// was built are loaded with it, and no longer once built for a single one.
+func TestFunction_BuiltPlatforms(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, RunDataDir), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	f := Function{Root: root, Build: BuildSpec{Image: "example.com/alice/f:latest"}}
+	f.Build.Platforms = []Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}
+	if err := f.WriteRuntimeBuiltImage(false); err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.getBuiltPlatforms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, f.Build.Platforms) {
+		t.Fatalf("expected platforms %v, got %v", f.Build.Platforms, got)
+	}
+
+	f.Build.Platforms = nil
+	if err := f.WriteRuntimeBuiltImage(false); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = f.getBuiltPlatforms(); err != nil || got != nil {
+		t.Fatalf("expected no platforms, got %v (%v)", got, err)
+	}
+}
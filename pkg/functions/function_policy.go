@@ -0,0 +1,47 @@
+package functions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkPolicy of the resources which deploying the function would create or
+// update, as rendered by the client's deployer, such that the policies are
+// enforced alike whichever deployer or pipeline deploys it.
+func (c *Client) checkPolicy(ctx context.Context, f Function) error {
+	if _, ok := c.policy.(*noopPolicyEvaluator); ok {
+		return nil
+	}
+	manifests, err := c.deployer.Render(ctx, f, DryRunClient)
+	if err != nil {
+		return fmt.Errorf("cannot render the function to evaluate deployment policies. %w", err)
+	}
+	return c.evaluatePolicy(ctx, manifests)
+}
+
+// evaluatePolicy of each manifest of the YAML stream.
+func (c *Client) evaluatePolicy(ctx context.Context, manifests []byte) error {
+	var mm []map[string]any
+	dec := yaml.NewDecoder(bytes.NewReader(manifests))
+	for {
+		var m map[string]any
+		if err := dec.Decode(&m); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("cannot decode the manifests rendered of the function. %w", err)
+		}
+		if m != nil {
+			mm = append(mm, m)
+		}
+	}
+	if c.verbose {
+		fmt.Fprintf(os.Stderr, "📜 Evaluating deployment policies\n")
+	}
+	return c.policy.Evaluate(ctx, mm...)
+}
@@ -0,0 +1,66 @@
+package functions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ProfileFile of the named profile, an overlay of func.yaml beside it.  For
+// example the profile "prod" is func.prod.yaml.
+func ProfileFile(name string) string {
+	return strings.TrimSuffix(FunctionFile, ".yaml") + "." + name + ".yaml"
+}
+
+// HasProfile returns true if the function has an overlay file for the named
+// profile.
+func (f Function) HasProfile(name string) bool {
+	if !validProfileName(name) {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(f.Root, ProfileFile(name)))
+	return err == nil
+}
+
+// WithProfile returns the function with the named profile applied, such as
+// to configure it differently for each environment to which it is deployed.
+// Values set in the profile's overlay file replace those of func.yaml: maps,
+// such as of annotations, are merged, and lists, such as of envs, replaced
+// as a whole.  The function itself is not modified.
+func (f Function) WithProfile(name string) (Function, error) {
+	if !validProfileName(name) {
+		return f, fmt.Errorf("invalid profile name %q", name)
+	}
+	bb, err := os.ReadFile(filepath.Join(f.Root, ProfileFile(name)))
+	if os.IsNotExist(err) {
+		return f, fmt.Errorf("%w: %v has no %v", ErrProfileNotFound, f.Root, ProfileFile(name))
+	} else if err != nil {
+		return f, err
+	}
+
+	// Applied to a copy of the function by way of its yaml, such that its
+	// maps and pointers are not shared with f.
+	base, err := yaml.Marshal(f)
+	if err != nil {
+		return f, err
+	}
+	var p Function
+	if err = yaml.Unmarshal(base, &p); err != nil {
+		return f, err
+	}
+	if err = yaml.Unmarshal(bb, &p); err != nil {
+		return f, fmt.Errorf("%v: %w", ProfileFile(name), formatUnmarshalError(err))
+	}
+	p.Root, p.Template, p.Local, p.Build.Image = f.Root, f.Template, f.Local, f.Build.Image
+	if err = p.Validate(); err != nil {
+		return f, fmt.Errorf("profile %v: %w", name, err)
+	}
+	return p, nil
+}
+
+func validProfileName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `/\`) && name != "." && name != ".."
+}
@@ -0,0 +1,74 @@
+package functions
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"knative.dev/pkg/ptr"
+)
+
+// TestFunction_WithProfile ensures that a profile's overlay replaces the
+// values it sets, merging maps and replacing lists, without modifying the
+// function.
+func TestFunction_WithProfile(t *testing.T) {
+	root := t.TempDir()
+	f := Function{
+		Root:    root,
+		Name:    "myfunc",
+		Runtime: "go",
+		Run:     RunSpec{Envs: []Env{{Name: ptr.String("A"), Value: ptr.String("1")}}},
+		Deploy: DeploySpec{
+			Annotations: map[string]string{"a": "1", "b": "2"},
+			Options:     Options{Scale: &ScaleOptions{Min: ptr.Int64(1)}},
+		},
+	}
+	overlay := `
+run:
+  envs:
+  - name: B
+    value: "2"
+deploy:
+  annotations:
+    b: prod
+  options:
+    scale:
+      min: 3
+`
+	if err := os.WriteFile(filepath.Join(root, "func.prod.yaml"), []byte(overlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !f.HasProfile("prod") || f.HasProfile("staging") {
+		t.Fatal("expected only the prod profile")
+	}
+
+	p, err := f.WithProfile("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Root != root || p.Name != "myfunc" {
+		t.Errorf("expected the function's values retained, got %q in %q", p.Name, p.Root)
+	}
+	if len(p.Run.Envs) != 1 || *p.Run.Envs[0].Name != "B" {
+		t.Errorf("expected the envs replaced, got %v", p.Run.Envs)
+	}
+	if p.Deploy.Annotations["a"] != "1" || p.Deploy.Annotations["b"] != "prod" {
+		t.Errorf("expected the annotations merged, got %v", p.Deploy.Annotations)
+	}
+	if *p.Deploy.Options.Scale.Min != 3 {
+		t.Errorf("expected min scale 3, got %v", *p.Deploy.Options.Scale.Min)
+	}
+
+	// The function itself is unchanged
+	if f.Deploy.Annotations["b"] != "2" || *f.Deploy.Options.Scale.Min != 1 || *f.Run.Envs[0].Name != "A" {
+		t.Errorf("expected the function unmodified, got %+v", f)
+	}
+
+	if _, err = f.WithProfile("staging"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound, got %v", err)
+	}
+	if _, err = f.WithProfile("../prod"); err == nil {
+		t.Error("expected an invalid profile name to error")
+	}
+}
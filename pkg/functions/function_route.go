@@ -0,0 +1,56 @@
+package functions
+
+import (
+	"fmt"
+
+	"knative.dev/func/pkg/utils"
+)
+
+const (
+	// RouteVisibilityPublic routes to the function from outside of the
+	// cluster; on OpenShift Serverless, by a Route.  The default.
+	RouteVisibilityPublic = "public"
+	// RouteVisibilityClusterLocal routes to the function only from within the
+	// cluster.
+	RouteVisibilityClusterLocal = "cluster-local"
+	// RouteVisibilityNone routes to the function from the cluster's ingress
+	// without OpenShift Serverless creating a Route, such that one may be
+	// managed separately.
+	RouteVisibilityNone = "none"
+)
+
+// RouteSpec of the route to a function deployed by the Knative deployer, in
+// particular to OpenShift Serverless, which creates an OpenShift Route for
+// each public function.
+type RouteSpec struct {
+	// Visibility of the function: "public" (the default), "cluster-local"
+	// or "none".
+	Visibility string `yaml:"visibility,omitempty" jsonschema:"enum=public,enum=cluster-local,enum=none"`
+
+	// Annotations of the function's OpenShift Route, such as
+	// haproxy.router.openshift.io/timeout.  Ignored by other clusters.
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// validateRoute checks that the visibility is known and the annotations are
+// of valid keys.
+// Returns array of error messages, empty if no errors are found
+func validateRoute(r *RouteSpec) (errs []string) {
+	if r == nil {
+		return
+	}
+	switch r.Visibility {
+	case "", RouteVisibilityPublic, RouteVisibilityClusterLocal, RouteVisibilityNone:
+	default:
+		errs = append(errs, fmt.Sprintf("route.visibility %q is unknown. Expected %q, %q or %q", r.Visibility, RouteVisibilityPublic, RouteVisibilityClusterLocal, RouteVisibilityNone))
+	}
+	for _, k := range sortedKeys(r.Annotations) {
+		if err := utils.ValidateLabelKey(k); err != nil {
+			errs = append(errs, fmt.Sprintf("route.annotations has invalid key %q: %v", k, err))
+		}
+	}
+	if len(r.Annotations) > 0 && (r.Visibility == RouteVisibilityClusterLocal || r.Visibility == RouteVisibilityNone) {
+		errs = append(errs, fmt.Sprintf("route.annotations are of the Route, which a function of visibility %q does not have", r.Visibility))
+	}
+	return
+}
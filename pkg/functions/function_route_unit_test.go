@@ -0,0 +1,27 @@
+package functions
+
+import "testing"
+
+func Test_validateRoute(t *testing.T) {
+	tests := []struct {
+		name  string
+		route *RouteSpec
+		errs  int
+	}{
+		{"correct - no route", nil, 0},
+		{"correct - public with annotations", &RouteSpec{Visibility: RouteVisibilityPublic, Annotations: map[string]string{"haproxy.router.openshift.io/timeout": "600s"}}, 0},
+		{"correct - annotations of the default visibility", &RouteSpec{Annotations: map[string]string{"haproxy.router.openshift.io/balance": "roundrobin"}}, 0},
+		{"correct - cluster-local", &RouteSpec{Visibility: RouteVisibilityClusterLocal}, 0},
+		{"correct - none", &RouteSpec{Visibility: RouteVisibilityNone}, 0},
+		{"incorrect - unknown visibility", &RouteSpec{Visibility: "private"}, 1},
+		{"incorrect - annotation key", &RouteSpec{Annotations: map[string]string{"bad key": "v"}}, 1},
+		{"incorrect - annotations without a route", &RouteSpec{Visibility: RouteVisibilityNone, Annotations: map[string]string{"a": "b"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateRoute(tt.route); len(errs) != tt.errs {
+				t.Errorf("validateRoute() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package functions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// SBOMFormatCycloneDX is the format of CycloneDX JSON documents.
+	SBOMFormatCycloneDX = "cyclonedx"
+	// SBOMFormatSPDX is the format of SPDX JSON documents.
+	SBOMFormatSPDX = "spdx"
+
+	// BuiltSBOM is the name of the file in the runtime metadata directory
+	// (RunDataDir) holding the SBOM generated by the last build.
+	BuiltSBOM = "sbom.json"
+)
+
+// SBOMMediaType of the documents of the SBOM format, being also the type of
+// the artifact attached to the function's image.
+func SBOMMediaType(format string) string {
+	if format == SBOMFormatSPDX {
+		return "application/spdx+json"
+	}
+	return "application/vnd.cyclonedx+json"
+}
+
+// SBOMPath of the SBOM generated by the function's last build.
+func (f Function) SBOMPath() string {
+	return filepath.Join(f.Root, RunDataDir, BuiltSBOM)
+}
+
+// sbomArtifact attached to the function's image in addition to those it
+// declares, if an SBOM was generated when it was built.
+func (f Function) sbomArtifact() (ArtifactSpec, bool) {
+	if f.Build.SBOM == "" {
+		return ArtifactSpec{}, false
+	}
+	if _, err := os.Stat(f.SBOMPath()); err != nil {
+		return ArtifactSpec{}, false
+	}
+	mediaType := SBOMMediaType(f.Build.SBOM)
+	return ArtifactSpec{Path: RunDataDir + "/" + BuiltSBOM, Type: mediaType, MediaType: mediaType}, true
+}
+
+// validateSBOM format, if any.
+// Returns array of error messages, empty if no errors are found
+func validateSBOM(format string) (errs []string) {
+	switch format {
+	case "", SBOMFormatCycloneDX, SBOMFormatSPDX:
+	default:
+		errs = append(errs, fmt.Sprintf("build.sbom %q is not a supported format.  Supported formats are %q and %q", format, SBOMFormatCycloneDX, SBOMFormatSPDX))
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package functions
+
+import (
+	"os"
+	"testing"
+)
+
+func Test_validateSBOM(t *testing.T) {
+	for _, format := range []string{"", SBOMFormatCycloneDX, SBOMFormatSPDX} {
+		if errs := validateSBOM(format); len(errs) != 0 {
+			t.Errorf("expected %q to be valid, got %v", format, errs)
+		}
+	}
+	if errs := validateSBOM("syft"); len(errs) != 1 {
+		t.Errorf("expected an unsupported format to be invalid, got %v", errs)
+	}
+}
+
+func TestFunction_sbomArtifact(t *testing.T) {
+	f := Function{Root: t.TempDir(), Build: BuildSpec{SBOM: SBOMFormatCycloneDX}}
+	if _, ok := f.sbomArtifact(); ok {
+		t.Fatal("expected no SBOM artifact before the SBOM is generated")
+	}
+	if err := ensureRunDataDir(f.Root); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(f.SBOMPath(), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, ok := f.sbomArtifact()
+	if !ok {
+		t.Fatal("expected an SBOM artifact")
+	}
+	if a.Path != ".func/sbom.json" || a.MediaType != "application/vnd.cyclonedx+json" {
+		t.Fatalf("unexpected SBOM artifact %+v", a)
+	}
+
+	f.Build.SBOM = ""
+	if _, ok := f.sbomArtifact(); ok {
+		t.Fatal("expected no SBOM artifact of a function not declaring an SBOM")
+	}
+}
@@ -0,0 +1,105 @@
+package functions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scanners of function images for known vulnerabilities.
+const (
+	ScannerTrivy = "trivy"
+	ScannerGrype = "grype"
+)
+
+// DefaultScanner of images whose scan names none.
+const DefaultScanner = ScannerTrivy
+
+// Severities of vulnerabilities, from the least severe.  Those which a
+// scanner reports as unknown or negligible are less severe than all.
+var Severities = []string{"low", "medium", "high", "critical"}
+
+// DefaultScanSeverity at or above which vulnerabilities fail the build.
+const DefaultScanSeverity = "high"
+
+// ScanSpec defines that the function's image is scanned for known
+// vulnerabilities (CVEs) once built, failing the build if any are found at
+// or above its severity.
+type ScanSpec struct {
+	// Scanner of the image, trivy or grype.  Defaults to DefaultScanner.
+	Scanner string `yaml:"scanner,omitempty" jsonschema:"enum=trivy,enum=grype"`
+
+	// Severity at or above which vulnerabilities fail the build, low,
+	// medium, high or critical.  Defaults to DefaultScanSeverity.
+	Severity string `yaml:"severity,omitempty" jsonschema:"enum=low,enum=medium,enum=high,enum=critical"`
+}
+
+// ScannerName of the scan, its scanner or the default.
+func (s ScanSpec) ScannerName() string {
+	if s.Scanner == "" {
+		return DefaultScanner
+	}
+	return s.Scanner
+}
+
+// Threshold of the scan, its severity or the default.
+func (s ScanSpec) Threshold() string {
+	if s.Severity == "" {
+		return DefaultScanSeverity
+	}
+	return s.Severity
+}
+
+// Vulnerability found in a package of an image.
+type Vulnerability struct {
+	ID           string `json:"id" yaml:"id"`
+	Package      string `json:"package" yaml:"package"`
+	Version      string `json:"version" yaml:"version"`
+	FixedVersion string `json:"fixedVersion,omitempty" yaml:"fixedVersion,omitempty"`
+	// Severity, in lower case, being one of Severities, or as reported by
+	// the scanner (such as "unknown") otherwise.
+	Severity string `json:"severity" yaml:"severity"`
+	Title    string `json:"title,omitempty" yaml:"title,omitempty"`
+}
+
+// ScanReport of the vulnerabilities found in an image.
+type ScanReport struct {
+	Image           string          `json:"image" yaml:"image"`
+	Scanner         string          `json:"scanner" yaml:"scanner"`
+	Threshold       string          `json:"threshold" yaml:"threshold"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities" yaml:"vulnerabilities"`
+}
+
+// Failing vulnerabilities of the report, those at or above its threshold.
+func (r ScanReport) Failing() (vv []Vulnerability) {
+	threshold := severityRank(r.Threshold)
+	for _, v := range r.Vulnerabilities {
+		if severityRank(v.Severity) >= threshold {
+			vv = append(vv, v)
+		}
+	}
+	return
+}
+
+// severityRank of the severity, 1 for the least of Severities and 0 for one
+// which is not of them.
+func severityRank(severity string) int {
+	return slices.Index(Severities, strings.ToLower(severity)) + 1
+}
+
+// validateScan checks the scanner and severity of the scan, if any.
+// Returns array of error messages, empty if no errors are found
+func validateScan(scan *ScanSpec) (errs []string) {
+	if scan == nil {
+		return
+	}
+	switch scan.Scanner {
+	case "", ScannerTrivy, ScannerGrype:
+	default:
+		errs = append(errs, fmt.Sprintf("build.scan scanner %q is not supported. Expected %v or %v", scan.Scanner, ScannerTrivy, ScannerGrype))
+	}
+	if scan.Severity != "" && severityRank(scan.Severity) == 0 {
+		errs = append(errs, fmt.Sprintf("build.scan severity %q is not one of %v", scan.Severity, strings.Join(Severities, ", ")))
+	}
+	return
+}
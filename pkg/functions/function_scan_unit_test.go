@@ -0,0 +1,39 @@
+package functions
+
+import (
+	"testing"
+)
+
+func Test_validateScan(t *testing.T) {
+	tests := []struct {
+		name string
+		scan *ScanSpec
+		errs int
+	}{
+		{"none", nil, 0},
+		{"defaults", &ScanSpec{}, 0},
+		{"grype critical", &ScanSpec{Scanner: ScannerGrype, Severity: "critical"}, 0},
+		{"unsupported scanner", &ScanSpec{Scanner: "clair"}, 1},
+		{"unknown severity", &ScanSpec{Severity: "severe"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateScan(tt.scan); len(errs) != tt.errs {
+				t.Errorf("validateScan() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
+
+func TestScanReport_Failing(t *testing.T) {
+	r := ScanReport{Threshold: "medium", Vulnerabilities: []Vulnerability{
+		{ID: "CVE-1", Severity: "low"},
+		{ID: "CVE-2", Severity: "medium"},
+		{ID: "CVE-3", Severity: "critical"},
+		{ID: "CVE-4", Severity: "unknown"},
+	}}
+	failing := r.Failing()
+	if len(failing) != 2 || failing[0].ID != "CVE-2" || failing[1].ID != "CVE-3" {
+		t.Fatalf("expected CVE-2 and CVE-3 to fail, got %v", failing)
+	}
+}
@@ -0,0 +1,143 @@
+package functions
+
+import (
+	"fmt"
+	"strconv"
+
+	"knative.dev/func/pkg/utils"
+)
+
+// SchedulingSpec of the function's instances onto the nodes of the cluster,
+// such as to pin them to a pool of GPU or spot nodes.
+type SchedulingSpec struct {
+	// NodeSelector labels of the nodes to which instances are scheduled.
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty"`
+	// Tolerations of the taints of nodes, without which instances are not
+	// scheduled to them.
+	Tolerations []Toleration `yaml:"tolerations,omitempty"`
+	// Affinity of instances to nodes.
+	Affinity *Affinity `yaml:"affinity,omitempty"`
+}
+
+type Toleration struct {
+	// Key of the taint tolerated.  Empty, with the operator Exists, it is
+	// of all taints.
+	Key string `yaml:"key,omitempty"`
+	// Operator of the key to the value: Equal (the default) or Exists.
+	Operator string `yaml:"operator,omitempty" jsonschema:"enum=Equal,enum=Exists"`
+	Value    string `yaml:"value,omitempty"`
+	// Effect of the taint tolerated, or all effects if empty.
+	Effect string `yaml:"effect,omitempty" jsonschema:"enum=NoSchedule,enum=PreferNoSchedule,enum=NoExecute"`
+	// TolerationSeconds of a NoExecute taint, after which the instance is
+	// evicted.  It is tolerated indefinitely if not set.
+	TolerationSeconds *int64 `yaml:"tolerationSeconds,omitempty"`
+}
+
+type Affinity struct {
+	NodeAffinity *NodeAffinity `yaml:"nodeAffinity,omitempty"`
+}
+
+type NodeAffinity struct {
+	// Required requirements of a node, all of which it must meet.
+	Required []NodeSelectorRequirement `yaml:"required,omitempty"`
+	// Preferred requirements of nodes, the weights of those met by a node
+	// being summed to rank it.
+	Preferred []PreferredSchedulingTerm `yaml:"preferred,omitempty"`
+}
+
+type PreferredSchedulingTerm struct {
+	Weight int32                     `yaml:"weight" jsonschema:"minimum=1,maximum=100"`
+	Match  []NodeSelectorRequirement `yaml:"match"`
+}
+
+type NodeSelectorRequirement struct {
+	// Key of the node's label.
+	Key string `yaml:"key"`
+	// Operator of the label to the values.
+	Operator string   `yaml:"operator" jsonschema:"enum=In,enum=NotIn,enum=Exists,enum=DoesNotExist,enum=Gt,enum=Lt"`
+	Values   []string `yaml:"values,omitempty"`
+}
+
+// validateScheduling checks that the node selector is of labels, and that the
+// tolerations and affinity are of valid operators and effects.
+// Returns array of error messages, empty if no errors are found
+func validateScheduling(s *SchedulingSpec) (errs []string) {
+	if s == nil {
+		return
+	}
+	for _, k := range sortedKeys(s.NodeSelector) {
+		if err := utils.ValidateLabelKey(k); err != nil {
+			errs = append(errs, fmt.Sprintf("scheduling.nodeSelector has invalid key %q: %v", k, err))
+		}
+		if err := utils.ValidateLabelValue(s.NodeSelector[k]); err != nil {
+			errs = append(errs, fmt.Sprintf("scheduling.nodeSelector has invalid value of %q: %v", k, err))
+		}
+	}
+	for i, t := range s.Tolerations {
+		switch t.Operator {
+		case "", "Equal":
+			if t.Key == "" {
+				errs = append(errs, fmt.Sprintf("scheduling.tolerations entry #%d is missing a key, required unless the operator is Exists", i))
+			}
+		case "Exists":
+			if t.Value != "" {
+				errs = append(errs, fmt.Sprintf("scheduling.tolerations entry #%d has a value, which must be empty with the operator Exists", i))
+			}
+		default:
+			errs = append(errs, fmt.Sprintf("scheduling.tolerations entry #%d has invalid operator %q, allowed is only Equal or Exists", i, t.Operator))
+		}
+		switch t.Effect {
+		case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+		default:
+			errs = append(errs, fmt.Sprintf("scheduling.tolerations entry #%d has invalid effect %q, allowed is only NoSchedule, PreferNoSchedule or NoExecute", i, t.Effect))
+		}
+		if t.TolerationSeconds != nil && t.Effect != "NoExecute" {
+			errs = append(errs, fmt.Sprintf("scheduling.tolerations entry #%d has tolerationSeconds, which apply only to the effect NoExecute", i))
+		}
+	}
+	if s.Affinity == nil || s.Affinity.NodeAffinity == nil {
+		return
+	}
+	a := s.Affinity.NodeAffinity
+	for i, r := range a.Required {
+		errs = append(errs, validateNodeSelectorRequirement(fmt.Sprintf("scheduling.affinity.nodeAffinity.required entry #%d", i), r)...)
+	}
+	for i, p := range a.Preferred {
+		field := fmt.Sprintf("scheduling.affinity.nodeAffinity.preferred entry #%d", i)
+		if p.Weight < 1 || p.Weight > 100 {
+			errs = append(errs, fmt.Sprintf("%s has weight %d, which must not be less than 1 or greater than 100", field, p.Weight))
+		}
+		if len(p.Match) == 0 {
+			errs = append(errs, fmt.Sprintf("%s has no requirements to match", field))
+		}
+		for j, r := range p.Match {
+			errs = append(errs, validateNodeSelectorRequirement(fmt.Sprintf("%s match #%d", field, j), r)...)
+		}
+	}
+	return
+}
+
+func validateNodeSelectorRequirement(field string, r NodeSelectorRequirement) (errs []string) {
+	if err := utils.ValidateLabelKey(r.Key); err != nil {
+		errs = append(errs, fmt.Sprintf("%s has invalid key %q: %v", field, r.Key, err))
+	}
+	switch r.Operator {
+	case "In", "NotIn":
+		if len(r.Values) == 0 {
+			errs = append(errs, fmt.Sprintf("%s requires values with the operator %v", field, r.Operator))
+		}
+	case "Exists", "DoesNotExist":
+		if len(r.Values) > 0 {
+			errs = append(errs, fmt.Sprintf("%s may not have values with the operator %v", field, r.Operator))
+		}
+	case "Gt", "Lt":
+		if len(r.Values) != 1 {
+			errs = append(errs, fmt.Sprintf("%s requires exactly one value with the operator %v", field, r.Operator))
+		} else if _, err := strconv.ParseInt(r.Values[0], 10, 64); err != nil {
+			errs = append(errs, fmt.Sprintf("%s requires an integer value with the operator %v, got %q", field, r.Operator, r.Values[0]))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("%s has invalid operator %q, allowed is only In, NotIn, Exists, DoesNotExist, Gt or Lt", field, r.Operator))
+	}
+	return
+}
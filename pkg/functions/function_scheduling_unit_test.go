@@ -0,0 +1,73 @@
+package functions
+
+import (
+	"testing"
+
+	"knative.dev/pkg/ptr"
+)
+
+func Test_validateScheduling(t *testing.T) {
+	tests := []struct {
+		name       string
+		scheduling *SchedulingSpec
+		errs       int
+	}{
+		{
+			"correct - no scheduling",
+			nil,
+			0,
+		},
+		{
+			"correct - node selector, tolerations and affinity",
+			&SchedulingSpec{
+				NodeSelector: map[string]string{"cloud.google.com/gke-spot": "true"},
+				Tolerations: []Toleration{
+					{Key: "nvidia.com/gpu", Operator: "Exists", Effect: "NoSchedule"},
+					{Key: "spot", Value: "true", Effect: "NoExecute", TolerationSeconds: ptr.Int64(30)},
+				},
+				Affinity: &Affinity{NodeAffinity: &NodeAffinity{
+					Required: []NodeSelectorRequirement{{Key: "gpu", Operator: "In", Values: []string{"a100"}}},
+					Preferred: []PreferredSchedulingTerm{{Weight: 50, Match: []NodeSelectorRequirement{
+						{Key: "memory", Operator: "Gt", Values: []string{"64"}},
+					}}},
+				}},
+			},
+			0,
+		},
+		{
+			"incorrect - node selector",
+			&SchedulingSpec{NodeSelector: map[string]string{",gpu": "a:100"}},
+			2,
+		},
+		{
+			"incorrect - tolerations",
+			&SchedulingSpec{Tolerations: []Toleration{
+				{Value: "true"},
+				{Key: "spot", Operator: "Exists", Value: "true"},
+				{Key: "spot", Operator: "Matches", Effect: "Evict"},
+				{Key: "spot", Effect: "NoSchedule", TolerationSeconds: ptr.Int64(30)},
+			}},
+			5,
+		},
+		{
+			"incorrect - affinity",
+			&SchedulingSpec{Affinity: &Affinity{NodeAffinity: &NodeAffinity{
+				Required: []NodeSelectorRequirement{
+					{Key: "gpu", Operator: "In"},
+					{Key: "gpu", Operator: "Exists", Values: []string{"a100"}},
+					{Key: "memory", Operator: "Gt", Values: []string{"lots"}},
+					{Key: "gpu", Operator: "Like"},
+				},
+				Preferred: []PreferredSchedulingTerm{{Weight: 101}},
+			}}},
+			6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateScheduling(tt.scheduling); len(got) != tt.errs {
+				t.Errorf("validateScheduling() = %v\n got %d errors but want %d", got, len(got), tt.errs)
+			}
+		})
+	}
+}
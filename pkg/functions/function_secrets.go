@@ -0,0 +1,82 @@
+package functions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SecretsPath is the directory of the build container at which the
+// function's build secrets are mounted, each as the file of its ID.
+const SecretsPath = "/run/secrets"
+
+// SecretSpec declares a secret of the function's build, such as the token of
+// a private package index or an .npmrc, which is available to the build as
+// the file SecretsPath/<id>, but is not written to the function's image.
+type SecretSpec struct {
+	// ID of the secret, the name of its file in the build container.
+	ID string `yaml:"id"`
+
+	// Path of the local file of the secret's value, absolute, relative to the
+	// function root, or relative to the home directory if prefixed by "~/".
+	Path string `yaml:"path,omitempty"`
+
+	// Env is the name of the local environment variable of the secret's
+	// value, used in lieu of a file.
+	Env string `yaml:"env,omitempty"`
+}
+
+// Value of the secret, read from its file or environment variable on the
+// local machine.
+func (s SecretSpec) Value(root string) ([]byte, error) {
+	if s.Env != "" {
+		v, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return nil, fmt.Errorf("the build secret %q requires the environment variable %v", s.ID, s.Env)
+		}
+		return []byte(v), nil
+	}
+	path := s.Path
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, path[2:])
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the build secret %q: %w", s.ID, err)
+	}
+	return data, nil
+}
+
+var secretIDRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateSecrets checks that each build secret has a distinct ID which is
+// a valid file name, and exactly one of a path or environment variable.
+// Returns array of error messages, empty if no errors are found
+func validateSecrets(secrets []SecretSpec) (errs []string) {
+	seen := map[string]bool{}
+	for i, s := range secrets {
+		if s.ID == "" {
+			errs = append(errs, fmt.Sprintf("build.secrets entry #%d is missing id field", i))
+			continue
+		}
+		if !secretIDRegex.MatchString(s.ID) {
+			errs = append(errs, fmt.Sprintf("build.secrets id %q must consist of alphanumeric characters, '.', '-' or '_'", s.ID))
+		}
+		if seen[s.ID] {
+			errs = append(errs, fmt.Sprintf("build.secrets id %q is declared more than once", s.ID))
+		}
+		seen[s.ID] = true
+		if (s.Path == "") == (s.Env == "") {
+			errs = append(errs, fmt.Sprintf("build.secrets %q requires exactly one of path or env", s.ID))
+		}
+	}
+	return
+}
@@ -0,0 +1,63 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_validateSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets []SecretSpec
+		errs    int
+	}{
+		{"none", nil, 0},
+		{"file", []SecretSpec{{ID: "npmrc", Path: "~/.npmrc"}}, 0},
+		{"env", []SecretSpec{{ID: "pypi-token", Env: "PYPI_TOKEN"}}, 0},
+		{"missing id", []SecretSpec{{Path: "~/.npmrc"}}, 1},
+		{"invalid id", []SecretSpec{{ID: "../npmrc", Path: "~/.npmrc"}}, 1},
+		{"duplicate", []SecretSpec{{ID: "npmrc", Path: "~/.npmrc"}, {ID: "npmrc", Env: "NPMRC"}}, 1},
+		{"no source", []SecretSpec{{ID: "npmrc"}}, 1},
+		{"both sources", []SecretSpec{{ID: "npmrc", Path: "~/.npmrc", Env: "NPMRC"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateSecrets(tt.secrets); len(errs) != tt.errs {
+				t.Errorf("validateSecrets() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
+
+func TestSecretSpec_Value(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "token"), []byte("file-token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FUNC_TEST_TOKEN", "env-token")
+
+	tests := []struct {
+		name   string
+		secret SecretSpec
+		value  string
+		err    bool
+	}{
+		{"relative", SecretSpec{ID: "token", Path: "token"}, "file-token", false},
+		{"absolute", SecretSpec{ID: "token", Path: filepath.Join(root, "token")}, "file-token", false},
+		{"env", SecretSpec{ID: "token", Env: "FUNC_TEST_TOKEN"}, "env-token", false},
+		{"missing file", SecretSpec{ID: "token", Path: "missing"}, "", true},
+		{"missing env", SecretSpec{ID: "token", Env: "FUNC_TEST_MISSING_TOKEN"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.secret.Value(root)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if string(v) != tt.value {
+				t.Errorf("expected %q, got %q", tt.value, v)
+			}
+		})
+	}
+}
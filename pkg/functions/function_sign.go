@@ -0,0 +1,17 @@
+package functions
+
+// SignSpec defines how the function's image is signed once pushed: with a
+// cosign private key, or keyless, with a short-lived certificate issued to
+// the OIDC identity of the environment (for example a CI workflow).
+type SignSpec struct {
+	// Key is the cosign private key with which to sign the image, as a path
+	// relative to the function root.  Its password, if encrypted, is read
+	// from COSIGN_PASSWORD.  Keyless signing is used if empty.
+	Key string `yaml:"key,omitempty"`
+}
+
+// Keyless returns true if the image is signed with a certificate of the
+// environment's identity rather than a key.
+func (s SignSpec) Keyless() bool {
+	return s.Key == ""
+}
@@ -0,0 +1,421 @@
+package functions
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v2"
+)
+
+// SopsMetadata is the metadata written by SOPS (https://getsops.io) into an
+// encrypted func.yaml.  It is retained as-is such that func can update
+// the unencrypted portions of the file while leaving it decryptable.
+//
+// Only age recipients are supported for decryption by func itself.
+// Encrypt func.yaml with --mac-only-encrypted so that the message
+// authentication code remains valid when func updates unencrypted fields
+// (such as deploy.image) after a deployment.  For example:
+//
+//	sops encrypt --age <recipient> --encrypted-regex '^(value|annotations)$' \
+//	  --mac-only-encrypted --in-place func.yaml
+type SopsMetadata struct {
+	KMS                     []map[string]interface{} `yaml:"kms,omitempty"`
+	GCPKMS                  []map[string]interface{} `yaml:"gcp_kms,omitempty"`
+	AzureKV                 []map[string]interface{} `yaml:"azure_kv,omitempty"`
+	HCVault                 []map[string]interface{} `yaml:"hc_vault,omitempty"`
+	Age                     []SopsAgeKey             `yaml:"age,omitempty"`
+	LastModified            string                   `yaml:"lastmodified,omitempty"`
+	MAC                     string                   `yaml:"mac,omitempty"`
+	PGP                     []map[string]interface{} `yaml:"pgp,omitempty"`
+	UnencryptedSuffix       string                   `yaml:"unencrypted_suffix,omitempty"`
+	EncryptedSuffix         string                   `yaml:"encrypted_suffix,omitempty"`
+	UnencryptedRegex        string                   `yaml:"unencrypted_regex,omitempty"`
+	EncryptedRegex          string                   `yaml:"encrypted_regex,omitempty"`
+	UnencryptedCommentRegex string                   `yaml:"unencrypted_comment_regex,omitempty"`
+	EncryptedCommentRegex   string                   `yaml:"encrypted_comment_regex,omitempty"`
+	MACOnlyEncrypted        bool                     `yaml:"mac_only_encrypted,omitempty"`
+	Version                 string                   `yaml:"version,omitempty"`
+}
+
+// SopsAgeKey is the data key of a SOPS encrypted file, encrypted for a
+// single age recipient.
+type SopsAgeKey struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+// ErrSopsKeyUnavailable is returned when a function contains encrypted
+// values but none of the available age identities can decrypt them.
+var ErrSopsKeyUnavailable = errors.New("func.yaml contains SOPS encrypted values, but no age identity able to decrypt them was found (set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE)")
+
+// sopsValuePattern matches a single SOPS encrypted value.
+var sopsValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]\z`)
+
+// IsEncrypted returns true if the value is a SOPS encrypted value.
+func IsEncrypted(v string) bool {
+	return sopsValuePattern.MatchString(v)
+}
+
+// Encrypted returns true if the function's func.yaml is SOPS encrypted.
+func (f Function) Encrypted() bool {
+	return f.Sops != nil
+}
+
+// Decrypt returns a copy of the function with the SOPS encrypted values of
+// environment variables, build environment variables and annotations
+// replaced by their plaintext.  The function itself is left unchanged such
+// that plaintext values are never written back to func.yaml.  Functions
+// which are not encrypted are returned as-is, and an error is returned should
+// the value of any other field be encrypted.
+func (f Function) Decrypt() (Function, error) {
+	if !f.Encrypted() {
+		return f, nil
+	}
+	if err := f.checkEncryptedFields(); err != nil {
+		return f, err
+	}
+	if !f.hasEncryptedValues() {
+		return f, nil
+	}
+	key, err := f.Sops.dataKey()
+	if err != nil {
+		return f, err
+	}
+	if err = f.Sops.verifyMAC(filepath.Join(f.Root, FunctionFile), key); err != nil {
+		return f, err
+	}
+
+	decrypt := func(v string, path ...string) (string, error) {
+		if !IsEncrypted(v) {
+			return v, nil
+		}
+		return sopsDecryptValue(v, key, strings.Join(path, ":")+":")
+	}
+
+	if f.Run.Envs, err = decryptEnvs(f.Run.Envs, decrypt, "run", "envs", "value"); err != nil {
+		return f, err
+	}
+	if f.Build.BuildEnvs, err = decryptEnvs(f.Build.BuildEnvs, decrypt, "build", "buildEnvs", "value"); err != nil {
+		return f, err
+	}
+	annotations := make(map[string]string, len(f.Deploy.Annotations))
+	for k, v := range f.Deploy.Annotations {
+		if annotations[k], err = decrypt(v, "deploy", "annotations", k); err != nil {
+			return f, fmt.Errorf("unable to decrypt annotation %q: %w", k, err)
+		}
+	}
+	f.Deploy.Annotations = annotations
+	return f, nil
+}
+
+// RedactedValue replaces each encrypted value of a function whose resources
+// are rendered rather than deployed.
+const RedactedValue = "REDACTED"
+
+// Redact returns a copy of the function with the SOPS encrypted values of
+// environment variables, build environment variables and annotations
+// replaced by RedactedValue, such that resources rendered of it hold neither
+// their plaintext nor their ciphertext.
+func (f Function) Redact() Function {
+	redact := func(v string, _ ...string) (string, error) {
+		if IsEncrypted(v) {
+			return RedactedValue, nil
+		}
+		return v, nil
+	}
+	f.Run.Envs, _ = decryptEnvs(f.Run.Envs, redact)
+	f.Build.BuildEnvs, _ = decryptEnvs(f.Build.BuildEnvs, redact)
+	annotations := make(map[string]string, len(f.Deploy.Annotations))
+	for k, v := range f.Deploy.Annotations {
+		annotations[k], _ = redact(v)
+	}
+	f.Deploy.Annotations = annotations
+	return f
+}
+
+func (f Function) hasEncryptedValues() bool {
+	for _, e := range append(append([]Env{}, f.Run.Envs...), f.Build.BuildEnvs...) {
+		if e.Value != nil && IsEncrypted(*e.Value) {
+			return true
+		}
+	}
+	for _, v := range f.Deploy.Annotations {
+		if IsEncrypted(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// decryptEnvs returns a copy of the envs with encrypted values decrypted.
+func decryptEnvs(envs []Env, decrypt func(string, ...string) (string, error), path ...string) ([]Env, error) {
+	if envs == nil {
+		return nil, nil
+	}
+	out := make([]Env, len(envs))
+	for i, e := range envs {
+		out[i] = e
+		if e.Value == nil {
+			continue
+		}
+		v, err := decrypt(*e.Value, path...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt %v: %w", e, err)
+		}
+		out[i].Value = &v
+	}
+	return out, nil
+}
+
+// dataKey decrypts the file's data key using the first age identity able
+// to do so.
+func (m *SopsMetadata) dataKey() ([]byte, error) {
+	identities, err := sopsAgeIdentities()
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, ErrSopsKeyUnavailable
+	}
+	for _, k := range m.Age {
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(k.Enc)), identities...)
+		if err != nil {
+			continue // not a recipient for which we hold an identity
+		}
+		return io.ReadAll(r)
+	}
+	return nil, ErrSopsKeyUnavailable
+}
+
+// verifyMAC of the file, which is the SHA-512 of each value in document
+// order, the plaintext of those encrypted, or, of a file encrypted with
+// mac_only_encrypted, of only those encrypted.
+func (m *SopsMetadata) verifyMAC(filename string, key []byte) error {
+	if m.MAC == "" {
+		return errors.New("func.yaml is SOPS encrypted without a message authentication code")
+	}
+	doc, err := readSopsDocument(filename)
+	if err != nil {
+		return err
+	}
+
+	hash := sha512.New()
+	err = walkSopsValues(doc, func(path []string, v interface{}) error {
+		switch v := v.(type) {
+		case string:
+			if IsEncrypted(v) {
+				plain, err := sopsDecryptValue(v, key, strings.Join(path, ":")+":")
+				if err != nil {
+					return err
+				}
+				hash.Write([]byte(plain))
+			} else if !m.MACOnlyEncrypted {
+				hash.Write([]byte(v))
+			}
+		case nil: // not hashed by SOPS
+		default:
+			if !m.MACOnlyEncrypted {
+				hash.Write(sopsValueBytes(v))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	mac, err := sopsDecryptValue(m.MAC, key, m.LastModified)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt SOPS message authentication code: %w", err)
+	}
+	if !strings.EqualFold(mac, fmt.Sprintf("%X", hash.Sum(nil))) {
+		if m.MACOnlyEncrypted {
+			return errors.New("SOPS message authentication code mismatch: encrypted values in func.yaml have been modified")
+		}
+		return errors.New("SOPS message authentication code mismatch: values in func.yaml have been modified (encrypt it with --mac-only-encrypted for func to update its unencrypted values)")
+	}
+	return nil
+}
+
+// readSopsDocument of the file, in document order.
+func readSopsDocument(filename string) (doc yaml.MapSlice, err error) {
+	bb, err := os.ReadFile(filename)
+	if err != nil {
+		return
+	}
+	err = yaml.Unmarshal(bb, &doc)
+	return
+}
+
+// walkSopsValues of the document in document order, but for its sops
+// metadata, calling fn with each value and its path as SOPS names it: the
+// keys of the maps in which it is nested, list items not extending the path.
+func walkSopsValues(doc yaml.MapSlice, fn func(path []string, v interface{}) error) error {
+	var walk func(v interface{}, path []string) error
+	walk = func(v interface{}, path []string) error {
+		switch v := v.(type) {
+		case yaml.MapSlice:
+			for _, item := range v {
+				k := fmt.Sprint(item.Key)
+				if len(path) == 0 && k == "sops" {
+					continue
+				}
+				if err := walk(item.Value, append(path, k)); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for _, item := range v {
+				if err := walk(item, path); err != nil {
+					return err
+				}
+			}
+		default:
+			return fn(path, v)
+		}
+		return nil
+	}
+	return walk(doc, nil)
+}
+
+// decryptedFields of func.yaml, the paths of those values which are decrypted
+// if encrypted (see Decrypt), "*" being any key.
+var decryptedFields = [][]string{
+	{"run", "envs", "value"},
+	{"build", "buildEnvs", "value"},
+	{"deploy", "annotations", "*"},
+}
+
+// checkEncryptedFields of the function's func.yaml, returning an error of the
+// first value encrypted which is not of a field which is decrypted, as it
+// would otherwise reach the builder or deployer as its ciphertext.
+func (f Function) checkEncryptedFields() error {
+	doc, err := readSopsDocument(filepath.Join(f.Root, FunctionFile))
+	if err != nil {
+		return err
+	}
+	return walkSopsValues(doc, func(path []string, v interface{}) error {
+		if s, ok := v.(string); !ok || !IsEncrypted(s) || isDecryptedField(path) {
+			return nil
+		}
+		return fmt.Errorf("the value of %v in func.yaml is SOPS encrypted, which is supported only of run.envs, build.buildEnvs and deploy.annotations", strings.Join(path, "."))
+	})
+}
+
+// isDecryptedField returns true if the path is of a field which is decrypted.
+func isDecryptedField(path []string) bool {
+	for _, field := range decryptedFields {
+		if len(field) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range field {
+			if field[i] != "*" && field[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sopsValueBytes of a value other than a string, as SOPS hashes it.
+func sopsValueBytes(v interface{}) []byte {
+	switch v := v.(type) {
+	case int:
+		return []byte(strconv.Itoa(v))
+	case int64:
+		return []byte(strconv.FormatInt(v, 10))
+	case uint64:
+		return []byte(strconv.FormatUint(v, 10))
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		if v {
+			return []byte("True")
+		}
+		return []byte("False")
+	}
+	return []byte(fmt.Sprint(v))
+}
+
+// sopsDecryptValue decrypts a single SOPS value, authenticated by the
+// additional data (the value's path).
+func sopsDecryptValue(v string, key []byte, additionalData string) (string, error) {
+	matches := sopsValuePattern.FindStringSubmatch(v)
+	if matches == nil {
+		return "", errors.New("value is not SOPS encrypted")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return "", fmt.Errorf("malformed encrypted value: %w", err)
+		}
+		parts[i] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", err
+	}
+	plain, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", fmt.Errorf("unable to decrypt value: %w", err)
+	}
+	return string(plain), nil
+}
+
+// sopsAgeIdentities available in the environment, following the same
+// lookup as SOPS: SOPS_AGE_KEY, SOPS_AGE_KEY_FILE and then the default
+// keys file in the user's config directory.
+func sopsAgeIdentities() (identities []age.Identity, err error) {
+	var sources [][]byte
+	if v := os.Getenv("SOPS_AGE_KEY"); v != "" {
+		sources = append(sources, []byte(v))
+	}
+	path := os.Getenv("SOPS_AGE_KEY_FILE")
+	if path == "" {
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			if dir, err = os.UserConfigDir(); err != nil {
+				return nil, nil // no config dir; no default keys file
+			}
+		}
+		path = filepath.Join(dir, "sops", "age", "keys.txt")
+	}
+	if bb, err := os.ReadFile(path); err == nil {
+		sources = append(sources, bb)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, s := range sources {
+		ii, err := age.ParseIdentities(bytes.NewReader(s))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse age identities: %w", err)
+		}
+		identities = append(identities, ii...)
+	}
+	return
+}
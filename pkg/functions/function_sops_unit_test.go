@@ -0,0 +1,257 @@
+package functions
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// sopsEncryptValue encrypts as SOPS does, for use in constructing fixtures.
+func sopsEncryptValue(t *testing.T, v string, key []byte, additionalData string) string {
+	t.Helper()
+	iv := make([]byte, 32)
+	if _, err := rand.Read(iv); err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := gcm.Seal(nil, iv, []byte(v), []byte(additionalData))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%v,iv:%v,tag:%v,type:str]", enc(data), enc(iv), enc(tag))
+}
+
+// sopsDataKey generates an age identity and a data key encrypted for it, the
+// latter armored and indented for the sops metadata of fixtures.
+func sopsDataKey(t *testing.T) (identity *age.X25519Identity, key []byte, enc string) {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key = make([]byte, 32)
+	if _, err = rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	aw := armor.NewWriter(buf)
+	w, err := age.Encrypt(aw, identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write(key); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return identity, key, "      " + strings.ReplaceAll(strings.TrimSpace(buf.String()), "\n", "\n      ")
+}
+
+// TestFunction_Decrypt ensures that SOPS encrypted values are decrypted when
+// an age identity is available, and that func.yaml itself remains encrypted.
+func TestFunction_Decrypt(t *testing.T) {
+	root := t.TempDir()
+	identity, key, enc := sopsDataKey(t)
+
+	lastModified := "2024-01-01T00:00:00Z"
+	hash := sha512.New()
+	hash.Write([]byte("s3cret"))
+	hash.Write([]byte("token"))
+	mac := sopsEncryptValue(t, fmt.Sprintf("%X", hash.Sum(nil)), key, lastModified)
+
+	yml := fmt.Sprintf(`specVersion: %v
+name: encrypted
+runtime: go
+run:
+  envs:
+  - name: PASSWORD
+    value: %v
+  - name: PLAIN
+    value: plain
+deploy:
+  annotations:
+    example.com/token: %v
+sops:
+  age:
+  - recipient: %v
+    enc: |
+%v
+  lastmodified: "%v"
+  mac: %v
+  encrypted_regex: ^(value|annotations)$
+  mac_only_encrypted: true
+  version: 3.9.0
+`, LastSpecVersion(),
+		sopsEncryptValue(t, "s3cret", key, "run:envs:value:"),
+		sopsEncryptValue(t, "token", key, "deploy:annotations:example.com/token:"),
+		identity.Recipient(), enc, lastModified, mac)
+	if err := os.WriteFile(filepath.Join(root, FunctionFile), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Encrypted() {
+		t.Fatal("expected function to be encrypted")
+	}
+
+	// Without an identity, decryption fails
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(root, "missing.txt"))
+	if _, err = f.Decrypt(); !errors.Is(err, ErrSopsKeyUnavailable) {
+		t.Fatalf("expected ErrSopsKeyUnavailable, got %v", err)
+	}
+
+	// With the identity, values are decrypted
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+	d, err := f.Decrypt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *d.Run.Envs[0].Value != "s3cret" || *d.Run.Envs[1].Value != "plain" {
+		t.Fatalf("unexpected decrypted envs: %v, %v", *d.Run.Envs[0].Value, *d.Run.Envs[1].Value)
+	}
+	if d.Deploy.Annotations["example.com/token"] != "token" {
+		t.Fatalf("unexpected decrypted annotation: %v", d.Deploy.Annotations["example.com/token"])
+	}
+
+	// The original is untouched, and writing it retains the encryption metadata
+	if !IsEncrypted(*f.Run.Envs[0].Value) || !IsEncrypted(f.Deploy.Annotations["example.com/token"]) {
+		t.Fatal("expected the original function to remain encrypted")
+	}
+	f.Deploy.Image = "example.com/alice/encrypted@sha256:0000"
+	if err = f.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = NewFunction(root); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Decrypt(); err != nil {
+		t.Fatalf("expected encrypted func.yaml to remain decryptable after write: %v", err)
+	}
+
+	// Tampering with an encrypted value fails authentication
+	moved := f.Deploy.Annotations["example.com/token"] // valid ciphertext at the wrong path
+	f.Run.Envs[0].Value = &moved
+	if _, err = f.Decrypt(); err == nil {
+		t.Fatal("expected decryption of a value moved to another path to fail")
+	}
+}
+
+// TestFunction_DecryptTampered ensures that the message authentication code
+// of a file encrypted without mac_only_encrypted is verified over all of its
+// values, such that modifying an unencrypted value fails decryption.
+func TestFunction_DecryptTampered(t *testing.T) {
+	root := t.TempDir()
+	identity, key, enc := sopsDataKey(t)
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	lastModified := "2024-01-01T00:00:00Z"
+	hash := sha512.New()
+	for _, v := range []string{LastSpecVersion(), "encrypted", "go", "PASSWORD", "s3cret", "1", "True"} {
+		hash.Write([]byte(v))
+	}
+	mac := sopsEncryptValue(t, fmt.Sprintf("%X", hash.Sum(nil)), key, lastModified)
+
+	yml := fmt.Sprintf(`specVersion: %v
+name: encrypted
+runtime: go
+run:
+  envs:
+  - name: PASSWORD
+    value: %v
+deploy:
+  options:
+    scale:
+      min: 1
+  remote: true
+sops:
+  age:
+  - recipient: %v
+    enc: |
+%v
+  lastmodified: "%v"
+  mac: %v
+  encrypted_regex: ^value$
+  version: 3.9.0
+`, LastSpecVersion(), sopsEncryptValue(t, "s3cret", key, "run:envs:value:"), identity.Recipient(), enc, lastModified, mac)
+	write := func(yml string) Function {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, FunctionFile), []byte(yml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := NewFunction(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	if _, err := write(yml).Decrypt(); err != nil {
+		t.Fatalf("expected the untampered file to decrypt: %v", err)
+	}
+	if _, err := write(strings.Replace(yml, "runtime: go", "runtime: node", 1)).Decrypt(); err == nil || !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("expected a mismatched message authentication code, got %v", err)
+	}
+}
+
+// TestFunction_DecryptUnsupported ensures that a value encrypted of a field
+// which is not decrypted is refused, rather than used as its ciphertext.
+func TestFunction_DecryptUnsupported(t *testing.T) {
+	root := t.TempDir()
+	identity, key, enc := sopsDataKey(t)
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	yml := fmt.Sprintf(`specVersion: %v
+name: encrypted
+runtime: go
+deploy:
+  labels:
+  - key: team
+    value: %v
+sops:
+  age:
+  - recipient: %v
+    enc: |
+%v
+  lastmodified: "2024-01-01T00:00:00Z"
+  mac: %v
+  encrypted_regex: ^value$
+  mac_only_encrypted: true
+  version: 3.9.0
+`, LastSpecVersion(), sopsEncryptValue(t, "alice", key, "deploy:labels:value:"), identity.Recipient(), enc,
+		sopsEncryptValue(t, "0", key, "2024-01-01T00:00:00Z"))
+	if err := os.WriteFile(filepath.Join(root, FunctionFile), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFunction(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Decrypt(); err == nil || !strings.Contains(err.Error(), "deploy.labels.value") {
+		t.Fatalf("expected the encrypted label to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Strategies by which the tag of a function's image is derived when building,
+// when its image is not given explicitly.
+const (
+	// TagStrategyLatest tags every build "latest", the default.
+	TagStrategyLatest = "latest"
+	// TagStrategyGitSHA tags with the short hash of the commit checked out.
+	TagStrategyGitSHA = "git-sha"
+	// TagStrategySemver tags with the semantic version of the git tag of the
+	// commit checked out, without its "v" prefix.
+	TagStrategySemver = "semver"
+	// TagStrategyTimestamp tags with the time of the build, in UTC.
+	TagStrategyTimestamp = "timestamp"
+)
+
+// TagStrategies supported, the first being the default.
+var TagStrategies = []string{TagStrategyLatest, TagStrategyGitSHA, TagStrategySemver, TagStrategyTimestamp}
+
+// TagExpressions which may be used in the tag of a function's image, as in
+// registry.example.com/alice/hello:{{git.sha}}, expanded when it is built.
+var TagExpressions = []string{"git.sha", "git.shortSha", "git.branch", "git.tag", "timestamp"}
+
+// timestampLayout of tags derived from the time of the build.
+const timestampLayout = "20060102-150405"
+
+var (
+	tagExpressionRegex = regexp.MustCompile(`{{\s*([\w.]+)\s*}}`)
+	semverRegex        = regexp.MustCompile(`^v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)$`)
+	invalidTagRegex    = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+)
+
+// HasTagExpressions returns whether the image includes expressions.
+func HasTagExpressions(image string) bool {
+	return tagExpressionRegex.MatchString(image)
+}
+
+// TagStrategyName of the function, its strategy or the default.
+func (f Function) TagStrategyName() string {
+	if f.Build.TagStrategy == "" {
+		return TagStrategyLatest
+	}
+	return f.Build.TagStrategy
+}
+
+// TaggedImage of the function as given by its image, with the tag last
+// derived from its expressions if it has them (see Deploy.Tag), or no tag if
+// it has not yet been built.
+func (f Function) TaggedImage() string {
+	if !HasTagExpressions(f.Image) {
+		return f.Image
+	}
+	repository, _ := splitImageTag(f.Image)
+	if f.Deploy.Tag == "" {
+		return repository
+	}
+	return repository + ":" + f.Deploy.Tag
+}
+
+// deriveTag of the function's image when built at the given time: that of
+// the expressions of its image if it has them, or else of its strategy.
+// Returns "" if its image is given explicitly without expressions, or it is
+// tagged "latest".
+func (f Function) deriveTag(now time.Time) (tag string, err error) {
+	if f.Image != "" {
+		if !HasTagExpressions(f.Image) {
+			return "", nil
+		}
+		_, tag = splitImageTag(f.Image)
+		tag = tagExpressionRegex.ReplaceAllStringFunc(tag, func(e string) string {
+			v, vErr := tagValue(f.Root, tagExpressionRegex.FindStringSubmatch(e)[1], now)
+			if vErr != nil && err == nil {
+				err = vErr
+			}
+			return v
+		})
+		return
+	}
+	switch f.TagStrategyName() {
+	case TagStrategyGitSHA:
+		return tagValue(f.Root, "git.shortSha", now)
+	case TagStrategySemver:
+		return tagValue(f.Root, "git.tag", now)
+	case TagStrategyTimestamp:
+		return tagValue(f.Root, "timestamp", now)
+	}
+	return "", nil
+}
+
+// tagValue of the expression for the function at root.
+func tagValue(root, expression string, now time.Time) (string, error) {
+	if expression == "timestamp" {
+		return now.UTC().Format(timestampLayout), nil
+	}
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("cannot expand {{%v}}: the function is not in a git repository: %w", expression, err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("cannot expand {{%v}}: %w", expression, err)
+	}
+	switch expression {
+	case "git.sha":
+		return head.Hash().String(), nil
+	case "git.shortSha":
+		return head.Hash().String()[:7], nil
+	case "git.branch":
+		if !head.Name().IsBranch() {
+			return "", fmt.Errorf("cannot expand {{%v}}: no branch is checked out", expression)
+		}
+		return invalidTagRegex.ReplaceAllString(head.Name().Short(), "-"), nil
+	case "git.tag":
+		return semverTag(repo, head.Hash())
+	}
+	return "", fmt.Errorf("unknown expression {{%v}}.  Expected one of %v", expression, strings.Join(TagExpressions, ", "))
+}
+
+// semverTag of the commit: the semantic version of the greatest git tag of
+// the commit which is one, without its "v" prefix.
+func semverTag(repo *git.Repository, commit plumbing.Hash) (string, error) {
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", err
+	}
+	var versions []string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		if tag, err := repo.TagObject(hash); err == nil { // annotated
+			if c, err := tag.Commit(); err == nil {
+				hash = c.Hash
+			}
+		}
+		if m := semverRegex.FindStringSubmatch(ref.Name().Short()); m != nil && hash == commit {
+			versions = append(versions, m[1])
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", errors.New("the commit checked out has no git tag of a semantic version (such as v1.2.3)")
+	}
+	slices.SortFunc(versions, compareVersions)
+	return versions[len(versions)-1], nil
+}
+
+// compareVersions of the form major.minor.patch[-prerelease], a version being
+// greater than its prereleases.
+func compareVersions(a, b string) int {
+	av, apre, _ := strings.Cut(a, "-")
+	bv, bpre, _ := strings.Cut(b, "-")
+	aa, bb := strings.Split(av, "."), strings.Split(bv, ".")
+	for i := range aa {
+		if c := len(aa[i]) - len(bb[i]); c != 0 { // no leading zeros
+			return c
+		}
+		if c := strings.Compare(aa[i], bb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	}
+	return strings.Compare(apre, bpre)
+}
+
+// splitImageTag into its repository and tag, "" if it has none.
+func splitImageTag(image string) (repository, tag string) {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return image, "" // none, or the port of the registry
+	}
+	return image[:i], image[i+1:]
+}
+
+// validateTags checks the tag strategy of the function, and the expressions
+// of its image, which may only be of its tag.
+// Returns array of error messages, empty if no errors are found
+func validateTags(f Function) (errs []string) {
+	if f.Build.TagStrategy != "" && !slices.Contains(TagStrategies, f.Build.TagStrategy) {
+		errs = append(errs, fmt.Sprintf("build.tagStrategy %q is not supported. Expected one of %v", f.Build.TagStrategy, strings.Join(TagStrategies, ", ")))
+	}
+	if !HasTagExpressions(f.Image) {
+		return
+	}
+	repository, tag := splitImageTag(f.Image)
+	if HasTagExpressions(repository) || tag == "" {
+		errs = append(errs, fmt.Sprintf("image %q may only have expressions in its tag", f.Image))
+	}
+	for _, m := range tagExpressionRegex.FindAllStringSubmatch(tag, -1) {
+		if !slices.Contains(TagExpressions, m[1]) {
+			errs = append(errs, fmt.Sprintf("image %q expression {{%v}} is not one of %v", f.Image, m[1], strings.Join(TagExpressions, ", ")))
+		}
+	}
+	return
+}
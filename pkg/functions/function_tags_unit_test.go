@@ -0,0 +1,131 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initTagsRepo initializes a git repository at root with a commit, tagged
+// with the given tags, annotated if prefixed with "a:".
+func initTagsRepo(t *testing.T, root string, tags ...string) plumbing.Hash {
+	t.Helper()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "handle.go"), []byte("package function\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = wt.Add("handle.go"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "alice", Email: "alice@example.com", When: time.Now()}
+	commit, err := wt.Commit("initial", &git.CommitOptions{Author: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range tags {
+		var opts *git.CreateTagOptions
+		if name, ok := strings.CutPrefix(tag, "a:"); ok {
+			tag, opts = name, &git.CreateTagOptions{Tagger: sig, Message: name}
+		}
+		if _, err = repo.CreateTag(tag, commit, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return commit
+}
+
+func TestFunction_deriveTag(t *testing.T) {
+	root := t.TempDir()
+	commit := initTagsRepo(t, root, "v1.2.3", "a:v1.10.0-rc.1", "a:v1.10.0", "release")
+	now := time.Date(2026, 10, 14, 9, 30, 5, 0, time.FixedZone("", 3600))
+
+	tests := []struct {
+		name  string
+		image string
+		tags  string
+		tag   string
+	}{
+		{"latest", "", TagStrategyLatest, ""},
+		{"default", "", "", ""},
+		{"git-sha", "", TagStrategyGitSHA, commit.String()[:7]},
+		{"semver", "", TagStrategySemver, "1.10.0"},
+		{"timestamp", "", TagStrategyTimestamp, "20261014-083005"},
+		{"explicit", "example.com/alice/f:v1", TagStrategyGitSHA, ""},
+		{"expressions", "example.com/alice/f:{{ git.branch }}-{{git.sha}}", "", "master-" + commit.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Function{Root: root, Image: tt.image, Build: BuildSpec{TagStrategy: tt.tags}}
+			tag, err := f.deriveTag(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tag != tt.tag {
+				t.Fatalf("expected tag %q, got %q", tt.tag, tag)
+			}
+		})
+	}
+}
+
+func TestFunction_deriveTagErrors(t *testing.T) {
+	untagged := t.TempDir()
+	initTagsRepo(t, untagged, "release")
+	if _, err := (Function{Root: untagged, Build: BuildSpec{TagStrategy: TagStrategySemver}}).deriveTag(time.Now()); err == nil {
+		t.Error("expected an error deriving the semver of a commit without a tag of one")
+	}
+	if _, err := (Function{Root: t.TempDir(), Build: BuildSpec{TagStrategy: TagStrategyGitSHA}}).deriveTag(time.Now()); err == nil {
+		t.Error("expected an error deriving the git-sha of a function not in a repository")
+	}
+}
+
+func TestFunction_TaggedImage(t *testing.T) {
+	tests := []struct {
+		image, tag, tagged string
+	}{
+		{"example.com/alice/f:v1", "", "example.com/alice/f:v1"},
+		{"localhost:5000/alice/f:{{git.sha}}", "", "localhost:5000/alice/f"},
+		{"localhost:5000/alice/f:{{git.sha}}", "abc", "localhost:5000/alice/f:abc"},
+	}
+	for _, tt := range tests {
+		f := Function{Image: tt.image, Deploy: DeploySpec{Tag: tt.tag}}
+		if tagged := f.TaggedImage(); tagged != tt.tagged {
+			t.Errorf("TaggedImage() of %q = %q, want %q", tt.image, tagged, tt.tagged)
+		}
+	}
+}
+
+func Test_validateTags(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Function
+		errs int
+	}{
+		{"none", Function{}, 0},
+		{"strategy", Function{Build: BuildSpec{TagStrategy: TagStrategySemver}}, 0},
+		{"unknown strategy", Function{Build: BuildSpec{TagStrategy: "branch"}}, 1},
+		{"expressions", Function{Image: "example.com/alice/f:{{git.shortSha}}-{{timestamp}}"}, 0},
+		{"unknown expression", Function{Image: "example.com/alice/f:{{git.author}}"}, 1},
+		{"expression of the repository", Function{Image: "example.com/{{git.branch}}/f:latest"}, 1},
+		{"expression without a tag", Function{Image: "example.com/alice/{{git.branch}}"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateTags(tt.f); len(errs) != tt.errs {
+				t.Errorf("validateTags() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
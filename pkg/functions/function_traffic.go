@@ -0,0 +1,116 @@
+package functions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// TrafficLatest is the revision of a traffic split which is that being
+	// deployed.
+	TrafficLatest = "latest"
+	// TrafficPrevious is the revision of a traffic split which is the latest
+	// ready revision prior to the deployment.
+	TrafficPrevious = "prev"
+	// TrafficCandidate is the tag of the revision deployed by the blue-green
+	// strategy, routed none of the traffic until promoted.
+	TrafficCandidate = "candidate"
+)
+
+const (
+	// StrategyLatest routes all of the traffic to each new revision of a
+	// function once ready, unless split otherwise.
+	StrategyLatest = "latest"
+	// StrategyBlueGreen routes each new revision none of the traffic, tagged
+	// as the candidate, until promoted.
+	StrategyBlueGreen = "blue-green"
+)
+
+// TrafficSplit is the percentage of a function's traffic routed to one of its
+// revisions on deployment, such that a new revision may be introduced with
+// part of the traffic rather than all of it.
+type TrafficSplit struct {
+	// Revision to which the traffic is routed: "latest", being that deployed,
+	// "prev", being the latest ready revision prior to the deployment, or the
+	// name of a revision.
+	Revision string `yaml:"revision"`
+
+	// Percent of the traffic routed to the revision.
+	Percent int64 `yaml:"percent" jsonschema:"minimum=0,maximum=100"`
+
+	// Tag of the revision, by which it is routed a URL of its own.  The latest
+	// and previous revisions are tagged "latest" and "prev" by default.
+	Tag string `yaml:"tag,omitempty"`
+}
+
+// ParseTraffic splits of the form revision=percent, separated by commas, for
+// example "latest=90,prev=10".
+func ParseTraffic(s string) (tt []TrafficSplit, err error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	for _, split := range strings.Split(s, ",") {
+		revision, percent, ok := strings.Cut(strings.TrimSpace(split), "=")
+		if !ok || revision == "" {
+			return nil, fmt.Errorf("invalid traffic split %q. Expected revision=percent, such as latest=90", split)
+		}
+		p, err := strconv.ParseInt(strings.TrimSuffix(percent, "%"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid percent of traffic split %q: %w", split, err)
+		}
+		tt = append(tt, TrafficSplit{Revision: revision, Percent: p})
+	}
+	return tt, nil
+}
+
+// validateTraffic checks that the splits are of distinct revisions, with
+// valid tags, and that their percentages total 100.
+// Returns array of error messages, empty if no errors are found
+func validateTraffic(tt []TrafficSplit) (errs []string) {
+	if len(tt) == 0 {
+		return
+	}
+	var total int64
+	revisions := map[string]bool{}
+	for i, t := range tt {
+		if t.Revision == "" {
+			errs = append(errs, fmt.Sprintf("deploy.traffic entry #%d requires a revision", i))
+		}
+		if revisions[t.Revision] {
+			errs = append(errs, fmt.Sprintf("deploy.traffic revision %q is given more than once", t.Revision))
+		}
+		revisions[t.Revision] = true
+		if t.Percent < 0 || t.Percent > 100 {
+			errs = append(errs, fmt.Sprintf("deploy.traffic percent of %q must be between 0 and 100, got %d", t.Revision, t.Percent))
+		}
+		if t.Tag != "" {
+			for _, msg := range validation.IsDNS1035Label(t.Tag) {
+				errs = append(errs, fmt.Sprintf("deploy.traffic tag %q of %q is invalid: %v", t.Tag, t.Revision, msg))
+			}
+		}
+		total += t.Percent
+	}
+	if total != 100 {
+		errs = append(errs, fmt.Sprintf("deploy.traffic percentages must total 100, got %d", total))
+	}
+	return
+}
+
+// validateStrategy checks that the strategy of deployment is known, and that
+// a blue-green deployment does not also split the traffic.
+// Returns array of error messages, empty if no errors are found
+func validateStrategy(d DeploySpec) (errs []string) {
+	switch d.Strategy {
+	case "", StrategyLatest:
+	case StrategyBlueGreen:
+		if len(d.Traffic) > 0 {
+			errs = append(errs, "deploy.strategy blue-green routes the traffic itself, and may not be given with deploy.traffic")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("deploy.strategy %q is unknown. Expected %q or %q", d.Strategy, StrategyLatest, StrategyBlueGreen))
+	}
+	return
+}
@@ -0,0 +1,68 @@
+package functions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTraffic(t *testing.T) {
+	tt, err := ParseTraffic("latest=90, prev=10%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TrafficSplit{{Revision: "latest", Percent: 90}, {Revision: "prev", Percent: 10}}
+	if !reflect.DeepEqual(tt, want) {
+		t.Errorf("expected %v, got %v", want, tt)
+	}
+	for _, s := range []string{"latest", "=10", "latest=all"} {
+		if _, err := ParseTraffic(s); err == nil {
+			t.Errorf("expected an error parsing %q", s)
+		}
+	}
+}
+
+func Test_validateTraffic(t *testing.T) {
+	tests := []struct {
+		name    string
+		traffic []TrafficSplit
+		errs    int
+	}{
+		{"none", nil, 0},
+		{"split", []TrafficSplit{{Revision: "latest", Percent: 90}, {Revision: "prev", Percent: 10}}, 0},
+		{"tagged", []TrafficSplit{{Revision: "latest", Percent: 100, Tag: "canary"}}, 0},
+		{"not 100", []TrafficSplit{{Revision: "latest", Percent: 90}}, 1},
+		{"out of range", []TrafficSplit{{Revision: "latest", Percent: 110}, {Revision: "prev", Percent: -10}}, 2},
+		{"duplicate", []TrafficSplit{{Revision: "latest", Percent: 50}, {Revision: "latest", Percent: 50}}, 1},
+		{"no revision", []TrafficSplit{{Percent: 100}}, 1},
+		{"invalid tag", []TrafficSplit{{Revision: "latest", Percent: 100, Tag: "Not_A_Label"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateTraffic(tt.traffic); len(errs) != tt.errs {
+				t.Errorf("validateTraffic() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
+
+func Test_validateStrategy(t *testing.T) {
+	split := []TrafficSplit{{Revision: "latest", Percent: 100}}
+	tests := []struct {
+		name   string
+		deploy DeploySpec
+		errs   int
+	}{
+		{"default", DeploySpec{}, 0},
+		{"latest", DeploySpec{Strategy: StrategyLatest, Traffic: split}, 0},
+		{"blue-green", DeploySpec{Strategy: StrategyBlueGreen}, 0},
+		{"blue-green split", DeploySpec{Strategy: StrategyBlueGreen, Traffic: split}, 1},
+		{"unknown", DeploySpec{Strategy: "canary"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateStrategy(tt.deploy); len(errs) != tt.errs {
+				t.Errorf("validateStrategy() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
@@ -176,6 +176,65 @@ func TestFunction_ImageName(t *testing.T) {
 	}
 }
 
+// Test_LabelsMapOf ensures that the labels of a scope are those of the scope
+// and of both scopes, and that the default labels are of each.
+func Test_LabelsMapOf(t *testing.T) {
+	costCenter, team, scaling := "cost-center", "team", "scaling"
+	value := "value"
+	f := Function{
+		Name:    "some-function",
+		Runtime: "golang",
+		Deploy: DeploySpec{Labels: []Label{
+			{Key: &costCenter, Value: &value, Scope: ScopeService},
+			{Key: &team, Value: &value},
+			{Key: &scaling, Value: &value, Scope: ScopeRevision},
+		}},
+	}
+	tests := map[string][]string{
+		ScopeService:  {costCenter, team},
+		ScopeRevision: {team, scaling},
+		ScopeBoth:     {costCenter, team, scaling},
+	}
+	for scope, keys := range tests {
+		t.Run(scope, func(t *testing.T) {
+			got, err := f.LabelsMapOf(scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := expectedDefaultLabels(f)
+			for _, k := range keys {
+				expected[k] = value
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected labels %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+// Test_AnnotationsOf ensures that the annotations of a scope are those of
+// both scopes with those of the scope alone, which take precedence.
+func Test_AnnotationsOf(t *testing.T) {
+	f := Function{Deploy: DeploySpec{
+		Annotations:         map[string]string{"a": "1", "b": "2"},
+		ServiceAnnotations:  map[string]string{"b": "service", "c": "3"},
+		RevisionAnnotations: map[string]string{"d": "4"},
+	}}
+	tests := map[string]map[string]string{
+		ScopeService:  {"a": "1", "b": "service", "c": "3"},
+		ScopeRevision: {"a": "1", "b": "2", "d": "4"},
+		ScopeBoth:     {"a": "1", "b": "2"},
+	}
+	for scope, expected := range tests {
+		if got := f.AnnotationsOf(scope); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected the annotations of %v %v, got %v", scope, expected, got)
+		}
+	}
+	if f.Deploy.Annotations["b"] != "2" {
+		t.Fatal("expected the function's annotations unmodified")
+	}
+}
+
 func Test_LabelsMap(t *testing.T) {
 	key1 := "key1"
 	key2 := "key2"
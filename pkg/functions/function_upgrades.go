@@ -0,0 +1,102 @@
+package functions
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ImageUpgrade is a newer patch version of an image upon which the function
+// is built, as referenced by its func.yaml.
+type ImageUpgrade struct {
+	// Field of func.yaml which references the image, for example
+	// build.runImage or build.builderImages.pack.
+	Field string `json:"field" yaml:"field"`
+	Image string `json:"image" yaml:"image"`
+	// Latest is the image of the newest tag of the same major and minor
+	// version, and of the same variant (such as "-slim").
+	Latest string `json:"latest" yaml:"latest"`
+}
+
+// baseImages of the function by the field of func.yaml referencing each,
+// for those defined.
+func (f Function) baseImages() map[string]string {
+	images := map[string]string{}
+	for builder, image := range f.Build.BuilderImages {
+		if image != "" {
+			images["build.builderImages."+builder] = image
+		}
+	}
+	if f.Build.BaseImage != "" {
+		images["build.baseImage"] = f.Build.BaseImage
+	}
+	if f.Build.RunImage != "" {
+		images["build.runImage"] = f.Build.RunImage
+	}
+	return images
+}
+
+// ApplyImageUpgrades to the function, replacing each image upgraded with its
+// latest version.
+func (f Function) ApplyImageUpgrades(uu []ImageUpgrade) Function {
+	for _, u := range uu {
+		switch {
+		case u.Field == "build.baseImage":
+			f.Build.BaseImage = u.Latest
+		case u.Field == "build.runImage":
+			f.Build.RunImage = u.Latest
+		case strings.HasPrefix(u.Field, "build.builderImages."):
+			builderImages := make(map[string]string, len(f.Build.BuilderImages))
+			for k, v := range f.Build.BuilderImages {
+				builderImages[k] = v
+			}
+			builderImages[strings.TrimPrefix(u.Field, "build.builderImages.")] = u.Latest
+			f.Build.BuilderImages = builderImages
+		}
+	}
+	return f
+}
+
+// versionTagRegex matches tags of a major, minor and optional patch version,
+// with an optional "v" prefix and a suffix naming a variant of the image,
+// such as 3.13.2-slim or v1.24.2.
+var versionTagRegex = regexp.MustCompile(`^(v?)(\d+)\.(\d+)(?:\.(\d+))?(.*)$`)
+
+type versionTag struct {
+	prefix, suffix      string
+	major, minor, patch int
+}
+
+func parseVersionTag(tag string) (v versionTag, ok bool) {
+	m := versionTagRegex.FindStringSubmatch(tag)
+	if m == nil || m[4] == "" {
+		return v, false // not a version, or one of a minor version alone
+	}
+	v.prefix, v.suffix = m[1], m[5]
+	v.major, _ = strconv.Atoi(m[2])
+	v.minor, _ = strconv.Atoi(m[3])
+	v.patch, _ = strconv.Atoi(m[4])
+	return v, true
+}
+
+// LatestPatch of the tag among the tags of its repository: that of the
+// greatest patch of the same major and minor version and variant, or "" if
+// there is none newer, or the tag is not of a patch version.  Tags of a
+// minor version alone (3.13-slim) and such as "latest" are those to which
+// the publisher pushes each patch, and so are never upgraded.
+func LatestPatch(tag string, tags []string) string {
+	current, ok := parseVersionTag(tag)
+	if !ok {
+		return ""
+	}
+	latest, patch := "", current.patch
+	for _, t := range tags {
+		v, ok := parseVersionTag(t)
+		if !ok || v.prefix != current.prefix || v.suffix != current.suffix ||
+			v.major != current.major || v.minor != current.minor || v.patch <= patch {
+			continue
+		}
+		latest, patch = t, v.patch
+	}
+	return latest
+}
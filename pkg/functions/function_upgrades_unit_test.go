@@ -0,0 +1,42 @@
+package functions
+
+import (
+	"testing"
+)
+
+func TestLatestPatch(t *testing.T) {
+	tags := []string{"latest", "3.13", "3.13-slim", "3.13.1-slim", "3.13.2", "3.13.10-slim", "3.14.0-slim", "v3.13.4-slim"}
+	tests := []struct {
+		tag, latest string
+	}{
+		{"3.13.1-slim", "3.13.10-slim"},
+		{"3.13.10-slim", ""},
+		{"3.13.0", "3.13.2"},
+		{"v3.13.0-slim", "v3.13.4-slim"},
+		{"3.13-slim", ""},
+		{"latest", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if latest := LatestPatch(tt.tag, tags); latest != tt.latest {
+				t.Errorf("LatestPatch(%q) = %q, want %q", tt.tag, latest, tt.latest)
+			}
+		})
+	}
+}
+
+func TestFunction_ApplyImageUpgrades(t *testing.T) {
+	builderImages := map[string]string{"pack": "builder:1.0.0"}
+	f := Function{Build: BuildSpec{BuilderImages: builderImages, BaseImage: "base:1.0.0", RunImage: "run:1.0.0"}}
+	f = f.ApplyImageUpgrades([]ImageUpgrade{
+		{Field: "build.builderImages.pack", Latest: "builder:1.0.1"},
+		{Field: "build.baseImage", Latest: "base:1.0.2"},
+		{Field: "build.runImage", Latest: "run:1.0.3"},
+	})
+	if f.Build.BuilderImages["pack"] != "builder:1.0.1" || f.Build.BaseImage != "base:1.0.2" || f.Build.RunImage != "run:1.0.3" {
+		t.Fatalf("expected the images upgraded, got %+v", f.Build)
+	}
+	if builderImages["pack"] != "builder:1.0.0" {
+		t.Error("expected the builder images of the original function unchanged")
+	}
+}
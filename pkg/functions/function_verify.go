@@ -0,0 +1,78 @@
+package functions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// VerifySpec defines the signature policy the function's image must satisfy
+// before it is deployed.  Either a public key or a keyless (certificate)
+// identity is required.
+type VerifySpec struct {
+	// Key is the cosign public key used to verify the image signature, either
+	// as a path relative to the function root or as an inline PEM block.
+	Key string `yaml:"key,omitempty"`
+
+	// Identity required of the keyless signing certificate (its email or URI
+	// subject alternative name), for example
+	// "https://github.com/org/repo/.github/workflows/release.yaml@refs/heads/main"
+	Identity string `yaml:"identity,omitempty"`
+
+	// IdentityRegexp is a regular expression the identity of the keyless
+	// signing certificate must match.  Alternative to Identity.
+	IdentityRegexp string `yaml:"identityRegexp,omitempty"`
+
+	// Issuer is the OIDC issuer required of the keyless signing certificate,
+	// for example "https://token.actions.githubusercontent.com"
+	Issuer string `yaml:"issuer,omitempty"`
+
+	// IssuerRegexp is a regular expression the OIDC issuer of the keyless
+	// signing certificate must match.  Alternative to Issuer.
+	IssuerRegexp string `yaml:"issuerRegexp,omitempty"`
+
+	// Attestations lists in-toto predicate types (for example
+	// "https://slsa.dev/provenance/v1") for which a verified attestation of
+	// the image must exist in addition to its signature.
+	Attestations []string `yaml:"attestations,omitempty"`
+}
+
+// Keyless returns true if the policy verifies certificate identities rather
+// than a public key.
+func (v VerifySpec) Keyless() bool {
+	return v.Key == ""
+}
+
+// validateVerify checks the signature policy is complete and unambiguous
+// Returns array of error messages, empty if no errors are found
+func validateVerify(v *VerifySpec) (errs []string) {
+	if v == nil {
+		return
+	}
+	keyless := v.Identity != "" || v.IdentityRegexp != "" || v.Issuer != "" || v.IssuerRegexp != ""
+	switch {
+	case v.Key != "" && keyless:
+		errs = append(errs, "deploy.verify may specify either a key or a keyless identity, not both")
+	case v.Key == "" && !keyless:
+		errs = append(errs, "deploy.verify requires either a key or a keyless identity and issuer")
+	case keyless:
+		if v.Identity == "" && v.IdentityRegexp == "" {
+			errs = append(errs, "deploy.verify keyless verification requires identity or identityRegexp")
+		}
+		if v.Issuer == "" && v.IssuerRegexp == "" {
+			errs = append(errs, "deploy.verify keyless verification requires issuer or issuerRegexp")
+		}
+	}
+	if _, err := regexp.Compile(v.IdentityRegexp); err != nil {
+		errs = append(errs, fmt.Sprintf("deploy.verify.identityRegexp is not a valid regular expression: %v", err))
+	}
+	if _, err := regexp.Compile(v.IssuerRegexp); err != nil {
+		errs = append(errs, fmt.Sprintf("deploy.verify.issuerRegexp is not a valid regular expression: %v", err))
+	}
+	for i, a := range v.Attestations {
+		if strings.TrimSpace(a) == "" {
+			errs = append(errs, fmt.Sprintf("deploy.verify attestation entry #%d is empty", i))
+		}
+	}
+	return
+}
@@ -0,0 +1,31 @@
+package functions
+
+import (
+	"testing"
+)
+
+func Test_validateVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		verify *VerifySpec
+		errs   int
+	}{
+		{"no policy", nil, 0},
+		{"key", &VerifySpec{Key: "cosign.pub"}, 0},
+		{"keyless", &VerifySpec{Identity: "dev@example.com", Issuer: "https://accounts.google.com"}, 0},
+		{"keyless regexps", &VerifySpec{IdentityRegexp: ".*@example.com", IssuerRegexp: "^https://"}, 0},
+		{"empty", &VerifySpec{}, 1},
+		{"key and identity", &VerifySpec{Key: "cosign.pub", Identity: "dev@example.com"}, 1},
+		{"identity without issuer", &VerifySpec{Identity: "dev@example.com"}, 1},
+		{"issuer without identity", &VerifySpec{Issuer: "https://accounts.google.com"}, 1},
+		{"invalid regexp", &VerifySpec{IdentityRegexp: "(", Issuer: "https://accounts.google.com"}, 1},
+		{"empty attestation", &VerifySpec{Key: "cosign.pub", Attestations: []string{"https://slsa.dev/provenance/v1", " "}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateVerify(tt.verify); len(errs) != tt.errs {
+				t.Errorf("validateVerify() = %v\n got %d errors but want %d", errs, len(errs), tt.errs)
+			}
+		})
+	}
+}
@@ -1,12 +1,19 @@
 package functions
 
-import "fmt"
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
 
 type Volume struct {
 	Secret                *string                `yaml:"secret,omitempty" jsonschema:"oneof_required=secret"`
 	ConfigMap             *string                `yaml:"configMap,omitempty" jsonschema:"oneof_required=configmap"`
 	PersistentVolumeClaim *PersistentVolumeClaim `yaml:"persistentVolumeClaim,omitempty" jsonschema:"oneof_required=persistentVolumeClaim"`
 	EmptyDir              *EmptyDir              `yaml:"emptyDir,omitempty" jsonschema:"oneof_required=emptyDir"`
+	Projected             *Projected             `yaml:"projected,omitempty" jsonschema:"oneof_required=projected"`
 	Path                  *string                `yaml:"path,omitempty"`
 }
 
@@ -39,6 +46,45 @@ type EmptyDir struct {
 	SizeLimit *string `yaml:"sizeLimit,omitempty"`
 }
 
+// Projected volume, projecting its sources into the same directory.
+// More info: https://kubernetes.io/docs/concepts/storage/projected-volumes
+type Projected struct {
+	// sources projected, each exactly one of a secret, a config map or a
+	// service account token.
+	Sources []ProjectedSource `yaml:"sources"`
+}
+
+type ProjectedSource struct {
+	// secret of which the keys are projected, each as a file.
+	Secret *string `yaml:"secret,omitempty"`
+	// configMap of which the keys are projected, each as a file.
+	ConfigMap *string `yaml:"configMap,omitempty"`
+	// serviceAccountToken projected of the function's service account.
+	ServiceAccountToken *ServiceAccountToken `yaml:"serviceAccountToken,omitempty"`
+}
+
+type ServiceAccountToken struct {
+	// path of the token's file, relative to the volume's.
+	Path string `yaml:"path"`
+	// audience of the token, by default the API server's.
+	Audience string `yaml:"audience,omitempty"`
+	// expirationSeconds of the token, after which it is rotated.  The
+	// default is of an hour, and it must not be less than ten minutes.
+	ExpirationSeconds *int64 `yaml:"expirationSeconds,omitempty" jsonschema_extras:"minimum=600"`
+}
+
+func (s ProjectedSource) String() string {
+	switch {
+	case s.Secret != nil:
+		return fmt.Sprintf("Secret \"%s\"", *s.Secret)
+	case s.ConfigMap != nil:
+		return fmt.Sprintf("ConfigMap \"%s\"", *s.ConfigMap)
+	case s.ServiceAccountToken != nil:
+		return fmt.Sprintf("ServiceAccountToken \"%s\"", s.ServiceAccountToken.Path)
+	}
+	return "no source type"
+}
+
 func (v Volume) String() string {
 	var result string
 	if v.ConfigMap != nil {
@@ -58,6 +104,12 @@ func (v Volume) String() string {
 		if v.EmptyDir.SizeLimit != nil {
 			result += fmt.Sprintf(" with size limit \"%s\"", *v.EmptyDir.SizeLimit)
 		}
+	} else if v.Projected != nil {
+		sources := make([]string, len(v.Projected.Sources))
+		for i, s := range v.Projected.Sources {
+			sources[i] = s.String()
+		}
+		result = fmt.Sprintf("Projected volume of %s", strings.Join(sources, ", "))
 	} else {
 		result = "No volume type"
 	}
@@ -80,6 +132,8 @@ func (v Volume) String() string {
 //     path: /etc/secret-volume
 //   - emptyDir: {}                                         # mount EmptyDir as Volume
 //     path: /etc/configMap-volume
+//   - projected: { sources: [ { secret: example-secret } ] } # mount projected sources as Volume
+//     path: /etc/projected-volume
 func validateVolumes(volumes []Volume) (errors []string) {
 
 	for i, vol := range volumes {
@@ -104,6 +158,16 @@ func validateVolumes(volumes []Volume) (errors []string) {
 			if vol.EmptyDir.Medium != StorageMediumDefault && vol.EmptyDir.Medium != StorageMediumMemory {
 				errors = append(errors, fmt.Sprintf("volume entry #%d (%s) has invalid storage medium (%s)", i, vol, vol.EmptyDir.Medium))
 			}
+			if vol.EmptyDir.SizeLimit != nil {
+				if _, err := resource.ParseQuantity(*vol.EmptyDir.SizeLimit); err != nil {
+					errors = append(errors, fmt.Sprintf("volume entry #%d (%s) has invalid size limit (%s); %s", i, vol, *vol.EmptyDir.SizeLimit, err))
+				}
+			}
+		}
+
+		if vol.Projected != nil {
+			numVolumes++
+			errors = append(errors, validateProjected(i, vol)...)
 		}
 
 		if numVolumes == 0 {
@@ -119,3 +183,33 @@ func validateVolumes(volumes []Volume) (errors []string) {
 
 	return
 }
+
+// validateProjected checks that the projected volume of the entry has sources,
+// each of exactly one type, and that its tokens are of relative paths.
+func validateProjected(i int, vol Volume) (errors []string) {
+	if len(vol.Projected.Sources) == 0 {
+		errors = append(errors, fmt.Sprintf("volume entry #%d (%s) is missing projected sources", i, vol))
+	}
+	for j, s := range vol.Projected.Sources {
+		numSources := 0
+		if s.Secret != nil {
+			numSources++
+		}
+		if s.ConfigMap != nil {
+			numSources++
+		}
+		if t := s.ServiceAccountToken; t != nil {
+			numSources++
+			if t.Path == "" || path.IsAbs(t.Path) || strings.HasPrefix(path.Clean(t.Path), "..") {
+				errors = append(errors, fmt.Sprintf("volume entry #%d (%s) has projected source #%d with invalid token path %q; it must be relative to the volume", i, vol, j, t.Path))
+			}
+			if t.ExpirationSeconds != nil && *t.ExpirationSeconds < 600 {
+				errors = append(errors, fmt.Sprintf("volume entry #%d (%s) has projected source #%d with token expiration of %d seconds; it must not be less than 600", i, vol, j, *t.ExpirationSeconds))
+			}
+		}
+		if numSources != 1 {
+			errors = append(errors, fmt.Sprintf("volume entry #%d (%s) has projected source #%d which must specify exactly one source type", i, vol, j))
+		}
+	}
+	return
+}
@@ -2,6 +2,8 @@ package functions
 
 import (
 	"testing"
+
+	"knative.dev/pkg/ptr"
 )
 
 func Test_validateVolumes(t *testing.T) {
@@ -173,6 +175,54 @@ func Test_validateVolumes(t *testing.T) {
 			},
 			2,
 		},
+		{
+			"correct entry - projected volume",
+			[]Volume{
+				{
+					Projected: &Projected{Sources: []ProjectedSource{
+						{Secret: &secret},
+						{ConfigMap: &cm},
+						{ServiceAccountToken: &ServiceAccountToken{Path: "token", Audience: "vault"}},
+					}},
+					Path: &path,
+				},
+			},
+			0,
+		},
+		{
+			"incorrect entry - projected volume without sources",
+			[]Volume{
+				{
+					Projected: &Projected{},
+					Path:      &path,
+				},
+			},
+			1,
+		},
+		{
+			"incorrect entry - projected sources of no, two, and invalid tokens",
+			[]Volume{
+				{
+					Projected: &Projected{Sources: []ProjectedSource{
+						{},
+						{Secret: &secret, ConfigMap: &cm},
+						{ServiceAccountToken: &ServiceAccountToken{Path: "/token", ExpirationSeconds: ptr.Int64(60)}},
+					}},
+					Path: &path,
+				},
+			},
+			4,
+		},
+		{
+			"incorrect entry - emptyDir of invalid size limit",
+			[]Volume{
+				{
+					EmptyDir: &EmptyDir{SizeLimit: ptr.String("lots")},
+					Path:     &path,
+				},
+			},
+			1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -251,6 +301,17 @@ func Test_validateVolumesString(t *testing.T) {
 			Volume{},
 			"No volume type",
 		},
+		{
+			"volume projected with path",
+			Volume{
+				Projected: &Projected{Sources: []ProjectedSource{
+					{Secret: &secret},
+					{ServiceAccountToken: &ServiceAccountToken{Path: "token"}},
+				}},
+				Path: &path,
+			},
+			"Projected volume of Secret \"secret\", ServiceAccountToken \"token\" at path: \"path\"",
+		},
 	}
 
 	for _, tt := range tests {
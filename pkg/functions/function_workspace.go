@@ -0,0 +1,51 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WorkspaceFile is the manifest of a workspace: a directory of several
+// functions, such as a repository, built together.
+const WorkspaceFile = "func.workspace.yaml"
+
+// Workspace manifest, listing the functions of the workspace.
+type Workspace struct {
+	// Functions of the workspace, as the paths of their roots relative to
+	// that of the workspace.
+	Functions []string `yaml:"functions"`
+}
+
+// WorkspaceFunctions of the workspace at root: those listed by its manifest
+// (WorkspaceFile), in its order, or those found beneath root if it has none
+// (see FindFunctions).
+func WorkspaceFunctions(root string) (ff []Function, err error) {
+	data, err := os.ReadFile(filepath.Join(root, WorkspaceFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return FindFunctions(root)
+	} else if err != nil {
+		return
+	}
+	var w Workspace
+	if err = yaml.UnmarshalStrict(data, &w); err != nil {
+		return nil, fmt.Errorf("cannot read %v: %w", WorkspaceFile, err)
+	}
+	for _, path := range w.Functions {
+		if !filepath.IsLocal(path) {
+			return nil, fmt.Errorf("function %q of %v is not within the workspace", path, WorkspaceFile)
+		}
+		f, err := NewFunction(filepath.Join(root, path))
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the function at %v: %w", path, err)
+		}
+		if !f.Initialized() {
+			return nil, fmt.Errorf("function %q of %v: %w", path, WorkspaceFile, NewErrNotInitialized(f.Root))
+		}
+		ff = append(ff, f)
+	}
+	return
+}
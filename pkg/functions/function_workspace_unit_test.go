@@ -0,0 +1,49 @@
+package functions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWorkspaceFunctions ensures the functions of a workspace are those of
+// its manifest, in its order, or else those found beneath it.
+func TestWorkspaceFunctions(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "b", "c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, FunctionFile), []byte("name: "+dir+"\nruntime: go\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ff, err := WorkspaceFunctions(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ff) != 3 {
+		t.Fatalf("expected the 3 functions found, got %v", ff)
+	}
+
+	manifest := filepath.Join(root, WorkspaceFile)
+	if err = os.WriteFile(manifest, []byte("functions:\n- c\n- a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ff, err = WorkspaceFunctions(root); err != nil {
+		t.Fatal(err)
+	}
+	if len(ff) != 2 || ff[0].Name != "c" || ff[1].Name != "a" {
+		t.Fatalf("expected functions c and a, got %v", ff)
+	}
+
+	for _, data := range []string{"functions:\n- ../a\n", "functions:\n- missing\n", "function:\n- a\n"} {
+		if err = os.WriteFile(manifest, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = WorkspaceFunctions(root); err == nil {
+			t.Errorf("expected the manifest %q to be rejected", data)
+		}
+	}
+}
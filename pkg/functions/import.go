@@ -0,0 +1,226 @@
+package functions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"knative.dev/func/pkg/filesystem"
+	"knative.dev/func/pkg/scaffolding"
+)
+
+// Import the existing source code at the given git repository as a new
+// function at cfg.Root.  The uri is of the form <url>[#ref][/subdir], where
+// ref is a branch, tag or commit (the default branch if omitted), and subdir
+// is the directory of the repository containing the code (the repository's
+// root if omitted).  For example:
+//
+//	https://github.com/alice/services.git#release/1.0/api
+//	https://github.com/alice/services.git#/api
+//
+// A func.yaml in the imported code is retained, with the name and runtime of
+// cfg taking precedence when provided.  Otherwise the runtime is detected
+// from the code's manifests (go.mod, package.json, etc).  Should the import
+// fail, the code copied is removed, such that it may be retried.
+func (c *Client) Import(ctx context.Context, cfg Function, uri string) (f Function, err error) {
+	if cfg.Root, err = filepath.Abs(cfg.Root); err != nil {
+		return
+	}
+	if err = os.MkdirAll(cfg.Root, 0755); err != nil {
+		return
+	}
+	if err = assertEmptyRoot(cfg.Root); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			_ = removeContents(cfg.Root)
+		}
+	}()
+	if cfg.Name == "" {
+		cfg.Name = nameFromPath(cfg.Root)
+	}
+
+	// Clone and copy the requested directory into the function's root
+	src, subdir, err := checkoutSource(ctx, uri)
+	if err != nil {
+		return
+	}
+	if _, err = src.Stat(subdir); err != nil {
+		return f, fmt.Errorf("directory %q not found in repository %v: %w", subdir, uri, err)
+	}
+	if err = filesystem.CopyFromFS(subdir, cfg.Root, src); err != nil {
+		return
+	}
+
+	// Merge with any func.yaml of the imported code
+	if f, err = NewFunction(cfg.Root); err != nil {
+		return
+	}
+	f.Root = cfg.Root
+	f.Name = cfg.Name
+	if cfg.Runtime != "" {
+		f.Runtime = cfg.Runtime
+	}
+	if f.Runtime == "" {
+		if f.Runtime = detectRuntime(cfg.Root); f.Runtime == "" {
+			return f, ErrRuntimeNotDetected
+		}
+	}
+	if f.SpecVersion == "" {
+		f.SpecVersion = LastSpecVersion()
+	}
+	f.Origin = Origin{Repository: uri}
+
+	if err = ensureRunDataDir(f.Root); err != nil {
+		return
+	}
+	if err = ensureFuncIgnore(f.Root); err != nil {
+		return
+	}
+	f.Created = time.Now()
+	if err = f.Write(); err != nil {
+		return
+	}
+
+	// Functions of runtimes which are scaffolded must implement one of the
+	// supported signatures in order to be built.
+	if err = c.verifyScaffolding(ctx, f); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: the imported %v code can not yet be built as a function. %v\n", f.Runtime, err)
+	}
+	return NewFunction(f.Root)
+}
+
+// removeContents of the directory, leaving the directory itself.
+func removeContents(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err = os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkoutSource clones the repository of the given uri and checks out the
+// ref it indicates, returning the work tree and subdirectory requested.
+func checkoutSource(ctx context.Context, uri string) (fs filesystem.Filesystem, subdir string, err error) {
+	url, fragment, _ := strings.Cut(uri, "#")
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), memfs.New(), &git.CloneOptions{
+		URL:               url,
+		Tags:              git.AllTags,
+		RecurseSubmodules: git.NoRecurseSubmodules,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to clone repository %v: %w", url, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return
+	}
+
+	fs = filesystem.NewBillyFilesystem(wt.Filesystem)
+
+	// Without a ref, the default branch is used
+	if fragment == "" || strings.HasPrefix(fragment, "/") {
+		return fs, cleanSubdir(fragment), nil
+	}
+
+	// The ref may itself contain slashes (release/1.0), so the longest prefix
+	// of the fragment which resolves to a commit is taken as the ref, and the
+	// remainder as the subdirectory.
+	parts := strings.Split(strings.TrimSuffix(fragment, "/"), "/")
+	for i := len(parts); i > 0; i-- {
+		ref := strings.Join(parts[:i], "/")
+		hash, ok := resolveRef(repo, ref)
+		if !ok {
+			continue
+		}
+		if err = wt.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+			return nil, "", fmt.Errorf("failed to check out %v: %w", ref, err)
+		}
+		return fs, cleanSubdir(strings.Join(parts[i:], "/")), nil
+	}
+	return nil, "", fmt.Errorf("no branch, tag or commit of repository %v matches %q", url, fragment)
+}
+
+// cleanSubdir returns the given subdirectory of a repository as a relative
+// path, "." being its root.
+func cleanSubdir(dir string) string {
+	if dir = strings.Trim(path.Clean("/"+dir), "/"); dir == "" {
+		return "."
+	}
+	return dir
+}
+
+// resolveRef to a commit: a branch of the origin, a tag or a commit hash.
+func resolveRef(repo *git.Repository, ref string) (plumbing.Hash, bool) {
+	for _, rev := range []string{"refs/remotes/origin/" + ref, "refs/tags/" + ref, ref} {
+		if hash, err := repo.ResolveRevision(plumbing.Revision(rev)); err == nil {
+			return *hash, true
+		}
+	}
+	return plumbing.ZeroHash, false
+}
+
+// runtimeManifests by which the runtime of existing code is detected, in
+// order of precedence.
+var runtimeManifests = []struct {
+	file     string
+	runtime  string
+	contains string // optional content which must also be present
+}{
+	{"go.mod", "go", ""},
+	{"Cargo.toml", "rust", ""},
+	{"pom.xml", "springboot", "spring-boot"},
+	{"pom.xml", "quarkus", ""},
+	{"tsconfig.json", "typescript", ""},
+	{"package.json", "node", ""},
+	{"pyproject.toml", "python", ""},
+	{"requirements.txt", "python", ""},
+	{"setup.py", "python", ""},
+}
+
+// detectRuntime of the code at root, or the empty string if not recognized.
+func detectRuntime(root string) string {
+	for _, m := range runtimeManifests {
+		data, err := os.ReadFile(filepath.Join(root, m.file))
+		if err != nil {
+			continue
+		}
+		if m.contains == "" || strings.Contains(string(data), m.contains) {
+			return m.runtime
+		}
+	}
+	return ""
+}
+
+// verifyScaffolding of the function can be written, if its runtime is one
+// which is scaffolded.
+func (c *Client) verifyScaffolding(ctx context.Context, f Function) error {
+	dir, err := os.MkdirTemp("", "func-scaffolding")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	err = c.Scaffold(ctx, f, dir)
+	var notImplemented scaffolding.ErrDetectorNotImplemented
+	var notRecognized scaffolding.ErrRuntimeNotRecognized
+	if errors.As(err, &notImplemented) || errors.As(err, &notRecognized) {
+		return nil // not a scaffolded runtime
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package functions_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// newSourceRepo creates a git repository with a commit of the given files,
+// the commit being both the default branch and the branch "release/1.0".
+// A second commit of the files "next" is then made to the default branch.
+func newSourceRepo(t *testing.T, files, next map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(files map[string]string) plumbing.Hash {
+		for name, content := range files {
+			path := filepath.Join(root, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := wt.Add("."); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: "xyz", Email: "xyz@abc.com", When: time.Now()}
+		hash, err := wt.Commit("commit", &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	hash := commit(files)
+	if err = repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/release/1.0", hash)); err != nil {
+		t.Fatal(err)
+	}
+	commit(next)
+	return root
+}
+
+// TestClient_Import ensures that a function can be created from a
+// subdirectory of an existing repository at a given ref, with its runtime
+// detected.
+func TestClient_Import(t *testing.T) {
+	source := newSourceRepo(t,
+		map[string]string{
+			"README.md":                      "services",
+			"services/api/go.mod":            "module function\n\ngo 1.24\n",
+			"services/api/handle.go":         "package function\n\nimport \"net/http\"\n\nfunc Handle(w http.ResponseWriter, r *http.Request) {}\n",
+			"services/worker/pyproject.toml": "[project]\nname = \"worker\"\n",
+		},
+		map[string]string{"services/api/next.go": "package function\n"})
+
+	root := filepath.Join(t.TempDir(), "api")
+	client := fn.New()
+	f, err := client.Import(context.Background(), fn.Function{Root: root}, source+"#release/1.0/services/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "api" || f.Runtime != "go" {
+		t.Fatalf("expected go function 'api', got %q function %q", f.Runtime, f.Name)
+	}
+	if !f.Initialized() {
+		t.Fatal("expected the imported function to be initialized")
+	}
+	if f.Origin.Repository != source+"#release/1.0/services/api" {
+		t.Fatalf("expected the origin of the function to be the repository, got %+v", f.Origin)
+	}
+	for _, name := range []string{"handle.go", "go.mod", fn.FunctionFile, fn.IgnoreFile} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("expected %v to be written. %v", name, err)
+		}
+	}
+	for _, name := range []string{"next.go", "README.md", "services"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %v not to be imported", name)
+		}
+	}
+
+	// The default branch, with a directory detected as python
+	root = filepath.Join(t.TempDir(), "worker")
+	if f, err = client.Import(context.Background(), fn.Function{Root: root}, source+"#/services/worker"); err != nil {
+		t.Fatal(err)
+	}
+	if f.Runtime != "python" {
+		t.Fatalf("expected a python function, got %q", f.Runtime)
+	}
+}
+
+// TestClient_ImportFuncYAML ensures that the func.yaml of imported code is
+// retained, with the name of the new function taking precedence.
+func TestClient_ImportFuncYAML(t *testing.T) {
+	source := newSourceRepo(t,
+		map[string]string{
+			"func.yaml": "specVersion: 0.36.0\nname: original\nruntime: node\nregistry: example.com/alice\n",
+			"index.js":  "module.exports = function () {}\n",
+		}, map[string]string{"next.js": ""})
+
+	root := filepath.Join(t.TempDir(), "imported")
+	f, err := fn.New().Import(context.Background(), fn.Function{Root: root}, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "imported" || f.Runtime != "node" || f.Registry != "example.com/alice" {
+		t.Fatalf("expected func.yaml to be merged, got name %q runtime %q registry %q", f.Name, f.Runtime, f.Registry)
+	}
+	if _, err = os.Stat(filepath.Join(root, "next.js")); err != nil {
+		t.Error("expected the default branch to be imported")
+	}
+}
+
+// TestClient_ImportErrors ensures that an unknown ref, a missing
+// subdirectory and an undetectable runtime are reported.
+func TestClient_ImportErrors(t *testing.T) {
+	source := newSourceRepo(t, map[string]string{"docs/README.md": ""}, map[string]string{"docs/NOTES.md": ""})
+	client := fn.New()
+
+	for _, uri := range []string{source + "#no-such-ref", source + "#/no-such-dir"} {
+		if _, err := client.Import(context.Background(), fn.Function{Root: t.TempDir()}, uri); err == nil {
+			t.Errorf("expected an error importing %v", uri)
+		}
+	}
+	root := filepath.Join(t.TempDir(), "docs")
+	_, err := client.Import(context.Background(), fn.Function{Root: root}, source+"#/docs")
+	if !errors.Is(err, fn.ErrRuntimeNotDetected) {
+		t.Fatalf("expected ErrRuntimeNotDetected, got %v", err)
+	}
+
+	// The code copied is removed, such that the import may be retried with
+	// the runtime given.
+	if entries, err := os.ReadDir(root); err != nil || len(entries) != 0 {
+		t.Fatalf("expected the failed import to leave %v empty, got %v (%v)", root, entries, err)
+	}
+	if _, err = client.Import(context.Background(), fn.Function{Root: root, Runtime: "go"}, source+"#/docs"); err != nil {
+		t.Fatalf("expected the import to be retried with the runtime given, got %v", err)
+	}
+}
@@ -1,17 +1,22 @@
 package functions
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/gorilla/websocket"
 
 	"github.com/google/uuid"
 )
@@ -25,6 +30,24 @@ const (
 	DefaultInvokeFormat      = "http"
 )
 
+// Protocols of streamed responses which may be consumed by InvokeStream.
+const (
+	// StreamSSE consumes a stream of Server-Sent Events
+	// (https://html.spec.whatwg.org/multipage/server-sent-events.html)
+	StreamSSE = "sse"
+	// StreamWebSocket upgrades the connection to a WebSocket, sending the
+	// message's data and consuming the messages which follow.
+	StreamWebSocket = "websocket"
+)
+
+// StreamMessage is a single message of a streamed response: either a
+// Server-Sent Event or a WebSocket message.
+type StreamMessage struct {
+	Event string // Event type (SSE only). Empty is the default "message".
+	ID    string // Event ID (SSE only).
+	Data  string
+}
+
 // InvokeMesage is the message used by the convenience method Invoke to provide
 // a simple way to trigger the execution of a function during development.
 type InvokeMessage struct {
@@ -98,13 +121,23 @@ func invoke(ctx context.Context, c *Client, f Function, target string, m InvokeM
 	case "http":
 		return sendHttp(ctx, route, m, c.transport, verbose)
 	case "cloudevent":
+		var evt *cloudevents.Event
 		switch m.RequestType {
 		case "POST":
-			body, err = sendEvent(ctx, route, m, c.transport, verbose)
+			evt, err = sendEvent(ctx, route, m, c.transport, verbose)
 		case "GET":
 			// Construct a special CloudEvents GET request.
 			// This will be used most likely only for very special cases
-			body, err = sendGetEvent(ctx, route, m, c.transport, verbose)
+			evt, err = sendGetEvent(ctx, route, m, c.transport, verbose)
+		}
+		if err != nil || evt == nil { // Check for nil in case no event is returned
+			return
+		}
+		body = evt.String()
+		if f.Events.Validate {
+			// The returned event is one emitted by the function, and is therefore
+			// checked against its declared contract.
+			err = f.ValidateEvent(*evt)
 		}
 	default:
 		err = fmt.Errorf("format '%v' not supported", format)
@@ -163,14 +196,14 @@ func invocationRoute(ctx context.Context, c *Client, f Function, target string)
 }
 
 // sendEvent to the route populated with data in the invoke message.
-func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (resp string, err error) {
+func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (resp *cloudevents.Event, err error) {
 	event := cloudevents.NewEvent()
 	event.SetID(m.ID)
 	event.SetSource(m.Source)
 	event.SetType(m.Type)
 	err = event.SetData(m.ContentType, (m.Data))
 	if err != nil {
-		return "", fmt.Errorf("cannot set data: %w", err)
+		return nil, fmt.Errorf("cannot set data: %w", err)
 	}
 	c, err := cloudevents.NewClientHTTP(
 		cloudevents.WithTarget(route),
@@ -187,8 +220,8 @@ func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundT
 	evt, result := c.Request(cloudevents.ContextWithTarget(ctx, route), event)
 	if cloudevents.IsUndelivered(result) {
 		err = fmt.Errorf("unable to invoke: %v", result)
-	} else if evt != nil { // Check for nil in case no event is returned
-		resp = evt.String()
+	} else {
+		resp = evt
 	}
 
 	return
@@ -204,7 +237,7 @@ func sendEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundT
 // Since this is not the case for GET request, we need to specify custom protocol
 // and use a slightly different client resulting in a slightly different
 // function all together.
-func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (resp string, err error) {
+func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, verbose bool) (resp *cloudevents.Event, err error) {
 	if m.ID == "" {
 		// we're using a different Client function, we need to create an ID.
 		// ce.NewClientHTTP() sets ID if not present, ce.NewClient() doesn't
@@ -240,8 +273,8 @@ func sendGetEvent(ctx context.Context, route string, m InvokeMessage, t http.Rou
 	evt, result := c.Request(cloudevents.ContextWithTarget(ctx, route), event)
 	if cloudevents.IsUndelivered(result) {
 		err = fmt.Errorf("unable to invoke: %v", result)
-	} else if evt != nil { // Check for nil in case no event is returned
-		resp = evt.String()
+	} else {
+		resp = evt
 	}
 	return
 }
@@ -286,3 +319,196 @@ func sendHttp(ctx context.Context, route string, m InvokeMessage, t http.RoundTr
 	b, err := io.ReadAll(resp.Body)
 	return resp.Header, string(b), err
 }
+
+// invokeStream invokes the function instance in the target environment with
+// the invocation message, calling onMessage with each message of the
+// streamed response until the stream ends or the context is canceled.
+func invokeStream(ctx context.Context, c *Client, f Function, target string, m InvokeMessage, protocol string, onMessage func(StreamMessage) error, verbose bool) (err error) {
+	format := DefaultInvokeFormat
+	if f.Invoke != "" {
+		format = f.Invoke
+	}
+	if m.Format != "" {
+		format = m.Format
+	}
+	if format != "http" {
+		return fmt.Errorf("streaming is not supported for functions invoked with format '%v'", format)
+	}
+	if m.RequestType == "" {
+		m.RequestType = DefaultInvokeRequestType
+	}
+	if m.RequestType != "POST" && m.RequestType != "GET" {
+		return fmt.Errorf("http request type '%v' not supported, expected GET or POST", m.RequestType)
+	}
+
+	route, err := invocationRoute(ctx, c, f, target)
+	if err != nil {
+		return
+	}
+	if verbose {
+		fmt.Printf("Invoking function at %v, streaming %v\n", route, protocol)
+	}
+
+	switch protocol {
+	case StreamSSE:
+		return streamSSE(ctx, route, m, c.transport, onMessage)
+	case StreamWebSocket:
+		return streamWebSocket(ctx, route, m, c.transport, onMessage)
+	default:
+		return fmt.Errorf("stream protocol '%v' not supported, expected '%v' or '%v'", protocol, StreamSSE, StreamWebSocket)
+	}
+}
+
+// streamSSE sends the invoke message to the route as does sendHttp, but
+// accepting an event stream in response, which is consumed as events arrive.
+// Unlike sendHttp there is no overall timeout, as streams may be long-lived.
+func streamSSE(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, onMessage func(StreamMessage) error) error {
+	req, err := http.NewRequestWithContext(ctx, m.RequestType, route, bytes.NewReader(m.Data))
+	if err != nil {
+		return fmt.Errorf("failure to create request: %w", err)
+	}
+	req.Header.Add("Content-Type", m.ContentType)
+	req.Header.Add("Accept", "text/event-stream")
+	req.Header.Add("Cache-Control", "no-cache")
+
+	resp, err := (&http.Client{Transport: t}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > 299 {
+		return fmt.Errorf("failure invoking '%v' (HTTP %v)", route, resp.StatusCode)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "text/event-stream" {
+		return fmt.Errorf("function at '%v' did not respond with an event stream (Content-Type %q)", route, resp.Header.Get("Content-Type"))
+	}
+	err = readEvents(resp.Body, onMessage)
+	if ctx.Err() != nil {
+		return nil // canceled by the caller; the stream ends
+	}
+	return err
+}
+
+// readEvents parses the event stream, calling onMessage with each event as
+// it is dispatched.  Comments and 'retry' fields are ignored, as is an
+// event which is not terminated by a blank line when the stream ends.
+func readEvents(r io.Reader, onMessage func(StreamMessage) error) error {
+	var (
+		event StreamMessage
+		data  []string
+		id    string // the last event ID persists across events
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" { // dispatch
+			if len(data) > 0 {
+				event.ID = id
+				event.Data = strings.Join(data, "\n")
+				if err := onMessage(event); err != nil {
+					return err
+				}
+			}
+			event, data = StreamMessage{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") { // comment
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				id = value
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// streamWebSocket upgrades a connection to the route to a WebSocket,
+// sending the message's data (if any) as the first message and consuming
+// the messages received until the function closes the connection or the
+// context is canceled.  Connections are dialed with the same transport
+// settings (such as TLS verification) used for other invocations.
+func streamWebSocket(ctx context.Context, route string, m InvokeMessage, t http.RoundTripper, onMessage func(StreamMessage) error) error {
+	u, err := url.Parse(route)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+
+	dialer := &websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: time.Minute}
+	if ht := httpTransport(t); ht != nil {
+		dialer.Proxy = ht.Proxy
+		dialer.NetDialContext = ht.DialContext
+		dialer.NetDialTLSContext = ht.DialTLSContext
+		dialer.TLSClientConfig = ht.TLSClientConfig
+	}
+	conn, resp, err := dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failure upgrading '%v' to a WebSocket (HTTP %v)", route, resp.StatusCode)
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// Closing the connection when canceled ends the read loop below.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(time.Second))
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	if len(m.Data) > 0 {
+		messageType := websocket.TextMessage
+		if !utf8.Valid(m.Data) {
+			messageType = websocket.BinaryMessage
+		}
+		if err = conn.WriteMessage(messageType, m.Data); err != nil {
+			return err
+		}
+	}
+	for {
+		_, p, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		}
+		if err = onMessage(StreamMessage{Data: string(p)}); err != nil {
+			return err
+		}
+	}
+}
+
+// httpTransport returns the *http.Transport underlying t, if any.
+func httpTransport(t http.RoundTripper) *http.Transport {
+	switch t := t.(type) {
+	case *http.Transport:
+		return t
+	case interface{ HTTPTransport() *http.Transport }:
+		return t.HTTPTransport()
+	}
+	return nil
+}
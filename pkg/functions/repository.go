@@ -531,6 +531,20 @@ func (r *Repository) Write(dest string) (err error) {
 	return filesystem.CopyFromFS(".", dest, fs)
 }
 
+// origin of the repository as recorded of the functions created from its
+// templates: its URL if it has one, that from which it was loaded if remote,
+// or else its name.
+func (r *Repository) origin() string {
+	if remote := r.URL(); remote != "" {
+		return remote
+	}
+	// A scheme of a single letter is that of a Windows path's drive.
+	if u, err := url.Parse(r.uri); err == nil && len(u.Scheme) > 1 && u.Scheme != "file" {
+		return r.uri
+	}
+	return r.Name
+}
+
 // URL attempts to read the remote git origin URL of the repository.  Best
 // effort; returns empty string if the repository is not a git repo or the repo
 // has been mutated beyond recognition on disk (ex: removing the origin remote)
@@ -98,10 +98,19 @@ func (t *Templates) Write(f *Function) error {
 	}
 
 	// The function's Template
-	template, err := t.Get(f.Runtime, f.Template)
+	repoName, tplName := splitTemplateFullname(f.Template)
+	repo, err := t.client.Repositories().Get(repoName)
+	if err != nil {
+		return err
+	}
+	template, err := repo.Template(f.Runtime, tplName)
 	if err != nil {
 		return err
 	}
 
-	return template.Write(context.TODO(), f)
+	if err = template.Write(context.TODO(), f); err != nil {
+		return err
+	}
+	f.Origin = Origin{Template: tplName, Repository: repo.origin()}
+	return nil
 }
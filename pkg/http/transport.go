@@ -110,6 +110,13 @@ func (r *roundTripCloser) Close() error {
 	return r.dialer.Close()
 }
 
+// HTTPTransport returns the underlying transport, such that its dialers and
+// TLS configuration may be used for connections which bypass round trips,
+// such as WebSockets.
+func (r *roundTripCloser) HTTPTransport() *http.Transport {
+	return r.Transport
+}
+
 func newDialerWithFallback(primaryDialer ContextDialer, fallbackDialer ContextDialer) *dialerWithFallback {
 	return &dialerWithFallback{
 		primaryDialer:  primaryDialer,
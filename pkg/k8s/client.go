@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"context"
 	"fmt"
 
 	"k8s.io/client-go/dynamic"
@@ -9,21 +10,50 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// contextKey of the kubeconfig context carried by a context.Context.
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying the kubeconfig context, such that
+// clients created of it connect to the cluster of that kubeconfig context
+// rather than the current one.  An empty name is the current context.
+func WithContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// ContextFrom returns the kubeconfig context carried by ctx, empty if none,
+// being the current context.
+func ContextFrom(ctx context.Context) string {
+	name, _ := ctx.Value(contextKey{}).(string)
+	return name
+}
+
 func NewClientAndResolvedNamespace(ns string) (*kubernetes.Clientset, string, error) {
+	return NewClientAndResolvedNamespaceFrom(context.Background(), ns)
+}
+
+// NewClientAndResolvedNamespaceFrom is NewClientAndResolvedNamespace of the
+// kubeconfig context carried by ctx.
+func NewClientAndResolvedNamespaceFrom(ctx context.Context, ns string) (*kubernetes.Clientset, string, error) {
 	var err error
 	if ns == "" {
-		ns, err = GetDefaultNamespace()
+		ns, _, err = GetClientConfigFrom(ctx).Namespace()
 		if err != nil {
 			return nil, ns, err
 		}
 	}
 
-	client, err := NewKubernetesClientset()
+	client, err := NewKubernetesClientsetFrom(ctx)
 	return client, ns, err
 }
 
 func NewKubernetesClientset() (*kubernetes.Clientset, error) {
-	restConfig, err := GetClientConfig().ClientConfig()
+	return NewKubernetesClientsetFrom(context.Background())
+}
+
+// NewKubernetesClientsetFrom is NewKubernetesClientset of the kubeconfig
+// context carried by ctx.
+func NewKubernetesClientsetFrom(ctx context.Context) (*kubernetes.Clientset, error) {
+	restConfig, err := GetClientConfigFrom(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new kubernetes client: %w", err)
 	}
@@ -40,14 +70,57 @@ func NewDynamicClient() (dynamic.Interface, error) {
 	return dynamic.NewForConfig(restConfig)
 }
 
+// NewDynamicClientFrom is NewDynamicClient of the kubeconfig context carried
+// by ctx.
+func NewDynamicClientFrom(ctx context.Context) (dynamic.Interface, error) {
+	restConfig, err := GetClientConfigFrom(ctx).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new kubernetes client: %w", err)
+	}
+
+	return dynamic.NewForConfig(restConfig)
+}
+
 // GetDefaultNamespace returns default namespace
 func GetDefaultNamespace() (namespace string, err error) {
 	namespace, _, err = GetClientConfig().Namespace()
 	return
 }
 
+// GetCurrentContext returns the name of the active kubeconfig context and
+// the server address of its cluster.
+func GetCurrentContext() (name, server string, err error) {
+	return GetCurrentContextFrom(context.Background())
+}
+
+// GetCurrentContextFrom is GetCurrentContext of the kubeconfig context
+// carried by ctx, if any, being that which is active for its clients.
+func GetCurrentContextFrom(ctx context.Context) (name, server string, err error) {
+	raw, err := GetClientConfigFrom(ctx).RawConfig()
+	if err != nil {
+		return
+	}
+	if name = ContextFrom(ctx); name == "" {
+		name = raw.CurrentContext
+	}
+	c, ok := raw.Contexts[name]
+	if !ok {
+		return "", "", fmt.Errorf("kubeconfig context %q not found", name)
+	}
+	if cluster, ok := raw.Clusters[c.Cluster]; ok {
+		server = cluster.Server
+	}
+	return
+}
+
 func GetClientConfig() clientcmd.ClientConfig {
+	return GetClientConfigFrom(context.Background())
+}
+
+// GetClientConfigFrom is GetClientConfig of the kubeconfig context carried by
+// ctx, if any.
+func GetClientConfigFrom(ctx context.Context) clientcmd.ClientConfig {
 	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		clientcmd.NewDefaultClientConfigLoadingRules(),
-		&clientcmd.ConfigOverrides{})
+		&clientcmd.ConfigOverrides{CurrentContext: ContextFrom(ctx)})
 }
@@ -13,7 +13,7 @@ import (
 )
 
 func GetConfigMap(ctx context.Context, name, namespaceOverride string) (*corev1.ConfigMap, error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +54,7 @@ func ListConfigMapsNamesIfConnected(ctx context.Context, namespaceOverride strin
 }
 
 func listConfigMapsNames(ctx context.Context, namespaceOverride string) (names []string, err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,664 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/ptr"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s/labels"
+)
+
+const (
+	// containerPort on which functions listen, as they do under Knative.
+	containerPort = 8080
+
+	// deployTimeout is that of waiting for a rollout, unless the context
+	// carries another.
+	deployTimeout = 120 * time.Second
+
+	// rolloutInterval at which the rollout of a deployment is polled.
+	rolloutInterval = time.Second
+
+	// URLAnnotation of a function's Deployment, being the URL at which it is
+	// reachable, such that it is listed and described without also getting
+	// the resources by which it is exposed.
+	URLAnnotation = "function.knative.dev/url"
+)
+
+// httpRouteResource of the Gateway API, applied by the dynamic client such
+// that the Gateway API need not be a dependency.
+var httpRouteResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+
+type DeployerOpt func(*Deployer)
+
+// DeployDecorator updates the annotations and labels of the resources of a
+// function, as does that of the Knative deployer.
+type DeployDecorator interface {
+	UpdateAnnotations(fn.Function, map[string]string) map[string]string
+	UpdateLabels(fn.Function, map[string]string) map[string]string
+}
+
+// Deployer of functions as a plain Deployment and Service, optionally exposed
+// by an Ingress or an HTTPRoute, for clusters without Knative Serving.  The
+// features of Knative which require revisions or eventing, being traffic
+// splits, blue-green deployments and subscriptions, are unsupported.
+type Deployer struct {
+	// verbose logging enablement flag.
+	verbose bool
+
+	decorator DeployDecorator
+}
+
+func NewDeployer(opts ...DeployerOpt) *Deployer {
+	d := &Deployer{}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+func WithDeployerVerbose(verbose bool) DeployerOpt {
+	return func(d *Deployer) {
+		d.verbose = verbose
+	}
+}
+
+// WithDeployerDecorator updates the annotations and labels of the function's
+// Deployment and of its pods.
+func WithDeployerDecorator(decorator DeployDecorator) DeployerOpt {
+	return func(d *Deployer) {
+		d.decorator = decorator
+	}
+}
+
+// Deploy the function, creating its resources or updating them if already
+// deployed, and waiting for the rollout of its Deployment unless the context
+// requests no waiting.
+func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if namespace == "" {
+		return fn.DeploymentResult{}, fmt.Errorf("deployer requires either a target namespace or that the function be already deployed")
+	}
+	if f.Deploy.Image == "" {
+		f.Deploy.Image = f.Build.Image
+	}
+
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	var dyn dynamic.Interface
+	if f.Deploy.Expose != nil && f.Deploy.Expose.Kind == fn.ExposeHTTPRoute {
+		if dyn, err = NewDynamicClientFrom(ctx); err != nil {
+			return fn.DeploymentResult{}, err
+		}
+	}
+
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+	if _, _, err = ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps); err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	if _, _, err = ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs); err != nil {
+		return fn.DeploymentResult{}, err
+	}
+	if err = CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName); err != nil {
+		return fn.DeploymentResult{}, fmt.Errorf("k8s deployer failed to generate the Deployment: %v", err)
+	}
+
+	// Resources updated concurrently, such as by another deployment of the
+	// function, are applied anew.
+	var status fn.Status
+	err = Retry(ctx, RetryOptionsFrom(ctx), func() (err error) {
+		status, err = apply(ctx, client, dyn, f, namespace, d.decorator, nil)
+		return
+	})
+	if err != nil {
+		return fn.DeploymentResult{}, err
+	}
+
+	wait, _ := ctx.Value(fn.DeployWaitKey{}).(fn.Wait)
+	if wait != fn.WaitNone {
+		timeout, _ := ctx.Value(fn.DeployWaitTimeoutKey{}).(time.Duration)
+		if timeout <= 0 {
+			timeout = deployTimeout
+		}
+		if d.verbose {
+			fmt.Fprintln(os.Stderr, "Waiting for the Deployment to roll out")
+		}
+		if err = waitForRollout(ctx, client, namespace, f.Name, timeout); err != nil {
+			return fn.DeploymentResult{}, fmt.Errorf("k8s deployer failed to wait for the Deployment to roll out: %v", err)
+		}
+	}
+
+	url := functionURL(f, namespace)
+	if d.verbose {
+		fmt.Fprintf(os.Stderr, "Function deployed in namespace %q and exposed at URL:\n%s\n", namespace, url)
+	}
+	return fn.DeploymentResult{
+		Status:    status,
+		URL:       url,
+		Namespace: namespace,
+	}, nil
+}
+
+// Render the Deployment, Service and any Ingress or HTTPRoute which deploying
+// the function would create or update, as a YAML stream.  A server dry run
+// submits them to the cluster for admission without persisting them.
+func (d *Deployer) Render(ctx context.Context, f fn.Function, mode fn.DryRun) ([]byte, error) {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if f.Deploy.Image == "" {
+		f.Deploy.Image = f.Build.Image
+	}
+
+	var (
+		objects []runtime.Object
+		err     error
+	)
+	switch mode {
+	case fn.DryRunClient:
+		if objects, err = generateResources(f, namespace, d.decorator); err != nil {
+			return nil, err
+		}
+	case fn.DryRunServer:
+		if namespace == "" {
+			if namespace, err = GetDefaultNamespace(); err != nil {
+				return nil, err
+			}
+		}
+		client, err := NewKubernetesClientsetFrom(ctx)
+		if err != nil {
+			return nil, err
+		}
+		dyn, err := NewDynamicClientFrom(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = apply(ctx, client, dyn, f, namespace, d.decorator, func(o runtime.Object) { objects = append(objects, o) }, metav1.DryRunAll); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid dry run %q", mode)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, o := range objects {
+		if err = encodeManifest(enc, o); err != nil {
+			return nil, err
+		}
+	}
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// apply the function's resources to the namespace, creating those which do
+// not exist and updating those which do.  Each resource as admitted is
+// passed to applied, if given.  Returned is whether the function was deployed
+// or updated, by whether its Deployment existed.
+func apply(ctx context.Context, client kubernetes.Interface, dyn dynamic.Interface, f fn.Function, namespace string, decorator DeployDecorator, applied func(runtime.Object), dryRun ...string) (status fn.Status, err error) {
+	if applied == nil {
+		applied = func(runtime.Object) {}
+	}
+	deployment, err := generateDeployment(f, namespace, decorator)
+	if err != nil {
+		return
+	}
+	status = fn.Deployed
+
+	deployments := client.AppsV1().Deployments(namespace)
+	existing, err := deployments.Get(ctx, f.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		deployment, err = deployments.Create(ctx, deployment, metav1.CreateOptions{DryRun: dryRun})
+	case err == nil:
+		status = fn.Updated
+		existing.Labels = deployment.Labels
+		existing.Annotations = deployment.Annotations
+		existing.Spec.Replicas = deployment.Spec.Replicas
+		existing.Spec.Template = deployment.Spec.Template
+		deployment, err = deployments.Update(ctx, existing, metav1.UpdateOptions{DryRun: dryRun})
+	}
+	if err != nil {
+		err = fmt.Errorf("k8s deployer failed to deploy the Deployment: %w", err)
+		return
+	}
+	applied(withType(deployment, appsv1.SchemeGroupVersion.String(), "Deployment"))
+
+	service := generateService(f, namespace)
+	services := client.CoreV1().Services(namespace)
+	existingService, err := services.Get(ctx, f.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		service, err = services.Create(ctx, service, metav1.CreateOptions{DryRun: dryRun})
+	case err == nil:
+		// The cluster IP of a service is immutable
+		existingService.Labels = service.Labels
+		existingService.Spec.Selector = service.Spec.Selector
+		existingService.Spec.Ports = service.Spec.Ports
+		service, err = services.Update(ctx, existingService, metav1.UpdateOptions{DryRun: dryRun})
+	}
+	if err != nil {
+		err = fmt.Errorf("k8s deployer failed to deploy the Service: %w", err)
+		return
+	}
+	applied(withType(service, "v1", "Service"))
+
+	if f.Deploy.Expose == nil {
+		return
+	}
+	switch f.Deploy.Expose.Kind {
+	case fn.ExposeIngress:
+		ingress := generateIngress(f, namespace)
+		ingresses := client.NetworkingV1().Ingresses(namespace)
+		var existing *networkingv1.Ingress
+		existing, err = ingresses.Get(ctx, f.Name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+			ingress, err = ingresses.Create(ctx, ingress, metav1.CreateOptions{DryRun: dryRun})
+		case err == nil:
+			existing.Labels = ingress.Labels
+			existing.Spec = ingress.Spec
+			ingress, err = ingresses.Update(ctx, existing, metav1.UpdateOptions{DryRun: dryRun})
+		}
+		if err != nil {
+			err = fmt.Errorf("k8s deployer failed to deploy the Ingress: %w", err)
+			return
+		}
+		applied(withType(ingress, networkingv1.SchemeGroupVersion.String(), "Ingress"))
+	case fn.ExposeHTTPRoute:
+		route := generateHTTPRoute(f, namespace)
+		routes := dyn.Resource(httpRouteResource).Namespace(namespace)
+		var existing *unstructured.Unstructured
+		existing, err = routes.Get(ctx, f.Name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+			route, err = routes.Create(ctx, route, metav1.CreateOptions{DryRun: dryRun})
+		case err == nil:
+			route.SetResourceVersion(existing.GetResourceVersion())
+			route, err = routes.Update(ctx, route, metav1.UpdateOptions{DryRun: dryRun})
+		}
+		if err != nil {
+			err = fmt.Errorf("k8s deployer failed to deploy the HTTPRoute: %w", err)
+			return
+		}
+		applied(route)
+	}
+	return
+}
+
+// waitForRollout of the named Deployment, until its latest generation is
+// observed and all of its replicas are updated and available.
+func waitForRollout(ctx context.Context, client kubernetes.Interface, namespace, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(rolloutInterval)
+	defer ticker.Stop()
+	for {
+		deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if err == nil && rolledOut(deployment) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout after %v waiting for the Deployment %v", timeout, name)
+		case <-ticker.C:
+		}
+	}
+}
+
+// rolledOut is whether the deployment's latest generation is fully rolled
+// out.
+func rolledOut(d *appsv1.Deployment) bool {
+	if d.Status.ObservedGeneration < d.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	return d.Status.UpdatedReplicas >= replicas &&
+		d.Status.AvailableReplicas >= replicas &&
+		d.Status.Replicas == d.Status.UpdatedReplicas
+}
+
+// generateResources of the function as they would be created.
+func generateResources(f fn.Function, namespace string, decorator DeployDecorator) ([]runtime.Object, error) {
+	deployment, err := generateDeployment(f, namespace, decorator)
+	if err != nil {
+		return nil, err
+	}
+	objects := []runtime.Object{
+		withType(deployment, appsv1.SchemeGroupVersion.String(), "Deployment"),
+		withType(generateService(f, namespace), "v1", "Service"),
+	}
+	if f.Deploy.Expose != nil {
+		switch f.Deploy.Expose.Kind {
+		case fn.ExposeIngress:
+			objects = append(objects, withType(generateIngress(f, namespace), networkingv1.SchemeGroupVersion.String(), "Ingress"))
+		case fn.ExposeHTTPRoute:
+			objects = append(objects, generateHTTPRoute(f, namespace))
+		}
+	}
+	return objects, nil
+}
+
+// generateDeployment of the function, its pods selected by the function's
+// name label and run with the restricted security context with which Knative
+// runs them.  The labels and annotations of both are updated by the
+// decorator, if given.
+func generateDeployment(f fn.Function, namespace string, decorator DeployDecorator) (*appsv1.Deployment, error) {
+	runAsNonRoot := true
+	allowPrivilegeEscalation := false
+	container := corev1.Container{
+		Name:  "user-container",
+		Image: f.Deploy.Image,
+		Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: containerPort}},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot:             &runAsNonRoot,
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+	}
+	SetHealthEndpoints(f, &container)
+
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+	env, envFrom, err := ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	if err != nil {
+		return nil, err
+	}
+	// Knative provides the port to the container; here the deployer does.
+	if !hasEnv(env, "PORT") {
+		env = append(env, corev1.EnvVar{Name: "PORT", Value: fmt.Sprint(containerPort)})
+	}
+	container.Env = env
+	container.EnvFrom = envFrom
+
+	volumes, volumeMounts, err := ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+	if err != nil {
+		return nil, err
+	}
+	container.VolumeMounts = volumeMounts
+
+	if container.Resources, err = resourceRequirements(f.Deploy.Options.Resources); err != nil {
+		return nil, err
+	}
+	SetExtendedResources(f.Deploy.Options.Resources, &container)
+
+	// The Deployment is of the service scope, and its pods of the revision
+	ll, err := f.LabelsMapOf(fn.ScopeService)
+	if err != nil {
+		return nil, err
+	}
+	podLabels, err := f.LabelsMapOf(fn.ScopeRevision)
+	if err != nil {
+		return nil, err
+	}
+	if f.Domain != "" {
+		ll["func.domain"] = f.Domain
+		podLabels["func.domain"] = f.Domain
+	}
+	annotations := map[string]string{URLAnnotation: functionURL(f, namespace)}
+	for k, v := range f.AnnotationsOf(fn.ScopeService) {
+		annotations[k] = v
+	}
+	podAnnotations := f.AnnotationsOf(fn.ScopeRevision)
+	if decorator != nil {
+		ll = decorator.UpdateLabels(f, ll)
+		podLabels = decorator.UpdateLabels(f, podLabels)
+		annotations = decorator.UpdateAnnotations(f, annotations)
+		podAnnotations = decorator.UpdateAnnotations(f, podAnnotations)
+	}
+
+	replicas := int32(1)
+	if s := f.Deploy.Options.Scale; s != nil && s.Min != nil && *s.Min > 1 {
+		replicas = int32(*s.Min)
+	}
+
+	// The old revisions of a Deployment are its ReplicaSets
+	var history *int32
+	if keep := f.Deploy.Options.RevisionHistoryLimit; keep != nil {
+		history = ptr.Int32(int32(*keep))
+	}
+
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        f.Name,
+			Namespace:   namespace,
+			Labels:      ll,
+			Annotations: annotations,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas:             &replicas,
+			RevisionHistoryLimit: history,
+			Selector:             &metav1.LabelSelector{MatchLabels: selectorOf(f)},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      podLabels,
+					Annotations: podAnnotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers:         []corev1.Container{container},
+					ServiceAccountName: f.Deploy.ServiceAccountName,
+					Volumes:            volumes,
+				},
+			},
+		},
+	}
+	SetScheduling(f.Deploy.Scheduling, &d.Spec.Template.Spec)
+	return d, nil
+}
+
+// generateService of the function, routing port 80 to that of its pods.
+func generateService(f fn.Function, namespace string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: namespace,
+			Labels:    selectorOf(f),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: selectorOf(f),
+			Ports: []corev1.ServicePort{{
+				Name:       "http",
+				Port:       80,
+				TargetPort: intstr.FromInt32(containerPort),
+			}},
+		},
+	}
+}
+
+// generateIngress exposing the function's Service at its host.
+func generateIngress(f fn.Function, namespace string) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      f.Name,
+			Namespace: namespace,
+			Labels:    selectorOf(f),
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: exposedHost(f, namespace),
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: f.Name,
+									Port: networkingv1.ServiceBackendPort{Number: 80},
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+	if class := f.Deploy.Expose.IngressClass; class != "" {
+		ingress.Spec.IngressClassName = &class
+	}
+	return ingress
+}
+
+// generateHTTPRoute attaching the function's Service to the gateway at its
+// host.
+func generateHTTPRoute(f fn.Function, namespace string) *unstructured.Unstructured {
+	parent := map[string]any{"name": f.Deploy.Expose.Gateway}
+	if ns, name, ok := strings.Cut(f.Deploy.Expose.Gateway, "/"); ok {
+		parent = map[string]any{"namespace": ns, "name": name}
+	}
+	spec := map[string]any{
+		"parentRefs": []any{parent},
+		"rules": []any{map[string]any{
+			"backendRefs": []any{map[string]any{"name": f.Name, "port": int64(80)}},
+		}},
+	}
+	if host := exposedHost(f, namespace); host != "" {
+		spec["hostnames"] = []any{host}
+	}
+	route := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": httpRouteResource.GroupVersion().String(),
+		"kind":       "HTTPRoute",
+		"metadata": map[string]any{
+			"name":      f.Name,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}}
+	route.SetLabels(selectorOf(f))
+	return route
+}
+
+// selectorOf the function's resources, by the label of its name.
+func selectorOf(f fn.Function) map[string]string {
+	return map[string]string{labels.FunctionNameKey: f.Name}
+}
+
+// exposedHost of the function, given or else of its domain, empty if neither.
+func exposedHost(f fn.Function, namespace string) string {
+	if f.Deploy.Expose == nil {
+		return ""
+	}
+	if f.Deploy.Expose.Host != "" {
+		return f.Deploy.Expose.Host
+	}
+	if f.Domain != "" {
+		return fmt.Sprintf("%v.%v.%v", f.Name, namespace, f.Domain)
+	}
+	return ""
+}
+
+// functionURL of the function: that of its exposed host, else that of its
+// Service within the cluster.
+func functionURL(f fn.Function, namespace string) string {
+	if host := exposedHost(f, namespace); host != "" {
+		return "http://" + host
+	}
+	return fmt.Sprintf("http://%v.%v.svc.cluster.local", f.Name, namespace)
+}
+
+// resourceRequirements of the container, of the function's resources options.
+func resourceRequirements(o *fn.ResourcesOptions) (r corev1.ResourceRequirements, err error) {
+	if o == nil {
+		return
+	}
+	set := func(rl *corev1.ResourceList, name corev1.ResourceName, value *string) {
+		if value == nil || err != nil {
+			return
+		}
+		var q resource.Quantity
+		if q, err = resource.ParseQuantity(*value); err != nil {
+			err = fmt.Errorf("invalid %v %q: %w", name, *value, err)
+			return
+		}
+		if *rl == nil {
+			*rl = corev1.ResourceList{}
+		}
+		(*rl)[name] = q
+	}
+	if o.Requests != nil {
+		set(&r.Requests, corev1.ResourceCPU, o.Requests.CPU)
+		set(&r.Requests, corev1.ResourceMemory, o.Requests.Memory)
+	}
+	if o.Limits != nil {
+		set(&r.Limits, corev1.ResourceCPU, o.Limits.CPU)
+		set(&r.Limits, corev1.ResourceMemory, o.Limits.Memory)
+	}
+	return
+}
+
+func hasEnv(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withType sets the type of the object, which typed clients leave unset.
+func withType[T runtime.Object](o T, apiVersion, kind string) T {
+	o.GetObjectKind().SetGroupVersionKind(schema.FromAPIVersionAndKind(apiVersion, kind))
+	return o
+}
+
+// encodeManifest of the object as a document of the YAML stream, without its
+// status or the fields managed by the cluster.
+func encodeManifest(enc *yaml.Encoder, obj runtime.Object) error {
+	manifest, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(manifest, "status")
+	if metadata, ok := manifest["metadata"].(map[string]any); ok {
+		delete(metadata, "creationTimestamp")
+		delete(metadata, "managedFields")
+	}
+	if spec, ok := manifest["spec"].(map[string]any); ok {
+		if template, ok := spec["template"].(map[string]any); ok {
+			if metadata, ok := template["metadata"].(map[string]any); ok {
+				delete(metadata, "creationTimestamp")
+			}
+		}
+	}
+	return enc.Encode(manifest)
+}
@@ -0,0 +1,182 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s/labels"
+)
+
+// TestApply ensures that the resources of a function are created on its first
+// deployment and updated thereafter, the Ingress exposing it at the host of
+// its domain.
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	min := int64(2)
+	f := fn.Function{
+		Name:    "myfn",
+		Runtime: "go",
+		Domain:  "example.com",
+		Deploy: fn.DeploySpec{
+			Image:    "example.com/myfn:latest",
+			Deployer: fn.DeployerK8s,
+			Expose:   &fn.ExposeSpec{Kind: fn.ExposeIngress},
+			Options:  fn.Options{Scale: &fn.ScaleOptions{Min: &min}},
+		},
+	}
+
+	status, err := apply(ctx, client, nil, f, "ns", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != fn.Deployed {
+		t.Fatalf("expected deployed, got %v", status)
+	}
+	d, err := client.AppsV1().Deployments("ns").Get(ctx, "myfn", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *d.Spec.Replicas != 2 {
+		t.Errorf("expected the minimum scale of replicas, got %v", *d.Spec.Replicas)
+	}
+	if d.Spec.Selector.MatchLabels[labels.FunctionNameKey] != "myfn" {
+		t.Errorf("expected pods selected by the function's name, got %v", d.Spec.Selector.MatchLabels)
+	}
+	if got := d.Annotations[URLAnnotation]; got != "http://myfn.ns.example.com" {
+		t.Errorf("unexpected URL %q", got)
+	}
+	c := d.Spec.Template.Spec.Containers[0]
+	if !hasEnv(c.Env, "PORT") || c.ReadinessProbe == nil {
+		t.Errorf("expected the port provided and the container probed, got %v", c)
+	}
+	ingress, err := client.NetworkingV1().Ingresses("ns").Get(ctx, "myfn", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host := ingress.Spec.Rules[0].Host; host != "myfn.ns.example.com" {
+		t.Errorf("unexpected ingress host %q", host)
+	}
+
+	f.Deploy.Image = "example.com/myfn:v2"
+	if status, err = apply(ctx, client, nil, f, "ns", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if status != fn.Updated {
+		t.Fatalf("expected updated, got %v", status)
+	}
+	d, _ = client.AppsV1().Deployments("ns").Get(ctx, "myfn", metav1.GetOptions{})
+	if image := deploymentImage(d); image != "example.com/myfn:v2" {
+		t.Errorf("expected the deployment updated, got image %q", image)
+	}
+}
+
+// testDecorator adds a label and an annotation to those of the function.
+type testDecorator struct{}
+
+func (testDecorator) UpdateAnnotations(f fn.Function, annotations map[string]string) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations["decorated"] = f.Name
+	return annotations
+}
+
+func (testDecorator) UpdateLabels(f fn.Function, labels map[string]string) map[string]string {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels["decorated"] = f.Name
+	return labels
+}
+
+// TestGenerateDeployment_Decorator ensures that the labels and annotations of
+// both the Deployment and its pods are updated by the deployer's decorator,
+// as are those of the Knative deployer.
+func TestGenerateDeployment_Decorator(t *testing.T) {
+	f := fn.Function{Name: "myfn", Runtime: "go", Deploy: fn.DeploySpec{Image: "example.com/myfn:latest"}}
+	d, err := generateDeployment(f, "ns", testDecorator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, m := range map[string]map[string]string{
+		"deployment labels":      d.Labels,
+		"deployment annotations": d.Annotations,
+		"pod labels":             d.Spec.Template.Labels,
+		"pod annotations":        d.Spec.Template.Annotations,
+	} {
+		if m["decorated"] != "myfn" {
+			t.Errorf("expected the %v decorated, got %v", name, m)
+		}
+	}
+	if d.Annotations[URLAnnotation] == "" {
+		t.Errorf("expected the URL annotation retained, got %v", d.Annotations)
+	}
+}
+
+// TestGenerateHTTPRoute ensures the route attaches to the gateway, of the
+// namespace if given, and routes to the function's Service.
+func TestGenerateHTTPRoute(t *testing.T) {
+	f := fn.Function{Name: "myfn", Deploy: fn.DeploySpec{Expose: &fn.ExposeSpec{Kind: fn.ExposeHTTPRoute, Gateway: "infra/public", Host: "myfn.example.com"}}}
+	route := generateHTTPRoute(f, "ns")
+
+	parents := route.Object["spec"].(map[string]any)["parentRefs"].([]any)
+	if p := parents[0].(map[string]any); p["namespace"] != "infra" || p["name"] != "public" {
+		t.Errorf("unexpected parent %v", p)
+	}
+	hosts := route.Object["spec"].(map[string]any)["hostnames"].([]any)
+	if len(hosts) != 1 || hosts[0] != "myfn.example.com" {
+		t.Errorf("unexpected hostnames %v", hosts)
+	}
+	if url := functionURL(f, "ns"); url != "http://myfn.example.com" {
+		t.Errorf("unexpected URL %q", url)
+	}
+	if url := functionURL(fn.Function{Name: "myfn"}, "ns"); url != "http://myfn.ns.svc.cluster.local" {
+		t.Errorf("unexpected URL of an unexposed function %q", url)
+	}
+}
+
+// TestRolledOut ensures a deployment is rolled out only once its latest
+// generation's replicas are all updated and available.
+func TestRolledOut(t *testing.T) {
+	replicas := int32(2)
+	d := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+	d.Generation = 2
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2}
+	if rolledOut(d) {
+		t.Error("expected a generation not yet observed not rolled out")
+	}
+	d.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2}
+	if rolledOut(d) {
+		t.Error("expected old replicas yet running not rolled out")
+	}
+	d.Status.Replicas = 2
+	if !rolledOut(d) {
+		t.Error("expected rolled out")
+	}
+}
+
+// TestListDeployments ensures only the deployments of functions are listed.
+func TestListDeployments(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns", Labels: map[string]string{labels.FunctionNameKey: "b", labels.FunctionRuntimeKey: "go"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns", Labels: map[string]string{labels.FunctionNameKey: "a"}, Annotations: map[string]string{URLAnnotation: "http://a.example.com"}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns"}},
+	)
+	items, _, err := listDeployments(ctx, client, "ns", metav1.ListOptions{LabelSelector: functionSelector("")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Name != "a" || items[1].Name != "b" {
+		t.Fatalf("expected functions a and b, got %v", items)
+	}
+	if items[0].URL != "http://a.example.com" || items[1].Runtime != "go" {
+		t.Errorf("unexpected items %v", items)
+	}
+}
@@ -0,0 +1,56 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Describer of the functions deployed by the k8s deployer.
+type Describer struct {
+	verbose bool
+}
+
+func NewDescriber(verbose bool) *Describer {
+	return &Describer{verbose: verbose}
+}
+
+// Describe the named function by its Deployment.  Routes are the URL at
+// which it is exposed, if any, and that of its Service within the cluster.
+func (d *Describer) Describe(ctx context.Context, name, namespace string) (description fn.Instance, err error) {
+	if namespace == "" {
+		err = fmt.Errorf("function namespace is required when describing %q", name)
+		return
+	}
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return
+	}
+	deployment, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fn.ErrFunctionNotFound
+		}
+		return
+	}
+
+	internal := fmt.Sprintf("http://%v.%v.svc.cluster.local", name, namespace)
+	description.Name = name
+	description.Namespace = namespace
+	description.Image = deploymentImage(deployment)
+	description.Route = deployment.Annotations[URLAnnotation]
+	if description.Route == "" {
+		description.Route = internal
+	}
+	description.Routes = []string{description.Route}
+	if description.Route != internal {
+		description.Routes = append(description.Routes, internal)
+	}
+	description.Labels = deployment.Labels
+	description.Provisioning = newProvisioning(deployment)
+	return
+}
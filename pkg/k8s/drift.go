@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// driftIgnoredAnnotations of a Deployment, which are set by the cluster or by
+// tooling rather than by deploying.
+var driftIgnoredAnnotations = []string{
+	"deployment.kubernetes.io/",
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// Snapshots of the function's deployed Deployment, and of that which
+// deploying the function would produce, such that edits of it on the cluster
+// are diffed.  The image is that last built, which is not rebuilt.
+func (d *Deployer) Snapshots(ctx context.Context, f fn.Function) (deployed, desired fn.Snapshot, err error) {
+	namespace := f.Deploy.Namespace
+	if namespace == "" {
+		err = fmt.Errorf("function %v has not been deployed", f.Name)
+		return
+	}
+	if f.Build.Image != "" {
+		f.Deploy.Image = f.Build.Image
+	}
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return
+	}
+	live, err := client.AppsV1().Deployments(namespace).Get(ctx, f.Name, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+	rendered, err := generateDeployment(f, namespace, d.decorator)
+	if err != nil {
+		return
+	}
+	deployed, desired = snapshotOf(live), snapshotOf(rendered)
+	if desired.ImageDigest == "" {
+		desired.ImageDigest = deployed.ImageDigest // of an image by tag, presumed that deployed
+	}
+	return
+}
+
+// snapshotOf the Deployment: of its first container, its replicas, its
+// service account, and its own labels and annotations.
+func snapshotOf(d *appsv1.Deployment) fn.Snapshot {
+	s := fn.Snapshot{
+		Envs:               map[string]string{},
+		Resources:          map[string]string{},
+		Scale:              map[string]string{},
+		ServiceAccountName: d.Spec.Template.Spec.ServiceAccountName,
+		Labels:             d.Labels,
+		Annotations:        map[string]string{},
+	}
+	if d.Spec.Replicas != nil {
+		s.Scale["replicas"] = strconv.Itoa(int(*d.Spec.Replicas))
+	}
+	for k, v := range d.Annotations {
+		ignored := false
+		for _, prefix := range driftIgnoredAnnotations {
+			ignored = ignored || strings.HasPrefix(k, prefix)
+		}
+		if !ignored {
+			s.Annotations[k] = v
+		}
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return s
+	}
+	c := d.Spec.Template.Spec.Containers[0]
+	s.Image = c.Image
+	if _, digest, ok := strings.Cut(c.Image, "@"); ok {
+		s.ImageDigest = digest
+	}
+	for _, e := range c.Env {
+		s.Envs[e.Name] = EnvValue(e)
+	}
+	for _, e := range c.EnvFrom {
+		if e.SecretRef != nil {
+			s.EnvFrom = append(s.EnvFrom, "secret:"+e.SecretRef.Name)
+		}
+		if e.ConfigMapRef != nil {
+			s.EnvFrom = append(s.EnvFrom, "configMap:"+e.ConfigMapRef.Name)
+		}
+	}
+	for name, q := range c.Resources.Requests {
+		s.Resources["requests."+string(name)] = q.String()
+	}
+	for name, q := range c.Resources.Limits {
+		s.Resources["limits."+string(name)] = q.String()
+	}
+	return s
+}
+
+// EnvValue of the environment variable: its value, or a reference to the
+// secret or config map from which it is read.
+func EnvValue(e corev1.EnvVar) string {
+	switch {
+	case e.ValueFrom == nil:
+		return e.Value
+	case e.ValueFrom.SecretKeyRef != nil:
+		return fmt.Sprintf("secret:%v/%v", e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key)
+	case e.ValueFrom.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("configMap:%v/%v", e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key)
+	}
+	return "(from the cluster)"
+}
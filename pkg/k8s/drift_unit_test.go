@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_snapshotOf ensures that edits of a deployed Deployment on the cluster
+// are diffed as changes from it to that which deploying would produce, the
+// annotations of the cluster ignored.
+func Test_snapshotOf(t *testing.T) {
+	name, value := "A", "1"
+	f := fn.Function{
+		Name:    "myfn",
+		Runtime: "go",
+		Run:     fn.RunSpec{Envs: []fn.Env{{Name: &name, Value: &value}}},
+		Deploy:  fn.DeploySpec{Image: "example.com/myfn@sha256:aaa", Deployer: fn.DeployerK8s},
+	}
+	deployed, err := generateDeployment(f, "ns", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := snapshotOf(deployed).Diff(snapshotOf(deployed)); len(changes) != 0 {
+		t.Fatalf("expected no drift, got %v", changes)
+	}
+
+	live := deployed.DeepCopy()
+	live.Annotations["deployment.kubernetes.io/revision"] = "3"
+	live.Labels["team"] = "a"
+	replicas := int32(3)
+	live.Spec.Replicas = &replicas
+	c := &live.Spec.Template.Spec.Containers[0]
+	for i := range c.Env {
+		if c.Env[i].Name == "A" {
+			c.Env[i] = corev1.EnvVar{Name: "A", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s"}, Key: "k"}}}
+		}
+	}
+
+	want := []fn.Change{
+		{Field: "envs.A", From: "secret:s/k", To: "1"},
+		{Field: "scale.replicas", From: "3", To: "1"},
+		{Field: "labels.team", From: "a"},
+	}
+	if changes := snapshotOf(live).Diff(snapshotOf(deployed)); !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected changes\n%v\ngot\n%v", want, changes)
+	}
+}
@@ -0,0 +1,146 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s/labels"
+)
+
+// hookPollInterval at which the job of a hook is polled for completion.
+const hookPollInterval = 2 * time.Second
+
+// HookRunner runs the container image hooks of a function as jobs of the
+// cluster, in the namespace to which the function is deployed.
+type HookRunner struct {
+	out io.Writer
+}
+
+// NewHookRunner which writes the logs of each hook's job to stderr.
+func NewHookRunner() *HookRunner {
+	return &HookRunner{out: os.Stderr}
+}
+
+// Run the image of the hook as a job, once, waiting for it to complete.  The
+// job is deleted once complete, its logs having been written.
+func (r *HookRunner) Run(ctx context.Context, f fn.Function, h fn.Hook, env []string) error {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	job, err := hookJob(f, h, env)
+	if err != nil {
+		return err
+	}
+	jobs := client.BatchV1().Jobs(namespace)
+	if job, err = jobs.Create(ctx, job, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("cannot create the job of the hook: %w", err)
+	}
+	defer func() {
+		background := metav1.DeletePropagationBackground
+		_ = jobs.Delete(context.Background(), job.Name, metav1.DeleteOptions{PropagationPolicy: &background})
+	}()
+
+	ticker := time.NewTicker(hookPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		if job, err = jobs.Get(ctx, job.Name, metav1.GetOptions{}); err != nil {
+			return err
+		}
+		if job.Status.Succeeded > 0 || job.Status.Failed > 0 {
+			break
+		}
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	if err == nil {
+		for _, pod := range pods.Items {
+			if logs, err := GetPodLogs(ctx, namespace, pod.Name, ""); err == nil {
+				fmt.Fprint(r.out, logs)
+			}
+		}
+	}
+	if job.Status.Failed > 0 {
+		return fmt.Errorf("job %v failed", job.Name)
+	}
+	return nil
+}
+
+// hookJob of the hook of the function, run once as the function's service
+// account, with the function's environment and then that given.
+func hookJob(f fn.Function, h fn.Hook, env []string) (*batchv1.Job, error) {
+	name := h.Name
+	if name == "" {
+		name = "hook"
+	}
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	envVars, envFrom, err := ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	if err != nil {
+		return nil, fmt.Errorf("cannot process the environment of the hook: %w", err)
+	}
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		envVars = append(envVars, corev1.EnvVar{Name: k, Value: v})
+	}
+	backoffLimit := int32(0)
+	ttl := int32(600)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   jobName(f.Name+"-"+name) + "-" + rand.String(5),
+			Labels: map[string]string{labels.FunctionNameKey: f.Name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:    "hook",
+						Image:   h.Image,
+						Args:    h.Args,
+						Env:     envVars,
+						EnvFrom: envFrom,
+					}},
+					ServiceAccountName: f.Deploy.ServiceAccountName,
+					RestartPolicy:      corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}, nil
+}
+
+// jobName of s, as a DNS label leaving room for a random suffix.
+func jobName(s string) string {
+	s = strings.ToLower(s)
+	s = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '-'
+	}, s)
+	if len(s) > 57 {
+		s = s[:57]
+	}
+	return strings.Trim(s, "-")
+}
@@ -0,0 +1,48 @@
+package k8s
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestHookJob ensures that the job of a hook is run once, of its image and
+// args, as the function's service account with its environment and that
+// provided, and is named as a DNS label.
+func TestHookJob(t *testing.T) {
+	dsn, secret := "DSN", "{{ secret:db:dsn }}"
+	f := fn.Function{Name: "f"}
+	f.Run.Envs = []fn.Env{{Name: &dsn, Value: &secret}}
+	f.Deploy.ServiceAccountName = "migrator"
+	h := fn.Hook{Name: "Migrate DB", Image: "example.com/migrate", Args: []string{"up"}}
+	job, err := hookJob(f, h, []string{"FUNC_NAME=f", "FUNC_URL=http://f.example.com/?a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(job.Name, "f-migrate-db-") {
+		t.Errorf("expected the job named of the function and hook, got %q", job.Name)
+	}
+	if *job.Spec.BackoffLimit != 0 {
+		t.Errorf("expected the job not retried, got a backoff limit of %v", *job.Spec.BackoffLimit)
+	}
+	c := job.Spec.Template.Spec.Containers[0]
+	if c.Image != h.Image || len(c.Args) != 1 || c.Args[0] != "up" {
+		t.Errorf("expected the image and args of the hook, got %v %v", c.Image, c.Args)
+	}
+	if last := c.Env[len(c.Env)-1]; last.Name != "FUNC_URL" || last.Value != "http://f.example.com/?a=b" {
+		t.Errorf("expected the environment provided, got %v", c.Env)
+	}
+	if !slices.ContainsFunc(c.Env, func(e corev1.EnvVar) bool {
+		return e.Name == dsn && e.ValueFrom != nil && e.ValueFrom.SecretKeyRef.Name == "db"
+	}) {
+		t.Errorf("expected the environment of the function, got %v", c.Env)
+	}
+	if sa := job.Spec.Template.Spec.ServiceAccountName; sa != "migrator" {
+		t.Errorf("expected the job run as the function's service account, got %q", sa)
+	}
+}
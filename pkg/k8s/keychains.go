@@ -2,11 +2,16 @@ package k8s
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"strings"
 
+	ecr "github.com/awslabs/amazon-ecr-credential-helper/ecr-login"
+	"github.com/awslabs/amazon-ecr-credential-helper/ecr-login/api"
+	acr "github.com/chrismellard/docker-credential-acr-env/pkg/credhelper"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/google"
 
@@ -14,11 +19,72 @@ import (
 	"knative.dev/func/pkg/oci"
 )
 
+// Workload identity
+//
+// The cloud credential loaders below obtain short-lived registry tokens from
+// the ambient identity of the environment in which func runs rather than
+// from stored secrets.  Each defers to the provider's standard credential
+// chain, which includes:
+//
+//	GKE: Workload Identity (metadata server), and federated OIDC via an
+//	     "external_account" GOOGLE_APPLICATION_CREDENTIALS file.
+//	EKS: IAM Roles for Service Accounts (AWS_ROLE_ARN and
+//	     AWS_WEB_IDENTITY_TOKEN_FILE) and EKS Pod Identity, also usable for
+//	     federated OIDC from CI.
+//	AKS: Azure Workload Identity (AZURE_CLIENT_ID, AZURE_TENANT_ID and
+//	     AZURE_FEDERATED_TOKEN_FILE) and managed identities.
+//
+// Only registry credentials are obtained this way.  Access to the cluster
+// itself is governed by the kubeconfig (or in-cluster service account) in
+// use, which for these providers typically delegates to an exec plugin such
+// as gke-gcloud-auth-plugin, "aws eks get-token" or kubelogin.
+//
+// Loaders return creds.ErrCredentialsNotFound for registries of other
+// providers, and when the provider's credential chain yields no credentials,
+// such that the next loader is tried.
+
+// WorkloadIdentity returns the name of the cloud workload identity federation
+// configured in the environment ("gke", "eks" or "aks"), or the empty string
+// if none is detected.  The metadata-server based GKE Workload Identity can
+// not be detected without network access and is therefore not reported.
+func WorkloadIdentity() string {
+	switch {
+	case os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "" || os.Getenv("AZURE_FEDERATED_TOKEN") != "":
+		return "aks"
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "":
+		return "eks"
+	case isGoogleExternalAccount(os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")):
+		return "gke"
+	}
+	return ""
+}
+
+func isGoogleExternalAccount(path string) bool {
+	if path == "" {
+		return false
+	}
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(bb, &cfg) == nil && cfg.Type == "external_account"
+}
+
+// isGoogleRegistry returns true for Container Registry and Artifact Registry
+// hosts.
+func isGoogleRegistry(registry string) bool {
+	return registry == "gcr.io" || strings.HasSuffix(registry, ".gcr.io") ||
+		strings.HasSuffix(registry, "-docker.pkg.dev")
+}
+
 func GetGoogleCredentialLoader() []creds.CredentialsCallback {
 	return []creds.CredentialsCallback{
 		func(registry string) (oci.Credentials, error) {
-			if registry != "gcr.io" {
-				return oci.Credentials{}, nil // skip if not GCR
+			if !isGoogleRegistry(registry) {
+				return oci.Credentials{}, creds.ErrCredentialsNotFound
 			}
 
 			res, err := name.NewRegistry(registry)
@@ -36,6 +102,10 @@ func GetGoogleCredentialLoader() []creds.CredentialsCallback {
 				return oci.Credentials{}, fmt.Errorf("get authorization: %w", err)
 			}
 
+			if authCfg.Username == "" && authCfg.Password == "" {
+				return oci.Credentials{}, creds.ErrCredentialsNotFound // anonymous
+			}
+
 			return oci.Credentials{
 				Username: authCfg.Username,
 				Password: authCfg.Password,
@@ -45,18 +115,51 @@ func GetGoogleCredentialLoader() []creds.CredentialsCallback {
 }
 
 func GetECRCredentialLoader() []creds.CredentialsCallback {
-	return []creds.CredentialsCallback{} // TODO: Implement ECR credentials loader
+	return []creds.CredentialsCallback{
+		func(registry string) (oci.Credentials, error) {
+			if _, err := api.ExtractRegistry(registry); err != nil {
+				return oci.Credentials{}, creds.ErrCredentialsNotFound // skip if not ECR
+			}
+			username, password, err := ecr.NewECRHelper(ecr.WithLogger(io.Discard)).Get(registry)
+			if err != nil {
+				// The helper reports all failures as not found.  Fall through to
+				// the remaining loaders (docker config, prompt) in that case.
+				return oci.Credentials{}, creds.ErrCredentialsNotFound
+			}
+			return oci.Credentials{
+				Username: username,
+				Password: password,
+			}, nil
+		},
+	}
 }
 
 func GetACRCredentialLoader() []creds.CredentialsCallback {
 	return []creds.CredentialsCallback{
 		func(registry string) (oci.Credentials, error) {
 			if !strings.HasSuffix(registry, ".azurecr.io") {
-				return oci.Credentials{}, nil
+				return oci.Credentials{}, creds.ErrCredentialsNotFound
+			}
+
+			// Workload identity (federated token) or other credentials configured
+			// via the environment take precedence over a local Azure CLI login.
+			if WorkloadIdentity() == "aks" || os.Getenv("AZURE_CLIENT_ID") != "" {
+				username, password, err := acr.NewACRCredentialsHelper().Get(registry)
+				if err != nil {
+					// No usable federated token or client secret.  Fall through to
+					// the remaining loaders (docker config, prompt) as for ECR.
+					return oci.Credentials{}, creds.ErrCredentialsNotFound
+				}
+				return oci.Credentials{
+					Username: username,
+					Password: password,
+				}, nil
 			}
 
 			f, err := os.Open(path.Join(os.Getenv("HOME"), ".azure", "accessTokens.json"))
-			if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return oci.Credentials{}, creds.ErrCredentialsNotFound
+			} else if err != nil {
 				return oci.Credentials{}, fmt.Errorf("open Azure access tokens: %w", err)
 			}
 			defer f.Close()
@@ -79,7 +182,7 @@ func GetACRCredentialLoader() []creds.CredentialsCallback {
 					}, nil
 				}
 			}
-			return oci.Credentials{}, nil
+			return oci.Credentials{}, creds.ErrCredentialsNotFound
 		},
 	}
 }
@@ -0,0 +1,69 @@
+package k8s_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"knative.dev/func/pkg/creds"
+	"knative.dev/func/pkg/k8s"
+)
+
+func TestWorkloadIdentity(t *testing.T) {
+	for _, v := range []string{"AZURE_FEDERATED_TOKEN_FILE", "AZURE_FEDERATED_TOKEN",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_FULL_URI", "GOOGLE_APPLICATION_CREDENTIALS"} {
+		t.Setenv(v, "")
+	}
+	if id := k8s.WorkloadIdentity(); id != "" {
+		t.Fatalf("expected no workload identity, got %q", id)
+	}
+
+	credsFile := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(credsFile, []byte(`{"type":"external_account"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credsFile)
+	if id := k8s.WorkloadIdentity(); id != "gke" {
+		t.Fatalf("expected gke, got %q", id)
+	}
+
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "/var/run/secrets/eks.amazonaws.com/serviceaccount/token")
+	if id := k8s.WorkloadIdentity(); id != "eks" {
+		t.Fatalf("expected eks, got %q", id)
+	}
+
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "/var/run/secrets/azure/tokens/azure-identity-token")
+	if id := k8s.WorkloadIdentity(); id != "aks" {
+		t.Fatalf("expected aks, got %q", id)
+	}
+}
+
+// TestCloudCredentialLoaders_OtherRegistries ensures that the cloud loaders
+// defer to the remaining loaders for registries of other providers.
+func TestCloudCredentialLoaders_OtherRegistries(t *testing.T) {
+	loaders := append(k8s.GetGoogleCredentialLoader(), k8s.GetECRCredentialLoader()...)
+	loaders = append(loaders, k8s.GetACRCredentialLoader()...)
+	for _, load := range loaders {
+		if _, err := load("quay.io"); !errors.Is(err, creds.ErrCredentialsNotFound) {
+			t.Fatalf("expected ErrCredentialsNotFound, got %v", err)
+		}
+	}
+}
+
+// TestACRCredentialLoader_NoFederatedToken ensures that an Azure client ID
+// without a usable federated token defers to the remaining loaders rather
+// than failing.
+func TestACRCredentialLoader_NoFederatedToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AZURE_CLIENT_ID", "00000000-0000-0000-0000-000000000000")
+	t.Setenv("AZURE_TENANT_ID", "")
+	t.Setenv("AZURE_CLIENT_SECRET", "")
+	t.Setenv("AZURE_FEDERATED_TOKEN", "")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+	for _, load := range k8s.GetACRCredentialLoader() {
+		if _, err := load("example.azurecr.io"); !errors.Is(err, creds.ErrCredentialsNotFound) {
+			t.Fatalf("expected ErrCredentialsNotFound, got %v", err)
+		}
+	}
+}
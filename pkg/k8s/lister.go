@@ -0,0 +1,204 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s/labels"
+)
+
+// watchRetryInterval between attempts to resume watching deployments.
+const watchRetryInterval = 2 * time.Second
+
+// Lister of the functions deployed by the k8s deployer, being the Deployments
+// labelled with the name of a function.
+type Lister struct {
+	verbose bool
+}
+
+func NewLister(verbose bool) *Lister {
+	return &Lister{verbose: verbose}
+}
+
+// List functions, optionally specifying a namespace and a label selector.
+// Those of all namespaces are listed if none is given.
+func (l *Lister) List(ctx context.Context, namespace, selector string) (items []fn.ListItem, err error) {
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return
+	}
+	items, _, err = listDeployments(ctx, client, namespace, metav1.ListOptions{LabelSelector: functionSelector(selector)})
+	return
+}
+
+// ListPage of at most limit functions, continuing from the token of the
+// previous page.
+func (l *Lister) ListPage(ctx context.Context, namespace, selector string, limit int64, token string) (items []fn.ListItem, next string, err error) {
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return
+	}
+	return listDeployments(ctx, client, namespace, metav1.ListOptions{LabelSelector: functionSelector(selector), Limit: limit, Continue: token})
+}
+
+// Watch functions, optionally specifying a namespace and a label selector.
+// The deployments are listed, such that errors connecting are returned, and
+// listed again on each change, resuming the watch should it be ended.
+func (l *Lister) Watch(ctx context.Context, namespace, selector string) (<-chan []fn.ListItem, error) {
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options := metav1.ListOptions{LabelSelector: functionSelector(selector)}
+	items, _, err := listDeployments(ctx, client, namespace, options)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan []fn.ListItem)
+	go func() {
+		defer close(ch)
+		watchDeployments(ctx, client, namespace, options, items, ch)
+	}()
+	return ch, nil
+}
+
+// watchDeployments sending the list of the functions initially and after each
+// change until the context is done.
+func watchDeployments(ctx context.Context, client kubernetes.Interface, namespace string, options metav1.ListOptions, items []fn.ListItem, ch chan<- []fn.ListItem) {
+	send := func(items []fn.ListItem) bool {
+		select {
+		case ch <- items:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if !send(items) {
+		return
+	}
+	wait := func() {
+		select {
+		case <-time.After(watchRetryInterval):
+		case <-ctx.Done():
+		}
+	}
+	for ctx.Err() == nil {
+		watcher, err := client.AppsV1().Deployments(namespace).Watch(ctx, options)
+		if err != nil {
+			wait()
+			continue
+		}
+		for event := range watcher.ResultChan() {
+			if event.Type == watch.Bookmark {
+				continue
+			}
+			var items []fn.ListItem
+			if items, _, err = listDeployments(ctx, client, namespace, options); err != nil {
+				break
+			}
+			if !send(items) {
+				watcher.Stop()
+				return
+			}
+		}
+		watcher.Stop()
+		if err != nil {
+			wait()
+		}
+	}
+}
+
+// functionSelector of the deployments of functions, being those with their
+// name label, and which match the selector if given.
+func functionSelector(selector string) string {
+	if selector == "" {
+		return labels.FunctionNameKey
+	}
+	return labels.FunctionNameKey + "," + selector
+}
+
+// listDeployments as functions, ordered by namespace and name, returning the
+// token from which to continue a paged list.
+func listDeployments(ctx context.Context, client kubernetes.Interface, namespace string, options metav1.ListOptions) (items []fn.ListItem, next string, err error) {
+	lst, err := client.AppsV1().Deployments(namespace).List(ctx, options)
+	if err != nil {
+		if errors.IsResourceExpired(err) {
+			err = fmt.Errorf("the continue token has expired, list again from the first page: %w", err)
+		}
+		return
+	}
+	for i := range lst.Items {
+		items = append(items, newListItem(&lst.Items[i]))
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+	next = lst.Continue
+	return
+}
+
+func newListItem(d *appsv1.Deployment) fn.ListItem {
+	return fn.ListItem{
+		Name:         d.Name,
+		Namespace:    d.Namespace,
+		Runtime:      d.Labels[labels.FunctionRuntimeKey],
+		URL:          d.Annotations[URLAnnotation],
+		Ready:        string(deploymentReady(d)),
+		Created:      d.CreationTimestamp.Time,
+		Image:        deploymentImage(d),
+		Provisioning: newProvisioning(d),
+	}
+}
+
+// deploymentReady is the status of the Available condition of the deployment.
+func deploymentReady(d *appsv1.Deployment) corev1.ConditionStatus {
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status
+		}
+	}
+	return corev1.ConditionUnknown
+}
+
+func deploymentImage(d *appsv1.Deployment) string {
+	if cc := d.Spec.Template.Spec.Containers; len(cc) > 0 {
+		return cc[0].Image
+	}
+	return ""
+}
+
+// newProvisioning of the deployment, its scale being its fixed replicas.
+func newProvisioning(d *appsv1.Deployment) (p fn.Provisioning) {
+	if d.Spec.Replicas != nil {
+		replicas := int64(*d.Spec.Replicas)
+		p.MinScale, p.MaxScale = &replicas, &replicas
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return
+	}
+	quantity := func(rl corev1.ResourceList, name corev1.ResourceName) string {
+		if q, ok := rl[name]; ok {
+			return q.String()
+		}
+		return ""
+	}
+	r := d.Spec.Template.Spec.Containers[0].Resources
+	p.CPURequest = quantity(r.Requests, corev1.ResourceCPU)
+	p.CPULimit = quantity(r.Limits, corev1.ResourceCPU)
+	p.MemoryRequest = quantity(r.Requests, corev1.ResourceMemory)
+	p.MemoryLimit = quantity(r.Limits, corev1.ResourceMemory)
+	return
+}
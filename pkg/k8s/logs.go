@@ -16,7 +16,7 @@ func GetPodLogs(ctx context.Context, namespace, podName, containerName string) (
 		podLogOpts.Container = containerName
 	}
 
-	client, namespace, _ := NewClientAndResolvedNamespace(namespace)
+	client, namespace, _ := NewClientAndResolvedNamespaceFrom(ctx, namespace)
 	request := client.CoreV1().Pods(namespace).GetLogs(podName, &podLogOpts)
 
 	containerLogStream, err := request.Stream(ctx)
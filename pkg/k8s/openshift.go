@@ -25,7 +25,7 @@ const (
 )
 
 func GetOpenShiftServiceCA(ctx context.Context) (*x509.Certificate, error) {
-	client, ns, err := NewClientAndResolvedNamespace("")
+	client, ns, err := NewClientAndResolvedNamespaceFrom(ctx, "")
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +91,13 @@ func GetDefaultOpenShiftRegistry() string {
 		ns = "default"
 	}
 
-	return openShiftRegistryHostPort + "/" + ns
+	return OpenShiftRegistry(ns)
+}
+
+// OpenShiftRegistry is the cluster's internal registry of the namespace, to
+// which images of functions deployed to it may be pushed.
+func OpenShiftRegistry(namespace string) string {
+	return openShiftRegistryHostPort + "/" + namespace
 }
 
 func GetOpenShiftDockerCredentialLoaders() []creds.CredentialsCallback {
@@ -24,7 +24,7 @@ import (
 )
 
 func GetPersistentVolumeClaim(ctx context.Context, name, namespaceOverride string) (*corev1.PersistentVolumeClaim, error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +33,7 @@ func GetPersistentVolumeClaim(ctx context.Context, name, namespaceOverride strin
 }
 
 func CreatePersistentVolumeClaim(ctx context.Context, name, namespaceOverride string, labels map[string]string, annotations map[string]string, accessMode corev1.PersistentVolumeAccessMode, resourceRequest resource.Quantity, storageClassName string) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -64,7 +64,7 @@ func CreatePersistentVolumeClaim(ctx context.Context, name, namespaceOverride st
 }
 
 func DeletePersistentVolumeClaims(ctx context.Context, namespaceOverride string, listOptions metav1.ListOptions) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -271,7 +271,7 @@ func ListPersistentVolumeClaimsNamesIfConnected(ctx context.Context, namespaceOv
 }
 
 func listPersistentVolumeClaimsNames(ctx context.Context, namespaceOverride string) (names []string, err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
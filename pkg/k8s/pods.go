@@ -0,0 +1,106 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// RunPod runs the pod to completion in the namespace, the current namespace
+// if empty, returning the terminated state of its container.  The secret, if
+// any, is created beforehand, and both are removed once the pod terminates.
+// The input is attached to the stdin of the pod's container, which must be
+// named as is the pod, and its output is streamed to out as it is written.
+func RunPod(ctx context.Context, namespace string, pod *corev1.Pod, secret *corev1.Secret, in io.Reader, out io.Writer) (state corev1.ContainerStateTerminated, err error) {
+	restConf, err := GetClientConfig().ClientConfig()
+	if err != nil {
+		return state, fmt.Errorf("cannot get client config: %w", err)
+	}
+	restConf.WarningHandler = restclient.NoWarnings{}
+	if err = setConfigDefaults(restConf); err != nil {
+		return state, fmt.Errorf("cannot set config defaults: %w", err)
+	}
+	client, err := kubernetes.NewForConfig(restConf)
+	if err != nil {
+		return state, fmt.Errorf("cannot create k8s client: %w", err)
+	}
+	if namespace == "" {
+		if namespace, err = GetDefaultNamespace(); err != nil {
+			return state, fmt.Errorf("cannot get namespace: %w", err)
+		}
+	}
+
+	// Removal is not of the context, which may be that cancelled.
+	if secret != nil {
+		secrets := client.CoreV1().Secrets(namespace)
+		if _, err = secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return state, fmt.Errorf("cannot create secret: %w", err)
+		}
+		defer func() {
+			_ = secrets.Delete(context.WithoutCancel(ctx), secret.Name, metav1.DeleteOptions{})
+		}()
+	}
+	pods := client.CoreV1().Pods(namespace)
+
+	localCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ready := podReady(localCtx, client.CoreV1(), pod.Name, namespace)
+
+	if _, err = pods.Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return state, fmt.Errorf("cannot create pod: %w", err)
+	}
+	defer func() {
+		_ = pods.Delete(context.WithoutCancel(ctx), pod.Name, metav1.DeleteOptions{})
+	}()
+
+	select {
+	case err = <-ready:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-time.After(time.Minute * 5):
+		err = errors.New("timeout waiting for pod to start")
+	}
+	if err != nil {
+		return state, fmt.Errorf("cannot start the pod: %w", err)
+	}
+
+	watcher, err := pods.Watch(localCtx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", pod.Name).String(),
+		Watch:         true,
+	})
+	if err != nil {
+		return state, fmt.Errorf("cannot set up the watcher: %w", err)
+	}
+	defer watcher.Stop()
+	termCh := make(chan corev1.ContainerStateTerminated, 1)
+	go func() {
+		for event := range watcher.ResultChan() {
+			p, ok := event.Object.(*corev1.Pod)
+			if !ok || len(p.Status.ContainerStatuses) == 0 {
+				continue
+			}
+			if t := p.Status.ContainerStatuses[0].State.Terminated; t != nil {
+				termCh <- *t
+				return
+			}
+		}
+	}()
+
+	if err = attach(ctx, client.CoreV1().RESTClient(), restConf, pod.Name, namespace, in, out, out); err != nil {
+		return state, fmt.Errorf("cannot attach stdio to the pod: %w", err)
+	}
+	select {
+	case state = <-termCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return
+}
@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Remover of the functions deployed by the k8s deployer.
+type Remover struct {
+	verbose bool
+}
+
+func NewRemover(verbose bool) *Remover {
+	return &Remover{verbose: verbose}
+}
+
+// Remove the function's Deployment and Service, and the Ingress or HTTPRoute
+// by which it is exposed, if any.
+func (r *Remover) Remove(ctx context.Context, name, namespace string) error {
+	if namespace == "" {
+		fmt.Fprintf(os.Stderr, "no namespace defined when trying to delete a function in k8s remover\n")
+		return fn.ErrNamespaceRequired
+	}
+	client, err := NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return err
+	}
+	background := metav1.DeletePropagationBackground
+	options := metav1.DeleteOptions{PropagationPolicy: &background}
+
+	if err = client.AppsV1().Deployments(namespace).Delete(ctx, name, options); err != nil {
+		if errors.IsNotFound(err) {
+			return fn.ErrFunctionNotFound
+		}
+		return fmt.Errorf("k8s remover failed to delete the Deployment: %v", err)
+	}
+	if err = client.CoreV1().Services(namespace).Delete(ctx, name, options); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("k8s remover failed to delete the Service: %v", err)
+	}
+	if err = client.NetworkingV1().Ingresses(namespace).Delete(ctx, name, options); err != nil && !errors.IsNotFound(err) && !errors.IsForbidden(err) {
+		return fmt.Errorf("k8s remover failed to delete the Ingress: %v", err)
+	}
+	// The Gateway API may not be installed, in which case there is no route.
+	if dyn, err := NewDynamicClientFrom(ctx); err == nil {
+		err = dyn.Resource(httpRouteResource).Namespace(namespace).Delete(ctx, name, options)
+		if err != nil && !errors.IsNotFound(err) && !errors.IsForbidden(err) {
+			return fmt.Errorf("k8s remover failed to delete the HTTPRoute: %v", err)
+		}
+	}
+	return nil
+}
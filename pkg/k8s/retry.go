@@ -0,0 +1,81 @@
+package k8s
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+const (
+	// DefaultRetries is the number of attempts at applying a function's
+	// resources, absent those provided via the context.
+	DefaultRetries = 5
+	// DefaultRetryTimeout is the longest for which the attempts are made,
+	// absent that provided via the context.
+	DefaultRetryTimeout = time.Minute
+)
+
+// retryInterval before the second attempt, doubled before each thereafter.
+var retryInterval = 500 * time.Millisecond
+
+// RetryOptions of the application of a function's resources.
+type RetryOptions struct {
+	// Attempts made at most, the first included.
+	Attempts int
+	// Timeout after which no further attempts are made.
+	Timeout time.Duration
+}
+
+// RetryOptionsFrom the context, defaulting to DefaultRetries attempts for at
+// most DefaultRetryTimeout.
+func RetryOptionsFrom(ctx context.Context) RetryOptions {
+	o := RetryOptions{Attempts: DefaultRetries, Timeout: DefaultRetryTimeout}
+	if n, ok := ctx.Value(fn.DeployRetriesKey{}).(int); ok && n > 0 {
+		o.Attempts = n
+	}
+	if t, ok := ctx.Value(fn.DeployRetryTimeoutKey{}).(time.Duration); ok && t > 0 {
+		o.Timeout = t
+	}
+	return o
+}
+
+// IsRetryable reports whether the error of a request to the API server is
+// one which retrying may resolve: a conflict, of a resource updated
+// concurrently, or a transient error of the server.
+func IsRetryable(err error) bool {
+	return errors.IsConflict(err) ||
+		errors.IsServerTimeout(err) ||
+		errors.IsTimeout(err) ||
+		errors.IsTooManyRequests(err) ||
+		errors.IsServiceUnavailable(err) ||
+		errors.IsInternalError(err) ||
+		utilnet.IsConnectionReset(err)
+}
+
+// Retry f while its error is retryable, backing off exponentially between
+// attempts, until the attempts of the options are exhausted or their timeout
+// has elapsed.  Returned is the error of the last attempt.  The function
+// should get the resources it updates anew, such that a conflict is of a
+// version of the resource not yet seen.
+func Retry(ctx context.Context, o RetryOptions, f func() error) (err error) {
+	deadline := time.Now().Add(o.Timeout)
+	interval := retryInterval
+	for attempt := 1; ; attempt++ {
+		if err = f(); err == nil || !IsRetryable(err) {
+			return
+		}
+		if attempt >= o.Attempts || time.Now().Add(interval).After(deadline) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestRetry ensures that retryable errors are retried until the attempts are
+// exhausted, and that others are returned at once.
+func TestRetry(t *testing.T) {
+	defer func(d time.Duration) { retryInterval = d }(retryInterval)
+	retryInterval = time.Millisecond
+
+	conflict := k8serrors.NewConflict(schema.GroupResource{Resource: "services"}, "f", errors.New("modified"))
+	unavailable := k8serrors.NewServiceUnavailable("unavailable")
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "services"}, "f", errors.New("denied"))
+
+	tests := []struct {
+		name     string
+		errs     []error // of each attempt, nil thereafter
+		attempts int     // of the options
+		want     int     // attempts made
+		wantErr  error
+	}{
+		{name: "success", attempts: 3, want: 1},
+		{name: "conflict then success", errs: []error{conflict}, attempts: 3, want: 2},
+		{name: "transient errors then success", errs: []error{unavailable, unavailable}, attempts: 3, want: 3},
+		{name: "attempts exhausted", errs: []error{conflict, conflict, conflict}, attempts: 2, want: 2, wantErr: conflict},
+		{name: "not retryable", errs: []error{forbidden}, attempts: 3, want: 1, wantErr: forbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var made int
+			err := Retry(context.Background(), RetryOptions{Attempts: tt.attempts, Timeout: time.Minute}, func() error {
+				made++
+				if made <= len(tt.errs) {
+					return tt.errs[made-1]
+				}
+				return nil
+			})
+			if made != tt.want {
+				t.Errorf("expected %v attempts, got %v", tt.want, made)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestRetry_Timeout ensures no attempt is made beyond the timeout.
+func TestRetry_Timeout(t *testing.T) {
+	defer func(d time.Duration) { retryInterval = d }(retryInterval)
+	retryInterval = time.Hour
+
+	var made int
+	err := Retry(context.Background(), RetryOptions{Attempts: 5, Timeout: time.Second}, func() error {
+		made++
+		return k8serrors.NewTooManyRequests("slow down", 1)
+	})
+	if made != 1 || err == nil {
+		t.Fatalf("expected a single failed attempt, got %v attempts and %v", made, err)
+	}
+}
+
+// TestRetryOptionsFrom ensures the options are those of the context, else the
+// defaults.
+func TestRetryOptionsFrom(t *testing.T) {
+	o := RetryOptionsFrom(context.Background())
+	if o.Attempts != DefaultRetries || o.Timeout != DefaultRetryTimeout {
+		t.Fatalf("expected the defaults, got %+v", o)
+	}
+	ctx := context.WithValue(context.Background(), fn.DeployRetriesKey{}, 2)
+	ctx = context.WithValue(ctx, fn.DeployRetryTimeoutKey{}, 10*time.Second)
+	if o = RetryOptionsFrom(ctx); o.Attempts != 2 || o.Timeout != 10*time.Second {
+		t.Fatalf("expected the options of the context, got %+v", o)
+	}
+}
+
+// TestApply_Retry ensures that applying the function's resources is retried
+// when its Deployment was updated concurrently.
+func TestApply_Retry(t *testing.T) {
+	defer func(d time.Duration) { retryInterval = d }(retryInterval)
+	retryInterval = time.Millisecond
+
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	f := fn.Function{Name: "myfn", Runtime: "go", Deploy: fn.DeploySpec{Image: "example.com/myfn:v1", Deployer: fn.DeployerK8s}}
+	if _, err := apply(ctx, client, nil, f, "ns", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var conflicts int
+	client.PrependReactor("update", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts++; conflicts == 1 {
+			return true, nil, k8serrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "myfn", errors.New("modified"))
+		}
+		return false, nil, nil
+	})
+
+	f.Deploy.Image = "example.com/myfn:v2"
+	err := Retry(ctx, RetryOptions{Attempts: 3, Timeout: time.Minute}, func() error {
+		_, err := apply(ctx, client, nil, f, "ns", nil, nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conflicts != 2 {
+		t.Fatalf("expected the update conflicting once and then retried, got %v updates", conflicts)
+	}
+	d, _ := client.AppsV1().Deployments("ns").Get(ctx, "myfn", metav1.GetOptions{})
+	if image := deploymentImage(d); image != "example.com/myfn:v2" {
+		t.Errorf("expected the deployment updated, got image %q", image)
+	}
+}
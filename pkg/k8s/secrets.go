@@ -16,7 +16,7 @@ import (
 )
 
 func GetSecret(ctx context.Context, name, namespaceOverride string) (*corev1.Secret, error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +57,7 @@ func ListSecretsNamesIfConnected(ctx context.Context, namespaceOverride string)
 }
 
 func listSecretsNames(ctx context.Context, namespaceOverride string) (names []string, err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -75,7 +75,7 @@ func listSecretsNames(ctx context.Context, namespaceOverride string) (names []st
 }
 
 func DeleteSecrets(ctx context.Context, namespaceOverride string, listOptions metav1.ListOptions) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -105,7 +105,7 @@ func EnsureDockerRegistrySecretExist(ctx context.Context, name, namespaceOverrid
 }
 
 func EnsureSecretExist(ctx context.Context, secret corev1.Secret, namespaceOverride string) (err error) {
-	client, namespace, err := NewClientAndResolvedNamespace(namespaceOverride)
+	client, namespace, err := NewClientAndResolvedNamespaceFrom(ctx, namespaceOverride)
 	if err != nil {
 		return
 	}
@@ -7,7 +7,7 @@ import (
 )
 
 func GetServiceAccount(ctx context.Context, referencedServiceAccount, namespace string) error {
-	k8sClient, err := NewKubernetesClientset()
+	k8sClient, err := NewKubernetesClientsetFrom(ctx)
 	if err != nil {
 		return err
 	}
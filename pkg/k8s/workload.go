@@ -0,0 +1,551 @@
+package k8s
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Default health endpoints of functions, probed unless the function declares
+// its own.
+const (
+	DefaultLivenessEndpoint  = "/health/liveness"
+	DefaultReadinessEndpoint = "/health/readiness"
+)
+
+// SetHealthEndpoints of the container, being its liveness and readiness
+// probes of the function's health endpoints, else of the defaults.
+func probeFor(url string) *corev1.Probe {
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: url,
+			},
+		},
+	}
+}
+
+func SetHealthEndpoints(f fn.Function, c *corev1.Container) *corev1.Container {
+	// Set the defaults
+	c.LivenessProbe = probeFor(DefaultLivenessEndpoint)
+	c.ReadinessProbe = probeFor(DefaultReadinessEndpoint)
+
+	// If specified in func.yaml, the provided values override the defaults
+	if f.Deploy.HealthEndpoints.Liveness != "" {
+		c.LivenessProbe = probeFor(f.Deploy.HealthEndpoints.Liveness)
+	}
+	if f.Deploy.HealthEndpoints.Readiness != "" {
+		c.ReadinessProbe = probeFor(f.Deploy.HealthEndpoints.Readiness)
+	}
+	return c
+}
+
+// SetExtendedResources of the container, each both requested and limited, as
+// extended resources can not be overcommitted.
+func SetExtendedResources(o *fn.ResourcesOptions, c *corev1.Container) {
+	if o == nil {
+		return
+	}
+	for name, n := range o.Extended {
+		if c.Resources.Requests == nil {
+			c.Resources.Requests = corev1.ResourceList{}
+		}
+		if c.Resources.Limits == nil {
+			c.Resources.Limits = corev1.ResourceList{}
+		}
+		c.Resources.Requests[corev1.ResourceName(name)] = *resource.NewQuantity(n, resource.DecimalSI)
+		c.Resources.Limits[corev1.ResourceName(name)] = *resource.NewQuantity(n, resource.DecimalSI)
+	}
+}
+
+// SetScheduling of the pod spec to that of the function, replacing its node
+// selector, tolerations and node affinity.
+func SetScheduling(s *fn.SchedulingSpec, spec *corev1.PodSpec) {
+	spec.NodeSelector, spec.Tolerations, spec.Affinity = nil, nil, nil
+	if s == nil {
+		return
+	}
+	if len(s.NodeSelector) > 0 {
+		spec.NodeSelector = s.NodeSelector
+	}
+	for _, t := range s.Tolerations {
+		spec.Tolerations = append(spec.Tolerations, corev1.Toleration{
+			Key:               t.Key,
+			Operator:          corev1.TolerationOperator(t.Operator),
+			Value:             t.Value,
+			Effect:            corev1.TaintEffect(t.Effect),
+			TolerationSeconds: t.TolerationSeconds,
+		})
+	}
+	if s.Affinity == nil || s.Affinity.NodeAffinity == nil {
+		return
+	}
+	requirements := func(rr []fn.NodeSelectorRequirement) (expressions []corev1.NodeSelectorRequirement) {
+		for _, r := range rr {
+			expressions = append(expressions, corev1.NodeSelectorRequirement{
+				Key:      r.Key,
+				Operator: corev1.NodeSelectorOperator(r.Operator),
+				Values:   r.Values,
+			})
+		}
+		return
+	}
+	a := &corev1.NodeAffinity{}
+	if len(s.Affinity.NodeAffinity.Required) > 0 {
+		a.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: requirements(s.Affinity.NodeAffinity.Required)}},
+		}
+	}
+	for _, p := range s.Affinity.NodeAffinity.Preferred {
+		a.PreferredDuringSchedulingIgnoredDuringExecution = append(a.PreferredDuringSchedulingIgnoredDuringExecution, corev1.PreferredSchedulingTerm{
+			Weight:     p.Weight,
+			Preference: corev1.NodeSelectorTerm{MatchExpressions: requirements(p.Match)},
+		})
+	}
+	spec.Affinity = &corev1.Affinity{NodeAffinity: a}
+}
+
+// ProcessEnvs generates array of EnvVars and EnvFromSources from a function config
+// envs:
+//   - name: EXAMPLE1                            # ENV directly from a value
+//     value: value1
+//   - name: EXAMPLE2                            # ENV from the local ENV var
+//     value: {{ env:MY_ENV }}
+//   - name: EXAMPLE3
+//     value: {{ secret:example-secret:key }}    # ENV from a key in Secret
+//   - value: {{ secret:example-secret }}        # all ENVs from Secret
+//   - name: EXAMPLE4
+//     value: {{ configMap:configMapName:key }}  # ENV from a key in ConfigMap
+//   - value: {{ configMap:configMapName }}      # all key-pair values from ConfigMap are set as ENV
+func ProcessEnvs(envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Set[string]) ([]corev1.EnvVar, []corev1.EnvFromSource, error) {
+
+	envs = withOpenAddress(envs) // prepends ADDRESS=0.0.0.0 if not extant
+
+	envVars := []corev1.EnvVar{{Name: "BUILT", Value: time.Now().Format("20060102T150405")}}
+	envFrom := []corev1.EnvFromSource{}
+
+	for _, env := range envs {
+		if env.Name == nil && env.Value != nil {
+			// all key-pair values from secret/configMap are set as ENV, eg. {{ secret:secretName }} or {{ configMap:configMapName }}
+			if strings.HasPrefix(*env.Value, "{{") {
+				envFromSource, err := createEnvFromSource(*env.Value, referencedSecrets, referencedConfigMaps)
+				if err != nil {
+					return nil, nil, err
+				}
+				envFrom = append(envFrom, *envFromSource)
+				continue
+			}
+		} else if env.Name != nil && env.Value != nil {
+			if strings.HasPrefix(*env.Value, "{{") {
+				slices := strings.Split(strings.Trim(*env.Value, "{} "), ":")
+				if len(slices) == 3 {
+					// ENV from a key in secret/configMap, eg. FOO={{ secret:secretName:key }} FOO={{ configMap:configMapName.key }}
+					valueFrom, err := createEnvVarSource(slices, referencedSecrets, referencedConfigMaps)
+					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, ValueFrom: valueFrom})
+					if err != nil {
+						return nil, nil, err
+					}
+					continue
+				} else if len(slices) == 2 {
+					// ENV from the local ENV var, eg. FOO={{ env:LOCAL_ENV }}
+					localValue, err := processLocalEnvValue(*env.Value)
+					if err != nil {
+						return nil, nil, err
+					}
+					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: localValue})
+					continue
+				}
+			} else {
+				// a standard ENV with key and value, eg. FOO=bar
+				envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: *env.Value})
+				continue
+			}
+		}
+		return nil, nil, fmt.Errorf("unsupported env source entry \"%v\"", env)
+	}
+
+	return envVars, envFrom, nil
+}
+
+// withOpenAddresss prepends ADDRESS=0.0.0.0 to the envs if not present.
+//
+// This is combined with the value of PORT at runtime to determine the full
+// Listener address on which a Function will listen tcp requests.
+//
+// Runtimes should, by default, only listen on the loopback interface by
+// default, as they may be `func run` locally, for security purposes.
+// This environment vriable instructs the runtimes to listen on all interfaces
+// by default when actually being deployed, since they will need to actually
+// listen for client requests and for health readiness/liveness probes.
+//
+// Should a user wish to securely open their function to only receive requests
+// on a specific interface, such as a WireGuar-encrypted mesh network which
+// presents as a specific interface, that can be achieved by setting the
+// ADDRESS value as an environment variable on their function to the interface
+// on which to listen.
+//
+// NOTE this env is currently only respected by scaffolded Go functions, because
+// they are the only ones which support being `func run` locally.  Other
+// runtimes will respect the value as they are updated to support scaffolding.
+func withOpenAddress(ee []fn.Env) []fn.Env {
+	// TODO: this is unnecessarily complex due to both key and value of the
+	// envs slice being being pointers.  There is an outstanding tech-debt item
+	// to remove pointers from Function Envs, Volumes, Labels, and Options.
+	var found bool
+	for _, e := range ee {
+		if e.Name != nil && *e.Name == "ADDRESS" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		k := "ADDRESS"
+		v := "0.0.0.0"
+		ee = append(ee, fn.Env{Name: &k, Value: &v})
+	}
+	return ee
+}
+
+func createEnvFromSource(value string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvFromSource, error) {
+	slices := strings.Split(strings.Trim(value, "{} "), ":")
+	if len(slices) != 2 {
+		return nil, fmt.Errorf("env requires a value in form \"resourceType:name\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
+	}
+
+	envVarSource := corev1.EnvFromSource{}
+
+	typeString := strings.TrimSpace(slices[0])
+	sourceName := strings.TrimSpace(slices[1])
+
+	var sourceType string
+
+	switch typeString {
+	case "configMap":
+		sourceType = "ConfigMap"
+		envVarSource.ConfigMapRef = &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			}}
+
+		if !referencedConfigMaps.Has(sourceName) {
+			referencedConfigMaps.Insert(sourceName)
+		}
+	case "secret":
+		sourceType = "Secret"
+		envVarSource.SecretRef = &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			}}
+		if !referencedSecrets.Has(sourceName) {
+			referencedSecrets.Insert(sourceName)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
+	}
+
+	if len(sourceName) == 0 {
+		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
+	}
+
+	return &envVarSource, nil
+}
+
+func createEnvVarSource(slices []string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvVarSource, error) {
+
+	if len(slices) != 3 {
+		return nil, fmt.Errorf("env requires a value in form \"resourceType:name:key\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
+	}
+
+	envVarSource := corev1.EnvVarSource{}
+
+	typeString := strings.TrimSpace(slices[0])
+	sourceName := strings.TrimSpace(slices[1])
+	sourceKey := strings.TrimSpace(slices[2])
+
+	var sourceType string
+
+	switch typeString {
+	case "configMap":
+		sourceType = "ConfigMap"
+		envVarSource.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			},
+			Key: sourceKey}
+
+		if !referencedConfigMaps.Has(sourceName) {
+			referencedConfigMaps.Insert(sourceName)
+		}
+	case "secret":
+		sourceType = "Secret"
+		envVarSource.SecretKeyRef = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: sourceName,
+			},
+			Key: sourceKey}
+
+		if !referencedSecrets.Has(sourceName) {
+			referencedSecrets.Insert(sourceName)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
+	}
+
+	if len(sourceName) == 0 {
+		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
+	}
+
+	if len(sourceKey) == 0 {
+		return nil, fmt.Errorf("the key referenced by resource %s %q cannot be an empty string", sourceType, sourceName)
+	}
+
+	return &envVarSource, nil
+}
+
+var evRegex = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
+
+const (
+	ctxIdx = 1
+	valIdx = 2
+)
+
+func processLocalEnvValue(val string) (string, error) {
+	match := evRegex.FindStringSubmatch(val)
+	if len(match) > valIdx {
+		if match[ctxIdx] != "env" {
+			return "", fmt.Errorf("allowed env value entry is \"{{ env:LOCAL_VALUE }}\"; got: %q", match[ctxIdx])
+		}
+		if v, ok := os.LookupEnv(match[valIdx]); ok {
+			return v, nil
+		} else {
+			return "", fmt.Errorf("required local environment variable %q is not set", match[valIdx])
+		}
+	} else {
+		return val, nil
+	}
+}
+
+// ProcessVolumes generates Volumes and VolumeMounts from a function config
+// volumes:
+//   - secret: example-secret                              # mount Secret as Volume
+//     path: /etc/secret-volume
+//   - configMap: example-configMap                        # mount ConfigMap as Volume
+//     path: /etc/configMap-volume
+//   - persistentVolumeClaim: { claimName: example-pvc }   # mount PersistentVolumeClaim as Volume
+//     path: /etc/secret-volume
+//   - emptyDir: {}                                         # mount EmptyDir as Volume
+//     path: /etc/configMap-volume
+//   - projected: { sources: [ { secret: example-secret } ] } # mount projected sources as Volume
+//     path: /etc/projected-volume
+func ProcessVolumes(volumes []fn.Volume, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) ([]corev1.Volume, []corev1.VolumeMount, error) {
+
+	createdVolumes := sets.NewString()
+	usedPaths := sets.NewString()
+
+	newVolumes := []corev1.Volume{}
+	newVolumeMounts := []corev1.VolumeMount{}
+
+	for _, vol := range volumes {
+
+		volumeName := ""
+
+		if vol.Secret != nil {
+			volumeName = "secret-" + *vol.Secret
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: *vol.Secret,
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+
+				if !referencedSecrets.Has(*vol.Secret) {
+					referencedSecrets.Insert(*vol.Secret)
+				}
+			}
+		} else if vol.ConfigMap != nil {
+			volumeName = "config-map-" + *vol.ConfigMap
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: *vol.ConfigMap,
+							},
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+
+				if !referencedConfigMaps.Has(*vol.ConfigMap) {
+					referencedConfigMaps.Insert(*vol.ConfigMap)
+				}
+			}
+		} else if vol.PersistentVolumeClaim != nil {
+			volumeName = "pvc-" + *vol.PersistentVolumeClaim.ClaimName
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: *vol.PersistentVolumeClaim.ClaimName,
+							ReadOnly:  vol.PersistentVolumeClaim.ReadOnly,
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+
+				if !referencedPVCs.Has(*vol.PersistentVolumeClaim.ClaimName) {
+					referencedPVCs.Insert(*vol.PersistentVolumeClaim.ClaimName)
+				}
+			}
+		} else if vol.EmptyDir != nil {
+			volumeName = "empty-dir-" + rand.String(7)
+
+			if !createdVolumes.Has(volumeName) {
+
+				var sizeLimit *resource.Quantity
+				if vol.EmptyDir.SizeLimit != nil {
+					sl, err := resource.ParseQuantity(*vol.EmptyDir.SizeLimit)
+					if err != nil {
+						return nil, nil, fmt.Errorf("invalid quantity for sizeLimit: %s. Error: %s", *vol.EmptyDir.SizeLimit, err)
+					}
+					sizeLimit = &sl
+				}
+
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{
+							Medium:    corev1.StorageMedium(vol.EmptyDir.Medium),
+							SizeLimit: sizeLimit,
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+			}
+		} else if vol.Projected != nil {
+			// Named of its path, which is of only one volume, such that the
+			// name is the same on each deployment.
+			volumeName = fmt.Sprintf("projected-%x", sha256.Sum256([]byte(*vol.Path)))[:18]
+
+			if !createdVolumes.Has(volumeName) {
+				newVolumes = append(newVolumes, corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						Projected: &corev1.ProjectedVolumeSource{
+							Sources: projectedSources(vol.Projected.Sources, referencedSecrets, referencedConfigMaps),
+						},
+					},
+				})
+				createdVolumes.Insert(volumeName)
+			}
+		}
+
+		if volumeName != "" {
+			if !usedPaths.Has(*vol.Path) {
+				newVolumeMounts = append(newVolumeMounts, corev1.VolumeMount{
+					Name:      volumeName,
+					MountPath: *vol.Path,
+				})
+				usedPaths.Insert(*vol.Path)
+			} else {
+				return nil, nil, fmt.Errorf("mount path %s is defined multiple times", *vol.Path)
+			}
+		}
+	}
+
+	return newVolumes, newVolumeMounts, nil
+}
+
+// projectedSources of a projected volume, noting the secrets and config maps
+// they reference.
+func projectedSources(sources []fn.ProjectedSource, referencedSecrets, referencedConfigMaps *sets.Set[string]) (projections []corev1.VolumeProjection) {
+	for _, s := range sources {
+		switch {
+		case s.Secret != nil:
+			projections = append(projections, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: *s.Secret}},
+			})
+			referencedSecrets.Insert(*s.Secret)
+		case s.ConfigMap != nil:
+			projections = append(projections, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: *s.ConfigMap}},
+			})
+			referencedConfigMaps.Insert(*s.ConfigMap)
+		case s.ServiceAccountToken != nil:
+			projections = append(projections, corev1.VolumeProjection{
+				ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+					Path:              s.ServiceAccountToken.Path,
+					Audience:          s.ServiceAccountToken.Audience,
+					ExpirationSeconds: s.ServiceAccountToken.ExpirationSeconds,
+				},
+			})
+		}
+	}
+	return
+}
+
+// CheckResourcesArePresent returns error if Secrets or ConfigMaps
+// referenced in input sets are not deployed on the cluster in the specified namespace
+func CheckResourcesArePresent(ctx context.Context, namespace string, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string], referencedServiceAccount string) error {
+
+	errMsg := ""
+	for s := range *referencedSecrets {
+		_, err := GetSecret(ctx, s, namespace)
+		if err != nil {
+			if errors.IsForbidden(err) {
+				errMsg += " Ensure that the service account has the necessary permissions to access the secret.\n"
+			} else {
+				errMsg += fmt.Sprintf("  referenced Secret \"%s\" is not present in namespace \"%s\"\n", s, namespace)
+			}
+		}
+	}
+
+	for cm := range *referencedConfigMaps {
+		_, err := GetConfigMap(ctx, cm, namespace)
+		if err != nil {
+			errMsg += fmt.Sprintf("  referenced ConfigMap \"%s\" is not present in namespace \"%s\"\n", cm, namespace)
+		}
+	}
+
+	for pvc := range *referencedPVCs {
+		_, err := GetPersistentVolumeClaim(ctx, pvc, namespace)
+		if err != nil {
+			errMsg += fmt.Sprintf("  referenced PersistentVolumeClaim \"%s\" is not present in namespace \"%s\"\n", pvc, namespace)
+		}
+	}
+
+	// check if referenced ServiceAccount is present in the namespace if it is not default
+	if referencedServiceAccount != "" && referencedServiceAccount != "default" {
+		err := GetServiceAccount(ctx, referencedServiceAccount, namespace)
+		if err != nil {
+			errMsg += fmt.Sprintf("  referenced ServiceAccount \"%s\" is not present in namespace \"%s\"\n", referencedServiceAccount, namespace)
+		}
+	}
+
+	if errMsg != "" {
+		return fmt.Errorf("error(s) while validating resources:\n%s", errMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+func Test_SetHealthEndpoints(t *testing.T) {
+	f := fn.Function{
+		Name: "testing",
+		Deploy: fn.DeploySpec{
+			HealthEndpoints: fn.HealthEndpoints{
+				Liveness:  "/lively",
+				Readiness: "/readyAsIllEverBe",
+			},
+		},
+	}
+	c := corev1.Container{}
+	SetHealthEndpoints(f, &c)
+	got := c.LivenessProbe.HTTPGet.Path
+	if got != "/lively" {
+		t.Errorf("expected \"/lively\" but got %v", got)
+	}
+	got = c.ReadinessProbe.HTTPGet.Path
+	if got != "/readyAsIllEverBe" {
+		t.Errorf("expected \"readyAsIllEverBe\" but got %v", got)
+	}
+}
+
+// Test_SetExtendedResources ensures that the extended resources of a function
+// are both requested and limited, retaining its other resources.
+func Test_SetExtendedResources(t *testing.T) {
+	c := &corev1.Container{Resources: corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}}
+	SetExtendedResources(&fn.ResourcesOptions{Extended: map[string]int64{"nvidia.com/gpu": 2}}, c)
+	gpu := corev1.ResourceName("nvidia.com/gpu")
+	if q := c.Resources.Requests[gpu]; q.Value() != 2 {
+		t.Errorf("expected 2 gpus requested, got %v", q.String())
+	}
+	if q := c.Resources.Limits[gpu]; q.Value() != 2 {
+		t.Errorf("expected 2 gpus limited, got %v", q.String())
+	}
+	if q := c.Resources.Limits[corev1.ResourceMemory]; q.String() != "1Gi" {
+		t.Errorf("expected the memory limit retained, got %v", q.String())
+	}
+}
+
+// Test_ProcessVolumes_Projected ensures that a projected volume is of each of
+// its sources, named the same of its path on each deployment, and that the
+// secrets and config maps it projects are referenced.
+func Test_ProcessVolumes_Projected(t *testing.T) {
+	secret, cm, path := "s", "cm", "/etc/projected"
+	volumes := []fn.Volume{{
+		Projected: &fn.Projected{Sources: []fn.ProjectedSource{
+			{Secret: &secret},
+			{ConfigMap: &cm},
+			{ServiceAccountToken: &fn.ServiceAccountToken{Path: "token", Audience: "vault"}},
+		}},
+		Path: &path,
+	}}
+	process := func() ([]corev1.Volume, []corev1.VolumeMount, sets.Set[string], sets.Set[string]) {
+		secrets, configMaps, pvcs := sets.New[string](), sets.New[string](), sets.New[string]()
+		vv, mm, err := ProcessVolumes(volumes, &secrets, &configMaps, &pvcs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vv, mm, secrets, configMaps
+	}
+	vv, mm, secrets, configMaps := process()
+	if len(vv) != 1 || vv[0].Projected == nil || len(vv[0].Projected.Sources) != 3 {
+		t.Fatalf("expected a projected volume of three sources, got %+v", vv)
+	}
+	if token := vv[0].Projected.Sources[2].ServiceAccountToken; token == nil || token.Path != "token" || token.Audience != "vault" {
+		t.Fatalf("unexpected token projection %+v", token)
+	}
+	if len(mm) != 1 || mm[0].Name != vv[0].Name || mm[0].MountPath != path {
+		t.Fatalf("unexpected mounts %+v", mm)
+	}
+	if !secrets.Has(secret) || !configMaps.Has(cm) {
+		t.Fatal("expected the projected secret and config map referenced")
+	}
+	if again, _, _, _ := process(); again[0].Name != vv[0].Name {
+		t.Fatalf("expected the volume named the same, got %v and %v", vv[0].Name, again[0].Name)
+	}
+}
+
+// Test_SetScheduling ensures that the scheduling of a function replaces that
+// of the pod spec, its node affinity required of a single term.
+func Test_SetScheduling(t *testing.T) {
+	spec := &corev1.PodSpec{NodeSelector: map[string]string{"old": "true"}}
+	SetScheduling(&fn.SchedulingSpec{
+		NodeSelector: map[string]string{"pool": "gpu"},
+		Tolerations:  []fn.Toleration{{Key: "nvidia.com/gpu", Operator: "Exists", Effect: "NoSchedule"}},
+		Affinity: &fn.Affinity{NodeAffinity: &fn.NodeAffinity{
+			Required:  []fn.NodeSelectorRequirement{{Key: "gpu", Operator: "In", Values: []string{"a100", "h100"}}},
+			Preferred: []fn.PreferredSchedulingTerm{{Weight: 10, Match: []fn.NodeSelectorRequirement{{Key: "spot", Operator: "Exists"}}}},
+		}},
+	}, spec)
+	if !reflect.DeepEqual(spec.NodeSelector, map[string]string{"pool": "gpu"}) {
+		t.Errorf("unexpected node selector %v", spec.NodeSelector)
+	}
+	if len(spec.Tolerations) != 1 || spec.Tolerations[0].Operator != corev1.TolerationOpExists || spec.Tolerations[0].Effect != corev1.TaintEffectNoSchedule {
+		t.Errorf("unexpected tolerations %+v", spec.Tolerations)
+	}
+	a := spec.Affinity.NodeAffinity
+	if terms := a.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms; len(terms) != 1 || len(terms[0].MatchExpressions) != 1 ||
+		terms[0].MatchExpressions[0].Operator != corev1.NodeSelectorOpIn {
+		t.Errorf("unexpected required node affinity %+v", terms)
+	}
+	if p := a.PreferredDuringSchedulingIgnoredDuringExecution; len(p) != 1 || p[0].Weight != 10 {
+		t.Errorf("unexpected preferred node affinity %+v", p)
+	}
+
+	SetScheduling(nil, spec)
+	if spec.NodeSelector != nil || spec.Tolerations != nil || spec.Affinity != nil {
+		t.Errorf("expected the scheduling of no spec removed, got %+v", spec)
+	}
+}
+
+func Test_setHealthEndpointDefaults(t *testing.T) {
+	f := fn.Function{
+		Name: "testing",
+	}
+	c := corev1.Container{}
+	SetHealthEndpoints(f, &c)
+	got := c.LivenessProbe.HTTPGet.Path
+	if got != DefaultLivenessEndpoint {
+		t.Errorf("expected \"%v\" but got %v", DefaultLivenessEndpoint, got)
+	}
+	got = c.ReadinessProbe.HTTPGet.Path
+	if got != DefaultReadinessEndpoint {
+		t.Errorf("expected \"%v\" but got %v", DefaultReadinessEndpoint, got)
+	}
+}
+
+func Test_processValue(t *testing.T) {
+	testEnvVarOld, testEnvVarOldExists := os.LookupEnv("TEST_K8S_WORKLOAD")
+	os.Setenv("TEST_K8S_WORKLOAD", "VALUE_FOR_TEST_K8S_WORKLOAD")
+	defer func() {
+		if testEnvVarOldExists {
+			os.Setenv("TEST_K8S_WORKLOAD", testEnvVarOld)
+		} else {
+			os.Unsetenv("TEST_K8S_WORKLOAD")
+		}
+	}()
+
+	unsetVarOld, unsetVarOldExists := os.LookupEnv("UNSET_VAR")
+	os.Unsetenv("UNSET_VAR")
+	defer func() {
+		if unsetVarOldExists {
+			os.Setenv("UNSET_VAR", unsetVarOld)
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "simple value", arg: "A_VALUE", want: "A_VALUE", wantErr: false},
+		{name: "using envvar value", arg: "{{ env:TEST_K8S_WORKLOAD }}", want: "VALUE_FOR_TEST_K8S_WORKLOAD", wantErr: false},
+		{name: "bad context", arg: "{{secret:S}}", want: "", wantErr: true},
+		{name: "unset envvar", arg: "{{env:SOME_UNSET_VAR}}", want: "", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := processLocalEnvValue(test.arg)
+			if (err != nil) != test.wantErr {
+				t.Errorf("processValue() error = %v, wantErr %v", err, test.wantErr)
+				return
+			}
+			if got != test.want {
+				t.Errorf("processValue() got = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
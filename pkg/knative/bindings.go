@@ -0,0 +1,147 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	messagingv1 "knative.dev/eventing/pkg/apis/messaging/v1"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	eventingversioned "knative.dev/eventing/pkg/client/clientset/versioned"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Kinds of the event bindings of a function.
+const (
+	bindingKindTrigger      = "Trigger"
+	bindingKindSinkBinding  = "SinkBinding"
+	bindingKindSubscription = "Subscription"
+)
+
+// eventBindings of the service: the triggers, sink bindings and channel
+// subscriptions in its namespace whose sink is the service.  Those APIs of
+// Eventing which are not installed on the cluster are skipped.
+func eventBindings(ctx context.Context, client eventingversioned.Interface, service *v1.Service) ([]fn.EventBinding, error) {
+	sinks, err := listEventSinks(ctx, client, service.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the event bindings of %v: %w", service.Name, err)
+	}
+	return sinks.bindings(service), nil
+}
+
+// eventSinks are the triggers, sink bindings and channel subscriptions of a
+// namespace, or of all namespaces, listed once such that the bindings of each
+// of its services are found without listing them again.
+type eventSinks struct {
+	triggers      []eventingv1.Trigger
+	sinkBindings  []sourcesv1.SinkBinding
+	subscriptions []messagingv1.Subscription
+}
+
+// listEventSinks of the namespace, all if empty.  Those APIs of Eventing
+// which are not installed on the cluster are skipped.
+func listEventSinks(ctx context.Context, client eventingversioned.Interface, namespace string) (*eventSinks, error) {
+	sinks := &eventSinks{}
+
+	triggers, err := client.EventingV1().Triggers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("cannot list triggers: %w", err)
+	} else if err == nil {
+		sinks.triggers = triggers.Items
+	}
+
+	sinkBindings, err := client.SourcesV1().SinkBindings(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("cannot list sink bindings: %w", err)
+	} else if err == nil {
+		sinks.sinkBindings = sinkBindings.Items
+	}
+
+	subscriptions, err := client.MessagingV1().Subscriptions(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("cannot list subscriptions: %w", err)
+	} else if err == nil {
+		sinks.subscriptions = subscriptions.Items
+	}
+	return sinks, nil
+}
+
+// listEventSinksOrNone of the namespace, or none should there be no client or
+// should they not be listable, such as by a lack of permission to do so.
+func listEventSinksOrNone(ctx context.Context, client eventingversioned.Interface, namespace string) *eventSinks {
+	if client == nil {
+		return &eventSinks{}
+	}
+	sinks, err := listEventSinks(ctx, client, namespace)
+	if err != nil {
+		return &eventSinks{}
+	}
+	return sinks
+}
+
+// bindings of which the service is the sink, of those in its namespace.
+func (s *eventSinks) bindings(service *v1.Service) []fn.EventBinding {
+	bb := []fn.EventBinding{}
+	for _, t := range s.triggers {
+		if t.Namespace != service.Namespace || !sinksTo(t.Spec.Subscriber, service) {
+			continue
+		}
+		b := fn.EventBinding{Kind: bindingKindTrigger, Name: t.Name, From: "Broker/" + t.Spec.Broker}
+		if t.Spec.Filter != nil {
+			b.Filter = formatAttributes(t.Spec.Filter.Attributes)
+		}
+		bb = append(bb, b)
+	}
+	for _, b := range s.sinkBindings {
+		if b.Namespace != service.Namespace || !sinksTo(b.Spec.Sink, service) {
+			continue
+		}
+		subject := b.Spec.Subject.Kind + "/" + b.Spec.Subject.Name
+		if b.Spec.Subject.Name == "" && b.Spec.Subject.Selector != nil {
+			subject = b.Spec.Subject.Kind + "/" + metav1.FormatLabelSelector(b.Spec.Subject.Selector)
+		}
+		bb = append(bb, fn.EventBinding{Kind: bindingKindSinkBinding, Name: b.Name, From: subject})
+	}
+	for _, sub := range s.subscriptions {
+		if sub.Namespace != service.Namespace || sub.Spec.Subscriber == nil || !sinksTo(*sub.Spec.Subscriber, service) {
+			continue
+		}
+		bb = append(bb, fn.EventBinding{Kind: bindingKindSubscription, Name: sub.Name,
+			From: sub.Spec.Channel.Kind + "/" + sub.Spec.Channel.Name})
+	}
+	return bb
+}
+
+// sinksTo is true if the destination is the service, by reference or by its
+// URL or cluster-local address.
+func sinksTo(d duckv1.Destination, service *v1.Service) bool {
+	if d.Ref != nil {
+		return d.Ref.Kind == "Service" && d.Ref.Name == service.Name &&
+			(d.Ref.Namespace == "" || d.Ref.Namespace == service.Namespace)
+	}
+	if d.URI == nil {
+		return false
+	}
+	if service.Status.URL != nil && d.URI.Host == service.Status.URL.Host {
+		return true
+	}
+	return service.Status.Address != nil && service.Status.Address.URL != nil &&
+		d.URI.Host == service.Status.Address.URL.Host
+}
+
+// formatAttributes of a filter as key=value, sorted by key.
+func formatAttributes(aa map[string]string) string {
+	ss := make([]string, 0, len(aa))
+	for k, v := range aa {
+		ss = append(ss, k+"="+v)
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
@@ -0,0 +1,103 @@
+package knative
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	messagingv1 "knative.dev/eventing/pkg/apis/messaging/v1"
+	sourcesv1 "knative.dev/eventing/pkg/apis/sources/v1"
+	"knative.dev/eventing/pkg/client/clientset/versioned/fake"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"knative.dev/pkg/tracker"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	servingfake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_eventBindings ensures that the triggers, sink bindings and
+// subscriptions whose sink is the service are found, by reference or by URL,
+// and that those of other services are not.
+func Test_eventBindings(t *testing.T) {
+	const ns = "test-ns"
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "myfunc", Namespace: ns}}
+	service.Status.Address = &duckv1.Addressable{URL: apis.HTTP("myfunc.test-ns.svc.cluster.local")}
+
+	ref := func(name string) duckv1.Destination {
+		return duckv1.Destination{Ref: &duckv1.KReference{APIVersion: "serving.knative.dev/v1", Kind: "Service", Name: name}}
+	}
+	meta := func(name string) metav1.ObjectMeta { return metav1.ObjectMeta{Name: name, Namespace: ns} }
+
+	trigger := &eventingv1.Trigger{ObjectMeta: meta("myfunc-trigger"), Spec: eventingv1.TriggerSpec{
+		Broker:     "default",
+		Filter:     &eventingv1.TriggerFilter{Attributes: eventingv1.TriggerFilterAttributes{"type": "example", "source": "s"}},
+		Subscriber: ref("myfunc"),
+	}}
+	other := &eventingv1.Trigger{ObjectMeta: meta("other-trigger"), Spec: eventingv1.TriggerSpec{
+		Broker: "default", Subscriber: ref("other"),
+	}}
+	binding := &sourcesv1.SinkBinding{ObjectMeta: meta("heartbeat")}
+	binding.Spec.Sink = duckv1.Destination{URI: apis.HTTP("myfunc.test-ns.svc.cluster.local")}
+	binding.Spec.Subject = tracker.Reference{APIVersion: "apps/v1", Kind: "Deployment", Name: "heartbeat"}
+	subscriber := ref("myfunc")
+	subscription := &messagingv1.Subscription{ObjectMeta: meta("orders"), Spec: messagingv1.SubscriptionSpec{
+		Channel:    duckv1.KReference{Kind: "InMemoryChannel", Name: "orders"},
+		Subscriber: &subscriber,
+	}}
+
+	client := fake.NewSimpleClientset(trigger, other, binding, subscription)
+	bb, err := eventBindings(context.Background(), client, service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []fn.EventBinding{
+		{Kind: "Trigger", Name: "myfunc-trigger", From: "Broker/default", Filter: "source=s,type=example"},
+		{Kind: "SinkBinding", Name: "heartbeat", From: "Deployment/heartbeat"},
+		{Kind: "Subscription", Name: "orders", From: "InMemoryChannel/orders"},
+	}
+	if !reflect.DeepEqual(bb, want) {
+		t.Errorf("expected bindings\n%+v\ngot\n%+v", want, bb)
+	}
+}
+
+// TestLister_Bindings ensures that the event bindings of listed functions are
+// joined, by namespace, and that functions are listed without them should
+// there be no eventing client.
+func TestLister_Bindings(t *testing.T) {
+	ref := func(name string) duckv1.Destination {
+		return duckv1.Destination{Ref: &duckv1.KReference{Kind: "Service", Name: name}}
+	}
+	trigger := func(namespace, name, subscriber string) *eventingv1.Trigger {
+		return &eventingv1.Trigger{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: eventingv1.TriggerSpec{Broker: "default", Subscriber: ref(subscriber)}}
+	}
+	serving := servingfake.NewSimpleClientset(
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns"}},
+	)
+	eventing := fake.NewSimpleClientset(
+		trigger("ns", "a-1", "a"),
+		trigger("ns", "a-2", "a"),
+		trigger("other", "b-1", "b"), // of a service of the same name elsewhere
+	)
+
+	items, _, err := listServices(context.Background(), serving.ServingV1(), eventing, "", metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || len(items[0].Bindings) != 2 || len(items[1].Bindings) != 0 {
+		t.Fatalf("expected two bindings of a and none of b, got %+v", items)
+	}
+
+	items, _, err = listServices(context.Background(), serving.ServingV1(), nil, "ns", metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || len(items[0].Bindings) != 0 {
+		t.Fatalf("expected no bindings without an eventing client, got %+v", items)
+	}
+}
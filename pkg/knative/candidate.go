@@ -0,0 +1,116 @@
+package knative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	"knative.dev/client/pkg/wait"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// ErrNoCandidate indicates that a function has no candidate revision, deployed
+// by the blue-green strategy, to promote.
+var ErrNoCandidate = errors.New("there is no candidate revision of the function to promote")
+
+// setCandidate of the service, being updated from the previous, such that the
+// revision of the update is routed none of the traffic, but is tagged as the
+// candidate.  Traffic routed to the latest revision is instead routed to the
+// latest ready revision prior to the update, by name, as is that already
+// routed by name.  The traffic is unchanged if there is no ready revision of
+// the previous service to which it may be routed.
+func setCandidate(service, previous *v1.Service) {
+	var blue string
+	if previous != nil {
+		blue = previous.Status.LatestReadyRevisionName
+	}
+	traffic := service.Spec.Traffic
+	if len(traffic) == 0 { // defaulted as routing all traffic to the latest
+		isLatest, all := true, int64(100)
+		traffic = []v1.TrafficTarget{{LatestRevision: &isLatest, Percent: &all}}
+	}
+	var targets []v1.TrafficTarget
+	for _, t := range traffic {
+		if t.Tag == fn.TrafficCandidate {
+			continue // of a previous candidate, not promoted
+		}
+		if t.LatestRevision != nil && *t.LatestRevision {
+			if blue == "" {
+				return
+			}
+			t.LatestRevision = nil
+			t.RevisionName = blue
+		}
+		targets = append(targets, t)
+	}
+	isLatest, none := true, int64(0)
+	service.Spec.Traffic = append(targets, v1.TrafficTarget{Tag: fn.TrafficCandidate, LatestRevision: &isLatest, Percent: &none})
+}
+
+// candidateURL of the route, empty if it has no candidate.
+func candidateURL(route *v1.Route) string {
+	for _, t := range route.Status.Traffic {
+		if t.Tag == fn.TrafficCandidate && t.URL != nil {
+			return t.URL.String()
+		}
+	}
+	return ""
+}
+
+// PromoteCandidate of the deployed Knative service, routing all of its
+// traffic to its candidate revision, by name, removing the candidate's tag.
+// Tagged traffic is retained, routed none of it.  Returned is the name of the
+// revision promoted.
+func PromoteCandidate(ctx context.Context, name, namespace string) (string, error) {
+	client, err := newServingClient(ctx, namespace)
+	if err != nil {
+		return "", err
+	}
+	service, err := client.GetService(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("cannot get %v: %w", name, err)
+	}
+	revision := candidateRevision(service)
+	if revision == "" {
+		return "", ErrNoCandidate
+	}
+	if _, err = client.UpdateServiceWithRetry(ctx, name, promoteService(revision), 3); err != nil {
+		return "", fmt.Errorf("cannot promote %v: %w", name, err)
+	}
+	err, _ = client.WaitForService(ctx, name,
+		clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
+		wait.NoopMessageCallback())
+	return revision, err
+}
+
+// candidateRevision of the service, as resolved by its status, empty if none.
+func candidateRevision(service *v1.Service) string {
+	for _, t := range service.Status.Traffic {
+		if t.Tag == fn.TrafficCandidate {
+			return t.RevisionName
+		}
+	}
+	return ""
+}
+
+// promoteService returns an update of a service's traffic, routing all of it
+// to the revision, without the candidate's tag.
+func promoteService(revision string) func(*v1.Service) (*v1.Service, error) {
+	return func(service *v1.Service) (*v1.Service, error) {
+		service, err := rollbackService(revision)(service)
+		if err != nil {
+			return service, err
+		}
+		var targets []v1.TrafficTarget
+		for _, t := range service.Spec.Traffic {
+			if t.Tag != fn.TrafficCandidate {
+				targets = append(targets, t)
+			}
+		}
+		service.Spec.Traffic = targets
+		return service, nil
+	}
+}
@@ -0,0 +1,69 @@
+package knative
+
+import (
+	"testing"
+
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_setCandidate ensures that the traffic of the latest revision is pinned
+// to that previously ready, the new revision being routed none of it as the
+// candidate, replacing any previous candidate.
+func Test_setCandidate(t *testing.T) {
+	previous := &v1.Service{}
+	previous.Status.LatestReadyRevisionName = "f-00002"
+
+	latest, all, none := true, int64(100), int64(0)
+	service := &v1.Service{}
+	service.Spec.Traffic = []v1.TrafficTarget{
+		{LatestRevision: &latest, Percent: &all},
+		{LatestRevision: &latest, Percent: &none, Tag: fn.TrafficCandidate},
+	}
+	setCandidate(service, previous)
+	tt := service.Spec.Traffic
+	if len(tt) != 2 {
+		t.Fatalf("expected the pinned revision and the candidate, got %v", tt)
+	}
+	if tt[0].RevisionName != "f-00002" || tt[0].LatestRevision != nil || *tt[0].Percent != 100 {
+		t.Errorf("expected all traffic pinned to f-00002, got %v", tt[0])
+	}
+	if tt[1].Tag != fn.TrafficCandidate || !*tt[1].LatestRevision || *tt[1].Percent != 0 {
+		t.Errorf("expected the latest revision the candidate, routed none, got %v", tt[1])
+	}
+
+	// Without a ready revision to pin, the traffic is unchanged.
+	service = &v1.Service{}
+	setCandidate(service, &v1.Service{})
+	if len(service.Spec.Traffic) != 0 {
+		t.Errorf("expected the traffic unchanged, got %v", service.Spec.Traffic)
+	}
+}
+
+// Test_promoteService ensures that all traffic is routed to the candidate, by
+// name, its tag removed.
+func Test_promoteService(t *testing.T) {
+	latest, all, none := true, int64(100), int64(0)
+	service := &v1.Service{}
+	service.Spec.Traffic = []v1.TrafficTarget{
+		{RevisionName: "f-00002", Percent: &all},
+		{LatestRevision: &latest, Percent: &none, Tag: fn.TrafficCandidate},
+	}
+	service.Status.Traffic = []v1.TrafficTarget{
+		{RevisionName: "f-00002", Percent: &all},
+		{RevisionName: "f-00003", Percent: &none, Tag: fn.TrafficCandidate},
+	}
+	revision := candidateRevision(service)
+	if revision != "f-00003" {
+		t.Fatalf("expected the candidate f-00003, got %q", revision)
+	}
+	got, err := promoteService(revision)(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt := got.Spec.Traffic
+	if len(tt) != 1 || tt[0].RevisionName != "f-00003" || *tt[0].Percent != 100 {
+		t.Errorf("expected all traffic to f-00003, got %v", tt)
+	}
+}
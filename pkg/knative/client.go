@@ -1,12 +1,14 @@
 package knative
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
 	clienteventingv1 "knative.dev/client/pkg/eventing/v1"
 	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	eventingversioned "knative.dev/eventing/pkg/client/clientset/versioned"
 	eventingv1 "knative.dev/eventing/pkg/client/clientset/versioned/typed/eventing/v1"
 	servingv1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1"
 
@@ -20,11 +22,29 @@ const (
 )
 
 func NewServingClient(namespace string) (clientservingv1.KnServingClient, error) {
+	return newServingClient(context.Background(), namespace)
+}
+
+// newServingClient of the kubeconfig context carried by ctx, if any.
+func newServingClient(ctx context.Context, namespace string) (clientservingv1.KnServingClient, error) {
+	servingClient, err := newServingV1Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client := clientservingv1.NewKnServingClient(servingClient, namespace)
+
+	return client, nil
+}
+
+// newServingV1Client of the typed Knative Serving API, for those operations,
+// such as watching, which the Knative client does not provide.
+func newServingV1Client(ctx context.Context) (*servingv1.ServingV1Client, error) {
 	if err := validateKubeconfigFile(); err != nil {
 		return nil, err
 	}
 
-	restConfig, err := k8s.GetClientConfig().ClientConfig()
+	restConfig, err := k8s.GetClientConfigFrom(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new serving client: %v", err)
 	}
@@ -33,18 +53,20 @@ func NewServingClient(namespace string) (clientservingv1.KnServingClient, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new serving client: %v", err)
 	}
-
-	client := clientservingv1.NewKnServingClient(servingClient, namespace)
-
-	return client, nil
+	return servingClient, nil
 }
 
 func NewEventingClient(namespace string) (clienteventingv1.KnEventingClient, error) {
+	return newEventingClient(context.Background(), namespace)
+}
+
+// newEventingClient of the kubeconfig context carried by ctx, if any.
+func newEventingClient(ctx context.Context, namespace string) (clienteventingv1.KnEventingClient, error) {
 	if err := validateKubeconfigFile(); err != nil {
 		return nil, err
 	}
 
-	restConfig, err := k8s.GetClientConfig().ClientConfig()
+	restConfig, err := k8s.GetClientConfigFrom(ctx).ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new serving client: %v", err)
 	}
@@ -59,6 +81,25 @@ func NewEventingClient(namespace string) (clienteventingv1.KnEventingClient, err
 	return client, nil
 }
 
+// newEventingClientset of the typed Knative Eventing APIs, of its triggers,
+// sources and messaging, which the Knative client does not all provide.
+func newEventingClientset(ctx context.Context) (eventingversioned.Interface, error) {
+	if err := validateKubeconfigFile(); err != nil {
+		return nil, err
+	}
+
+	restConfig, err := k8s.GetClientConfigFrom(ctx).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new eventing client: %v", err)
+	}
+
+	client, err := eventingversioned.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new eventing client: %v", err)
+	}
+	return client, nil
+}
+
 // validateKubeconfigFile checks if explicitly set KUBECONFIG path exists
 func validateKubeconfigFile() error {
 	kubeconfigPath := os.Getenv("KUBECONFIG")
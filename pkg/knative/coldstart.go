@@ -0,0 +1,202 @@
+package knative
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	"knative.dev/client/pkg/wait"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/perf"
+)
+
+// scaleToZeroPoll is the interval at which the pods of a service are listed
+// while waiting for it to scale to zero.
+const scaleToZeroPoll = 2 * time.Second
+
+// WaitForScaleToZero waits until no pods of the given Knative service exist,
+// including those which are terminating.  The time this takes is governed by
+// the cluster's autoscaler, such as its stable window and scale-to-zero
+// grace period, and is typically a minute or more after the last request.
+// A service whose minimum scale is greater than zero never scales to zero,
+// and so is an error rather than awaited.
+func WaitForScaleToZero(ctx context.Context, name, namespace string) error {
+	serving, err := NewServingClient(namespace)
+	if err != nil {
+		return err
+	}
+	service, err := serving.GetService(ctx, name)
+	if err != nil {
+		return fmt.Errorf("cannot get %v: %w", name, err)
+	}
+	if err = checkScalesToZero(service); err != nil {
+		return err
+	}
+
+	client, namespace, err := k8s.NewClientAndResolvedNamespaceFrom(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("cannot create k8s client: %w", err)
+	}
+	for {
+		pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("serving.knative.dev/service=%s", name),
+		})
+		if err != nil {
+			return fmt.Errorf("cannot list pods of %v: %w", name, err)
+		}
+		if len(pods.Items) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v did not scale to zero (%v pods remain): %w", name, len(pods.Items), ctx.Err())
+		case <-time.After(scaleToZeroPoll):
+		}
+	}
+}
+
+// ScaleDownWindow temporarily sets the autoscaling window of the given
+// Knative service, and retains none of its pods once scaled to zero, such
+// that it scales to zero the given window after its last request rather than
+// after that of the cluster's autoscaler.  The cluster's scale-to-zero grace
+// period, which can not be set per revision, still applies.  A new revision
+// is created and awaited.  The returned restore updates the service to its
+// prior settings, again as a new revision, and should be called once done.
+func ScaleDownWindow(ctx context.Context, name, namespace string, window time.Duration) (restore func(context.Context) error, err error) {
+	client, err := NewServingClient(namespace)
+	if err != nil {
+		return
+	}
+	service, err := client.GetService(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %v: %w", name, err)
+	}
+	prior := map[string]string{}
+	for _, key := range scaleDownAnnotations {
+		if v, ok := service.Spec.Template.Annotations[key]; ok {
+			prior[key] = v
+		}
+	}
+	update := func(ctx context.Context, annotations map[string]string) error {
+		if _, err := client.UpdateServiceWithRetry(ctx, name, scaleDownService(annotations), 3); err != nil {
+			return fmt.Errorf("cannot update the autoscaling window of %v: %w", name, err)
+		}
+		err, _ := client.WaitForService(ctx, name,
+			clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
+			wait.NoopMessageCallback())
+		return err
+	}
+	if err = update(ctx, map[string]string{
+		autoscaling.WindowAnnotationKey:              window.String(),
+		autoscaling.ScaleToZeroPodRetentionPeriodKey: "0s",
+	}); err != nil {
+		return
+	}
+	return func(ctx context.Context) error { return update(ctx, prior) }, nil
+}
+
+// scaleDownAnnotations are those of a service's template set by
+// ScaleDownWindow.
+var scaleDownAnnotations = []string{autoscaling.WindowAnnotationKey, autoscaling.ScaleToZeroPodRetentionPeriodKey}
+
+// scaleDownService returns an update of a service setting its scale down
+// annotations to those given, those not given being removed.
+func scaleDownService(annotations map[string]string) func(*v1.Service) (*v1.Service, error) {
+	return func(service *v1.Service) (*v1.Service, error) {
+		if service.Spec.Template.Annotations == nil {
+			service.Spec.Template.Annotations = map[string]string{}
+		}
+		for _, key := range scaleDownAnnotations {
+			if v, ok := annotations[key]; ok {
+				service.Spec.Template.Annotations[key] = v
+			} else {
+				delete(service.Spec.Template.Annotations, key)
+			}
+		}
+		// A new revision is created, of a generated name.
+		service.Spec.Template.Name = ""
+		return service, nil
+	}
+}
+
+// checkScalesToZero returns an error if the service's minimum scale is
+// greater than zero, such that it is never scaled to zero.
+func checkScalesToZero(service *v1.Service) error {
+	if min := scaleAnnotation(service.Spec.Template.Annotations, autoscaling.MinScaleAnnotationKey); min != nil && *min > 0 {
+		return fmt.Errorf("%v never scales to zero, its minimum scale being %v. Deploy it with a minimum scale of 0 (options.scale.min of func.yaml) to measure its cold starts", service.Name, *min)
+	}
+	return nil
+}
+
+// ColdStart sends a request to the given Knative service, served at url,
+// which is expected to have been scaled to zero (see WaitForScaleToZero).
+// Returned is the sample of the cold start, including the times at which the
+// pod which served the request was created, started and became ready.
+func ColdStart(ctx context.Context, name, namespace, url string, insecure bool) (s perf.Sample, err error) {
+	client, namespace, err := k8s.NewClientAndResolvedNamespaceFrom(ctx, namespace)
+	if err != nil {
+		return s, fmt.Errorf("cannot create k8s client: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { s.FirstByte = time.Now() },
+	}))
+	httpClient := &http.Client{Transport: &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: insecure}, // #nosec G402 -- opt-in via --insecure
+		DisableKeepAlives: true,                                      // each sample is a new connection
+	}}
+	s.Requested = time.Now()
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return s, fmt.Errorf("cannot invoke %v: %w", url, err)
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	s.StatusCode = res.StatusCode
+
+	// The pod which served the request is the first created for it.
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("serving.knative.dev/service=%s", name),
+	})
+	if err != nil {
+		return s, fmt.Errorf("cannot list pods of %v: %w", name, err)
+	}
+	var pod *corev1.Pod
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if pod == nil || p.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			pod = p
+		}
+	}
+	if pod == nil {
+		return // served without a pod, such as by a cached response
+	}
+	s.Pod = pod.Name
+	s.Created = pod.CreationTimestamp.Time
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == "user-container" && cs.State.Running != nil {
+			s.Started = cs.State.Running.StartedAt.Time
+		}
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			s.Ready = c.LastTransitionTime.Time
+		}
+	}
+	return
+}
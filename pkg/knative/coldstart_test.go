@@ -0,0 +1,61 @@
+package knative
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// TestCheckScalesToZero ensures that a service whose minimum scale is greater
+// than zero is not awaited to scale to zero.
+func TestCheckScalesToZero(t *testing.T) {
+	for min, scales := range map[string]bool{"": true, "0": true, "1": false} {
+		service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "f"}}
+		if min != "" {
+			service.Spec.Template.Annotations = map[string]string{autoscaling.MinScaleAnnotationKey: min}
+		}
+		if err := checkScalesToZero(service); (err == nil) != scales {
+			t.Errorf("min-scale %q: expected scaling to zero %v, got %v", min, scales, err)
+		}
+	}
+}
+
+// TestScaleDownService ensures that the scale down annotations of a service
+// are set, and restored, leaving its others and creating a new revision.
+func TestScaleDownService(t *testing.T) {
+	service := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "f"}}
+	service.Spec.Template.Name = "f-00001"
+	service.Spec.Template.Annotations = map[string]string{
+		autoscaling.MinScaleAnnotationKey: "0",
+		autoscaling.WindowAnnotationKey:   "2m",
+	}
+
+	service, err := scaleDownService(map[string]string{
+		autoscaling.WindowAnnotationKey:              "6s",
+		autoscaling.ScaleToZeroPodRetentionPeriodKey: "0s",
+	})(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotations := service.Spec.Template.Annotations
+	if annotations[autoscaling.WindowAnnotationKey] != "6s" || annotations[autoscaling.ScaleToZeroPodRetentionPeriodKey] != "0s" {
+		t.Errorf("expected the scale down annotations to be set, got %v", annotations)
+	}
+	if annotations[autoscaling.MinScaleAnnotationKey] != "0" {
+		t.Errorf("expected other annotations to be retained, got %v", annotations)
+	}
+	if service.Spec.Template.Name != "" {
+		t.Errorf("expected a new revision of a generated name, got %q", service.Spec.Template.Name)
+	}
+
+	// Restored, the window is that prior and the retention period unset
+	if service, err = scaleDownService(map[string]string{autoscaling.WindowAnnotationKey: "2m"})(service); err != nil {
+		t.Fatal(err)
+	}
+	annotations = service.Spec.Template.Annotations
+	if _, ok := annotations[autoscaling.ScaleToZeroPodRetentionPeriodKey]; ok || annotations[autoscaling.WindowAnnotationKey] != "2m" {
+		t.Errorf("expected the prior scale down annotations to be restored, got %v", annotations)
+	}
+}
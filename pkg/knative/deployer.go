@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"regexp"
 	"strings"
 	"time"
 
@@ -17,12 +16,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/client/pkg/flags"
 	servingclientlib "knative.dev/client/pkg/serving"
 	clientservingv1 "knative.dev/client/pkg/serving/v1"
-	"knative.dev/client/pkg/wait"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	v1 "knative.dev/serving/pkg/apis/serving/v1"
 
@@ -30,8 +27,8 @@ import (
 	"knative.dev/func/pkg/k8s"
 )
 
-const LIVENESS_ENDPOINT = "/health/liveness"
-const READINESS_ENDPOINT = "/health/readiness"
+const LIVENESS_ENDPOINT = k8s.DefaultLivenessEndpoint
+const READINESS_ENDPOINT = k8s.DefaultReadinessEndpoint
 
 type DeployDecorator interface {
 	UpdateAnnotations(fn.Function, map[string]string) map[string]string
@@ -45,6 +42,10 @@ type Deployer struct {
 	verbose bool
 
 	decorator DeployDecorator
+
+	// isOpenShift reports whether the cluster is OpenShift, of an
+	// OpenShift-aware deployer.
+	isOpenShift func() bool
 }
 
 // ActiveNamespace attempts to read the Kubernetes active namespace.
@@ -88,7 +89,7 @@ func (d *Deployer) isImageInPrivateRegistry(ctx context.Context, client clientse
 	if err != nil {
 		return false
 	}
-	k8sClient, err := k8s.NewKubernetesClientset()
+	k8sClient, err := k8s.NewKubernetesClientsetFrom(ctx)
 	if err != nil {
 		return false
 	}
@@ -111,7 +112,7 @@ func (d *Deployer) isImageInPrivateRegistry(ctx context.Context, client clientse
 	return false
 }
 
-func onClusterFix(f fn.Function) fn.Function {
+func onClusterFix(ctx context.Context, f fn.Function) fn.Function {
 	// This only exists because of a bootstapping problem with On-Cluster
 	// builds:  It appears that, when sending a function to be built on-cluster
 	// the target namespace is not being transmitted in the pipeline
@@ -120,13 +121,13 @@ func onClusterFix(f fn.Function) fn.Function {
 	// earlier versions of this logic relied entirely on the current
 	// kubernetes context.
 	if f.Namespace == "" && f.Deploy.Namespace == "" {
-		f.Namespace, _ = k8s.GetDefaultNamespace()
+		f.Namespace, _, _ = k8s.GetClientConfigFrom(ctx).Namespace()
 	}
 	return f
 }
 
 func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResult, error) {
-	f = onClusterFix(f)
+	f = onClusterFix(ctx, f)
 	// Choosing f.Namespace vs f.Deploy.Namespace:
 	// This is minimal logic currently required of all deployer impls.
 	// If f.Namespace is defined, this is the (possibly new) target
@@ -153,25 +154,35 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 	if f.Deploy.Image == "" {
 		f.Deploy.Image = f.Build.Image
 	}
+	d.warnRoute(os.Stderr, f)
+	f = d.withRoute(f)
+
+	wo := waitOptionsFrom(ctx)
+	ro := k8s.RetryOptionsFrom(ctx)
 
 	// Clients
-	client, err := NewServingClient(namespace)
+	client, err := newServingClient(ctx, namespace)
 	if err != nil {
 		return fn.DeploymentResult{}, wrapDeployerClientError(err)
 	}
-	eventingClient, err := NewEventingClient(namespace)
+	eventingClient, err := newEventingClient(ctx, namespace)
 	if err != nil {
 		return fn.DeploymentResult{}, wrapDeployerClientError(err)
 	}
-	// check if 'dapr-system' namespace exists
-	daprInstalled := false
-	k8sClient, err := k8s.NewKubernetesClientset()
+	domainClient, err := newDomainMappingClient(ctx)
 	if err != nil {
 		return fn.DeploymentResult{}, wrapDeployerClientError(err)
 	}
-	_, err = k8sClient.CoreV1().Namespaces().Get(ctx, "dapr-system", metav1.GetOptions{})
-	if err == nil {
-		daprInstalled = true
+	daprInstalled, err := isDaprInstalled(ctx)
+	if err != nil {
+		return fn.DeploymentResult{}, wrapDeployerClientError(err)
+	}
+	k8sClient, err := k8s.NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return fn.DeploymentResult{}, wrapDeployerClientError(err)
+	}
+	if err = checkFeatures(ctx, k8sClient, f); err != nil {
+		return fn.DeploymentResult{}, err
 	}
 
 	var outBuff SynchronizedBuffer
@@ -202,13 +213,15 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				return fn.DeploymentResult{}, err
 			}
 
-			err = checkResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
+			err = k8s.CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
 			if err != nil {
 				err = fmt.Errorf("knative deployer failed to generate the Knative Service: %v", err)
 				return fn.DeploymentResult{}, err
 			}
 
-			err = client.CreateService(ctx, service)
+			err = k8s.Retry(ctx, ro, func() error {
+				return client.CreateService(ctx, service)
+			})
 			if err != nil {
 				err = fmt.Errorf("knative deployer failed to deploy the Knative Service: %v", err)
 				return fn.DeploymentResult{}, err
@@ -229,10 +242,7 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				close(chprivate)
 			}()
 			go func() {
-				err, _ := client.WaitForService(ctx, f.Name,
-					clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
-					wait.NoopMessageCallback())
-				cherr <- err
+				cherr <- waitForService(ctx, client, f.Name, wo)
 				close(cherr)
 			}()
 
@@ -264,9 +274,8 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				return fn.DeploymentResult{}, err
 			}
 
-			route, err := client.GetRoute(ctx, f.Name)
+			route, err := getRoute(ctx, client, f.Name, wo)
 			if err != nil {
-				err = fmt.Errorf("knative deployer failed to get the Route: %v", err)
 				return fn.DeploymentResult{}, err
 			}
 
@@ -275,6 +284,10 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 				return fn.DeploymentResult{}, err
 			}
 
+			if err = syncDomainMappings(ctx, domainClient, f, namespace); err != nil {
+				return fn.DeploymentResult{}, err
+			}
+
 			if d.verbose {
 				fmt.Printf("Function deployed in namespace %q and exposed at URL:\n%s\n", namespace, route.Status.URL.String())
 			}
@@ -294,32 +307,36 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 		referencedConfigMaps := sets.New[string]()
 		referencedPVCs := sets.New[string]()
 
-		newEnv, newEnvFrom, err := processEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+		newEnv, newEnvFrom, err := k8s.ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
 		if err != nil {
 			return fn.DeploymentResult{}, err
 		}
 
-		newVolumes, newVolumeMounts, err := processVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+		newVolumes, newVolumeMounts, err := k8s.ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
 		if err != nil {
 			return fn.DeploymentResult{}, err
 		}
 
-		err = checkResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
+		err = k8s.CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName)
 		if err != nil {
 			err = fmt.Errorf("knative deployer failed to update the Knative Service: %v", err)
 			return fn.DeploymentResult{}, err
 		}
 
-		_, err = client.UpdateServiceWithRetry(ctx, f.Name, updateService(f, previousService, newEnv, newEnvFrom, newVolumes, newVolumeMounts, d.decorator, daprInstalled), 3)
+		update := updateService(f, previousService, newEnv, newEnvFrom, newVolumes, newVolumeMounts, d.decorator, daprInstalled)
+
+		// Each attempt updates the service as last got, such that an update
+		// conflicting with another is made anew.
+		err = k8s.Retry(ctx, ro, func() error {
+			_, err := client.UpdateServiceWithRetry(ctx, f.Name, update, 1)
+			return err
+		})
 		if err != nil {
 			err = fmt.Errorf("knative deployer failed to update the Knative Service: %v", err)
 			return fn.DeploymentResult{}, err
 		}
 
-		err, _ = client.WaitForService(ctx, f.Name,
-			clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
-			wait.NoopMessageCallback())
-		if err != nil {
+		if err = waitForService(ctx, client, f.Name, wo); err != nil {
 			if !d.verbose {
 				fmt.Fprintln(os.Stderr, "\nService output:")
 				_, _ = io.Copy(os.Stderr, &outBuff)
@@ -328,17 +345,39 @@ func (d *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 			return fn.DeploymentResult{}, err
 		}
 
-		route, err := client.GetRoute(ctx, f.Name)
+		route, err := getRoute(ctx, client, f.Name, wo)
 		if err != nil {
-			err = fmt.Errorf("knative deployer failed to get the Route: %v", err)
 			return fn.DeploymentResult{}, err
 		}
 
+		// Deploying a paused function brings it back online.
+		if _, paused := previousService.Annotations[PausedAnnotation]; paused {
+			if err = unblockIngress(ctx, f.Name, namespace); err != nil {
+				return fn.DeploymentResult{}, err
+			}
+		}
+
 		err = createTriggers(ctx, f, client, eventingClient)
 		if err != nil {
 			return fn.DeploymentResult{}, err
 		}
 
+		if err = syncDomainMappings(ctx, domainClient, f, namespace); err != nil {
+			return fn.DeploymentResult{}, err
+		}
+
+		// Old revisions beyond those of the history retained are deleted, the
+		// function being deployed regardless of their deletion.
+		if keep := f.Deploy.Options.RevisionHistoryLimit; keep != nil {
+			if err = pruneRevisions(ctx, client, f.Name, previousService.Status.LatestCreatedRevisionName, *keep, d.verbose); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: unable to delete old revisions: %v\n", err)
+			}
+		}
+
+		if url := candidateURL(route); url != "" && f.Deploy.Strategy == fn.StrategyBlueGreen {
+			fmt.Fprintf(os.Stderr, "🔵 Candidate revision deployed, routed none of the traffic, at URL:\n   %v\n", url)
+		}
+
 		return fn.DeploymentResult{
 			Status:    fn.Updated,
 			URL:       route.Status.URL.String(),
@@ -356,6 +395,19 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 
 	fmt.Fprintf(os.Stderr, "🎯 Creating Triggers on the cluster\n")
 
+	for _, trigger := range generateTriggers(f, ksvc) {
+		err = eventingClient.CreateTrigger(ctx, trigger)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			err = fmt.Errorf("knative deployer failed to create the Trigger: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// generateTriggers of the function's subscriptions, delivering to its
+// service.
+func generateTriggers(f fn.Function, ksvc *v1.Service) (triggers []*eventingv1.Trigger) {
 	for i, sub := range f.Deploy.Subscriptions {
 		// create the filter:
 		attributes := make(map[string]string)
@@ -363,7 +415,7 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 			attributes[key] = value
 		}
 
-		err = eventingClient.CreateTrigger(ctx, &eventingv1.Trigger{
+		triggers = append(triggers, &eventingv1.Trigger{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: fmt.Sprintf("%s-function-trigger-%d", ksvc.Name, i),
 				OwnerReferences: []metav1.OwnerReference{
@@ -390,37 +442,8 @@ func createTriggers(ctx context.Context, f fn.Function, client clientservingv1.K
 				},
 			},
 		})
-		if err != nil && !errors.IsAlreadyExists(err) {
-			err = fmt.Errorf("knative deployer failed to create the Trigger: %v", err)
-			return err
-		}
-	}
-	return nil
-}
-
-func probeFor(url string) *corev1.Probe {
-	return &corev1.Probe{
-		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: &corev1.HTTPGetAction{
-				Path: url,
-			},
-		},
-	}
-}
-
-func setHealthEndpoints(f fn.Function, c *corev1.Container) *corev1.Container {
-	// Set the defaults
-	c.LivenessProbe = probeFor(LIVENESS_ENDPOINT)
-	c.ReadinessProbe = probeFor(READINESS_ENDPOINT)
-
-	// If specified in func.yaml, the provided values override the defaults
-	if f.Deploy.HealthEndpoints.Liveness != "" {
-		c.LivenessProbe = probeFor(f.Deploy.HealthEndpoints.Liveness)
 	}
-	if f.Deploy.HealthEndpoints.Readiness != "" {
-		c.ReadinessProbe = probeFor(f.Deploy.HealthEndpoints.Readiness)
-	}
-	return c
+	return
 }
 
 func generateNewService(f fn.Function, decorator DeployDecorator, daprInstalled bool) (*v1.Service, error) {
@@ -442,39 +465,39 @@ func generateNewService(f fn.Function, decorator DeployDecorator, daprInstalled
 			SeccompProfile:           &seccompProfile,
 		},
 	}
-	setHealthEndpoints(f, &container)
+	k8s.SetHealthEndpoints(f, &container)
 
 	referencedSecrets := sets.New[string]()
 	referencedConfigMaps := sets.New[string]()
 	referencedPVC := sets.New[string]()
 
-	newEnv, newEnvFrom, err := processEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	newEnv, newEnvFrom, err := k8s.ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
 	if err != nil {
 		return nil, err
 	}
 	container.Env = newEnv
 	container.EnvFrom = newEnvFrom
 
-	newVolumes, newVolumeMounts, err := processVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVC)
+	newVolumes, newVolumeMounts, err := k8s.ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVC)
 	if err != nil {
 		return nil, err
 	}
 	container.VolumeMounts = newVolumeMounts
 
-	labels, err := generateServiceLabels(f, decorator)
+	labels, err := generateServiceLabels(f, decorator, fn.ScopeService)
 	if err != nil {
 		return nil, err
 	}
-
-	annotations := generateServiceAnnotations(f, decorator, nil, daprInstalled)
-
-	// we need to create a separate map for Annotations specified in a Revision,
-	// in case we will need to specify autoscaling annotations -> these could be only in a Revision not in a Service
-	revisionAnnotations := make(map[string]string)
-	for k, v := range annotations {
-		revisionAnnotations[k] = v
+	revisionLabels, err := generateServiceLabels(f, decorator, fn.ScopeRevision)
+	if err != nil {
+		return nil, err
 	}
 
+	// The annotations of the revision template are a map of their own, as
+	// those of autoscaling are set only on the revision, not on the service.
+	annotations := generateServiceAnnotations(f, decorator, nil, daprInstalled, fn.ScopeService)
+	revisionAnnotations := generateServiceAnnotations(f, decorator, nil, daprInstalled, fn.ScopeRevision)
+
 	service := &v1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        f.Name,
@@ -485,7 +508,7 @@ func generateNewService(f fn.Function, decorator DeployDecorator, daprInstalled
 			ConfigurationSpec: v1.ConfigurationSpec{
 				Template: v1.RevisionTemplateSpec{
 					ObjectMeta: metav1.ObjectMeta{
-						Labels:      labels,
+						Labels:      revisionLabels,
 						Annotations: revisionAnnotations,
 					},
 					Spec: v1.RevisionSpec{
@@ -506,15 +529,17 @@ func generateNewService(f fn.Function, decorator DeployDecorator, daprInstalled
 	if err != nil {
 		return service, err
 	}
+	k8s.SetScheduling(f.Deploy.Scheduling, &service.Spec.Template.Spec.PodSpec)
+	setTraffic(service, nil, f.Deploy.Traffic)
 
 	return service, nil
 }
 
 // generateServiceLabels creates a final map of service labels based
-// on the function's defined labels plus the
-// application of any provided label decorator.
-func generateServiceLabels(f fn.Function, d DeployDecorator) (ll map[string]string, err error) {
-	ll, err = f.LabelsMap()
+// on the function's defined labels of the scope (the service or its
+// revisions) plus the application of any provided label decorator.
+func generateServiceLabels(f fn.Function, d DeployDecorator, scope string) (ll map[string]string, err error) {
+	ll, err = f.LabelsMapOf(scope)
 	if err != nil {
 		return
 	}
@@ -531,11 +556,12 @@ func generateServiceLabels(f fn.Function, d DeployDecorator) (ll map[string]stri
 }
 
 // generateServiceAnnotations creates a final map of service annotations based
-// on static defaults plus the function's defined annotations plus the
-// application of any provided annotation decorator.
+// on static defaults plus the function's defined annotations of the scope
+// (the service or its revisions) plus the application of any provided
+// annotation decorator.
 // Also sets `serving.knative.dev/creator` to a value specified in annotations in the service reference in the previousService parameter,
 // this is beneficial when we are updating a service to pass validation on Knative side - the annotation is immutable.
-func generateServiceAnnotations(f fn.Function, d DeployDecorator, previousService *v1.Service, daprInstalled bool) (aa map[string]string) {
+func generateServiceAnnotations(f fn.Function, d DeployDecorator, previousService *v1.Service, daprInstalled bool, scope string) (aa map[string]string) {
 	aa = make(map[string]string)
 
 	if daprInstalled {
@@ -547,7 +573,7 @@ func generateServiceAnnotations(f fn.Function, d DeployDecorator, previousServic
 	}
 
 	// Function-defined annotations
-	for k, v := range f.Deploy.Annotations {
+	for k, v := range f.AnnotationsOf(scope) {
 		aa[k] = v
 	}
 
@@ -567,6 +593,17 @@ func generateServiceAnnotations(f fn.Function, d DeployDecorator, previousServic
 	return
 }
 
+// isDaprInstalled on the cluster, as indicated by its 'dapr-system'
+// namespace existing.
+func isDaprInstalled(ctx context.Context) (bool, error) {
+	k8sClient, err := k8s.NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, err = k8sClient.CoreV1().Namespaces().Get(ctx, "dapr-system", metav1.GetOptions{})
+	return err == nil, nil
+}
+
 // annotations which, if included and Dapr control plane is installed in
 // the target cluster will result in a sidecar exposing the dapr HTTP API
 // on localhost:3500 and metrics on 9092
@@ -587,17 +624,8 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		// this prevents conflicts in Revision name when updating the KService from multiple places.
 		service.Spec.Template.Name = ""
 
-		annotations := generateServiceAnnotations(f, decorator, previousService, daprInstalled)
-
-		// we need to create a separate map for Annotations specified in a Revision,
-		// in case we will need to specify autoscaling annotations -> these could be only in a Revision not in a Service
-		revisionAnnotations := make(map[string]string)
-		for k, v := range annotations {
-			revisionAnnotations[k] = v
-		}
-
-		service.Annotations = annotations
-		service.Spec.Template.Annotations = revisionAnnotations
+		service.Annotations = generateServiceAnnotations(f, decorator, previousService, daprInstalled, fn.ScopeService)
+		service.Spec.Template.Annotations = generateServiceAnnotations(f, decorator, previousService, daprInstalled, fn.ScopeRevision)
 
 		// I hate that we have to do this. Users should not see these values.
 		// It is an implementation detail. These health endpoints should not be
@@ -612,20 +640,19 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		// config. At runtime this configuration file could be consulted. I don't
 		// know what this would mean for developers using the func library directly.
 		cp := &service.Spec.Template.Spec.Containers[0]
-		setHealthEndpoints(f, cp)
+		k8s.SetHealthEndpoints(f, cp)
 
 		err := setServiceOptions(&service.Spec.Template, f.Deploy.Options)
 		if err != nil {
 			return service, err
 		}
 
-		labels, err := generateServiceLabels(f, decorator)
-		if err != nil {
+		if service.Labels, err = generateServiceLabels(f, decorator, fn.ScopeService); err != nil {
+			return nil, err
+		}
+		if service.Spec.Template.Labels, err = generateServiceLabels(f, decorator, fn.ScopeRevision); err != nil {
 			return nil, err
 		}
-
-		service.Labels = labels
-		service.Spec.Template.Labels = labels
 
 		err = flags.UpdateImage(&service.Spec.Template.Spec.PodSpec, f.Deploy.Image)
 		if err != nil {
@@ -637,395 +664,22 @@ func updateService(f fn.Function, previousService *v1.Service, newEnv []corev1.E
 		cp.VolumeMounts = newVolumeMounts
 		service.Spec.Template.Spec.Volumes = newVolumes
 		service.Spec.Template.Spec.ServiceAccountName = f.Deploy.ServiceAccountName
-		return service, nil
-	}
-}
-
-// processEnvs generates array of EnvVars and EnvFromSources from a function config
-// envs:
-//   - name: EXAMPLE1                            # ENV directly from a value
-//     value: value1
-//   - name: EXAMPLE2                            # ENV from the local ENV var
-//     value: {{ env:MY_ENV }}
-//   - name: EXAMPLE3
-//     value: {{ secret:example-secret:key }}    # ENV from a key in Secret
-//   - value: {{ secret:example-secret }}        # all ENVs from Secret
-//   - name: EXAMPLE4
-//     value: {{ configMap:configMapName:key }}  # ENV from a key in ConfigMap
-//   - value: {{ configMap:configMapName }}      # all key-pair values from ConfigMap are set as ENV
-func processEnvs(envs []fn.Env, referencedSecrets, referencedConfigMaps *sets.Set[string]) ([]corev1.EnvVar, []corev1.EnvFromSource, error) {
-
-	envs = withOpenAddress(envs) // prepends ADDRESS=0.0.0.0 if not extant
-
-	envVars := []corev1.EnvVar{{Name: "BUILT", Value: time.Now().Format("20060102T150405")}}
-	envFrom := []corev1.EnvFromSource{}
-
-	for _, env := range envs {
-		if env.Name == nil && env.Value != nil {
-			// all key-pair values from secret/configMap are set as ENV, eg. {{ secret:secretName }} or {{ configMap:configMapName }}
-			if strings.HasPrefix(*env.Value, "{{") {
-				envFromSource, err := createEnvFromSource(*env.Value, referencedSecrets, referencedConfigMaps)
-				if err != nil {
-					return nil, nil, err
-				}
-				envFrom = append(envFrom, *envFromSource)
-				continue
-			}
-		} else if env.Name != nil && env.Value != nil {
-			if strings.HasPrefix(*env.Value, "{{") {
-				slices := strings.Split(strings.Trim(*env.Value, "{} "), ":")
-				if len(slices) == 3 {
-					// ENV from a key in secret/configMap, eg. FOO={{ secret:secretName:key }} FOO={{ configMap:configMapName.key }}
-					valueFrom, err := createEnvVarSource(slices, referencedSecrets, referencedConfigMaps)
-					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, ValueFrom: valueFrom})
-					if err != nil {
-						return nil, nil, err
-					}
-					continue
-				} else if len(slices) == 2 {
-					// ENV from the local ENV var, eg. FOO={{ env:LOCAL_ENV }}
-					localValue, err := processLocalEnvValue(*env.Value)
-					if err != nil {
-						return nil, nil, err
-					}
-					envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: localValue})
-					continue
-				}
-			} else {
-				// a standard ENV with key and value, eg. FOO=bar
-				envVars = append(envVars, corev1.EnvVar{Name: *env.Name, Value: *env.Value})
-				continue
-			}
-		}
-		return nil, nil, fmt.Errorf("unsupported env source entry \"%v\"", env)
-	}
-
-	return envVars, envFrom, nil
-}
-
-// withOpenAddresss prepends ADDRESS=0.0.0.0 to the envs if not present.
-//
-// This is combined with the value of PORT at runtime to determine the full
-// Listener address on which a Function will listen tcp requests.
-//
-// Runtimes should, by default, only listen on the loopback interface by
-// default, as they may be `func run` locally, for security purposes.
-// This environment vriable instructs the runtimes to listen on all interfaces
-// by default when actually being deployed, since they will need to actually
-// listen for client requests and for health readiness/liveness probes.
-//
-// Should a user wish to securely open their function to only receive requests
-// on a specific interface, such as a WireGuar-encrypted mesh network which
-// presents as a specific interface, that can be achieved by setting the
-// ADDRESS value as an environment variable on their function to the interface
-// on which to listen.
-//
-// NOTE this env is currently only respected by scaffolded Go functions, because
-// they are the only ones which support being `func run` locally.  Other
-// runtimes will respect the value as they are updated to support scaffolding.
-func withOpenAddress(ee []fn.Env) []fn.Env {
-	// TODO: this is unnecessarily complex due to both key and value of the
-	// envs slice being being pointers.  There is an outstanding tech-debt item
-	// to remove pointers from Function Envs, Volumes, Labels, and Options.
-	var found bool
-	for _, e := range ee {
-		if e.Name != nil && *e.Name == "ADDRESS" {
-			found = true
-			break
-		}
-	}
-	if !found {
-		k := "ADDRESS"
-		v := "0.0.0.0"
-		ee = append(ee, fn.Env{Name: &k, Value: &v})
-	}
-	return ee
-}
-
-func createEnvFromSource(value string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvFromSource, error) {
-	slices := strings.Split(strings.Trim(value, "{} "), ":")
-	if len(slices) != 2 {
-		return nil, fmt.Errorf("env requires a value in form \"resourceType:name\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
-	}
-
-	envVarSource := corev1.EnvFromSource{}
-
-	typeString := strings.TrimSpace(slices[0])
-	sourceName := strings.TrimSpace(slices[1])
-
-	var sourceType string
-
-	switch typeString {
-	case "configMap":
-		sourceType = "ConfigMap"
-		envVarSource.ConfigMapRef = &corev1.ConfigMapEnvSource{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			}}
-
-		if !referencedConfigMaps.Has(sourceName) {
-			referencedConfigMaps.Insert(sourceName)
-		}
-	case "secret":
-		sourceType = "Secret"
-		envVarSource.SecretRef = &corev1.SecretEnvSource{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			}}
-		if !referencedSecrets.Has(sourceName) {
-			referencedSecrets.Insert(sourceName)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
-	}
-
-	if len(sourceName) == 0 {
-		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
-	}
-
-	return &envVarSource, nil
-}
-
-func createEnvVarSource(slices []string, referencedSecrets, referencedConfigMaps *sets.Set[string]) (*corev1.EnvVarSource, error) {
-
-	if len(slices) != 3 {
-		return nil, fmt.Errorf("env requires a value in form \"resourceType:name:key\" where \"resourceType\" can be one of \"configMap\" or \"secret\"; got %q", slices)
-	}
-
-	envVarSource := corev1.EnvVarSource{}
-
-	typeString := strings.TrimSpace(slices[0])
-	sourceName := strings.TrimSpace(slices[1])
-	sourceKey := strings.TrimSpace(slices[2])
-
-	var sourceType string
-
-	switch typeString {
-	case "configMap":
-		sourceType = "ConfigMap"
-		envVarSource.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			},
-			Key: sourceKey}
-
-		if !referencedConfigMaps.Has(sourceName) {
-			referencedConfigMaps.Insert(sourceName)
-		}
-	case "secret":
-		sourceType = "Secret"
-		envVarSource.SecretKeyRef = &corev1.SecretKeySelector{
-			LocalObjectReference: corev1.LocalObjectReference{
-				Name: sourceName,
-			},
-			Key: sourceKey}
-
-		if !referencedSecrets.Has(sourceName) {
-			referencedSecrets.Insert(sourceName)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported env source type %q; supported source types are \"configMap\" or \"secret\"", slices[0])
-	}
-
-	if len(sourceName) == 0 {
-		return nil, fmt.Errorf("the name of %s cannot be an empty string", sourceType)
-	}
-
-	if len(sourceKey) == 0 {
-		return nil, fmt.Errorf("the key referenced by resource %s %q cannot be an empty string", sourceType, sourceName)
-	}
-
-	return &envVarSource, nil
-}
-
-var evRegex = regexp.MustCompile(`^{{\s*(\w+)\s*:(\w+)\s*}}$`)
-
-const (
-	ctxIdx = 1
-	valIdx = 2
-)
-
-func processLocalEnvValue(val string) (string, error) {
-	match := evRegex.FindStringSubmatch(val)
-	if len(match) > valIdx {
-		if match[ctxIdx] != "env" {
-			return "", fmt.Errorf("allowed env value entry is \"{{ env:LOCAL_VALUE }}\"; got: %q", match[ctxIdx])
-		}
-		if v, ok := os.LookupEnv(match[valIdx]); ok {
-			return v, nil
-		} else {
-			return "", fmt.Errorf("required local environment variable %q is not set", match[valIdx])
-		}
-	} else {
-		return val, nil
-	}
-}
-
-// / processVolumes generates Volumes and VolumeMounts from a function config
-// volumes:
-//   - secret: example-secret                              # mount Secret as Volume
-//     path: /etc/secret-volume
-//   - configMap: example-configMap                        # mount ConfigMap as Volume
-//     path: /etc/configMap-volume
-//   - persistentVolumeClaim: { claimName: example-pvc }   # mount PersistentVolumeClaim as Volume
-//     path: /etc/secret-volume
-//   - emptyDir: {}                                         # mount EmptyDir as Volume
-//     path: /etc/configMap-volume
-func processVolumes(volumes []fn.Volume, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string]) ([]corev1.Volume, []corev1.VolumeMount, error) {
-
-	createdVolumes := sets.NewString()
-	usedPaths := sets.NewString()
-
-	newVolumes := []corev1.Volume{}
-	newVolumeMounts := []corev1.VolumeMount{}
-
-	for _, vol := range volumes {
-
-		volumeName := ""
-
-		if vol.Secret != nil {
-			volumeName = "secret-" + *vol.Secret
-
-			if !createdVolumes.Has(volumeName) {
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						Secret: &corev1.SecretVolumeSource{
-							SecretName: *vol.Secret,
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-
-				if !referencedSecrets.Has(*vol.Secret) {
-					referencedSecrets.Insert(*vol.Secret)
-				}
-			}
-		} else if vol.ConfigMap != nil {
-			volumeName = "config-map-" + *vol.ConfigMap
-
-			if !createdVolumes.Has(volumeName) {
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						ConfigMap: &corev1.ConfigMapVolumeSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: *vol.ConfigMap,
-							},
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-
-				if !referencedConfigMaps.Has(*vol.ConfigMap) {
-					referencedConfigMaps.Insert(*vol.ConfigMap)
-				}
-			}
-		} else if vol.PersistentVolumeClaim != nil {
-			volumeName = "pvc-" + *vol.PersistentVolumeClaim.ClaimName
-
-			if !createdVolumes.Has(volumeName) {
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-							ClaimName: *vol.PersistentVolumeClaim.ClaimName,
-							ReadOnly:  vol.PersistentVolumeClaim.ReadOnly,
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-
-				if !referencedPVCs.Has(*vol.PersistentVolumeClaim.ClaimName) {
-					referencedPVCs.Insert(*vol.PersistentVolumeClaim.ClaimName)
-				}
-			}
-		} else if vol.EmptyDir != nil {
-			volumeName = "empty-dir-" + rand.String(7)
-
-			if !createdVolumes.Has(volumeName) {
-
-				var sizeLimit *resource.Quantity
-				if vol.EmptyDir.SizeLimit != nil {
-					sl, err := resource.ParseQuantity(*vol.EmptyDir.SizeLimit)
-					if err != nil {
-						return nil, nil, fmt.Errorf("invalid quantity for sizeLimit: %s. Error: %s", *vol.EmptyDir.SizeLimit, err)
-					}
-					sizeLimit = &sl
-				}
-
-				newVolumes = append(newVolumes, corev1.Volume{
-					Name: volumeName,
-					VolumeSource: corev1.VolumeSource{
-						EmptyDir: &corev1.EmptyDirVolumeSource{
-							Medium:    corev1.StorageMedium(vol.EmptyDir.Medium),
-							SizeLimit: sizeLimit,
-						},
-					},
-				})
-				createdVolumes.Insert(volumeName)
-			}
-		}
-
-		if volumeName != "" {
-			if !usedPaths.Has(*vol.Path) {
-				newVolumeMounts = append(newVolumeMounts, corev1.VolumeMount{
-					Name:      volumeName,
-					MountPath: *vol.Path,
-				})
-				usedPaths.Insert(*vol.Path)
-			} else {
-				return nil, nil, fmt.Errorf("mount path %s is defined multiple times", *vol.Path)
-			}
+		k8s.SetScheduling(f.Deploy.Scheduling, &service.Spec.Template.Spec.PodSpec)
+		setTraffic(service, previousService, f.Deploy.Traffic)
+		if f.Deploy.Strategy == fn.StrategyBlueGreen {
+			setCandidate(service, previousService)
 		}
+		return service, nil
 	}
-
-	return newVolumes, newVolumeMounts, nil
 }
 
-// checkResourcesArePresent returns error if Secrets or ConfigMaps
-// referenced in input sets are not deployed on the cluster in the specified namespace
-func checkResourcesArePresent(ctx context.Context, namespace string, referencedSecrets, referencedConfigMaps, referencedPVCs *sets.Set[string], referencedServiceAccount string) error {
-
-	errMsg := ""
-	for s := range *referencedSecrets {
-		_, err := k8s.GetSecret(ctx, s, namespace)
-		if err != nil {
-			if errors.IsForbidden(err) {
-				errMsg += " Ensure that the service account has the necessary permissions to access the secret.\n"
-			} else {
-				errMsg += fmt.Sprintf("  referenced Secret \"%s\" is not present in namespace \"%s\"\n", s, namespace)
-			}
-		}
-	}
-
-	for cm := range *referencedConfigMaps {
-		_, err := k8s.GetConfigMap(ctx, cm, namespace)
-		if err != nil {
-			errMsg += fmt.Sprintf("  referenced ConfigMap \"%s\" is not present in namespace \"%s\"\n", cm, namespace)
-		}
-	}
-
-	for pvc := range *referencedPVCs {
-		_, err := k8s.GetPersistentVolumeClaim(ctx, pvc, namespace)
-		if err != nil {
-			errMsg += fmt.Sprintf("  referenced PersistentVolumeClaim \"%s\" is not present in namespace \"%s\"\n", pvc, namespace)
-		}
-	}
-
-	// check if referenced ServiceAccount is present in the namespace if it is not default
-	if referencedServiceAccount != "" && referencedServiceAccount != "default" {
-		err := k8s.GetServiceAccount(ctx, referencedServiceAccount, namespace)
-		if err != nil {
-			errMsg += fmt.Sprintf("  referenced ServiceAccount \"%s\" is not present in namespace \"%s\"\n", referencedServiceAccount, namespace)
-		}
-	}
-
-	if errMsg != "" {
-		return fmt.Errorf("error(s) while validating resources:\n%s", errMsg)
+// scaleClass annotated of the class of the scale options, being the class of
+// the Knative Pod Autoscaler unless that of the Horizontal Pod Autoscaler.
+func scaleClass(class string) string {
+	if class == fn.ScaleClassHPA {
+		return autoscaling.HPA
 	}
-
-	return nil
+	return autoscaling.KPA
 }
 
 // setServiceOptions sets annotations on Service Revision Template or in the Service Spec
@@ -1048,6 +702,12 @@ func setServiceOptions(template *v1.RevisionTemplateSpec, options fn.Options) er
 			toRemove = append(toRemove, autoscaling.MaxScaleAnnotationKey)
 		}
 
+		if options.Scale.Class != nil {
+			toUpdate[autoscaling.ClassAnnotationKey] = scaleClass(*options.Scale.Class)
+		} else {
+			toRemove = append(toRemove, autoscaling.ClassAnnotationKey)
+		}
+
 		if options.Scale.Metric != nil {
 			toUpdate[autoscaling.MetricAnnotationKey] = *options.Scale.Metric
 		} else {
@@ -1117,11 +777,68 @@ func setServiceOptions(template *v1.RevisionTemplateSpec, options fn.Options) er
 				template.Spec.ContainerConcurrency = options.Resources.Limits.Concurrency
 			}
 		}
+
+		k8s.SetExtendedResources(options.Resources, &template.Spec.Containers[0])
 	}
 
 	return servingclientlib.UpdateRevisionTemplateAnnotations(template, toUpdate, toRemove)
 }
 
+// setTraffic of the service to the splits, if any, such that a new revision
+// is introduced with part of the traffic.  The previous revision is the
+// latest ready revision of the previous service; a first deployment has none,
+// and so the traffic of the previous revision is routed to the latest.  The
+// latest and previous revisions are tagged by default, such that each is
+// routed a URL of its own.  Without splits the traffic is routed as it was.
+func setTraffic(service, previous *v1.Service, tt []fn.TrafficSplit) {
+	if len(tt) == 0 {
+		return
+	}
+	var prev string
+	if previous != nil {
+		prev = previous.Status.LatestReadyRevisionName
+	}
+	var (
+		targets  []v1.TrafficTarget
+		orphaned int64 // percent of a previous revision of which there is none
+		latest   = -1  // index of the target of the latest revision
+	)
+	for _, t := range tt {
+		percent := t.Percent
+		target := v1.TrafficTarget{Tag: t.Tag, Percent: &percent}
+		switch t.Revision {
+		case fn.TrafficLatest:
+			isLatest := true
+			target.LatestRevision = &isLatest
+			if target.Tag == "" {
+				target.Tag = fn.TrafficLatest
+			}
+			latest = len(targets)
+		case fn.TrafficPrevious:
+			if prev == "" {
+				orphaned += t.Percent
+				continue
+			}
+			target.RevisionName = prev
+			if target.Tag == "" {
+				target.Tag = fn.TrafficPrevious
+			}
+		default:
+			target.RevisionName = t.Revision
+		}
+		targets = append(targets, target)
+	}
+	if orphaned > 0 {
+		if latest < 0 {
+			isLatest, percent := true, int64(0)
+			targets = append(targets, v1.TrafficTarget{Tag: fn.TrafficLatest, LatestRevision: &isLatest, Percent: &percent})
+			latest = len(targets) - 1
+		}
+		*targets[latest].Percent += orphaned
+	}
+	service.Spec.Traffic = targets
+}
+
 // wrapDeployerClientError wraps Kubernetes client creation errors with typed errors
 func wrapDeployerClientError(err error) error {
 	if err == nil {
@@ -1,92 +1,119 @@
 package knative
 
 import (
-	"os"
+	"fmt"
+	"reflect"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	fn "knative.dev/func/pkg/functions"
 )
 
-func Test_setHealthEndpoints(t *testing.T) {
-	f := fn.Function{
-		Name: "testing",
-		Deploy: fn.DeploySpec{
-			HealthEndpoints: fn.HealthEndpoints{
-				Liveness:  "/lively",
-				Readiness: "/readyAsIllEverBe",
-			},
-		},
-	}
-	c := corev1.Container{}
-	setHealthEndpoints(f, &c)
-	got := c.LivenessProbe.HTTPGet.Path
-	if got != "/lively" {
-		t.Errorf("expected \"/lively\" but got %v", got)
+// Test_setTraffic ensures that traffic is split between the latest revision,
+// the previous and those named, with the latest and previous tagged by
+// default, and that the traffic of the previous revision of a first
+// deployment is routed to the latest.
+func Test_setTraffic(t *testing.T) {
+	split := []fn.TrafficSplit{
+		{Revision: fn.TrafficLatest, Percent: 80},
+		{Revision: fn.TrafficPrevious, Percent: 10},
+		{Revision: "myfunc-00001", Percent: 10, Tag: "stable"},
 	}
-	got = c.ReadinessProbe.HTTPGet.Path
-	if got != "/readyAsIllEverBe" {
-		t.Errorf("expected \"readyAsIllEverBe\" but got %v", got)
+	describe := func(service *v1.Service) (ss []string) {
+		for _, t := range service.Spec.Traffic {
+			latest := t.LatestRevision != nil && *t.LatestRevision
+			ss = append(ss, fmt.Sprintf("%v/%v/%v=%v", t.RevisionName, latest, t.Tag, *t.Percent))
+		}
+		return
 	}
-}
 
-func Test_setHealthEndpointDefaults(t *testing.T) {
-	f := fn.Function{
-		Name: "testing",
+	previous := &v1.Service{}
+	previous.Status.LatestReadyRevisionName = "myfunc-00002"
+	service := &v1.Service{}
+	setTraffic(service, previous, split)
+	want := []string{"/true/latest=80", "myfunc-00002/false/prev=10", "myfunc-00001/false/stable=10"}
+	if got := describe(service); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected traffic %v, got %v", want, got)
 	}
-	c := corev1.Container{}
-	setHealthEndpoints(f, &c)
-	got := c.LivenessProbe.HTTPGet.Path
-	if got != LIVENESS_ENDPOINT {
-		t.Errorf("expected \"%v\" but got %v", LIVENESS_ENDPOINT, got)
+
+	service = &v1.Service{}
+	setTraffic(service, nil, split)
+	want = []string{"/true/latest=90", "myfunc-00001/false/stable=10"}
+	if got := describe(service); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the traffic of the previous revision routed to the latest, got %v", got)
 	}
-	got = c.ReadinessProbe.HTTPGet.Path
-	if got != READINESS_ENDPOINT {
-		t.Errorf("expected \"%v\" but got %v", READINESS_ENDPOINT, got)
+
+	service = &v1.Service{}
+	service.Spec.Traffic = []v1.TrafficTarget{{RevisionName: "myfunc-00001"}}
+	setTraffic(service, previous, nil)
+	if len(service.Spec.Traffic) != 1 {
+		t.Errorf("expected the traffic unchanged without splits, got %v", describe(service))
 	}
 }
 
-func Test_processValue(t *testing.T) {
-	testEnvVarOld, testEnvVarOldExists := os.LookupEnv("TEST_KNATIVE_DEPLOYER")
-	os.Setenv("TEST_KNATIVE_DEPLOYER", "VALUE_FOR_TEST_KNATIVE_DEPLOYER")
-	defer func() {
-		if testEnvVarOldExists {
-			os.Setenv("TEST_KNATIVE_DEPLOYER", testEnvVarOld)
-		} else {
-			os.Unsetenv("TEST_KNATIVE_DEPLOYER")
-		}
-	}()
+// Test_setServiceOptions_Class ensures that the autoscaler class is annotated
+// by its full name, and removed once no longer of the options.
+func Test_setServiceOptions_Class(t *testing.T) {
+	class, metric := fn.ScaleClassHPA, "cpu"
+	template := &v1.RevisionTemplateSpec{}
+	template.Spec.Containers = []corev1.Container{{}}
+	options := fn.Options{Scale: &fn.ScaleOptions{Class: &class, Metric: &metric}}
+	if err := setServiceOptions(template, options); err != nil {
+		t.Fatal(err)
+	}
+	if got := template.Annotations["autoscaling.knative.dev/class"]; got != "hpa.autoscaling.knative.dev" {
+		t.Errorf("expected the hpa class annotated, got %q", got)
+	}
+	if got := template.Annotations["autoscaling.knative.dev/metric"]; got != "cpu" {
+		t.Errorf("expected the cpu metric annotated, got %q", got)
+	}
 
-	unsetVarOld, unsetVarOldExists := os.LookupEnv("UNSET_VAR")
-	os.Unsetenv("UNSET_VAR")
-	defer func() {
-		if unsetVarOldExists {
-			os.Setenv("UNSET_VAR", unsetVarOld)
-		}
-	}()
+	if err := setServiceOptions(template, fn.Options{Scale: &fn.ScaleOptions{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := template.Annotations["autoscaling.knative.dev/class"]; ok {
+		t.Error("expected the class annotation removed")
+	}
+}
 
-	tests := []struct {
-		name    string
-		arg     string
-		want    string
-		wantErr bool
-	}{
-		{name: "simple value", arg: "A_VALUE", want: "A_VALUE", wantErr: false},
-		{name: "using envvar value", arg: "{{ env:TEST_KNATIVE_DEPLOYER }}", want: "VALUE_FOR_TEST_KNATIVE_DEPLOYER", wantErr: false},
-		{name: "bad context", arg: "{{secret:S}}", want: "", wantErr: true},
-		{name: "unset envvar", arg: "{{env:SOME_UNSET_VAR}}", want: "", wantErr: true},
-	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			got, err := processLocalEnvValue(test.arg)
-			if (err != nil) != test.wantErr {
-				t.Errorf("processValue() error = %v, wantErr %v", err, test.wantErr)
-				return
-			}
-			if got != test.want {
-				t.Errorf("processValue() got = %v, want %v", got, test.want)
-			}
-		})
+// Test_generateNewService_Scopes ensures that labels and annotations of the
+// service scope are of only the service, and those of the revision scope of
+// only its revision template.
+func Test_generateNewService_Scopes(t *testing.T) {
+	costCenter, scaling, value := "cost-center", "scaling", "value"
+	f := fn.Function{Name: "testing", Runtime: "go", Deploy: fn.DeploySpec{
+		Image: "example.com/alice/testing:latest",
+		Labels: []fn.Label{
+			{Key: &costCenter, Value: &value, Scope: fn.ScopeService},
+			{Key: &scaling, Value: &value, Scope: fn.ScopeRevision},
+		},
+		Annotations:         map[string]string{"both": "1"},
+		ServiceAnnotations:  map[string]string{"service": "2"},
+		RevisionAnnotations: map[string]string{"revision": "3"},
+	}}
+	service, err := generateNewService(f, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := service.Spec.Template
+	if _, ok := service.Labels[costCenter]; !ok {
+		t.Error("expected the service labelled of the service scope")
+	}
+	if _, ok := service.Labels[scaling]; ok {
+		t.Error("expected the service not labelled of the revision scope")
+	}
+	if _, ok := template.Labels[scaling]; !ok {
+		t.Error("expected the revision labelled of the revision scope")
+	}
+	if _, ok := template.Labels[costCenter]; ok {
+		t.Error("expected the revision not labelled of the service scope")
+	}
+	if a := service.Annotations; a["both"] != "1" || a["service"] != "2" || a["revision"] != "" {
+		t.Errorf("unexpected annotations of the service %v", a)
+	}
+	if a := template.Annotations; a["both"] != "1" || a["service"] != "" || a["revision"] != "3" {
+		t.Errorf("unexpected annotations of the revision %v", a)
 	}
 }
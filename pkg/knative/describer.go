@@ -7,6 +7,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	clientservingv1 "knative.dev/client/pkg/serving/v1"
 	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 
 	fn "knative.dev/func/pkg/functions"
 )
@@ -32,18 +33,26 @@ func (d *Describer) Describe(ctx context.Context, name, namespace string) (descr
 		return
 	}
 
-	servingClient, err := NewServingClient(namespace)
+	servingClient, err := newServingClient(ctx, namespace)
 	if err != nil {
 		return
 	}
 
-	eventingClient, err := NewEventingClient(namespace)
+	eventingClient, err := newEventingClient(ctx, namespace)
+	if err != nil {
+		return
+	}
+
+	eventingClientset, err := newEventingClientset(ctx)
 	if err != nil {
 		return
 	}
 
 	service, err := servingClient.GetService(ctx, name)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			err = fn.ErrFunctionNotFound
+		}
 		return
 	}
 
@@ -64,8 +73,18 @@ func (d *Describer) Describe(ctx context.Context, name, namespace string) (descr
 
 	description.Name = name
 	description.Namespace = namespace
+	description.Image = deployedImage(ctx, servingClient, service)
 	description.Route = primaryRouteURL
 	description.Routes = routeURLs
+	if domainClient, err := newDomainMappingClient(ctx); err == nil {
+		description.Routes = append(description.Routes, domainURLs(ctx, domainClient, name, namespace)...)
+	}
+	description.Traffic = newTraffic(service)
+	description.Provisioning = newProvisioning(service)
+
+	if description.Bindings, err = eventBindings(ctx, eventingClientset, service); err != nil {
+		return
+	}
 
 	triggers, err := eventingClient.ListTriggers(ctx)
 	// IsNotFound -- Eventing is probably not installed on the cluster
@@ -105,3 +124,18 @@ func (d *Describer) Describe(ctx context.Context, name, namespace string) (descr
 
 	return
 }
+
+// deployedImage of the service: that of its latest ready revision by digest,
+// as resolved by the cluster, or else the image of its template.
+func deployedImage(ctx context.Context, client clientservingv1.KnServingClient, service *servingv1.Service) string {
+	if name := service.Status.LatestReadyRevisionName; name != "" {
+		r, err := client.GetRevision(ctx, name)
+		if err == nil && len(r.Status.ContainerStatuses) > 0 && r.Status.ContainerStatuses[0].ImageDigest != "" {
+			return r.Status.ContainerStatuses[0].ImageDigest
+		}
+	}
+	if len(service.Spec.Template.Spec.Containers) > 0 {
+		return service.Spec.Template.Spec.Containers[0].Image
+	}
+	return ""
+}
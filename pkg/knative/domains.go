@@ -0,0 +1,141 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	servingv1beta1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1beta1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// newDomainMappingClient of the typed Knative Serving API of DomainMappings,
+// which the Knative client does not provide.
+func newDomainMappingClient(ctx context.Context) (servingv1beta1.ServingV1beta1Interface, error) {
+	if err := validateKubeconfigFile(); err != nil {
+		return nil, err
+	}
+
+	restConfig, err := k8s.GetClientConfigFrom(ctx).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new serving client: %v", err)
+	}
+
+	client, err := servingv1beta1.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new serving client: %v", err)
+	}
+	return client, nil
+}
+
+// generateDomainMappings of the function's custom domains, each mapping its
+// domain, by which it is named, to the function's service.
+func generateDomainMappings(f fn.Function, namespace string) (mappings []*v1beta1.DomainMapping) {
+	for _, domain := range f.Deploy.Domains {
+		mappings = append(mappings, &v1beta1.DomainMapping{
+			TypeMeta: metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: "DomainMapping"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      domain,
+				Namespace: namespace,
+				Labels:    map[string]string{fnlabels.FunctionNameKey: f.Name},
+			},
+			Spec: v1beta1.DomainMappingSpec{
+				Ref: duckv1.KReference{
+					APIVersion: v1.SchemeGroupVersion.String(),
+					Kind:       "Service",
+					Name:       f.Name,
+					Namespace:  namespace,
+				},
+			},
+		})
+	}
+	return
+}
+
+// syncDomainMappings of the function, creating or updating those of its
+// custom domains and deleting those of domains it no longer declares.  A
+// domain mapped to another service, of which the mapping is not the
+// function's, is an error rather than taken over.
+func syncDomainMappings(ctx context.Context, client servingv1beta1.ServingV1beta1Interface, f fn.Function, namespace string) error {
+	mappings := client.DomainMappings(namespace)
+	for _, mapping := range generateDomainMappings(f, namespace) {
+		existing, err := mappings.Get(ctx, mapping.Name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+			_, err = mappings.Create(ctx, mapping, metav1.CreateOptions{})
+		case err == nil:
+			if owner := existing.Labels[fnlabels.FunctionNameKey]; owner != f.Name {
+				return fmt.Errorf("the domain %v is already mapped to %v/%v", mapping.Name, existing.Spec.Ref.Kind, existing.Spec.Ref.Name)
+			}
+			existing.Spec.Ref = mapping.Spec.Ref
+			_, err = mappings.Update(ctx, existing, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("knative deployer failed to map the domain %v: %v", mapping.Name, err)
+		}
+	}
+
+	lst, err := mappings.List(ctx, metav1.ListOptions{LabelSelector: fnlabels.FunctionNameKey + "=" + f.Name})
+	if err != nil {
+		if len(f.Deploy.Domains) == 0 {
+			return nil // of a cluster without domain mappings, there are none
+		}
+		return fmt.Errorf("knative deployer failed to list the domain mappings: %v", err)
+	}
+	for _, mapping := range lst.Items {
+		if slices.Contains(f.Deploy.Domains, mapping.Name) {
+			continue
+		}
+		if err = mappings.Delete(ctx, mapping.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("knative deployer failed to unmap the domain %v: %v", mapping.Name, err)
+		}
+	}
+	if len(f.Deploy.Domains) > 0 {
+		fmt.Fprintf(os.Stderr, "🌐 Custom domains mapped to the function:\n")
+		for _, domain := range f.Deploy.Domains {
+			fmt.Fprintf(os.Stderr, "   https://%v\n", domain)
+		}
+	}
+	return nil
+}
+
+// removeDomainMappings of the named function.
+func removeDomainMappings(ctx context.Context, client servingv1beta1.ServingV1beta1Interface, name, namespace string) error {
+	mappings := client.DomainMappings(namespace)
+	lst, err := mappings.List(ctx, metav1.ListOptions{LabelSelector: fnlabels.FunctionNameKey + "=" + name})
+	if err != nil {
+		return nil // of a cluster without domain mappings, there are none
+	}
+	for _, mapping := range lst.Items {
+		if err = mappings.Delete(ctx, mapping.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("knative remover failed to delete the domain mapping %v: %v", mapping.Name, err)
+		}
+	}
+	return nil
+}
+
+// domainURLs of the named function's custom domains, as reported by their
+// mappings, or of their domains if not yet reported.
+func domainURLs(ctx context.Context, client servingv1beta1.ServingV1beta1Interface, name, namespace string) (urls []string) {
+	lst, err := client.DomainMappings(namespace).List(ctx, metav1.ListOptions{LabelSelector: fnlabels.FunctionNameKey + "=" + name})
+	if err != nil {
+		return
+	}
+	for _, mapping := range lst.Items {
+		if mapping.Status.URL != nil {
+			urls = append(urls, mapping.Status.URL.String())
+		} else {
+			urls = append(urls, "https://"+mapping.Name)
+		}
+	}
+	return
+}
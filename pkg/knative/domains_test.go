@@ -0,0 +1,55 @@
+package knative
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/client/clientset/versioned/fake"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_syncDomainMappings ensures that the custom domains of a function are
+// mapped to it, those it no longer declares unmapped, and those mapped to
+// others not taken over.
+func Test_syncDomainMappings(t *testing.T) {
+	ctx := context.Background()
+	other := &v1beta1.DomainMapping{ObjectMeta: metav1.ObjectMeta{Name: "other.example.com", Namespace: "ns"}}
+	other.Spec.Ref.Kind, other.Spec.Ref.Name = "Service", "other"
+	client := fake.NewSimpleClientset(other).ServingV1beta1()
+
+	f := fn.Function{Name: "myfn", Deploy: fn.DeploySpec{Domains: []string{"api.example.com", "www.example.com"}}}
+	if err := syncDomainMappings(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	m, err := client.DomainMappings("ns").Get(ctx, "api.example.com", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Spec.Ref.Name != "myfn" || m.Spec.Ref.Kind != "Service" {
+		t.Errorf("expected the domain mapped to the function's service, got %v", m.Spec.Ref)
+	}
+
+	f.Deploy.Domains = []string{"api.example.com"}
+	if err = syncDomainMappings(ctx, client, f, "ns"); err != nil {
+		t.Fatal(err)
+	}
+	if urls := domainURLs(ctx, client, "myfn", "ns"); len(urls) != 1 || urls[0] != "https://api.example.com" {
+		t.Errorf("expected only api.example.com mapped, got %v", urls)
+	}
+
+	f.Deploy.Domains = []string{"other.example.com"}
+	if err = syncDomainMappings(ctx, client, f, "ns"); err == nil {
+		t.Error("expected a domain mapped to another service not taken over")
+	}
+
+	if err = removeDomainMappings(ctx, client, "myfn", "ns"); err != nil {
+		t.Fatal(err)
+	}
+	lst, _ := client.DomainMappings("ns").List(ctx, metav1.ListOptions{})
+	if len(lst.Items) != 1 || lst.Items[0].Name != "other.example.com" {
+		t.Errorf("expected only the function's mappings removed, got %v", lst.Items)
+	}
+}
@@ -0,0 +1,161 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// driftIgnoredAnnotations of a service, which are set by the cluster or by
+// tooling rather than by deploying.
+var driftIgnoredAnnotations = []string{
+	"serving.knative.dev/",
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// Snapshots of the function's deployed Knative service and its triggers, and
+// of those which deploying the function would produce, such that edits of
+// them on the cluster are diffed.
+//
+// The image is that last built, which is not rebuilt: the digest of an image
+// given by tag is presumed to be that deployed.
+func (d *Deployer) Snapshots(ctx context.Context, f fn.Function) (deployed, desired fn.Snapshot, err error) {
+	namespace := f.Deploy.Namespace
+	if namespace == "" {
+		err = fmt.Errorf("function %v has not been deployed", f.Name)
+		return
+	}
+	if f.Build.Image != "" {
+		f.Deploy.Image = f.Build.Image
+	}
+	f = d.withRoute(f)
+
+	client, err := newServingClient(ctx, namespace)
+	if err != nil {
+		err = wrapDeployerClientError(err)
+		return
+	}
+	eventingClient, err := newEventingClient(ctx, namespace)
+	if err != nil {
+		err = wrapDeployerClientError(err)
+		return
+	}
+	daprInstalled, err := isDaprInstalled(ctx)
+	if err != nil {
+		err = wrapDeployerClientError(err)
+		return
+	}
+
+	live, err := client.GetService(ctx, f.Name)
+	if err != nil {
+		return
+	}
+
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+	newEnv, newEnvFrom, err := k8s.ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	if err != nil {
+		return
+	}
+	newVolumes, newVolumeMounts, err := k8s.ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+	if err != nil {
+		return
+	}
+	rendered, err := updateService(f, live, newEnv, newEnvFrom, newVolumes, newVolumeMounts, d.decorator, daprInstalled)(live.DeepCopy())
+	if err != nil {
+		return
+	}
+
+	var liveDigest string
+	if name := live.Status.LatestReadyRevisionName; name != "" {
+		if r, err := client.GetRevision(ctx, name); err == nil && len(r.Status.ContainerStatuses) > 0 {
+			liveDigest = r.Status.ContainerStatuses[0].ImageDigest
+		}
+	}
+
+	triggers, err := eventingClient.ListTriggers(ctx)
+	if err != nil {
+		err = fmt.Errorf("cannot list the triggers of %v: %w", f.Name, err)
+		return
+	}
+	var liveTriggers []*eventingv1.Trigger
+	for i := range triggers.Items {
+		ref := triggers.Items[i].Spec.Subscriber.Ref
+		if ref != nil && ref.Kind == "Service" && ref.Name == f.Name {
+			liveTriggers = append(liveTriggers, &triggers.Items[i])
+		}
+	}
+
+	deployed, desired = snapshots(live, rendered, liveDigest, liveTriggers, generateTriggers(f, live))
+	return
+}
+
+// snapshots of the live service and its triggers, and of those desired.  The
+// digest of the live image, if known, is that resolved by the cluster; that
+// of the desired image, if given by tag, is presumed to be that deployed.
+func snapshots(live, desired *v1.Service, liveDigest string, liveTriggers, desiredTriggers []*eventingv1.Trigger) (a, b fn.Snapshot) {
+	a = newRevision(&v1.Revision{ObjectMeta: live.Spec.Template.ObjectMeta, Spec: live.Spec.Template.Spec}).snapshot()
+	b = newRevision(&v1.Revision{ObjectMeta: desired.Spec.Template.ObjectMeta, Spec: desired.Spec.Template.Spec}).snapshot()
+	a.ImageDigest = imageDigest(liveDigest)
+	b.ImageDigest = imageDigest(b.Image)
+	if b.ImageDigest == "" {
+		b.ImageDigest = a.ImageDigest
+	}
+	a.ServiceAccountName = live.Spec.Template.Spec.ServiceAccountName
+	b.ServiceAccountName = desired.Spec.Template.Spec.ServiceAccountName
+	a.Labels, b.Labels = live.Labels, desired.Labels
+	a.Annotations = withoutIgnoredAnnotations(live.Annotations)
+	b.Annotations = withoutIgnoredAnnotations(desired.Annotations)
+	a.Triggers, b.Triggers = describeTriggers(liveTriggers), describeTriggers(desiredTriggers)
+	return
+}
+
+// imageDigest of the image reference, or empty if it is not by digest.
+func imageDigest(image string) string {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	return ""
+}
+
+func withoutIgnoredAnnotations(aa map[string]string) map[string]string {
+	m := map[string]string{}
+	for k, v := range aa {
+		ignored := false
+		for _, prefix := range driftIgnoredAnnotations {
+			if strings.HasPrefix(k, prefix) {
+				ignored = true
+			}
+		}
+		if !ignored {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// describeTriggers by name as their broker and filter, for example
+// "broker=default filter=type=example.com".
+func describeTriggers(tt []*eventingv1.Trigger) map[string]string {
+	m := map[string]string{}
+	for _, t := range tt {
+		var filters []string
+		if t.Spec.Filter != nil {
+			for k, v := range t.Spec.Filter.Attributes {
+				filters = append(filters, k+"="+v)
+			}
+		}
+		sort.Strings(filters)
+		m[t.Name] = fmt.Sprintf("broker=%v filter=%v", t.Spec.Broker, strings.Join(filters, ","))
+	}
+	return m
+}
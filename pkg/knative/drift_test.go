@@ -0,0 +1,81 @@
+package knative
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// Test_snapshots ensures that edits of a deployed service on the cluster are
+// diffed as changes from it to that which deploying would produce.
+func Test_snapshots(t *testing.T) {
+	f := fn.Function{
+		Name:    "myfn",
+		Runtime: "go",
+		Run:     fn.RunSpec{Envs: []fn.Env{{Name: ptr("A"), Value: ptr("1")}}},
+		Deploy: fn.DeploySpec{
+			Image:         "example.com/myfn@sha256:aaa",
+			Subscriptions: []fn.KnativeSubscription{{Source: "default", Filters: map[string]string{"type": "example"}}},
+		},
+	}
+	deployed, err := generateNewService(f, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triggers := generateTriggers(f, deployed)
+
+	// Freshly deployed, there is no drift.
+	a, b := snapshots(deployed, render(t, f, deployed), "example.com/myfn@sha256:aaa", triggers, triggers)
+	if changes := a.Diff(b); len(changes) != 0 {
+		t.Fatalf("expected no drift, got %v", changes)
+	}
+
+	// Edited out-of-band
+	live := deployed.DeepCopy()
+	live.Spec.Template.Spec.Containers[0].Env[1].Value = "2"
+	live.Spec.Template.Annotations[autoscaling.MinScaleAnnotationKey] = "3"
+	live.Labels["team"] = "a"
+	live.Annotations["serving.knative.dev/lastModifier"] = "alice"
+	edited := generateTriggers(fn.Function{Deploy: fn.DeploySpec{Subscriptions: []fn.KnativeSubscription{
+		{Source: "default", Filters: map[string]string{"type": "other"}}}}}, live)
+
+	a, b = snapshots(live, render(t, f, live), "example.com/myfn@sha256:bbb", edited, generateTriggers(f, live))
+	changes := a.Diff(b)
+	want := []fn.Change{
+		{Field: "imageDigest", From: "sha256:bbb", To: "sha256:aaa"},
+		{Field: "envs.A", From: "2", To: "1"},
+		{Field: "scale.min-scale", From: "3"},
+		{Field: "labels.team", From: "a"},
+		{Field: "triggers.myfn-function-trigger-0", From: "broker=default filter=type=other", To: "broker=default filter=type=example"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected changes\n%v\ngot\n%v", want, changes)
+	}
+}
+
+// render the service as deployed over the live service.
+func render(t *testing.T, f fn.Function, live *v1.Service) *v1.Service {
+	t.Helper()
+	secrets, configMaps, pvcs := sets.New[string](), sets.New[string](), sets.New[string]()
+	env, envFrom, err := k8s.ProcessEnvs(f.Run.Envs, &secrets, &configMaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	volumes, mounts, err := k8s.ProcessVolumes(f.Run.Volumes, &secrets, &configMaps, &pvcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired, err := updateService(f, live, env, envFrom, volumes, mounts, nil, false)(live.DeepCopy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return desired
+}
+
+func ptr(s string) *string { return &s }
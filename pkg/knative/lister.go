@@ -2,13 +2,30 @@ package knative
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"golang.org/x/sync/errgroup"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	clienterrors "knative.dev/client/pkg/errors"
+	eventingversioned "knative.dev/eventing/pkg/client/clientset/versioned"
 	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	servingv1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1"
 
 	fn "knative.dev/func/pkg/functions"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
 )
 
+// watchRetryInterval between attempts to resume watching services.
+const watchRetryInterval = 2 * time.Second
+
 type Lister struct {
 	verbose bool
 }
@@ -17,42 +34,300 @@ func NewLister(verbose bool) *Lister {
 	return &Lister{verbose: verbose}
 }
 
-// List functions, optionally specifying a namespace.
-func (l *Lister) List(ctx context.Context, namespace string) (items []fn.ListItem, err error) {
-	client, err := NewServingClient(namespace)
+// List functions, optionally specifying a namespace and a label selector.
+// Those of all namespaces, if none is given, are listed by a single list of
+// the cluster, as are their revisions and event bindings.
+func (l *Lister) List(ctx context.Context, namespace, selector string) (items []fn.ListItem, err error) {
+	client, err := newServingV1Client(ctx)
 	if err != nil {
 		return
 	}
+	items, _, err = listServices(ctx, client, newEventingClientsetOrNil(ctx), namespace, metav1.ListOptions{LabelSelector: selector})
+	return
+}
+
+// newEventingClientsetOrNil is the client with which the event bindings of
+// listed functions are found, or nil should there be none, in which case the
+// functions are listed without them.
+func newEventingClientsetOrNil(ctx context.Context) eventingversioned.Interface {
+	client, err := newEventingClientset(ctx)
+	if err != nil {
+		return nil
+	}
+	return client
+}
 
-	lst, err := client.ListServices(ctx)
+// ListPage of at most limit functions, continuing from the token of the
+// previous page, by the continue tokens of the Kubernetes list API.  Tokens
+// expire, after which listing starts again from the first page.
+func (l *Lister) ListPage(ctx context.Context, namespace, selector string, limit int64, token string) (items []fn.ListItem, next string, err error) {
+	client, err := newServingV1Client(ctx)
 	if err != nil {
 		return
 	}
+	return listServices(ctx, client, newEventingClientsetOrNil(ctx), namespace, metav1.ListOptions{LabelSelector: selector, Limit: limit, Continue: token})
+}
 
-	services := lst.Items[:]
+// listServices as functions, returning the token from which to continue a
+// paged list.  The typed client is used rather than that of Knative, whose
+// list options select only labels equal to values and are not paged.  The
+// revisions and event bindings of the namespace, of the cluster if none, are
+// listed concurrently with the services, once each, and joined to them.
+func listServices(ctx context.Context, client servingv1.ServingV1Interface, eventing eventingversioned.Interface, namespace string, options metav1.ListOptions) (items []fn.ListItem, next string, err error) {
+	var (
+		lst    *v1.ServiceList
+		sinks  *eventSinks
+		images = newRevisionImages(client)
+	)
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() (err error) {
+		lst, err = client.Services(namespace).List(egCtx, options)
+		return
+	})
+	// The revisions of a page are got as needed rather than listing those of
+	// the whole namespace.
+	if options.Limit <= 0 {
+		eg.Go(func() error {
+			images.list(egCtx, namespace)
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		sinks = listEventSinksOrNone(egCtx, eventing, namespace)
+		return nil
+	})
+	if err = eg.Wait(); err != nil {
+		if errors.IsResourceExpired(err) {
+			err = fmt.Errorf("the continue token has expired, list again from the first page: %w", err)
+			return
+		}
+		err = clienterrors.GetError(err)
+		return
+	}
 
-	for _, service := range services {
+	for _, service := range lst.Items {
+		item := newListItem(&service, images.image(ctx, &service))
+		item.Bindings = sinks.bindings(&service)
+		items = append(items, item)
+	}
+	next = lst.Continue
+	return
+}
 
-		// get status
-		ready := corev1.ConditionUnknown
-		for _, con := range service.Status.Conditions {
-			if con.Type == apis.ConditionReady {
-				ready = con.Status
-				break
+// Watch functions, optionally specifying a namespace and a label selector.
+// The services are listed, such that errors connecting are returned, and then
+// watched; the watch is resumed, relisting as necessary, should it be ended by
+// the server.
+func (l *Lister) Watch(ctx context.Context, namespace, selector string) (<-chan []fn.ListItem, error) {
+	client, err := newServingV1Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w := &serviceWatch{client: client, eventing: newEventingClientsetOrNil(ctx), namespace: namespace, selector: selector, images: newRevisionImages(client)}
+	if err = w.list(ctx); err != nil {
+		return nil, err
+	}
+	ch := make(chan []fn.ListItem)
+	go func() {
+		defer close(ch)
+		w.watch(ctx, ch)
+	}()
+	return ch, nil
+}
+
+// serviceWatch maintains the services of a namespace from watch events.
+type serviceWatch struct {
+	client    servingv1.ServingV1Interface
+	eventing  eventingversioned.Interface
+	namespace string
+	selector  string
+	images    *revisionImages
+	services  map[string]*v1.Service // by namespace/name
+	version   string                 // resource version from which to watch
+}
+
+func (w *serviceWatch) list(ctx context.Context) error {
+	lst, err := w.client.Services(w.namespace).List(ctx, metav1.ListOptions{LabelSelector: w.selector})
+	if err != nil {
+		return err
+	}
+	w.services = map[string]*v1.Service{}
+	for i := range lst.Items {
+		s := &lst.Items[i]
+		w.services[s.Namespace+"/"+s.Name] = s
+	}
+	w.version = lst.ResourceVersion
+	w.images.list(ctx, w.namespace)
+	return nil
+}
+
+// watch the services, sending their list initially and on each change until
+// the context is done.
+func (w *serviceWatch) watch(ctx context.Context, ch chan<- []fn.ListItem) {
+	send := func() bool {
+		select {
+		case ch <- w.items(ctx):
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if !send() {
+		return
+	}
+	relist := false
+	for ctx.Err() == nil {
+		if relist {
+			if err := w.list(ctx); err != nil {
+				w.wait(ctx)
+				continue
 			}
+			if !send() {
+				return
+			}
+			relist = false
+		}
+		watcher, err := w.client.Services(w.namespace).Watch(ctx, metav1.ListOptions{LabelSelector: w.selector, ResourceVersion: w.version})
+		if err != nil {
+			relist = true // as the version may have expired
+			w.wait(ctx)
+			continue
 		}
+		relist = w.follow(ctx, watcher, send)
+	}
+}
 
-		runtimeLabel := ""
+// follow the events of the watch until it ends or the context is done,
+// returning true if the services are to be relisted.
+func (w *serviceWatch) follow(ctx context.Context, watcher watch.Interface, send func() bool) (relist bool) {
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false // ended by the server, and so resumed
+			}
+			s, ok := event.Object.(*v1.Service)
+			if !ok || event.Type == watch.Error {
+				return true
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				w.services[s.Namespace+"/"+s.Name] = s
+			case watch.Deleted:
+				delete(w.services, s.Namespace+"/"+s.Name)
+			default:
+				continue
+			}
+			w.version = s.ResourceVersion
+			if !send() {
+				return false
+			}
+		}
+	}
+}
+
+func (w *serviceWatch) wait(ctx context.Context) {
+	select {
+	case <-time.After(watchRetryInterval):
+	case <-ctx.Done():
+	}
+}
+
+// items of the services, ordered by namespace and name.  Event bindings are
+// not watched, and so are listed again for each change of the services.
+func (w *serviceWatch) items(ctx context.Context) []fn.ListItem {
+	sinks := listEventSinksOrNone(ctx, w.eventing, w.namespace)
+	items := make([]fn.ListItem, 0, len(w.services))
+	for _, s := range w.services {
+		item := newListItem(s, w.images.image(ctx, s))
+		item.Bindings = sinks.bindings(s)
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Namespace != items[j].Namespace {
+			return items[i].Namespace < items[j].Namespace
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+// revisionImages resolves the images of services as the cluster resolved
+// those of their latest ready revisions, by digest.  Revisions are immutable,
+// such that those resolved are retained.
+type revisionImages struct {
+	client servingv1.ServingV1Interface
+	images map[string]string // by namespace/name of the revision
+}
+
+func newRevisionImages(client servingv1.ServingV1Interface) *revisionImages {
+	return &revisionImages{client: client, images: map[string]string{}}
+}
 
-		listItem := fn.ListItem{
-			Name:      service.Name,
-			Namespace: service.Namespace,
-			Runtime:   runtimeLabel,
-			URL:       service.Status.URL.String(),
-			Ready:     string(ready),
+// list the revisions of the namespace, such that the images of its services
+// are resolved without getting each revision.  Revisions which cannot be
+// listed are got as needed.
+func (r *revisionImages) list(ctx context.Context, namespace string) {
+	lst, err := r.client.Revisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: serving.ServiceLabelKey})
+	if err != nil {
+		return
+	}
+	for i := range lst.Items {
+		r.add(&lst.Items[i])
+	}
+}
+
+func (r *revisionImages) add(revision *v1.Revision) {
+	image := ""
+	if cc := revision.Status.ContainerStatuses; len(cc) > 0 {
+		image = cc[0].ImageDigest
+	}
+	r.images[revision.Namespace+"/"+revision.Name] = image
+}
+
+// image of the service's latest ready revision, or that of its template if
+// not resolved.
+func (r *revisionImages) image(ctx context.Context, service *v1.Service) string {
+	if name := service.Status.LatestReadyRevisionName; name != "" {
+		key := service.Namespace + "/" + name
+		if _, ok := r.images[key]; !ok {
+			if revision, err := r.client.Revisions(service.Namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+				r.add(revision)
+			}
+		}
+		if image := r.images[key]; image != "" {
+			return image
 		}
+	}
+	if cc := service.Spec.Template.Spec.Containers; len(cc) > 0 {
+		return cc[0].Image
+	}
+	return ""
+}
 
-		items = append(items, listItem)
+func newListItem(service *v1.Service, image string) fn.ListItem {
+	// get status
+	ready := corev1.ConditionUnknown
+	for _, con := range service.Status.Conditions {
+		if con.Type == apis.ConditionReady {
+			ready = con.Status
+			break
+		}
+	}
+
+	return fn.ListItem{
+		Name:         service.Name,
+		Namespace:    service.Namespace,
+		Runtime:      service.Labels[fnlabels.FunctionRuntimeKey],
+		URL:          service.Status.URL.String(),
+		Ready:        string(ready),
+		Created:      service.CreationTimestamp.Time,
+		Image:        image,
+		Revision:     service.Status.LatestReadyRevisionName,
+		Traffic:      newTraffic(service),
+		Provisioning: newProvisioning(service),
 	}
-	return
 }
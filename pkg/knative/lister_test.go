@@ -0,0 +1,211 @@
+package knative
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8stesting "k8s.io/client-go/testing"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+	"knative.dev/serving/pkg/client/clientset/versioned/fake"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestLister_Selector ensures that the services listed are those matching the
+// label selector, including selectors other than of equality.
+func TestLister_Selector(t *testing.T) {
+	service := func(name string, labels map[string]string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", Labels: labels}}
+	}
+	client := fake.NewSimpleClientset(
+		service("a", map[string]string{"team": "payments", "env": "prod"}),
+		service("b", map[string]string{"team": "payments", "env": "dev"}),
+		service("c", map[string]string{"team": "search"}),
+	)
+
+	items, _, err := listServices(context.Background(), client.ServingV1(), nil, "ns", metav1.ListOptions{LabelSelector: "team=payments,env!=prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "b" {
+		t.Fatalf("expected only service b, got %v", items)
+	}
+}
+
+// TestLister_Image ensures that the image listed is that of the service's
+// latest ready revision by digest, or that of its template otherwise.
+func TestLister_Image(t *testing.T) {
+	ready := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns"}}
+	ready.Spec.Template.Spec.Containers = []corev1.Container{{Image: "example.com/a:latest"}}
+	ready.Status.LatestReadyRevisionName = "a-00001"
+	pending := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns"}}
+	pending.Spec.Template.Spec.Containers = []corev1.Container{{Image: "example.com/b:latest"}}
+	revision := &v1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "a-00001", Namespace: "ns",
+		Labels: map[string]string{"serving.knative.dev/service": "a"}}}
+	revision.Status.ContainerStatuses = []v1.ContainerStatus{{ImageDigest: "example.com/a@sha256:abc"}}
+	client := fake.NewSimpleClientset(ready, pending, revision)
+
+	items, _, err := listServices(context.Background(), client.ServingV1(), nil, "ns", metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected two services, got %v", items)
+	}
+	if items[0].Image != "example.com/a@sha256:abc" || items[0].Revision != "a-00001" {
+		t.Errorf("expected the image of the ready revision by digest, got %+v", items[0])
+	}
+	if items[1].Image != "example.com/b:latest" {
+		t.Errorf("expected the image of the template, got %+v", items[1])
+	}
+}
+
+// TestLister_AllNamespaces ensures that the functions of all namespaces are
+// listed by a single list of the cluster, as are their revisions, rather than
+// by those of each namespace.
+func TestLister_AllNamespaces(t *testing.T) {
+	var objects []runtime.Object
+	for _, ns := range []string{"a", "b", "c"} {
+		for _, name := range []string{"x", "y"} {
+			objects = append(objects, &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns,
+				Labels: map[string]string{"name": name}}})
+		}
+	}
+	client := fake.NewSimpleClientset(objects...)
+
+	items, _, err := listServices(context.Background(), client.ServingV1(), nil, "", metav1.ListOptions{LabelSelector: "name=x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Namespace+"/"+item.Name)
+	}
+	sort.Strings(got)
+	if want := []string{"a/x", "b/x", "c/x"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	lists := map[string]int{}
+	for _, action := range client.Actions() {
+		if action.GetVerb() != "list" {
+			continue
+		}
+		if action.GetNamespace() != "" {
+			t.Errorf("expected %v listed of the cluster, got of namespace %q", action.GetResource().Resource, action.GetNamespace())
+		}
+		lists[action.GetResource().Resource]++
+	}
+	if want := map[string]int{"services": 1, "revisions": 1}; !reflect.DeepEqual(lists, want) {
+		t.Errorf("expected lists %v, got %v", want, lists)
+	}
+}
+
+// TestLister_Page ensures that a page is listed by the limit and continue
+// token given, returning that of the next page.
+func TestLister_Page(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		options := action.(k8stesting.ListActionImpl).ListOptions
+		if options.Limit != 1 || options.Continue != "page-2" {
+			t.Errorf("expected limit 1 from page-2, got %v from %q", options.Limit, options.Continue)
+		}
+		lst := &v1.ServiceList{Items: []v1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns"}}}}
+		lst.Continue = "page-3"
+		return true, lst, nil
+	})
+
+	items, next, err := listServices(context.Background(), client.ServingV1(), nil, "ns", metav1.ListOptions{Limit: 1, Continue: "page-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "b" || next != "page-3" {
+		t.Errorf("expected service b and the token of page-3, got %v and %q", items, next)
+	}
+}
+
+// TestLister_Watch ensures that the list of services is sent initially and on
+// each change, and that the watch is resumed with the services relisted
+// should it fail.
+func TestLister_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := func(name string) *v1.Service {
+		return &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	}
+	client := fake.NewSimpleClientset(service("a"))
+	watchers := make(chan *watch.FakeWatcher, 1)
+	client.PrependWatchReactor("services", func(k8stesting.Action) (bool, watch.Interface, error) {
+		w := watch.NewFake()
+		watchers <- w
+		return true, w, nil
+	})
+
+	w := &serviceWatch{client: client.ServingV1(), namespace: "ns", images: newRevisionImages(client.ServingV1())}
+	if err := w.list(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan []fn.ListItem)
+	go func() {
+		defer close(ch)
+		w.watch(ctx, ch)
+	}()
+
+	expect := func(names ...string) {
+		t.Helper()
+		select {
+		case items := <-ch:
+			if len(items) != len(names) {
+				t.Fatalf("expected %v, got %v", names, items)
+			}
+			for i, item := range items {
+				if item.Name != names[i] {
+					t.Fatalf("expected %v, got %v", names, items)
+				}
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %v", names)
+		}
+	}
+	watcher := func() *watch.FakeWatcher {
+		t.Helper()
+		select {
+		case w := <-watchers:
+			return w
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the watch")
+		}
+		return nil
+	}
+
+	expect("a")
+	fw := watcher()
+	fw.Add(service("b"))
+	expect("a", "b")
+	fw.Delete(service("a"))
+	expect("b")
+
+	// A failed watch is resumed, with the services relisted: those of the
+	// server, which are unchanged by the events of the fake watch.
+	fw.Error(&metav1.Status{Message: "too old resource version"})
+	expect("a")
+	fw = watcher()
+	fw.Modify(service("a"))
+	expect("a")
+
+	// The channel is closed once the context is done.
+	cancel()
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the channel closed")
+	}
+}
@@ -24,7 +24,7 @@ import (
 //
 // This function runs as long as the passed context is active (i.e. it is required cancel the context to stop log gathering).
 func GetKServiceLogs(ctx context.Context, namespace, kServiceName, image string, since *time.Time, out io.Writer) error {
-	client, namespace, err := k8s.NewClientAndResolvedNamespace(namespace)
+	client, namespace, err := k8s.NewClientAndResolvedNamespaceFrom(ctx, namespace)
 	if err != nil {
 		return fmt.Errorf("cannot create k8s client: %w", err)
 	}
@@ -0,0 +1,198 @@
+package knative
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"knative.dev/func/pkg/k8s"
+)
+
+const (
+	// PausedAnnotation of a paused service records the settings it had before
+	// being paused, such that they are restored when it is resumed.
+	PausedAnnotation = "func.knative.dev/paused"
+
+	// visibilityLabel of a Knative service, which when cluster-local is not
+	// routed from outside the cluster.
+	visibilityLabel = "networking.knative.dev/visibility"
+)
+
+var (
+	// ErrPaused indicates that a function to be paused is already paused.
+	ErrPaused = errors.New("function is already paused")
+
+	// ErrNotPaused indicates that a function to be resumed is not paused.
+	ErrNotPaused = errors.New("function is not paused")
+)
+
+// pausedState of a service, prior to being paused.  Empty values were unset.
+type pausedState struct {
+	MinScale   string `json:"minScale,omitempty"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// Pause the deployed Knative service, taking it offline until resumed: it is
+// no longer routed from outside the cluster, its pods admit no traffic from
+// within the cluster, such that requests to it fail, and it scales to zero
+// once idle, no longer being kept running by its minimum scale.  Its prior
+// settings are recorded in an annotation of the service, from which Resume
+// restores them.
+func Pause(ctx context.Context, name, namespace string) error {
+	return updatePaused(ctx, name, namespace, pauseService, blockIngress)
+}
+
+// Resume the paused Knative service, restoring the settings it had before it
+// was paused.
+func Resume(ctx context.Context, name, namespace string) error {
+	return updatePaused(ctx, name, namespace, resumeService, func(ctx context.Context, service *v1.Service) error {
+		return unblockIngress(ctx, service.Name, service.Namespace)
+	})
+}
+
+// updatePaused updates the service, having first updated the network policy
+// blocking its ingress, such that a failure to do so leaves the service to be
+// paused or resumed again.
+func updatePaused(ctx context.Context, name, namespace string, update func(*v1.Service) (*v1.Service, error), ingress func(context.Context, *v1.Service) error) error {
+	client, err := newServingClient(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	// Checked before updating, as an update which fails is retried.
+	service, err := client.GetService(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err = update(service.DeepCopy()); err != nil {
+		return err
+	}
+	if err = ingress(ctx, service); err != nil {
+		return err
+	}
+	_, err = client.UpdateServiceWithRetry(ctx, name, update, 3)
+	return err
+}
+
+// pausedPolicyName of the network policy blocking the ingress of the paused
+// service.
+func pausedPolicyName(name string) string {
+	return name + "-paused"
+}
+
+// pausedPolicy denying all ingress to the pods of the service, including
+// that of the activator and of the cluster's ingress, owned by the service
+// such that it is deleted with it.
+func pausedPolicy(service *v1.Service) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pausedPolicyName(service.Name),
+			Namespace: service.Namespace,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1.SchemeGroupVersion.String(),
+				Kind:       "Service",
+				Name:       service.Name,
+				UID:        service.UID,
+			}},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{serving.ServiceLabelKey: service.Name}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// blockIngress of the service's pods by its paused network policy.
+func blockIngress(ctx context.Context, service *v1.Service) error {
+	client, err := k8s.NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.NetworkingV1().NetworkPolicies(service.Namespace).Create(ctx, pausedPolicy(service), metav1.CreateOptions{})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("cannot block the ingress of %v: %w", service.Name, err)
+	}
+	return nil
+}
+
+// unblockIngress of the service's pods by deleting its paused network policy,
+// if any.
+func unblockIngress(ctx context.Context, name, namespace string) error {
+	client, err := k8s.NewKubernetesClientsetFrom(ctx)
+	if err != nil {
+		return err
+	}
+	err = client.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, pausedPolicyName(name), metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("cannot unblock the ingress of %v: %w", name, err)
+	}
+	return nil
+}
+
+// pauseService records the service's settings and takes it offline.
+func pauseService(service *v1.Service) (*v1.Service, error) {
+	if _, ok := service.Annotations[PausedAnnotation]; ok {
+		return nil, ErrPaused
+	}
+	template := service.Spec.Template.Annotations
+	state := pausedState{
+		MinScale:   template[autoscaling.MinScaleAnnotationKey],
+		Visibility: service.Labels[visibilityLabel],
+	}
+	bb, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	if service.Annotations == nil {
+		service.Annotations = map[string]string{}
+	}
+	service.Annotations[PausedAnnotation] = string(bb)
+	if service.Labels == nil {
+		service.Labels = map[string]string{}
+	}
+	service.Labels[visibilityLabel] = serving.VisibilityClusterLocal
+	if template == nil {
+		template = map[string]string{}
+	}
+	template[autoscaling.MinScaleAnnotationKey] = "0"
+	service.Spec.Template.Annotations = template
+	service.Spec.Template.Name = ""
+	return service, nil
+}
+
+// resumeService restores the settings recorded when the service was paused.
+func resumeService(service *v1.Service) (*v1.Service, error) {
+	recorded, ok := service.Annotations[PausedAnnotation]
+	if !ok {
+		return nil, ErrNotPaused
+	}
+	var state pausedState
+	if err := json.Unmarshal([]byte(recorded), &state); err != nil {
+		return nil, fmt.Errorf("invalid %v annotation: %w", PausedAnnotation, err)
+	}
+	restore := func(m map[string]string, key, value string) {
+		if value == "" {
+			delete(m, key)
+		} else {
+			m[key] = value
+		}
+	}
+	if service.Labels == nil {
+		service.Labels = map[string]string{}
+	}
+	restore(service.Labels, visibilityLabel, state.Visibility)
+	if service.Spec.Template.Annotations == nil {
+		service.Spec.Template.Annotations = map[string]string{}
+	}
+	restore(service.Spec.Template.Annotations, autoscaling.MinScaleAnnotationKey, state.MinScale)
+	delete(service.Annotations, PausedAnnotation)
+	service.Spec.Template.Name = ""
+	return service, nil
+}
@@ -0,0 +1,126 @@
+//go:build integration
+
+package knative_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	"knative.dev/func/pkg/knative"
+	"knative.dev/func/pkg/oci"
+)
+
+// TestInt_Pause ensures that a paused function cannot be invoked from within
+// the cluster, and that it can once resumed.
+func TestInt_Pause(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	name := "func-int-knative-pause-" + rand.String(5)
+	root := t.TempDir()
+	ns := namespace(t, ctx)
+
+	t.Cleanup(cancel)
+
+	client := fn.New(
+		fn.WithBuilder(oci.NewBuilder("", false)),
+		fn.WithPusher(oci.NewPusher(true, true, true)),
+		fn.WithDeployer(knative.NewDeployer(knative.WithDeployerVerbose(true))),
+		fn.WithDescriber(knative.NewDescriber(false)),
+		fn.WithRemover(knative.NewRemover(false)),
+	)
+
+	f, err := client.Init(fn.Function{
+		Root:      root,
+		Name:      name,
+		Runtime:   "go",
+		Namespace: ns,
+		Registry:  registry(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if f, _, err = client.Push(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Deploy(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := client.Remove(ctx, "", "", f, true); err != nil {
+			t.Logf("error removing Function: %v", err)
+		}
+	})
+
+	url := fmt.Sprintf("http://%v.%v.svc.cluster.local", name, ns)
+	if !requestInCluster(t, ctx, ns, url) {
+		t.Fatal("expected the function to be invoked from within the cluster")
+	}
+
+	if err = knative.Pause(ctx, name, ns); err != nil {
+		t.Fatal(err)
+	}
+	if requestInCluster(t, ctx, ns, url) {
+		t.Fatal("expected a request to the paused function from within the cluster to fail")
+	}
+
+	if err = knative.Resume(ctx, name, ns); err != nil {
+		t.Fatal(err)
+	}
+	if !requestInCluster(t, ctx, ns, url) {
+		t.Fatal("expected the resumed function to be invoked from within the cluster")
+	}
+}
+
+// requestInCluster of the url by a pod of the namespace, returning whether
+// it was served successfully.
+func requestInCluster(t *testing.T, ctx context.Context, namespace, url string) bool {
+	t.Helper()
+	cliSet, err := k8s.NewKubernetesClientset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "func-int-request-"},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:  "curl",
+				Image: "curlimages/curl",
+				Args:  []string{"-sf", "--retry", "5", "--retry-all-errors", "--max-time", "20", url},
+			}},
+		},
+	}
+	if pod, err = cliSet.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cliSet.CoreV1().Pods(namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{})
+	})
+	for {
+		p, err := cliSet.CoreV1().Pods(namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch p.Status.Phase {
+		case corev1.PodSucceeded:
+			return true
+		case corev1.PodFailed:
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("timeout waiting for the request of %v", url)
+		case <-time.After(time.Second):
+		}
+	}
+}
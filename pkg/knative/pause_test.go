@@ -0,0 +1,98 @@
+package knative
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// Test_pauseService ensures that a paused service is restored to its prior
+// settings when resumed, including those which were unset.
+func Test_pauseService(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+	}{
+		{"unset", nil, nil},
+		{"set", map[string]string{visibilityLabel: "cluster-local", "app": "myfn"},
+			map[string]string{autoscaling.MinScaleAnnotationKey: "2", autoscaling.MaxScaleAnnotationKey: "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &v1.Service{}
+			service.Labels = copyMap(tt.labels)
+			service.Spec.Template.Annotations = copyMap(tt.annotations)
+
+			paused, err := pauseService(service.DeepCopy())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if paused.Labels[visibilityLabel] != "cluster-local" || paused.Spec.Template.Annotations[autoscaling.MinScaleAnnotationKey] != "0" {
+				t.Fatalf("expected the service to be taken offline, got labels %v annotations %v", paused.Labels, paused.Spec.Template.Annotations)
+			}
+			if _, err = pauseService(paused.DeepCopy()); !errors.Is(err, ErrPaused) {
+				t.Fatalf("expected ErrPaused, got %v", err)
+			}
+
+			resumed, err := resumeService(paused.DeepCopy())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := resumed.Annotations[PausedAnnotation]; ok {
+				t.Fatal("expected the paused annotation to be removed")
+			}
+			if !reflect.DeepEqual(nonNil(resumed.Labels), nonNil(tt.labels)) ||
+				!reflect.DeepEqual(nonNil(resumed.Spec.Template.Annotations), nonNil(tt.annotations)) {
+				t.Fatalf("expected labels %v annotations %v restored, got %v %v", tt.labels, tt.annotations,
+					resumed.Labels, resumed.Spec.Template.Annotations)
+			}
+			if _, err = resumeService(resumed.DeepCopy()); !errors.Is(err, ErrNotPaused) {
+				t.Fatalf("expected ErrNotPaused, got %v", err)
+			}
+		})
+	}
+}
+
+// Test_pausedPolicy ensures that the network policy of a paused service
+// denies all ingress to its pods, and is owned by the service.
+func Test_pausedPolicy(t *testing.T) {
+	service := &v1.Service{}
+	service.Name, service.Namespace, service.UID = "myfn", "ns", "uid"
+
+	p := pausedPolicy(service)
+	if p.Name != "myfn-paused" || p.Namespace != "ns" {
+		t.Fatalf("unexpected policy %v/%v", p.Namespace, p.Name)
+	}
+	if !reflect.DeepEqual(p.Spec.PodSelector.MatchLabels, map[string]string{"serving.knative.dev/service": "myfn"}) {
+		t.Errorf("expected the pods of the service selected, got %v", p.Spec.PodSelector)
+	}
+	if len(p.Spec.PolicyTypes) != 1 || p.Spec.PolicyTypes[0] != networkingv1.PolicyTypeIngress || len(p.Spec.Ingress) != 0 {
+		t.Errorf("expected all ingress denied, got %+v", p.Spec)
+	}
+	if len(p.OwnerReferences) != 1 || p.OwnerReferences[0].UID != "uid" || p.OwnerReferences[0].Kind != "Service" {
+		t.Errorf("expected the policy owned by the service, got %v", p.OwnerReferences)
+	}
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := map[string]string{}
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func nonNil(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
@@ -28,7 +28,7 @@ func (remover *Remover) Remove(ctx context.Context, name, ns string) (err error)
 		return fn.ErrNamespaceRequired
 	}
 
-	client, err := NewServingClient(ns)
+	client, err := newServingClient(ctx, ns)
 	if err != nil {
 		return
 	}
@@ -39,7 +39,12 @@ func (remover *Remover) Remove(ctx context.Context, name, ns string) (err error)
 			return fn.ErrFunctionNotFound
 		}
 		err = fmt.Errorf("knative remover failed to delete the service: %v", err)
+		return
 	}
 
-	return
+	domainClient, err := newDomainMappingClient(ctx)
+	if err != nil {
+		return
+	}
+	return removeDomainMappings(ctx, domainClient, name, ns)
 }
@@ -0,0 +1,233 @@
+package knative
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	eventingv1 "knative.dev/eventing/pkg/apis/eventing/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// Render the Knative Service, the Triggers of its subscriptions and the
+// DomainMappings of its custom domains, which deploying the function would
+// create or update, as a YAML stream.
+//
+// A client dry run renders the resources as they would be created, without
+// contacting the cluster, such that a function previously deployed is
+// rendered as if deployed for the first time.  A server dry run submits the
+// resources to the cluster for admission without persisting them, updating
+// the Service if already deployed, such that they are rendered as validated
+// and defaulted by the cluster.
+func (d *Deployer) Render(ctx context.Context, f fn.Function, mode fn.DryRun) ([]byte, error) {
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = f.Deploy.Namespace
+	}
+	if f.Deploy.Image == "" {
+		f.Deploy.Image = f.Build.Image
+	}
+	f = d.withRoute(f)
+
+	var (
+		service  *v1.Service
+		triggers []*eventingv1.Trigger
+		err      error
+	)
+	switch mode {
+	case fn.DryRunClient:
+		if service, err = generateNewService(f, d.decorator, false); err != nil {
+			return nil, fmt.Errorf("knative deployer failed to generate the Knative Service: %v", err)
+		}
+		service.Namespace = namespace
+		triggers = generateTriggers(f, withServiceType(service))
+	case fn.DryRunServer:
+		f = onClusterFix(ctx, f)
+		if namespace == "" {
+			namespace = f.Namespace
+		}
+		if service, triggers, err = d.renderServer(ctx, f, namespace); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid dry run %q", mode)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err = encodeManifest(enc, withServiceType(service)); err != nil {
+		return nil, err
+	}
+	for _, t := range triggers {
+		t.TypeMeta = metav1.TypeMeta{APIVersion: eventingv1.SchemeGroupVersion.String(), Kind: "Trigger"}
+		t.Namespace = namespace
+		if err = encodeManifest(enc, t); err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range generateDomainMappings(f, namespace) {
+		if err = encodeManifest(enc, m); err != nil {
+			return nil, err
+		}
+	}
+	if err = enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// References of a function to resources which it requires exist in the
+// namespace to which it is deployed, but which are not deployed with it.
+type References struct {
+	Secrets                []string
+	ConfigMaps             []string
+	PersistentVolumeClaims []string
+	ServiceAccount         string
+}
+
+// ReferencesOf the function, by its environment, volumes and service account,
+// each sorted by name.
+func ReferencesOf(f fn.Function) (r References, err error) {
+	secrets := sets.New[string]()
+	configMaps := sets.New[string]()
+	pvcs := sets.New[string]()
+	if _, _, err = k8s.ProcessEnvs(f.Run.Envs, &secrets, &configMaps); err != nil {
+		return
+	}
+	if _, _, err = k8s.ProcessVolumes(f.Run.Volumes, &secrets, &configMaps, &pvcs); err != nil {
+		return
+	}
+	r.Secrets = sets.List(secrets)
+	r.ConfigMaps = sets.List(configMaps)
+	r.PersistentVolumeClaims = sets.List(pvcs)
+	if sa := f.Deploy.ServiceAccountName; sa != "default" {
+		r.ServiceAccount = sa
+	}
+	return
+}
+
+// renderServer creates, or updates if deployed, the function's service and
+// creates its triggers, each as a server dry run.  Returned are the resources
+// as admitted by the cluster.
+func (d *Deployer) renderServer(ctx context.Context, f fn.Function, namespace string) (*v1.Service, []*eventingv1.Trigger, error) {
+	if namespace == "" {
+		return nil, nil, fmt.Errorf("deployer requires either a target namespace or that the function be already deployed")
+	}
+	serving, err := newServingV1Client(ctx)
+	if err != nil {
+		return nil, nil, wrapDeployerClientError(err)
+	}
+	eventing, err := newEventingClientset(ctx)
+	if err != nil {
+		return nil, nil, wrapDeployerClientError(err)
+	}
+	daprInstalled, err := isDaprInstalled(ctx)
+	if err != nil {
+		return nil, nil, wrapDeployerClientError(err)
+	}
+
+	referencedSecrets := sets.New[string]()
+	referencedConfigMaps := sets.New[string]()
+	referencedPVCs := sets.New[string]()
+	newEnv, newEnvFrom, err := k8s.ProcessEnvs(f.Run.Envs, &referencedSecrets, &referencedConfigMaps)
+	if err != nil {
+		return nil, nil, err
+	}
+	newVolumes, newVolumeMounts, err := k8s.ProcessVolumes(f.Run.Volumes, &referencedSecrets, &referencedConfigMaps, &referencedPVCs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = k8s.CheckResourcesArePresent(ctx, namespace, &referencedSecrets, &referencedConfigMaps, &referencedPVCs, f.Deploy.ServiceAccountName); err != nil {
+		return nil, nil, fmt.Errorf("knative deployer failed to generate the Knative Service: %v", err)
+	}
+
+	dryRun := []string{metav1.DryRunAll}
+	services := serving.Services(namespace)
+	previous, err := services.Get(ctx, f.Name, metav1.GetOptions{})
+	var service *v1.Service
+	switch {
+	case errors.IsNotFound(err):
+		if service, err = generateNewService(f, d.decorator, daprInstalled); err != nil {
+			return nil, nil, fmt.Errorf("knative deployer failed to generate the Knative Service: %v", err)
+		}
+		if service, err = services.Create(ctx, service, metav1.CreateOptions{DryRun: dryRun}); err != nil {
+			return nil, nil, fmt.Errorf("knative deployer failed to dry run the Knative Service: %v", err)
+		}
+	case err != nil:
+		if wrappedErr := wrapK8sConnectionError(err); wrappedErr != nil {
+			return nil, nil, wrappedErr
+		}
+		return nil, nil, fmt.Errorf("knative deployer failed to get the Knative Service: %v", err)
+	default:
+		update := updateService(f, previous, newEnv, newEnvFrom, newVolumes, newVolumeMounts, d.decorator, daprInstalled)
+		if service, err = update(previous.DeepCopy()); err != nil {
+			return nil, nil, fmt.Errorf("knative deployer failed to update the Knative Service: %v", err)
+		}
+		if service, err = services.Update(ctx, service, metav1.UpdateOptions{DryRun: dryRun}); err != nil {
+			return nil, nil, fmt.Errorf("knative deployer failed to dry run the Knative Service: %v", err)
+		}
+	}
+
+	var triggers []*eventingv1.Trigger
+	for _, t := range generateTriggers(f, withServiceType(service)) {
+		created, err := eventing.EventingV1().Triggers(namespace).Create(ctx, t, metav1.CreateOptions{DryRun: dryRun})
+		if errors.IsAlreadyExists(err) {
+			triggers = append(triggers, t) // as deploying, which leaves it be
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("knative deployer failed to dry run the Trigger: %v", err)
+		}
+		triggers = append(triggers, created)
+	}
+	return service, triggers, nil
+}
+
+// withServiceType sets the type of the service, which is not set of those
+// generated nor of those returned by the typed client, but is that by which
+// its triggers refer to it.
+func withServiceType(service *v1.Service) *v1.Service {
+	service.TypeMeta = metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Service"}
+	return service
+}
+
+// encodeManifest of the object as a document of the YAML stream, without its
+// status or the fields managed by the cluster, which are not applied.
+func encodeManifest(enc *yaml.Encoder, obj runtime.Object) error {
+	manifest, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(manifest, "status")
+	if spec, ok := manifest["spec"].(map[string]any); ok {
+		if template, ok := spec["template"].(map[string]any); ok {
+			if metadata, ok := template["metadata"].(map[string]any); ok {
+				delete(metadata, "creationTimestamp")
+			}
+		}
+	}
+	if metadata, ok := manifest["metadata"].(map[string]any); ok {
+		delete(metadata, "creationTimestamp")
+		delete(metadata, "managedFields")
+		// The owner of a trigger of a service not yet created has no UID,
+		// without which it may not be applied.
+		if refs, ok := metadata["ownerReferences"].([]any); ok {
+			for _, ref := range refs {
+				if r, ok := ref.(map[string]any); ok && r["uid"] == "" {
+					delete(metadata, "ownerReferences")
+					break
+				}
+			}
+		}
+	}
+	return enc.Encode(manifest)
+}
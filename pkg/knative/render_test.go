@@ -0,0 +1,64 @@
+package knative
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestDeployer_RenderClient ensures that a client dry run renders the service
+// and the triggers of the function's subscriptions as a YAML stream, without
+// a cluster.
+func TestDeployer_RenderClient(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/nonexistent")
+	f := fn.Function{
+		Name:      "f",
+		Runtime:   "go",
+		Namespace: "ns",
+		Build:     fn.BuildSpec{Image: "example.com/alice/f:latest"},
+		Deploy: fn.DeploySpec{
+			Subscriptions: []fn.KnativeSubscription{
+				{Source: "default", Filters: map[string]string{"type": "com.example"}},
+			},
+		},
+	}
+	bb, err := NewDeployer().Render(context.Background(), f, fn.DryRunClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var manifests []map[string]any
+	dec := yaml.NewDecoder(strings.NewReader(string(bb)))
+	for {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			break
+		}
+		manifests = append(manifests, m)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("expected a service and a trigger, got:\n%s", bb)
+	}
+	if manifests[0]["kind"] != "Service" || manifests[1]["kind"] != "Trigger" {
+		t.Fatalf("expected a service then a trigger, got:\n%s", bb)
+	}
+	for _, m := range manifests {
+		metadata := m["metadata"].(map[string]any)
+		if metadata["namespace"] != "ns" {
+			t.Errorf("expected namespace ns, got %v", metadata["namespace"])
+		}
+		if _, ok := m["status"]; ok {
+			t.Errorf("expected no status rendered of %v", m["kind"])
+		}
+		if _, ok := metadata["ownerReferences"]; ok {
+			t.Errorf("expected no owner of a service not yet created")
+		}
+	}
+	if !strings.Contains(string(bb), "image: example.com/alice/f:latest") {
+		t.Errorf("expected the built image rendered, got:\n%s", bb)
+	}
+}
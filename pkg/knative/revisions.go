@@ -0,0 +1,282 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// Revision of a deployed function, as relevant to what changed between
+// deployments.
+type Revision struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Ready   bool      `json:"ready"`
+	// Image of the revision, as deployed.  The digest, if resolved by the
+	// cluster, is that of ImageDigest.
+	Image       string `json:"image"`
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// Traffic routed to the revision, as a percentage, and its tags.
+	Traffic int64    `json:"traffic"`
+	Tags    []string `json:"tags,omitempty"`
+	// Envs of the revision, by name.  Values from secrets and config maps
+	// are their references, for example "secret:mysecret/key".
+	Envs map[string]string `json:"envs,omitempty"`
+	// EnvFrom secrets and config maps, for example "configMap:myconfig".
+	EnvFrom []string `json:"envFrom,omitempty"`
+	// Resources of the revision's container, for example "limits.memory".
+	Resources map[string]string `json:"resources,omitempty"`
+	// Scale of the revision: its autoscaling annotations, such as
+	// "min-scale", and its "concurrency".
+	Scale map[string]string `json:"scale,omitempty"`
+}
+
+// Revisions of the deployed Knative service, newest first, with the traffic
+// routed to each.
+func Revisions(ctx context.Context, name, namespace string) ([]Revision, error) {
+	client, err := NewServingClient(namespace)
+	if err != nil {
+		return nil, err
+	}
+	service, err := client.GetService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.ListRevisions(ctx, clientservingv1.WithService(name))
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the revisions of %v: %w", name, err)
+	}
+	return newRevisions(service, list.Items), nil
+}
+
+// newRevisions of the service from those listed, newest first.
+func newRevisions(service *v1.Service, items []v1.Revision) []Revision {
+	rr := make([]Revision, 0, len(items))
+	for i := range items {
+		r := newRevision(&items[i])
+		for _, t := range service.Status.Traffic {
+			if trafficRevision(service, t) != r.Name {
+				continue
+			}
+			if t.Percent != nil {
+				r.Traffic += *t.Percent
+			}
+			if t.Tag != "" {
+				r.Tags = append(r.Tags, t.Tag)
+			}
+		}
+		rr = append(rr, r)
+	}
+	sort.SliceStable(rr, func(i, j int) bool { return rr[i].Created.After(rr[j].Created) })
+	return rr
+}
+
+// pruneRevisions of the service, deleting all but the newest keep of those of
+// its revisions which are no longer referenced.  The revision latest created
+// before the deployment, previous, distinguishes those created since, which
+// the service's status may yet to reflect, such as when not waited upon.
+// Those which are not deleted, of having been deleted meanwhile, are ignored.
+func pruneRevisions(ctx context.Context, client clientservingv1.KnServingClient, name, previous string, keep int64, verbose bool) error {
+	service, err := client.GetService(ctx, name)
+	if err != nil {
+		return err
+	}
+	list, err := client.ListRevisions(ctx, clientservingv1.WithService(name))
+	if err != nil {
+		return fmt.Errorf("cannot list the revisions of %v: %w", name, err)
+	}
+	for _, revision := range prunableRevisions(service, list.Items, previous, keep) {
+		if err = client.DeleteRevision(ctx, revision, 0); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("cannot delete the revision %v: %w", revision, err)
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "🧹 Deleted the old revision %v\n", revision)
+		}
+	}
+	return nil
+}
+
+// prunableRevisions of the service: the names of those of its revisions which
+// are unreferenced, being neither its latest created nor latest ready nor
+// routed traffic nor tagged, nor of a later generation than the previous
+// revision latest created, but for the newest keep of those.  Should the
+// generation of the previous revision not be known, none are prunable.
+func prunableRevisions(service *v1.Service, items []v1.Revision, previous string, keep int64) (names []string) {
+	referenced := map[string]bool{
+		service.Status.LatestCreatedRevisionName: true,
+		service.Status.LatestReadyRevisionName:   true,
+	}
+	for _, t := range service.Spec.Traffic {
+		referenced[t.RevisionName] = true
+	}
+	for _, t := range service.Status.Traffic {
+		referenced[trafficRevision(service, t)] = true
+	}
+	generation := int64(-1)
+	for _, r := range items {
+		if r.Name == previous {
+			generation = revisionGeneration(r)
+		}
+	}
+	if generation < 0 {
+		return
+	}
+	var unreferenced []v1.Revision
+	for _, r := range items {
+		if g := revisionGeneration(r); !referenced[r.Name] && g >= 0 && g <= generation {
+			unreferenced = append(unreferenced, r)
+		}
+	}
+	sort.SliceStable(unreferenced, func(i, j int) bool {
+		return unreferenced[i].CreationTimestamp.After(unreferenced[j].CreationTimestamp.Time)
+	})
+	for i, r := range unreferenced {
+		if int64(i) >= keep {
+			names = append(names, r.Name)
+		}
+	}
+	return
+}
+
+// revisionGeneration of the configuration of which the revision was created,
+// or -1 if it is not known.
+func revisionGeneration(r v1.Revision) int64 {
+	g, err := strconv.ParseInt(r.Labels[serving.ConfigurationGenerationLabelKey], 10, 64)
+	if err != nil {
+		return -1
+	}
+	return g
+}
+
+// trafficRevision to which the traffic target routes, the latest being
+// resolved to the service's latest ready revision.
+func trafficRevision(service *v1.Service, t v1.TrafficTarget) string {
+	if t.RevisionName == "" && t.LatestRevision != nil && *t.LatestRevision {
+		return service.Status.LatestReadyRevisionName
+	}
+	return t.RevisionName
+}
+
+// newTraffic of the service as routed by the cluster.
+func newTraffic(service *v1.Service) []fn.TrafficTarget {
+	var tt []fn.TrafficTarget
+	for _, t := range service.Status.Traffic {
+		target := fn.TrafficTarget{
+			Revision: trafficRevision(service, t),
+			Tag:      t.Tag,
+			Latest:   t.LatestRevision != nil && *t.LatestRevision,
+		}
+		if t.Percent != nil {
+			target.Percent = *t.Percent
+		}
+		tt = append(tt, target)
+	}
+	return tt
+}
+
+// newProvisioning of the service as configured by its template: the
+// resources of its container and its scale annotations.  Scale annotations
+// which are not integers are not bounds.
+func newProvisioning(service *v1.Service) (p fn.Provisioning) {
+	bound := func(key string) *int64 {
+		if v, err := strconv.ParseInt(service.Spec.Template.Annotations[key], 10, 64); err == nil {
+			return &v
+		}
+		return nil
+	}
+	p.MinScale = bound(autoscaling.MinScaleAnnotationKey)
+	p.MaxScale = bound(autoscaling.MaxScaleAnnotationKey)
+	if len(service.Spec.Template.Spec.Containers) == 0 {
+		return
+	}
+	quantity := func(rl corev1.ResourceList, name corev1.ResourceName) string {
+		if q, ok := rl[name]; ok {
+			return q.String()
+		}
+		return ""
+	}
+	r := service.Spec.Template.Spec.Containers[0].Resources
+	p.CPURequest = quantity(r.Requests, corev1.ResourceCPU)
+	p.CPULimit = quantity(r.Limits, corev1.ResourceCPU)
+	p.MemoryRequest = quantity(r.Requests, corev1.ResourceMemory)
+	p.MemoryLimit = quantity(r.Limits, corev1.ResourceMemory)
+	return
+}
+
+func newRevision(rev *v1.Revision) Revision {
+	r := Revision{
+		Name:      rev.Name,
+		Created:   rev.CreationTimestamp.Time,
+		Ready:     rev.IsReady(),
+		Envs:      map[string]string{},
+		Resources: map[string]string{},
+		Scale:     map[string]string{},
+	}
+	if len(rev.Status.ContainerStatuses) > 0 {
+		r.ImageDigest = rev.Status.ContainerStatuses[0].ImageDigest
+	}
+	for _, key := range []string{autoscaling.MinScaleAnnotationKey, autoscaling.MaxScaleAnnotationKey,
+		autoscaling.ClassAnnotationKey, autoscaling.TargetAnnotationKey, autoscaling.MetricAnnotationKey, autoscaling.TargetUtilizationPercentageKey} {
+		if v, ok := rev.Annotations[key]; ok {
+			r.Scale[strings.TrimPrefix(key, autoscaling.GroupName+"/")] = v
+		}
+	}
+	if rev.Spec.ContainerConcurrency != nil {
+		r.Scale["concurrency"] = strconv.FormatInt(*rev.Spec.ContainerConcurrency, 10)
+	}
+	if len(rev.Spec.Containers) == 0 {
+		return r
+	}
+	c := rev.Spec.Containers[0]
+	r.Image = c.Image
+	for _, e := range c.Env {
+		r.Envs[e.Name] = k8s.EnvValue(e)
+	}
+	for _, e := range c.EnvFrom {
+		if e.SecretRef != nil {
+			r.EnvFrom = append(r.EnvFrom, "secret:"+e.SecretRef.Name)
+		}
+		if e.ConfigMapRef != nil {
+			r.EnvFrom = append(r.EnvFrom, "configMap:"+e.ConfigMapRef.Name)
+		}
+	}
+	for name, q := range c.Resources.Requests {
+		r.Resources["requests."+string(name)] = q.String()
+	}
+	for name, q := range c.Resources.Limits {
+		r.Resources["limits."+string(name)] = q.String()
+	}
+	return r
+}
+
+// Diff the revisions, returning the changes from a to b of their image,
+// environment, resources and scale, in that order.
+func Diff(a, b Revision) []fn.Change {
+	return a.snapshot().Diff(b.snapshot())
+}
+
+// snapshot of the revision, of those fields by which revisions differ.
+func (r Revision) snapshot() fn.Snapshot {
+	return fn.Snapshot{
+		Image:       r.Image,
+		ImageDigest: r.ImageDigest,
+		Envs:        r.Envs,
+		EnvFrom:     r.EnvFrom,
+		Resources:   r.Resources,
+		Scale:       r.Scale,
+	}
+}
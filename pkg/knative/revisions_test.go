@@ -0,0 +1,189 @@
+package knative
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_newRevisions ensures that revisions are listed newest first, with the
+// traffic routed to each, including that to the latest revision.
+func Test_newRevisions(t *testing.T) {
+	now := time.Now()
+	revision := func(name string, age time.Duration, image string) v1.Revision {
+		r := v1.Revision{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))}}
+		r.Annotations = map[string]string{autoscaling.MinScaleAnnotationKey: "1"}
+		r.Spec.Containers = []corev1.Container{{
+			Image: image,
+			Env: []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "s"}, Key: "k"}}}},
+			Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}},
+		}}
+		return r
+	}
+	latest, fifty, ten := true, int64(50), int64(10)
+	service := &v1.Service{}
+	service.Status.LatestReadyRevisionName = "fn-00002"
+	service.Status.Traffic = []v1.TrafficTarget{
+		{LatestRevision: &latest, Percent: &fifty},
+		{RevisionName: "fn-00001", Percent: &fifty, Tag: "stable"},
+		{RevisionName: "fn-00001", Percent: &ten, Tag: "canary"},
+	}
+
+	rr := newRevisions(service, []v1.Revision{
+		revision("fn-00001", time.Hour, "example.com/fn:v1"),
+		revision("fn-00002", time.Minute, "example.com/fn:v2"),
+	})
+	if len(rr) != 2 || rr[0].Name != "fn-00002" || rr[1].Name != "fn-00001" {
+		t.Fatalf("expected the newest revision first, got %+v", rr)
+	}
+	if rr[0].Traffic != 50 || rr[1].Traffic != 60 || !reflect.DeepEqual(rr[1].Tags, []string{"stable", "canary"}) {
+		t.Fatalf("unexpected traffic %v and %v (%v)", rr[0].Traffic, rr[1].Traffic, rr[1].Tags)
+	}
+	r := rr[1]
+	if r.Image != "example.com/fn:v1" || r.Envs["A"] != "1" || r.Envs["B"] != "secret:s/k" ||
+		r.Resources["limits.memory"] != "256Mi" || r.Scale["min-scale"] != "1" {
+		t.Fatalf("unexpected revision %+v", r)
+	}
+}
+
+// Test_newTraffic ensures that the traffic of a service is that of each of
+// its targets, the latest resolved to the latest ready revision.
+func Test_newTraffic(t *testing.T) {
+	latest, ninety, ten := true, int64(90), int64(10)
+	service := &v1.Service{}
+	service.Status.LatestReadyRevisionName = "fn-00002"
+	service.Status.Traffic = []v1.TrafficTarget{
+		{LatestRevision: &latest, Percent: &ninety},
+		{RevisionName: "fn-00001", Percent: &ten, Tag: "canary"},
+	}
+	want := []fn.TrafficTarget{
+		{Revision: "fn-00002", Percent: 90, Latest: true},
+		{Revision: "fn-00001", Percent: 10, Tag: "canary"},
+	}
+	if got := newTraffic(service); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+// TestDiff ensures that the changes between revisions are those of their
+// image, environment, resources and scale.
+func TestDiff(t *testing.T) {
+	a := Revision{
+		Image:     "example.com/fn:v1",
+		Envs:      map[string]string{"A": "1", "B": "2"},
+		Resources: map[string]string{"limits.memory": "256Mi"},
+		Scale:     map[string]string{"min-scale": "1"},
+	}
+	b := Revision{
+		Image:     "example.com/fn:v2",
+		Envs:      map[string]string{"A": "1", "C": "3"},
+		EnvFrom:   []string{"secret:s"},
+		Resources: map[string]string{"limits.memory": "512Mi"},
+		Scale:     map[string]string{"min-scale": "1"},
+	}
+	want := []fn.Change{
+		{Field: "image", From: "example.com/fn:v1", To: "example.com/fn:v2"},
+		{Field: "envs.B", From: "2"},
+		{Field: "envs.C", To: "3"},
+		{Field: "envFrom", To: "secret:s"},
+		{Field: "resources.limits.memory", From: "256Mi", To: "512Mi"},
+	}
+	if got := Diff(a, b); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := Diff(a, a); len(got) != 0 {
+		t.Fatalf("expected no changes, got %+v", got)
+	}
+}
+
+// Test_newProvisioning ensures that the resources and scale bounds of a
+// service are those of its template.
+func Test_newProvisioning(t *testing.T) {
+	service := &v1.Service{}
+	service.Spec.Template.Annotations = map[string]string{autoscaling.MinScaleAnnotationKey: "1", autoscaling.MaxScaleAnnotationKey: "x"}
+	service.Spec.Template.Spec.Containers = []corev1.Container{{Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}}}
+	p := newProvisioning(service)
+	if p.CPURequest != "100m" || p.CPULimit != "" || p.MemoryRequest != "" || p.MemoryLimit != "256Mi" {
+		t.Errorf("unexpected resources %+v", p)
+	}
+	if p.MinScale == nil || *p.MinScale != 1 || p.MaxScale != nil {
+		t.Errorf("expected a minimum scale of 1 and no maximum, got %v and %v", p.MinScale, p.MaxScale)
+	}
+}
+
+// Test_prunableRevisions ensures that of the revisions of a service those
+// unreferenced are pruned but for the newest retained, and that those routed
+// traffic, tagged, or latest are never pruned.
+func Test_prunableRevisions(t *testing.T) {
+	items := testRevisions(6)
+	latest, hundred := true, int64(100)
+	service := &v1.Service{}
+	service.Spec.Traffic = []v1.TrafficTarget{
+		{LatestRevision: &latest, Percent: &hundred},
+		{RevisionName: "fn-00001", Tag: "stable"},
+	}
+	service.Status.LatestCreatedRevisionName = "fn-00006"
+	service.Status.LatestReadyRevisionName = "fn-00005"
+
+	tests := []struct {
+		keep     int64
+		expected []string
+	}{
+		{0, []string{"fn-00004", "fn-00003", "fn-00002"}},
+		{1, []string{"fn-00003", "fn-00002"}},
+		{3, nil},
+	}
+	for _, tt := range tests {
+		if got := prunableRevisions(service, items, "fn-00005", tt.keep); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("expected of %d retained %v pruned, got %v", tt.keep, tt.expected, got)
+		}
+	}
+}
+
+// Test_prunableRevisions_NotObserved ensures that a revision created by the
+// deployment is not pruned while the status of the service has yet to reflect
+// it, as when the deployment is not waited upon.
+func Test_prunableRevisions_NotObserved(t *testing.T) {
+	items := testRevisions(4)
+	latest, hundred := true, int64(100)
+	service := &v1.Service{}
+	service.Spec.Traffic = []v1.TrafficTarget{{LatestRevision: &latest, Percent: &hundred}}
+	service.Status.LatestCreatedRevisionName = "fn-00003" // not yet fn-00004
+	service.Status.LatestReadyRevisionName = "fn-00003"
+
+	expected := []string{"fn-00002", "fn-00001"}
+	if got := prunableRevisions(service, items, "fn-00003", 0); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v pruned, got %v", expected, got)
+	}
+	if got := prunableRevisions(service, items, "", 0); got != nil {
+		t.Errorf("expected none pruned without a previous revision, got %v", got)
+	}
+}
+
+// testRevisions named and of the generation 1 to n, created a minute apart.
+func testRevisions(n int) (items []v1.Revision) {
+	now := time.Now()
+	for i := 1; i <= n; i++ {
+		items = append(items, v1.Revision{ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("fn-%05d", i),
+			Labels:            map[string]string{serving.ConfigurationGenerationLabelKey: strconv.Itoa(i)},
+			CreationTimestamp: metav1.NewTime(now.Add(time.Duration(i) * time.Minute)),
+		}})
+	}
+	return
+}
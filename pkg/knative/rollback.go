@@ -0,0 +1,70 @@
+package knative
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	"knative.dev/client/pkg/wait"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// ErrNoPreviousRevision indicates that there is no ready revision older than
+// that serving a function, to which it may be rolled back.
+var ErrNoPreviousRevision = errors.New("there is no ready revision prior to that serving the function")
+
+// PreviousRevision of the revisions, newest first as of Revisions: the newest
+// ready revision older than that currently routed the most traffic.
+func PreviousRevision(rr []Revision) (Revision, error) {
+	current := 0
+	for i, r := range rr {
+		if r.Traffic > rr[current].Traffic {
+			current = i
+		}
+	}
+	for _, r := range rr[min(current+1, len(rr)):] {
+		if r.Ready {
+			return r, nil
+		}
+	}
+	return Revision{}, ErrNoPreviousRevision
+}
+
+// Rollback the deployed Knative service, routing all of its traffic to the
+// revision.  Traffic is routed to the revision by name, such that revisions
+// of later deployments are routed none of it until routed otherwise, such as
+// by deploying with a traffic split.  The tags of the service's traffic are
+// retained, routed no traffic, such that their URLs continue to resolve.
+func Rollback(ctx context.Context, name, namespace, revision string) error {
+	client, err := NewServingClient(namespace)
+	if err != nil {
+		return err
+	}
+	if _, err = client.UpdateServiceWithRetry(ctx, name, rollbackService(revision), 3); err != nil {
+		return fmt.Errorf("cannot roll back %v: %w", name, err)
+	}
+	err, _ = client.WaitForService(ctx, name,
+		clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
+		wait.NoopMessageCallback())
+	return err
+}
+
+// rollbackService returns an update of a service's traffic, routing all of it
+// to the revision.
+func rollbackService(revision string) func(*v1.Service) (*v1.Service, error) {
+	return func(service *v1.Service) (*v1.Service, error) {
+		all := int64(100)
+		traffic := []v1.TrafficTarget{{RevisionName: revision, Percent: &all}}
+		for _, t := range service.Spec.Traffic {
+			if t.Tag == "" {
+				continue
+			}
+			none := int64(0)
+			t.Percent = &none
+			traffic = append(traffic, t)
+		}
+		service.Spec.Traffic = traffic
+		return service, nil
+	}
+}
@@ -0,0 +1,75 @@
+package knative
+
+import (
+	"testing"
+
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func TestPreviousRevision(t *testing.T) {
+	tests := []struct {
+		name    string
+		rr      []Revision
+		want    string
+		wantErr bool
+	}{
+		{"previous of latest", []Revision{
+			{Name: "f-00003", Ready: true, Traffic: 100},
+			{Name: "f-00002", Ready: true},
+			{Name: "f-00001", Ready: true},
+		}, "f-00002", false},
+		{"previous of rolled back", []Revision{
+			{Name: "f-00003", Ready: true},
+			{Name: "f-00002", Ready: true, Traffic: 100},
+			{Name: "f-00001", Ready: true},
+		}, "f-00001", false},
+		{"previous of split", []Revision{
+			{Name: "f-00003", Ready: true, Traffic: 10},
+			{Name: "f-00002", Ready: true, Traffic: 90},
+			{Name: "f-00001", Ready: true},
+		}, "f-00001", false},
+		{"skips unready", []Revision{
+			{Name: "f-00003", Ready: true, Traffic: 100},
+			{Name: "f-00002", Ready: false},
+			{Name: "f-00001", Ready: true},
+		}, "f-00001", false},
+		{"none", []Revision{{Name: "f-00001", Ready: true, Traffic: 100}}, "", true},
+		{"no revisions", nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PreviousRevision(tt.rr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PreviousRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Name != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got.Name)
+			}
+		})
+	}
+}
+
+// Test_rollbackService ensures that all traffic is routed to the revision,
+// retaining the tags of the previous traffic routed none.
+func Test_rollbackService(t *testing.T) {
+	latest, ninety, ten := true, int64(90), int64(10)
+	service := &v1.Service{}
+	service.Spec.Traffic = []v1.TrafficTarget{
+		{LatestRevision: &latest, Percent: &ninety, Tag: "latest"},
+		{RevisionName: "f-00002", Percent: &ten},
+	}
+	got, err := rollbackService("f-00001")(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tt := got.Spec.Traffic
+	if len(tt) != 2 {
+		t.Fatalf("expected the revision and the tagged target, got %v", tt)
+	}
+	if tt[0].RevisionName != "f-00001" || *tt[0].Percent != 100 {
+		t.Errorf("expected all traffic to f-00001, got %v", tt[0])
+	}
+	if tt[1].Tag != "latest" || *tt[1].Percent != 0 {
+		t.Errorf("expected the tag latest retained routed no traffic, got %v", tt[1])
+	}
+}
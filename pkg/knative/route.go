@@ -0,0 +1,74 @@
+package knative
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"knative.dev/serving/pkg/apis/serving"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// disableRouteAnnotation of a Knative service, with which OpenShift
+// Serverless creates no Route to it.
+const disableRouteAnnotation = "serving.knative.openshift.io/disableRoute"
+
+// WithDeployerOpenShift makes the deployer OpenShift-aware: when the cluster
+// is OpenShift, as reported by the given function, the function's route
+// annotations are given to its Route, and a visibility of "none" disables the
+// Route.  These are otherwise ignored, with a warning.
+func WithDeployerOpenShift(isOpenShift func() bool) DeployerOpt {
+	return func(d *Deployer) {
+		d.isOpenShift = isOpenShift
+	}
+}
+
+// openShift reports whether the cluster is OpenShift, if the deployer is
+// OpenShift-aware.
+func (d *Deployer) openShift() bool {
+	return d.isOpenShift != nil && d.isOpenShift()
+}
+
+// withRoute returns the function with the settings of its route given as the
+// labels and annotations of its service: those of the visibility, and on
+// OpenShift those of the Route, which Serverless propagates to it.
+func (d *Deployer) withRoute(f fn.Function) fn.Function {
+	r := f.Deploy.Route
+	if r == nil {
+		return f
+	}
+	if r.Visibility == fn.RouteVisibilityClusterLocal {
+		key, value := visibilityLabel, serving.VisibilityClusterLocal
+		f.Deploy.Labels = append(slices.Clone(f.Deploy.Labels), fn.Label{Key: &key, Value: &value, Scope: fn.ScopeService})
+	}
+	if !d.openShift() {
+		return f
+	}
+	aa := maps.Clone(f.Deploy.ServiceAnnotations)
+	if aa == nil {
+		aa = map[string]string{}
+	}
+	maps.Copy(aa, r.Annotations)
+	if r.Visibility == fn.RouteVisibilityNone {
+		aa[disableRouteAnnotation] = "true"
+	}
+	f.Deploy.ServiceAnnotations = aa
+	return f
+}
+
+// warnRoute of the settings of the function's route which only OpenShift
+// applies, when not deploying to OpenShift.
+func (d *Deployer) warnRoute(w io.Writer, f fn.Function) {
+	r := f.Deploy.Route
+	if r == nil || d.openShift() {
+		return
+	}
+	if len(r.Annotations) > 0 {
+		fmt.Fprintf(w, "Warning: route annotations apply only to OpenShift Routes; they are ignored by this cluster\n")
+	}
+	if r.Visibility == fn.RouteVisibilityNone {
+		fmt.Fprintf(w, "Warning: route visibility %q applies only to OpenShift; the function is routed as %q\n", fn.RouteVisibilityNone, fn.RouteVisibilityPublic)
+	}
+}
@@ -0,0 +1,95 @@
+package knative
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_withRoute ensures that the route of a function is rendered as the
+// labels and annotations of its service, those of its OpenShift Route only
+// by an OpenShift-aware deployer on OpenShift.
+func Test_withRoute(t *testing.T) {
+	timeout := "haproxy.router.openshift.io/timeout"
+	tests := []struct {
+		name        string
+		route       *fn.RouteSpec
+		openShift   bool
+		labels      map[string]string // of the service, expected
+		annotations map[string]string // of the service, expected
+	}{
+		{
+			name: "no route",
+		},
+		{
+			name:   "cluster-local",
+			route:  &fn.RouteSpec{Visibility: fn.RouteVisibilityClusterLocal},
+			labels: map[string]string{visibilityLabel: "cluster-local"},
+		},
+		{
+			name:        "openshift route annotations",
+			route:       &fn.RouteSpec{Annotations: map[string]string{timeout: "600s"}},
+			openShift:   true,
+			annotations: map[string]string{timeout: "600s"},
+		},
+		{
+			name:        "openshift route disabled",
+			route:       &fn.RouteSpec{Visibility: fn.RouteVisibilityNone},
+			openShift:   true,
+			annotations: map[string]string{disableRouteAnnotation: "true"},
+		},
+		{
+			name:  "route annotations ignored off openshift",
+			route: &fn.RouteSpec{Visibility: fn.RouteVisibilityNone, Annotations: map[string]string{timeout: "600s"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDeployer(WithDeployerOpenShift(func() bool { return tt.openShift }))
+			f := fn.Function{Name: "myfn", Runtime: "go", Deploy: fn.DeploySpec{Image: "example.com/myfn:latest", Route: tt.route}}
+			service, err := generateNewService(d.withRoute(f), nil, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, k := range []string{visibilityLabel} {
+				if service.Labels[k] != tt.labels[k] {
+					t.Errorf("expected label %v %q, got %q", k, tt.labels[k], service.Labels[k])
+				}
+				if _, ok := service.Spec.Template.Labels[k]; ok {
+					t.Errorf("expected label %v only of the service", k)
+				}
+			}
+			for _, k := range []string{timeout, disableRouteAnnotation} {
+				if service.Annotations[k] != tt.annotations[k] {
+					t.Errorf("expected annotation %v %q, got %q", k, tt.annotations[k], service.Annotations[k])
+				}
+				if _, ok := service.Spec.Template.Annotations[k]; ok {
+					t.Errorf("expected annotation %v only of the service", k)
+				}
+			}
+			if f.Deploy.ServiceAnnotations != nil || len(f.Deploy.Labels) != 0 {
+				t.Error("expected the function itself unchanged")
+			}
+		})
+	}
+}
+
+// Test_warnRoute ensures the settings of a route which only OpenShift
+// applies are warned of elsewhere.
+func Test_warnRoute(t *testing.T) {
+	f := fn.Function{Deploy: fn.DeploySpec{Route: &fn.RouteSpec{Annotations: map[string]string{"a": "b"}}}}
+
+	var b bytes.Buffer
+	NewDeployer().warnRoute(&b, f)
+	if !strings.Contains(b.String(), "route annotations apply only to OpenShift") {
+		t.Errorf("expected a warning, got %q", b.String())
+	}
+
+	b.Reset()
+	NewDeployer(WithDeployerOpenShift(func() bool { return true })).warnRoute(&b, f)
+	if b.Len() != 0 {
+		t.Errorf("expected no warning on OpenShift, got %q", b.String())
+	}
+}
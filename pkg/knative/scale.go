@@ -0,0 +1,69 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	"knative.dev/client/pkg/wait"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// Scale sets the scale bounds of the deployed Knative service, leaving the
+// rest of the service as deployed.  A nil bound is left as it is, and a
+// maximum of zero is unbounded.  The service's new revision is awaited.
+func Scale(ctx context.Context, name, namespace string, min, max *int64) error {
+	client, err := NewServingClient(namespace)
+	if err != nil {
+		return err
+	}
+	if _, err = client.UpdateServiceWithRetry(ctx, name, scaleService(min, max), 3); err != nil {
+		return fmt.Errorf("cannot scale %v: %w", name, err)
+	}
+	err, _ = client.WaitForService(ctx, name,
+		clientservingv1.WaitConfig{Timeout: DefaultWaitingTimeout, ErrorWindow: DefaultErrorWindowTimeout},
+		wait.NoopMessageCallback())
+	return err
+}
+
+// scaleService returns an update of a service's scale bounds, verifying
+// they remain consistent with any bound not being updated.
+func scaleService(min, max *int64) func(*v1.Service) (*v1.Service, error) {
+	return func(service *v1.Service) (*v1.Service, error) {
+		annotations := service.Spec.Template.Annotations
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		newMin, newMax := min, max
+		if newMin == nil {
+			newMin = scaleAnnotation(annotations, autoscaling.MinScaleAnnotationKey)
+		}
+		if newMax == nil {
+			newMax = scaleAnnotation(annotations, autoscaling.MaxScaleAnnotationKey)
+		}
+		if newMin != nil && newMax != nil && *newMax > 0 && *newMin > *newMax {
+			return nil, fmt.Errorf("minimum scale %v may not exceed the maximum scale %v", *newMin, *newMax)
+		}
+		if min != nil {
+			annotations[autoscaling.MinScaleAnnotationKey] = strconv.FormatInt(*min, 10)
+		}
+		if max != nil {
+			annotations[autoscaling.MaxScaleAnnotationKey] = strconv.FormatInt(*max, 10)
+		}
+		service.Spec.Template.Annotations = annotations
+		// A new revision is created, of a generated name.
+		service.Spec.Template.Name = ""
+		return service, nil
+	}
+}
+
+// scaleAnnotation of the template, nil if not set or not a number.
+func scaleAnnotation(annotations map[string]string, key string) *int64 {
+	v, err := strconv.ParseInt(annotations[key], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,49 @@
+package knative
+
+import (
+	"testing"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+func Test_scaleService(t *testing.T) {
+	i := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		min, max    *int64
+		wantMin     string
+		wantMax     string
+		wantErr     bool
+	}{
+		{"both", nil, i(1), i(10), "1", "10", false},
+		{"min only", map[string]string{autoscaling.MaxScaleAnnotationKey: "5"}, i(2), nil, "2", "5", false},
+		{"max only", map[string]string{autoscaling.MinScaleAnnotationKey: "1"}, nil, i(3), "1", "3", false},
+		{"unbounded max", map[string]string{autoscaling.MaxScaleAnnotationKey: "5"}, i(7), i(0), "7", "0", false},
+		{"min above max", nil, i(5), i(2), "", "", true},
+		{"min above existing max", map[string]string{autoscaling.MaxScaleAnnotationKey: "5"}, i(6), nil, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &v1.Service{}
+			service.Spec.Template.Name = "myfn-00001"
+			service.Spec.Template.Annotations = tt.annotations
+			got, err := scaleService(tt.min, tt.max)(service)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scaleService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			a := got.Spec.Template.Annotations
+			if a[autoscaling.MinScaleAnnotationKey] != tt.wantMin || a[autoscaling.MaxScaleAnnotationKey] != tt.wantMax {
+				t.Errorf("got min %q max %q, want min %q max %q", a[autoscaling.MinScaleAnnotationKey],
+					a[autoscaling.MaxScaleAnnotationKey], tt.wantMin, tt.wantMax)
+			}
+			if got.Spec.Template.Name != "" {
+				t.Errorf("expected the revision name to be generated, got %q", got.Spec.Template.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// The config-features ConfigMap of Knative Serving, of its feature flags.
+const (
+	servingNamespace  = "knative-serving"
+	featuresConfigMap = "config-features"
+)
+
+// schedulingFeatures of the function: the Knative feature flags which its
+// scheduling requires, each disabled by default.
+func schedulingFeatures(s *fn.SchedulingSpec) (flags []string) {
+	if s == nil {
+		return
+	}
+	if len(s.NodeSelector) > 0 {
+		flags = append(flags, "kubernetes.podspec-nodeselector")
+	}
+	if len(s.Tolerations) > 0 {
+		flags = append(flags, "kubernetes.podspec-tolerations")
+	}
+	if s.Affinity != nil && s.Affinity.NodeAffinity != nil {
+		flags = append(flags, "kubernetes.podspec-affinity")
+	}
+	return
+}
+
+// checkFeatures of the cluster, returning an error naming those feature flags
+// which the function requires but which are not enabled, rather than the
+// less helpful error of the service being rejected.  The features of
+// clusters which can not be read, such as for lack of permission, are not
+// checked.
+func checkFeatures(ctx context.Context, client kubernetes.Interface, f fn.Function) error {
+	flags := schedulingFeatures(f.Deploy.Scheduling)
+	if len(flags) == 0 {
+		return nil
+	}
+	cm, err := client.CoreV1().ConfigMaps(servingNamespace).Get(ctx, featuresConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	var disabled []string
+	for _, flag := range flags {
+		if v := cm.Data[flag]; v != "enabled" && v != "allowed" {
+			disabled = append(disabled, flag)
+		}
+	}
+	if len(disabled) > 0 {
+		return fmt.Errorf("the scheduling of the function requires the Knative feature flags %v, which are not enabled in the %v/%v ConfigMap: %v",
+			strings.Join(disabled, ", "), servingNamespace, featuresConfigMap, "https://knative.dev/docs/serving/configuration/feature-flags/")
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package knative
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_checkFeatures ensures that a function scheduled by features which are
+// not enabled is an error naming them, and that the features of a cluster
+// which can not be read are not checked.
+func Test_checkFeatures(t *testing.T) {
+	f := fn.Function{Deploy: fn.DeploySpec{Scheduling: &fn.SchedulingSpec{
+		NodeSelector: map[string]string{"gpu": "true"},
+		Tolerations:  []fn.Toleration{{Key: "gpu", Operator: "Exists"}},
+	}}}
+	features := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: featuresConfigMap, Namespace: servingNamespace}, Data: data}
+	}
+
+	err := checkFeatures(context.Background(), fake.NewSimpleClientset(features(map[string]string{
+		"kubernetes.podspec-nodeselector": "enabled",
+	})), f)
+	if err == nil || !strings.Contains(err.Error(), "kubernetes.podspec-tolerations") || strings.Contains(err.Error(), "nodeselector") {
+		t.Fatalf("expected an error of only the tolerations not enabled, got %v", err)
+	}
+
+	if err = checkFeatures(context.Background(), fake.NewSimpleClientset(features(map[string]string{
+		"kubernetes.podspec-nodeselector": "enabled",
+		"kubernetes.podspec-tolerations":  "allowed",
+	})), f); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = checkFeatures(context.Background(), fake.NewSimpleClientset(), f); err != nil {
+		t.Fatalf("expected the features of a cluster unread not checked, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package knative
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	clientservingv1 "knative.dev/client/pkg/serving/v1"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// waitInterval at which the service is polled while waiting.
+const waitInterval = time.Second
+
+// waitOptions of a deployment, as provided via the context.
+type waitOptions struct {
+	wait    fn.Wait
+	timeout time.Duration
+	events  func(fn.DeployEvent)
+}
+
+// waitOptionsFrom the context, defaulting to waiting for the revision to be
+// ready for at most DefaultWaitingTimeout, reporting progress to none.
+func waitOptionsFrom(ctx context.Context) waitOptions {
+	o := waitOptions{wait: fn.WaitReady, timeout: DefaultWaitingTimeout, events: func(fn.DeployEvent) {}}
+	if w, ok := ctx.Value(fn.DeployWaitKey{}).(fn.Wait); ok && w != "" {
+		o.wait = w
+	}
+	if t, ok := ctx.Value(fn.DeployWaitTimeoutKey{}).(time.Duration); ok && t > 0 {
+		o.timeout = t
+	}
+	if e, ok := ctx.Value(fn.DeployEventsKey{}).(func(fn.DeployEvent)); ok && e != nil {
+		o.events = e
+	}
+	return o
+}
+
+// waitForService of the given name until the condition of the options is
+// met, reporting each stage of its progress as it is reached.  A revision
+// which fails for longer than the DefaultErrorWindowTimeout is an error, as
+// is not meeting the condition within the timeout.
+func waitForService(ctx context.Context, client clientservingv1.KnServingClient, name string, o waitOptions) error {
+	if o.wait == fn.WaitNone {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+
+	p := progress{start: time.Now(), wait: o.wait}
+	var failing time.Time
+	ticker := time.NewTicker(waitInterval)
+	defer ticker.Stop()
+	for {
+		service, err := client.GetService(ctx, name)
+		if err != nil && !k8serrors.IsNotFound(err) && ctx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			var revision *v1.Revision
+			if r := service.Status.LatestCreatedRevisionName; r != "" {
+				revision, _ = client.GetRevision(ctx, r)
+			}
+			done, failure := p.observe(service, revision, o.events)
+			if done {
+				return nil
+			}
+			switch {
+			case failure == nil:
+				failing = time.Time{}
+			case failing.IsZero():
+				failing = time.Now()
+			case time.Since(failing) > DefaultErrorWindowTimeout:
+				return failure
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout after %v waiting for %v to be %v", o.timeout, name, o.wait)
+		case <-ticker.C:
+		}
+	}
+}
+
+// progress of a deployment, being the stages reached thus far.
+type progress struct {
+	start   time.Time
+	wait    fn.Wait
+	reached []fn.DeployStage
+}
+
+// observe the service and its latest created revision, reporting each stage
+// newly reached, in order.  Returned is whether the condition of the wait is
+// met or, if not, the failure of the revision or service, if any.
+func (p *progress) observe(service *v1.Service, revision *v1.Revision, events func(fn.DeployEvent)) (done bool, failure error) {
+	if service.Status.ObservedGeneration != service.Generation {
+		return false, nil // not yet reconciled
+	}
+	name := service.Status.LatestCreatedRevisionName
+	if name == "" {
+		return false, nil
+	}
+	isReady := service.Status.LatestReadyRevisionName == name &&
+		service.Status.GetCondition(v1.ServiceConditionConfigurationsReady).IsTrue()
+	isPulled := isReady || (revision != nil && revision.Name == name &&
+		revision.Status.GetCondition(v1.RevisionConditionResourcesAvailable).IsTrue())
+	isRouted := isReady && service.Status.GetCondition(v1.ServiceConditionReady).IsTrue()
+
+	stages := []struct {
+		stage fn.DeployStage
+		ok    bool
+	}{
+		{fn.StageRevisionCreated, true},
+		{fn.StageImagePulled, isPulled},
+		{fn.StageReady, isReady},
+		{fn.StageRouted, isRouted},
+	}
+	for i, s := range stages {
+		if !s.ok {
+			break
+		}
+		if i < len(p.reached) {
+			continue
+		}
+		p.reached = append(p.reached, s.stage)
+		events(fn.DeployEvent{Stage: s.stage, Revision: name, Elapsed: time.Since(p.start)})
+	}
+
+	if (p.wait == fn.WaitReady && isReady) || (p.wait == fn.WaitTrafficShifted && isRouted) {
+		return true, nil
+	}
+	if revision != nil && revision.Name == name {
+		if c := revision.Status.GetCondition(v1.RevisionConditionReady); c.IsFalse() {
+			return false, fmt.Errorf("revision %v failed: %v", name, c.Message)
+		}
+	}
+	if c := service.Status.GetCondition(v1.ServiceConditionReady); c.IsFalse() {
+		return false, fmt.Errorf("%v failed: %v", service.Name, c.Message)
+	}
+	return false, nil
+}
+
+// getRoute of the service of the given name.  A route not yet created, as
+// may be the case when not waiting, is returned empty.
+func getRoute(ctx context.Context, client clientservingv1.KnServingClient, name string, o waitOptions) (*v1.Route, error) {
+	route, err := client.GetRoute(ctx, name)
+	if k8serrors.IsNotFound(err) && o.wait == fn.WaitNone {
+		return &v1.Route{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("knative deployer failed to get the Route: %v", err)
+	}
+	return route, nil
+}
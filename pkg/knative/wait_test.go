@@ -0,0 +1,81 @@
+package knative
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	v1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Test_progress ensures that each stage of a deployment is reported once, in
+// order, as it is reached, and that the wait is done upon its condition.
+func Test_progress(t *testing.T) {
+	service := &v1.Service{}
+	service.Generation = 2
+	service.Status.ObservedGeneration = 1
+	revision := &v1.Revision{}
+	revision.Name = "f-00002"
+
+	var stages []fn.DeployStage
+	events := func(e fn.DeployEvent) {
+		if e.Revision != "f-00002" {
+			t.Errorf("expected events of f-00002, got %v", e.Revision)
+		}
+		stages = append(stages, e.Stage)
+	}
+	p := progress{wait: fn.WaitTrafficShifted}
+
+	// Not yet reconciled
+	if done, _ := p.observe(service, nil, events); done || len(stages) != 0 {
+		t.Fatalf("expected no progress of a service not yet reconciled, got %v", stages)
+	}
+
+	// Revision created and its image pulled
+	service.Status.ObservedGeneration = 2
+	service.Status.LatestCreatedRevisionName = "f-00002"
+	revision.Status.SetConditions(apis.Conditions{{Type: v1.RevisionConditionResourcesAvailable, Status: corev1.ConditionTrue}})
+	if done, _ := p.observe(service, revision, events); done {
+		t.Fatal("expected not done before ready")
+	}
+
+	// Ready, but not yet routed
+	service.Status.LatestReadyRevisionName = "f-00002"
+	service.Status.SetConditions(apis.Conditions{{Type: v1.ServiceConditionConfigurationsReady, Status: corev1.ConditionTrue}})
+	if done, _ := p.observe(service, revision, events); done {
+		t.Fatal("expected not done before routed")
+	}
+	if done, _ := (&progress{wait: fn.WaitReady}).observe(service, revision, func(fn.DeployEvent) {}); !done {
+		t.Fatal("expected waiting for ready done once ready")
+	}
+
+	// Routed
+	service.Status.SetConditions(apis.Conditions{
+		{Type: v1.ServiceConditionConfigurationsReady, Status: corev1.ConditionTrue},
+		{Type: v1.ServiceConditionReady, Status: corev1.ConditionTrue},
+	})
+	if done, _ := p.observe(service, revision, events); !done {
+		t.Fatal("expected done once routed")
+	}
+	expected := []fn.DeployStage{fn.StageRevisionCreated, fn.StageImagePulled, fn.StageReady, fn.StageRouted}
+	if !reflect.DeepEqual(stages, expected) {
+		t.Fatalf("expected stages %v, got %v", expected, stages)
+	}
+}
+
+// Test_progressFailure ensures that a failed revision is reported.
+func Test_progressFailure(t *testing.T) {
+	service := &v1.Service{}
+	service.Status.LatestCreatedRevisionName = "f-00001"
+	revision := &v1.Revision{}
+	revision.Name = "f-00001"
+	revision.Status.SetConditions(apis.Conditions{{Type: v1.RevisionConditionReady, Status: corev1.ConditionFalse, Message: "image not found"}})
+
+	p := progress{wait: fn.WaitReady}
+	if _, err := p.observe(service, revision, func(fn.DeployEvent) {}); err == nil {
+		t.Fatal("expected the failure of the revision")
+	}
+}
@@ -57,6 +57,7 @@ type DeployInput struct {
 	BuildTimestamp     *bool   `json:"buildTimestamp,omitempty" jsonschema:"Use actual time in image metadata"`
 	Remote             *bool   `json:"remote,omitempty" jsonschema:"Trigger remote deployment"`
 	Verbose            *bool   `json:"verbose,omitempty" jsonschema:"Enable verbose logging output"`
+	Yes                *bool   `json:"yes,omitempty" jsonschema:"Confirm the first deployment to a cluster and namespace, which is otherwise refused"`
 }
 
 func (i DeployInput) Args() []string {
@@ -82,6 +83,7 @@ func (i DeployInput) Args() []string {
 	args = appendBoolFlag(args, "--build-timestamp", i.BuildTimestamp)
 	args = appendBoolFlag(args, "--remote", i.Remote)
 	args = appendBoolFlag(args, "--verbose", i.Verbose)
+	args = appendBoolFlag(args, "--yes", i.Yes)
 
 	return args
 }
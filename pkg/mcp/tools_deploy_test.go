@@ -39,6 +39,7 @@ func TestTool_Deploy_Args(t *testing.T) {
 		"buildTimestamp":   "--build-timestamp",
 		"remote":           "--remote",
 		"verbose":          "--verbose",
+		"yes":              "--yes",
 	}
 
 	executor := mock.NewExecutor()
@@ -0,0 +1,211 @@
+// Package metrics queries the metrics of deployed functions' invocations from
+// Prometheus, as recorded of each request by the queue proxy of Knative
+// Serving.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+)
+
+// Location of a Prometheus service on the cluster.
+type Location struct {
+	Namespace string
+	Service   string
+	Port      string
+}
+
+// DefaultLocations at which Prometheus is looked for on the cluster, in
+// order, being those at which it is installed by kube-prometheus, the
+// kube-prometheus-stack and Prometheus charts, and formerly by Knative.
+var DefaultLocations = []Location{
+	{Namespace: "monitoring", Service: "prometheus-k8s", Port: "9090"},
+	{Namespace: "monitoring", Service: "prometheus-operated", Port: "9090"},
+	{Namespace: "observability", Service: "prometheus-kube-prometheus-prometheus", Port: "9090"},
+	{Namespace: "monitoring", Service: "prometheus-kube-prometheus-prometheus", Port: "9090"},
+	{Namespace: "prometheus", Service: "prometheus-server", Port: "80"},
+	{Namespace: "knative-monitoring", Service: "prometheus-system-np", Port: "8080"},
+}
+
+// Provider of the metrics of functions' invocations.
+type Provider struct {
+	url       string
+	locations []Location
+	client    *http.Client
+	verbose   bool
+}
+
+type Option func(*Provider)
+
+// WithURL of Prometheus, such as that of a port forward, rather than one of
+// the locations at which it is looked for on the cluster.
+func WithURL(url string) Option {
+	return func(p *Provider) {
+		p.url = url
+	}
+}
+
+// WithLocations at which Prometheus is looked for on the cluster, by default
+// DefaultLocations.
+func WithLocations(ll []Location) Option {
+	return func(p *Provider) {
+		p.locations = ll
+	}
+}
+
+func WithVerbose(verbose bool) Option {
+	return func(p *Provider) {
+		p.verbose = verbose
+	}
+}
+
+func NewProvider(opts ...Option) *Provider {
+	p := &Provider{
+		locations: DefaultLocations,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// querier executes a PromQL query, returning the response of the Prometheus
+// HTTP API.
+type querier func(ctx context.Context, query string) ([]byte, error)
+
+// Metrics of the function's invocations over the window until now.  Returns
+// fn.ErrMetricsNotAvailable if Prometheus is not found on the cluster.
+func (p *Provider) Metrics(ctx context.Context, name, namespace string, window time.Duration) (m fn.Metrics, err error) {
+	q, err := p.querier(ctx)
+	if err != nil {
+		return
+	}
+	selector := fmt.Sprintf(`namespace_name=%q,service_name=%q`, namespace, name)
+	r := fmt.Sprintf("%ds", int64(window.Seconds()))
+
+	m.Window = window
+	if m.Requests, err = scalar(ctx, q, fmt.Sprintf(`sum(increase(revision_request_count{%v}[%v]))`, selector, r)); err != nil {
+		return
+	}
+	if m.Requests > 0 {
+		var failed float64
+		if failed, err = scalar(ctx, q, fmt.Sprintf(`sum(increase(revision_request_count{%v,response_code_class="5xx"}[%v]))`, selector, r)); err != nil {
+			return
+		}
+		m.ErrorRate = failed / m.Requests
+	}
+	// Latencies are recorded in milliseconds.
+	ms, err := scalar(ctx, q, fmt.Sprintf(`histogram_quantile(0.95, sum by (le) (rate(revision_request_latencies_bucket{%v}[%v])))`, selector, r))
+	if err != nil {
+		return
+	}
+	m.LatencyP95 = time.Duration(ms * float64(time.Millisecond))
+	return
+}
+
+// querier of the Prometheus at the URL if given, or else of that found at
+// the first of the locations on the cluster which responds, via the proxy of
+// the Kubernetes API server.
+func (p *Provider) querier(ctx context.Context) (querier, error) {
+	if p.url != "" {
+		return p.urlQuerier(p.url), nil
+	}
+	client, err := k8s.NewKubernetesClientset()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", fn.ErrMetricsNotAvailable, err)
+	}
+	for _, l := range p.locations {
+		q := proxyQuerier(client, l)
+		if _, err := scalar(ctx, q, "vector(1)"); err != nil {
+			if p.verbose {
+				fmt.Fprintf(os.Stderr, "Prometheus not found at %v/%v:%v: %v\n", l.Namespace, l.Service, l.Port, err)
+			}
+			continue
+		}
+		return q, nil
+	}
+	return nil, fn.ErrMetricsNotAvailable
+}
+
+func (p *Provider) urlQuerier(base string) querier {
+	return func(ctx context.Context, query string) ([]byte, error) {
+		u := strings.TrimSuffix(base, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+		bb, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK && len(bb) == 0 {
+			return nil, fmt.Errorf("query failed: %v", res.Status)
+		}
+		return bb, nil
+	}
+}
+
+func proxyQuerier(client kubernetes.Interface, l Location) querier {
+	return func(ctx context.Context, query string) ([]byte, error) {
+		return client.CoreV1().Services(l.Namespace).
+			ProxyGet("http", l.Service, l.Port, "api/v1/query", map[string]string{"query": query}).
+			DoRaw(ctx)
+	}
+}
+
+// response of the Prometheus HTTP API to an instant query.
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []any `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// scalar value of the query, being that of the first sample of its result,
+// or zero if it has none or is not a number.
+func scalar(ctx context.Context, q querier, query string) (float64, error) {
+	bb, err := q(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	var r response
+	if err = json.Unmarshal(bb, &r); err != nil {
+		return 0, fmt.Errorf("unexpected response of Prometheus: %w", err)
+	}
+	if r.Status != "success" {
+		return 0, fmt.Errorf("query failed: %v", r.Error)
+	}
+	if len(r.Data.Result) == 0 || len(r.Data.Result[0].Value) != 2 {
+		return 0, nil
+	}
+	s, _ := r.Data.Result[0].Value[1].(string)
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, nil
+	}
+	return v, nil
+}
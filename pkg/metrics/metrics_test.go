@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProvider_Metrics ensures that the requests, error rate and latency of a
+// function are those queried of Prometheus for its service.
+func TestProvider_Metrics(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("query")
+		queries = append(queries, q)
+		value := "NaN"
+		switch {
+		case strings.Contains(q, `response_code_class="5xx"`):
+			value = "5"
+		case strings.Contains(q, "revision_request_count"):
+			value = "200"
+		case strings.Contains(q, "histogram_quantile"):
+			value = "123.4"
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,%q]}]}}`, value)
+	}))
+	defer server.Close()
+
+	m, err := NewProvider(WithURL(server.URL)).Metrics(context.Background(), "myfunc", "ns", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Window != time.Hour || m.Requests != 200 || m.ErrorRate != 0.025 || m.LatencyP95 != 123400*time.Microsecond {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+	for _, q := range queries {
+		if !strings.Contains(q, `namespace_name="ns",service_name="myfunc"`) || !strings.Contains(q, "[3600s]") {
+			t.Errorf("expected the query of the function's service over the window, got %v", q)
+		}
+	}
+}
+
+// TestProvider_MetricsNoRequests ensures that a function without requests has
+// no error rate or latency, and that failed queries are errors.
+func TestProvider_MetricsNoRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Query().Get("query"), "invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","error":"parse error"}`)
+			return
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+	}))
+	defer server.Close()
+
+	p := NewProvider(WithURL(server.URL))
+	m, err := p.Metrics(context.Background(), "myfunc", "ns", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Requests != 0 || m.ErrorRate != 0 || m.LatencyP95 != 0 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+	if _, err = scalar(context.Background(), p.urlQuerier(server.URL), "invalid"); err == nil || !strings.Contains(err.Error(), "parse error") {
+		t.Errorf("expected the error of the query, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Attacher struct {
+	AttachInvoked bool
+	AttachFn      func(context.Context, fn.Function) ([]fn.Artifact, error)
+
+	ArtifactsInvoked bool
+	ArtifactsFn      func(context.Context, string) ([]fn.Artifact, error)
+
+	FetchInvoked bool
+	FetchFn      func(context.Context, string, fn.Artifact, io.Writer) error
+}
+
+func NewAttacher() *Attacher {
+	return &Attacher{
+		AttachFn:    func(context.Context, fn.Function) ([]fn.Artifact, error) { return nil, nil },
+		ArtifactsFn: func(context.Context, string) ([]fn.Artifact, error) { return nil, nil },
+		FetchFn:     func(context.Context, string, fn.Artifact, io.Writer) error { return nil },
+	}
+}
+
+func (a *Attacher) Attach(ctx context.Context, f fn.Function) ([]fn.Artifact, error) {
+	a.AttachInvoked = true
+	return a.AttachFn(ctx, f)
+}
+
+func (a *Attacher) Artifacts(ctx context.Context, image string) ([]fn.Artifact, error) {
+	a.ArtifactsInvoked = true
+	return a.ArtifactsFn(ctx, image)
+}
+
+func (a *Attacher) Fetch(ctx context.Context, image string, artifact fn.Artifact, w io.Writer) error {
+	a.FetchInvoked = true
+	return a.FetchFn(ctx, image, artifact, w)
+}
@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// BuildCacher retaining the caches given, which are removed from its list
+// when removed.
+type BuildCacher struct {
+	CachesInvoked bool
+	RemoveInvoked bool
+	Retained      []fn.BuildCache
+	Removed       []fn.BuildCache
+}
+
+func NewBuildCacher(cc ...fn.BuildCache) *BuildCacher {
+	return &BuildCacher{Retained: cc}
+}
+
+func (b *BuildCacher) Caches(context.Context, fn.Function) ([]fn.BuildCache, error) {
+	b.CachesInvoked = true
+	return append([]fn.BuildCache{}, b.Retained...), nil
+}
+
+func (b *BuildCacher) Remove(_ context.Context, c fn.BuildCache) error {
+	b.RemoveInvoked = true
+	for i, r := range b.Retained {
+		if r.Name == c.Name {
+			b.Retained = append(b.Retained[:i], b.Retained[i+1:]...)
+			break
+		}
+	}
+	b.Removed = append(b.Removed, c)
+	return nil
+}
@@ -17,6 +17,8 @@ const DefaultNamespace = "default"
 type Deployer struct {
 	DeployInvoked bool
 	DeployFn      func(context.Context, fn.Function) (fn.DeploymentResult, error)
+	RenderInvoked bool
+	RenderFn      func(context.Context, fn.Function, fn.DryRun) ([]byte, error)
 }
 
 func NewDeployer() *Deployer {
@@ -33,6 +35,7 @@ func NewDeployer() *Deployer {
 			}
 			return
 		},
+		RenderFn: func(context.Context, fn.Function, fn.DryRun) ([]byte, error) { return nil, nil },
 	}
 }
 
@@ -41,11 +44,17 @@ func (i *Deployer) Deploy(ctx context.Context, f fn.Function) (fn.DeploymentResu
 	return i.DeployFn(ctx, f)
 }
 
+func (i *Deployer) Render(ctx context.Context, f fn.Function, mode fn.DryRun) ([]byte, error) {
+	i.RenderInvoked = true
+	return i.RenderFn(ctx, f, mode)
+}
+
 // NewDeployerWithResult is a convenience method for creating a mock deployer
 // with a deploy function implementation which returns the given result
 // and no error.
 func NewDeployerWithResult(result fn.DeploymentResult) *Deployer {
 	return &Deployer{
 		DeployFn: func(context.Context, fn.Function) (fn.DeploymentResult, error) { return result, nil },
+		RenderFn: func(context.Context, fn.Function, fn.DryRun) ([]byte, error) { return nil, nil },
 	}
 }
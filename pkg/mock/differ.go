@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Differ struct {
+	SnapshotsInvoked bool
+	SnapshotsFn      func(context.Context, fn.Function) (fn.Snapshot, fn.Snapshot, error)
+}
+
+func NewDiffer() *Differ {
+	return &Differ{
+		SnapshotsFn: func(context.Context, fn.Function) (fn.Snapshot, fn.Snapshot, error) {
+			return fn.Snapshot{}, fn.Snapshot{}, nil
+		},
+	}
+}
+
+func (d *Differ) Snapshots(ctx context.Context, f fn.Function) (fn.Snapshot, fn.Snapshot, error) {
+	d.SnapshotsInvoked = true
+	return d.SnapshotsFn(ctx, f)
+}
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+)
+
+type DigestResolver struct {
+	DigestInvoked bool
+	DigestFn      func(context.Context, string) (string, error)
+}
+
+func NewDigestResolver() *DigestResolver {
+	return &DigestResolver{
+		DigestFn: func(context.Context, string) (string, error) { return "", nil },
+	}
+}
+
+func (r *DigestResolver) Digest(ctx context.Context, image string) (string, error) {
+	r.DigestInvoked = true
+	return r.DigestFn(ctx, image)
+}
@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type HookRunner struct {
+	RunInvoked bool
+	RunFn      func(context.Context, fn.Function, fn.Hook, []string) error
+}
+
+func NewHookRunner() *HookRunner {
+	return &HookRunner{
+		RunFn: func(context.Context, fn.Function, fn.Hook, []string) error { return nil },
+	}
+}
+
+func (r *HookRunner) Run(ctx context.Context, f fn.Function, h fn.Hook, env []string) error {
+	r.RunInvoked = true
+	return r.RunFn(ctx, f, h, env)
+}
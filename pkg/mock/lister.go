@@ -7,17 +7,48 @@ import (
 )
 
 type Lister struct {
-	ListInvoked bool
-	ListFn      func(context.Context, string) ([]fn.ListItem, error)
+	ListInvoked     bool
+	ListFn          func(context.Context, string, string) ([]fn.ListItem, error)
+	ListPageInvoked bool
+	ListPageFn      func(context.Context, string, string, int64, string) ([]fn.ListItem, string, error)
+	WatchInvoked    bool
+	WatchFn         func(context.Context, string, string) (<-chan []fn.ListItem, error)
 }
 
 func NewLister() *Lister {
-	return &Lister{
-		ListFn: func(context.Context, string) ([]fn.ListItem, error) { return []fn.ListItem{}, nil },
+	l := &Lister{
+		ListFn: func(context.Context, string, string) ([]fn.ListItem, error) { return []fn.ListItem{}, nil },
 	}
+	// By default the list is a single page.
+	l.ListPageFn = func(ctx context.Context, ns, selector string, _ int64, _ string) ([]fn.ListItem, string, error) {
+		items, err := l.ListFn(ctx, ns, selector)
+		return items, "", err
+	}
+	// By default the list is received once, as listed.
+	l.WatchFn = func(ctx context.Context, ns, selector string) (<-chan []fn.ListItem, error) {
+		items, err := l.ListFn(ctx, ns, selector)
+		if err != nil {
+			return nil, err
+		}
+		ch := make(chan []fn.ListItem, 1)
+		ch <- items
+		close(ch)
+		return ch, nil
+	}
+	return l
 }
 
-func (l *Lister) List(ctx context.Context, ns string) ([]fn.ListItem, error) {
+func (l *Lister) List(ctx context.Context, ns, selector string) ([]fn.ListItem, error) {
 	l.ListInvoked = true
-	return l.ListFn(ctx, ns)
+	return l.ListFn(ctx, ns, selector)
+}
+
+func (l *Lister) ListPage(ctx context.Context, ns, selector string, limit int64, token string) ([]fn.ListItem, string, error) {
+	l.ListPageInvoked = true
+	return l.ListPageFn(ctx, ns, selector, limit, token)
+}
+
+func (l *Lister) Watch(ctx context.Context, ns, selector string) (<-chan []fn.ListItem, error) {
+	l.WatchInvoked = true
+	return l.WatchFn(ctx, ns, selector)
 }
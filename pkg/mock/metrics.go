@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type MetricsProvider struct {
+	MetricsInvoked bool
+	MetricsFn      func(context.Context, string, string, time.Duration) (fn.Metrics, error)
+}
+
+func NewMetricsProvider() *MetricsProvider {
+	return &MetricsProvider{
+		MetricsFn: func(context.Context, string, string, time.Duration) (fn.Metrics, error) {
+			return fn.Metrics{}, fn.ErrMetricsNotAvailable
+		},
+	}
+}
+
+func (p *MetricsProvider) Metrics(ctx context.Context, name, namespace string, window time.Duration) (fn.Metrics, error) {
+	p.MetricsInvoked = true
+	return p.MetricsFn(ctx, name, namespace, window)
+}
@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+)
+
+type PolicyEvaluator struct {
+	EvaluateInvoked bool
+	EvaluateFn      func(context.Context, ...map[string]any) error
+}
+
+func NewPolicyEvaluator() *PolicyEvaluator {
+	return &PolicyEvaluator{
+		EvaluateFn: func(context.Context, ...map[string]any) error { return nil },
+	}
+}
+
+func (p *PolicyEvaluator) Evaluate(ctx context.Context, manifests ...map[string]any) error {
+	p.EvaluateInvoked = true
+	return p.EvaluateFn(ctx, manifests...)
+}
@@ -0,0 +1,24 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type SBOMGenerator struct {
+	GenerateInvoked bool
+	GenerateFn      func(context.Context, fn.Function, io.Writer) error
+}
+
+func NewSBOMGenerator() *SBOMGenerator {
+	return &SBOMGenerator{
+		GenerateFn: func(context.Context, fn.Function, io.Writer) error { return nil },
+	}
+}
+
+func (g *SBOMGenerator) Generate(ctx context.Context, f fn.Function, w io.Writer) error {
+	g.GenerateInvoked = true
+	return g.GenerateFn(ctx, f, w)
+}
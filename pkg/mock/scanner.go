@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Scanner struct {
+	ScanInvoked bool
+	ScanFn      func(context.Context, fn.Function, string) (fn.ScanReport, error)
+}
+
+func NewScanner() *Scanner {
+	return &Scanner{
+		ScanFn: func(context.Context, fn.Function, string) (fn.ScanReport, error) { return fn.ScanReport{}, nil },
+	}
+}
+
+func (s *Scanner) Scan(ctx context.Context, f fn.Function, path string) (fn.ScanReport, error) {
+	s.ScanInvoked = true
+	return s.ScanFn(ctx, f, path)
+}
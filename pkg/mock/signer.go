@@ -0,0 +1,23 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+type Signer struct {
+	SignInvoked bool
+	SignFn      func(context.Context, fn.Function) (string, error)
+}
+
+func NewSigner() *Signer {
+	return &Signer{
+		SignFn: func(context.Context, fn.Function) (string, error) { return "", nil },
+	}
+}
+
+func (s *Signer) Sign(ctx context.Context, f fn.Function) (string, error) {
+	s.SignInvoked = true
+	return s.SignFn(ctx, f)
+}
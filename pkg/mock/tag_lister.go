@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+)
+
+type TagLister struct {
+	TagsInvoked bool
+	TagsFn      func(context.Context, string) ([]string, error)
+}
+
+func NewTagLister() *TagLister {
+	return &TagLister{
+		TagsFn: func(context.Context, string) ([]string, error) { return nil, nil },
+	}
+}
+
+func (l *TagLister) Tags(ctx context.Context, repository string) ([]string, error) {
+	l.TagsInvoked = true
+	return l.TagsFn(ctx, repository)
+}
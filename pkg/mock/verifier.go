@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// VerifiedDigest is the digest of the image verified by default.
+const VerifiedDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+type Verifier struct {
+	VerifyInvoked bool
+	VerifyFn      func(context.Context, fn.Function) (string, error)
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{
+		VerifyFn: func(context.Context, fn.Function) (string, error) { return VerifiedDigest, nil },
+	}
+}
+
+func (v *Verifier) Verify(ctx context.Context, f fn.Function) (string, error) {
+	v.VerifyInvoked = true
+	return v.VerifyFn(ctx, f)
+}
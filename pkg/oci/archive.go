@@ -0,0 +1,139 @@
+package oci
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// ArchiveFormat of a file to which an image is written by WriteArchive.
+type ArchiveFormat string
+
+const (
+	// ArchiveOCI is an OCI image layout as a tar, of an image or an index of
+	// the images of several platforms.
+	ArchiveOCI ArchiveFormat = "oci"
+	// ArchiveDocker is a tar as written by "docker save", of the image of a
+	// single platform, which is loaded with "docker load".
+	ArchiveDocker ArchiveFormat = "docker-archive"
+)
+
+// ParseArchive of the form <format>:<path>, for example oci:./function.tar.
+func ParseArchive(s string) (format ArchiveFormat, path string, err error) {
+	f, path, ok := strings.Cut(s, ":")
+	format = ArchiveFormat(f)
+	if !ok || path == "" {
+		return "", "", fmt.Errorf("invalid archive %q. Expected <format>:<path>, for example oci:./function.tar", s)
+	}
+	if format != ArchiveOCI && format != ArchiveDocker {
+		return "", "", fmt.Errorf("invalid archive format %q. Expected %v or %v", f, ArchiveOCI, ArchiveDocker)
+	}
+	return
+}
+
+// LastBuildIndex is the index of the image written by the function's last
+// build as an OCI layout, by the host and buildkit builders.
+func LastBuildIndex(f fn.Function) (v1.ImageIndex, error) {
+	dir, err := getLastBuildDir(f)
+	if err != nil {
+		return nil, err
+	}
+	return layout.ImageIndexFromPath(filepath.Join(dir, "oci"))
+}
+
+// WriteArchive of the image or images of the index, named by ref, to path.
+func WriteArchive(path string, format ArchiveFormat, ref name.Reference, ii v1.ImageIndex) error {
+	img, err := singleImage(ii)
+	if err != nil {
+		return err
+	}
+	if format == ArchiveDocker {
+		if img == nil {
+			return fmt.Errorf("a %v holds the image of a single platform. Use %v to write the images of several", ArchiveDocker, ArchiveOCI)
+		}
+		return tarball.WriteToFile(path, ref, img)
+	}
+
+	// The layout is written to a directory and then archived, such that the
+	// archive is written only if the layout is written in full.
+	dir, err := os.MkdirTemp("", "func-oci-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	p, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		return err
+	}
+	annotations := layout.WithAnnotations(map[string]string{"org.opencontainers.image.ref.name": ref.Name()})
+	if img != nil {
+		err = p.AppendImage(img, annotations)
+	} else {
+		err = p.AppendIndex(ii, annotations)
+	}
+	if err != nil {
+		return err
+	}
+	return writeTar(path, dir)
+}
+
+// writeTar of the files of dir to the file at path.
+func writeTar(path, dir string) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	tw := tar.NewWriter(file)
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err = tw.WriteHeader(hdr); err != nil || d.IsDir() {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return
+	}
+	return tw.Close()
+}
@@ -0,0 +1,124 @@
+package oci
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// TestParseArchive ensures archives are of a known format and a path.
+func TestParseArchive(t *testing.T) {
+	tests := []struct {
+		value  string
+		format ArchiveFormat
+		path   string
+		err    bool
+	}{
+		{"oci:./function.tar", ArchiveOCI, "./function.tar", false},
+		{"docker-archive:/tmp/f.tar", ArchiveDocker, "/tmp/f.tar", false},
+		{"function.tar", "", "", true},
+		{"oci:", "", "", true},
+		{"zip:function.zip", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			format, path, err := ParseArchive(tt.value)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if format != tt.format || path != tt.path {
+				t.Errorf("expected %v:%v, got %v:%v", tt.format, tt.path, format, path)
+			}
+		})
+	}
+}
+
+// TestWriteArchive ensures an index is written as an OCI image layout, and
+// that a docker-archive is of the image of a single platform.
+func TestWriteArchive(t *testing.T) {
+	dir := t.TempDir()
+	ref := name.MustParseReference("example.com/alice/f:latest")
+	ii, err := random.Index(64, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "function.tar")
+	if err = WriteArchive(path, ArchiveOCI, ref, ii); err != nil {
+		t.Fatal(err)
+	}
+	extracted := filepath.Join(dir, "layout")
+	untar(t, path, extracted)
+	p, err := layout.ImageIndexFromPath(extracted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := p.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ii.Digest()
+	if len(im.Manifests) != 1 || im.Manifests[0].Digest != want {
+		t.Fatalf("expected the index %v, got %v", want, im.Manifests)
+	}
+	if n := im.Manifests[0].Annotations["org.opencontainers.image.ref.name"]; n != ref.Name() {
+		t.Errorf("expected the index named %v, got %q", ref.Name(), n)
+	}
+
+	if err = WriteArchive(path, ArchiveDocker, ref, ii); err == nil {
+		t.Fatal("expected an error writing several images to a docker-archive")
+	}
+	single, err := random.Index(64, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteArchive(path, ArchiveDocker, ref, single); err != nil {
+		t.Fatal(err)
+	}
+	tag := ref.(name.Tag)
+	if _, err = tarball.ImageFromPath(path, &tag); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func untar(t *testing.T, path, dir string) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		target := filepath.Join(dir, hdr.Name)
+		if hdr.Typeflag == tar.TypeDir {
+			if err = os.MkdirAll(target, 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(target, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
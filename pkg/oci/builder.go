@@ -28,6 +28,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/pkg/errors"
 
+	"knative.dev/func/pkg/filesystem"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/scaffolding"
 )
@@ -37,21 +38,16 @@ const (
 	DefaultGid = 1000
 )
 
-var defaultIgnored = []string{
-	".git",
-	".func",
-	".funcignore",
-	".gitignore",
-}
-
-var builders = map[string]languageBuilder{
-	"go":     goBuilder{},
-	"python": pythonBuilder{},
+var languageBuilders = map[string]languageBuilder{
+	"go":         goBuilder{},
+	"node":       nodeBuilder{},
+	"python":     pythonBuilder{},
+	"typescript": typescriptBuilder{},
 }
 
 // IsSupported is for UX.
 func IsSupported(runtime string) bool {
-	_, ok := builders[runtime]
+	_, ok := languageBuilders[runtime]
 	return ok
 }
 
@@ -96,6 +92,9 @@ func NewBuilder(name string, verbose bool) *Builder {
 //
 // Platforms are optional and default to fn.DefaultPlatforms.
 func (b *Builder) Build(ctx context.Context, f fn.Function, pp []fn.Platform) (err error) {
+	if len(f.Build.Secrets) > 0 {
+		return errors.New("build secrets (build.secrets) are not supported by the host builder. Use the buildkit or pack builder")
+	}
 	if len(pp) == 0 {
 		pp = fn.DefaultPlatforms // Use Default platforms if not provided
 	}
@@ -116,11 +115,12 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, pp []fn.Platform) (e
 	defer func() {
 		// Always remove our own PID link when build completes
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "rm %v\n", job.pidLink())
+			fmt.Fprintf(job.stderr(), "rm %v\n", job.pidLink())
 		}
 		_ = os.Remove(job.pidLink())
 	}()
 
+	fn.ReportDeployEvent(ctx, fn.DeployEvent{Stage: fn.StagePhase, Phase: fn.PhaseBuild})
 	if err = scaffold(job); err != nil { // write out the service wrapper
 		return
 	}
@@ -129,6 +129,7 @@ func (b *Builder) Build(ctx context.Context, f fn.Function, pp []fn.Platform) (e
 		return
 	}
 
+	fn.ReportDeployEvent(ctx, fn.DeployEvent{Stage: fn.StagePhase, Phase: fn.PhaseExport})
 	if err = updateLastLink(job); err != nil { // .func/builds/last
 		return
 	}
@@ -157,14 +158,14 @@ func setup(job buildJob) (err error) {
 	// Build directory
 	if _, err = os.Stat(job.buildDir()); !os.IsNotExist(err) {
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "rm -rf %v\n", job.buildDir())
+			fmt.Fprintf(job.stderr(), "rm -rf %v\n", job.buildDir())
 		}
 		if err = os.RemoveAll(job.buildDir()); err != nil {
 			return
 		}
 	}
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "mkdir -p %v\n", job.buildDir())
+		fmt.Fprintf(job.stderr(), "mkdir -p %v\n", job.buildDir())
 	}
 	if err = os.MkdirAll(job.buildDir(), 0774); err != nil {
 		return
@@ -173,7 +174,7 @@ func setup(job buildJob) (err error) {
 	// PID links directory
 	if _, err = os.Stat(job.pidsDir()); os.IsNotExist(err) {
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "mkdir -p %v\n", job.pidsDir())
+			fmt.Fprintf(job.stderr(), "mkdir -p %v\n", job.pidsDir())
 		}
 		if err = os.MkdirAll(job.pidsDir(), 0774); err != nil {
 			return
@@ -183,7 +184,7 @@ func setup(job buildJob) (err error) {
 	// Link to last build attempted (this)
 	target := filepath.Join("..", "by-hash", job.hash)
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "ln -s %v %v\n", target, job.pidLink())
+		fmt.Fprintf(job.stderr(), "ln -s %v %v\n", target, job.pidLink())
 	}
 	if err = os.Symlink(target, job.pidLink()); err != nil {
 		return err
@@ -219,7 +220,7 @@ func cleanup(job buildJob) {
 		}
 		dir := filepath.Join(job.pidsDir(), d.Name())
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "rm %v\n", dir)
+			fmt.Fprintf(job.stderr(), "rm %v\n", dir)
 		}
 		_ = os.RemoveAll(dir)
 	}
@@ -237,7 +238,7 @@ func cleanup(job buildJob) {
 			continue
 		}
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "rm %v\n", dir)
+			fmt.Fprintf(job.stderr(), "rm %v\n", dir)
 		}
 		_ = os.RemoveAll(dir)
 	}
@@ -252,6 +253,12 @@ func scaffold(job buildJob) (err error) {
 	if err != nil {
 		return
 	}
+	// Node and TypeScript functions are served by their own faas-js-runtime
+	// dependency, so only the certificates written with all scaffolding are
+	// required.
+	if job.function.Runtime == "node" || job.function.Runtime == "typescript" {
+		return filesystem.CopyFromFS("certs", job.buildDir(), repo.FS())
+	}
 	return scaffolding.Write(
 		job.buildDir(),       // desintation for scaffolding
 		job.function.Root,    // source to be scaffolded
@@ -350,6 +357,27 @@ func containerize(job buildJob) error {
 	return writeIndex(job, manifests)
 }
 
+// Context returns the paths, relative to the function's root, of the files
+// which comprise the function's build context as layered by the host builder.
+func Context(f fn.Function) ([]string, error) {
+	ignorer, err := contextIgnorer(f)
+	if err != nil {
+		return nil, err
+	}
+	return ignorer.Context()
+}
+
+// contextIgnorer of the function's data layer.
+func contextIgnorer(f fn.Function) (fn.Ignorer, error) {
+	var patterns []string
+	if f.Runtime == "node" || f.Runtime == "typescript" {
+		// Dependencies are installed by the node and typescript builders into
+		// their own layer
+		patterns = append(patterns, "node_modules")
+	}
+	return fn.NewIgnorer(f.Root, patterns...)
+}
+
 // writeDataLayer creates the shared data layer in the container file hierarchy and
 // returns both its descriptor and layer metadata.
 func writeDataLayer(job buildJob) (layer imageLayer, err error) {
@@ -358,7 +386,12 @@ func writeDataLayer(job buildJob) (layer imageLayer, err error) {
 	source := job.function.Root // The source is the function's entire filesystem
 	target := filepath.Join(job.buildDir(), "datalayer.tar.gz")
 
-	if err = newDataTarball(source, target, defaultIgnored, job.verbose); err != nil {
+	ignorer, err := contextIgnorer(job.function)
+	if err != nil {
+		return
+	}
+
+	if err = newDataTarball(source, target, ignorer, job.verbose, job.stderr()); err != nil {
 		return
 	}
 
@@ -375,13 +408,13 @@ func writeDataLayer(job buildJob) (layer imageLayer, err error) {
 	// Blob
 	blob := filepath.Join(job.blobsDir(), layer.Descriptor.Digest.Hex)
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
+		fmt.Fprintf(job.stderr(), "mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
 	}
 	err = os.Rename(target, blob)
 	return
 }
 
-func newDataTarball(root, target string, ignored []string, verbose bool) error {
+func newDataTarball(root, target string, ignorer fn.Ignorer, verbose bool, out io.Writer) error {
 	targetFile, err := os.Create(target)
 	if err != nil {
 		return err
@@ -394,21 +427,11 @@ func newDataTarball(root, target string, ignored []string, verbose bool) error {
 	tw := tar.NewWriter(gw)
 	defer tw.Close()
 
-	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	return ignorer.Walk(func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip files explicitly ignored
-		for _, v := range ignored {
-			if info.Name() == v {
-				if info.IsDir() {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-		}
-
 		lnk := "" // if link, this will be used as the target
 		if info.Mode()&fs.ModeSymlink != 0 {
 			if lnk, err = validatedLinkTarget(root, path); err != nil {
@@ -433,7 +456,7 @@ func newDataTarball(root, target string, ignored []string, verbose bool) error {
 			return err
 		}
 		if verbose {
-			fmt.Fprintf(os.Stderr, "→ %v \n", header.Name)
+			fmt.Fprintf(out, "→ %v \n", header.Name)
 		}
 		if !info.Mode().IsRegular() { //nothing more to do for non-regular
 			return nil
@@ -493,7 +516,7 @@ func writeCertsLayer(job buildJob) (layer imageLayer, err error) {
 	source := filepath.Join(job.buildDir(), "ca-certificates.crt")
 	target := filepath.Join(job.buildDir(), "certslayer.tar.gz")
 
-	if err = newCertsTarball(source, target, job.verbose); err != nil {
+	if err = newCertsTarball(source, target, job.verbose, job.stderr()); err != nil {
 		return
 	}
 
@@ -510,13 +533,13 @@ func writeCertsLayer(job buildJob) (layer imageLayer, err error) {
 	// Blob
 	blob := filepath.Join(job.blobsDir(), layer.Descriptor.Digest.Hex)
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
+		fmt.Fprintf(job.stderr(), "mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
 	}
 	err = os.Rename(target, blob)
 	return
 }
 
-func newCertsTarball(source, target string, verbose bool) error {
+func newCertsTarball(source, target string, verbose bool, out io.Writer) error {
 	targetFile, err := os.Create(target)
 	if err != nil {
 		return err
@@ -554,7 +577,7 @@ func newCertsTarball(source, target string, verbose bool) error {
 			return err
 		}
 		if verbose {
-			fmt.Fprintf(os.Stderr, "→ %v \n", header.Name)
+			fmt.Fprintf(out, "→ %v \n", header.Name)
 		}
 		file, err := os.Open(source)
 		if err != nil {
@@ -574,8 +597,13 @@ func newCertsTarball(source, target string, verbose bool) error {
 // platform if a base image was specified for this builder.
 // Its layers are automatically downloaded into the local cache if this is
 // the first fetch and their blobs linked into the final OCI image.
+// The function is run directly upon the base, so a run image, if specified,
+// takes precedence.
 func pullBase(job buildJob, p v1.Platform) (image v1.Image, err error) {
 	baseImage := job.function.Build.BaseImage
+	if job.function.Build.RunImage != "" {
+		baseImage = job.function.Build.RunImage
+	}
 	if job.languageBuilder.Base(baseImage) == "" {
 		return // FROM SCRATCH
 	}
@@ -648,7 +676,7 @@ func ensureCached(job buildJob, layer v1.Layer) (err error) {
 	cachePath := filepath.Join(job.cacheDir(), digest.Hex)
 	if _, err = os.Stat(cachePath); !os.IsNotExist(err) {
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "Using cached base layer: %v\n", digest.Hex)
+			fmt.Fprintf(job.stderr(), "Using cached base layer: %v\n", digest.Hex)
 		}
 		return
 	}
@@ -668,7 +696,7 @@ func ensureCached(job buildJob, layer v1.Layer) (err error) {
 		return
 	}
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "Caching base image layer: %v\n", digest.Hex)
+		fmt.Fprintf(job.stderr(), "Caching base image layer: %v\n", digest.Hex)
 	}
 	return
 }
@@ -767,14 +795,14 @@ func newConfigEnvs(job buildJob) []string {
 	// environment FUNC_VERSION will be populated.  Otherwise it will exist
 	// (to indicate this logic was executed) but have an empty value.
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "cd %v && export FUNC_VERSION=$(%v describe --tags)\n", job.function.Root, gitbin)
+		fmt.Fprintf(job.stderr(), "cd %v && export FUNC_VERSION=$(%v describe --tags)\n", job.function.Root, gitbin)
 	}
 	cmd := exec.CommandContext(job.ctx, gitbin, "describe", "--tags")
 	cmd.Dir = job.function.Root
 	output, err := cmd.Output()
 	if err != nil {
 		if job.verbose {
-			fmt.Fprintf(os.Stderr, "WARN: unable to determine function version. %v\n", err)
+			fmt.Fprintf(job.stderr(), "WARN: unable to determine function version. %v\n", err)
 		}
 		envs = append(envs, "FUNC_VERSION=")
 	} else {
@@ -906,7 +934,7 @@ func newBuildJob(ctx context.Context, f fn.Function, pp []fn.Platform, verbose b
 
 	// Get the builder registered for this language
 	var ok bool
-	if job.languageBuilder, ok = builders[f.Runtime]; !ok {
+	if job.languageBuilder, ok = languageBuilders[f.Runtime]; !ok {
 		return job, fmt.Errorf("%v functions are not yet supported by the host builder", f.Runtime)
 	}
 	return job, nil
@@ -938,6 +966,12 @@ func (j buildJob) blobsDir() string {
 func (j buildJob) cacheDir() string {
 	return filepath.Join(j.function.Root, fn.RunDataDir, "blob-cache")
 }
+func (j buildJob) stdout() io.Writer {
+	return fn.BuildOutput(j.ctx, os.Stdout)
+}
+func (j buildJob) stderr() io.Writer {
+	return fn.BuildOutput(j.ctx, os.Stderr)
+}
 
 // isActive returns false if an active build for this Function is detected.
 func (j buildJob) isActive() bool {
@@ -1010,7 +1044,7 @@ func isLinkTo(link, target string) bool {
 
 func updateLastLink(job buildJob) error {
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "ln -s %v %v\n", job.buildDir(), job.lastLink())
+		fmt.Fprintf(job.stderr(), "ln -s %v %v\n", job.buildDir(), job.lastLink())
 	}
 	_ = os.RemoveAll(job.lastLink())
 	rp, err := filepath.Rel(filepath.Dir(job.lastLink()), job.buildDir())
@@ -1084,7 +1118,7 @@ func writeAsJSONBlob(job buildJob, tempName string, data any) (desc v1.Descripto
 	// move -> blobs
 	blobPath := filepath.Join(job.blobsDir(), hash.Hex)
 	if job.verbose {
-		fmt.Fprintf(os.Stderr, "mv %v %v\n", rel(job.buildDir(), filePath), rel(job.buildDir(), blobPath))
+		fmt.Fprintf(job.stderr(), "mv %v %v\n", rel(job.buildDir(), filePath), rel(job.buildDir(), blobPath))
 	}
 	// Need to close before rename
 	if err = file.Close(); err != nil {
@@ -80,6 +80,144 @@ func TestBuilder_BuildPython(t *testing.T) {
 	validateOCIStructure(last, t) // validate OCI compliant
 }
 
+// TestBuilder_BuildNode ensures that, when given a Node Function, an
+// OCI-compliant directory structure is created on .Build in the expected path.
+func TestBuilder_BuildNode(t *testing.T) {
+	testNode, _ := strconv.ParseBool(os.Getenv("FUNC_TEST_NODE"))
+	if !testNode {
+		t.Skip("Skipping test that requires special environment setup")
+	}
+	root, done := Mktemp(t)
+	defer done()
+
+	client := fn.New(fn.WithVerbose(true))
+
+	f, err := client.Init(fn.Function{Root: root, Runtime: "node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := NewBuilder("", true)
+
+	if err := builder.Build(context.Background(), f, TestPlatforms); err != nil {
+		t.Fatal(err)
+	}
+
+	last := filepath.Join(f.Root, fn.RunDataDir, "builds", "last", "oci")
+
+	validateOCIStructure(last, t) // validate OCI compliant
+}
+
+// TestBuilder_BuildTypeScript ensures that, when given a TypeScript function,
+// an OCI-compliant directory structure is created on .Build in the expected
+// path.
+func TestBuilder_BuildTypeScript(t *testing.T) {
+	testNode, _ := strconv.ParseBool(os.Getenv("FUNC_TEST_NODE"))
+	if !testNode {
+		t.Skip("Skipping test that requires special environment setup")
+	}
+	root, done := Mktemp(t)
+	defer done()
+
+	f, err := fn.New(fn.WithVerbose(true)).Init(fn.Function{Root: root, Runtime: "typescript"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewBuilder("", true).Build(context.Background(), f, TestPlatforms); err != nil {
+		t.Fatal(err)
+	}
+
+	last := filepath.Join(f.Root, fn.RunDataDir, "builds", "last", "oci")
+
+	validateOCIStructure(last, t) // validate OCI compliant
+}
+
+// Test_copyFunction ensures that the function is copied for compilation less
+// its node_modules and the files excluded from its build context.
+func Test_copyFunction(t *testing.T) {
+	root := t.TempDir()
+	for path, data := range map[string]string{
+		"package.json":         "{}",
+		"src/index.ts":         "export {}",
+		"node_modules/a/a.js":  "",
+		"ignored.txt":          "",
+		fn.IgnoreFile:          "ignored.txt\n",
+		".func/built-image":    "",
+		"src/nested/module.ts": "export {}",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "typescript")
+	if err := copyFunction(root, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var copied []string
+	_ = filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			copied = append(copied, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	sort.Strings(copied)
+	want := []string{"package.json", "src/index.ts", "src/nested/module.ts"}
+	if !cmp.Equal(copied, want) {
+		t.Fatalf("unexpected files copied (-want +got):\n%v", cmp.Diff(want, copied))
+	}
+}
+
+// Test_checkNativeModules ensures that dependencies with native addons,
+// installed on the host, are refused when building for another platform.
+func Test_checkNativeModules(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "node_modules")
+	host := v1.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	other := v1.Platform{OS: "linux", Architecture: "s390x"}
+	if runtime.GOARCH == "s390x" {
+		other.Architecture = "ppc64le"
+	}
+
+	// Without dependencies, any platform may be built
+	job := buildJob{platforms: []v1.Platform{host, other}}
+	if err := checkNativeModules(job, dir); err != nil {
+		t.Fatalf("unexpected error without dependencies: %v", err)
+	}
+
+	// Dependencies of JavaScript alone may be built for any platform
+	if err := os.MkdirAll(filepath.Join(dir, "a", "build", "Release"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "index.js"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkNativeModules(job, dir); err != nil {
+		t.Fatalf("unexpected error without native addons: %v", err)
+	}
+
+	// Native addons may be built for the host alone
+	if err := os.WriteFile(filepath.Join(dir, "a", "build", "Release", "a.node"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkNativeModules(buildJob{platforms: []v1.Platform{host}}, dir); err != nil {
+		t.Fatalf("unexpected error building for the host: %v", err)
+	}
+	err := checkNativeModules(job, dir)
+	var errNative ErrNativeModules
+	if !errors.As(err, &errNative) {
+		t.Fatalf("expected ErrNativeModules, got %v", err)
+	}
+	if !cmp.Equal(errNative.Addons, []string{"a/build/Release/a.node"}) || !cmp.Equal(errNative.Platforms, []string{other.String()}) {
+		t.Fatalf("unexpected addons %v or platforms %v", errNative.Addons, errNative.Platforms)
+	}
+}
+
 // TestBuilder_Files ensures that static files are added to the container
 // image as expected.  This includes template files, regular files and links.
 func TestBuilder_Files(t *testing.T) {
@@ -0,0 +1,75 @@
+package oci
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Caches of the function retained by the builder: its blob cache of base
+// layers, and the directories of its builds other than the last, which is
+// that pushed, and those in progress.
+func (b *Builder) Caches(ctx context.Context, f fn.Function) (cc []fn.BuildCache, err error) {
+	job := buildJob{function: f}
+	if c, ok := b.directoryCache(job.cacheDir()); ok {
+		cc = append(cc, c)
+	}
+	dd, err := os.ReadDir(job.buildsDir())
+	if os.IsNotExist(err) {
+		return cc, nil
+	} else if err != nil {
+		return nil, err
+	}
+	for _, d := range dd {
+		dir := filepath.Join(job.buildsDir(), d.Name())
+		if !d.IsDir() || isLinkTo(job.lastLink(), dir) || isBuilding(job, dir) {
+			continue
+		}
+		if c, ok := b.directoryCache(dir); ok {
+			cc = append(cc, c)
+		}
+	}
+	return cc, nil
+}
+
+// Remove the cache's directory.
+func (b *Builder) Remove(ctx context.Context, c fn.BuildCache) error {
+	return os.RemoveAll(c.Name)
+}
+
+// directoryCache of the directory, sized by its files and modified when the
+// last of them was written; false if there is no such directory.
+func (b *Builder) directoryCache(dir string) (fn.BuildCache, bool) {
+	fi, err := os.Stat(dir)
+	if err != nil || !fi.IsDir() {
+		return fn.BuildCache{}, false
+	}
+	c := fn.BuildCache{Builder: b.name, Kind: fn.CacheDirectory, Name: dir, Modified: fi.ModTime()}
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if fi, err := d.Info(); err == nil && fi.Mode().IsRegular() {
+			c.Size += fi.Size()
+			if fi.ModTime().After(c.Modified) {
+				c.Modified = fi.ModTime()
+			}
+		}
+		return nil
+	})
+	return c, true
+}
+
+// isBuilding returns true if a build in progress is of the directory.
+func isBuilding(job buildJob, dir string) bool {
+	dd, _ := os.ReadDir(job.pidsDir())
+	for _, d := range dd {
+		if processExists(d.Name()) && isLinkTo(filepath.Join(job.pidsDir(), d.Name()), dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package oci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestBuilder_Caches ensures that the blob cache and the builds other than
+// the last are listed as caches, and removed.
+func TestBuilder_Caches(t *testing.T) {
+	root := t.TempDir()
+	f := fn.Function{Root: root}
+	job := buildJob{function: f}
+	for _, dir := range []string{job.cacheDir(), filepath.Join(job.buildsDir(), "a"), filepath.Join(job.buildsDir(), "b")} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "blob"), []byte("12345"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("by-hash", "b"), job.lastLink()); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder("host", false)
+	cc, err := b.Caches(context.Background(), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc) != 2 || cc[0].Name != job.cacheDir() || cc[1].Name != filepath.Join(job.buildsDir(), "a") {
+		t.Fatalf("expected the blob cache and build a, got %+v", cc)
+	}
+	if cc[0].Size != 5 || cc[0].Kind != fn.CacheDirectory || cc[0].Builder != "host" {
+		t.Fatalf("unexpected cache %+v", cc[0])
+	}
+
+	if err = b.Remove(context.Background(), cc[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(cc[1].Name); !os.IsNotExist(err) {
+		t.Fatalf("expected the build removed, got %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(job.buildsDir(), "b")); err != nil {
+		t.Fatalf("expected the last build retained, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package oci
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // BuildErr indicates a general build error occurred.
 type BuildErr struct {
@@ -18,3 +21,17 @@ type ErrBuildInProgress struct {
 func (e ErrBuildInProgress) Error() string {
 	return fmt.Sprintf("a build for this function is associated with an active PID appears to be already in progress %v", e.Dir)
 }
+
+// ErrNativeModules is returned when the dependencies of a Node function,
+// installed on the host, include native addons yet the function is built
+// for a platform other than that of the host.
+type ErrNativeModules struct {
+	Addons    []string
+	Platforms []string
+}
+
+func (e ErrNativeModules) Error() string {
+	return fmt.Sprintf("the function's dependencies include native addons (%v) which were installed for the host, "+
+		"and so cannot be built for %v by the host builder. Build for the host's platform alone, or use the buildkit or pack builder",
+		strings.Join(e.Addons, ", "), strings.Join(e.Platforms, ", "))
+}
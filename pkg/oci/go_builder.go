@@ -49,7 +49,7 @@ func (b goBuilder) WritePlatform(cfg buildJob, p v1.Platform) (layers []imageLay
 
 	// Tarball
 	target := filepath.Join(cfg.buildDir(), fmt.Sprintf("execlayer.%v.%v.tar.gz", p.OS, p.Architecture))
-	if err = goExeTarball(exe, target, cfg.verbose); err != nil {
+	if err = goExeTarball(exe, target, cfg.verbose, cfg.stdout()); err != nil {
 		return
 	}
 
@@ -67,7 +67,7 @@ func (b goBuilder) WritePlatform(cfg buildJob, p v1.Platform) (layers []imageLay
 	// Blob
 	blob := filepath.Join(cfg.blobsDir(), desc.Digest.Hex)
 	if cfg.verbose {
-		fmt.Printf("mv %v %v\n", rel(cfg.buildDir(), target), rel(cfg.buildDir(), blob))
+		fmt.Fprintf(cfg.stdout(), "mv %v %v\n", rel(cfg.buildDir(), target), rel(cfg.buildDir(), blob))
 	}
 	err = os.Rename(target, blob)
 	if err != nil {
@@ -86,16 +86,16 @@ func goBuild(cfg buildJob, p v1.Platform) (binPath string, err error) {
 	}
 	envs := goBuildEnvs(p)
 	if cfg.verbose {
-		fmt.Printf("%v %v\n", gobin, strings.Join(args, " "))
+		fmt.Fprintf(cfg.stdout(), "%v %v\n", gobin, strings.Join(args, " "))
 	} else {
-		fmt.Printf("   %v\n", filepath.Base(outpath))
+		fmt.Fprintf(cfg.stdout(), "   %v\n", filepath.Base(outpath))
 	}
 
 	cmd := exec.CommandContext(cfg.ctx, gobin, "mod", "tidy")
 	cmd.Env = envs
 	cmd.Dir = cfg.buildDir()
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stderr = cfg.stderr()
+	cmd.Stdout = cfg.stdout()
 	err = cmd.Run()
 	if err != nil {
 		return "", fmt.Errorf("cannot sync deps: %w", err)
@@ -105,8 +105,8 @@ func goBuild(cfg buildJob, p v1.Platform) (binPath string, err error) {
 	cmd = exec.CommandContext(cfg.ctx, gobin, args...)
 	cmd.Env = envs
 	cmd.Dir = cfg.buildDir()
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stderr = cfg.stderr()
+	cmd.Stdout = cfg.stdout()
 
 	return outpath, cmd.Run()
 }
@@ -175,7 +175,7 @@ func goBuildEnvs(p v1.Platform) (envs []string) {
 	return envs
 }
 
-func goExeTarball(source, target string, verbose bool) error {
+func goExeTarball(source, target string, verbose bool, out io.Writer) error {
 	targetFile, err := os.Create(target)
 	if err != nil {
 		return err
@@ -207,7 +207,7 @@ func goExeTarball(source, target string, verbose bool) error {
 		return err
 	}
 	if verbose {
-		fmt.Printf("→ %v \n", header.Name)
+		fmt.Fprintf(out, "→ %v \n", header.Name)
 	}
 
 	file, err := os.Open(source)
@@ -221,7 +221,7 @@ func goExeTarball(source, target string, verbose bool) error {
 		return err
 	}
 	if verbose {
-		fmt.Printf("  wrote %v bytes \n", i)
+		fmt.Fprintf(out, "  wrote %v bytes \n", i)
 	}
 	return nil
 }
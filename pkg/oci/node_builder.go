@@ -0,0 +1,252 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	slashpath "path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+
+	"knative.dev/func/pkg/builders"
+)
+
+var defaultNodeBase = "node:22-slim"
+
+// nodeManifests are the files copied from the function into the build
+// directory in order to install the function's production dependencies.
+var nodeManifests = []string{"package.json", "package-lock.json", "npm-shrinkwrap.json", ".npmrc"}
+
+// nodeBuilder builds Node functions, which are served by the function's own
+// faas-js-runtime dependency and therefore require no scaffolding.
+type nodeBuilder struct{}
+
+func (b nodeBuilder) Base(customBase string) string {
+	if customBase != "" {
+		return customBase
+	}
+	out, err := exec.Command("node", "-v").CombinedOutput()
+	if err != nil {
+		return defaultNodeBase
+	}
+	subMatches := regexp.MustCompile(`v(\d+)\.\d+\.\d+`).FindSubmatch(out)
+	if len(subMatches) != 2 {
+		return defaultNodeBase
+	}
+	return fmt.Sprintf("node:%s-slim", subMatches[1])
+}
+
+// Configure the container to start the faas-js-runtime with the function's
+// main module.
+func (b nodeBuilder) Configure(job buildJob, _ v1.Platform, cf v1.ConfigFile) (v1.ConfigFile, error) {
+	main, err := builders.NodeMain(job.function.Root, "index.js")
+	if err != nil {
+		return cf, err
+	}
+	return nodeConfigure(cf, main), nil
+}
+
+// nodeConfigure the container to start the faas-js-runtime with the main
+// module, a slash-separated path relative to the function's root.
+func nodeConfigure(cf v1.ConfigFile, main string) v1.ConfigFile {
+	cf.Config.Env = append(cf.Config.Env, "NODE_ENV=production")
+	cf.Config.Cmd = []string{"node",
+		"/func/node_modules/faas-js-runtime/bin/cli.js",
+		slashpath.Join("/func", main)}
+	return cf
+}
+
+// WriteShared installs the function's production dependencies using the
+// host's npm, and layers them as /func/node_modules.
+func (b nodeBuilder) WriteShared(job buildJob) (layers []imageLayer, err error) {
+	// Copy the package manifests into the build directory such that the
+	// installation neither uses nor modifies the function's node_modules.
+	lock := false
+	for _, name := range nodeManifests {
+		var data []byte
+		if data, err = os.ReadFile(filepath.Join(job.function.Root, name)); os.IsNotExist(err) {
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+		if err = os.WriteFile(filepath.Join(job.buildDir(), name), data, 0644); err != nil {
+			return
+		}
+		lock = lock || name == "package-lock.json" || name == "npm-shrinkwrap.json"
+	}
+
+	// Install production dependencies, reproducibly if locked.
+	args := []string{"install", "--omit=dev"}
+	if lock {
+		args[0] = "ci"
+	}
+	if err = npm(job, job.buildDir(), args...); err != nil {
+		return nil, fmt.Errorf("cannot install dependencies: %w", err)
+	}
+	if err = checkNativeModules(job, filepath.Join(job.buildDir(), "node_modules")); err != nil {
+		return
+	}
+
+	layer, err := newNodeLayer(job, filepath.Join(job.buildDir(), "node_modules"), "/func/node_modules", "node_modules.tar.gz")
+	if err != nil {
+		return
+	}
+	return []imageLayer{layer}, nil
+}
+
+// checkNativeModules refuses dependencies, installed at dir, which include
+// native addons (.node files) when building for a platform other than that
+// of the host: the addons are built, or chosen, by npm for the host alone,
+// yet would be layered into the image of every platform.
+func checkNativeModules(job buildJob, dir string) error {
+	var foreign []string
+	for _, p := range job.platforms {
+		if p.OS != runtime.GOOS || p.Architecture != runtime.GOARCH {
+			foreign = append(foreign, p.String())
+		}
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+	var addons []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return filepath.SkipDir // no dependencies
+		} else if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".node" {
+			return nil
+		}
+		addon, err := filepath.Rel(dir, path)
+		addons = append(addons, filepath.ToSlash(addon))
+		return err
+	})
+	if err != nil || len(addons) == 0 {
+		return err
+	}
+	return ErrNativeModules{Addons: addons, Platforms: foreign}
+}
+
+// npm runs the command with the arguments in the directory.
+func npm(job buildJob, dir string, args ...string) error {
+	if job.verbose {
+		fmt.Fprintf(job.stdout(), "npm %v\n", strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(job.ctx, "npm", args...)
+	cmd.Dir = dir
+	cmd.Stderr = job.stderr()
+	cmd.Stdout = job.stdout()
+	return cmd.Run()
+}
+
+// newNodeLayer of the directory source, which when extracted is at prefix,
+// its blob written as the named tarball.
+func newNodeLayer(job buildJob, source, prefix, name string) (l imageLayer, err error) {
+	var desc v1.Descriptor
+	var layer v1.Layer
+
+	// Tarball
+	target := filepath.Join(job.buildDir(), name)
+	if err = newNodeModulesTarball(source, prefix, target); err != nil {
+		return
+	}
+
+	// Layer
+	if layer, err = tarball.LayerFromFile(target); err != nil {
+		return
+	}
+
+	// Descriptor
+	if desc, err = newDescriptor(layer); err != nil {
+		return
+	}
+
+	// Blob
+	blob := filepath.Join(job.blobsDir(), desc.Digest.Hex)
+	if job.verbose {
+		fmt.Fprintf(job.stdout(), "mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
+	}
+	if err = os.Rename(target, blob); err != nil {
+		return
+	}
+
+	return imageLayer{Descriptor: desc, Layer: layer}, nil
+}
+
+func (b nodeBuilder) WritePlatform(buildJob, v1.Platform) ([]imageLayer, error) {
+	return []imageLayer{}, nil
+}
+
+// newNodeModulesTarball of the installed dependencies, or other files, at root
+// which, when extracted, are at prefix (such as /func/node_modules).
+func newNodeModulesTarball(root, prefix, target string) error {
+	targetFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer targetFile.Close()
+
+	gw := gzip.NewWriter(targetFile)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	// A function with no dependencies has no node_modules
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		lnk := "" // if link, this will be used as the target
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if lnk, err = validatedLinkTarget(root, path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, lnk)
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		header.Name = slashpath.Join(prefix, filepath.ToSlash(relPath))
+		header.Uid = DefaultUid
+		header.Gid = DefaultGid
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() { //nothing more to do for non-regular
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tw, file)
+		return err
+	})
+}
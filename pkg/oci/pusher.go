@@ -7,17 +7,15 @@ import (
 	"os"
 	"path/filepath"
 
-	"golang.org/x/term"
-
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/pkg/errors"
-	progress "github.com/schollz/progressbar/v3"
 
 	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/progress"
 )
 
 type Credentials struct {
@@ -39,8 +37,7 @@ type Pusher struct {
 	Username string
 	Verbose  bool
 
-	updates chan v1.Update
-	done    chan bool
+	plain bool // plain progress lines in place of a progress bar
 
 	transport http.RoundTripper
 }
@@ -67,14 +64,20 @@ func WithTransport(transport http.RoundTripper) Opt {
 	}
 }
 
+// WithPlainProgress reports the progress of a push as a summary of each
+// phase rather than as a progress bar, even on a terminal.
+func WithPlainProgress(plain bool) Opt {
+	return func(pusher *Pusher) {
+		pusher.plain = plain
+	}
+}
+
 func NewPusher(insecure, anon, verbose bool, opts ...Opt) *Pusher {
 	result := &Pusher{
 		credentialsProvider: EmptyCredentialsProvider,
 		Insecure:            insecure,
 		Anonymous:           anon,
 		Verbose:             verbose,
-		updates:             make(chan v1.Update, 10),
-		done:                make(chan bool, 1),
 		transport:           remote.DefaultTransport,
 	}
 	for _, opt := range opts {
@@ -86,8 +89,6 @@ func NewPusher(insecure, anon, verbose bool, opts ...Opt) *Pusher {
 func (p *Pusher) Push(ctx context.Context, f fn.Function) (digest string, err error) {
 	credentials, _ := p.credentialsProvider(ctx, f.Build.Image)
 
-	go p.handleUpdates(ctx)
-	defer func() { p.done <- true }()
 	buildDir, err := getLastBuildDir(f)
 	if err != nil {
 		return
@@ -108,47 +109,98 @@ func (p *Pusher) Push(ctx context.Context, f fn.Function) (digest string, err er
 	if err != nil {
 		return
 	}
-	if err = p.writeIndex(ctx, ref, ii, credentials); err != nil {
+	// An image built for a single platform is pushed as that image, rather
+	// than as an index of one, such that it is pulled as is by any client.
+	img, err := singleImage(ii)
+	if err != nil {
 		return
 	}
-	h, err := ii.Digest()
+
+	// Report progress of the push until it completes, the channel of updates
+	// being closed by the writer once it is started.  Should the push fail
+	// before, such as for want of credentials, reporting is stopped on return.
+	updates := make(chan v1.Update, 10)
+	out := fn.BuildOutput(ctx, os.Stderr)
+	transfer := progress.NewTransfer(out,
+		fmt.Sprintf("pushing %v (%v)", ref, describeLayers(ii)),
+		progress.WithInteractive(!p.plain && progress.IsTerminal(out)))
+	pushProgress := fn.NewPushProgress(ctx, ref.String())
+	reported, stop := make(chan struct{}), make(chan struct{})
+	defer func() {
+		close(stop)
+		<-reported
+	}()
+	go func() {
+		defer close(reported)
+		for {
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					return
+				}
+				if update.Error == nil {
+					transfer.Update(update.Complete, update.Total)
+					pushProgress.Update("", update.Complete, update.Total)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+	if img != nil {
+		err = p.writeImage(ctx, ref, img, credentials, updates)
+	} else {
+		err = p.writeIndex(ctx, ref, ii, credentials, updates)
+	}
+	if err != nil {
+		return
+	}
+	<-reported
+	transfer.Finish()
+
+	var h v1.Hash
+	if img != nil {
+		h, err = img.Digest()
+	} else {
+		h, err = ii.Digest()
+	}
 	if err != nil {
 		return
 	}
 	digest = h.String()
 	if p.Verbose {
-		fmt.Printf("\ndigest: %s\n", h)
+		fmt.Fprintf(fn.BuildOutput(ctx, os.Stdout), "\ndigest: %s\n", h)
 	}
 	return
 }
 
-func (p *Pusher) handleUpdates(ctx context.Context) {
-	var bar *progress.ProgressBar
-	for {
-		select {
-		case update := <-p.updates:
-			if bar == nil {
-				bar = progress.NewOptions64(update.Total,
-					progress.OptionSetVisibility(term.IsTerminal(int(os.Stdin.Fd()))),
-					progress.OptionSetDescription("pushing"),
-					progress.OptionShowCount(),
-					progress.OptionShowBytes(true),
-					progress.OptionShowElapsedTimeOnFinish())
-			}
-			_ = bar.Set64(update.Complete)
+// describeLayers of the images of the index, for example "3 layers", counting
+// those shared by images of several platforms once.
+func describeLayers(ii v1.ImageIndex) string {
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return "unknown layers"
+	}
+	layers := map[v1.Hash]bool{}
+	for _, desc := range im.Manifests {
+		img, err := ii.Image(desc.Digest)
+		if err != nil {
 			continue
-		case <-p.done:
-			if bar != nil {
-				_ = bar.Finish()
-			}
-			return
-		case <-ctx.Done():
-			if bar != nil {
-				_ = bar.Finish()
+		}
+		ll, err := img.Layers()
+		if err != nil {
+			continue
+		}
+		for _, l := range ll {
+			if h, err := l.Digest(); err == nil {
+				layers[h] = true
 			}
-			return
 		}
 	}
+	if len(layers) == 1 {
+		return "1 layer"
+	}
+	return fmt.Sprintf("%d layers", len(layers))
 }
 
 // The last build directory is symlinked upon successful build.
@@ -160,23 +212,52 @@ func getLastBuildDir(f fn.Function) (string, error) {
 	return dir, nil
 }
 
+// singleImage of the index, if it is of the image of a single platform.
+func singleImage(ii v1.ImageIndex) (v1.Image, error) {
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(im.Manifests) != 1 || !im.Manifests[0].MediaType.IsImage() {
+		return nil, nil
+	}
+	return ii.Image(im.Manifests[0].Digest)
+}
+
 // writeIndex to its defined registry.
-func (p *Pusher) writeIndex(ctx context.Context, ref name.Reference, ii v1.ImageIndex, creds Credentials) error {
+func (p *Pusher) writeIndex(ctx context.Context, ref name.Reference, ii v1.ImageIndex, creds Credentials, updates chan v1.Update) error {
+	oo, err := p.remoteOptions(ctx, creds, updates)
+	if err != nil {
+		return err
+	}
+	return remote.WriteIndex(ref, ii, oo...)
+}
+
+// writeImage to its defined registry.
+func (p *Pusher) writeImage(ctx context.Context, ref name.Reference, img v1.Image, creds Credentials, updates chan v1.Update) error {
+	oo, err := p.remoteOptions(ctx, creds, updates)
+	if err != nil {
+		return err
+	}
+	return remote.Write(ref, img, oo...)
+}
+
+// remoteOptions of a write, reporting its progress to updates.
+func (p *Pusher) remoteOptions(ctx context.Context, creds Credentials, updates chan v1.Update) ([]remote.Option, error) {
 	oo := []remote.Option{
 		remote.WithContext(ctx),
-		remote.WithProgress(p.updates),
+		remote.WithProgress(updates),
 		remote.WithTransport(p.transport),
 	}
 
 	if !p.Anonymous {
 		a, err := p.authOption(ctx, creds)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		oo = append(oo, a)
 	}
-
-	return remote.WriteIndex(ref, ii, oo...)
+	return oo, nil
 }
 
 // authOption selects an appropriate authentication option.
@@ -8,7 +8,10 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,7 +19,9 @@ import (
 	"knative.dev/func/pkg/oci/mock"
 	. "knative.dev/func/pkg/testing"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
 // TestPusher_Push ensures the base case that the pusher contacts the
@@ -162,3 +167,85 @@ func TestPusher_BasicAuth(t *testing.T) {
 		t.Fatal("timed out waiting for a successful basic auth request")
 	}
 }
+
+// TestPusher_SinglePlatform ensures that an image built for a single
+// platform is pushed as that image rather than as an index of one.
+func TestPusher_SinglePlatform(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+
+	client := fn.New(
+		fn.WithBuilder(NewBuilder("", false)),
+		fn.WithPusher(NewPusher(true, true, false)))
+	f := fn.Function{Root: root, Runtime: "go", Name: "f", Registry: strings.TrimPrefix(s.URL, "http://") + "/funcs"}
+	f, err := client.Init(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f, fn.BuildWithPlatforms([]fn.Platform{{OS: "linux", Architecture: "arm64"}})); err != nil {
+		t.Fatal(err)
+	}
+	if f, _, err = client.Push(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := name.ParseReference(f.Build.Image, name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := remote.Get(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !desc.MediaType.IsImage() {
+		t.Fatalf("expected an image manifest, got %v", desc.MediaType)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OS != "linux" || cfg.Architecture != "arm64" {
+		t.Fatalf("expected an image of linux/arm64, got %v/%v", cfg.OS, cfg.Architecture)
+	}
+}
+
+// TestPusher_FailedBeforeWrite ensures that the reporting of the progress of
+// a push which fails before it is written, such as for conflicting
+// credentials, is stopped rather than left running.
+func TestPusher_FailedBeforeWrite(t *testing.T) {
+	root, done := Mktemp(t)
+	defer done()
+
+	pusher := NewPusher(true, false, false)
+	client := fn.New(fn.WithBuilder(NewBuilder("", false)), fn.WithPusher(pusher))
+	f, err := client.Init(fn.Function{Root: root, Runtime: "go", Name: "f", Registry: "127.0.0.1:1/funcs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = client.Build(context.Background(), f); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), fn.PushUsernameKey{}, "username")
+	ctx = context.WithValue(ctx, fn.PushTokenKey{}, "token")
+	if _, err = pusher.Push(ctx, f); err == nil {
+		t.Fatal("expected the push to fail given both a username and token")
+	}
+	stack := make([]byte, 1<<20)
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		n := runtime.Stack(stack, true)
+		if !strings.Contains(string(stack[:n]), "oci.(*Pusher).Push.func") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the reporting of progress to be stopped:\n%s", stack[:n])
+		}
+	}
+}
@@ -59,12 +59,12 @@ func (b pythonBuilder) WriteShared(job buildJob) (layers []imageLayer, err error
 
 	// Create venv
 	if job.verbose {
-		fmt.Printf("python -m venv .venv\n")
+		fmt.Fprintf(job.stdout(), "python -m venv .venv\n")
 	}
 	cmd := exec.CommandContext(job.ctx, pythonCmd(), "-m", "venv", ".venv")
 	cmd.Dir = job.buildDir()
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stderr = job.stderr()
+	cmd.Stdout = job.stdout()
 	if err = cmd.Run(); err != nil {
 		return
 	}
@@ -73,12 +73,12 @@ func (b pythonBuilder) WriteShared(job buildJob) (layers []imageLayer, err error
 
 	// Upgrade pip
 	if job.verbose {
-		fmt.Printf(".venv/bin/pip install --upgrade pip\n")
+		fmt.Fprintf(job.stdout(), ".venv/bin/pip install --upgrade pip\n")
 	}
 	cmd = exec.CommandContext(job.ctx, pipPath, "install", "--upgrade", "pip")
 	cmd.Dir = job.buildDir()
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stderr = job.stderr()
+	cmd.Stdout = job.stdout()
 	if err = cmd.Run(); err != nil {
 		return
 	}
@@ -86,12 +86,12 @@ func (b pythonBuilder) WriteShared(job buildJob) (layers []imageLayer, err error
 	// Install Dependencies of the current project into ./lib
 	// In the scaffolding direcotory.
 	if job.verbose {
-		fmt.Printf(".venv/bin/pip install . --target lib\n")
+		fmt.Fprintf(job.stdout(), ".venv/bin/pip install . --target lib\n")
 	}
 	cmd = exec.CommandContext(job.ctx, pipPath, "install", ".", "--target", "lib")
 	cmd.Dir = job.buildDir()
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
+	cmd.Stderr = job.stderr()
+	cmd.Stdout = job.stdout()
 	if err = cmd.Run(); err != nil {
 		return
 	}
@@ -116,7 +116,7 @@ func (b pythonBuilder) WriteShared(job buildJob) (layers []imageLayer, err error
 	// Blob
 	blob := filepath.Join(job.blobsDir(), desc.Digest.Hex)
 	if job.verbose {
-		fmt.Printf("mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
+		fmt.Fprintf(job.stdout(), "mv %v %v\n", rel(job.buildDir(), target), rel(job.buildDir(), blob))
 	}
 	if err = os.Rename(target, blob); err != nil {
 		return
@@ -0,0 +1,112 @@
+package oci
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"knative.dev/func/pkg/builders"
+	fn "knative.dev/func/pkg/functions"
+)
+
+// typescriptBuilder builds TypeScript functions, which are compiled with the
+// function's own build script and then served as are Node functions.
+type typescriptBuilder struct {
+	nodeBuilder
+}
+
+// Configure the container to start the faas-js-runtime with the function's
+// compiled main module.
+func (b typescriptBuilder) Configure(job buildJob, _ v1.Platform, cf v1.ConfigFile) (v1.ConfigFile, error) {
+	main, err := builders.NodeMain(job.function.Root, "build/index.js")
+	if err != nil {
+		return cf, err
+	}
+	return nodeConfigure(cf, main), nil
+}
+
+// WriteShared compiles the function using the host's npm, and layers it with
+// its production dependencies at /func.
+//
+// The function is compiled in a copy of its source within the build
+// directory such that its own node_modules and build output are neither used
+// nor modified.  All dependencies are installed to compile the function with
+// `npm run build`, after which those of development are pruned.
+func (b typescriptBuilder) WriteShared(job buildJob) (layers []imageLayer, err error) {
+	dir := filepath.Join(job.buildDir(), "typescript")
+	if err = copyFunction(job.function.Root, dir); err != nil {
+		return nil, fmt.Errorf("cannot copy the function to compile it: %w", err)
+	}
+
+	install := "install"
+	for _, name := range []string{"package-lock.json", "npm-shrinkwrap.json"} {
+		if _, err = os.Stat(filepath.Join(dir, name)); err == nil {
+			install = "ci"
+		}
+	}
+	if err = npm(job, dir, install); err != nil {
+		return nil, fmt.Errorf("cannot install dependencies: %w", err)
+	}
+	if err = npm(job, dir, "run", "build"); err != nil {
+		return nil, fmt.Errorf("cannot compile the function: %w", err)
+	}
+	if err = npm(job, dir, "prune", "--omit=dev"); err != nil {
+		return nil, fmt.Errorf("cannot prune development dependencies: %w", err)
+	}
+	if err = checkNativeModules(job, filepath.Join(dir, "node_modules")); err != nil {
+		return
+	}
+
+	layer, err := newNodeLayer(job, dir, "/func", "typescript.tar.gz")
+	if err != nil {
+		return
+	}
+	return []imageLayer{layer}, nil
+}
+
+// copyFunction at root to dir, less the files excluded from its build context
+// and its node_modules.
+func copyFunction(root, dir string) error {
+	ignorer, err := fn.NewIgnorer(root, "node_modules")
+	if err != nil {
+		return err
+	}
+	return ignorer.Walk(func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&fs.ModeSymlink != 0:
+			lnk, err := validatedLinkTarget(root, path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(lnk, target)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+		_, err = io.Copy(dst, src)
+		return err
+	})
+}
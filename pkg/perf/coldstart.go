@@ -0,0 +1,200 @@
+// Package perf analyzes the performance of deployed functions, such as the
+// time taken to serve a request when scaled to zero (a cold start).
+package perf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Phase of a cold start.
+type Phase string
+
+const (
+	// PhaseActivator is the time from the request being sent until a pod is
+	// created to serve it: the request is held by the Knative activator while
+	// the autoscaler scales the function up from zero.
+	PhaseActivator Phase = "activator"
+	// PhaseContainerStart is the time from the pod being created until the
+	// function's container is started, including scheduling and pulling the
+	// image.
+	PhaseContainerStart Phase = "container-start"
+	// PhaseReadiness is the time from the container starting until it is
+	// ready: the initialization of the function's runtime and code.
+	PhaseReadiness Phase = "readiness"
+	// PhaseFirstByte is the time from the pod being ready until the first
+	// byte of the response is received.
+	PhaseFirstByte Phase = "first-byte"
+	// PhaseTotal is the time from the request being sent until the first byte
+	// of the response is received.
+	PhaseTotal Phase = "total"
+)
+
+// Phases of a cold start, in order.
+var Phases = []Phase{PhaseActivator, PhaseContainerStart, PhaseReadiness, PhaseFirstByte, PhaseTotal}
+
+// Sample of a single cold start.  Requested and FirstByte are measured by
+// the local clock, while the times of the pod are those reported by the
+// cluster, so the phases between the two are subject to any skew between
+// the clocks.
+type Sample struct {
+	Requested  time.Time `json:"requested"`
+	Created    time.Time `json:"created,omitempty"` // pod created
+	Started    time.Time `json:"started,omitempty"` // container started
+	Ready      time.Time `json:"ready,omitempty"`   // pod ready
+	FirstByte  time.Time `json:"firstByte"`
+	StatusCode int       `json:"statusCode"`
+	Pod        string    `json:"pod,omitempty"`
+}
+
+// Durations of each phase of the sample.  Phases for which the pod's
+// times are not known are omitted.
+func (s Sample) Durations() map[Phase]time.Duration {
+	d := map[Phase]time.Duration{PhaseTotal: s.FirstByte.Sub(s.Requested)}
+	if s.Created.IsZero() || s.Started.IsZero() || s.Ready.IsZero() {
+		return d
+	}
+	d[PhaseActivator] = nonNegative(s.Created.Sub(s.Requested))
+	d[PhaseContainerStart] = nonNegative(s.Started.Sub(s.Created))
+	d[PhaseReadiness] = nonNegative(s.Ready.Sub(s.Started))
+	d[PhaseFirstByte] = nonNegative(s.FirstByte.Sub(s.Ready))
+	return d
+}
+
+// nonNegative duration, as clock skew may otherwise yield negative phases.
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Stats of a phase over all samples.
+type Stats struct {
+	Phase  Phase         `json:"phase"`
+	Min    time.Duration `json:"min"`
+	Median time.Duration `json:"median"`
+	Max    time.Duration `json:"max"`
+}
+
+// Report of the cold starts of a function.
+type Report struct {
+	Function    string   `json:"function"`
+	Image       string   `json:"image,omitempty"`
+	ImageSize   int64    `json:"imageSize,omitempty"` // compressed, if known
+	Samples     []Sample `json:"samples"`
+	Stats       []Stats  `json:"stats"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// NewReport of the cold start samples of a function whose image is of the
+// given (compressed) size, or zero if unknown.
+func NewReport(f fn.Function, samples []Sample, imageSize int64) Report {
+	r := Report{
+		Function:  f.Name,
+		Image:     f.Deploy.Image,
+		ImageSize: imageSize,
+		Samples:   samples,
+	}
+	for _, p := range Phases {
+		var dd []time.Duration
+		for _, s := range samples {
+			if d, ok := s.Durations()[p]; ok {
+				dd = append(dd, d)
+			}
+		}
+		if len(dd) == 0 {
+			continue
+		}
+		sort.Slice(dd, func(i, j int) bool { return dd[i] < dd[j] })
+		r.Stats = append(r.Stats, Stats{Phase: p, Min: dd[0], Median: dd[len(dd)/2], Max: dd[len(dd)-1]})
+	}
+	r.Suggestions = suggest(f, r)
+	return r
+}
+
+// Median duration of the given phase, or zero if not measured.
+func (r Report) Median(p Phase) time.Duration {
+	for _, s := range r.Stats {
+		if s.Phase == p {
+			return s.Median
+		}
+	}
+	return 0
+}
+
+// Thresholds above which a phase is considered slow enough to suggest
+// how it might be improved.
+const (
+	slowTotal          = time.Second
+	slowActivator      = 2 * time.Second
+	slowContainerStart = 5 * time.Second
+	slowReadiness      = 2 * time.Second
+	largeImage         = 250_000_000
+)
+
+// suggest how the cold starts of the report might be improved.
+func suggest(f fn.Function, r Report) (ss []string) {
+	if r.Median(PhaseTotal) < slowTotal {
+		return
+	}
+	if scale := f.Deploy.Options.Scale; scale == nil || scale.Min == nil || *scale.Min == 0 {
+		ss = append(ss, "Set a minimum scale of 1 (options.scale.min in func.yaml) to keep an instance "+
+			"running, avoiding cold starts entirely at the cost of that instance's resources.")
+	}
+	if r.Median(PhaseActivator) > slowActivator {
+		ss = append(ss, "Most time passes before a pod is created for the request. Check the "+
+			"cluster's autoscaler for delays in scaling from zero.")
+	}
+	if r.Median(PhaseContainerStart) > slowContainerStart {
+		if r.ImageSize > largeImage {
+			ss = append(ss, fmt.Sprintf("The function's image is %v MB, the pulling of which delays the "+
+				"container's start. Use a smaller base image (build.baseImage) or builder.", r.ImageSize/1_000_000))
+		} else {
+			ss = append(ss, "The container is slow to start. Check for delays in scheduling the pod "+
+				"or pulling its image, for example by the nodes' image pull policy.")
+		}
+	}
+	if r.Median(PhaseReadiness) > slowReadiness {
+		switch f.Runtime {
+		case "springboot", "quarkus":
+			ss = append(ss, "The function is slow to initialize. Build a native executable "+
+				"(for example with GraalVM) to reduce the startup time of the JVM.")
+		default:
+			ss = append(ss, "The function is slow to initialize. Defer expensive initialization, "+
+				"such as connecting to other services, until it is first needed.")
+		}
+	}
+	return
+}
+
+// ImageSize returns the compressed size of the given image in its registry:
+// the total size of its layers.  For a multi-platform image, the size of
+// the image for linux/amd64 is returned.
+func ImageSize(ctx context.Context, image string) (int64, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return 0, err
+	}
+	img, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return 0, err
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, l := range m.Layers {
+		size += l.Size
+	}
+	return size, nil
+}
@@ -0,0 +1,86 @@
+package perf_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/perf"
+)
+
+// sample of a cold start with the given phase durations.
+func sample(activator, start, readiness, firstByte time.Duration) perf.Sample {
+	t := time.Unix(0, 0)
+	s := perf.Sample{Requested: t}
+	s.Created = s.Requested.Add(activator)
+	s.Started = s.Created.Add(start)
+	s.Ready = s.Started.Add(readiness)
+	s.FirstByte = s.Ready.Add(firstByte)
+	return s
+}
+
+// TestNewReport ensures that the statistics of each phase are calculated
+// over all samples.
+func TestNewReport(t *testing.T) {
+	f := fn.Function{Name: "f", Runtime: "go"}
+	r := perf.NewReport(f, []perf.Sample{
+		sample(100*time.Millisecond, 200*time.Millisecond, 50*time.Millisecond, 10*time.Millisecond),
+		sample(300*time.Millisecond, 100*time.Millisecond, 50*time.Millisecond, 30*time.Millisecond),
+		sample(200*time.Millisecond, 300*time.Millisecond, 50*time.Millisecond, 20*time.Millisecond),
+	}, 0)
+
+	expected := map[perf.Phase][3]time.Duration{
+		perf.PhaseActivator:      {100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+		perf.PhaseContainerStart: {100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond},
+		perf.PhaseReadiness:      {50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond},
+		perf.PhaseFirstByte:      {10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond},
+		perf.PhaseTotal:          {360 * time.Millisecond, 480 * time.Millisecond, 570 * time.Millisecond},
+	}
+	if len(r.Stats) != len(perf.Phases) {
+		t.Fatalf("expected stats of %v phases, got %v", len(perf.Phases), len(r.Stats))
+	}
+	for _, s := range r.Stats {
+		e := expected[s.Phase]
+		if s.Min != e[0] || s.Median != e[1] || s.Max != e[2] {
+			t.Errorf("unexpected stats of phase %v: %+v", s.Phase, s)
+		}
+	}
+	if len(r.Suggestions) != 0 {
+		t.Fatalf("expected no suggestions for fast cold starts, got %v", r.Suggestions)
+	}
+}
+
+// TestNewReport_Suggestions ensures that slow phases yield suggestions
+// relevant to the function.
+func TestNewReport_Suggestions(t *testing.T) {
+	f := fn.Function{Name: "f", Runtime: "quarkus"}
+	r := perf.NewReport(f, []perf.Sample{sample(0, 8*time.Second, 4*time.Second, 0)}, 400_000_000)
+	all := strings.Join(r.Suggestions, "\n")
+	for _, s := range []string{"options.scale.min", "400 MB", "native executable"} {
+		if !strings.Contains(all, s) {
+			t.Errorf("expected a suggestion mentioning %q, got:\n%v", s, all)
+		}
+	}
+
+	// A minimum scale is not suggested when already set
+	one := int64(1)
+	f.Deploy.Options.Scale = &fn.ScaleOptions{Min: &one}
+	r = perf.NewReport(f, []perf.Sample{sample(0, 8*time.Second, 0, 0)}, 0)
+	if all = strings.Join(r.Suggestions, "\n"); strings.Contains(all, "options.scale.min") {
+		t.Errorf("unexpected minimum scale suggestion:\n%v", all)
+	}
+}
+
+// TestSample_Durations ensures that a sample without the times of its pod
+// reports only its total, and that clock skew does not yield negative phases.
+func TestSample_Durations(t *testing.T) {
+	s := perf.Sample{Requested: time.Unix(0, 0), FirstByte: time.Unix(2, 0)}
+	if d := s.Durations(); len(d) != 1 || d[perf.PhaseTotal] != 2*time.Second {
+		t.Fatalf("expected only the total, got %v", d)
+	}
+	s = sample(-time.Second, time.Second, time.Second, time.Second)
+	if d := s.Durations(); d[perf.PhaseActivator] != 0 {
+		t.Fatalf("expected a negative phase to be zero, got %v", d[perf.PhaseActivator])
+	}
+}
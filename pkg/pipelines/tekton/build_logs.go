@@ -0,0 +1,234 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	pipelineClient "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/typed/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/apis"
+
+	fn "knative.dev/func/pkg/functions"
+	"knative.dev/func/pkg/k8s"
+	fnlabels "knative.dev/func/pkg/k8s/labels"
+)
+
+// BuildLogsRetained is the number of remote builds of a function whose logs
+// are retained on the cluster.  The logs of older builds are deleted.
+const BuildLogsRetained = 10
+
+const (
+	// buildLogsLabel marks the config maps in which the logs of remote builds
+	// are retained.
+	buildLogsLabel = "function.knative.dev/build-logs"
+
+	buildStartedAnnotation   = "function.knative.dev/build-started"
+	buildCompletedAnnotation = "function.knative.dev/build-completed"
+	buildSucceededAnnotation = "function.knative.dev/build-succeeded"
+	buildMessageAnnotation   = "function.knative.dev/build-message"
+
+	// maxBuildLogsSize of the logs of a build, within the size limit of a
+	// config map.  The logs of each step are truncated to their last lines
+	// such that those of all steps fit.
+	maxBuildLogsSize = 900 * 1024
+)
+
+// BuildLog is the log of a remote build of a function, retained after the
+// pods which ran it are removed.
+type BuildLog struct {
+	// Run is the name of the PipelineRun of the build.
+	Run       string         `json:"run"`
+	Started   time.Time      `json:"started"`
+	Completed time.Time      `json:"completed"`
+	Succeeded bool           `json:"succeeded"`
+	Message   string         `json:"message,omitempty"`
+	Steps     []BuildStepLog `json:"steps"`
+}
+
+// BuildStepLog is the log of a step of a task of a remote build.
+type BuildStepLog struct {
+	Task string `json:"task"`
+	Step string `json:"step"`
+	Log  string `json:"log"`
+}
+
+// BuildLogs of the most recent remote builds of the named function, newest
+// first.  At most last are returned, or all retained if last is not positive.
+func BuildLogs(ctx context.Context, name, namespace string, last int) ([]BuildLog, error) {
+	client, namespace, err := k8s.NewClientAndResolvedNamespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	ll, err := listBuildLogs(ctx, client, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if last > 0 && len(ll) > last {
+		ll = ll[:last]
+	}
+	return ll, nil
+}
+
+// retainBuildLogs of the completed PipelineRun in a config map, deleting
+// those of the function's builds beyond the most recent BuildLogsRetained.
+func retainBuildLogs(ctx context.Context, tekton pipelineClient.TektonV1Interface, client kubernetes.Interface, f fn.Function, pr *v1.PipelineRun, namespace string, labels map[string]string) error {
+	steps := collectBuildLogs(ctx, tekton, client, pr, namespace)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        pr.Name + "-logs",
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		Data: map[string]string{},
+	}
+	for k, v := range labels {
+		cm.Labels[k] = v
+	}
+	cm.Labels[fnlabels.FunctionNameKey] = f.Name
+	cm.Labels[buildLogsLabel] = "true"
+
+	condition := pr.Status.GetCondition(apis.ConditionSucceeded)
+	if condition != nil {
+		cm.Annotations[buildSucceededAnnotation] = fmt.Sprint(condition.Status == corev1.ConditionTrue)
+		cm.Annotations[buildMessageAnnotation] = condition.Message
+	}
+	if pr.Status.StartTime != nil {
+		cm.Annotations[buildStartedAnnotation] = pr.Status.StartTime.UTC().Format(time.RFC3339)
+	}
+	if pr.Status.CompletionTime != nil {
+		cm.Annotations[buildCompletedAnnotation] = pr.Status.CompletionTime.UTC().Format(time.RFC3339)
+	}
+
+	limit := maxBuildLogsSize
+	if len(steps) > 0 {
+		limit = maxBuildLogsSize / len(steps)
+	}
+	for i, s := range steps {
+		// Keys are ordered by the index of the step, and are otherwise valid
+		// as task and step names are DNS labels.
+		cm.Data[fmt.Sprintf("%03d.%v.%v", i, s.Task, s.Step)] = truncateLog(s.Log, limit)
+	}
+
+	if _, err := client.CoreV1().ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("cannot create config map of the build logs: %w", err)
+	}
+
+	retained, err := listBuildLogs(ctx, client, f.Name, namespace)
+	if err != nil {
+		return err
+	}
+	for i := BuildLogsRetained; i < len(retained); i++ {
+		err = client.CoreV1().ConfigMaps(namespace).Delete(ctx, retained[i].Run+"-logs", metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot delete the logs of build %v: %w", retained[i].Run, err)
+		}
+	}
+	return nil
+}
+
+// collectBuildLogs of each step of the PipelineRun's tasks, in order.  Steps
+// whose logs are not available, for example because their pod was removed,
+// are logged as such.
+func collectBuildLogs(ctx context.Context, tekton pipelineClient.TektonV1Interface, client kubernetes.Interface, pr *v1.PipelineRun, namespace string) (steps []BuildStepLog) {
+	for _, ref := range pr.Status.ChildReferences {
+		t, err := tekton.TaskRuns(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			steps = append(steps, BuildStepLog{Task: ref.PipelineTaskName, Log: fmt.Sprintf("cannot get TaskRun %v: %v\n", ref.Name, err)})
+			continue
+		}
+		task := ref.PipelineTaskName
+		if task == "" {
+			task = t.Name
+		}
+		for _, s := range t.Status.Steps {
+			log, err := podLogs(ctx, client, namespace, t.Status.PodName, s.Container)
+			if err != nil {
+				log = fmt.Sprintf("cannot get the log of step %v: %v\n", s.Name, err)
+			}
+			steps = append(steps, BuildStepLog{Task: task, Step: s.Name, Log: log})
+		}
+	}
+	return
+}
+
+func podLogs(ctx context.Context, client kubernetes.Interface, namespace, pod, container string) (string, error) {
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container}).Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	bb, err := io.ReadAll(stream)
+	return string(bb), err
+}
+
+// truncateLog to its last lines within limit bytes.
+func truncateLog(log string, limit int) string {
+	const marker = "[earlier lines truncated]\n"
+	if len(log) <= limit {
+		return log
+	}
+	log = log[len(log)-(limit-len(marker)):]
+	if i := strings.IndexByte(log, '\n'); i >= 0 {
+		log = log[i+1:]
+	}
+	return marker + log
+}
+
+// listBuildLogs of the function retained in the namespace, newest first.
+func listBuildLogs(ctx context.Context, client kubernetes.Interface, name, namespace string) ([]BuildLog, error) {
+	selector := k8slabels.SelectorFromSet(k8slabels.Set{fnlabels.FunctionNameKey: name, buildLogsLabel: "true"})
+	cms, err := client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the build logs of %v: %w", name, err)
+	}
+	ll := make([]BuildLog, 0, len(cms.Items))
+	for _, cm := range cms.Items {
+		ll = append(ll, newBuildLog(cm))
+	}
+	sort.SliceStable(ll, func(i, j int) bool { return ll[i].Started.After(ll[j].Started) })
+	return ll, nil
+}
+
+func newBuildLog(cm corev1.ConfigMap) BuildLog {
+	l := BuildLog{
+		Run:       strings.TrimSuffix(cm.Name, "-logs"),
+		Succeeded: cm.Annotations[buildSucceededAnnotation] == "true",
+		Message:   cm.Annotations[buildMessageAnnotation],
+		Steps:     []BuildStepLog{},
+	}
+	l.Started, _ = time.Parse(time.RFC3339, cm.Annotations[buildStartedAnnotation])
+	l.Completed, _ = time.Parse(time.RFC3339, cm.Annotations[buildCompletedAnnotation])
+
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := BuildStepLog{Log: cm.Data[k]}
+		if parts := strings.SplitN(k, ".", 3); len(parts) == 3 {
+			s.Task, s.Step = parts[1], parts[2]
+		}
+		l.Steps = append(l.Steps, s)
+	}
+	return l
+}
+
+// deleteBuildLogs of the function's remote builds.
+func deleteBuildLogs(ctx context.Context, namespace string, listOptions metav1.ListOptions) error {
+	client, namespace, err := k8s.NewClientAndResolvedNamespace(namespace)
+	if err != nil {
+		return err
+	}
+	listOptions.LabelSelector += "," + buildLogsLabel + "=true"
+	return client.CoreV1().ConfigMaps(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
+}
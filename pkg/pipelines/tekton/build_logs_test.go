@@ -0,0 +1,141 @@
+package tekton
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	fakepipeline "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestRetainBuildLogs ensures that the logs of each step of a build are
+// retained, and read back in order.
+func TestRetainBuildLogs(t *testing.T) {
+	ctx := context.Background()
+	const ns = "test-ns"
+	f := fn.Function{Name: "myfunc"}
+
+	tekton := fakepipeline.NewSimpleClientset(
+		taskRun(ns, "run-1-fetch", "pod-fetch", "clone"),
+		taskRun(ns, "run-1-build", "pod-build", "prepare", "build"),
+	)
+	client := fake.NewSimpleClientset()
+
+	pr := pipelineRun("run-1", time.Now(), corev1.ConditionFalse, "build failed")
+	pr.Status.ChildReferences = []pipelinev1.ChildStatusReference{
+		{Name: "run-1-fetch", PipelineTaskName: "fetch-sources"},
+		{Name: "run-1-build", PipelineTaskName: "build"},
+	}
+	if err := retainBuildLogs(ctx, tekton.TektonV1(), client, f, pr, ns, map[string]string{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ll, err := listBuildLogs(ctx, client, f.Name, ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ll) != 1 {
+		t.Fatalf("expected the logs of one build, got %v", len(ll))
+	}
+	l := ll[0]
+	if l.Run != "run-1" || l.Succeeded || l.Message != "build failed" {
+		t.Errorf("unexpected build log %+v", l)
+	}
+	var got []string
+	for _, s := range l.Steps {
+		got = append(got, s.Task+"/"+s.Step)
+		if s.Log == "" {
+			t.Errorf("expected the log of step %v/%v", s.Task, s.Step)
+		}
+	}
+	if want := "fetch-sources/clone build/prepare build/build"; strings.Join(got, " ") != want {
+		t.Errorf("expected steps %q, got %q", want, strings.Join(got, " "))
+	}
+
+	cm, err := client.CoreV1().ConfigMaps(ns).Get(ctx, "run-1-logs", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Labels["a"] != "b" {
+		t.Errorf("expected the labels of the function's resources, got %v", cm.Labels)
+	}
+}
+
+// TestRetainBuildLogs_Retention ensures that only the logs of the most recent
+// builds are retained.
+func TestRetainBuildLogs_Retention(t *testing.T) {
+	ctx := context.Background()
+	const ns = "test-ns"
+	f := fn.Function{Name: "myfunc"}
+
+	tekton := fakepipeline.NewSimpleClientset()
+	client := fake.NewSimpleClientset()
+
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < BuildLogsRetained+2; i++ {
+		pr := pipelineRun(fmt.Sprintf("run-%v", i), start.Add(time.Duration(i)*time.Minute), corev1.ConditionTrue, "")
+		if err := retainBuildLogs(ctx, tekton.TektonV1(), client, f, pr, ns, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ll, err := listBuildLogs(ctx, client, f.Name, ns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ll) != BuildLogsRetained {
+		t.Fatalf("expected the logs of %v builds, got %v", BuildLogsRetained, len(ll))
+	}
+	if want := fmt.Sprintf("run-%v", BuildLogsRetained+1); ll[0].Run != want {
+		t.Errorf("expected the newest build %v first, got %v", want, ll[0].Run)
+	}
+	if !ll[0].Succeeded {
+		t.Error("expected the build to have succeeded")
+	}
+	for _, l := range ll {
+		if l.Run == "run-0" || l.Run == "run-1" {
+			t.Errorf("expected the logs of %v to be deleted", l.Run)
+		}
+	}
+}
+
+func TestTruncateLog(t *testing.T) {
+	log := strings.Repeat("a line of the log\n", 100)
+	if got := truncateLog(log, len(log)); got != log {
+		t.Error("expected a log within the limit to be unchanged")
+	}
+	got := truncateLog(log, 100)
+	if len(got) > 100 {
+		t.Errorf("expected at most 100 bytes, got %v", len(got))
+	}
+	if !strings.HasPrefix(got, "[earlier lines truncated]\na line") {
+		t.Errorf("expected whole lines after the marker, got %q", got)
+	}
+}
+
+func pipelineRun(name string, started time.Time, status corev1.ConditionStatus, message string) *pipelinev1.PipelineRun {
+	pr := &pipelinev1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	pr.Status.StartTime = &metav1.Time{Time: started}
+	pr.Status.CompletionTime = &metav1.Time{Time: started.Add(time.Minute)}
+	pr.Status.Conditions = duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: status, Message: message}}
+	return pr
+}
+
+func taskRun(namespace, name, pod string, steps ...string) *pipelinev1.TaskRun {
+	tr := &pipelinev1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	tr.Status.PodName = pod
+	for _, s := range steps {
+		tr.Status.Steps = append(tr.Status.Steps, pipelinev1.StepState{Name: s, Container: "step-" + s})
+	}
+	return tr
+}
@@ -124,12 +124,17 @@ func (pp *PipelinesProvider) createLocalPACResources(ctx context.Context, f fn.F
 		labels = pp.decorator.UpdateLabels(f, labels)
 	}
 
+	builderImage, err := getBuilderImage(f, pp.builderPolicies)
+	if err != nil {
+		return err
+	}
+
 	err = createPipelineTemplatePAC(f, labels)
 	if err != nil {
 		return err
 	}
 
-	err = createPipelineRunTemplatePAC(f, labels)
+	err = createPipelineRunTemplatePAC(f, labels, builderImage)
 	if err != nil {
 		return err
 	}
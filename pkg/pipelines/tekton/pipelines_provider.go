@@ -19,7 +19,6 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
-	gitignore "github.com/sabhiram/go-gitignore"
 	"github.com/tektoncd/cli/pkg/pipelinerun"
 	"github.com/tektoncd/cli/pkg/taskrun"
 	v1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
@@ -29,12 +28,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8slabels "k8s.io/apimachinery/pkg/labels"
 
+	"knative.dev/func/pkg/builders"
 	"knative.dev/func/pkg/docker"
 	fn "knative.dev/func/pkg/functions"
 	"knative.dev/func/pkg/k8s"
 	fnlabels "knative.dev/func/pkg/k8s/labels"
 	"knative.dev/func/pkg/knative"
 	"knative.dev/func/pkg/oci"
+	"knative.dev/func/pkg/progress"
 	"knative.dev/pkg/apis"
 )
 
@@ -54,9 +55,11 @@ type pacURLCallback = func() (string, error)
 
 type PipelinesProvider struct {
 	verbose             bool
+	plain               bool
 	getPacURL           pacURLCallback
 	credentialsProvider oci.CredentialsProvider
 	decorator           PipelineDecorator
+	builderPolicies     map[string]builders.Policy
 }
 
 func WithCredentialsProvider(credentialsProvider oci.CredentialsProvider) Opt {
@@ -65,6 +68,15 @@ func WithCredentialsProvider(credentialsProvider oci.CredentialsProvider) Opt {
 	}
 }
 
+// WithPlainProgress reports the progress of the upload of a function's
+// source as a summary of each phase rather than redrawing it in place, even
+// on a terminal.
+func WithPlainProgress(plain bool) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.plain = plain
+	}
+}
+
 func WithVerbose(verbose bool) Opt {
 	return func(pp *PipelinesProvider) {
 		pp.verbose = verbose
@@ -77,6 +89,14 @@ func WithPipelineDecorator(decorator PipelineDecorator) Opt {
 	}
 }
 
+// WithBuilderPolicies restricts the builder images of on-cluster builds, and
+// provides defaults, by the policy of each builder as do local builds.
+func WithBuilderPolicies(policies map[string]builders.Policy) Opt {
+	return func(pp *PipelinesProvider) {
+		pp.builderPolicies = policies
+	}
+}
+
 func WithPacURLCallback(getPacURL pacURLCallback) Opt {
 	return func(pp *PipelinesProvider) {
 		pp.getPacURL = getPacURL
@@ -153,6 +173,11 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		labels = pp.decorator.UpdateLabels(f, labels)
 	}
 
+	builderImage, err := getBuilderImage(f, pp.builderPolicies)
+	if err != nil {
+		return "", f, err
+	}
+
 	err = createPipelinePersistentVolumeClaim(ctx, f, namespace, labels)
 	if err != nil {
 		return "", f, err
@@ -162,10 +187,14 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		// Use direct upload to PVC if Git is not set up.
 		content := sourcesAsTarStream(f)
 		defer content.Close()
-		err = k8s.UploadToVolume(ctx, content, getPipelinePvcName(f), namespace)
+		transfer := progress.NewTransfer(os.Stderr, "uploading source",
+			progress.WithTotal(sourcesSize(f)),
+			progress.WithInteractive(!pp.plain && progress.IsTerminal(os.Stderr)))
+		err = k8s.UploadToVolume(ctx, io.TeeReader(content, transfer), getPipelinePvcName(f), namespace)
 		if err != nil {
 			return "", f, fmt.Errorf("cannot upload sources to the PVC: %w", err)
 		}
+		transfer.Finish()
 	}
 
 	err = createAndApplyPipelineTemplate(f, namespace, labels)
@@ -205,7 +234,7 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		return "", f, fmt.Errorf("problem in creating secret: %v", err)
 	}
 
-	err = createAndApplyPipelineRunTemplate(f, namespace, labels)
+	err = createAndApplyPipelineRunTemplate(f, namespace, labels, builderImage)
 	if err != nil {
 		return "", f, fmt.Errorf("problem in creating pipeline run: %v", err)
 	}
@@ -233,6 +262,15 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 		return "", f, fmt.Errorf("problem in retriving pipeline run status: %v", err)
 	}
 
+	// Retained such that they can be read with 'func build logs --remote'
+	// once the pods of the build are removed.
+	if kc, err := k8s.NewKubernetesClientset(); err == nil {
+		err = retainBuildLogs(ctx, client, kc, f, newestPipelineRun, namespace, labels)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot retain the build logs: %v\n", err)
+		}
+	}
+
 	if newestPipelineRun.Status.GetCondition(apis.ConditionSucceeded).Status == corev1.ConditionFalse {
 		message := getFailedPipelineRunLog(ctx, client, newestPipelineRun, namespace)
 		return "", f, fmt.Errorf("function pipeline run has failed with message: \n\n%s", message)
@@ -261,20 +299,33 @@ func (pp *PipelinesProvider) Run(ctx context.Context, f fn.Function) (string, fn
 	return ksvc.Status.URL.String(), f, nil
 }
 
-// Creates tar stream with the function sources as they were in "./source" directory.
-func sourcesAsTarStream(f fn.Function) *io.PipeReader {
-	ignored := func(p string) bool { return strings.HasPrefix(p, ".git") }
-	if gi, err := gitignore.CompileIgnoreFile(filepath.Join(f.Root, ".gitignore")); err == nil {
-		ignored = func(p string) bool {
-			if strings.HasPrefix(p, ".git") {
-				return true
-			}
-			return gi.MatchesPath(p)
-		}
+// sourcesSize is the approximate size of the tar stream of the function's
+// sources: the total size of the files of its build context, or zero if it
+// can not be determined.
+func sourcesSize(f fn.Function) (size int64) {
+	ignorer, err := fn.NewIgnorer(f.Root)
+	if err != nil {
+		return 0
 	}
+	_ = ignorer.Walk(func(_ string, fi fs.FileInfo, err error) error {
+		if err == nil && fi.Mode().IsRegular() {
+			size += fi.Size()
+		}
+		return nil
+	})
+	return
+}
 
+// Creates tar stream with the function sources as they were in "./source" directory.
+func sourcesAsTarStream(f fn.Function) *io.PipeReader {
 	pr, pw := io.Pipe()
 
+	ignorer, err := fn.NewIgnorer(f.Root)
+	if err != nil {
+		_ = pw.CloseWithError(fmt.Errorf("cannot determine the function's build context: %w", err))
+		return pr
+	}
+
 	const nobodyID = 65534
 
 	const up = ".." + string(os.PathSeparator)
@@ -294,7 +345,7 @@ func sourcesAsTarStream(f fn.Function) *io.PipeReader {
 			_ = pw.CloseWithError(fmt.Errorf("error while creating tar stream from sources: %w", err))
 		}
 
-		err = filepath.Walk(f.Root, func(p string, fi fs.FileInfo, err error) error {
+		err = ignorer.Walk(func(p string, fi fs.FileInfo, err error) error {
 			if err != nil {
 				return fmt.Errorf("error traversing function directory: %w", err)
 			}
@@ -308,10 +359,6 @@ func sourcesAsTarStream(f fn.Function) *io.PipeReader {
 				return nil
 			}
 
-			if ignored(relp) {
-				return nil
-			}
-
 			lnk := ""
 			if fi.Mode()&fs.ModeSymlink != 0 {
 				lnk, err = os.Readlink(p)
@@ -403,6 +450,7 @@ func (pp *PipelinesProvider) removeClusterResources(ctx context.Context, f fn.Fu
 		k8s.DeleteSecrets,
 		k8s.DeletePersistentVolumeClaims,
 		deletePACRepositories,
+		deleteBuildLogs,
 	}
 
 	wg.Add(len(deleteFunctions))
@@ -39,17 +39,22 @@ func deletePipelineRuns(ctx context.Context, namespace string, listOptions metav
 	return client.PipelineRuns(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
 }
 
-// guilderImage returns the builder image to use when building the Function
-// with the Pack strategy if it can be calculated (the Function has a defined
-// language runtime.  Errors are checked elsewhere, so at this level they
-// manifest as an inability to get a builder image = empty string.
-func getBuilderImage(f fn.Function) (name string) {
+// getBuilderImage returns the builder image to use when building the
+// Function with its builder (s2i or pack), chosen as are those of local
+// builds: subject to the builder's policy, whose defaults take precedence
+// over the builder's own.  ErrBuilderNotAllowed is returned should the image
+// chosen not be allowed.  Other errors are checked elsewhere, so at this
+// level they manifest as an inability to get a builder image = empty string.
+func getBuilderImage(f fn.Function, policies map[string]builders.Policy) (string, error) {
+	builder, defaults := builders.Pack, buildpacks.DefaultBuilderImages
 	if f.Build.Builder == builders.S2I {
-		name, _ = s2i.BuilderImage(f, builders.S2I)
-	} else {
-		name, _ = buildpacks.BuilderImage(f, builders.Pack)
+		builder, defaults = builders.S2I, s2i.DefaultBuilderImages
+	}
+	name, err := policies[builder].Image(f, builder, defaults)
+	if errors.As(err, &builders.ErrBuilderNotAllowed{}) {
+		return "", err
 	}
-	return
+	return name, nil
 }
 
 func getPipelineName(f fn.Function) string {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
@@ -106,6 +107,7 @@ type templateData struct {
 	FunctionImage string
 	Registry      string
 	BuilderImage  string
+	RunImage      string
 	BuildEnvs     []string
 
 	PipelineName    string
@@ -137,6 +139,14 @@ type templateData struct {
 
 	// TLS verification for registry operations
 	TlsVerify string
+
+	// Parts of the builtin Pipeline, rendered for a custom Pipeline template
+	// (build.pipelineTemplate or .tekton/pipeline.yaml) to include: the
+	// items of its spec.params, spec.tasks and spec.workspaces, indented to
+	// follow the respective key of the template.
+	Params     string
+	Tasks      string
+	Workspaces string
 }
 
 // createPipelineTemplatePAC creates a Pipeline template used for PAC on-cluster build
@@ -177,12 +187,17 @@ func createPipelineTemplatePAC(f fn.Function, labels map[string]string) error {
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
 
+	template, _, err := pipelineTemplate(f, "", template, &data)
+	if err != nil {
+		return err
+	}
+
 	return createResource(f.Root, pipelineFileNamePAC, template, data)
 }
 
 // createPipelineRunTemplatePAC creates a PipelineRun template used for PAC on-cluster build
 // it creates the resource in the project directory
-func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error {
+func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string, builderImage string) error {
 	contextDir := f.Build.Git.ContextDir
 	if contextDir == "" && f.Build.Builder == builders.S2I {
 		// TODO(lkingland): could instead update S2I to interpret empty string
@@ -227,7 +242,8 @@ func createPipelineRunTemplatePAC(f fn.Function, labels map[string]string) error
 		ContextDir:    contextDir,
 		FunctionImage: image,
 		Registry:      f.Registry,
-		BuilderImage:  getBuilderImage(f),
+		BuilderImage:  builderImage,
+		RunImage:      f.Build.RunImage,
 		BuildEnvs:     buildEnvs,
 
 		PipelineName:    getPipelineName(f),
@@ -386,12 +402,17 @@ func createAndApplyPipelineTemplate(f fn.Function, namespace string, labels map[
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
 
-	return createAndApplyResource(f.Root, pipelineFileName, template, "pipeline", getPipelineName(f), namespace, data)
+	template, filePath, err := pipelineTemplate(f, path.Join(f.Root, resourcesDirectory, pipelineFileName), template, &data)
+	if err != nil {
+		return err
+	}
+
+	return applyResource(filePath, template, "pipeline", getPipelineName(f), namespace, data)
 }
 
 // createAndApplyPipelineRunTemplate creates and applies PipelineRun template for a standard on-cluster build
 // all resources are created on the fly, if there's a PipelineRun defined in the project directory, it is used instead
-func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels map[string]string) error {
+func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels map[string]string, builderImage string) error {
 	contextDir := f.Build.Git.ContextDir
 	if contextDir == "" && f.Build.Builder == builders.S2I {
 		// TODO(lkingland): could instead update S2I to interpret empty string
@@ -431,7 +452,8 @@ func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels m
 		ContextDir:    contextDir,
 		FunctionImage: f.Deploy.Image,
 		Registry:      f.Registry,
-		BuilderImage:  getBuilderImage(f),
+		BuilderImage:  builderImage,
+		RunImage:      f.Build.RunImage,
 		BuildEnvs:     buildEnvs,
 
 		PipelineName:    getPipelineName(f),
@@ -456,33 +478,39 @@ func createAndApplyPipelineRunTemplate(f fn.Function, namespace string, labels m
 		return builders.ErrBuilderNotSupported{Builder: f.Build.Builder}
 	}
 
-	return createAndApplyResource(f.Root, pipelineFileName, template, "pipelinerun", getPipelineRunGenerateName(f), namespace, data)
+	return createAndApplyResource(f.Root, pipelineRunFilenane, template, "pipelinerun", getPipelineRunGenerateName(f), namespace, data)
 }
 
 // allows simple mocking in unit tests
 var manifestivalClient = k8s.GetManifestivalClient
 
 // createAndApplyResource tries to create and apply a resource to the k8s cluster from the input template and data,
-// if there's the same resource already created in the project directory, it is used instead
+// if there's the same resource already created in the project directory, it is used as the template instead
 func createAndApplyResource(projectRoot, fileName, fileTemplate, kind, resourceName, namespace string, data interface{}) error {
-	var source manifestival.Source
-
 	filePath := path.Join(projectRoot, resourcesDirectory, fileName)
-	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
-		source = manifestival.Path(filePath)
-	} else {
-		tmpl, err := template.New("template").Parse(fileTemplate)
-		if err != nil {
-			return fmt.Errorf("error parsing template: %v", err)
-		}
+	if b, err := os.ReadFile(filePath); err == nil {
+		fileTemplate = string(b)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("error reading template: %v", err)
+	}
+	return applyResource(filePath, fileTemplate, kind, resourceName, namespace, data)
+}
 
-		var buf bytes.Buffer
-		err = tmpl.Execute(&buf, data)
-		if err != nil {
-			return fmt.Errorf("error executing template: %v", err)
-		}
-		source = manifestival.Reader(&buf)
+// applyResource renders the template with the data and applies the resulting
+// resource to the k8s cluster.  The file path is that of the template, if it
+// is of the project, to which errors refer.
+func applyResource(filePath, fileTemplate, kind, resourceName, namespace string, data interface{}) error {
+	tmpl, err := template.New("template").Parse(fileTemplate)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return fmt.Errorf("error executing template: %v", err)
 	}
+	source := manifestival.Reader(&buf)
 
 	client, err := manifestivalClient()
 	if err != nil {
@@ -523,3 +551,74 @@ func createAndApplyResource(projectRoot, fileName, fileTemplate, kind, resourceN
 
 	return m.Apply()
 }
+
+// pipelineTemplate returns the template of the function's Pipeline, and the
+// path of the file it is of: that at build.pipelineTemplate, if defined, else
+// that at the overlay path, if given and extant, else the builtin.  The data
+// is given the Params, Tasks and Workspaces of the builtin, for a custom
+// template to include those with which func builds and deploys the function.
+func pipelineTemplate(f fn.Function, overlay, builtin string, data *templateData) (string, string, error) {
+	filePath := overlay
+	if f.Build.PipelineTemplate != "" {
+		filePath = f.Build.PipelineTemplate
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(f.Root, filePath)
+		}
+	}
+	if filePath == "" {
+		return builtin, overlay, nil
+	}
+	b, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) && f.Build.PipelineTemplate == "" {
+		return builtin, overlay, nil
+	} else if err != nil {
+		return "", "", fmt.Errorf("error reading pipeline template: %v", err)
+	}
+
+	tmpl, err := template.New("builtin").Parse(builtin)
+	if err != nil {
+		return "", "", fmt.Errorf("error parsing pipeline template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("error executing pipeline template: %v", err)
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return "", "", fmt.Errorf("error parsing pipeline template: %v", err)
+	}
+	spec := mappingValue(doc.Content[0], "spec")
+	for _, part := range []struct {
+		key   string
+		field *string
+	}{
+		{"params", &data.Params},
+		{"tasks", &data.Tasks},
+		{"workspaces", &data.Workspaces},
+	} {
+		var out bytes.Buffer
+		enc := yaml.NewEncoder(&out)
+		enc.SetIndent(2)
+		if err = enc.Encode(mappingValue(spec, part.key)); err != nil {
+			return "", "", err
+		}
+		if err = enc.Close(); err != nil {
+			return "", "", err
+		}
+		*part.field = strings.ReplaceAll(strings.TrimSpace(out.String()), "\n", "\n    ")
+	}
+	return string(b), filePath, nil
+}
+
+// mappingValue returns the value of the key of a YAML mapping node, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
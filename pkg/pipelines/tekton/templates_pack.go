@@ -37,6 +37,10 @@ spec:
     - description: Builder image to be used
       name: builderImage
       type: string
+    - description: Run image to be used instead of the builder's default
+      name: runImage
+      type: string
+      default: ""
     - description: Environment variables to set during build time
       name: buildEnvs
       type: array
@@ -61,6 +65,8 @@ spec:
           value: $(params.contextDir)
         - name: BUILDER_IMAGE
           value: $(params.builderImage)
+        - name: RUN_IMAGE
+          value: $(params.runImage)
         - name: ENV_VARS
           value:
             - '$(params.buildEnvs[*])'
@@ -126,6 +132,8 @@ spec:
       value: {{.Registry}}
     - name: builderImage
       value: {{.BuilderImage}}
+    - name: runImage
+      value: "{{.RunImage}}"
     - name: buildEnvs
       value:
         {{range .BuildEnvs -}}
@@ -189,6 +197,8 @@ spec:
       value: {{.Registry}}
     - name: builderImage
       value: {{.BuilderImage}}
+    - name: runImage
+      value: "{{.RunImage}}"
     - name: buildEnvs
       value:
         {{range .BuildEnvs -}}
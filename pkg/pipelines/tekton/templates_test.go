@@ -1,11 +1,15 @@
 package tekton
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/manifestival/manifestival"
 	"github.com/manifestival/manifestival/fake"
+	"gopkg.in/yaml.v3"
 
 	"knative.dev/func/pkg/builders"
 	fn "knative.dev/func/pkg/functions"
@@ -147,7 +151,11 @@ func Test_createPipelineRunTemplatePAC(t *testing.T) {
 			f.Image = "docker.io/alice/" + f.Name
 			f.Registry = TestRegistry
 
-			err = createPipelineRunTemplatePAC(f, make(map[string]string))
+			builderImage, err := getBuilderImage(f, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = createPipelineRunTemplatePAC(f, make(map[string]string), builderImage)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("createPipelineRunTemplate() error = %v, wantErr %v", err, tt.wantErr)
@@ -316,9 +324,125 @@ func Test_createAndApplyPipelineRunTemplate(t *testing.T) {
 			f.Image = "docker.io/alice/" + f.Name
 			f.Registry = TestRegistry
 
-			if err := createAndApplyPipelineRunTemplate(f, tt.namespace, tt.labels); (err != nil) != tt.wantErr {
+			builderImage, err := getBuilderImage(f, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := createAndApplyPipelineRunTemplate(f, tt.namespace, tt.labels, builderImage); (err != nil) != tt.wantErr {
 				t.Errorf("createAndApplyPipelineRunTemplate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+// Test_pipelineTemplate ensures that a Pipeline template of the function is
+// rendered in lieu of the builtin, including the parameters, tasks and
+// workspaces of the builtin alongside its own.
+func Test_pipelineTemplate(t *testing.T) {
+	root := t.TempDir()
+	const custom = `apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: {{.PipelineName}}
+spec:
+  params:
+    {{.Params}}
+    - name: slackChannel
+      type: string
+      default: deploys
+  tasks:
+    {{.Tasks}}
+    - name: notify
+      runAfter:
+        - deploy
+      taskRef:
+        name: send-to-channel-slack
+  workspaces:
+    {{.Workspaces}}
+`
+	if err := os.MkdirAll(filepath.Join(root, "ci"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "ci", "pipeline.yaml"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := fn.Function{Root: root, Name: "myfn", Build: fn.BuildSpec{Builder: builders.Pack, PipelineTemplate: "ci/pipeline.yaml"}}
+	if err := createPipelineTemplatePAC(f, map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(root, resourcesDirectory, pipelineFileNamePAC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type named struct {
+		Name string `yaml:"name"`
+	}
+	var p struct {
+		Metadata named `yaml:"metadata"`
+		Spec     struct {
+			Params     []named `yaml:"params"`
+			Tasks      []named `yaml:"tasks"`
+			Workspaces []named `yaml:"workspaces"`
+		} `yaml:"spec"`
+	}
+	if err = yaml.Unmarshal(b, &p); err != nil {
+		t.Fatalf("rendered template is not valid YAML: %v\n%s", err, b)
+	}
+	if p.Metadata.Name != getPipelineName(f) {
+		t.Errorf("expected pipeline %q, got %q", getPipelineName(f), p.Metadata.Name)
+	}
+	names := func(nn []named) (s []string) {
+		for _, n := range nn {
+			s = append(s, n.Name)
+		}
+		return
+	}
+	if got, want := names(p.Spec.Tasks), []string{"fetch-sources", "scaffold", "build", "deploy", "notify"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected tasks %v, got %v", want, got)
+	}
+	if got := names(p.Spec.Params); len(got) == 0 || got[0] != "gitRepository" || got[len(got)-1] != "slackChannel" {
+		t.Errorf("expected the builtin params followed by those of the template, got %v", got)
+	}
+	if got := names(p.Spec.Workspaces); len(got) != 3 || got[0] != "source-workspace" {
+		t.Errorf("expected the builtin workspaces, got %v", got)
+	}
+
+	// A missing template, explicitly defined, is an error.
+	f.Build.PipelineTemplate = "missing.yaml"
+	if _, _, err = pipelineTemplate(f, "", packPipelineTemplate, &templateData{}); err == nil {
+		t.Error("expected an error for a missing pipeline template")
+	}
+}
+
+// Test_getBuilderImage ensures that the builder image of an on-cluster build
+// is chosen subject to the builder's policy, as is that of a local build.
+func Test_getBuilderImage(t *testing.T) {
+	f := fn.Function{Runtime: "go", Build: fn.BuildSpec{Builder: builders.S2I}}
+
+	// Defaults of the policy take precedence over those of the builder
+	policies := map[string]builders.Policy{
+		builders.S2I: {Defaults: map[string]string{"go": "registry.example.com/s2i/go:1"}},
+	}
+	image, err := getBuilderImage(f, policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image != "registry.example.com/s2i/go:1" {
+		t.Errorf("expected the default of the policy, got %q", image)
+	}
+
+	// The image chosen must be allowed by the policy
+	policies = map[string]builders.Policy{
+		builders.S2I: {Allowed: []string{"registry.example.com/"}},
+	}
+	if _, err = getBuilderImage(f, policies); !errors.As(err, &builders.ErrBuilderNotAllowed{}) {
+		t.Errorf("expected ErrBuilderNotAllowed, got %v", err)
+	}
+
+	// The policy of another builder does not apply
+	f.Build.Builder = builders.Pack
+	if _, err = getBuilderImage(f, policies); err != nil {
+		t.Errorf("expected the policy of s2i not to apply to pack, got %v", err)
+	}
+}
@@ -14,6 +14,8 @@ var (
 	ErrRuntimeRequired = errors.New("runtime is required to build")
 
 	ErrBuilpacksNotSupported = errors.New("additional Buildpacks are not supported for on cluster build")
+
+	ErrRunImageNotSupported = errors.New("a run image is not supported for on cluster build with the s2i builder")
 )
 
 type ErrRuntimeNotSupported struct {
@@ -34,6 +36,9 @@ func validatePipeline(f fn.Function) error {
 			return ErrBuilpacksNotSupported
 		}
 	case builders.S2I:
+		if f.Build.RunImage != "" {
+			return ErrRunImageNotSupported
+		}
 		_, err := s2i.BuilderImage(f, builders.S2I)
 		return err
 	default:
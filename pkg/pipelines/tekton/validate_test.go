@@ -61,6 +61,16 @@ func Test_validatePipeline(t *testing.T) {
 			function: fn.Function{Build: fn.BuildSpec{Builder: builders.S2I}, Runtime: "node"},
 			wantErr:  false,
 		},
+		{
+			name:     "Supported runtime - pack builder - with run image",
+			function: fn.Function{Build: fn.BuildSpec{Builder: builders.Pack, RunImage: "example.com/run"}, Runtime: "node"},
+			wantErr:  false,
+		},
+		{
+			name:     "Supported runtime - s2i builder - with run image",
+			function: fn.Function{Build: fn.BuildSpec{Builder: builders.S2I, RunImage: "example.com/run"}, Runtime: "node"},
+			wantErr:  true,
+		},
 		{
 			name:     "Supported runtime - pack builder - with additional Buildpacks",
 			function: fn.Function{Build: fn.BuildSpec{Builder: builders.Pack, Buildpacks: testBuildpacks}, Runtime: "node"},
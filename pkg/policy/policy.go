@@ -0,0 +1,226 @@
+/*
+Package policy evaluates organizational deployment policies, written in Rego
+(https://www.openpolicyagent.org/docs/latest/policy-language/), against the
+manifests rendered for a function prior to their being applied to a cluster.
+
+Each policy module declares a package and any number of 'deny' rules which
+produce a message for each violation.  Each manifest is evaluated as 'input',
+for example:
+
+	package func.limits
+
+	deny contains msg if {
+		input.kind == "Service"
+		some c in input.spec.template.spec.containers
+		not c.resources.limits.memory
+		msg := "containers must set a memory limit (set options.resources.limits.memory in func.yaml)"
+	}
+*/
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// Extension of policy module files.
+const Extension = ".rego"
+
+// Violation of a policy by a manifest.
+type Violation struct {
+	// Package of the policy which was violated, for example "func.limits"
+	Package string
+	// Kind and Name of the violating manifest
+	Kind string
+	Name string
+	// Message produced by the policy.
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%v/%v: %v (policy %v)", v.Kind, v.Name, v.Message, v.Package)
+}
+
+// ErrViolations is returned when manifests violate one or more policies.
+type ErrViolations []Violation
+
+func (e ErrViolations) Error() string {
+	b := strings.Builder{}
+	if len(e) == 1 {
+		b.WriteString("deployment violates 1 policy:")
+	} else {
+		fmt.Fprintf(&b, "deployment violates %d policies:", len(e))
+	}
+	for _, v := range e {
+		fmt.Fprintf(&b, "\n  - %v", v)
+	}
+	return b.String()
+}
+
+// Engine evaluates the policies found in a set of paths.  Policies are
+// loaded and compiled once, on first evaluation.
+type Engine struct {
+	paths []string
+
+	once     sync.Once
+	err      error
+	packages []string
+	queries  map[string]rego.PreparedEvalQuery // deny query by package
+}
+
+// New policy engine which loads policy modules from the given paths.  Each
+// path may be a single module or a directory, which is searched
+// recursively.  Paths which do not exist are ignored.
+func New(paths ...string) *Engine {
+	return &Engine{paths: paths}
+}
+
+// Packages returns the names of the policy packages loaded, which is empty
+// when no policies were found.
+func (e *Engine) Packages() ([]string, error) {
+	e.once.Do(e.load)
+	return e.packages, e.err
+}
+
+// Evaluate the manifests against all policies, returning ErrViolations if
+// any are denied.  An engine without policies permits all manifests.
+func (e *Engine) Evaluate(ctx context.Context, manifests ...map[string]any) error {
+	packages, err := e.Packages()
+	if err != nil {
+		return err
+	}
+	if len(packages) == 0 {
+		return nil
+	}
+	var violations ErrViolations
+	for _, m := range manifests {
+		kind, _ := m["kind"].(string)
+		name, _ := nested(m, "metadata", "name").(string)
+		for _, pkg := range packages {
+			messages, err := e.deny(ctx, pkg, m)
+			if err != nil {
+				return fmt.Errorf("error evaluating policy %v: %w", pkg, err)
+			}
+			for _, msg := range messages {
+				violations = append(violations, Violation{Package: pkg, Kind: kind, Name: name, Message: msg})
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// deny evaluates the deny rules of the package against the input, returning
+// the messages of the violations, sorted.
+func (e *Engine) deny(ctx context.Context, pkg string, input map[string]any) (messages []string, err error) {
+	rs, err := e.queries[pkg].Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return
+	}
+	for _, r := range rs {
+		for _, x := range r.Expressions {
+			values, ok := x.Value.([]any) // sets are returned as arrays
+			if !ok {
+				continue
+			}
+			for _, v := range values {
+				messages = append(messages, message(v))
+			}
+		}
+	}
+	sort.Strings(messages)
+	return
+}
+
+// message of a violation.  Rules may produce strings or objects with a
+// 'msg' member (as with conftest); anything else is rendered as JSON.
+func message(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// load and compile all modules found in the engine's paths.
+func (e *Engine) load() {
+	var modules []*ast.Module
+	for _, root := range e.paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || filepath.Ext(path) != Extension || strings.HasSuffix(path, "_test"+Extension) {
+				return nil
+			}
+			src, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			m, err := ast.ParseModuleWithOpts(path, string(src), ast.ParserOptions{RegoVersion: ast.RegoV1})
+			if err != nil {
+				return fmt.Errorf("invalid policy: %w", err)
+			}
+			modules = append(modules, m)
+			pkg := strings.TrimPrefix(m.Package.Path.String(), "data.")
+			if !slices.Contains(e.packages, pkg) {
+				e.packages = append(e.packages, pkg)
+			}
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			e.err = err
+			return
+		}
+	}
+	sort.Strings(e.packages)
+
+	e.queries = make(map[string]rego.PreparedEvalQuery, len(e.packages))
+	for _, pkg := range e.packages {
+		opts := []func(*rego.Rego){
+			rego.Query("data." + pkg + ".deny"),
+			rego.SetRegoVersion(ast.RegoV1),
+		}
+		for _, m := range modules {
+			opts = append(opts, rego.ParsedModule(m))
+		}
+		if e.queries[pkg], e.err = rego.New(opts...).PrepareForEval(context.Background()); e.err != nil {
+			e.err = fmt.Errorf("invalid policy %v: %w", pkg, e.err)
+			return
+		}
+	}
+}
+
+func nested(m map[string]any, keys ...string) any {
+	var v any = m
+	for _, k := range keys {
+		mm, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = mm[k]
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/func/pkg/policy"
+)
+
+const limitsPolicy = `package func.limits
+
+deny contains msg if {
+	some c in input.spec.template.spec.containers
+	not c.resources.limits.memory
+	msg := "containers must set a memory limit"
+}
+`
+
+const tagsPolicy = `package func.tags
+
+deny contains {"msg": msg} if {
+	some c in input.spec.template.spec.containers
+	endswith(c.image, ":latest")
+	msg := sprintf("image %v must not use the :latest tag", [c.image])
+}
+`
+
+func service(image, memory string) map[string]any {
+	container := map[string]any{"image": image}
+	if memory != "" {
+		container["resources"] = map[string]any{"limits": map[string]any{"memory": memory}}
+	}
+	return map[string]any{
+		"apiVersion": "serving.knative.dev/v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": "myfunc"},
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"containers": []any{container},
+		}}},
+	}
+}
+
+func writePolicies(t *testing.T, policies map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, src := range policies {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestEngine_Evaluate ensures manifests are evaluated against policies
+// loaded from multiple paths, and that violations identify the policy and
+// the offending manifest.
+func TestEngine_Evaluate(t *testing.T) {
+	global := writePolicies(t, map[string]string{"limits.rego": limitsPolicy})
+	repo := writePolicies(t, map[string]string{"nested/tags.rego": tagsPolicy, "README.md": "not a policy"})
+	engine := policy.New(global, repo, filepath.Join(t.TempDir(), "nonexistent"))
+
+	packages, err := engine.Packages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(packages, ",") != "func.limits,func.tags" {
+		t.Fatalf("unexpected packages %v", packages)
+	}
+
+	if err = engine.Evaluate(context.Background(), service("example.com/myfunc:v1", "256Mi")); err != nil {
+		t.Fatalf("expected compliant manifest to pass. %v", err)
+	}
+
+	err = engine.Evaluate(context.Background(), service("example.com/myfunc:latest", ""))
+	var violations policy.ErrViolations
+	if !errors.As(err, &violations) {
+		t.Fatalf("expected violations, got %v", err)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", violations)
+	}
+	expected := policy.Violation{Package: "func.tags", Kind: "Service", Name: "myfunc",
+		Message: "image example.com/myfunc:latest must not use the :latest tag"}
+	if violations[1] != expected {
+		t.Fatalf("unexpected violation %v", violations[1])
+	}
+}
+
+// TestEngine_Empty ensures that no policies permits everything.
+func TestEngine_Empty(t *testing.T) {
+	if err := policy.New(t.TempDir()).Evaluate(context.Background(), service("example.com/myfunc:latest", "")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestEngine_Invalid ensures invalid policies are reported rather than
+// silently ignored.
+func TestEngine_Invalid(t *testing.T) {
+	dir := writePolicies(t, map[string]string{"broken.rego": "package func.broken\n\ndeny contains msg if {"})
+	if err := policy.New(dir).Evaluate(context.Background(), service("example.com/myfunc:v1", "")); err == nil {
+		t.Fatal("expected an invalid policy to error")
+	}
+}
@@ -0,0 +1,207 @@
+// Package progress reports the progress of long-running transfers, such as
+// pushing an image or uploading a function's source to the cluster.
+//
+// On an interactive terminal a transfer is drawn as a single line which is
+// redrawn in place, showing the amount transferred, the total size (if known)
+// and the current rate.  Otherwise, such as in CI, a line is written as the
+// transfer starts, as each quarter completes, and as a summary upon completion.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// redrawInterval is the minimum time between redraws of an interactive
+// transfer.
+const redrawInterval = 100 * time.Millisecond
+
+// barWidth is the number of characters of an interactive progress bar.
+const barWidth = 24
+
+// Transfer reports the progress of a single transfer.  It is safe for
+// concurrent use.  Its Write method counts the bytes written, such that it may
+// be used with an io.TeeReader or io.MultiWriter when the transfer is a
+// stream.
+type Transfer struct {
+	out         io.Writer
+	description string
+	interactive bool
+	now         func() time.Time
+
+	mu       sync.Mutex
+	started  time.Time
+	drawn    time.Time // time of the last interactive draw
+	total    int64     // bytes to transfer, or 0 if unknown
+	complete int64     // bytes transferred
+	quarter  int64     // last quarter completed, reported non-interactively
+	finished bool
+}
+
+// Option for a Transfer.
+type Option func(*Transfer)
+
+// WithInteractive overrides the default of drawing the transfer in place only
+// when its output is a terminal.
+func WithInteractive(i bool) Option {
+	return func(t *Transfer) {
+		t.interactive = i
+	}
+}
+
+// WithTotal bytes to be transferred, if known before the transfer begins.
+func WithTotal(n int64) Option {
+	return func(t *Transfer) {
+		t.total = n
+	}
+}
+
+// WithClock sets the source of the current time. Used in tests.
+func WithClock(now func() time.Time) Option {
+	return func(t *Transfer) {
+		t.now = now
+	}
+}
+
+// NewTransfer reporting to out the progress of the transfer described, for
+// example "pushing example.com/alice/f:latest".
+func NewTransfer(out io.Writer, description string, options ...Option) *Transfer {
+	t := &Transfer{
+		out:         out,
+		description: description,
+		interactive: IsTerminal(out),
+		now:         time.Now,
+		quarter:     -1,
+	}
+	for _, o := range options {
+		o(t)
+	}
+	return t
+}
+
+// IsTerminal returns true if w is a terminal.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// Update the transfer with the number of bytes transferred thus far of a
+// total.  A total of zero retains the total already known.
+func (t *Transfer) Update(complete, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if total > 0 {
+		t.total = total
+	}
+	t.complete = complete
+	t.report(false)
+}
+
+// Write counts len(p) bytes as transferred.
+func (t *Transfer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.complete += int64(len(p))
+	t.report(false)
+	return len(p), nil
+}
+
+// Finish the transfer, writing its summary.  Only the first call has effect.
+func (t *Transfer) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+	t.report(true)
+	t.finished = true
+}
+
+// report the current state of the transfer.  Expects the lock to be held.
+func (t *Transfer) report(final bool) {
+	if t.finished {
+		return
+	}
+	now := t.now()
+	if t.started.IsZero() {
+		t.started = now
+		if !t.interactive {
+			if t.total > 0 {
+				fmt.Fprintf(t.out, "%v: started (%v)\n", t.description, FormatBytes(t.total))
+			} else {
+				fmt.Fprintf(t.out, "%v: started\n", t.description)
+			}
+		}
+	}
+	elapsed := now.Sub(t.started)
+
+	if t.interactive {
+		if !final && now.Sub(t.drawn) < redrawInterval {
+			return
+		}
+		t.drawn = now
+		fmt.Fprintf(t.out, "\r%v", t.line(elapsed))
+		if final {
+			fmt.Fprintln(t.out)
+		}
+		return
+	}
+
+	if final {
+		fmt.Fprintf(t.out, "%v: done, %v in %v (%v)\n", t.description,
+			FormatBytes(t.complete), elapsed.Round(100*time.Millisecond), formatRate(t.complete, elapsed))
+		return
+	}
+	if t.total <= 0 {
+		return
+	}
+	// Quarters are reported as they complete, excluding the last, which is
+	// the summary.
+	if q := t.complete * 4 / t.total; q > t.quarter && q > 0 && q < 4 {
+		t.quarter = q
+		fmt.Fprintf(t.out, "%v: %d%% (%v of %v, %v)\n", t.description, q*25,
+			FormatBytes(t.complete), FormatBytes(t.total), formatRate(t.complete, elapsed))
+	}
+}
+
+// line drawn for an interactive transfer.
+func (t *Transfer) line(elapsed time.Duration) string {
+	rate := formatRate(t.complete, elapsed)
+	if t.total <= 0 {
+		return fmt.Sprintf("%v  %v  %v   ", t.description, FormatBytes(t.complete), rate)
+	}
+	complete := min(t.complete, t.total)
+	filled := int(complete * barWidth / t.total)
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	return fmt.Sprintf("%v  [%v] %3d%%  %v / %v  %v   ", t.description, bar,
+		complete*100/t.total, FormatBytes(complete), FormatBytes(t.total), rate)
+}
+
+// formatRate of n bytes over the given duration.
+func formatRate(n int64, d time.Duration) string {
+	if d <= 0 {
+		return "- B/s"
+	}
+	return FormatBytes(int64(float64(n)/d.Seconds())) + "/s"
+}
+
+// FormatBytes as a human-readable size using decimal units, for example
+// "12.3 MB".
+func FormatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,76 @@
+package progress_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"knative.dev/func/pkg/progress"
+)
+
+// clock which advances by one second each time it is read.
+func clock() func() time.Time {
+	t := time.Unix(0, 0)
+	return func() time.Time {
+		t = t.Add(time.Second)
+		return t
+	}
+}
+
+// TestTransfer_NonInteractive ensures that a transfer which is not
+// interactive is reported as a line at its start, as each quarter completes,
+// and as a summary.
+func TestTransfer_NonInteractive(t *testing.T) {
+	var out bytes.Buffer
+	tr := progress.NewTransfer(&out, "pushing", progress.WithInteractive(false), progress.WithClock(clock()))
+	for _, n := range []int64{0, 100, 250, 400, 900, 1000} {
+		tr.Update(n, 1000)
+	}
+	tr.Finish()
+	tr.Finish() // only the first has effect
+
+	expected := []string{
+		"pushing: started (1.0 kB)",
+		"pushing: 25% (250 B of 1.0 kB, 125 B/s)",
+		"pushing: 75% (900 B of 1.0 kB, 225 B/s)",
+		"pushing: done, 1.0 kB in 6s (166 B/s)",
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected output:\n%v", out.String())
+	}
+}
+
+// TestTransfer_Interactive ensures that an interactive transfer is drawn as a
+// single line, redrawn in place, with its size and rate.
+func TestTransfer_Interactive(t *testing.T) {
+	var out bytes.Buffer
+	tr := progress.NewTransfer(&out, "uploading", progress.WithInteractive(true), progress.WithClock(clock()))
+	if _, err := tr.Write(make([]byte, 2000)); err != nil {
+		t.Fatal(err)
+	}
+	tr.Finish()
+
+	if strings.Count(out.String(), "\n") != 1 || !strings.HasSuffix(out.String(), "\n") {
+		t.Fatalf("expected a single line, got %q", out.String())
+	}
+	last := out.String()[strings.LastIndex(out.String(), "\r")+1:]
+	if !strings.Contains(last, "uploading  2.0 kB  2.0 kB/s") {
+		t.Fatalf("unexpected final line %q", last)
+	}
+}
+
+// TestFormatBytes ensures sizes are formatted using decimal units.
+func TestFormatBytes(t *testing.T) {
+	for n, expected := range map[int64]string{
+		0:             "0 B",
+		999:           "999 B",
+		1000:          "1.0 kB",
+		12_345_678:    "12.3 MB",
+		3_000_000_000: "3.0 GB",
+	} {
+		if got := progress.FormatBytes(n); got != expected {
+			t.Errorf("expected %v to be %q, got %q", n, expected, got)
+		}
+	}
+}
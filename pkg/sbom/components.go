@@ -0,0 +1,203 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"golang.org/x/mod/modfile"
+)
+
+// Component of a function: a dependency declared by one of its manifests.
+type Component struct {
+	// Ecosystem of the component, its package URL type, such as "golang".
+	Ecosystem string
+	Name      string
+	// Version of the component, empty if not pinned by the manifest.
+	Version string
+}
+
+// PURL is the package URL of the component.
+func (c Component) PURL() string {
+	name := c.Name
+	if c.Ecosystem == "npm" && strings.HasPrefix(name, "@") {
+		name = "%40" + name[1:]
+	}
+	if c.Ecosystem == "maven" {
+		name = strings.Replace(name, ":", "/", 1)
+	}
+	purl := "pkg:" + c.Ecosystem + "/" + name
+	if c.Version != "" {
+		purl += "@" + c.Version
+	}
+	return purl
+}
+
+// manifests of each ecosystem, by the name of the file of the function's
+// root from which its components are read.  A lockfile is preferred over the
+// manifest it locks, being of the exact versions installed.
+var manifests = []struct {
+	file      string
+	ecosystem string
+	parse     func([]byte) ([]Component, error)
+}{
+	{"go.mod", "golang", parseGoMod},
+	{"package-lock.json", "npm", parsePackageLock},
+	{"requirements.txt", "pypi", parseRequirements},
+	{"Cargo.lock", "cargo", parseCargoLock},
+	{"pom.xml", "maven", parsePom},
+}
+
+// Components of the function at root, ordered by their package URLs.
+func Components(root string) (cc []Component, err error) {
+	seen := map[string]bool{}
+	for _, m := range manifests {
+		data, err := os.ReadFile(filepath.Join(root, m.file))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found, err := m.parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the dependencies of %v: %w", m.file, err)
+		}
+		for _, c := range found {
+			c.Ecosystem = m.ecosystem
+			if !seen[c.PURL()] {
+				seen[c.PURL()] = true
+				cc = append(cc, c)
+			}
+		}
+	}
+	sort.Slice(cc, func(i, j int) bool { return cc[i].PURL() < cc[j].PURL() })
+	return
+}
+
+// parseGoMod modules required, directly or indirectly.
+func parseGoMod(data []byte) (cc []Component, err error) {
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return
+	}
+	for _, r := range f.Require {
+		cc = append(cc, Component{Name: r.Mod.Path, Version: r.Mod.Version})
+	}
+	return
+}
+
+// parsePackageLock packages installed, of lockfile versions 2 and 3, or the
+// dependencies of those of version 1.
+func parsePackageLock(data []byte) (cc []Component, err error) {
+	var lock struct {
+		Packages map[string]struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Link    bool   `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err = json.Unmarshal(data, &lock); err != nil {
+		return
+	}
+	for path, p := range lock.Packages {
+		i := strings.LastIndex(path, "node_modules/")
+		if i < 0 || p.Link {
+			continue // the function itself, or a link to a workspace
+		}
+		name := p.Name
+		if name == "" {
+			name = path[i+len("node_modules/"):]
+		}
+		cc = append(cc, Component{Name: name, Version: p.Version})
+	}
+	if len(lock.Packages) == 0 {
+		for name, d := range lock.Dependencies {
+			cc = append(cc, Component{Name: name, Version: d.Version})
+		}
+	}
+	return
+}
+
+// requirementRE matches a requirement's name and, if pinned, its version.
+var requirementRE = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(==\s*([^\s;,]+))?`)
+
+// separatorsRE of the name of a Python package, normalized as a hyphen.
+var separatorsRE = regexp.MustCompile(`[-_.]+`)
+
+// parseRequirements of a pip requirements file.  Options, such as of indexes
+// or of other requirements files, are not followed.
+func parseRequirements(data []byte) (cc []Component, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		m := requirementRE.FindStringSubmatch(line)
+		if m == nil {
+			continue // such as a URL or path
+		}
+		// Names are normalized as are those of the index (PEP 503)
+		name := strings.ToLower(separatorsRE.ReplaceAllString(m[1], "-"))
+		cc = append(cc, Component{Name: name, Version: m[4]})
+	}
+	return cc, scanner.Err()
+}
+
+// parseCargoLock packages of a registry or repository, those of the function
+// itself having no source.
+func parseCargoLock(data []byte) (cc []Component, err error) {
+	var lock struct {
+		Package []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+			Source  string `toml:"source"`
+		} `toml:"package"`
+	}
+	if err = toml.Unmarshal(data, &lock); err != nil {
+		return
+	}
+	for _, p := range lock.Package {
+		if p.Source != "" {
+			cc = append(cc, Component{Name: p.Name, Version: p.Version})
+		}
+	}
+	return
+}
+
+// parsePom dependencies declared, as groupId:artifactId.  Versions given by
+// properties, or managed by a parent, are not resolved.
+func parsePom(data []byte) (cc []Component, err error) {
+	var pom struct {
+		Dependencies []struct {
+			GroupID    string `xml:"groupId"`
+			ArtifactID string `xml:"artifactId"`
+			Version    string `xml:"version"`
+		} `xml:"dependencies>dependency"`
+	}
+	if err = xml.Unmarshal(data, &pom); err != nil {
+		return
+	}
+	for _, d := range pom.Dependencies {
+		version := strings.TrimSpace(d.Version)
+		if strings.Contains(version, "${") {
+			version = ""
+		}
+		cc = append(cc, Component{Name: d.GroupID + ":" + d.ArtifactID, Version: version})
+	}
+	return
+}
@@ -0,0 +1,190 @@
+/*
+Package sbom generates software bills of materials of functions, as
+CycloneDX or SPDX JSON documents listing the dependencies declared by their
+manifests and lockfiles: go.mod, package-lock.json, requirements.txt,
+Cargo.lock and pom.xml.  Being read from the function's source, the SBOM is
+the same whichever builder builds its image.
+*/
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Opt is an option for the generator.
+type Opt func(*Generator)
+
+// WithToolVersion of func, recorded as that of the tool which generated the
+// SBOM.
+func WithToolVersion(v string) Opt {
+	return func(g *Generator) {
+		g.version = v
+	}
+}
+
+// Generator of SBOMs.  Implements fn.SBOMGenerator.
+type Generator struct {
+	version string
+	now     func() time.Time
+}
+
+// NewGenerator of SBOMs from the manifests of functions.
+func NewGenerator(opts ...Opt) *Generator {
+	g := &Generator{now: time.Now}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+// Generate the SBOM of the function in its format (f.Build.SBOM),
+// CycloneDX by default.
+func (g *Generator) Generate(ctx context.Context, f fn.Function, w io.Writer) error {
+	cc, err := Components(f.Root)
+	if err != nil {
+		return err
+	}
+	var doc any
+	switch f.Build.SBOM {
+	case fn.SBOMFormatCycloneDX, "":
+		doc = g.cycloneDX(f, cc)
+	case fn.SBOMFormatSPDX:
+		doc = g.spdx(f, cc)
+	default:
+		return fmt.Errorf("unsupported SBOM format %q", f.Build.SBOM)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// cdxDocument of CycloneDX, of specification version 1.5.
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []cdxComponent `json:"components"`
+	} `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// cycloneDX document of the function's components, each a dependency of
+// the function itself.
+func (g *Generator) cycloneDX(f fn.Function, cc []Component) cdxDocument {
+	doc := cdxDocument{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.NewString(),
+		Version:      1,
+		Components:   []cdxComponent{},
+	}
+	doc.Metadata.Timestamp = g.now().UTC().Format(time.RFC3339)
+	doc.Metadata.Tools.Components = []cdxComponent{{Type: "application", Name: "func", Version: g.version}}
+	doc.Metadata.Component = cdxComponent{Type: "application", BOMRef: f.Name, Name: f.Name}
+
+	root := cdxDependency{Ref: f.Name, DependsOn: []string{}}
+	for _, c := range cc {
+		doc.Components = append(doc.Components, cdxComponent{Type: "library", BOMRef: c.PURL(), Name: c.Name, Version: c.Version, PURL: c.PURL()})
+		root.DependsOn = append(root.DependsOn, c.PURL())
+	}
+	doc.Dependencies = []cdxDependency{root}
+	return doc
+}
+
+// spdxDocument of SPDX, of specification version 2.3.
+type spdxDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	Packages      []spdxPackage      `json:"packages"`
+	Relationships []spdxRelationship `json:"relationships"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// spdx document of the function, as a package described by the document,
+// and of its components, each a package upon which it depends.
+func (g *Generator) spdx(f fn.Function, cc []Component) spdxDocument {
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              f.Name,
+		DocumentNamespace: "https://knative.dev/func/spdx/" + f.Name + "-" + uuid.NewString(),
+		Packages:          []spdxPackage{{SPDXID: "SPDXRef-Function", Name: f.Name, DownloadLocation: "NOASSERTION"}},
+		Relationships:     []spdxRelationship{{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: "SPDXRef-Function"}},
+	}
+	creator := "Tool: func"
+	if g.version != "" {
+		creator += "-" + g.version
+	}
+	doc.CreationInfo.Created = g.now().UTC().Format(time.RFC3339)
+	doc.CreationInfo.Creators = []string{creator}
+
+	for i, c := range cc {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           id,
+			Name:             c.Name,
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs:     []spdxExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: c.PURL()}},
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: "SPDXRef-Function", Type: "DEPENDS_ON", Related: id})
+	}
+	return doc
+}
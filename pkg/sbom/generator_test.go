@@ -0,0 +1,117 @@
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// write the files to a new function root.
+func write(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// TestComponents ensures the dependencies of each ecosystem's manifests are
+// read, ordered by their package URLs.
+func TestComponents(t *testing.T) {
+	root := write(t, map[string]string{
+		"go.mod": "module function\n\ngo 1.24\n\nrequire (\n\tgithub.com/a/b v1.2.3\n\tgolang.org/x/c v0.1.0 // indirect\n)\n",
+		"package-lock.json": `{"lockfileVersion": 3, "packages": {
+			"": {"name": "f"},
+			"node_modules/faas-js-runtime": {"version": "2.4.0"},
+			"node_modules/@scope/pkg": {"version": "1.0.0"},
+			"node_modules/faas-js-runtime/node_modules/nested": {"version": "0.1.0"},
+			"node_modules/workspace": {"link": true}}}`,
+		"requirements.txt": "# deps\nFlask_Cors==4.0.1 ; python_version > '3.8'\nrequests[socks] == 2.32.3\nunpinned>=1.0\n-r other.txt\n",
+		"Cargo.lock":       "[[package]]\nname = \"function\"\nversion = \"0.1.0\"\n\n[[package]]\nname = \"serde\"\nversion = \"1.0.200\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n",
+		"pom.xml":          "<project><dependencies><dependency><groupId>io.quarkus</groupId><artifactId>quarkus-funqy-http</artifactId></dependency><dependency><groupId>org.a</groupId><artifactId>b</artifactId><version>2.0</version></dependency></dependencies></project>",
+	})
+	cc, err := Components(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range cc {
+		got = append(got, c.PURL())
+	}
+	want := []string{
+		"pkg:cargo/serde@1.0.200",
+		"pkg:golang/github.com/a/b@v1.2.3",
+		"pkg:golang/golang.org/x/c@v0.1.0",
+		"pkg:maven/io.quarkus/quarkus-funqy-http",
+		"pkg:maven/org.a/b@2.0",
+		"pkg:npm/%40scope/pkg@1.0.0",
+		"pkg:npm/faas-js-runtime@2.4.0",
+		"pkg:npm/nested@0.1.0",
+		"pkg:pypi/flask-cors@4.0.1",
+		"pkg:pypi/requests@2.32.3",
+		"pkg:pypi/unpinned",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected components\nwant: %v\n got: %v", want, got)
+	}
+}
+
+// TestGenerate ensures the SBOM is written in the function's format, with
+// each component a dependency of the function.
+func TestGenerate(t *testing.T) {
+	root := write(t, map[string]string{"go.mod": "module function\n\nrequire github.com/a/b v1.2.3\n"})
+	g := NewGenerator(WithToolVersion("v1.0.0"))
+	g.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := fn.Function{Root: root, Name: "f", Build: fn.BuildSpec{SBOM: fn.SBOMFormatCycloneDX}}
+		if err := g.Generate(context.Background(), f, &buf); err != nil {
+			t.Fatal(err)
+		}
+		var doc cdxDocument
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.BOMFormat != "CycloneDX" || doc.Metadata.Timestamp != "2026-01-02T03:04:05Z" || doc.Metadata.Component.Name != "f" {
+			t.Fatalf("unexpected document %+v", doc)
+		}
+		if len(doc.Components) != 1 || doc.Components[0].PURL != "pkg:golang/github.com/a/b@v1.2.3" {
+			t.Fatalf("unexpected components %+v", doc.Components)
+		}
+		if len(doc.Dependencies) != 1 || !reflect.DeepEqual(doc.Dependencies[0].DependsOn, []string{"pkg:golang/github.com/a/b@v1.2.3"}) {
+			t.Fatalf("unexpected dependencies %+v", doc.Dependencies)
+		}
+	})
+
+	t.Run("spdx", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := fn.Function{Root: root, Name: "f", Build: fn.BuildSpec{SBOM: fn.SBOMFormatSPDX}}
+		if err := g.Generate(context.Background(), f, &buf); err != nil {
+			t.Fatal(err)
+		}
+		var doc spdxDocument
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.SPDXVersion != "SPDX-2.3" || !reflect.DeepEqual(doc.CreationInfo.Creators, []string{"Tool: func-v1.0.0"}) {
+			t.Fatalf("unexpected document %+v", doc)
+		}
+		if len(doc.Packages) != 2 || doc.Packages[1].ExternalRefs[0].Locator != "pkg:golang/github.com/a/b@v1.2.3" {
+			t.Fatalf("unexpected packages %+v", doc.Packages)
+		}
+		if len(doc.Relationships) != 2 || doc.Relationships[1].Type != "DEPENDS_ON" {
+			t.Fatalf("unexpected relationships %+v", doc.Relationships)
+		}
+	})
+}
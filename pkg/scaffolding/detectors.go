@@ -75,6 +75,48 @@ func (d goDetector) Detect(dir string) (static, instanced bool, err error) {
 	return static, instanced, nil
 }
 
+// DetectMiddleware returns true if the function declares a package-level
+// Middleware func, conventionally in middleware.go.
+func (d goDetector) DetectMiddleware(dir string) (bool, error) {
+	declared, err := d.declares(dir, "Middleware")
+	if err != nil {
+		return false, fmt.Errorf("middleware detector encountered an error when scanning the function's source code. %w", err)
+	}
+	return declared["Middleware"], nil
+}
+
+// DetectLifecycle returns whether the function declares the package-level
+// Start and Stop lifecycle hooks of a static function.
+func (d goDetector) DetectLifecycle(dir string) (start, stop bool, err error) {
+	declared, err := d.declares(dir, "Start", "Stop")
+	if err != nil {
+		return false, false, fmt.Errorf("lifecycle detector encountered an error when scanning the function's source code. %w", err)
+	}
+	return declared["Start"], declared["Stop"], nil
+}
+
+// declares returns which of the named package-level functions are declared
+// by the non-test source files in dir.
+func (d goDetector) declares(dir string, functions ...string) (map[string]bool, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	declared := map[string]bool{}
+	for _, file := range files {
+		filename := filepath.Join(dir, file.Name())
+		if file.IsDir() || !strings.HasSuffix(filename, ".go") || strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		for _, function := range functions {
+			if !declared[function] && d.hasFunctionDeclaration(filename, function) {
+				declared[function] = true
+			}
+		}
+	}
+	return declared, nil
+}
+
 func (d goDetector) hasFunctionDeclaration(filename, function string) bool {
 	astFile, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.SkipObjectResolution)
 	if err != nil {
@@ -37,3 +37,19 @@ type ErrRuntimeNotRecognized struct {
 func (e ErrRuntimeNotRecognized) Error() string {
 	return fmt.Sprintf("signature not found.  The runtime %v is not recognized", e.Runtime)
 }
+
+type ErrMiddlewareNotSupported struct {
+	Signature string
+}
+
+func (e ErrMiddlewareNotSupported) Error() string {
+	return fmt.Sprintf("middleware is only supported for http functions, not %v", e.Signature)
+}
+
+type ErrLifecycleNotSupported struct {
+	Signature string
+}
+
+func (e ErrLifecycleNotSupported) Error() string {
+	return fmt.Sprintf("package-level Start and Stop are only supported for static http functions, not %v.  Implement them as methods of an instanced function instead", e.Signature)
+}
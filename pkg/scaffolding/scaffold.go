@@ -3,6 +3,7 @@ package scaffolding
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -10,6 +11,25 @@ import (
 	"knative.dev/func/pkg/filesystem"
 )
 
+const (
+	// StaticDir is the directory of a function's source whose files are
+	// served alongside the handler of HTTP functions.
+	StaticDir = "static"
+
+	// middlewareFile is the name of the scaffolding file which applies the
+	// middleware declared by a Go function.
+	middlewareFile = "middleware_init.go"
+
+	// startFile and stopFile are the names of the scaffolding files which
+	// assign the lifecycle hooks declared by a static Go function.
+	startFile = "start_init.go"
+	stopFile  = "stop_init.go"
+
+	// staticFile is the name of the scaffolding file which embeds the static
+	// files of a Go function.
+	staticFile = "static_init.go"
+)
+
 // Write scaffolding to a given path
 //
 // Scaffolding is a language-level operation which first detects the method
@@ -39,6 +59,22 @@ func Write(out, src, runtime, invoke string, fs filesystem.Filesystem) (err erro
 		return err
 	}
 
+	// detect any middleware, which is applied only around HTTP handlers
+	middleware, err := detectMiddleware(src, runtime)
+	if err != nil {
+		return err
+	}
+	if middleware && s != InstancedHTTP && s != StaticHTTP {
+		return ErrMiddlewareNotSupported{s.String()}
+	}
+
+	// detect any lifecycle hooks declared by a static function.  Instanced
+	// functions implement these as methods, which require no scaffolding.
+	start, stop, err := detectLifecycle(src, runtime, s)
+	if err != nil {
+		return err
+	}
+
 	// Filesystem Required
 	// This is a defensive check used to allow for simple tests which can
 	// omit providing a fileystem by just expecting this error.
@@ -57,6 +93,35 @@ func Write(out, src, runtime, invoke string, fs filesystem.Filesystem) (err erro
 		return ScaffoldingError{"filesystem copy failed", err}
 	}
 
+	// Middleware declared by the function is applied around its handler by
+	// the additional scaffolding at '[runtime]/scaffolding/middleware'.  It is
+	// stored as a template such that it is not compiled in place.
+	if middleware {
+		if err = writeTemplate(out, runtime, "middleware", middlewareFile, fs); err != nil {
+			return err
+		}
+	}
+
+	// Lifecycle hooks of a static function are assigned by the additional
+	// scaffolding at '[runtime]/scaffolding/lifecycle'.
+	for _, hook := range []struct {
+		declared bool
+		file     string
+	}{{start, startFile}, {stop, stopFile}} {
+		if !hook.declared {
+			continue
+		}
+		if err = writeTemplate(out, runtime, "lifecycle", hook.file, fs); err != nil {
+			return err
+		}
+	}
+
+	// A static directory in the function's source is served alongside the
+	// handler of HTTP functions.
+	if err = writeStatic(out, src, runtime, s, fs); err != nil {
+		return err
+	}
+
 	// Not my proudest moment
 	if runtime == "go" {
 		var data []byte
@@ -70,7 +135,17 @@ func Write(out, src, runtime, invoke string, fs filesystem.Filesystem) (err erro
 			return fmt.Errorf("cannot parse go.mod")
 		}
 		moduleName := string(matches[1])
-		for _, n := range []string{"go.mod", "main.go"} {
+		files := []string{"go.mod", "main.go"}
+		if middleware {
+			files = append(files, middlewareFile)
+		}
+		if start {
+			files = append(files, startFile)
+		}
+		if stop {
+			files = append(files, stopFile)
+		}
+		for _, n := range files {
 			p := filepath.Join(out, n)
 			data, err = os.ReadFile(p)
 			if err != nil {
@@ -106,6 +181,82 @@ func Write(out, src, runtime, invoke string, fs filesystem.Filesystem) (err erro
 	return
 }
 
+// writeTemplate writes the named file of the additional scaffolding at
+// '[runtime]/scaffolding/[dir]' to out.  Such files are stored as templates
+// (with a .tmpl suffix) such that they are not compiled in place.
+func writeTemplate(out, runtime, dir, name string, fs filesystem.Filesystem) error {
+	file, err := fs.Open(fmt.Sprintf("%v/scaffolding/%v/%v.tmpl", runtime, dir, name))
+	if err != nil {
+		return ErrScaffoldingNotFound
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return ScaffoldingError{dir + " read failed", err}
+	}
+	if err = os.WriteFile(filepath.Join(out, name), data, 0644); err != nil {
+		return ScaffoldingError{dir + " write failed", err}
+	}
+	return nil
+}
+
+// writeStatic copies the function's static directory, if any, into the
+// scaffolding such that it is included in the built image.  Go embeds the
+// files into the binary; Python serves them from the service package.
+func writeStatic(out, src, runtime string, s Signature, fs filesystem.Filesystem) error {
+	if s != InstancedHTTP && s != StaticHTTP {
+		return nil
+	}
+	fi, err := os.Stat(filepath.Join(src, StaticDir))
+	if err != nil || !fi.IsDir() {
+		return nil // no static files
+	}
+	var dest string
+	switch runtime {
+	case "go":
+		dest = filepath.Join(out, StaticDir)
+		if err = writeTemplate(out, runtime, "static", staticFile, fs); err != nil {
+			return err
+		}
+	case "python":
+		dest = filepath.Join(out, "service", StaticDir)
+	default:
+		return nil
+	}
+	if err = filesystem.CopyFromFS(StaticDir, dest, filesystem.NewOsFilesystem(src)); err != nil {
+		return ScaffoldingError{"static files copy failed", err}
+	}
+	return nil
+}
+
+// detectMiddleware returns true if the source code at the given location
+// declares middleware which must be applied by scaffolding.  Runtimes which
+// detect middleware at runtime (such as python) always return false.
+func detectMiddleware(src, runtime string) (bool, error) {
+	if runtime != "go" {
+		return false, nil
+	}
+	return goDetector{}.DetectMiddleware(src)
+}
+
+// detectLifecycle returns whether the source code at the given location
+// declares the package-level Start and Stop hooks of a static function,
+// which must be assigned by scaffolding.  Only static HTTP functions may
+// declare them.  Runtimes which detect lifecycle hooks at runtime (such as
+// python) always return false.
+func detectLifecycle(src, runtime string, s Signature) (start, stop bool, err error) {
+	if runtime != "go" || s == InstancedHTTP || s == InstancedCloudevents {
+		return
+	}
+	if start, stop, err = (goDetector{}).DetectLifecycle(src); err != nil {
+		return
+	}
+	if (start || stop) && s != StaticHTTP {
+		err = ErrLifecycleNotSupported{s.String()}
+	}
+	return
+}
+
 // detectSignature returns the Signature of the source code at the given
 // location assuming a provided runtime and invocation hint.
 func detectSignature(src, runtime, invoke string) (s Signature, err error) {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"knative.dev/func/pkg/filesystem"
@@ -118,6 +119,191 @@ func New() *F { return nil }
 
 }
 
+// TestWrite_Middleware ensures that the middleware scaffolding is written,
+// referencing the function's module, only when the function declares
+// middleware, and that middleware is rejected for CloudEvent functions.
+func TestWrite_Middleware(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewOsFilesystem(filepath.Join(cwd, "testdata", "testwrite"))
+
+	root, done := Mktemp(t)
+	defer done()
+
+	impl := `
+package f
+
+type F struct{}
+
+func New() *F { return nil }
+`
+	if err = os.WriteFile(filepath.Join(root, "f.go"), []byte(impl), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "go.mod"), []byte("module foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// No middleware declared: no middleware scaffolding
+	out := filepath.Join(root, "out")
+	if err = Write(out, root, "go", "", fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(out, "middleware_init.go")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no middleware scaffolding. %v", err)
+	}
+
+	// Middleware declared (test files are ignored)
+	mw := `
+package f
+
+import "net/http"
+
+func Middleware() []func(http.Handler) http.Handler { return nil }
+`
+	if err = os.WriteFile(filepath.Join(root, "f_test.go"), []byte(mw), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	out = filepath.Join(root, "out2")
+	if err = Write(out, root, "go", "", fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(out, "middleware_init.go")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected test files to be ignored. %v", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(root, "middleware.go"), []byte(mw), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	out = filepath.Join(root, "out3")
+	if err = Write(out, root, "go", "", fs); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "middleware_init.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `f "foo"`) {
+		t.Fatalf("expected middleware scaffolding to import the function module, got:\n%s", data)
+	}
+
+	// CloudEvent functions do not support middleware
+	err = Write(filepath.Join(root, "out4"), root, "go", "cloudevent", fs)
+	if !errors.As(err, &ErrMiddlewareNotSupported{}) {
+		t.Fatalf("expected ErrMiddlewareNotSupported, got %v", err)
+	}
+}
+
+// TestWrite_Lifecycle ensures that the package-level lifecycle hooks of a
+// static function are assigned by additional scaffolding, and that they are
+// rejected for static CloudEvent functions.
+func TestWrite_Lifecycle(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewOsFilesystem(filepath.Join(cwd, "testdata", "testwrite"))
+
+	root, done := Mktemp(t)
+	defer done()
+
+	impl := `
+package f
+
+import (
+	"context"
+	"net/http"
+)
+
+func Handle(w http.ResponseWriter, r *http.Request) {}
+
+func Stop(ctx context.Context) error { return nil }
+`
+	if err = os.WriteFile(filepath.Join(root, "f.go"), []byte(impl), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "go.mod"), []byte("module foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(root, "out")
+	if err = Write(out, root, "go", "", fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(out, "start_init.go")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no start scaffolding for an undeclared Start. %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "stop_init.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `f "foo"`) {
+		t.Fatalf("expected stop scaffolding to import the function module, got:\n%s", data)
+	}
+
+	// Static CloudEvent functions do not support package-level hooks
+	err = Write(filepath.Join(root, "out2"), root, "go", "cloudevent", fs)
+	if !errors.As(err, &ErrLifecycleNotSupported{}) {
+		t.Fatalf("expected ErrLifecycleNotSupported, got %v", err)
+	}
+}
+
+// TestWrite_Static ensures that a function's static directory is copied
+// into the scaffolding along with the scaffolding which serves it.
+func TestWrite_Static(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewOsFilesystem(filepath.Join(cwd, "testdata", "testwrite"))
+
+	root, done := Mktemp(t)
+	defer done()
+
+	impl := `
+package f
+
+type F struct{}
+
+func New() *F { return nil }
+`
+	if err = os.WriteFile(filepath.Join(root, "f.go"), []byte(impl), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, "go.mod"), []byte("module foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// No static directory: no static scaffolding
+	out := filepath.Join(root, "out")
+	if err = Write(out, root, "go", "", fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(out, staticFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected no static scaffolding. %v", err)
+	}
+
+	if err = os.MkdirAll(filepath.Join(root, StaticDir, "css"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(root, StaticDir, "css", "site.css"), []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out = filepath.Join(root, "out2")
+	if err = Write(out, root, "go", "", fs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(out, staticFile)); err != nil {
+		t.Fatalf("expected static scaffolding. %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, StaticDir, "css", "site.css"))
+	if err != nil || string(data) != "body{}" {
+		t.Fatalf("expected static files to be copied into the scaffolding. %v", err)
+	}
+}
+
 // TestWrite_ScaffoldingNotFound ensures that a typed error is returned
 // when scaffolding is not found.
 func TestWrite_ScaffoldingNotFound(t *testing.T) {
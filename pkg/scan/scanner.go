@@ -0,0 +1,167 @@
+/*
+Package scan scans the images of functions for known vulnerabilities
+(CVEs) with trivy or grype, run on an OCI archive of the image such that it
+is scanned as built, whether or not it has been pushed, and whichever
+builder built it.  The scanners are not linked into func, but are run as
+the trivy and grype commands of the PATH, which must be installed.
+*/
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// Opt is an option for the scanner.
+type Opt func(*Scanner)
+
+// WithVerbose toggles printing the output of the scanner while it scans.
+func WithVerbose(v bool) Opt {
+	return func(s *Scanner) {
+		s.verbose = v
+	}
+}
+
+// Scanner of images.  Implements fn.Scanner.
+type Scanner struct {
+	verbose bool
+	// run the command, returning its standard output.
+	run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// NewScanner of images, by the trivy or grype command of the PATH.
+func NewScanner(opts ...Opt) *Scanner {
+	s := &Scanner{}
+	s.run = s.exec
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan the image, written as an OCI archive to path, with the function's
+// scanner (f.Build.Scan), returning the vulnerabilities found of every
+// severity.
+func (s *Scanner) Scan(ctx context.Context, f fn.Function, path string) (r fn.ScanReport, err error) {
+	if f.Build.Scan == nil {
+		return r, errors.New("the function does not define a scan (build.scan)")
+	}
+	r = fn.ScanReport{
+		Image:     f.Build.Image,
+		Scanner:   f.Build.Scan.ScannerName(),
+		Threshold: f.Build.Scan.Threshold(),
+	}
+	switch r.Scanner {
+	case fn.ScannerTrivy:
+		out, err := s.run(ctx, "trivy", "image", "--input", path, "--format", "json", "--quiet")
+		if err != nil {
+			return r, err
+		}
+		r.Vulnerabilities, err = parseTrivy(out)
+		return r, err
+	case fn.ScannerGrype:
+		out, err := s.run(ctx, "grype", "oci-archive:"+path, "--output", "json", "--quiet")
+		if err != nil {
+			return r, err
+		}
+		r.Vulnerabilities, err = parseGrype(out)
+		return r, err
+	default:
+		return r, fmt.Errorf("unsupported scanner %q", r.Scanner)
+	}
+}
+
+func (s *Scanner) exec(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %v scanner was not found. Its command must be installed on the PATH to scan images, or select another with --scanner: %w", name, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if s.verbose {
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v failed: %w %v", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// trivyReport is the JSON report of 'trivy image --format json'.
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string
+			PkgName          string
+			InstalledVersion string
+			FixedVersion     string
+			Severity         string
+			Title            string
+		}
+	}
+}
+
+func parseTrivy(data []byte) (vv []fn.Vulnerability, err error) {
+	var report trivyReport
+	if err = json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("cannot read the trivy report: %w", err)
+	}
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			vv = append(vv, fn.Vulnerability{
+				ID:           v.VulnerabilityID,
+				Package:      v.PkgName,
+				Version:      v.InstalledVersion,
+				FixedVersion: v.FixedVersion,
+				Severity:     strings.ToLower(v.Severity),
+				Title:        v.Title,
+			})
+		}
+	}
+	return
+}
+
+// grypeReport is the JSON report of 'grype --output json'.
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID          string `json:"id"`
+			Severity    string `json:"severity"`
+			Description string `json:"description"`
+			Fix         struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+}
+
+func parseGrype(data []byte) (vv []fn.Vulnerability, err error) {
+	var report grypeReport
+	if err = json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("cannot read the grype report: %w", err)
+	}
+	for _, m := range report.Matches {
+		vv = append(vv, fn.Vulnerability{
+			ID:           m.Vulnerability.ID,
+			Package:      m.Artifact.Name,
+			Version:      m.Artifact.Version,
+			FixedVersion: strings.Join(m.Vulnerability.Fix.Versions, ", "),
+			Severity:     strings.ToLower(m.Vulnerability.Severity),
+			Title:        m.Vulnerability.Description,
+		})
+	}
+	return
+}
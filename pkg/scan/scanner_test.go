@@ -0,0 +1,61 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	fn "knative.dev/func/pkg/functions"
+)
+
+// TestScan ensures the report of each scanner is read as the
+// vulnerabilities of the image, and that each is run on the archive.
+func TestScan(t *testing.T) {
+	tests := []struct {
+		scanner string
+		args    []string
+		report  string
+	}{
+		{
+			scanner: fn.ScannerTrivy,
+			args:    []string{"trivy", "image", "--input", "/tmp/image.tar", "--format", "json", "--quiet"},
+			report: `{"Results": [{"Target": "image (debian 12.5)", "Vulnerabilities": [
+				{"VulnerabilityID": "CVE-2024-0001", "PkgName": "openssl", "InstalledVersion": "3.0.11", "FixedVersion": "3.0.13", "Severity": "HIGH", "Title": "openssl: overflow"},
+				{"VulnerabilityID": "CVE-2024-0002", "PkgName": "zlib", "InstalledVersion": "1.2.13", "Severity": "LOW"}]},
+				{"Target": "Python"}]}`,
+		},
+		{
+			scanner: fn.ScannerGrype,
+			args:    []string{"grype", "oci-archive:/tmp/image.tar", "--output", "json", "--quiet"},
+			report: `{"matches": [
+				{"vulnerability": {"id": "CVE-2024-0001", "severity": "High", "description": "openssl: overflow", "fix": {"versions": ["3.0.13"]}}, "artifact": {"name": "openssl", "version": "3.0.11"}},
+				{"vulnerability": {"id": "CVE-2024-0002", "severity": "Low", "fix": {"versions": []}}, "artifact": {"name": "zlib", "version": "1.2.13"}}]}`,
+		},
+	}
+	expected := []fn.Vulnerability{
+		{ID: "CVE-2024-0001", Package: "openssl", Version: "3.0.11", FixedVersion: "3.0.13", Severity: "high", Title: "openssl: overflow"},
+		{ID: "CVE-2024-0002", Package: "zlib", Version: "1.2.13", Severity: "low"},
+	}
+	for _, test := range tests {
+		t.Run(test.scanner, func(t *testing.T) {
+			s := NewScanner()
+			s.run = func(_ context.Context, name string, args ...string) ([]byte, error) {
+				if got := append([]string{name}, args...); !reflect.DeepEqual(got, test.args) {
+					t.Fatalf("expected command %v, got %v", test.args, got)
+				}
+				return []byte(test.report), nil
+			}
+			f := fn.Function{Build: fn.BuildSpec{Image: "example.com/alice/f:latest", Scan: &fn.ScanSpec{Scanner: test.scanner}}}
+			r, err := s.Scan(context.Background(), f, "/tmp/image.tar")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Scanner != test.scanner || r.Threshold != fn.DefaultScanSeverity || r.Image != f.Build.Image {
+				t.Fatalf("unexpected report %v %v %v", r.Image, r.Scanner, r.Threshold)
+			}
+			if !reflect.DeepEqual(r.Vulnerabilities, expected) {
+				t.Fatalf("expected %+v, got %+v", expected, r.Vulnerabilities)
+			}
+		})
+	}
+}
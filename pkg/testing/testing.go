@@ -283,6 +283,7 @@ func FromTempDirectory(t *testing.T) string {
 
 	// By default unit tests presum no config exists unless provided in testdata.
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
 
 	t.Setenv("KUBERNETES_SERVICE_HOST", "")
 
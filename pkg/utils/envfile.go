@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ParseEnvFile at the given path, returning its environment variables in the
+// form NAME=VALUE, in the order in which they are declared.
+//
+// The file is of the dotenv format: blank lines and lines beginning with '#'
+// are ignored, a line may be prefixed with 'export ', and a value may be
+// single-quoted (literal), double-quoted (supporting the escapes \n, \", and
+// \\), or unquoted, in which case it is trimmed and a trailing ' #' comment is
+// removed.  Values such as "{{ secret:name:key }}" are returned as-is, to be
+// interpolated as are those of the function's envs.
+func ParseEnvFile(path string) (envs []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%v:%v: expected NAME=VALUE, got %q", path, n, line)
+		}
+		if value, err = parseEnvFileValue(strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("%v:%v: %w", path, n, err)
+		}
+		envs = append(envs, name+"="+value)
+	}
+	return envs, scanner.Err()
+}
+
+// parseEnvFileValue of a line of an env file, unquoting it if quoted.
+func parseEnvFileValue(v string) (string, error) {
+	if v == "" {
+		return v, nil
+	}
+	switch quote := v[0]; quote {
+	case '\'', '"':
+		end := strings.LastIndexByte(v, quote)
+		if end == 0 {
+			return "", fmt.Errorf("unterminated quoted value %v", v)
+		}
+		if rest := strings.TrimSpace(v[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected characters after quoted value %v", v)
+		}
+		v = v[1:end]
+		if quote == '"' {
+			v = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(v)
+		}
+		return v, nil
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestParseEnvFile ensures that env files of the dotenv format are parsed in
+// order, with comments, export prefixes and quoting handled.
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    nil,
+		},
+		{
+			name:    "comments and blank lines",
+			content: "# a comment\n\nA=1\n  # indented comment\nB=2\n",
+			want:    []string{"A=1", "B=2"},
+		},
+		{
+			name:    "export prefix",
+			content: "export A=1\n",
+			want:    []string{"A=1"},
+		},
+		{
+			name:    "unquoted value is trimmed and loses trailing comment",
+			content: "A =  some value   # comment\nB=x#y\n",
+			want:    []string{"A=some value", "B=x#y"},
+		},
+		{
+			name:    "empty value",
+			content: "A=\n",
+			want:    []string{"A="},
+		},
+		{
+			name:    "single quoted value is literal",
+			content: `A='a \n # b'` + "\n",
+			want:    []string{`A=a \n # b`},
+		},
+		{
+			name:    "double quoted value supports escapes",
+			content: `A="line1\nline2 \"q\" \\" # comment` + "\n",
+			want:    []string{"A=line1\nline2 \"q\" \\"},
+		},
+		{
+			name:    "value containing an equals sign",
+			content: "A=b=c\n",
+			want:    []string{"A=b=c"},
+		},
+		{
+			name:    "interpolated values are returned as-is",
+			content: "A={{ secret:mysecret:key }}\nB={{ env:LOCAL }}\n",
+			want:    []string{"A={{ secret:mysecret:key }}", "B={{ env:LOCAL }}"},
+		},
+		{
+			name:    "missing equals sign",
+			content: "A\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			content: "=1\n",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			content: `A="abc` + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "characters after quoted value",
+			content: `A="abc" def` + "\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseEnvFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEnvFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseEnvFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseEnvFile_NotFound ensures a missing env file is an error.
+func TestParseEnvFile_NotFound(t *testing.T) {
+	if _, err := ParseEnvFile(filepath.Join(t.TempDir(), ".env")); err == nil {
+		t.Fatal("expected an error parsing a nonexistent env file")
+	}
+}
@@ -0,0 +1,280 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidImage indicates the image reference did not pass validation.
+type ErrInvalidImage error
+
+// ErrInvalidRegistry indicates the registry did not pass validation.
+type ErrInvalidRegistry error
+
+// Syntax of the parts of an image reference, as defined by the OCI
+// distribution specification.
+var (
+	hostLabelRegexp     = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+	pathComponentRegexp = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*$`)
+	tagRegexp           = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+	algorithmRegexp     = regexp.MustCompile(`^[a-z0-9]+([+._-][a-z0-9]+)*$`)
+	hexRegexp           = regexp.MustCompile(`^[a-f0-9]+$`)
+)
+
+// maxRepositoryLength of an image reference's host and path, excluding its
+// tag and digest.
+const maxRepositoryLength = 255
+
+// digestLengths of the hex encoded digests of the registered algorithms.
+var digestLengths = map[string]int{"sha256": 64, "sha512": 128}
+
+// ValidateImage validates that the input is a valid image reference of the
+// form [host[:port]/]path[:tag][@digest], such as
+// 'quay.io/alice/my-function:latest' or
+// 'example.com:5000/alice/f@sha256:<64 hex characters>'.
+func ValidateImage(image string) error {
+	return CheckImage(image).Err()
+}
+
+// ValidateRegistry validates that the input is a valid registry of function
+// images: a host with an optional port and path such as 'quay.io/alice' or
+// 'localhost:5000', or a single namespace of the default registry such as
+// 'alice'.  It may not include a tag or digest.
+func ValidateRegistry(registry string) error {
+	return CheckRegistry(registry).Err()
+}
+
+// CheckImage validates an image reference (see ValidateImage).
+func CheckImage(image string) Result {
+	r := Result{Field: "image", Value: image}
+	if image == "" {
+		r.Violations = []Violation{{Field: "image", Rule: RuleRequired, Message: "image is required"}}
+	} else {
+		repository, tag, digest := splitImage(image)
+		r.Violations = checkRepository("image", repository, true)
+		if tag != nil {
+			r.Violations = append(r.Violations, checkTag(*tag)...)
+		}
+		if digest != nil {
+			r.Violations = append(r.Violations, checkDigest(*digest)...)
+		}
+	}
+	return imageResult(r, func(err error) error { return ErrInvalidImage(err) })
+}
+
+// CheckRegistry validates a registry of function images (see
+// ValidateRegistry).
+func CheckRegistry(registry string) Result {
+	r := Result{Field: "registry", Value: registry}
+	registry = strings.TrimSuffix(registry, "/")
+	switch {
+	case registry == "":
+		r.Violations = []Violation{{Field: "registry", Rule: RuleRequired, Message: "registry is required"}}
+	case strings.Contains(registry, "@"):
+		r.Violations = []Violation{{Field: "registry", Value: registry, Rule: RuleFormat,
+			Message: "registry must not include a digest; use --image to specify an image by digest"}}
+	default:
+		if i := strings.LastIndex(registry, ":"); i > strings.LastIndex(registry, "/") && strings.Contains(registry, "/") {
+			r.Violations = []Violation{{Field: "registry", Value: registry, Rule: RuleFormat,
+				Message: "registry must not include a tag; use --image to specify an image by tag"}}
+			break
+		}
+		r.Violations = checkRepository("registry", registry, false)
+	}
+	return imageResult(r, func(err error) error { return ErrInvalidRegistry(err) })
+}
+
+// imageResult completes a result of validating an image or registry with its
+// error, and with a suggestion if one can be derived.
+func imageResult(r Result, wrap func(error) error) Result {
+	if len(r.Violations) == 0 {
+		return r
+	}
+	msgs := make([]string, len(r.Violations))
+	for i, v := range r.Violations {
+		msgs[i] = v.Message
+	}
+	r.err = wrap(fmt.Errorf("invalid %v '%v': %v", r.Field, r.Value, strings.Join(msgs, "; ")))
+
+	// Path components are often only invalid by being upper case.
+	if suggestion := suggestImage(r.Value); suggestion != r.Value {
+		check := CheckImage
+		if r.Field == "registry" {
+			check = CheckRegistry
+		}
+		if check(suggestion).Valid() {
+			for i := range r.Violations {
+				r.Violations[i].Suggestion = suggestion
+			}
+		}
+	}
+	return r
+}
+
+// splitImage into its repository (host and path), and tag and digest if
+// present.
+func splitImage(image string) (repository string, tag, digest *string) {
+	if name, d, found := strings.Cut(image, "@"); found {
+		image, digest = name, &d
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		t := image[i+1:]
+		image, tag = image[:i], &t
+	}
+	return image, tag, digest
+}
+
+// splitHost from the path of a repository.  As with the docker CLI, the first
+// component is a host only if it contains a '.' or ':', or is 'localhost'.
+func splitHost(repository string) (host, path string) {
+	first, rest, found := strings.Cut(repository, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return first, rest
+	}
+	if !found && (strings.Contains(first, ":") || first == "localhost") {
+		return first, "" // a host alone, such as a registry 'localhost:5000'
+	}
+	return "", repository
+}
+
+// checkRepository (host and path) of an image or registry.  The path of a
+// registry is optional.
+func checkRepository(field, repository string, pathRequired bool) (vv []Violation) {
+	if len(repository) > maxRepositoryLength {
+		vv = append(vv, Violation{Field: field, Value: repository, Rule: RuleMaxLength,
+			Message: fmt.Sprintf("%v name must be no more than %d characters", field, maxRepositoryLength)})
+	}
+	host, path := splitHost(repository)
+	if host != "" {
+		vv = append(vv, checkHost(host)...)
+	}
+	if path == "" {
+		if host == "" {
+			vv = append(vv, Violation{Field: field + " name", Rule: RuleRequired,
+				Message: fmt.Sprintf("%v name is required", field)})
+		} else if pathRequired {
+			vv = append(vv, Violation{Field: field + " path", Rule: RuleRequired,
+				Message: fmt.Sprintf("%v must include a path after its host", field)})
+		}
+		return
+	}
+	for _, c := range strings.Split(path, "/") {
+		vv = append(vv, checkPathComponent(c)...)
+	}
+	return
+}
+
+// checkHost of an image, which may include a port.
+func checkHost(host string) (vv []Violation) {
+	name, port := host, ""
+	if strings.HasPrefix(host, "[") { // IPv6
+		if end := strings.Index(host, "]"); end > 0 {
+			name, port = host[1:end], strings.TrimPrefix(host[end+1:], ":")
+			if ip := net.ParseIP(name); ip == nil || ip.To4() != nil {
+				vv = append(vv, Violation{Field: "registry host", Value: name, Rule: RuleFormat,
+					Message: fmt.Sprintf("registry host '%v' is not a valid IPv6 address", name)})
+			}
+		} else {
+			vv = append(vv, Violation{Field: "registry host", Value: host, Rule: RuleFormat,
+				Message: fmt.Sprintf("registry host '%v' is missing its closing ']'", host)})
+		}
+	} else {
+		if h, p, found := strings.Cut(host, ":"); found {
+			name, port = h, p
+		}
+		for _, label := range strings.Split(name, ".") {
+			if !hostLabelRegexp.MatchString(label) {
+				vv = append(vv, Violation{Field: "registry host", Value: name, Rule: RuleFormat,
+					Message: fmt.Sprintf("registry host '%v' is not a valid hostname or IP address", name)})
+				break
+			}
+		}
+	}
+	if port != "" || strings.HasSuffix(host, ":") {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 || strings.HasPrefix(port, "+") {
+			vv = append(vv, Violation{Field: "registry port", Value: port, Rule: RuleFormat,
+				Message: fmt.Sprintf("registry port '%v' must be a number between 1 and 65535", port)})
+		}
+	}
+	return
+}
+
+// checkPathComponent of an image: lower case alphanumeric characters
+// separated by a '.', one or two '_', or any number of '-'.
+func checkPathComponent(c string) []Violation {
+	if pathComponentRegexp.MatchString(c) {
+		return nil
+	}
+	v := Violation{Field: "image path component", Value: c}
+	switch {
+	case c == "":
+		v.Rule, v.Message = RuleFormat, "image path components must not be empty (check for repeated or trailing '/')"
+	case strings.ToLower(c) != c && pathComponentRegexp.MatchString(strings.ToLower(c)):
+		v.Rule, v.Message = RuleCharacters, fmt.Sprintf("image path component '%v' must be lower case", c)
+	default:
+		v.Rule = RuleFormat
+		v.Message = fmt.Sprintf("image path component '%v' must consist of lower case alphanumeric characters, "+
+			"separated by '.', '_', '__' or '-'", c)
+	}
+	return []Violation{v}
+}
+
+// checkTag of an image.
+func checkTag(tag string) []Violation {
+	if tagRegexp.MatchString(tag) {
+		return nil
+	}
+	v := Violation{Field: "image tag", Value: tag, Rule: RuleFormat,
+		Message: fmt.Sprintf("image tag '%v' must consist of up to 128 alphanumeric characters, '_', '.' or '-', "+
+			"and not start with '.' or '-'", tag)}
+	if tag == "" {
+		v.Rule, v.Message = RuleRequired, "image tag must not be empty when ':' is included"
+	} else if len(tag) > 128 {
+		v.Rule = RuleMaxLength
+	}
+	return []Violation{v}
+}
+
+// checkDigest of an image, of the form algorithm:hex.
+func checkDigest(digest string) []Violation {
+	violation := func(msg string, args ...any) []Violation {
+		return []Violation{{Field: "image digest", Value: digest, Rule: RuleFormat, Message: fmt.Sprintf(msg, args...)}}
+	}
+	algorithm, encoded, found := strings.Cut(digest, ":")
+	if !found || !algorithmRegexp.MatchString(algorithm) {
+		return violation("image digest '%v' must be of the form algorithm:hex, such as 'sha256:<64 hex characters>'", digest)
+	}
+	if !hexRegexp.MatchString(encoded) {
+		return violation("image digest '%v' must be lower case hexadecimal after '%v:'", digest, algorithm)
+	}
+	if n, ok := digestLengths[algorithm]; ok && len(encoded) != n {
+		return violation("image digest '%v' must have %d hex characters after '%v:', but has %d", digest, n, algorithm, len(encoded))
+	}
+	if _, ok := digestLengths[algorithm]; !ok && len(encoded) < 32 {
+		return violation("image digest '%v' is too short", digest)
+	}
+	return nil
+}
+
+// suggestImage similar to that given, by lower casing its path.
+func suggestImage(image string) string {
+	repository, tag, digest := splitImage(image)
+	host, path := splitHost(repository)
+	if path == "" {
+		return image
+	}
+	s := strings.ToLower(path)
+	if host != "" {
+		s = host + "/" + s
+	}
+	if tag != nil {
+		s += ":" + *tag
+	}
+	if digest != nil {
+		s += "@" + *digest
+	}
+	return s
+}
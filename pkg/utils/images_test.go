@@ -0,0 +1,114 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// TestValidateImage tests that only valid image references are accepted, and
+// that the part of the reference which is invalid is reported.
+func TestValidateImage(t *testing.T) {
+	cases := []struct {
+		In    string
+		Error string // expected in the error message, or empty if valid
+	}{
+		{"alpine", ""},
+		{"alpine:3.19", ""},
+		{"quay.io/alice/my-function:latest", ""},
+		{"localhost:5000/alice/f", ""},
+		{"127.0.0.1:5000/f", ""},
+		{"[::1]:5000/alice/f", ""},
+		{"example.com/a__b/c.d/e---f", ""},
+		{"quay.io/alice/f@" + testDigest, ""},
+		{"quay.io/alice/f:v1@" + testDigest, ""},
+		{"", "image is required"},
+		{"quay.io/Alice/f", "image path component 'Alice' must be lower case"},
+		{"quay.io/alice//f", "must not be empty"},
+		{"quay.io/alice/f_", "image path component 'f_'"},
+		{"exa_mple.com/alice/f", "registry host 'exa_mple.com'"},
+		{"example.com:99999/alice/f", "registry port '99999'"},
+		{"example.com:/alice/f", "registry port ''"},
+		{"[::1/alice/f", "missing its closing ']'"},
+		{"example.com", ""}, // a single path component, no host
+		{"localhost:5000", "must include a path after its host"},
+		{"alice/f:", "image tag must not be empty"},
+		{"alice/f:.latest", "image tag '.latest'"},
+		{"alice/f:" + strings.Repeat("a", 129), "up to 128"},
+		{"alice/f@sha256:1234", "must have 64 hex characters"},
+		{"alice/f@" + strings.ToUpper(testDigest), "must be of the form algorithm:hex"},
+		{"alice/f@sha256:" + strings.Repeat("E", 64), "lower case hexadecimal"},
+		{"alice/f@12345", "algorithm:hex"},
+		{"@" + testDigest, "image name is required"},
+	}
+	for _, c := range cases {
+		err := ValidateImage(c.In)
+		if c.Error == "" {
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", c.In, err)
+			}
+			continue
+		}
+		var e ErrInvalidImage
+		if !errors.As(err, &e) {
+			t.Errorf("expected ErrInvalidImage for %q, got %v", c.In, err)
+		} else if !strings.Contains(err.Error(), c.Error) {
+			t.Errorf("expected the error for %q to contain %q, got %q", c.In, c.Error, err)
+		}
+	}
+}
+
+// TestValidateRegistry tests that only valid registries are accepted.
+func TestValidateRegistry(t *testing.T) {
+	cases := []struct {
+		In    string
+		Error string
+	}{
+		{"alice", ""},
+		{"quay.io/alice", ""},
+		{"quay.io/alice/", ""},
+		{"localhost:5000", ""},
+		{"ghcr.io", ""},
+		{"ghcr.io/org/team", ""},
+		{"", "registry is required"},
+		{"quay.io/alice:latest", "must not include a tag"},
+		{"quay.io/alice@" + testDigest, "must not include a digest"},
+		{"localhost:port", "registry port 'port'"},
+		{"quay.io/Alice", "must be lower case"},
+		{"bad_host.io/alice", "registry host 'bad_host.io'"},
+	}
+	for _, c := range cases {
+		err := ValidateRegistry(c.In)
+		if c.Error == "" {
+			if err != nil {
+				t.Errorf("unexpected error for %q: %v", c.In, err)
+			}
+			continue
+		}
+		var e ErrInvalidRegistry
+		if !errors.As(err, &e) {
+			t.Errorf("expected ErrInvalidRegistry for %q, got %v", c.In, err)
+		} else if !strings.Contains(err.Error(), c.Error) {
+			t.Errorf("expected the error for %q to contain %q, got %q", c.In, c.Error, err)
+		}
+	}
+}
+
+// TestCheckImage_Suggestion ensures that an image which is invalid only in
+// its case is suggested in lower case.
+func TestCheckImage_Suggestion(t *testing.T) {
+	if s := CheckImage("Quay.io/Alice/MyFunc:Latest").Suggestion(); s != "Quay.io/alice/myfunc:Latest" {
+		t.Fatalf("unexpected suggestion %q", s)
+	}
+	if s := CheckRegistry("Alice").Suggestion(); s != "alice" {
+		t.Fatalf("unexpected suggestion %q", s)
+	}
+	if s := CheckImage("alice/f@sha256:1234").Suggestion(); s != "" {
+		t.Fatalf("expected no suggestion, got %q", s)
+	}
+}
@@ -1,13 +1,5 @@
 package utils
 
-import (
-	"errors"
-	"fmt"
-	"strings"
-
-	"k8s.io/apimachinery/pkg/util/validation"
-)
-
 // ErrInvalidName indicates the name did not pass function name validation.
 type ErrInvalidFunctionName error
 
@@ -33,48 +25,26 @@ type ErrInvalidNamespace error
 // It must consist of lower case alphanumeric characters or '-' and start with an alphabetic character and end with an alphanumeric character.
 // (e.g. 'my-name',  or 'abc-1', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')
 func ValidateFunctionName(name string) error {
-
-	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
-		// In case of invalid name the error is this:
-		// "a DNS-1035 label must consist of lower case alphanumeric characters or '-',
-		// start with an alphabetic character,
-		// and end with an alphanumeric character".
-		// Let's reuse it for our purposes, ie. replace "a DNS-1035 label" substring with "Function name" and the actual function name
-		return ErrInvalidFunctionName(errors.New(strings.Replace(strings.Join(errs, ""), "a DNS-1035 label", fmt.Sprintf("Function name '%v'", name), 1)))
-	}
-
-	return nil
+	return CheckFunctionName(name).Err()
 }
 
 // ValidateEnvVarName validatest that the input name is a valid Kubernetes Environment Variable name.
 // It must  must consist of alphabetic characters, digits, '_', '-', or '.', and must not start with a digit
 // (e.g. 'my.env-name',  or 'MY_ENV.NAME',  or 'MyEnvName1', regex used for validation is '[-._a-zA-Z][-._a-zA-Z0-9]*'
 func ValidateEnvVarName(name string) error {
-	if errs := validation.IsEnvVarName(name); len(errs) > 0 {
-		return ErrInvalidEnvVarName(errors.New(strings.Join(errs, "")))
-	}
-
-	return nil
+	return CheckEnvVarName(name).Err()
 }
 
 // ValidateConfigMapKey validatest that the input ConfigMap key is valid.
 // It must  must consist of alphabetic characters, digits, '_', '-', or '.', regex used for validation is '[-._a-zA-Z0-9]+'
 func ValidateConfigMapKey(key string) error {
-	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
-		return ErrInvalidConfigMapKey(errors.New(strings.Join(errs, "")))
-	}
-
-	return nil
+	return CheckConfigMapKey(key).Err()
 }
 
 // ValidateSecretKey validatest that the input Secret key is valid.
 // It must  must consist of alphabetic characters, digits, '_', '-', or '.', regex used for validation is '[-._a-zA-Z0-9]+'
 func ValidateSecretKey(key string) error {
-	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
-		return ErrInvalidSecretKey(errors.New(strings.Join(errs, "")))
-	}
-
-	return nil
+	return CheckSecretKey(key).Err()
 }
 
 // ValidateLabelKey validates that the input name is a valid Kubernetes key.
@@ -85,11 +55,7 @@ func ValidateSecretKey(key string) error {
 // a series of DNS labels separated by dots (.), not longer than 253 characters in total, followed
 // by a slash (/).
 func ValidateLabelKey(key string) error {
-	errs := validation.IsQualifiedName(key)
-	if len(errs) > 0 {
-		return ErrInvalidLabel(errors.New(strings.Join(errs, "")))
-	}
-	return nil
+	return CheckLabelKey(key).Err()
 }
 
 // ValidateLabelValue ensures that the input is a Kubernetes label value
@@ -99,34 +65,13 @@ func ValidateLabelKey(key string) error {
 // Label values may also come from the environment and therefore, could be enclosed with {{}}
 // Treat this as a special case.
 func ValidateLabelValue(value string) error {
-	var errs []string
-	if !strings.HasPrefix(value, "{{") {
-		errs = append(errs, validation.IsValidLabelValue(value)...)
-	}
-	if len(errs) > 0 {
-		return ErrInvalidLabel(errors.New(strings.Join(errs, "")))
-	}
-	return nil
+	return CheckLabelValue(value).Err()
 }
 
 // ValidateDomain validates that the input is a valid DNS subdomain name (RFC 1123).
 // Examples: "example.com", "api.example.com", "my-app.staging.example.com"
 func ValidateDomain(domain string) error {
-	if domain == "" {
-		return nil
-	}
-
-	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
-		errMsg := strings.Replace(
-			strings.Join(errs, ""),
-			"a lowercase RFC 1123 subdomain",
-			fmt.Sprintf("Domain '%v'", domain),
-			1,
-		)
-		return ErrInvalidDomain(errors.New(errMsg))
-	}
-
-	return nil
+	return CheckDomain(domain).Err()
 }
 
 // ValidateNamespace validates that the input name is a valid Kubernetes namespace name, ie. valid DNS-1123 label.
@@ -134,11 +79,5 @@ func ValidateDomain(domain string) error {
 // start with an alphabetic character, and end with an alphanumeric character
 // (e.g. 'my-namespace', 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')
 func ValidateNamespace(namespace string) error {
-	if errs := validation.IsDNS1035Label(namespace); len(errs) > 0 {
-		// Reuse the error message from Kubernetes validation
-		// Replace "a DNS-1035 label" with more user-friendly context
-		errMsg := strings.Replace(strings.Join(errs, ""), "a DNS-1035 label", fmt.Sprintf("Namespace '%v'", namespace), 1)
-		return ErrInvalidNamespace(errors.New(errMsg))
-	}
-	return nil
+	return CheckNamespace(namespace).Err()
 }
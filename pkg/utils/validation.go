@@ -0,0 +1,425 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Rule of a value's syntax which may be violated.
+type Rule string
+
+const (
+	// RuleRequired is violated by an empty value.
+	RuleRequired Rule = "required"
+	// RuleMaxLength is violated by a value which is too long.
+	RuleMaxLength Rule = "max-length"
+	// RuleCharacters is violated by a value containing disallowed characters.
+	RuleCharacters Rule = "characters"
+	// RuleStart is violated by a value (or segment) with a disallowed first
+	// character.
+	RuleStart Rule = "start"
+	// RuleEnd is violated by a value (or segment) with a disallowed last
+	// character.
+	RuleEnd Rule = "end"
+	// RuleFormat is violated by a value which is otherwise malformed.
+	RuleFormat Rule = "format"
+)
+
+// Violation of a rule by a value of a field.
+type Violation struct {
+	// Field validated, such as "function name" or "label key".
+	Field string `json:"field"`
+	// Value which violates the rule.
+	Value string `json:"value"`
+	// Rule violated.
+	Rule Rule `json:"rule"`
+	// Message describing the violation.
+	Message string `json:"message"`
+	// Characters of the value which are not allowed, if any, in order of
+	// first occurrence.
+	Characters string `json:"characters,omitempty"`
+	// Suggestion is a valid value similar to that which was given, if one
+	// can be derived.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Result of validating a value.  A value is valid if it has no violations.
+type Result struct {
+	Field      string      `json:"field"`
+	Value      string      `json:"value"`
+	Violations []Violation `json:"violations,omitempty"`
+
+	err error // as returned by the equivalent Validate function
+}
+
+// Valid returns true if the value violates no rules.
+func (r Result) Valid() bool {
+	return r.err == nil
+}
+
+// Err returns the error of an invalid value, or nil.  The error is the same
+// as that returned by the equivalent Validate function, such as
+// ErrInvalidFunctionName from ValidateFunctionName.
+func (r Result) Err() error {
+	return r.err
+}
+
+// Suggestion returns a valid value similar to that given, or the empty
+// string if the value is valid or none can be derived.
+func (r Result) Suggestion() string {
+	for _, v := range r.Violations {
+		if v.Suggestion != "" {
+			return v.Suggestion
+		}
+	}
+	return ""
+}
+
+// syntax of a class of values, such as DNS labels, from which violations are
+// determined and suggestions derived.
+type syntax struct {
+	maxLength   int
+	optional    bool            // may be empty
+	allowed     func(rune) bool // characters allowed anywhere
+	allowedDesc string          // description of allowed characters
+	first, last func(rune) bool // of each segment
+	firstDesc   string          // description of the allowed first character
+	lastDesc    string          // description of the allowed last character
+	separator   string          // of segments, if any (such as "." of domains)
+	lower       bool            // suggestions are lower case
+	replacement rune            // of disallowed characters in suggestions
+	prefix      string          // prepended to suggestions starting badly
+}
+
+func isLower(r rune) bool      { return r >= 'a' && r <= 'z' }
+func isUpper(r rune) bool      { return r >= 'A' && r <= 'Z' }
+func isDigit(r rune) bool      { return r >= '0' && r <= '9' }
+func isAlpha(r rune) bool      { return isLower(r) || isUpper(r) }
+func isLowerAlnum(r rune) bool { return isLower(r) || isDigit(r) }
+func isAlnum(r rune) bool      { return isAlpha(r) || isDigit(r) }
+
+// dns1035Label syntax, as of function names and namespaces.
+var dns1035Label = syntax{
+	maxLength:   validation.DNS1035LabelMaxLength,
+	allowed:     func(r rune) bool { return isLowerAlnum(r) || r == '-' },
+	allowedDesc: "lower case alphanumeric characters or '-'",
+	first:       isLower,
+	firstDesc:   "an alphabetic character",
+	last:        isLowerAlnum,
+	lastDesc:    "an alphanumeric character",
+	lower:       true,
+	replacement: '-',
+}
+
+// dns1123Subdomain syntax, as of domains and label key prefixes.
+var dns1123Subdomain = syntax{
+	maxLength:   validation.DNS1123SubdomainMaxLength,
+	allowed:     func(r rune) bool { return isLowerAlnum(r) || r == '-' || r == '.' },
+	allowedDesc: "lower case alphanumeric characters, '-' or '.'",
+	first:       isLowerAlnum,
+	firstDesc:   "an alphanumeric character",
+	last:        isLowerAlnum,
+	lastDesc:    "an alphanumeric character",
+	separator:   ".",
+	lower:       true,
+	replacement: '-',
+}
+
+// envVarName syntax.
+var envVarName = syntax{
+	allowed:     func(r rune) bool { return isAlnum(r) || r == '-' || r == '.' || r == '_' },
+	allowedDesc: "alphabetic characters, digits, '_', '-' or '.'",
+	first:       func(r rune) bool { return !isDigit(r) },
+	firstDesc:   "a character other than a digit",
+	replacement: '_',
+	prefix:      "_",
+}
+
+// configMapKey syntax, as of the keys of both ConfigMaps and Secrets.
+var configMapKey = syntax{
+	maxLength:   validation.DNS1123SubdomainMaxLength,
+	allowed:     func(r rune) bool { return isAlnum(r) || r == '-' || r == '.' || r == '_' },
+	allowedDesc: "alphanumeric characters, '-', '_' or '.'",
+	replacement: '_',
+}
+
+// qualifiedName syntax, as of the name of label keys and of label values.
+var qualifiedName = syntax{
+	maxLength:   validation.LabelValueMaxLength,
+	allowed:     func(r rune) bool { return isAlnum(r) || r == '-' || r == '.' || r == '_' },
+	allowedDesc: "alphanumeric characters, '-', '_' or '.'",
+	first:       isAlnum,
+	firstDesc:   "an alphanumeric character",
+	last:        isAlnum,
+	lastDesc:    "an alphanumeric character",
+	replacement: '-',
+}
+
+// check the value against the syntax, returning its violations.
+func (s syntax) check(field, value string) (vv []Violation) {
+	violation := func(rule Rule, msg string, args ...any) Violation {
+		return Violation{Field: field, Value: value, Rule: rule, Message: fmt.Sprintf(msg, args...)}
+	}
+	if value == "" {
+		if !s.optional {
+			vv = append(vv, violation(RuleRequired, "%v is required", field))
+		}
+		return
+	}
+	if s.maxLength > 0 && len(value) > s.maxLength {
+		vv = append(vv, violation(RuleMaxLength, "%v must be no more than %d characters", field, s.maxLength))
+	}
+	if invalid := s.invalidCharacters(value); invalid != "" {
+		v := violation(RuleCharacters, "%v must consist of %v, but contains %v", field, s.allowedDesc, quoteRunes(invalid))
+		v.Characters = invalid
+		vv = append(vv, v)
+	}
+	segments := []string{value}
+	if s.separator != "" {
+		segments = strings.Split(value, s.separator)
+	}
+	for _, seg := range segments {
+		first, _ := utf8.DecodeRuneInString(seg)
+		last, _ := utf8.DecodeLastRuneInString(seg)
+		badStart := s.first != nil && (seg == "" || !s.first(first))
+		badEnd := s.last != nil && (seg == "" || !s.last(last))
+		if badStart {
+			vv = append(vv, violation(RuleStart, "%v must start with %v", describeSegment(field, s.separator), s.firstDesc))
+		}
+		if badEnd {
+			vv = append(vv, violation(RuleEnd, "%v must end with %v", describeSegment(field, s.separator), s.lastDesc))
+		}
+		if badStart || badEnd {
+			break // reported once, rather than for each segment
+		}
+	}
+	if len(vv) > 0 {
+		if suggestion := s.suggest(value); suggestion != value && len(s.check(field, suggestion)) == 0 {
+			for i := range vv {
+				vv[i].Suggestion = suggestion
+			}
+		}
+	}
+	return
+}
+
+// invalidCharacters of the value, each listed once in order of occurrence.
+func (s syntax) invalidCharacters(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if !s.allowed(r) && !strings.ContainsRune(b.String(), r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// suggest a value similar to that given which has the syntax, or the empty
+// string if none can be derived.
+func (s syntax) suggest(value string) string {
+	if s.lower {
+		value = strings.ToLower(value)
+	}
+	// Replace disallowed characters, collapsing runs of replacements.
+	var b strings.Builder
+	for _, r := range value {
+		if !s.allowed(r) {
+			r = s.replacement
+		}
+		if r == s.replacement && strings.HasSuffix(b.String(), string(s.replacement)) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	value = b.String()
+
+	segments := []string{value}
+	if s.separator != "" {
+		segments = strings.Split(value, s.separator)
+	}
+	var out []string
+	for _, seg := range segments {
+		if s.last != nil {
+			seg = strings.TrimRightFunc(seg, func(r rune) bool { return !s.last(r) })
+		}
+		if s.first != nil {
+			if s.prefix != "" {
+				if first, _ := utf8.DecodeRuneInString(seg); seg != "" && !s.first(first) {
+					seg = s.prefix + seg
+				}
+			} else {
+				seg = strings.TrimLeftFunc(seg, func(r rune) bool { return !s.first(r) })
+			}
+		}
+		if seg != "" {
+			out = append(out, seg)
+		}
+	}
+	value = strings.Join(out, s.separator)
+	if s.maxLength > 0 && len(value) > s.maxLength {
+		value = value[:s.maxLength]
+		if s.last != nil {
+			value = strings.TrimRightFunc(value, func(r rune) bool { return !s.last(r) })
+		}
+	}
+	return value
+}
+
+// describeSegment of a field for messages: the field itself, or each of its
+// segments if separated.
+func describeSegment(field, separator string) string {
+	if separator == "" {
+		return field
+	}
+	return fmt.Sprintf("each %q-separated part of %v", separator, field)
+}
+
+// quoteRunes as a list for messages, such as "'A', '_' and '!'".
+func quoteRunes(s string) string {
+	var qq []string
+	for _, r := range s {
+		qq = append(qq, fmt.Sprintf("%q", r))
+	}
+	if len(qq) == 1 {
+		return qq[0]
+	}
+	return strings.Join(qq[:len(qq)-1], ", ") + " and " + qq[len(qq)-1]
+}
+
+// newResult of validating a value of a field, given the error of the
+// equivalent Validate function.  An invalid value always has at least one
+// violation, falling back to the error's message.
+func newResult(field, value string, s syntax, err error) Result {
+	r := Result{Field: field, Value: value, err: err}
+	if err == nil {
+		return r
+	}
+	if r.Violations = s.check(field, value); len(r.Violations) == 0 {
+		r.Violations = []Violation{{Field: field, Value: value, Rule: RuleFormat, Message: err.Error()}}
+	}
+	return r
+}
+
+// CheckFunctionName validates a function name, which must be a DNS-1035
+// label (see ValidateFunctionName).
+func CheckFunctionName(name string) Result {
+	var err error
+	if errs := validation.IsDNS1035Label(name); len(errs) > 0 {
+		// In case of invalid name the error is this:
+		// "a DNS-1035 label must consist of lower case alphanumeric characters or '-',
+		// start with an alphabetic character,
+		// and end with an alphanumeric character".
+		// Let's reuse it for our purposes, ie. replace "a DNS-1035 label" substring with "Function name" and the actual function name
+		err = ErrInvalidFunctionName(errors.New(strings.Replace(strings.Join(errs, ""), "a DNS-1035 label", fmt.Sprintf("Function name '%v'", name), 1)))
+	}
+	return newResult("function name", name, dns1035Label, err)
+}
+
+// CheckEnvVarName validates the name of an environment variable (see
+// ValidateEnvVarName).
+func CheckEnvVarName(name string) Result {
+	var err error
+	if errs := validation.IsEnvVarName(name); len(errs) > 0 {
+		err = ErrInvalidEnvVarName(errors.New(strings.Join(errs, "")))
+	}
+	return newResult("environment variable name", name, envVarName, err)
+}
+
+// CheckConfigMapKey validates a key of a ConfigMap (see ValidateConfigMapKey).
+func CheckConfigMapKey(key string) Result {
+	var err error
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		err = ErrInvalidConfigMapKey(errors.New(strings.Join(errs, "")))
+	}
+	return newResult("ConfigMap key", key, configMapKey, err)
+}
+
+// CheckSecretKey validates a key of a Secret (see ValidateSecretKey).
+func CheckSecretKey(key string) Result {
+	var err error
+	if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+		err = ErrInvalidSecretKey(errors.New(strings.Join(errs, "")))
+	}
+	return newResult("Secret key", key, configMapKey, err)
+}
+
+// CheckLabelKey validates a label key: a qualified name with an optional
+// DNS subdomain prefix (see ValidateLabelKey).
+func CheckLabelKey(key string) Result {
+	var err error
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		err = ErrInvalidLabel(errors.New(strings.Join(errs, "")))
+	}
+	r := Result{Field: "label key", Value: key, err: err}
+	if err == nil {
+		return r
+	}
+	prefix, name, found := strings.Cut(key, "/")
+	if !found {
+		prefix, name = "", key
+	}
+	if found {
+		r.Violations = append(r.Violations, dns1123Subdomain.check("label key prefix", prefix)...)
+	}
+	r.Violations = append(r.Violations, qualifiedName.check("label key name", name)...)
+	if strings.Count(key, "/") > 1 {
+		r.Violations = append(r.Violations, Violation{Field: "label key", Value: key, Rule: RuleFormat,
+			Message: "label key must be a name with an optional prefix, separated by a single '/'"})
+	}
+	if len(r.Violations) == 0 {
+		r.Violations = []Violation{{Field: "label key", Value: key, Rule: RuleFormat, Message: err.Error()}}
+	}
+	return r
+}
+
+// CheckLabelValue validates a label value, which may be empty, or reference
+// the environment using {{ }} (see ValidateLabelValue).
+func CheckLabelValue(value string) Result {
+	var errs []string
+	if !strings.HasPrefix(value, "{{") {
+		errs = append(errs, validation.IsValidLabelValue(value)...)
+	}
+	var err error
+	if len(errs) > 0 {
+		err = ErrInvalidLabel(errors.New(strings.Join(errs, "")))
+	}
+	s := qualifiedName
+	s.optional = true
+	return newResult("label value", value, s, err)
+}
+
+// CheckDomain validates a domain, which, unless empty, must be a DNS
+// subdomain (see ValidateDomain).
+func CheckDomain(domain string) Result {
+	var err error
+	if domain == "" {
+		return Result{Field: "domain"}
+	}
+	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+		errMsg := strings.Replace(
+			strings.Join(errs, ""),
+			"a lowercase RFC 1123 subdomain",
+			fmt.Sprintf("Domain '%v'", domain),
+			1,
+		)
+		err = ErrInvalidDomain(errors.New(errMsg))
+	}
+	return newResult("domain", domain, dns1123Subdomain, err)
+}
+
+// CheckNamespace validates a namespace name, which must be a DNS-1035 label
+// (see ValidateNamespace).
+func CheckNamespace(namespace string) Result {
+	var err error
+	if errs := validation.IsDNS1035Label(namespace); len(errs) > 0 {
+		// Reuse the error message from Kubernetes validation
+		// Replace "a DNS-1035 label" with more user-friendly context
+		errMsg := strings.Replace(strings.Join(errs, ""), "a DNS-1035 label", fmt.Sprintf("Namespace '%v'", namespace), 1)
+		err = ErrInvalidNamespace(errors.New(errMsg))
+	}
+	return newResult("namespace", namespace, dns1035Label, err)
+}
@@ -0,0 +1,92 @@
+//go:build !integration
+// +build !integration
+
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCheckFunctionName ensures that the violations of an invalid function
+// name are reported with the offending characters and a suggestion.
+func TestCheckFunctionName(t *testing.T) {
+	r := CheckFunctionName("My_Func!")
+	if r.Valid() {
+		t.Fatal("expected an invalid result")
+	}
+	var e ErrInvalidFunctionName
+	if !errors.As(r.Err(), &e) || r.Err().Error() != ValidateFunctionName("My_Func!").Error() {
+		t.Fatalf("expected the error of ValidateFunctionName, got %v", r.Err())
+	}
+	rules := map[Rule]Violation{}
+	for _, v := range r.Violations {
+		rules[v.Rule] = v
+	}
+	if v, ok := rules[RuleCharacters]; !ok || v.Characters != "M_F!" {
+		t.Fatalf("expected the characters 'M_F!' to be reported, got %+v", r.Violations)
+	}
+	if _, ok := rules[RuleStart]; !ok {
+		t.Fatalf("expected the start rule to be violated, got %+v", r.Violations)
+	}
+	if _, ok := rules[RuleEnd]; !ok {
+		t.Fatalf("expected the end rule to be violated, got %+v", r.Violations)
+	}
+	if r.Suggestion() != "my-func" {
+		t.Fatalf("expected suggestion 'my-func', got %q", r.Suggestion())
+	}
+
+	if r = CheckFunctionName("my-func"); !r.Valid() || len(r.Violations) != 0 || r.Err() != nil {
+		t.Fatalf("expected a valid result, got %+v", r)
+	}
+}
+
+// TestCheck_Consistent ensures that each check is invalid exactly when its
+// equivalent Validate function errors, and that an invalid value always has
+// at least one violation.  Any suggestion must itself be valid.
+func TestCheck_Consistent(t *testing.T) {
+	checks := []struct {
+		name     string
+		check    func(string) Result
+		validate func(string) error
+	}{
+		{"function name", CheckFunctionName, ValidateFunctionName},
+		{"namespace", CheckNamespace, ValidateNamespace},
+		{"domain", CheckDomain, ValidateDomain},
+		{"env var name", CheckEnvVarName, ValidateEnvVarName},
+		{"configmap key", CheckConfigMapKey, ValidateConfigMapKey},
+		{"secret key", CheckSecretKey, ValidateSecretKey},
+		{"label key", CheckLabelKey, ValidateLabelKey},
+		{"label value", CheckLabelValue, ValidateLabelValue},
+	}
+	values := []string{"", "a", "A", "-", "1abc", "abc-", "a.b", "a..b", ".a", "a_b", "a/b",
+		"example.com/a", "Example.com/a", "a/b/c", "{{ env:X }}", "with space", "ünïcode",
+		"abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyzabcdefghijklm"}
+	for _, c := range checks {
+		for _, v := range values {
+			r := c.check(v)
+			err := c.validate(v)
+			if r.Valid() != (err == nil) {
+				t.Errorf("%v %q: valid %v, but Validate returned %v", c.name, v, r.Valid(), err)
+			}
+			if !r.Valid() && len(r.Violations) == 0 {
+				t.Errorf("%v %q: invalid without violations", c.name, v)
+			}
+			if s := r.Suggestion(); s != "" && c.validate(s) != nil {
+				t.Errorf("%v %q: suggestion %q is invalid", c.name, v, s)
+			}
+		}
+	}
+}
+
+// TestCheckEnvVarName ensures that an environment variable name starting
+// with a digit is suggested with a prefix.
+func TestCheckEnvVarName(t *testing.T) {
+	r := CheckEnvVarName("1 VAR")
+	if r.Valid() {
+		t.Fatal("expected an invalid result")
+	}
+	if r.Suggestion() != "_1_VAR" {
+		t.Fatalf("expected suggestion '_1_VAR', got %q", r.Suggestion())
+	}
+}
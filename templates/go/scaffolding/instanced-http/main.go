@@ -10,7 +10,7 @@ import (
 )
 
 func main() {
-	if err := http.Start(f.New()); err != nil {
+	if err := http.Start(withStreaming(withMiddleware(withStatic(f.New())))); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
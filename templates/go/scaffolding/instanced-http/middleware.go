@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	fhttp "knative.dev/func-go/http"
+)
+
+// middleware returns the middleware to apply around the handler, outermost
+// first.  It is set only when the user's code declares a package-level
+// Middleware func, in which case an additional file assigning it is
+// written alongside this one during scaffolding.
+var middleware func() []func(http.Handler) http.Handler
+
+// withMiddleware returns the handler wrapped in any declared middleware.
+// The lifecycle methods (Start, Stop, Ready and Alive) of the handler are
+// retained.
+func withMiddleware(h fhttp.Handler) fhttp.Handler {
+	if middleware == nil {
+		return h
+	}
+	var next http.Handler = http.HandlerFunc(h.Handle)
+	mm := middleware()
+	for i := len(mm) - 1; i >= 0; i-- {
+		next = mm[i](next)
+	}
+	return wrapped{h, next}
+}
+
+type wrapped struct {
+	h    fhttp.Handler
+	next http.Handler
+}
+
+func (w wrapped) Handle(rw http.ResponseWriter, r *http.Request) {
+	w.next.ServeHTTP(rw, r)
+}
+
+func (w wrapped) Start(ctx context.Context, cfg map[string]string) error {
+	if i, ok := w.h.(fhttp.Starter); ok {
+		return i.Start(ctx, cfg)
+	}
+	return nil
+}
+
+func (w wrapped) Stop(ctx context.Context) error {
+	if i, ok := w.h.(fhttp.Stopper); ok {
+		return i.Stop(ctx)
+	}
+	return nil
+}
+
+func (w wrapped) Ready(ctx context.Context) (bool, error) {
+	if i, ok := w.h.(fhttp.ReadinessReporter); ok {
+		return i.Ready(ctx)
+	}
+	return true, nil
+}
+
+func (w wrapped) Alive(ctx context.Context) (bool, error) {
+	if i, ok := w.h.(fhttp.LivenessReporter); ok {
+		return i.Alive(ctx)
+	}
+	return true, nil
+}
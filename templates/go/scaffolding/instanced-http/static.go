@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	fhttp "knative.dev/func-go/http"
+)
+
+// static files to serve alongside the handler.  It is set only when the
+// source includes a static directory, in which case that directory is
+// embedded by an additional file written alongside this one during
+// scaffolding.
+var static fs.FS
+
+// Defaults of the static file server, each of which may be overridden by
+// the environment variable of the same name.
+const (
+	// FUNC_STATIC_PREFIX is the URL path under which files are served.
+	defaultStaticPrefix = "/"
+	// FUNC_STATIC_MAX_AGE is the Cache-Control max-age, in seconds, of
+	// assets other than HTML documents, which are always revalidated.
+	defaultStaticMaxAge = 3600
+)
+
+// withStatic returns the handler preceded by a server of any static files.
+// Requests which do not match a file are passed to the handler.  With
+// FUNC_STATIC_SPA=true, requests for HTML documents which do not match a
+// file are instead served index.html, such that client-side routes of a
+// single page application resolve.
+func withStatic(h fhttp.Handler) fhttp.Handler {
+	if static == nil {
+		return h
+	}
+	s := &staticHandler{
+		files:  static,
+		next:   http.HandlerFunc(h.Handle),
+		prefix: defaultStaticPrefix,
+		maxAge: defaultStaticMaxAge,
+		spa:    os.Getenv("FUNC_STATIC_SPA") == "true",
+		etags:  map[string]string{},
+	}
+	if v := os.Getenv("FUNC_STATIC_PREFIX"); v != "" {
+		s.prefix = "/" + strings.Trim(v, "/") + "/"
+		if s.prefix == "//" {
+			s.prefix = "/"
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("FUNC_STATIC_MAX_AGE")); err == nil && v >= 0 {
+		s.maxAge = v
+	}
+	return wrapped{h, s}
+}
+
+type staticHandler struct {
+	files  fs.FS
+	next   http.Handler
+	prefix string
+	maxAge int
+	spa    bool
+
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+func (s *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !strings.HasPrefix(r.URL.Path, s.prefix) {
+		s.next.ServeHTTP(w, r)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(r.URL.Path, s.prefix)), "/")
+	if name == "" {
+		name = "."
+	}
+	if s.serve(w, r, name) {
+		return
+	}
+	if s.spa && path.Ext(name) == "" && strings.Contains(r.Header.Get("Accept"), "text/html") && s.serve(w, r, "index.html") {
+		return
+	}
+	s.next.ServeHTTP(w, r)
+}
+
+// serve the named file, or the index.html of the named directory, returning
+// false if neither exist.
+func (s *staticHandler) serve(w http.ResponseWriter, r *http.Request, name string) bool {
+	fi, err := fs.Stat(s.files, name)
+	if err == nil && fi.IsDir() {
+		name = path.Join(name, "index.html")
+		fi, err = fs.Stat(s.files, name)
+	}
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	f, err := s.files.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		return false
+	}
+
+	etag, err := s.etag(name, content)
+	if err != nil {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	if path.Ext(name) == ".html" {
+		w.Header().Set("Cache-Control", "no-cache")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(s.maxAge))
+	}
+	http.ServeContent(w, r, name, fi.ModTime(), content)
+	return true
+}
+
+// etag of the named file, calculated from its content on first request.
+func (s *staticHandler) etag(name string, content io.ReadSeeker) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if etag, ok := s.etags[name]; ok {
+		return etag, nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	s.etags[name] = `"` + hex.EncodeToString(hash.Sum(nil))[:16] + `"`
+	return s.etags[name], nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	fhttp "knative.dev/func-go/http"
+)
+
+// start and stop are the lifecycle hooks of a static function.  Each is set
+// only when the user's code declares the package-level func of the same
+// name, in which case an additional file assigning it is written alongside
+// this one during scaffolding.
+var (
+	start func(ctx context.Context, cfg map[string]string) error
+	stop  func(ctx context.Context) error
+)
+
+// withLifecycle returns the handler with any declared lifecycle hooks.  Start
+// is invoked with the function's configuration before requests are served.
+// Stop is invoked on SIGTERM (or SIGINT) once in-flight requests have
+// completed, with a context which func-go cancels after a fixed 30 seconds,
+// regardless of the termination grace period.
+func withLifecycle(h fhttp.Handler) fhttp.Handler {
+	if start == nil && stop == nil {
+		return h
+	}
+	return hooks{h}
+}
+
+type hooks struct {
+	fhttp.Handler
+}
+
+func (h hooks) Start(ctx context.Context, cfg map[string]string) error {
+	if start == nil {
+		return nil
+	}
+	return start(ctx, cfg)
+}
+
+func (h hooks) Stop(ctx context.Context) error {
+	if stop == nil {
+		return nil
+	}
+	return stop(ctx)
+}
@@ -17,7 +17,7 @@ func (f fx) Handle(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
-	if err := fhttp.Start(fx(f.Handle)); err != nil {
+	if err := fhttp.Start(withStreaming(withMiddleware(withStatic(withLifecycle(fx(f.Handle)))))); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	fhttp "knative.dev/func-go/http"
+)
+
+// withStreaming returns the handler such that streaming requests are not
+// subject to the read and write timeouts of the server.  A request is
+// streaming when it accepts a Server-Sent Event stream (Accept:
+// text/event-stream) or requests an upgrade to a WebSocket.  The handler is
+// then free to flush events as they are produced (see http.Flusher) or to
+// hijack the connection (see http.Hijacker) for the life of the stream.
+func withStreaming(h fhttp.Handler) fhttp.Handler {
+	return wrapped{h, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if streaming(r) {
+			rc := http.NewResponseController(w)
+			_ = rc.SetReadDeadline(time.Time{})
+			_ = rc.SetWriteDeadline(time.Time{})
+		}
+		h.Handle(w, r)
+	})}
+}
+
+func streaming(r *http.Request) bool {
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return true
+	}
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
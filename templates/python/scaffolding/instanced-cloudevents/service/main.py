@@ -18,6 +18,42 @@ except ImportError:
         logging.error("Function must export either 'new' or 'handle'")
         raise
 
+try:
+    from function import start  # type: ignore[import]
+except ImportError:
+    start = None
+
+try:
+    from function import stop  # type: ignore[import]
+except ImportError:
+    stop = None
+
+
+def with_lifecycle(handle, start, stop):
+    """
+    Return a factory of instances which handle requests with the Function's
+    module-level 'handle', such that its module-level 'start' and 'stop'
+    hooks, if exported, are invoked as are those of an instanced Function:
+    'start' with the Function's configuration before requests are served,
+    and 'stop' on SIGTERM once in-flight requests have completed.
+    """
+    class Function:
+        async def handle(self, *args):
+            await handle(*args)
+
+    if start:
+        Function.start = lambda self, cfg: start(cfg)
+    if stop:
+        Function.stop = lambda self: stop()
+
+    def new():
+        return Function()
+    return new
+
+
+if handler.__name__ != "new" and (start or stop):
+    handler = with_lifecycle(handler, start, stop)
+
 if __name__ == "__main__":
     logging.info("Functions middleware invoking user function")
     serve(handler)
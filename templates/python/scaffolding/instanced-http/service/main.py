@@ -4,7 +4,12 @@ expose it as a network service.  This code is written on-demand when a
 Function is being built, deployed or run.  This will be included in the
 final container.
 """
+import functools
+import hashlib
 import logging
+import mimetypes
+import os
+import posixpath
 from func_python.http import serve
 
 logging.basicConfig(level=logging.INFO)
@@ -18,6 +23,162 @@ except ImportError:
         logging.error("Function must export either 'new' or 'handle'")
         raise
 
+try:
+    from function import start  # type: ignore[import]
+except ImportError:
+    start = None
+
+try:
+    from function import stop  # type: ignore[import]
+except ImportError:
+    stop = None
+
+try:
+    from function import middleware  # type: ignore[import]
+except ImportError:
+    middleware = []
+
+# Static files copied from the Function's 'static' directory, if any.
+STATIC_DIR = os.path.join(os.path.dirname(os.path.abspath(__file__)), "static")
+
+
+def with_lifecycle(handle, start, stop):
+    """
+    Return a factory of instances which handle requests with the Function's
+    module-level 'handle', such that its module-level 'start' and 'stop'
+    hooks, if exported, are invoked as are those of an instanced Function:
+    'start' with the Function's configuration before requests are served,
+    and 'stop' on SIGTERM once in-flight requests have completed.
+    """
+    class Function:
+        async def handle(self, *args):
+            await handle(*args)
+
+    if start:
+        Function.start = lambda self, cfg: start(cfg)
+    if stop:
+        Function.stop = lambda self: stop()
+
+    def new():
+        return Function()
+    return new
+
+
+def wrap_handler(handler, wrap):
+    """
+    Wrap the ASGI handle of the Function with the given wrap, which accepts
+    an ASGI application and returns another.  The instance returned by 'new'
+    retains its lifecycle methods (start, stop, alive, ready); only its
+    'handle' is wrapped.
+    """
+    if handler.__name__ == "new":
+        @functools.wraps(handler)
+        def new():
+            instance = handler()
+            instance.handle = wrap(instance.handle)
+            return instance
+        return new
+
+    app = wrap(handler)
+
+    @functools.wraps(handler)
+    async def handle(scope, receive, send):
+        await app(scope, receive, send)
+    return handle
+
+
+def with_middleware(handler, middleware):
+    """
+    Wrap the handler such that each request first passes through the
+    middleware, outermost first.  Each middleware is an ASGI middleware: a
+    callable which accepts an ASGI application and returns another.
+    """
+    def wrap(app):
+        for m in reversed(middleware):
+            app = m(app)
+        return app
+    return wrap_handler(handler, wrap)
+
+
+def with_static(handler, directory):
+    """
+    Wrap the handler such that GET and HEAD requests matching a file in the
+    directory are served that file.  HTML documents are always revalidated;
+    other assets may be cached for FUNC_STATIC_MAX_AGE seconds (default 3600).
+    Files are served under FUNC_STATIC_PREFIX (default "/").  With
+    FUNC_STATIC_SPA=true, requests for HTML documents which match no file are
+    served index.html.  All other requests are passed to the handler.
+    """
+    prefix = "/" + os.environ.get("FUNC_STATIC_PREFIX", "").strip("/")
+    prefix = prefix if prefix == "/" else prefix + "/"
+    spa = os.environ.get("FUNC_STATIC_SPA") == "true"
+    try:
+        max_age = max(0, int(os.environ.get("FUNC_STATIC_MAX_AGE", "3600")))
+    except ValueError:
+        max_age = 3600
+    etags = {}
+
+    def resolve(name):
+        path = os.path.join(directory, *[p for p in name.split("/") if p])
+        if os.path.isdir(path):
+            path = os.path.join(path, "index.html")
+        return path if os.path.isfile(path) else None
+
+    def etag(path):
+        if path not in etags:
+            with open(path, "rb") as f:
+                etags[path] = '"' + hashlib.sha256(f.read()).hexdigest()[:16] + '"'
+        return etags[path]
+
+    async def serve_file(scope, send, path):
+        headers = {k.decode("latin-1").lower(): v.decode("latin-1")
+                   for k, v in scope.get("headers", [])}
+        tag = etag(path)
+        cache = "no-cache" if path.endswith(".html") \
+            else f"public, max-age={max_age}"
+        response = [(b"etag", tag.encode()), (b"cache-control", cache.encode())]
+        if headers.get("if-none-match") == tag:
+            await send({"type": "http.response.start", "status": 304,
+                        "headers": response})
+            await send({"type": "http.response.body", "body": b""})
+            return
+        content_type = mimetypes.guess_type(path)[0] or "application/octet-stream"
+        with open(path, "rb") as f:
+            body = f.read()
+        response += [(b"content-type", content_type.encode()),
+                     (b"content-length", str(len(body)).encode())]
+        await send({"type": "http.response.start", "status": 200,
+                    "headers": response})
+        await send({"type": "http.response.body",
+                    "body": b"" if scope["method"] == "HEAD" else body})
+
+    def wrap(app):
+        async def static(scope, receive, send):
+            if scope["type"] != "http" or scope["method"] not in ("GET", "HEAD") \
+                    or not scope["path"].startswith(prefix):
+                return await app(scope, receive, send)
+            name = posixpath.normpath("/" + scope["path"][len(prefix):])
+            path = resolve(name)
+            if path is None and spa and not posixpath.splitext(name)[1]:
+                accept = dict(scope.get("headers", [])).get(b"accept", b"")
+                if b"text/html" in accept:
+                    path = resolve("index.html")
+            if path is None:
+                return await app(scope, receive, send)
+            await serve_file(scope, send, path)
+        return static
+    return wrap_handler(handler, wrap)
+
+
+if handler.__name__ != "new" and (start or stop):
+    handler = with_lifecycle(handler, start, stop)
+
+if os.path.isdir(STATIC_DIR):
+    handler = with_static(handler, STATIC_DIR)
+
+if middleware:
+    handler = with_middleware(handler, middleware)
+
 if __name__ == "__main__":
     logging.info("Functions middleware invoking user function")
     serve(handler)
@@ -74,7 +74,7 @@ func TestConfigLabel(t *testing.T) {
 	// Deploy
 	knFunc.TestCmd.
 		WithEnv(testEnvName, testEnvValue).
-		Exec("deploy", "--registry", common.GetRegistry())
+		Exec("deploy", "--yes", "--registry", common.GetRegistry())
 	defer knFunc.TestCmd.Exec("delete")
 
 	// Then assert that...
@@ -152,7 +152,7 @@ func TestConfigVolumes(t *testing.T) {
 	configVolumesRemove("/bad-cm", enter)
 
 	// Deploy
-	knFunc.TestCmd.Exec("deploy", "--registry", common.GetRegistry())
+	knFunc.TestCmd.Exec("deploy", "--yes", "--registry", common.GetRegistry())
 	defer knFunc.TestCmd.Exec("delete")
 	_, functionUrl := common.WaitForFunctionReady(t, funcName)
 
@@ -279,7 +279,7 @@ func TestConfigVolumesPvcEmptyDir(t *testing.T) {
 
 	// Deploy
 
-	knFunc.TestCmd.Exec("deploy", "--registry", common.GetRegistry())
+	knFunc.TestCmd.Exec("deploy", "--yes", "--registry", common.GetRegistry())
 	t.Cleanup(func() {
 		knFunc.TestCmd.Exec("delete")
 	})
@@ -131,7 +131,7 @@ func TestFunctionExtendedFlow(t *testing.T) {
 		assert.Assert(t, strings.Contains(listResult.Out, funcName) == false, "Function is listed as deployed after delete")
 	}
 
-	result = knFunc.Exec("deploy", "--build=false")
+	result = knFunc.Exec("deploy", "--yes", "--build=false")
 	firstRevisionName, functionUrl := common.WaitForFunctionReady(t, funcName)
 	defer knFuncDelete(t)
 
@@ -34,7 +34,7 @@ func lifecycleCloudEventsTest(t *testing.T, language string, builder string) {
 	knFunc := common.NewKnFuncShellCli(t)
 
 	knFunc.Exec("create", "--language", language, "--template", "cloudevents", funcPath)
-	knFunc.Exec("deploy", "--registry", common.GetRegistry(), "--builder", builder, "--path", funcPath)
+	knFunc.Exec("deploy", "--yes", "--registry", common.GetRegistry(), "--builder", builder, "--path", funcPath)
 	defer knFunc.Exec("delete", "--path", funcPath)
 
 	_, functionUrl := common.WaitForFunctionReady(t, funcName)
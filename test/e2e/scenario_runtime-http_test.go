@@ -74,7 +74,7 @@ func lifecycleHttpTest(t *testing.T, language string, builder string) {
 	knFunc := common.NewKnFuncShellCli(t)
 
 	knFunc.Exec("create", "--language", language, "--template", "http", funcPath)
-	knFunc.Exec("deploy", "--registry", common.GetRegistry(), "--builder", builder, "--path", funcPath)
+	knFunc.Exec("deploy", "--yes", "--registry", common.GetRegistry(), "--builder", builder, "--path", funcPath)
 	defer knFunc.Exec("delete", "--path", funcPath)
 
 	_, functionUrl := common.WaitForFunctionReady(t, funcName)
@@ -93,7 +93,7 @@ module.exports = { handle };
 	oncluster.AssertNoError(f.T, err)
 
 	knFunc.Exec("config", "env", "add", "--name", "TARGET_SINK", "--value", f.TestBrokerUrl, "-p", funcProducerPath)
-	knFunc.Exec("deploy", "-r", common.GetRegistry(), "-p", funcProducerPath)
+	knFunc.Exec("deploy", "--yes", "-r", common.GetRegistry(), "-p", funcProducerPath)
 	f.FuncProducerUrl = knFunc.Exec("describe", "-o", "url", "-p", funcProducerPath).Out
 	f.FuncProducerUrl = strings.TrimRight(f.FuncProducerUrl, "\n")
 
@@ -127,7 +127,7 @@ module.exports = { handle };
 	oncluster.AssertNoError(f.T, err)
 
 	knFunc.Exec("subscribe", "--filter", "type="+f.SubscribeToEventType, "--source", f.TestBrokerName)
-	knFunc.Exec("deploy", "-r", common.GetRegistry(), "-p", funcConsumerPath)
+	knFunc.Exec("deploy", "--yes", "-r", common.GetRegistry(), "-p", funcConsumerPath)
 
 	f.T.Cleanup(func() {
 		knFunc.Exec("delete", funcConsumerName)
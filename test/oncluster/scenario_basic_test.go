@@ -27,7 +27,7 @@ func TestBasicUpload(t *testing.T) {
 		WriteNewSimpleIndexJS(t, funcPath, "first revision")
 
 		// Deploy it
-		knFunc.Exec("deploy",
+		knFunc.Exec("deploy", "--yes",
 			"-p", funcPath,
 			"-r", common.GetRegistry(),
 			"--remote",
@@ -83,7 +83,7 @@ func TestBasicGit(t *testing.T) {
 		sh := GitInitialCommitAndPush(t, funcPath, remoteRepo.ExternalCloneURL)
 
 		// Deploy it
-		knFunc.Exec("deploy",
+		knFunc.Exec("deploy", "--yes",
 			"-p", funcPath,
 			"-r", common.GetRegistry(),
 			"--remote",
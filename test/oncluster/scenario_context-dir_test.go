@@ -40,7 +40,7 @@ func TestContextDirFunc(t *testing.T) {
 		// Initial commit to repository: git init + commit + push
 		GitInitialCommitAndPush(t, gitProjectPath, remoteRepo.ExternalCloneURL)
 
-		knFunc.Exec("deploy",
+		knFunc.Exec("deploy", "--yes",
 			"-p", funcPath,
 			"-r", common.GetRegistry(),
 			"--remote",
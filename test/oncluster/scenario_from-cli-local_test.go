@@ -27,7 +27,7 @@ func TestFromCliBuildLocal(t *testing.T) {
 	// Update func.yaml build as local + some fake url (it should not call it anyway)
 	UpdateFuncGit(t, funcPath, fn.Git{URL: "http://fake-repo/repo.git"})
 
-	knFunc.Exec("deploy",
+	knFunc.Exec("deploy", "--yes",
 		"-p", funcPath,
 		"-r", common.GetRegistry(),
 		// "--remote",  // NOTE: Intentionally omitted
@@ -44,7 +44,7 @@ func TestFromCliDefaultBranch(t *testing.T) {
 
 	GitInitialCommitAndPush(t, gitProjectPath, remoteRepo.ExternalCloneURL)
 
-	knFunc.Exec("deploy",
+	knFunc.Exec("deploy", "--yes",
 		"-r", common.GetRegistry(),
 		"-p", funcPath,
 		"--remote",
@@ -85,7 +85,7 @@ func TestFromCliFeatureBranch(t *testing.T) {
 	sh.Exec(`git commit -m "feature branch change"`)
 	sh.Exec("git push -u origin feature/branch")
 
-	knFunc.Exec("deploy",
+	knFunc.Exec("deploy", "--yes",
 		"-r", common.GetRegistry(),
 		"-p", funcPath,
 		"--remote",
@@ -123,7 +123,7 @@ func TestFromCliContextDirFunc(t *testing.T) {
 
 	GitInitialCommitAndPush(t, gitProjectPath, remoteRepo.ExternalCloneURL)
 
-	knFunc.Exec("deploy",
+	knFunc.Exec("deploy", "--yes",
 		"-r", common.GetRegistry(),
 		"-p", funcPath,
 		"--remote",
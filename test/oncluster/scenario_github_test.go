@@ -72,7 +72,7 @@ func TestGitHubFunc(t *testing.T) {
 
 	// -- Deploy Func
 	knFunc := common.NewKnFuncShellCli(t)
-	result := knFunc.Exec("deploy",
+	result := knFunc.Exec("deploy", "--yes",
 		"--path", funcPath,
 		"--registry", common.GetRegistry(),
 		"--remote",
@@ -87,7 +87,7 @@ func TestRemoteRepository(t *testing.T) {
 
 	knFunc.SourceDir = funcPath
 
-	knFunc.Exec("deploy", "--registry", common.GetRegistry(), "--remote")
+	knFunc.Exec("deploy", "--yes", "--registry", common.GetRegistry(), "--remote")
 	defer knFunc.Exec("delete")
 
 	result := knFunc.Exec("invoke", "-p", funcPath)
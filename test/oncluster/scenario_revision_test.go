@@ -108,7 +108,7 @@ func GitRevisionCheck(
 	// Setup specific code
 	setupCodeFn(sh, funcPath, remoteRepo.ClusterCloneURL)
 
-	knFunc.Exec("deploy",
+	knFunc.Exec("deploy", "--yes",
 		"-r", common.GetRegistry(),
 		"-p", funcPath,
 		"--remote",
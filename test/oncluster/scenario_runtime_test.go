@@ -73,7 +73,7 @@ func runtimeImpl(t *testing.T, lang string, builder string) {
 
 	GitInitialCommitAndPush(t, gitProjectPath, remoteRepo.ExternalCloneURL)
 
-	knFunc.Exec("deploy",
+	knFunc.Exec("deploy", "--yes",
 		"--registry", common.GetRegistry(),
 		"--path", funcPath,
 		"--remote",